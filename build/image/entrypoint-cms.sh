@@ -6,9 +6,13 @@ CONFIG_PATH=/etc/cms
 ROOT_CA_DIR=${CONFIG_PATH}/root-ca
 INTERMEDIATE_CA_DIR=${CONFIG_PATH}/intermediate-ca
 CERTDIR_TRUSTEDJWTCERTS=${CONFIG_PATH}/jwt
+REVOKED_CERTS_DIR=${CONFIG_PATH}/revoked-certificates
+CRL_DIR=${CONFIG_PATH}/crl
+ISSUED_CERTS_DIR=${CONFIG_PATH}/issued-certificates
+ACME_DIR=${CONFIG_PATH}/acme
 
 if [ ! -f $CONFIG_PATH/.setup_done ]; then
-  for directory in $LOG_PATH $CONFIG_PATH $CERTDIR_TRUSTEDJWTCERTS $ROOT_CA_DIR $INTERMEDIATE_CA_DIR; do
+  for directory in $LOG_PATH $CONFIG_PATH $CERTDIR_TRUSTEDJWTCERTS $ROOT_CA_DIR $INTERMEDIATE_CA_DIR $REVOKED_CERTS_DIR $CRL_DIR $ISSUED_CERTS_DIR $ACME_DIR $ACME_DIR/eab-keys $ACME_DIR/accounts $ACME_DIR/orders; do
     mkdir -p $directory
     if [ $? -ne 0 ]; then
       echo "Cannot create directory: $directory"
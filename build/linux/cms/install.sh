@@ -65,6 +65,18 @@ chmod 700 $CONFIG_PATH/root-ca
 mkdir -p $CONFIG_PATH/intermediate-ca && chown cms:cms $CONFIG_PATH/intermediate-ca
 chmod 700 $CONFIG_PATH/intermediate-ca
 
+mkdir -p $CONFIG_PATH/revoked-certificates && chown cms:cms $CONFIG_PATH/revoked-certificates
+chmod 700 $CONFIG_PATH/revoked-certificates
+
+mkdir -p $CONFIG_PATH/crl && chown cms:cms $CONFIG_PATH/crl
+chmod 700 $CONFIG_PATH/crl
+
+mkdir -p $CONFIG_PATH/issued-certificates && chown cms:cms $CONFIG_PATH/issued-certificates
+chmod 700 $CONFIG_PATH/issued-certificates
+
+mkdir -p $CONFIG_PATH/acme/eab-keys $CONFIG_PATH/acme/accounts $CONFIG_PATH/acme/orders && chown -R cms:cms $CONFIG_PATH/acme
+chmod -R 700 $CONFIG_PATH/acme
+
 # Create logging dir in /var/log
 mkdir -p $LOG_PATH && chown cms:cms $LOG_PATH
 chmod 700 $LOG_PATH
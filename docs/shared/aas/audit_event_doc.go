@@ -0,0 +1,96 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+package aas
+
+import "github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+
+// AuditEvents response payload
+// swagger:parameters AuditEvents
+type AuditEvents struct {
+	// in:body
+	Body types.AuditEvents
+}
+
+// swagger:operation GET /audit-events AuditEvents searchAuditEvents
+// ---
+// description: |
+//   Retrieves the audit trail of the changes made to the users, roles and permissions, the most recent first. Each
+//   event records the actor, the action, the target user, role or client, the permissions of the target before and
+//   after the change and the source IP of the request. A valid bearer token with the audit_events:search permission
+//   should be provided to authorize this REST call.
+//
+//   The actions are user:create, user:update, user:delete, user:change_password, user_roles:add, user_roles:delete,
+//   role:create, role:delete, client_roles:add, client_roles:delete and user_roles:sync. The roles of the LDAP
+//   directory users are synchronized with their groups at login, these changes are recorded with the actor
+//   ldap-federation.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: actor
+//   description: Name of the user or ID of the client that made the change.
+//   in: query
+//   type: string
+// - name: target
+//   description: ID or name of the user, role or client that was changed.
+//   in: query
+//   type: string
+// - name: action
+//   description: Action of the change.
+//   in: query
+//   type: string
+// - name: permission
+//   description: |
+//     Prefix of a permission rule granted by the change, the target holds the rule after but not before the
+//     change, e.g. keys:transfer returns the events that granted the keys:transfer permission.
+//   in: query
+//   type: string
+// - name: from
+//   description: Start of the time range, in RFC3339 format.
+//   in: query
+//   type: string
+//   format: date-time
+// - name: to
+//   description: End of the time range, in RFC3339 format.
+//   in: query
+//   type: string
+//   format: date-time
+// - name: limit
+//   description: Maximum number of events returned, 1000 by default.
+//   in: query
+//   type: integer
+// responses:
+//   '200':
+//     description: Successfully retrieved the audit events.
+//     schema:
+//       "$ref": "#/definitions/AuditEvents"
+//   '400':
+//     description: Invalid search criteria provided.
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/audit-events?permission=keys:transfer&from=2020-10-01T00:00:00Z
+// x-sample-call-output: |
+//    [
+//       {
+//          "id": "0b8a8c0e-3c29-4a2b-8f52-4f8f7bbd2a51",
+//          "time": "2020-10-20T08:12:45.132456Z",
+//          "actor": "admin",
+//          "action": "user_roles:add",
+//          "target_type": "user",
+//          "target_id": "1fdb39de-7bf5-44c8-b6c7-b4a1e5a9e6c2",
+//          "target_name": "operator",
+//          "after": [
+//             {
+//                "service": "KBS",
+//                "rules": [
+//                   "keys:transfer:*"
+//                ]
+//             }
+//          ],
+//          "source_ip": "10.105.167.153"
+//       }
+//    ]
+// ---
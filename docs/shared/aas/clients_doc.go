@@ -0,0 +1,332 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+package aas
+
+import "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+
+// ClientCreateInfo request payload
+// swagger:parameters ClientCreateInfo
+type ClientCreateInfo struct {
+	// in:body
+	Body aas.ClientCreate
+}
+
+// ClientResponse response payload
+// swagger:parameters ClientResponse
+type ClientResponse struct {
+	// in:body
+	Body aas.ClientCreateResponse
+}
+
+type ClientsResponse []aas.ClientCreateResponse
+
+// ClientsResponse response payload
+// swagger:parameters ClientsResponse
+type SwaggClientsResponse struct {
+	// in:body
+	Body ClientsResponse
+}
+
+// OAuth2TokenResponse response payload
+// swagger:parameters OAuth2TokenResponse
+type OAuth2TokenResponse struct {
+	// in:body
+	Body aas.OAuth2TokenResponse
+}
+
+// OAuth2Error response payload
+// swagger:parameters OAuth2Error
+type OAuth2Error struct {
+	// in:body
+	Body aas.OAuth2Error
+}
+
+// swagger:operation POST /clients Clients createClient
+// ---
+//
+// description: |
+//   Registers a service account that authenticates with the OAuth2 client credentials grant. The
+//   token_endpoint_auth_method is one of client_secret_basic, client_secret_post, private_key_jwt or
+//   tls_client_auth. A client secret is generated for the client_secret_* methods and is only returned
+//   in this response. The certificate_common_name of the CMS issued client certificate is required for
+//   private_key_jwt and tls_client_auth. A valid bearer token should be provided to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// consumes:
+//  - application/json
+// produces:
+//  - application/json
+// parameters:
+// - name: request body
+//   in: body
+//   required: true
+//   schema:
+//     "$ref": "#/definitions/ClientCreate"
+// responses:
+//   '201':
+//     description: Successfully registered the client.
+//     schema:
+//       "$ref": "#/definitions/ClientCreateResponse"
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/clients
+// x-sample-call-input: |
+//    {
+//       "client_name" : "ihub",
+//       "token_endpoint_auth_method" : "private_key_jwt",
+//       "certificate_common_name" : "IHUB Client"
+//    }
+// x-sample-call-output: |
+//       {
+//          "client_id": "4b9e1c2a-3d5f-4e61-9a7b-8c0d1e2f3a4b",
+//          "client_name" : "ihub",
+//          "token_endpoint_auth_method" : "private_key_jwt",
+//          "certificate_common_name" : "IHUB Client"
+//       }
+// ---
+
+// swagger:operation GET /clients Clients queryClients
+// ---
+// description: |
+//   Retrieves the list of registered clients. A valid bearer token should be provided to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: name
+//   description: Name of the client.
+//   in: query
+//   type: string
+// responses:
+//   '200':
+//     description: Successfully retrieved the clients.
+//     schema:
+//       "$ref": "#/definitions/ClientsResponse"
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/clients?name=ihub
+// x-sample-call-output: |
+//    [
+//       {
+//          "client_id": "4b9e1c2a-3d5f-4e61-9a7b-8c0d1e2f3a4b",
+//          "client_name" : "ihub",
+//          "token_endpoint_auth_method" : "private_key_jwt",
+//          "certificate_common_name" : "IHUB Client"
+//       }
+//    ]
+// ---
+
+// swagger:operation GET /clients/{client_id} Clients getClient
+// ---
+// description: |
+//   Retrieves the details of a client. A valid bearer token should be provided to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: client_id
+//   description: Unique ID of the client.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '200':
+//     description: Successfully retrieved the client.
+//     schema:
+//       "$ref": "#/definitions/ClientCreateResponse"
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/clients/4b9e1c2a-3d5f-4e61-9a7b-8c0d1e2f3a4b
+// ---
+
+// swagger:operation DELETE /clients/{client_id} Clients deleteClient
+// ---
+// description: |
+//   Deletes a client along with its role associations. A valid bearer token should be provided to authorize
+//   this REST call.
+//
+// security:
+//  - bearerAuth: []
+// parameters:
+// - name: client_id
+//   description: Unique ID of the client.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '204':
+//     description: Successfully deleted the client.
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/clients/4b9e1c2a-3d5f-4e61-9a7b-8c0d1e2f3a4b
+// ---
+
+// swagger:operation POST /clients/{client_id}/roles Clients addClientRoles
+// ---
+// description: |
+//   Assigns roles to a client. The roles of the client are the scopes it can request from the token endpoint.
+//   A valid bearer token should be provided to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// consumes:
+//  - application/json
+// parameters:
+// - name: client_id
+//   description: Unique ID of the client.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: request body
+//   in: body
+//   required: true
+//   schema:
+//     "$ref": "#/definitions/RoleIDs"
+// responses:
+//   '201':
+//     description: Successfully added the roles to the client.
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/clients/4b9e1c2a-3d5f-4e61-9a7b-8c0d1e2f3a4b/roles
+// x-sample-call-input: |
+//    {
+//       "role_ids": ["75fa8fe9-f57e-4224-b4a8-dd69d9b37a2d"]
+//    }
+// ---
+
+// swagger:operation GET /clients/{client_id}/roles Clients queryClientRoles
+// ---
+// description: |
+//   Retrieves the roles of a client. A valid bearer token should be provided to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: client_id
+//   description: Unique ID of the client.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '200':
+//     description: Successfully retrieved the roles of the client.
+//     schema:
+//       "$ref": "#/definitions/UserRolesResponse"
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/clients/4b9e1c2a-3d5f-4e61-9a7b-8c0d1e2f3a4b/roles
+// ---
+
+// swagger:operation DELETE /clients/{client_id}/roles/{role_id} Clients deleteClientRole
+// ---
+// description: |
+//   Removes a role from a client. A valid bearer token should be provided to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// parameters:
+// - name: client_id
+//   description: Unique ID of the client.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: role_id
+//   description: Unique ID of the role.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '204':
+//     description: Successfully removed the role from the client.
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/clients/4b9e1c2a-3d5f-4e61-9a7b-8c0d1e2f3a4b/roles/75fa8fe9-f57e-4224-b4a8-dd69d9b37a2d
+// ---
+
+// swagger:operation POST /oauth2/token Token getOAuth2Token
+// ---
+// description: |
+//   Issues an access token to a client with the OAuth2 client credentials grant (RFC 6749 section 4.4). The
+//   client authenticates with the method it is registered for: HTTP basic authentication (client_secret_basic),
+//   the client_id and client_secret parameters (client_secret_post), a JWT client assertion signed with the key of
+//   its CMS issued certificate and carrying the certificate chain in the x5c header (private_key_jwt, RFC 7523)
+//   or its CMS issued certificate in the TLS handshake (tls_client_auth, RFC 8705). The scope is a space separated
+//   list of the client's roles in the form &lt;service&gt;:&lt;role name&gt;[:&lt;context&gt;], all the roles of the
+//   client are granted when no scope is requested. Errors are returned as defined in RFC 6749 section 5.2.
+//
+//   Users obtain a short lived access token along with a refresh token with the password grant (RFC 6749
+//   section 4.3). The refresh_token grant (RFC 6749 section 6) exchanges a refresh token for a new access token
+//   and a new refresh token. A refresh token can only be used once, using it again revokes all the tokens of the
+//   user.
+//
+// consumes:
+//  - application/x-www-form-urlencoded
+// produces:
+//  - application/json
+// parameters:
+// - name: grant_type
+//   in: formData
+//   required: true
+//   type: string
+//   enum: [client_credentials, password, refresh_token]
+// - name: scope
+//   in: formData
+//   type: string
+// - name: username
+//   description: Name of the user, required for the password grant.
+//   in: formData
+//   type: string
+// - name: password
+//   description: Password of the user, required for the password grant.
+//   in: formData
+//   type: string
+// - name: refresh_token
+//   description: Refresh token, required for the refresh_token grant.
+//   in: formData
+//   type: string
+// - name: client_id
+//   in: formData
+//   type: string
+// - name: client_secret
+//   in: formData
+//   type: string
+// - name: client_assertion_type
+//   in: formData
+//   type: string
+//   enum: [urn:ietf:params:oauth:client-assertion-type:jwt-bearer]
+// - name: client_assertion
+//   in: formData
+//   type: string
+// responses:
+//   '200':
+//     description: Successfully issued the access token.
+//     schema:
+//       "$ref": "#/definitions/OAuth2TokenResponse"
+//   '400':
+//     description: Invalid request, grant, grant type or scope.
+//     schema:
+//       "$ref": "#/definitions/OAuth2Error"
+//   '401':
+//     description: Client authentication failed.
+//     schema:
+//       "$ref": "#/definitions/OAuth2Error"
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/oauth2/token
+// x-sample-call-input: |
+//    grant_type=client_credentials&scope=HVS%3AReportRetriever&client_assertion_type=urn%3Aietf%3Aparams%3Aoauth%3Aclient-assertion-type%3Ajwt-bearer&client_assertion=eyJhbGciOiJFUzM4NCIsIng1YyI6WyJNSUlC...
+// x-sample-call-output: |
+//    {
+//       "access_token": "eyJhbGciOiJSUzM4NCIsImtpZCI6ImE5ODQ...",
+//       "token_type": "Bearer",
+//       "expires_in": 7200,
+//       "scope": "HVS:ReportRetriever"
+//    }
+// ---
@@ -37,7 +37,9 @@ type UserCred struct {
 //   Creates a new bearer token that can be used in the Authorization header for other API
 //   requests. Bearer token Authorization is not required when requesting token for Authservice
 //   admin user. Authservice admin user bearer token should be provided in Authorization header
-//   when requesting bearer token for other users.
+//   when requesting bearer token for other users. Users that enrolled a TOTP authenticator provide
+//   the TOTP code or one of their recovery codes in totp_code. Users whose password expired have to
+//   change it with /users/changepassword before they get a token.
 //
 // consumes:
 // - application/json
@@ -0,0 +1,97 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+package aas
+
+import "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+
+// AccountLockouts response payload
+// swagger:parameters AccountLockouts
+type AccountLockouts struct {
+	// in:body
+	Body []aas.AccountLockout
+}
+
+// swagger:operation GET /lockouts Lockouts getLockouts
+// ---
+// description: |
+//   Retrieves the users and clients that are locked out after too many failed authentications. A user or a client
+//   that fails to authenticate auth-defender max-attempts times within interval-mins is locked out for
+//   lockout-duration-mins. The lockouts are stored in the database, they are kept across restarts and shared by the
+//   AAS instances. A valid bearer token with the users:search permission should be provided to authorize this REST
+//   call.
+//
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the lockouts.
+//     schema:
+//       "$ref": "#/definitions/AccountLockouts"
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/lockouts
+// x-sample-call-output: |
+//    [
+//       {
+//          "username": "operator",
+//          "failed_attempts": 5,
+//          "locked_until": "2020-10-21T10:32:11.845193Z"
+//       },
+//       {
+//          "client_id": "5ac27f5c-6dfe-4c4e-96d1-8bb3b2c49b4e",
+//          "failed_attempts": 5,
+//          "locked_until": "2020-10-21T10:35:02.112407Z"
+//       }
+//    ]
+// ---
+
+// swagger:operation DELETE /users/{id}/lockout Lockouts unlockUser
+// ---
+// description: |
+//   Unlocks a user before its lockout expires, the failed authentications of the user are cleared.
+//   A valid bearer token with the users:store permission should be provided to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// parameters:
+// - name: id
+//   description: Unique ID of the user.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '204':
+//     description: Successfully unlocked the user.
+//   '404':
+//     description: User not found.
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/users/1fdb39de-7bf5-44c8-b6c7-b4a1e5a9e6c2/lockout
+// ---
+
+// swagger:operation DELETE /clients/{id}/lockout Lockouts unlockClient
+// ---
+// description: |
+//   Unlocks a client before its lockout expires, the failed authentications of the client are cleared.
+//   A valid bearer token with the clients:create permission should be provided to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// parameters:
+// - name: id
+//   description: Unique ID of the client.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '204':
+//     description: Successfully unlocked the client.
+//   '404':
+//     description: Client not found.
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/clients/5ac27f5c-6dfe-4c4e-96d1-8bb3b2c49b4e/lockout
+// ---
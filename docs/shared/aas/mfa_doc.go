@@ -0,0 +1,138 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+package aas
+
+import "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+
+// TotpEnrollmentRequest request payload
+// swagger:parameters TotpEnrollmentRequest
+type TotpEnrollmentRequest struct {
+	// in:body
+	Body aas.TotpEnrollmentRequest
+}
+
+// TotpEnrollment response payload
+// swagger:parameters TotpEnrollment
+type TotpEnrollment struct {
+	// in:body
+	Body aas.TotpEnrollment
+}
+
+// MfaRecoveryCodes response payload
+// swagger:parameters MfaRecoveryCodes
+type MfaRecoveryCodes struct {
+	// in:body
+	Body aas.MfaRecoveryCodes
+}
+
+// swagger:operation POST /users/mfa/totp Users enrollTotp
+// ---
+// description: |
+//   Starts the enrollment of a TOTP authenticator. The user authenticates with its password, a user that already
+//   enrolled an authenticator provides a code of the current authenticator in totp_code to replace it. The secret is
+//   entered in the authenticator app or scanned from a QR code of the key_uri. The new authenticator is used once
+//   the enrollment is confirmed. Users holding a role of the AAS_MFA_REQUIRED_ROLES do not get a token before they
+//   enrolled an authenticator.
+//
+// consumes:
+//  - application/json
+// produces:
+//  - application/json
+// parameters:
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//     "$ref": "#/definitions/TotpEnrollmentRequest"
+// responses:
+//   '201':
+//     description: Successfully started the enrollment.
+//     schema:
+//       "$ref": "#/definitions/TotpEnrollment"
+//   '401':
+//     description: Invalid credentials or TOTP code.
+//   '404':
+//     description: MFA is not enabled.
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/users/mfa/totp
+// x-sample-call-input: |
+//    {
+//       "username": "admin@aas",
+//       "password": "aasAdminPass"
+//    }
+// x-sample-call-output: |
+//    {
+//       "secret": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP",
+//       "key_uri": "otpauth://totp/AAS:admin@aas?algorithm=SHA1&digits=6&issuer=AAS&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+//    }
+// ---
+
+// swagger:operation PUT /users/mfa/totp Users confirmTotp
+// ---
+// description: |
+//   Confirms the enrollment of a TOTP authenticator with a code of the new authenticator. The recovery codes that
+//   replace the TOTP code when the authenticator is lost are returned, each of them can be used once. They can not be
+//   retrieved again.
+//
+// consumes:
+//  - application/json
+// produces:
+//  - application/json
+// parameters:
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//     "$ref": "#/definitions/TotpEnrollmentRequest"
+// responses:
+//   '200':
+//     description: Successfully enabled the authenticator.
+//     schema:
+//       "$ref": "#/definitions/MfaRecoveryCodes"
+//   '400':
+//     description: No enrollment was started.
+//   '401':
+//     description: Invalid credentials or TOTP code.
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/users/mfa/totp
+// x-sample-call-input: |
+//    {
+//       "username": "admin@aas",
+//       "password": "aasAdminPass",
+//       "totp_code": "287082"
+//    }
+// x-sample-call-output: |
+//    {
+//       "recovery_codes": [
+//          "k3x7q-m2pzd",
+//          "a6wne-r4c2h",
+//          "..."
+//       ]
+//    }
+// ---
+
+// swagger:operation DELETE /users/{id}/mfa Users deleteUserMfa
+// ---
+// description: |
+//   Removes the TOTP authenticator and the recovery codes of a user, so that a user who lost both can enroll again.
+//   A valid bearer token with the users:store permission should be provided to authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// parameters:
+// - name: id
+//   description: Unique ID of the user.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '204':
+//     description: Successfully removed the authenticator of the user.
+//   '404':
+//     description: User not found.
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/users/1fdb39de-7bf5-44c8-b6c7-b4a1e5a9e6c2/mfa
+// ---
@@ -0,0 +1,90 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+package aas
+
+import (
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+)
+
+// JSONWebKeySet response payload
+// swagger:parameters JSONWebKeySet
+type JSONWebKeySet struct {
+	// in:body
+	Body jwtauth.JSONWebKeySet
+}
+
+// OpenIDConfiguration response payload
+// swagger:parameters OpenIDConfiguration
+type OpenIDConfiguration struct {
+	// in:body
+	Body aas.OpenIDConfiguration
+}
+
+// swagger:operation GET /noauth/jwks JwtCertificate getJwks
+// ---
+// description: |
+//   Retrieves the public keys of the token signing certificates as a JWK set (RFC 7517). The kid of a key is the
+//   hex encoded SHA1 of its certificate as in the kid header of the tokens, x5c holds the certificate and its chain.
+//   A new signing certificate is published before tokens are signed with it, so that the services that validate the
+//   tokens can rotate to the new key by kid.
+//
+// produces:
+//  - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the JWK set.
+//     schema:
+//       "$ref": "#/definitions/JSONWebKeySet"
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/noauth/jwks
+// x-sample-call-output: |
+//    {
+//       "keys": [
+//          {
+//             "kty": "RSA",
+//             "use": "sig",
+//             "kid": "a9840a7d2b0f16a42de8d3ae1a8b9cefbd4d7b38",
+//             "alg": "RS384",
+//             "n": "nmuHcfi8N6rYOT2fwoQ-nGc1284STwjwZVBv3VPdMmBWUeY93K5AuWWAm_AW_4kYdWFS2AdcYC6QAfDUAx0mTEj3...",
+//             "e": "AQAB",
+//             "x5c": [
+//                "MIIENTCCAp2gAwIBAgIBAzANBgkqhkiG9w0BAQwFADBHMQswCQYDVQQGEwJVUzEL..."
+//             ]
+//          }
+//       ]
+//    }
+// ---
+
+// swagger:operation GET /.well-known/openid-configuration JwtCertificate getOpenIDConfiguration
+// ---
+// description: |
+//   Retrieves the OpenID Connect discovery document of AAS. It lists the token endpoint, the revocation endpoint,
+//   the JWK set of the token signing keys and the supported grant types and client authentication methods. The
+//   issuer is the iss claim of the tokens and can be configured with AAS_JWT_ISSUER.
+//
+// produces:
+//  - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the OpenID configuration.
+//     schema:
+//       "$ref": "#/definitions/OpenIDConfiguration"
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/.well-known/openid-configuration
+// x-sample-call-output: |
+//    {
+//       "issuer": "https://authservice.com:8444/aas",
+//       "jwks_uri": "https://authservice.com:8444/aas/noauth/jwks",
+//       "token_endpoint": "https://authservice.com:8444/aas/oauth2/token",
+//       "revocation_endpoint": "https://authservice.com:8444/aas/oauth2/revoke",
+//       "grant_types_supported": ["client_credentials", "password", "refresh_token"],
+//       "token_endpoint_auth_methods_supported": ["client_secret_basic", "client_secret_post", "private_key_jwt", "tls_client_auth"],
+//       "response_types_supported": ["token"],
+//       "subject_types_supported": ["public"],
+//       "id_token_signing_alg_values_supported": ["RS384"],
+//       "claims_supported": ["iss", "sub", "exp", "iat", "jti", "roles", "permissions"]
+//    }
+// ---
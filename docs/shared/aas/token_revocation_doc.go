@@ -0,0 +1,84 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+package aas
+
+import "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+
+// RevocationList response payload
+// swagger:parameters RevocationList
+type RevocationList struct {
+	// in:body
+	Body aas.RevocationList
+}
+
+// swagger:operation POST /oauth2/revoke Token revokeToken
+// ---
+// description: |
+//   Revokes an access token or a refresh token as defined in RFC 7009. A token can be revoked by its subject or by a
+//   user with the tokens:revoke permission of AAS. Revoked access tokens are rejected by the services once they have
+//   retrieved the revocation list. Unknown and expired tokens are ignored. A valid bearer token should be provided to
+//   authorize this REST call.
+//
+// security:
+//  - bearerAuth: []
+// consumes:
+//  - application/x-www-form-urlencoded
+// produces:
+//  - application/json
+// parameters:
+// - name: token
+//   in: formData
+//   required: true
+//   type: string
+// responses:
+//   '200':
+//     description: Successfully revoked the token.
+//   '400':
+//     description: Invalid request.
+//     schema:
+//       "$ref": "#/definitions/OAuth2Error"
+//   '403':
+//     description: Not allowed to revoke the token.
+//     schema:
+//       "$ref": "#/definitions/OAuth2Error"
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/oauth2/revoke
+// x-sample-call-input: |
+//    token=eyJhbGciOiJSUzM4NCIsImtpZCI6ImE5ODQ...
+// ---
+
+// swagger:operation GET /noauth/revoked-tokens Token getRevokedTokens
+// ---
+// description: |
+//   Retrieves the revoked tokens that have not expired yet. An entry with a token_id revokes a single access token,
+//   an entry with a subject_hash revokes every token issued before revoked_at to the subject whose hex encoded SHA-384
+//   digest is subject_hash. The subjects are not disclosed and revoked_at is a whole second, as the issue time of the
+//   tokens. The services that validate AAS tokens poll this list.
+//
+// produces:
+//  - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the revoked tokens.
+//     schema:
+//       "$ref": "#/definitions/RevocationList"
+//
+// x-sample-call-endpoint: https://authservice.com:8444/aas/noauth/revoked-tokens
+// x-sample-call-output: |
+//    {
+//       "revoked_tokens": [
+//          {
+//             "token_id": "8b1f6a7e-2c3d-4e5f-9a0b-1c2d3e4f5a6b",
+//             "revoked_at": "2020-10-12T09:14:22.419Z",
+//             "expires_at": "2020-10-12T11:14:22Z"
+//          },
+//          {
+//             "subject_hash": "5f1a2e3b6c9d0e4f7a8b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b",
+//             "revoked_at": "2020-10-12T09:20:04Z",
+//             "expires_at": "2020-10-12T11:20:04Z"
+//          }
+//       ]
+//    }
+// ---
@@ -79,8 +79,8 @@ type SwaggUserRolesResponse struct {
 // description: |
 //   Creates a new user in the Authservice database. User can be one among the service users,
 //   user with install permissions or administrative user. An appropriate username and password
-//   should be provided to create the user. A valid bearer token should be provided to
-//   authorize this REST call.
+//   should be provided to create the user, the password must satisfy the password policy of AAS.
+//   A valid bearer token should be provided to authorize this REST call.
 //
 // security:
 //  - bearerAuth: []
@@ -281,7 +281,10 @@ type SwaggUserRolesResponse struct {
 // swagger:operation PATCH /users/changepassword Users changePassword
 // ---
 // description: |
-//   Updates the password for the specified user in the Authservice database.
+//   Updates the password for the specified user in the Authservice database. The new password must satisfy the
+//   password policy of AAS: AAS_PASSWORD_MIN_LENGTH, AAS_PASSWORD_MIN_CHAR_CLASSES and AAS_PASSWORD_HISTORY_COUNT.
+//   Users whose password is older than AAS_PASSWORD_MAX_AGE_DAYS do not get a token until they changed it with
+//   this call.
 //
 // consumes:
 //  - application/json
@@ -294,6 +297,8 @@ type SwaggUserRolesResponse struct {
 // responses:
 //   '200':
 //     description: Successfully updated the user password.
+//   '400':
+//     description: Invalid request or the new password does not satisfy the password policy.
 //
 // x-sample-call-endpoint: https://authservice.com:8444/aas/users/changepassword
 // x-sample-call-input: |
@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package docs
+
+import "github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+
+// EabKey response payload
+// swagger:parameters EabKey
+type EabKey struct {
+	// in:body
+	Body cms.EabKey
+}
+
+// AcmeDirectory response payload
+// swagger:parameters AcmeDirectory
+type AcmeDirectory struct {
+	// in:body
+	Body cms.AcmeDirectory
+}
+
+// AcmeOrder response payload
+// swagger:parameters AcmeOrder
+type AcmeOrder struct {
+	// in:body
+	Body cms.AcmeOrder
+}
+
+// swagger:operation POST /acme/eab-keys ACME CreateEabKey
+// ---
+//
+// description: |
+//   Creates a single use external account binding (EAB) key for registering an ACME account with CMS. The key
+//   expires after acme.eab-key-validity-mins. The CertApprover roles with context in the bearer token are bound
+//   to the key and later decide which identifiers the ACME account can request certificates for.
+//
+//    | Attribute | Description |
+//    |-----------|-------------|
+//    | key_id    | Key identifier to be used as the kid of the external account binding JWS. |
+//    | hmac_key  | Base64url encoded HMAC key used to sign the external account binding JWS. |
+//    | expires   | Time after which the key can no longer be used. |
+//
+// x-permissions: CMS CertApprover role with context
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// parameters:
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '201':
+//     description: Successfully created the EAB key.
+//     schema:
+//       $ref: "#/definitions/EabKey"
+//   '401':
+//     description: Token does not contain a CertApprover role with context
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://cms.com:8445/cms/v1/acme/eab-keys
+// x-sample-call-output: |
+//    {
+//        "key_id": "0d6e1a0a-4c1b-4d2b-9f4f-8b3f1f0c5a2e",
+//        "hmac_key": "pX9Z1n2Jw2Q0o7b5m6c8r1t3v5x7z9A0B2C4D6E8F0G",
+//        "expires": "2020-06-09T09:05:47.4325463Z"
+//    }
+
+// ---
+
+// swagger:operation GET /acme/directory ACME AcmeDirectory
+// ---
+//
+// description: |
+//   Returns the ACME (RFC 8555) directory of CMS. The ACME endpoints are available when acme.enabled is set in
+//   the CMS configuration. Accounts must be registered with an external account binding created with
+//   POST /acme/eab-keys.
+//
+//   All other ACME resources (new-account, account/{id}, account/{id}/orders, new-order, order/{id},
+//   order/{id}/finalize, authz/{id}/{index}, challenge/{id}/{index} and cert/{id}) accept POST requests with a
+//   JWS body of content type application/jose+json as defined in RFC 8555. Authorizations of an order are valid
+//   on creation when the identifiers are covered by the SAN lists of the roles bound to the account (challenge
+//   type aas-role-01). Errors are returned as application/problem+json documents.
+//
+// produces:
+// - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the ACME directory.
+//     schema:
+//       $ref: "#/definitions/AcmeDirectory"
+//
+// x-sample-call-endpoint: https://cms.com:8445/cms/v1/acme/directory
+// x-sample-call-output: |
+//    {
+//        "newNonce": "https://cms.com:8445/cms/v1/acme/new-nonce",
+//        "newAccount": "https://cms.com:8445/cms/v1/acme/new-account",
+//        "newOrder": "https://cms.com:8445/cms/v1/acme/new-order",
+//        "meta": {
+//            "externalAccountRequired": true
+//        }
+//    }
+
+// ---
+
+// swagger:operation POST /acme/order/{orderId}/finalize ACME AcmeFinalizeOrder
+// ---
+//
+// description: |
+//   Finalizes an ACME order. The payload of the JWS contains the base64url encoded CSR, whose SANs must match the
+//   identifiers of the order. The certificate is issued by the TLS CA and can be downloaded from the certificate
+//   URL of the returned order as application/pem-certificate-chain.
+//
+// consumes:
+// - application/jose+json
+// produces:
+// - application/json
+// parameters:
+// - name: orderId
+//   description: Order identifier.
+//   in: path
+//   required: true
+//   type: string
+// responses:
+//   '200':
+//     description: Successfully finalized the order.
+//     schema:
+//       $ref: "#/definitions/AcmeOrder"
+//   '400':
+//     description: Invalid JWS or CSR provided
+//   '403':
+//     description: Order is not ready or does not belong to the account
+//   '404':
+//     description: Order not found
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://cms.com:8445/cms/v1/acme/order/2f6b7ad4-52d6-4b35-a1d5-3a3c18e2f9e1/finalize
+// x-sample-call-output: |
+//    {
+//        "status": "valid",
+//        "expires": "2020-06-10T08:05:47.4325463Z",
+//        "identifiers": [
+//            {
+//                "type": "dns",
+//                "value": "kbs.example.com"
+//            }
+//        ],
+//        "authorizations": [
+//            "https://cms.com:8445/cms/v1/acme/authz/2f6b7ad4-52d6-4b35-a1d5-3a3c18e2f9e1/0"
+//        ],
+//        "finalize": "https://cms.com:8445/cms/v1/acme/order/2f6b7ad4-52d6-4b35-a1d5-3a3c18e2f9e1/finalize",
+//        "certificate": "https://cms.com:8445/cms/v1/acme/cert/2f6b7ad4-52d6-4b35-a1d5-3a3c18e2f9e1"
+//    }
+// ---
@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package docs
+
+import "github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+
+type IssuedCertificates []cms.IssuedCertificate
+
+// IssuedCertificate response payload
+// swagger:parameters IssuedCertificate
+type IssuedCertificate struct {
+	// in:body
+	Body cms.IssuedCertificate
+}
+
+// IssuedCertificateCollection response payload
+// swagger:parameters IssuedCertificateCollection
+type IssuedCertificateCollection struct {
+	// in:body
+	Body IssuedCertificates
+}
+
+// swagger:operation GET /certificates/{serialNumber} Certificate RetrieveIssuedCertificate
+// ---
+//
+// description: |
+//   Retrieves the details of a certificate issued by CMS.
+// x-permissions: certificates:retrieve
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// parameters:
+// - name: serialNumber
+//   description: Hex encoded serial number of the issued certificate.
+//   in: path
+//   required: true
+//   type: string
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the issued certificate.
+//     schema:
+//       $ref: "#/definitions/IssuedCertificate"
+//   '404':
+//     description: Issued certificate record not found
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://cms.com:8445/cms/v1/certificates/1f
+// x-sample-call-output: |
+//    {
+//        "serial_number": "1f",
+//        "subject": "HVS TLS Certificate",
+//        "san_list": [
+//            "hvs.server.com",
+//            "10.1.2.3"
+//        ],
+//        "cert_type": "TLS",
+//        "issuing_ca": "TLS",
+//        "requested_by": "admin@hvs",
+//        "not_before": "2020-06-09T08:05:47Z",
+//        "not_after": "2021-06-09T08:05:47Z",
+//        "certificate": "-----BEGIN CERTIFICATE-----\nMIIEKTCCApGgAwIBAgIBHzANBgkqhkiG9w0BAQwFADBH...\n-----END CERTIFICATE-----\n"
+//    }
+
+// ---
+
+// swagger:operation GET /certificates Certificate SearchIssuedCertificates
+// ---
+//
+// description: |
+//   Searches for the certificates issued by CMS. The filters are combined, so a certificate is returned only if it
+//   matches all the query parameters provided.
+// x-permissions: certificates:search
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// parameters:
+// - name: certType
+//   description: The certificate type requested while signing the certificate. e.g TLS, TLS-Client, Flavor-Signing.
+//   in: query
+//   type: string
+//   required: false
+// - name: subjectEqualTo
+//   description: Returns certificates with the given subject common name.
+//   in: query
+//   type: string
+//   required: false
+// - name: subjectContains
+//   description: Returns certificates whose subject common name contains the given value.
+//   in: query
+//   type: string
+//   required: false
+// - name: issuingCa
+//   description: The CA that issued the certificates. One of root, TLS, TLS-Client or Signing.
+//   in: query
+//   type: string
+//   required: false
+// - name: expiringBefore
+//   description: Returns certificates that expire before the given RFC3339 timestamp.
+//   in: query
+//   type: string
+//   format: date-time
+//   required: false
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully searched the issued certificates.
+//     schema:
+//       $ref: "#/definitions/IssuedCertificates"
+//   '400':
+//     description: Invalid search criteria provided
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://cms.com:8445/cms/v1/certificates?certType=TLS&expiringBefore=2021-07-01T00:00:00Z
+// x-sample-call-output: |
+//    [
+//        {
+//            "serial_number": "1f",
+//            "subject": "HVS TLS Certificate",
+//            "san_list": [
+//                "hvs.server.com",
+//                "10.1.2.3"
+//            ],
+//            "cert_type": "TLS",
+//            "issuing_ca": "TLS",
+//            "requested_by": "admin@hvs",
+//            "not_before": "2020-06-09T08:05:47Z",
+//            "not_after": "2021-06-09T08:05:47Z",
+//            "certificate": "-----BEGIN CERTIFICATE-----\nMIIEKTCCApGgAwIBAgIBHzANBgkqhkiG9w0BAQwFADBH...\n-----END CERTIFICATE-----\n"
+//        }
+//    ]
+// ---
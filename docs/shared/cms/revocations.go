@@ -0,0 +1,253 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package docs
+
+import "github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+
+type RevokedCertificates []cms.RevokedCertificate
+
+// RevocationRequest request payload
+// swagger:parameters RevocationRequest
+type RevocationRequest struct {
+	// in:body
+	Body cms.RevocationRequest
+}
+
+// RevokedCertificate response payload
+// swagger:parameters RevokedCertificate
+type RevokedCertificate struct {
+	// in:body
+	Body cms.RevokedCertificate
+}
+
+// RevokedCertificateCollection response payload
+// swagger:parameters RevokedCertificateCollection
+type RevokedCertificateCollection struct {
+	// in:body
+	Body RevokedCertificates
+}
+
+// swagger:operation POST /revocations Revocations RevokeCertificate
+// ---
+//
+// description: |
+//   Revokes a certificate issued by CMS. The CRL of the issuing CA is republished as part of the request.
+//
+//    | Attribute     | Description |
+//    |---------------|-------------|
+//    | serial_number | Hex encoded serial number of the certificate to be revoked. |
+//    | issuing_ca    | (Optional) The CA that issued the certificate. One of root, TLS, TLS-Client or Signing. The CA is taken from the issued certificate inventory, the request is rejected when it does not match. Required for the certificates that are not in the inventory. |
+//    | reason        | (Optional) Revocation reason. One of unspecified, keyCompromise, cACompromise, affiliationChanged, superseded, cessationOfOperation or privilegeWithdrawn. Defaults to unspecified. |
+//
+// x-permissions: revocations:create
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// consumes:
+// - application/json
+// parameters:
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//    "$ref": "#/definitions/RevocationRequest"
+// - name: Content-Type
+//   description: Content-Type header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '201':
+//     description: Successfully revoked the certificate.
+//     schema:
+//       $ref: "#/definitions/RevokedCertificate"
+//   '400':
+//     description: Invalid request body provided
+//   '409':
+//     description: Certificate is already revoked
+//   '415':
+//     description: Invalid Content-Type/Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://cms.com:8445/cms/v1/revocations
+// x-sample-call-input: |
+//    {
+//        "serial_number": "1f",
+//        "issuing_ca": "TLS",
+//        "reason": "keyCompromise"
+//    }
+// x-sample-call-output: |
+//    {
+//        "serial_number": "1f",
+//        "issuing_ca": "TLS",
+//        "reason": "keyCompromise",
+//        "revoked_at": "2020-06-09T08:05:47.4325463Z"
+//    }
+
+// ---
+
+// swagger:operation GET /revocations/{serialNumber} Revocations RetrieveRevokedCertificate
+// ---
+//
+// description: |
+//   Retrieves the revocation details of a certificate.
+// x-permissions: revocations:retrieve
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// parameters:
+// - name: serialNumber
+//   description: Hex encoded serial number of the revoked certificate.
+//   in: path
+//   required: true
+//   type: string
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the revoked certificate.
+//     schema:
+//       $ref: "#/definitions/RevokedCertificate"
+//   '404':
+//     description: Revoked certificate record not found
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://cms.com:8445/cms/v1/revocations/1f
+// x-sample-call-output: |
+//    {
+//        "serial_number": "1f",
+//        "issuing_ca": "TLS",
+//        "reason": "keyCompromise",
+//        "revoked_at": "2020-06-09T08:05:47.4325463Z"
+//    }
+
+// ---
+
+// swagger:operation GET /revocations Revocations SearchRevokedCertificates
+// ---
+//
+// description: |
+//   Searches for revoked certificates.
+// x-permissions: revocations:search
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// parameters:
+// - name: issuingCa
+//   description: The CA that issued the revoked certificates. One of root, TLS, TLS-Client or Signing.
+//   in: query
+//   type: string
+//   required: false
+// - name: revokedBefore
+//   description: Returns certificates revoked before the given RFC3339 timestamp.
+//   in: query
+//   type: string
+//   format: date-time
+//   required: false
+// - name: revokedAfter
+//   description: Returns certificates revoked after the given RFC3339 timestamp.
+//   in: query
+//   type: string
+//   format: date-time
+//   required: false
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully searched the revoked certificates.
+//     schema:
+//       $ref: "#/definitions/RevokedCertificates"
+//   '400':
+//     description: Invalid search criteria provided
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://cms.com:8445/cms/v1/revocations?issuingCa=TLS
+// x-sample-call-output: |
+//    [
+//        {
+//            "serial_number": "1f",
+//            "issuing_ca": "TLS",
+//            "reason": "keyCompromise",
+//            "revoked_at": "2020-06-09T08:05:47.4325463Z"
+//        }
+//    ]
+
+// ---
+
+// swagger:operation GET /crl CRL GetCrl
+// ---
+//
+// description: |
+//   Retrieves the DER encoded certificate revocation list signed by an issuing CA. This is the CRL distribution
+//   point embedded in certificates issued by CMS when revocation.base-url is configured.
+//
+// produces:
+// - application/pkix-crl
+// parameters:
+// - name: issuingCa
+//   description: The issuing CA. One of root, TLS, TLS-Client or Signing. Defaults to root.
+//   in: query
+//   type: string
+//   required: false
+// responses:
+//   '200':
+//     description: Successfully retrieved the CRL.
+//   '400':
+//     description: Invalid issuing CA provided
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://cms.com:8445/cms/v1/crl?issuingCa=TLS
+
+// ---
+
+// swagger:operation POST /ocsp OCSP OcspRequest
+// ---
+//
+// description: |
+//   OCSP responder (RFC 6960) for certificates issued by CMS. The response is signed by the CA that issued the
+//   certificate. The request can also be sent base64 encoded in the path of a GET request to /ocsp/{request}.
+//
+// consumes:
+// - application/ocsp-request
+// produces:
+// - application/ocsp-response
+// responses:
+//   '200':
+//     description: OCSP response with good, revoked or unknown certificate status.
+//   '415':
+//     description: Invalid Content-Type Header in Request
+//
+// x-sample-call-endpoint: https://cms.com:8445/cms/v1/ocsp
+// ---
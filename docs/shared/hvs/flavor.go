@@ -160,6 +160,7 @@ type SignedFlavorCollection struct {
 //    | connection_string              | (Optional) The host connection string. flavorgroup_names, partial_flavor_types can be provided as optional parameters along with the host connection string. |
 //    |                                | For INTEL hosts, this would have the vendor name, the IP addresses, or DNS host name and credentials i.e.: "intel:https://trustagent.server.com:1443 |
 //    |                                | For VMware, this includes the vCenter and host IP address or DNS host name i.e.: "vmware:https://vCenterServer.com:443/sdk;h=host;u=vCenterUsername;p=vCenterPassword" |
+//    |                                | For hosts running a gRPC quote agent, this has the grpc prefix and the agent URL i.e.: "grpc:https://agent.server.com:1443" |
 //    | flavors                        | (Optional) A collection of flavors in the defined flavor format. No other parameters are needed in this case.
 //    | signed_flavors                 | (Optional) This is collection of signed flavors consisting of flavor and signature provided by user. |
 //    | flavorgroup_names              | (Optional) Flavor group names that the created flavor(s) will be associated with. If not provided, created flavor will be associated with automatic flavor group. |
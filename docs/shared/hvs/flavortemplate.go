@@ -120,6 +120,7 @@ type FlavorTemplate struct {
 //    | ID                             | Unique ID of flavor template. |
 //    | Label                          | Name of the flavortemplate to be created. |
 //    | Condition                      | The “condition” uses meta-data from the host-manifest to determine if the flavor-template should be applied. An array of 'jsonquery' statements that are used to determine if the template should be executed. For example, “if TBOOT is installed”, use the information in the child “flavor-parts” to copy event-logs from the manifest’s PCR 17 & 18 to the PLATFORM flavor-part. |
+//    | ConditionExpression            | A Common Expression Language (CEL) expression over the host-manifest, available as “host_manifest”, that must evaluate to true for the flavor-template to be applied. It can be used instead of, or together with, the “condition”. For example, “host_manifest.host_info.os_name == 'RedHatEnterprise' && host_manifest.host_info.hardware_features.TPM.meta.tpm_version == '2.0'”. The expression is compiled when the template is created and syntax or type errors are reported in the response. |
 //    | FlavorParts                    | One or more flavor-part entities that are generated by the template. |
 //
 //   FlavorParts: The type or classification of the flavor. For more information on flavor parts, see the
@@ -139,6 +140,7 @@ type FlavorTemplate struct {
 //    | PcrMatches                     | Setting ‘pcr_matches’ to true in the flavor-template will update the flavor-part to enforce “PCR Matches Constants” rules during flavor verfication. |
 //    | EventLogEquals                 | Event log equals contains “eventlog_equals” section will update the flavor-part to enforce “PCR Event Log Equals” rules during verification.  The optional “excluding_tags” element can be used to omit events with a one or more “tags” during verification. |
 //    | EventLogIncludes               | EventLogInclude contains “eventlog_includes” section will update the flavor-part to enforce “PCR Event Log Includes” rules during verification. |
+//    | EventLogReplayAllBanks         | Setting “eventlog_replay_all_banks” to true will update the flavor-part to enforce “PCR Event Log Replay All Banks” rules during verification, which replay the event log of the PCR index in every bank of the quote (SHA1, SHA256, SHA384 and SHA512) and report the first divergent event of each bank that does not match its PCR value. |
 //
 //   Creates a Flavor template and stores it in the database.
 //
@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package hvs
+
+import "github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+
+// HostQuoteNonce response payload
+// swagger:parameters HostQuoteNonce
+type HostQuoteNonce struct {
+	// in:body
+	Body hvs.HostQuoteNonce
+}
+
+// HostQuote request payload
+// swagger:parameters HostQuote
+type HostQuote struct {
+	// in:body
+	Body hvs.HostQuote
+}
+
+// ---
+
+// swagger:operation POST /hosts/{host_id}/quote-nonce Hosts CreateHostQuoteNonce
+// ---
+//
+// description: |
+//   Creates the nonce that a push-mode host has to include in its next quote. The nonce can be used once and expires
+//   after five minutes. Requesting a new nonce invalidates the previous one of the host.
+//
+// x-permissions: host_quotes:create
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// parameters:
+// - name: host_id
+//   description: Unique ID of the host.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '201':
+//     description: Successfully created the nonce.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/HostQuoteNonce"
+//   '400':
+//     description: Host is not a push-mode host
+//   '404':
+//     description: Host record not found
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/hosts/fc0cc779-22b6-4741-b0d9-e2e69635ad1e/quote-nonce
+// x-sample-call-output: |
+//    {
+//        "nonce": "tHgfRQED1+pYgEZpq3dZC9ONmBCZ",
+//        "expiration": "2020-09-03T10:15:51.118513-07:00"
+//    }
+
+// ---
+
+// swagger:operation POST /hosts/{host_id}/quotes Hosts SubmitHostQuote
+// ---
+//
+// description: |
+//   Submits the TPM quote of a push-mode host. The quote must be taken over the nonce issued to the host and signed by
+//   an AIK certified by the HVS Privacy CA. The first accepted quote binds the host to its hardware UUID and AIK, later
+//   quotes reporting another hardware UUID or signed by another AIK are rejected. The verified host manifest is stored
+//   as the host status and the host is added to the flavor verification queue.
+//
+//   The serialized HostQuote Go struct object represents the content of the request body.
+//
+//    | Attribute         | Description |
+//    |-------------------|-------------|
+//    | nonce             | Nonce issued by HVS for this quote. |
+//    | quote             | Base64 encoded TPMS_ATTEST structure of the quote. |
+//    | signature         | Base64 encoded TPMT_SIGNATURE of the quote. |
+//    | pcr_values        | Base64 encoded values of the quoted PCRs, in the order of the PCR selection of the quote. |
+//    | aik_certificate   | Base64 encoded DER AIK certificate. |
+//    | event_log         | Measure log of the host in the Trust Agent format, or the base64 encoded TCG binary event log (binary_bios_measurements) of the host. |
+//    | host_info         | Host information in the Trust Agent format. |
+//
+// x-permissions: host_quotes:create
+// security:
+//  - bearerAuth: []
+// consumes:
+// - application/json
+// parameters:
+// - name: host_id
+//   description: Unique ID of the host.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//    "$ref": "#/definitions/HostQuote"
+// - name: Content-Type
+//   description: Content-Type header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '202':
+//     description: Successfully verified the quote and queued the host for flavor verification.
+//   '400':
+//     description: Invalid request body, nonce or quote provided, or the hardware UUID or AIK does not match the host
+//   '404':
+//     description: Host record not found
+//   '415':
+//     description: Invalid Content-Type Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/hosts/fc0cc779-22b6-4741-b0d9-e2e69635ad1e/quotes
+// x-sample-call-input: |
+//    {
+//        "nonce": "tHgfRQED1+pYgEZpq3dZC9ONmBCZ",
+//        "quote": "/1RDR4AYACIAC...",
+//        "signature": "ABQACwEAW...",
+//        "pcr_values": "AAAAAAAAA...",
+//        "aik_certificate": "MIIDTjCCAbagAwIBAgIG...",
+//        "event_log": "[]",
+//        "host_info": {
+//            "os_name": "RedHatEnterprise",
+//            "host_name": "edge-host1",
+//            "hardware_uuid": "80ecce40-04b8-e811-906e-00163566263e"
+//        }
+//    }
@@ -60,6 +60,8 @@ type HostFlavorgroupCreateRequest struct {
 //   "intel:https://trustagent.server.com:1443"</br>
 //   For VMware, this includes the vCenter and host IP address or DNS host name and credentials. e.g.:
 //   "vmware:https://vCenterServer.com:443/sdk;h=trustagent.server.com;u=vCenterUsername;p=vCenterPassword"</br>
+//   For hosts running a gRPC quote agent, this has the grpc prefix and the agent URL. e.g.:
+//   "grpc:https://agent.server.com:1443"</br>
 //   </pre>
 //
 //   <b>Creates a host.</b>
@@ -67,6 +69,7 @@ type HostFlavorgroupCreateRequest struct {
 //   A connection string and name for the host must be specified. This name is the value the Host Verification Service (HVS) uses to keep track of the host. It does not have to be the actual host name or IP address of the server.</br>
 //   If a flavor group is not specified, the host created will be assigned to the default “automatic” flavor group. If a flavor group is specified and does not already exist, it will be created with a default flavor match policy.</br>
 //   Once the host is created, it is added to the flavor verification queue in backend.</br>
+//   Hosts that HVS cannot connect to, e.g. hosts behind NAT, are created with push_mode set and without a connection string. They submit their quotes through the /hosts/{host_id}/quotes API and are verified when a quote is received.</br>
 //   </pre>
 //
 //   The serialized HostCreateRequest Go struct object represents the content of the request body.
@@ -77,6 +80,7 @@ type HostFlavorgroupCreateRequest struct {
 //    | connection_string | The host connection string. |
 //    | flavorgroup_names | List of flavor group names that the created host will be associated. |
 //    | description       | Host description. |
+//    | push_mode         | The host submits its quotes to HVS instead of being polled. |
 //
 // x-permissions: hosts:create
 // security:
@@ -0,0 +1,351 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package hvs
+
+import "github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+
+// NotificationSubscriptionCreateRequest request payload
+// swagger:parameters NotificationSubscriptionCreateRequest
+type NotificationSubscriptionCreateRequest struct {
+	// in:body
+	Body hvs.NotificationSubscriptionCreateRequest
+}
+
+// NotificationSubscription response payload
+// swagger:parameters NotificationSubscription
+type NotificationSubscription struct {
+	// in:body
+	Body hvs.NotificationSubscription
+}
+
+// NotificationSubscriptionCollection response payload
+// swagger:parameters NotificationSubscriptionCollection
+type NotificationSubscriptionCollection struct {
+	// in:body
+	Body hvs.NotificationSubscriptionCollection
+}
+
+// NotificationDeliveryCollection response payload
+// swagger:parameters NotificationDeliveryCollection
+type NotificationDeliveryCollection struct {
+	// in:body
+	Body hvs.NotificationDeliveryCollection
+}
+
+// HostTrustChange webhook payload
+// swagger:parameters HostTrustChange
+type HostTrustChange struct {
+	// in:body
+	Body hvs.HostTrustChange
+}
+
+// ---
+
+// swagger:operation POST /notification-subscriptions NotificationSubscriptions Create-NotificationSubscription
+// ---
+//
+// description: |
+//   Subscribes a webhook to the trust changes of the hosts. When a new trust report of a host changes its overall
+//   trust or the trust of one of its flavor parts compared to the previous report, HVS posts a host_trust_changed
+//   event to the URL of each matching subscription. The first report of a host is not notified.
+//
+//    | Attribute         | Description                                                                      |
+//    |-------------------|----------------------------------------------------------------------------------|
+//    | url               | https URL the events are posted to.                                              |
+//    | host_ids          | (Optional) Hosts whose trust changes are notified.                               |
+//    | flavorgroup_ids   | (Optional) Flavorgroups, the changes of the hosts in one of them are notified.   |
+//    | flavor_parts      | (Optional) Flavor parts, the changes where one of them changed are notified.     |
+//
+//   An empty filter matches every change. The events are signed with the secret returned in the response, which is
+//   not returned again: the X-Hvs-Signature header holds sha256= followed by the hex encoded HMAC-SHA256 of the body
+//   keyed with the secret. The X-Hvs-Event and X-Hvs-Delivery headers hold the event and the ID of the delivery.
+//   A delivery is successful when the URL responds with a 2xx status, otherwise it is retried with an exponential
+//   backoff, 5 attempts in total. Redirects are not followed.
+//
+//   The URL must not target a loopback, link-local or private address, the address is also checked when posting the
+//   events. Private networks can be allowed with the notification allowed-networks setting of the HVS configuration.
+//
+// x-permissions: notification_subscriptions:create
+// security:
+//  - bearerAuth: []
+// consumes:
+//  - application/json
+// produces:
+//  - application/json
+// parameters:
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//     "$ref": "#/definitions/NotificationSubscriptionCreateRequest"
+// - name: Content-Type
+//   description: Content-Type header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '201':
+//     description: Successfully created the notification subscription.
+//     schema:
+//       "$ref": "#/definitions/NotificationSubscription"
+//   '400':
+//     description: Invalid request body provided
+//   '415':
+//     description: Invalid Content-Type/Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/notification-subscriptions
+// x-sample-call-input: |
+//    {
+//        "url": "https://hooks.example.com/trust",
+//        "flavorgroup_ids": ["826501bd-3c75-4839-a08f-db5f744f8498"],
+//        "flavor_parts": ["PLATFORM", "OS"]
+//    }
+// x-sample-call-output: |
+//    {
+//        "id": "2f1c7a4e-5d0b-4a8e-9f3e-1b6c2d7e8a90",
+//        "url": "https://hooks.example.com/trust",
+//        "secret": "4f5d1b0d9f2c3e8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a",
+//        "flavorgroup_ids": ["826501bd-3c75-4839-a08f-db5f744f8498"],
+//        "flavor_parts": ["PLATFORM", "OS"],
+//        "created": "2020-10-20T08:12:45.132456Z"
+//    }
+// x-sample-webhook-payload: |
+//    {
+//        "event": "host_trust_changed",
+//        "host_id": "ee37c360-7eae-4250-a677-6ee12adce8e2",
+//        "host_name": "edge-1",
+//        "flavorgroup_ids": ["826501bd-3c75-4839-a08f-db5f744f8498"],
+//        "trusted": false,
+//        "previously_trusted": true,
+//        "flavor_parts": [
+//            {
+//                "flavor_part": "OS",
+//                "trusted": false,
+//                "previously_trusted": true
+//            }
+//        ],
+//        "report_id": "0b8a8c0e-3c29-4a2b-8f52-4f8f7bbd2a51",
+//        "time": "2020-10-20T09:02:11.521448Z"
+//    }
+
+// ---
+
+// swagger:operation GET /notification-subscriptions NotificationSubscriptions Search-NotificationSubscriptions
+// ---
+//
+// description: |
+//   Searches for notification subscriptions, the secrets are not returned. The filters select the subscriptions that
+//   explicitly filter on the given host, flavorgroup or flavor part.
+//
+// x-permissions: notification_subscriptions:search
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: hostId
+//   description: Host ID
+//   in: query
+//   type: string
+//   format: uuid
+//   required: false
+// - name: flavorgroupId
+//   description: Flavorgroup ID
+//   in: query
+//   type: string
+//   format: uuid
+//   required: false
+// - name: flavorPart
+//   description: Flavor part
+//   in: query
+//   type: string
+//   required: false
+//   enum: [PLATFORM, OS, HOST_UNIQUE, SOFTWARE, ASSET_TAG]
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully searched the notification subscriptions.
+//     schema:
+//       "$ref": "#/definitions/NotificationSubscriptionCollection"
+//   '400':
+//     description: Invalid search criteria provided
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/notification-subscriptions?flavorPart=OS
+// x-sample-call-output: |
+//    {
+//        "notification_subscriptions": [
+//            {
+//                "id": "2f1c7a4e-5d0b-4a8e-9f3e-1b6c2d7e8a90",
+//                "url": "https://hooks.example.com/trust",
+//                "flavorgroup_ids": ["826501bd-3c75-4839-a08f-db5f744f8498"],
+//                "flavor_parts": ["PLATFORM", "OS"],
+//                "created": "2020-10-20T08:12:45.132456Z"
+//            }
+//        ]
+//    }
+
+// ---
+
+// swagger:operation GET /notification-subscriptions/{subscription_id} NotificationSubscriptions Retrieve-NotificationSubscription
+// ---
+//
+// description: |
+//   Retrieves a notification subscription, the secret is not returned.
+//
+// x-permissions: notification_subscriptions:retrieve
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: subscription_id
+//   description: Unique ID of the notification subscription.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the notification subscription.
+//     schema:
+//       "$ref": "#/definitions/NotificationSubscription"
+//   '404':
+//     description: Notification subscription with given ID does not exist
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/notification-subscriptions/2f1c7a4e-5d0b-4a8e-9f3e-1b6c2d7e8a90
+
+// ---
+
+// swagger:operation GET /notification-subscriptions/{subscription_id}/deliveries NotificationSubscriptions Search-NotificationDeliveries
+// ---
+//
+// description: |
+//   Retrieves the log of the deliveries to a notification subscription, the most recent first. Each delivery records
+//   the number of attempts, the status code of the last response and the error of the last failed attempt.
+//
+// x-permissions: notification_subscriptions:retrieve
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: subscription_id
+//   description: Unique ID of the notification subscription.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: delivered
+//   description: Returns only the successful or only the failed deliveries.
+//   in: query
+//   type: boolean
+//   required: false
+// - name: limit
+//   description: Maximum number of deliveries returned, 100 by default.
+//   in: query
+//   type: integer
+//   required: false
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the notification deliveries.
+//     schema:
+//       "$ref": "#/definitions/NotificationDeliveryCollection"
+//   '400':
+//     description: Invalid search criteria provided
+//   '404':
+//     description: Notification subscription with given ID does not exist
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/notification-subscriptions/2f1c7a4e-5d0b-4a8e-9f3e-1b6c2d7e8a90/deliveries?delivered=false
+// x-sample-call-output: |
+//    {
+//        "notification_deliveries": [
+//            {
+//                "id": "c6a2b1d0-3e4f-4a5b-9c8d-7e6f5a4b3c2d",
+//                "subscription_id": "2f1c7a4e-5d0b-4a8e-9f3e-1b6c2d7e8a90",
+//                "host_id": "ee37c360-7eae-4250-a677-6ee12adce8e2",
+//                "event": "host_trust_changed",
+//                "attempts": 5,
+//                "status_code": 503,
+//                "delivered": false,
+//                "error": "Unexpected response status 503",
+//                "created": "2020-10-20T09:02:11.603214Z",
+//                "updated": "2020-10-20T09:04:46.711032Z"
+//            }
+//        ]
+//    }
+
+// ---
+
+// swagger:operation DELETE /notification-subscriptions/{subscription_id} NotificationSubscriptions Delete-NotificationSubscription
+// ---
+//
+// description: |
+//   Deletes a notification subscription and its delivery log.
+//
+// x-permissions: notification_subscriptions:delete
+// security:
+//  - bearerAuth: []
+// parameters:
+// - name: subscription_id
+//   description: Unique ID of the notification subscription.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// responses:
+//   '204':
+//     description: Successfully deleted the notification subscription.
+//   '404':
+//     description: Notification subscription with given ID does not exist
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/notification-subscriptions/2f1c7a4e-5d0b-4a8e-9f3e-1b6c2d7e8a90
+
+// ---
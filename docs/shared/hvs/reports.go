@@ -28,6 +28,13 @@ type ReportCreateRequest struct {
 	Body hvs.ReportCreateRequest
 }
 
+// ReportSummary event payload
+// swagger:parameters ReportSummary
+type ReportSummary struct {
+	// in:body
+	Body hvs.ReportSummary
+}
+
 // ---
 
 // swagger:operation GET /reports Reports Search-Reports
@@ -46,6 +53,11 @@ type ReportCreateRequest struct {
 //
 //   <b>Searches for reports</b>
 //
+//   The reports:search permission can be restricted by constraints set in the context of the role holding it, e.g.
+//   flavorgroup=automatic,prod-*;host=edge-*. Only the reports of the hosts in one of the flavorgroups, given by ID or
+//   name, and whose host name matches are returned then. A value ending with * matches a prefix. A context that
+//   constrains neither flavorgroup nor host does not restrict the permission.
+//
 // x-permissions: reports:search
 // security:
 //  - bearerAuth: []
@@ -218,6 +230,8 @@ type ReportCreateRequest struct {
 //    | host_name                      | hostname of host |
 //    | hardware_uuid                  | Hardware UUID of host |
 //
+//   The reports:create permission can be restricted to the hosts of given flavorgroups and host names by constraints
+//   set in the context of the role holding it, e.g. flavorgroup=automatic,prod-*.
 //
 // x-permissions: reports:create
 // security:
@@ -329,6 +343,8 @@ type ReportCreateRequest struct {
 //
 // description: |
 //   Retrieves a report.
+//   The reports:retrieve permission can be restricted to the hosts of given flavorgroups and host names by constraints
+//   set in the context of the role holding it, e.g. flavorgroup=automatic,prod-*.
 //   Returns - The serialized Report Go struct object that was retrieved.
 // x-permissions: reports:retrieve
 // security:
@@ -420,3 +436,66 @@ type ReportCreateRequest struct {
 //       "expiration": "2018-07-23T17:39:52-0700"
 //     }
 //   }
+
+// ---
+
+// swagger:operation GET /reports/stream Reports Stream-Reports
+// ---
+//
+// description: |
+//   Streams the summaries of the reports as server-sent events as the flavor verification process stores them. Each
+//   report event holds the creation time and the ID of the report as event ID, in the form <creation time>_<report ID>,
+//   and the summary of the report, with the overall trust of the host, the trust of each flavor part and the faults,
+//   as data. A comment is sent every 15 seconds to keep the connection alive, and the stream is closed before the
+//   server write timeout.
+//   A client reconnecting with the ID of the last event it received, in the Last-Event-ID header or the lastEventId
+//   parameter, first receives the reports stored since the creation time of the report of that event, the oldest
+//   first, including the reports that have been replaced by a newer report of the same host since. At most 10000
+//   reports are replayed: when more were missed, a truncated event holding the number of replayed reports and the ID
+//   of the last of them is sent and the stream is closed, so that the client reconnects to receive the rest. An event
+//   ID holding the report ID alone, as sent by the previous releases, is still accepted.
+//   The reports:search permission can be restricted to the hosts of given flavorgroups and host names by constraints
+//   set in the context of the role holding it, only the reports of the allowed hosts are then sent.
+// x-permissions: reports:search
+// security:
+//  - bearerAuth: []
+// produces:
+// - text/event-stream
+// parameters:
+// - name: lastEventId
+//   description: ID of the last event received, the Last-Event-ID header takes precedence.
+//   in: query
+//   type: string
+//   required: false
+// - name: Last-Event-ID
+//   description: ID of the last event received.
+//   in: header
+//   type: string
+//   required: false
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - text/event-stream
+// responses:
+//   '200':
+//     description: Successfully opened the report stream, the data of the events is a ReportSummary.
+//     schema:
+//       $ref: "#/definitions/ReportSummary"
+//   '400':
+//     description: Invalid last event ID provided
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error.
+// x-sample-call-endpoint: https://hvs.com:8443/hvs/v2/reports/stream
+// x-sample-call-output: |
+//   retry: 3000
+//
+//   id: 2020-06-21T07:18:00.57Z_8a545a4f-d282-4d91-8ec5-bcbe439dcfbc
+//   event: report
+//   data: {"id":"8a545a4f-d282-4d91-8ec5-bcbe439dcfbc","host_id":"94824cb6-d6c8-4faf-83b0-125996ceebe2","host_name":"computepurley1","OVERALL":false,"flavors_trust":{"OS":true,"PLATFORM":false},"faults":[{"rule_name":"com.intel.mtwilson.core.verifier.policy.rule.PcrMatchesConstant","markers":["PLATFORM"],"fault_name":"com.intel.mtwilson.core.verifier.policy.fault.PcrValueMismatchSHA256","description":"Host PCR 0 with value '3f95ecbb...' does not match expected value"}],"created":"2020-06-21T07:18:00.57Z","expiration":"2020-06-22T07:18:00.57Z"}
+//
+//   : keep-alive
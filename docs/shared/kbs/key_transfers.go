@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package kbs
+
+import "github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
+
+type KeyTransferRecords []kbs.KeyTransferRecord
+
+// KeyTransferRecordCollection response payload
+// swagger:parameters KeyTransferRecordCollection
+type KeyTransferRecordCollection struct {
+	// in:body
+	Body KeyTransferRecords
+}
+
+// swagger:operation GET /key-transfers KeyTransfers SearchKeyTransfers
+// ---
+//
+// description: |
+//   Searches for the records of the key transfers.
+//   <pre>
+//   A record is stored for every attempt to transfer a key with the public key, the SAML report or the
+//   SKC library. The record holds the requester, the attestation type, the transfer policy of the key, the
+//   decision taken (allowed, denied or challenged), the reason of a refusal and the version of the
+//   transferred key. The latest records are returned first. When the KBS stores its data in directories, only
+//   the latest records are kept, 100000 by default as configured with KEY_TRANSFER_RECORDS_MAX_COUNT. The
+//   postgres store keeps all the records.
+//   </pre>
+//   Returns - The collection of serialized KeyTransferRecord Go struct objects.
+// x-permissions: key_transfers:search
+// security:
+//  - bearerAuth: []
+// produces:
+//  - application/json
+// parameters:
+// - name: keyId
+//   description: Unique identifier of the key.
+//   in: query
+//   type: string
+//   format: uuid
+//   required: false
+// - name: requester
+//   description: JWT subject, client certificate common name or host hardware UUID of the requester.
+//   in: query
+//   type: string
+//   required: false
+// - name: attestationType
+//   description: Attestation type of the transfer.
+//   in: query
+//   type: string
+//   required: false
+//   enum: [NONE, SAML, SW, SGX]
+// - name: decision
+//   description: Decision taken on the transfer.
+//   in: query
+//   type: string
+//   required: false
+//   enum: [allowed, denied, challenged]
+// - name: fromDate
+//   description: Records created at or after the date (YYYY-MM-DDThh:mm:ssZ).
+//   in: query
+//   type: string
+//   format: date-time
+//   required: false
+// - name: toDate
+//   description: Records created at or before the date (YYYY-MM-DDThh:mm:ssZ).
+//   in: query
+//   type: string
+//   format: date-time
+//   required: false
+// - name: limit
+//   description: Maximum number of records returned, the latest first. Defaults to 1000, at most 10000.
+//   in: query
+//   type: integer
+//   required: false
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the key transfer records.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/KeyTransferRecords"
+//   '400':
+//     description: Invalid values for request params
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://kbs.com:9443/kbs/v1/key-transfers?keyId=fc0cc779-22b6-4741-b0d9-e2e69635ad1e
+// x-sample-call-output: |
+//    [
+//        {
+//            "id": "5a1c6f34-5e2c-4b3b-9e60-2e8c6e3f5f2a",
+//            "key_id": "fc0cc779-22b6-4741-b0d9-e2e69635ad1e",
+//            "requester": "8032632b-8fa4-e811-906e-00163566263e",
+//            "attestation_type": "SAML",
+//            "transfer_policy_id": "3ce27bbd-3c5f-4b15-8c0a-44310f0f83d9",
+//            "decision": "denied",
+//            "reason": "Host is not trusted",
+//            "created_at": "2020-09-23T11:20:03.150146Z"
+//        },
+//        {
+//            "id": "0f6a2b5e-0dd5-4c4c-9a3f-8a7b9b8c1d2e",
+//            "key_id": "fc0cc779-22b6-4741-b0d9-e2e69635ad1e",
+//            "requester": "admin@kbs",
+//            "attestation_type": "NONE",
+//            "transfer_policy_id": "3ce27bbd-3c5f-4b15-8c0a-44310f0f83d9",
+//            "decision": "allowed",
+//            "version": 1,
+//            "created_at": "2020-09-23T11:16:26.738467Z"
+//        }
+//    ]
+
+// ---
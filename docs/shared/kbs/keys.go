@@ -36,6 +36,29 @@ type KeyTransferAttributes struct {
 	Body kbs.KeyTransferAttributes
 }
 
+type KeyVersionResponses []kbs.KeyVersionResponse
+
+// KeyRotate request payload
+// swagger:parameters KeyRotateRequest
+type KeyRotateRequest struct {
+	// in:body
+	Body kbs.KeyRotateRequest
+}
+
+// KeyUsagePolicy request payload
+// swagger:parameters KeyUsagePolicy
+type KeyUsagePolicy struct {
+	// in:body
+	Body kbs.KeyUsagePolicy
+}
+
+// KeyVersionCollection response payload
+// swagger:parameters KeyVersionCollection
+type KeyVersionCollection struct {
+	// in:body
+	Body KeyVersionResponses
+}
+
 // ---
 
 // swagger:operation POST /keys Keys CreateKey
@@ -52,6 +75,10 @@ type KeyTransferAttributes struct {
 //    | transfer_policy_id | Unique identifier of the transfer policy to apply to this key. |
 //    | label              | String to attach optionally a text description to the key, e.g. "US Nginx key". |
 //    | usage              | String to attach optionally a usage criteria for the key, e.g. "Country:US,State:CA". |
+//    | not_before         | Optional time from which the key is transferable, in RFC3339 format. |
+//    | not_after          | Optional time until which the key is transferable, in RFC3339 format. |
+//    | max_transfer_count | Optional number of transfers allowed, the transfers are not limited when it is not set. |
+//    | disabled           | Set to true to create the key with the transfers disabled. |
 //
 //   The serialized KeyInformation Go struct object represents the content of the key_information field.
 //
@@ -63,6 +90,12 @@ type KeyTransferAttributes struct {
 //    | key_string  | Base64 encoded private key to be registered. Supported only if key is created locally. |
 //    | kmip_key_id | Unique KMIP identifier of key to be registered. Supported only if key is created on KMIP server. |
 //
+//   The keys permissions can be restricted by constraints set in the context of the role holding them, e.g.
+//   label=prod-*;algorithm=AES allows only the keys whose label starts with prod- and whose algorithm is AES. The
+//   constraints apply to the keys that are created, registered, retrieved, transferred, deleted and searched. A
+//   context that constrains neither label nor algorithm, e.g. the permissions context of the SKC key transfer, does
+//   not restrict the permissions.
+//
 // x-permissions: keys:create,keys:register
 // security:
 //  - bearerAuth: []
@@ -130,7 +163,8 @@ type KeyTransferAttributes struct {
 // ---
 //
 // description: |
-//   Retrieves a key.
+//   Retrieves a key. The response includes the usage policy of the key, the number of transfers of the key in
+//   transfer_count and the time of the last transfer in last_transferred_at.
 //   Returns - The serialized KeyResponse Go struct object that was retrieved.
 // x-permissions: keys:retrieve
 // security:
@@ -175,7 +209,11 @@ type KeyTransferAttributes struct {
 //        },
 //        "transfer_policy_id": "3ce27bbd-3c5f-4b15-8c0a-44310f0f83d9",
 //        "transfer_link": "https://kbs.com:9443/kbs/v1/keys/fc0cc779-22b6-4741-b0d9-e2e69635ad1e/transfer",
-//        "created_at": "2020-09-23T11:16:26.738467277Z"
+//        "created_at": "2020-09-23T11:16:26.738467277Z",
+//        "version": 1,
+//        "max_transfer_count": 10,
+//        "transfer_count": 2,
+//        "last_transferred_at": "2020-09-24T08:42:11.529843752Z"
 //    }
 
 // ---
@@ -184,8 +222,10 @@ type KeyTransferAttributes struct {
 // ---
 //
 // description: |
-//   Transfers a key.
-//   Returns - The serialized KeyTransferAttributes Go struct object that was retrieved.
+//   Transfers a key, the primary version of the key unless a prior version is requested. The transfer is refused
+//   when the key is disabled, outside of its not_before and not_after window or once max_transfer_count transfers are
+//   counted.
+//   Returns - The serialized KeyTransferAttributes Go struct object that was retrieved, with the version of the key.
 // x-permissions: keys:transfer
 // security:
 //  - bearerAuth: []
@@ -214,6 +254,11 @@ type KeyTransferAttributes struct {
 //   required: true
 //   enum:
 //     - application/json
+// - name: version
+//   description: Version of the key to transfer, a prior version is transferable until the end of its validity.
+//   in: query
+//   type: integer
+//   required: false
 // responses:
 //   '200':
 //     description: Successfully transferred the key.
@@ -221,6 +266,10 @@ type KeyTransferAttributes struct {
 //       application/json
 //     schema:
 //       $ref: "#/definitions/KeyTransferAttributes"
+//   '400':
+//     description: Invalid request body or key version is no longer transferable
+//   '403':
+//     description: Usage policy of the key does not allow the transfer
 //   '404':
 //     description: Key record not found
 //   '415':
@@ -244,11 +293,243 @@ type KeyTransferAttributes struct {
 // x-sample-call-output: |
 //    {
 //        "id": "fc0cc779-22b6-4741-b0d9-e2e69635ad1e",
-//        "payload": "F+nUVyejh2Cp0wkLFvqNkhBydtnKY8v5eJ5zbl9gHoPbqvjwuSafx4LwnHOT6DJDqa8LO5ufVyLqqXVfyAdf88s1VnKLCE0Udbn8Zjnq4CHnR2KqDPWTauYLnuYJH2lVGf4Ke4mTcvOfBO9YRTop0WzfTBSuEFKrAsE67ERogtCvD7hf5LhJ2sxv0ej48uZ5KLHRVAzbWMttRZXbL10xTC+dZM9SIAWg2s0aq7Mb49h2rcaI307e3GQgsXhbopwSTC7L7Sy1RYUf4XvHl+/XMmVmvKWjOFIfOXTg8cA+COTBjzOQXVJiXF/xv5/idny0sOeyebFfnxfj7ZXJhqT8pYtiyRm0kzU35jtFTpJR8+aMkOjI/4KdbM6zoY+7JiRD2A0VNEAvQzEoKnY2H9/fIRlkYLtjCI/n5CSPg5Ap0wghqZAmmCeaOH48D0NgjpVQPhc/OQHq/k0HRUXvmUgQe/D4T3WIUdJCctSBGsjIn3WrusH+cb5eaof5Aqq7NT4W"
+//        "payload": "F+nUVyejh2Cp0wkLFvqNkhBydtnKY8v5eJ5zbl9gHoPbqvjwuSafx4LwnHOT6DJDqa8LO5ufVyLqqXVfyAdf88s1VnKLCE0Udbn8Zjnq4CHnR2KqDPWTauYLnuYJH2lVGf4Ke4mTcvOfBO9YRTop0WzfTBSuEFKrAsE67ERogtCvD7hf5LhJ2sxv0ej48uZ5KLHRVAzbWMttRZXbL10xTC+dZM9SIAWg2s0aq7Mb49h2rcaI307e3GQgsXhbopwSTC7L7Sy1RYUf4XvHl+/XMmVmvKWjOFIfOXTg8cA+COTBjzOQXVJiXF/xv5/idny0sOeyebFfnxfj7ZXJhqT8pYtiyRm0kzU35jtFTpJR8+aMkOjI/4KdbM6zoY+7JiRD2A0VNEAvQzEoKnY2H9/fIRlkYLtjCI/n5CSPg5Ap0wghqZAmmCeaOH48D0NgjpVQPhc/OQHq/k0HRUXvmUgQe/D4T3WIUdJCctSBGsjIn3WrusH+cb5eaof5Aqq7NT4W",
+//        "version": 2
 //    }
 
 // ---
 
+// swagger:operation POST /keys/{id}/rotate Keys RotateKey
+// ---
+//
+// description: |
+//   Rotates a key. The key keeps its ID and gets new material of the same algorithm as a new primary version, the
+//   transfers return the new version. The replaced version stays transferable for the validity given in the request,
+//   or else the validity configured with KEY_ROTATION_PRIOR_VERSION_VALIDITY (24 hours by default).
+//
+//   The serialized KeyRotateRequest Go struct object represents the content of the optional request body.
+//
+//    | Attribute                      | Description |
+//    |--------------------------------|-------------|
+//    | prior_version_validity_minutes | Minutes the replaced version stays transferable, 0 makes it not transferable. |
+//
+//   Returns - The serialized KeyResponse Go struct object of the rotated key.
+// x-permissions: keys:rotate
+// security:
+//  - bearerAuth: []
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// parameters:
+// - name: id
+//   description: Unique ID of the key.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: request body
+//   required: false
+//   in: body
+//   schema:
+//    "$ref": "#/definitions/KeyRotateRequest"
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully rotated the key.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/KeyResponse"
+//   '400':
+//     description: Invalid request body
+//   '404':
+//     description: Key record not found
+//   '409':
+//     description: Key was updated concurrently
+//   '415':
+//     description: Invalid Content-Type/Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://kbs.com:9443/kbs/v1/keys/fc0cc779-22b6-4741-b0d9-e2e69635ad1e/rotate
+// x-sample-call-input: |
+//    {
+//        "prior_version_validity_minutes": 60
+//    }
+// x-sample-call-output: |
+//    {
+//        "key_information": {
+//            "id": "fc0cc779-22b6-4741-b0d9-e2e69635ad1e",
+//            "algorithm": "AES",
+//            "key_length": 256
+//        },
+//        "transfer_policy_id": "3ce27bbd-3c5f-4b15-8c0a-44310f0f83d9",
+//        "transfer_link": "https://kbs.com:9443/kbs/v1/keys/fc0cc779-22b6-4741-b0d9-e2e69635ad1e/transfer",
+//        "created_at": "2020-09-23T11:16:26.738467277Z",
+//        "version": 2
+//    }
+
+// ---
+
+// swagger:operation PUT /keys/{id}/usage-policy Keys UpdateKeyUsagePolicy
+// ---
+//
+// description: |
+//   Replaces the usage policy of a key, the transfers already counted are kept.
+//
+//   The serialized KeyUsagePolicy Go struct object represents the content of the request body.
+//
+//    | Attribute          | Description |
+//    |--------------------|-------------|
+//    | not_before         | Time from which the key is transferable, in RFC3339 format. |
+//    | not_after          | Time until which the key is transferable, in RFC3339 format. |
+//    | max_transfer_count | Number of transfers allowed, the transfers are not limited when it is not set. |
+//    | disabled           | Set to true to disable the transfers of the key. |
+//
+//   Returns - The serialized KeyResponse Go struct object of the updated key.
+// x-permissions: keys:update
+// security:
+//  - bearerAuth: []
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// parameters:
+// - name: id
+//   description: Unique ID of the key.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: request body
+//   required: true
+//   in: body
+//   schema:
+//    "$ref": "#/definitions/KeyUsagePolicy"
+// - name: Content-Type
+//   description: Content-Type header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully updated the usage policy of the key.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/KeyResponse"
+//   '400':
+//     description: Invalid request body
+//   '404':
+//     description: Key record not found
+//   '409':
+//     description: Key was updated concurrently
+//   '415':
+//     description: Invalid Content-Type/Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://kbs.com:9443/kbs/v1/keys/fc0cc779-22b6-4741-b0d9-e2e69635ad1e/usage-policy
+// x-sample-call-input: |
+//    {
+//        "not_after": "2021-09-23T00:00:00Z",
+//        "max_transfer_count": 10
+//    }
+// x-sample-call-output: |
+//    {
+//        "key_information": {
+//            "id": "fc0cc779-22b6-4741-b0d9-e2e69635ad1e",
+//            "algorithm": "AES",
+//            "key_length": 256
+//        },
+//        "transfer_policy_id": "3ce27bbd-3c5f-4b15-8c0a-44310f0f83d9",
+//        "transfer_link": "https://kbs.com:9443/kbs/v1/keys/fc0cc779-22b6-4741-b0d9-e2e69635ad1e/transfer",
+//        "created_at": "2020-09-23T11:16:26.738467277Z",
+//        "version": 1,
+//        "not_after": "2021-09-23T00:00:00Z",
+//        "max_transfer_count": 10,
+//        "transfer_count": 2,
+//        "last_transferred_at": "2020-09-24T08:42:11.529843752Z"
+//    }
+
+// ---
+
+// swagger:operation GET /keys/{id}/versions Keys RetrieveKeyVersions
+// ---
+//
+// description: |
+//   Retrieves the versions of a key, the primary version first followed by the prior versions, newest first.
+//   Returns - The collection of serialized KeyVersionResponse Go struct objects.
+// x-permissions: keys:retrieve
+// security:
+//  - bearerAuth: []
+// produces:
+// - application/json
+// parameters:
+// - name: id
+//   description: Unique ID of the key.
+//   in: path
+//   required: true
+//   type: string
+//   format: uuid
+// - name: Accept
+//   description: Accept header
+//   in: header
+//   type: string
+//   required: true
+//   enum:
+//     - application/json
+// responses:
+//   '200':
+//     description: Successfully retrieved the key versions.
+//     content:
+//       application/json
+//     schema:
+//       $ref: "#/definitions/KeyVersionResponses"
+//   '404':
+//     description: Key record not found
+//   '415':
+//     description: Invalid Accept Header in Request
+//   '500':
+//     description: Internal server error
+//
+// x-sample-call-endpoint: https://kbs.com:9443/kbs/v1/keys/fc0cc779-22b6-4741-b0d9-e2e69635ad1e/versions
+// x-sample-call-output: |
+//    [
+//        {
+//            "version": 2,
+//            "primary": true,
+//            "transferable": true,
+//            "created_at": "2020-10-01T09:30:00.000000000Z"
+//        },
+//        {
+//            "version": 1,
+//            "primary": false,
+//            "transferable": true,
+//            "created_at": "2020-09-23T11:16:26.738467277Z",
+//            "retired_at": "2020-10-01T09:30:00.000000000Z",
+//            "transferable_until": "2020-10-02T09:30:00.000000000Z"
+//        }
+//    ]
+
+// ---
+
 // swagger:operation DELETE /keys/{id} Keys DeleteKey
 // ---
 //
@@ -70,6 +70,8 @@ type KeyTransferResponse struct {
 //     description: Key record not found
 //   '401':
 //     description: Unauthorized request
+//   '403':
+//     description: Usage policy of the key does not allow the transfer
 //   '500':
 //     description: Internal server error
 //
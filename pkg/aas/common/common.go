@@ -10,59 +10,115 @@ import (
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/defender"
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
 	"net/http"
+	"strings"
 	"time"
 )
 
-var defend *defender.Defender
-
 func init() {
 	c, _ := config.LoadConfiguration()
 
-	defend = defender.New(c.AuthDefender.MaxAttempts,
+	defend := defender.New(c.AuthDefender.MaxAttempts,
 		time.Duration(c.AuthDefender.IntervalMins)*time.Minute,
 		time.Duration(c.AuthDefender.LockoutDurationMins)*time.Minute)
 	quit := make(chan struct{})
 
 	go defend.CleanupTask(quit)
 
+	lockouts = defenderLockout{defend: defend}
 }
 
 func HttpHandleUserAuth(u domain.UserStore, username, password string) (int, error) {
+	return httpHandleUserAuth(u, username, password, nil)
+}
+
+// httpHandleUserAuth authenticates a user with its password. The secondFactor is verified once the password is
+// verified, the failures of the user are cleared only when both succeed.
+func httpHandleUserAuth(u domain.UserStore, username, password string, secondFactor func() (int, error)) (int, error) {
 	// first let us make sure that this is not a user that is banned
+	if banned, err := lockouts.locked(username); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("Authentication failure: could not retrieve lockout of user: %s error: %s", username, err)
+	} else if banned {
+		return http.StatusTooManyRequests, fmt.Errorf("Maximum login attempts exceeded for user : %s. Banned !", username)
+	}
 
-	foundInDefendList := false
-	// check if we have an entry for the client in the defend map.
-	// There are several scenarios in this case
-	if client, ok := defend.Client(username); ok {
-		foundInDefendList = true
-		if client.Banned() {
-			// case 1. Client is banned - however, the ban expired but cleanup is not done.
-			// just delete the client from the map
-			if client.BanExpired() {
-				defend.RemoveClient(client.Key())
-			} else {
-				return http.StatusTooManyRequests, fmt.Errorf("Maximum login attempts exceeded for user : %s. Banned !", username)
+	// fetch by user
+	user, err := u.Retrieve(types.User{Name: username})
+	if federation != nil && (err == nil && len(user.PasswordHash) == 0 ||
+		err != nil && strings.Contains(err.Error(), commErr.RecordNotFound)) {
+		// users without a local password are authenticated by the directory
+		if err != nil {
+			user = nil
+		}
+		if err := federation.authenticate(u, user, username, password); err != nil {
+			if httpStatus, lockErr := authFailed(username); lockErr != nil {
+				return httpStatus, lockErr
 			}
+			return http.StatusUnauthorized, fmt.Errorf("Directory authentication failure: user: %s, error : %s", username, err)
+		}
+	} else {
+		if err != nil {
+			return http.StatusUnauthorized, fmt.Errorf("BasicAuth failure: could not retrieve user: %s error: %s", username, err)
+		}
+		if err := user.CheckPassword([]byte(password)); err != nil {
+			if httpStatus, lockErr := authFailed(username); lockErr != nil {
+				return httpStatus, lockErr
+			}
+			return http.StatusUnauthorized, fmt.Errorf("BasicAuth failure: password mismatch, user: %s, error : %s", username, err)
+		}
+	}
+	if secondFactor != nil {
+		if httpStatus, err := secondFactor(); err != nil {
+			return httpStatus, err
 		}
 	}
 
-	// fetch by user
-	user, err := u.Retrieve(types.User{Name: username})
+	// the user is authorized, its previous failures are not counted anymore
+	if err := lockouts.succeeded(username); err != nil {
+		defaultLog.WithError(err).Warnf("Failed to clear the failed authentications of user : %s", username)
+	}
+	return 0, nil
+}
+
+// authFailed counts a failed authentication of a user, an error is returned when the user got locked out
+func authFailed(username string) (int, error) {
+	banned, err := lockouts.failed(username)
 	if err != nil {
-		return http.StatusUnauthorized, fmt.Errorf("BasicAuth failure: could not retrieve user: %s error: %s", username, err)
+		return http.StatusInternalServerError, fmt.Errorf("Authentication failure: could not record failure of user: %s error: %s", username, err)
 	}
-	if err := user.CheckPassword([]byte(password)); err != nil {
-		if defend.Inc(username) {
-			return http.StatusTooManyRequests, fmt.Errorf("Authentication failure - maximum login attempts exceeded for user : %s. Banned !", username)
-		}
-		return http.StatusUnauthorized, fmt.Errorf("BasicAuth failure: password mismatch, user: %s, error : %s", username, err)
+	if banned {
+		return http.StatusTooManyRequests, fmt.Errorf("Authentication failure - maximum login attempts exceeded for user : %s. Banned !", username)
+	}
+	return 0, nil
+}
+
+func HttpHandleClientAuth(c domain.ClientStore, clientID, secret string) (int, error) {
+	// clients share the lockouts with users, the subject is prefixed so that a client can not lock out a user
+	subject := clientLockoutPrefix + clientID
+
+	if banned, err := lockouts.locked(subject); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("Client authentication failure: could not retrieve lockout of client: %s error: %s", clientID, err)
+	} else if banned {
+		return http.StatusTooManyRequests, fmt.Errorf("Maximum authentication attempts exceeded for client : %s. Banned !", clientID)
 	}
-	// If we found the user earlier in the defend list, we should now remove as user is authorized
-	if foundInDefendList {
-		if client, ok := defend.Client(username); ok {
-			defend.RemoveClient(client.Key())
+
+	client, err := c.Retrieve(types.Client{ID: clientID})
+	if err != nil {
+		return http.StatusUnauthorized, fmt.Errorf("Client authentication failure: could not retrieve client: %s error: %s", clientID, err)
+	}
+	if err := client.CheckSecret([]byte(secret)); err != nil {
+		banned, lockErr := lockouts.failed(subject)
+		if lockErr != nil {
+			return http.StatusInternalServerError, fmt.Errorf("Client authentication failure: could not record failure of client: %s error: %s", clientID, lockErr)
+		}
+		if banned {
+			return http.StatusTooManyRequests, fmt.Errorf("Client authentication failure - maximum attempts exceeded for client : %s. Banned !", clientID)
 		}
+		return http.StatusUnauthorized, fmt.Errorf("Client authentication failure: secret mismatch, client: %s, error : %s", clientID, err)
+	}
+	if err := lockouts.succeeded(subject); err != nil {
+		defaultLog.WithError(err).Warnf("Failed to clear the failed authentications of client : %s", clientID)
 	}
 	return 0, nil
 }
@@ -0,0 +1,159 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package common
+
+import (
+	"strings"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/pkg/errors"
+)
+
+var defaultLog = log.GetDefaultLogger()
+
+// userFederation authenticates the users that do not have a local password with an identity provider
+type userFederation struct {
+	identityProvider domain.IdentityProvider
+	roleStore        domain.RoleStore
+	auditStore       domain.AuditStore
+	groupRoles       map[string][]aasModel.RoleInfo
+}
+
+var federation *userFederation
+
+// SetIdentityProvider enables the federation of the users of an external directory. Users without a local password
+// are authenticated by the identity provider and are created in AAS on their first login. Their roles are managed by
+// the directory: at each login they are replaced with the roles mapped to the groups of the user. Local users keep
+// authenticating with their password. The users created and the roles changed at login are recorded in auditStore.
+func SetIdentityProvider(identityProvider domain.IdentityProvider, roleStore domain.RoleStore, auditStore domain.AuditStore,
+	groupRoles []config.GroupRoleMapping) error {
+	if identityProvider == nil {
+		federation = nil
+		return nil
+	}
+
+	fed := &userFederation{
+		identityProvider: identityProvider,
+		roleStore:        roleStore,
+		auditStore:       auditStore,
+		groupRoles:       map[string][]aasModel.RoleInfo{},
+	}
+	for _, mapping := range groupRoles {
+		// distinguished names are compared case insensitively
+		group := strings.ToLower(mapping.Group)
+		for _, role := range mapping.Roles {
+			roleInfo, err := parseRoleInfo(role)
+			if err != nil {
+				return errors.Wrapf(err, "Invalid role of group %s", mapping.Group)
+			}
+			fed.groupRoles[group] = append(fed.groupRoles[group], roleInfo)
+		}
+	}
+	federation = fed
+	return nil
+}
+
+// parseRoleInfo parses a role in the form <service>:<role name>[:<context>]
+func parseRoleInfo(role string) (aasModel.RoleInfo, error) {
+	roleParts := strings.SplitN(role, ":", 3)
+	if len(roleParts) < 2 || roleParts[0] == "" || roleParts[1] == "" {
+		return aasModel.RoleInfo{}, errors.Errorf("Invalid role %s, the role must be <service>:<role name>[:<context>]", role)
+	}
+	roleInfo := aasModel.RoleInfo{Service: roleParts[0], Name: roleParts[1]}
+	if len(roleParts) == 3 {
+		roleInfo.Context = roleParts[2]
+	}
+	return roleInfo, nil
+}
+
+// authenticate authenticates the user with the identity provider, creates the user when it does not exist yet and
+// synchronizes the roles of the user with the roles mapped to its groups
+func (f *userFederation) authenticate(u domain.UserStore, user *types.User, username, password string) error {
+	groups, err := f.identityProvider.Authenticate(username, password)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		user, err = u.Create(types.User{Name: username})
+		if err != nil {
+			return errors.Wrap(err, "Could not create directory user")
+		}
+		f.recordAuditEvent(types.AuditEvent{Action: types.AuditUserCreate, TargetType: types.AuditTargetUser,
+			TargetID: user.ID, TargetName: user.Name})
+	}
+
+	mappedRoles := map[string]types.Role{}
+	for _, group := range groups {
+		for _, roleInfo := range f.groupRoles[strings.ToLower(group)] {
+			role, err := f.roleStore.Retrieve(&types.RoleSearch{RoleInfo: roleInfo})
+			if err != nil || role == nil {
+				defaultLog.WithError(err).Warnf("Role %s:%s:%s mapped to group %s does not exist", roleInfo.Service,
+					roleInfo.Name, roleInfo.Context, group)
+				continue
+			}
+			mappedRoles[role.ID] = *role
+		}
+	}
+
+	currentRoles, err := u.GetRoles(*user, nil, true)
+	if err != nil {
+		return errors.Wrap(err, "Could not retrieve roles of directory user")
+	}
+	changed := false
+	permissions := f.userPermissions(u, *user)
+	defer func() {
+		// the roles removed before a failure are recorded as well
+		if changed {
+			f.recordAuditEvent(types.AuditEvent{Action: types.AuditUserRolesSync, TargetType: types.AuditTargetUser,
+				TargetID: user.ID, TargetName: user.Name, Before: permissions, After: f.userPermissions(u, *user)})
+		}
+	}()
+	for _, role := range currentRoles {
+		if _, ok := mappedRoles[role.ID]; ok {
+			delete(mappedRoles, role.ID)
+			continue
+		}
+		if err = u.DeleteRole(*user, role.ID, nil); err != nil {
+			return errors.Wrap(err, "Could not remove role of directory user")
+		}
+		changed = true
+	}
+	if len(mappedRoles) > 0 {
+		addRoles := make(types.Roles, 0, len(mappedRoles))
+		for _, role := range mappedRoles {
+			addRoles = append(addRoles, role)
+		}
+		if err = u.AddRoles(*user, addRoles, false); err != nil {
+			return errors.Wrap(err, "Could not add roles to directory user")
+		}
+		changed = true
+	}
+	return nil
+}
+
+// userPermissions returns the permissions of a directory user for the audit trail
+func (f *userFederation) userPermissions(u domain.UserStore, user types.User) types.AuditPermissions {
+	permissions, err := u.GetPermissions(types.User{ID: user.ID}, nil)
+	if err != nil {
+		defaultLog.WithError(err).WithField("id", user.ID).Error("Failed to retrieve the permissions of directory user for the audit trail")
+	}
+	return permissions
+}
+
+// recordAuditEvent records a change made by the user federation in the audit trail
+func (f *userFederation) recordAuditEvent(event types.AuditEvent) {
+	if f.auditStore == nil {
+		return
+	}
+	event.Actor = types.AuditActorFederation
+	if err := f.auditStore.Create(event); err != nil {
+		defaultLog.WithError(err).WithField("event", event).Error("Failed to record audit event of directory user")
+	}
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package common
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/postgres/mock"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type testIdentityProvider map[string][]string
+
+func (idp testIdentityProvider) Authenticate(username, password string) ([]string, error) {
+	if groups, ok := idp[username]; ok && password == username+"Password" {
+		return groups, nil
+	}
+	return nil, errors.New("invalid directory credentials")
+}
+
+func TestDirectoryUserAuth(t *testing.T) {
+	roles := []types.Role{
+		{ID: uuid.New().String(), RoleInfo: aasModel.RoleInfo{Service: "HVS", Name: "Administrator"}},
+		{ID: uuid.New().String(), RoleInfo: aasModel.RoleInfo{Service: "KBS", Name: "KeyTransfer", Context: "permissions=nginx"}},
+		{ID: uuid.New().String(), RoleInfo: aasModel.RoleInfo{Service: "AAS", Name: "Administrator"}},
+	}
+	localHash, err := bcrypt.GenerateFromPassword([]byte("adminPassword"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	users := map[string]*types.User{"admin": {ID: uuid.New().String(), Name: "admin", PasswordHash: localHash}}
+	userRoles := map[string][]types.Role{}
+	db := &mock.MockDatabase{}
+	db.MockUserStore.RetrieveFunc = func(u types.User) (*types.User, error) {
+		if user, ok := users[u.Name]; ok {
+			return user, nil
+		}
+		return nil, errors.New("user retrieve: failed: record not found")
+	}
+	db.MockUserStore.CreateFunc = func(u types.User) (*types.User, error) {
+		u.ID = uuid.New().String()
+		users[u.Name] = &u
+		return &u, nil
+	}
+	db.MockUserStore.GetRolesFunc = func(u types.User, rs *types.RoleSearch, includeID bool) ([]types.Role, error) {
+		return userRoles[u.Name], nil
+	}
+	db.MockUserStore.AddRolesFunc = func(u types.User, rs types.Roles, mustAddAllRoles bool) error {
+		userRoles[u.Name] = append(userRoles[u.Name], rs...)
+		return nil
+	}
+	db.MockUserStore.DeleteRoleFunc = func(u types.User, roleID string, svcFltr []string) error {
+		var remaining []types.Role
+		for _, role := range userRoles[u.Name] {
+			if role.ID != roleID {
+				remaining = append(remaining, role)
+			}
+		}
+		userRoles[u.Name] = remaining
+		return nil
+	}
+	db.MockRoleStore.RetrieveFunc = func(rs *types.RoleSearch) (*types.Role, error) {
+		for _, role := range roles {
+			if role.RoleInfo == rs.RoleInfo {
+				return &role, nil
+			}
+		}
+		return nil, errors.New("record not found")
+	}
+
+	var auditEvents []types.AuditEvent
+	db.MockAuditStore.CreateFunc = func(event types.AuditEvent) error {
+		auditEvents = append(auditEvents, event)
+		return nil
+	}
+
+	idp := testIdentityProvider{
+		"alice": {"CN=HVS-Admins,OU=Groups,DC=example,DC=com", "cn=nginx,ou=groups,dc=example,dc=com"},
+		"admin": {"cn=hvs-admins,ou=groups,dc=example,dc=com"},
+	}
+	err = SetIdentityProvider(idp, db.RoleStore(), db.AuditStore(), []config.GroupRoleMapping{
+		{Group: "cn=hvs-admins,ou=groups,dc=example,dc=com", Roles: []string{"HVS:Administrator"}},
+		{Group: "cn=nginx,ou=groups,dc=example,dc=com", Roles: []string{"KBS:KeyTransfer:permissions=nginx", "KBS:Missing"}},
+	})
+	assert.NoError(t, err)
+	defer SetIdentityProvider(nil, nil, nil, nil)
+
+	// a directory user is created on the first login with the roles of its groups
+	status, err := HttpHandleUserAuth(db.UserStore(), "alice", "alicePassword")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, status)
+	if assert.Contains(t, users, "alice") {
+		assert.Empty(t, users["alice"].PasswordHash)
+	}
+	assert.ElementsMatch(t, []types.Role{roles[0], roles[1]}, userRoles["alice"])
+	// the user creation and the role changes are recorded in the audit trail
+	if assert.Len(t, auditEvents, 2) {
+		assert.Equal(t, types.AuditUserCreate, auditEvents[0].Action)
+		assert.Equal(t, types.AuditUserRolesSync, auditEvents[1].Action)
+		assert.Equal(t, types.AuditActorFederation, auditEvents[1].Actor)
+		assert.Equal(t, "alice", auditEvents[1].TargetName)
+	}
+
+	// roles that are no longer mapped to the groups of the user are removed
+	idp["alice"] = []string{"cn=nginx,ou=groups,dc=example,dc=com"}
+	userRoles["alice"] = append(userRoles["alice"], roles[2])
+	_, err = HttpHandleUserAuth(db.UserStore(), "alice", "alicePassword")
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Role{roles[1]}, userRoles["alice"])
+	assert.Len(t, auditEvents, 3)
+
+	// a login that does not change the roles is not recorded
+	_, err = HttpHandleUserAuth(db.UserStore(), "alice", "alicePassword")
+	assert.NoError(t, err)
+	assert.Len(t, auditEvents, 3)
+
+	// local users keep authenticating with their local password
+	_, err = HttpHandleUserAuth(db.UserStore(), "admin", "adminPassword")
+	assert.NoError(t, err)
+	assert.Empty(t, userRoles["admin"])
+	assert.Len(t, auditEvents, 3)
+
+	status, err = HttpHandleUserAuth(db.UserStore(), "bob", "bobPassword")
+	assert.Error(t, err)
+	assert.NotEqual(t, 0, status)
+	assert.NotContains(t, users, "bob")
+}
+
+func TestSetIdentityProviderInvalidRole(t *testing.T) {
+	err := SetIdentityProvider(testIdentityProvider{}, &mock.MockRoleStore{}, nil, []config.GroupRoleMapping{
+		{Group: "cn=hvs-admins,ou=groups,dc=example,dc=com", Roles: []string{"Administrator"}},
+	})
+	assert.Error(t, err)
+	assert.Nil(t, federation)
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package common
+
+import (
+	"strings"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/defender"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+)
+
+// clientLockoutPrefix prefixes the lockout subject of the clients so that a client can not lock out a user
+const clientLockoutPrefix = "client:"
+
+// accountLockout counts the failed authentications of the users and clients, a subject is locked out after too many
+// failures
+type accountLockout interface {
+	// locked tells whether the subject is locked out
+	locked(subject string) (bool, error)
+	// failed counts a failed authentication, it returns true when the subject is locked out
+	failed(subject string) (bool, error)
+	// succeeded clears the failures of the subject
+	succeeded(subject string) error
+	lockedOut() ([]types.AuthLockout, error)
+}
+
+var lockouts accountLockout
+
+// defenderLockout keeps the failures in memory, it is used until the lockout store is set
+type defenderLockout struct {
+	defend *defender.Defender
+}
+
+func (d defenderLockout) locked(subject string) (bool, error) {
+	client, ok := d.defend.Client(subject)
+	if !ok || !client.Banned() {
+		return false, nil
+	}
+	// the ban expired but the cleanup is not done yet
+	if client.BanExpired() {
+		d.defend.RemoveClient(client.Key())
+		return false, nil
+	}
+	return true, nil
+}
+
+func (d defenderLockout) failed(subject string) (bool, error) {
+	return d.defend.Inc(subject), nil
+}
+
+func (d defenderLockout) succeeded(subject string) error {
+	d.defend.RemoveClient(subject)
+	return nil
+}
+
+func (d defenderLockout) lockedOut() ([]types.AuthLockout, error) {
+	var locked []types.AuthLockout
+	for _, client := range d.defend.BanList() {
+		if subject, ok := client.Key().(string); ok && !client.BanExpired() {
+			expire := client.Expire()
+			locked = append(locked, types.AuthLockout{Subject: subject, LockedUntil: &expire})
+		}
+	}
+	return locked, nil
+}
+
+// storeLockout counts the failures in the database, the lockouts survive restarts and are shared by the AAS replicas
+type storeLockout struct {
+	store           domain.LockoutStore
+	maxAttempts     int
+	interval        time.Duration
+	lockoutDuration time.Duration
+}
+
+func (s storeLockout) locked(subject string) (bool, error) {
+	lockout, err := s.store.Retrieve(subject)
+	if err != nil {
+		return false, err
+	}
+	return lockout != nil && lockout.Locked(time.Now()), nil
+}
+
+func (s storeLockout) failed(subject string) (bool, error) {
+	now := time.Now()
+	lockout, err := s.store.RecordFailure(subject, now.Add(-s.interval), now)
+	if err != nil {
+		return false, err
+	}
+	if lockout.Locked(now) {
+		return true, nil
+	}
+	if lockout.FailedAttempts < s.maxAttempts {
+		return false, nil
+	}
+	return true, s.store.Lock(subject, now.Add(s.lockoutDuration))
+}
+
+func (s storeLockout) succeeded(subject string) error {
+	return s.store.Delete(subject)
+}
+
+func (s storeLockout) lockedOut() ([]types.AuthLockout, error) {
+	return s.store.RetrieveAllLocked(time.Now())
+}
+
+// cleanupTask removes the failures that are too old to be counted and the expired lockouts
+func (s storeLockout) cleanupTask(quit <-chan struct{}) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.store.DeleteExpired(time.Now().Add(-s.interval)); err != nil {
+				defaultLog.WithError(err).Warn("Failed to remove expired lockouts")
+			}
+		case <-quit:
+			return
+		}
+	}
+}
+
+// SetLockoutStore persists the failed authentications and the lockouts in the database. The users and clients that
+// fail to authenticate MaxAttempts times within IntervalMins are locked out for LockoutDurationMins, the defaults are
+// used for the values that are not set.
+func SetLockoutStore(store domain.LockoutStore, c config.AuthDefender, quit <-chan struct{}) {
+	s := storeLockout{
+		store:           store,
+		maxAttempts:     intOrDefault(c.MaxAttempts, constants.DefaultAuthDefendMaxAttempts),
+		interval:        time.Duration(intOrDefault(c.IntervalMins, constants.DefaultAuthDefendIntervalMins)) * time.Minute,
+		lockoutDuration: time.Duration(intOrDefault(c.LockoutDurationMins, constants.DefaultAuthDefendLockoutMins)) * time.Minute,
+	}
+	go s.cleanupTask(quit)
+	lockouts = s
+}
+
+func intOrDefault(value, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// LockedOutAccounts returns the users and clients that are locked out
+func LockedOutAccounts() ([]aasModel.AccountLockout, error) {
+	locked, err := lockouts.lockedOut()
+	if err != nil {
+		return nil, err
+	}
+	accounts := []aasModel.AccountLockout{}
+	for _, lockout := range locked {
+		account := aasModel.AccountLockout{FailedAttempts: lockout.FailedAttempts, LockedUntil: *lockout.LockedUntil}
+		if strings.HasPrefix(lockout.Subject, clientLockoutPrefix) {
+			account.ClientID = strings.TrimPrefix(lockout.Subject, clientLockoutPrefix)
+		} else {
+			account.UserName = lockout.Subject
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// UnlockUser clears the failed authentications of a user
+func UnlockUser(username string) error {
+	return lockouts.succeeded(username)
+}
+
+// UnlockClient clears the failed authentications of a client
+func UnlockClient(clientID string) error {
+	return lockouts.succeeded(clientLockoutPrefix + clientID)
+}
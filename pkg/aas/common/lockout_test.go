@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package common
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/postgres/mock"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newLockoutStore returns a mock lockout store that keeps the lockouts in a map like the database would
+func newLockoutStore(entries map[string]*types.AuthLockout) *mock.MockLockoutStore {
+	return &mock.MockLockoutStore{
+		RetrieveFunc: func(subject string) (*types.AuthLockout, error) {
+			return entries[subject], nil
+		},
+		RetrieveAllLockedFunc: func(t time.Time) ([]types.AuthLockout, error) {
+			var locked []types.AuthLockout
+			for _, l := range entries {
+				if l.Locked(t) {
+					locked = append(locked, *l)
+				}
+			}
+			return locked, nil
+		},
+		RecordFailureFunc: func(subject string, windowStart, t time.Time) (*types.AuthLockout, error) {
+			l, ok := entries[subject]
+			if !ok || l.FirstFailedAt.Before(windowStart) {
+				l = &types.AuthLockout{Subject: subject, FirstFailedAt: t}
+				entries[subject] = l
+			}
+			l.FailedAttempts++
+			copied := *l
+			return &copied, nil
+		},
+		LockFunc: func(subject string, until time.Time) error {
+			entries[subject].LockedUntil = &until
+			return nil
+		},
+		DeleteFunc: func(subject string) error {
+			delete(entries, subject)
+			return nil
+		},
+	}
+}
+
+func TestStoreLockout(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("userPassword"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	user := &types.User{ID: uuid.New().String(), Name: "operator", PasswordHash: hash}
+	db := &mock.MockDatabase{}
+	db.MockUserStore.RetrieveFunc = func(u types.User) (*types.User, error) {
+		if u.Name == user.Name {
+			return user, nil
+		}
+		return nil, errors.New("user retrieve: failed: record not found")
+	}
+
+	entries := map[string]*types.AuthLockout{}
+	quit := make(chan struct{})
+	defer close(quit)
+	testLockouts := lockouts
+	SetLockoutStore(newLockoutStore(entries), config.AuthDefender{MaxAttempts: 3, IntervalMins: 5, LockoutDurationMins: 15}, quit)
+	defer func() { lockouts = testLockouts }()
+
+	// a success clears the previous failures
+	_, err = HttpHandleUserAuth(db.UserStore(), "operator", "wrongPassword")
+	assert.Error(t, err)
+	assert.Equal(t, 1, entries["operator"].FailedAttempts)
+	_, err = HttpHandleUserAuth(db.UserStore(), "operator", "userPassword")
+	assert.NoError(t, err)
+	assert.NotContains(t, entries, "operator")
+
+	// failures older than the interval are not counted
+	for i := 0; i < 2; i++ {
+		_, err = HttpHandleUserAuth(db.UserStore(), "operator", "wrongPassword")
+		assert.Error(t, err)
+	}
+	entries["operator"].FirstFailedAt = time.Now().Add(-10 * time.Minute)
+	status, _ := HttpHandleUserAuth(db.UserStore(), "operator", "wrongPassword")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, 1, entries["operator"].FailedAttempts)
+
+	for _, expectedStatus := range []int{http.StatusUnauthorized, http.StatusTooManyRequests, http.StatusTooManyRequests} {
+		status, err = HttpHandleUserAuth(db.UserStore(), "operator", "wrongPassword")
+		assert.Error(t, err)
+		assert.Equal(t, expectedStatus, status)
+	}
+	// the user is locked out even with the right password until an administrator unlocks it
+	status, _ = HttpHandleUserAuth(db.UserStore(), "operator", "userPassword")
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	locked, err := LockedOutAccounts()
+	assert.NoError(t, err)
+	if assert.Len(t, locked, 1) {
+		assert.Equal(t, "operator", locked[0].UserName)
+		assert.Equal(t, 3, locked[0].FailedAttempts)
+	}
+
+	assert.NoError(t, UnlockUser("operator"))
+	_, err = HttpHandleUserAuth(db.UserStore(), "operator", "userPassword")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package common
+
+import (
+	"crypto/rand"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/mfa"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/pkg/errors"
+)
+
+const mfaKeyLength = 32
+
+var (
+	// ErrMfaCodeRequired is returned when a user that enrolled an authenticator did not provide a code
+	ErrMfaCodeRequired = errors.New("TOTP code required")
+	// ErrMfaEnrollmentRequired is returned when a role of a user requires MFA and the user did not enroll yet
+	ErrMfaEnrollmentRequired = errors.New("MFA enrollment required")
+	// ErrMfaInvalidCode is returned when the TOTP or recovery code does not match
+	ErrMfaInvalidCode = errors.New("invalid TOTP or recovery code")
+)
+
+// mfaPolicy holds the key that encrypts the TOTP secrets and the roles that require a second factor
+type mfaPolicy struct {
+	key           []byte
+	requiredRoles []aasModel.RoleInfo
+}
+
+var mfaSettings *mfaPolicy
+
+// SetMfaPolicy enables the TOTP second factor. The key encrypts the TOTP secrets in the database. Users holding one
+// of the required roles, in the form <service>:<role name>[:<context>], can not get a token before they enrolled an
+// authenticator, other users can enroll one optionally. A nil key disables MFA.
+func SetMfaPolicy(key []byte, requiredRoles []string) error {
+	if key == nil {
+		mfaSettings = nil
+		return nil
+	}
+	if len(key) != mfaKeyLength {
+		return errors.Errorf("Invalid MFA key length %d", len(key))
+	}
+	policy := &mfaPolicy{key: key}
+	for _, role := range requiredRoles {
+		roleInfo, err := parseRoleInfo(role)
+		if err != nil {
+			return errors.Wrap(err, "Invalid MFA required role")
+		}
+		policy.requiredRoles = append(policy.requiredRoles, roleInfo)
+	}
+	mfaSettings = policy
+	return nil
+}
+
+// LoadMfaKey reads the key that encrypts the TOTP secrets, the key is created when the file does not exist
+func LoadMfaKey(keyFile string) ([]byte, error) {
+	key, err := ioutil.ReadFile(keyFile)
+	if err == nil {
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "Could not read MFA key")
+	}
+	key = make([]byte, mfaKeyLength)
+	if _, err = rand.Read(key); err != nil {
+		return nil, errors.Wrap(err, "Could not generate MFA key")
+	}
+	if err = ioutil.WriteFile(keyFile, key, 0600); err != nil {
+		return nil, errors.Wrap(err, "Could not save MFA key")
+	}
+	return key, nil
+}
+
+// EncryptMfaSecret encrypts a TOTP secret with the MFA key
+func EncryptMfaSecret(secret []byte) ([]byte, error) {
+	if mfaSettings == nil {
+		return nil, errors.New("MFA is not enabled")
+	}
+	return crypt.AesEncrypt(secret, mfaSettings.key)
+}
+
+// DecryptMfaSecret decrypts a TOTP secret with the MFA key
+func DecryptMfaSecret(encryptedSecret []byte) ([]byte, error) {
+	if mfaSettings == nil {
+		return nil, errors.New("MFA is not enabled")
+	}
+	return crypt.AesDecrypt(encryptedSecret, mfaSettings.key)
+}
+
+// MfaEnabled tells whether the TOTP second factor is enabled
+func MfaEnabled() bool {
+	return mfaSettings != nil
+}
+
+// HttpHandleUserAuthWithMfa authenticates a user with its password and the TOTP or recovery code of its
+// authenticator. The code is only required from the users that enrolled an authenticator, users holding a role that
+// requires MFA are rejected until they enrolled one. Invalid codes are counted like invalid passwords. The users
+// whose password expired are rejected until they changed it.
+func HttpHandleUserAuthWithMfa(db domain.AASDatabase, username, password, code string) (int, error) {
+	return httpHandleUserAuth(db.UserStore(), username, password, func() (int, error) {
+		if httpStatus, err := handleUserMfa(db, username, code); err != nil {
+			return httpStatus, err
+		}
+		return handlePasswordAge(db, username)
+	})
+}
+
+func handleUserMfa(db domain.AASDatabase, username, code string) (int, error) {
+	if mfaSettings == nil {
+		return 0, nil
+	}
+	user, err := db.UserStore().Retrieve(types.User{Name: username})
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrapf(err, "MFA failure: could not retrieve user: %s", username)
+	}
+	userMfa, err := db.MfaStore().Retrieve(user.ID)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrapf(err, "MFA failure: could not retrieve MFA of user: %s", username)
+	}
+
+	if userMfa == nil || !userMfa.Enabled {
+		required, err := mfaRequired(db.UserStore(), *user)
+		if err != nil {
+			return http.StatusInternalServerError, errors.Wrapf(err, "MFA failure: could not retrieve roles of user: %s", username)
+		}
+		if required {
+			return http.StatusUnauthorized, errors.Wrapf(ErrMfaEnrollmentRequired, "MFA failure: user: %s", username)
+		}
+		return 0, nil
+	}
+
+	if code == "" {
+		return http.StatusUnauthorized, errors.Wrapf(ErrMfaCodeRequired, "MFA failure: user: %s", username)
+	}
+	valid, err := verifyMfaCode(db.MfaStore(), userMfa, code)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrapf(err, "MFA failure: could not verify code of user: %s", username)
+	}
+	if !valid {
+		banned, err := lockouts.failed(username)
+		if err != nil {
+			return http.StatusInternalServerError, errors.Wrapf(err, "MFA failure: could not record failure of user: %s", username)
+		}
+		if banned {
+			return http.StatusTooManyRequests, errors.Errorf("MFA failure - maximum login attempts exceeded for user : %s. Banned !", username)
+		}
+		return http.StatusUnauthorized, errors.Wrapf(ErrMfaInvalidCode, "MFA failure: user: %s", username)
+	}
+	return 0, nil
+}
+
+// verifyMfaCode verifies a TOTP code or a recovery code of an enrolled authenticator. Each code is accepted once.
+func verifyMfaCode(store domain.MfaStore, userMfa *types.UserMfa, code string) (bool, error) {
+	secret, err := DecryptMfaSecret(userMfa.TotpSecret)
+	if err != nil {
+		return false, errors.Wrap(err, "Could not decrypt TOTP secret")
+	}
+	if step, ok := mfa.Validate(secret, code, time.Now(), userMfa.LastUsedStep); ok {
+		return store.UseTotpStep(userMfa.UserID, step)
+	}
+	if !mfa.IsRecoveryCode(code) {
+		return false, nil
+	}
+	return store.UseRecoveryCode(userMfa.UserID, mfa.HashRecoveryCode(code))
+}
+
+// mfaRequired tells whether the user holds a role that requires MFA
+func mfaRequired(u domain.UserStore, user types.User) (bool, error) {
+	if len(mfaSettings.requiredRoles) == 0 {
+		return false, nil
+	}
+	roles, err := u.GetRoles(user, nil, false)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		for _, required := range mfaSettings.requiredRoles {
+			if role.Service == required.Service && role.Name == required.Name &&
+				(required.Context == "" || role.Context == required.Context) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
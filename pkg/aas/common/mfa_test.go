@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package common
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/defender"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/mfa"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/postgres/mock"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserAuthWithMfa(t *testing.T) {
+	key := make([]byte, mfaKeyLength)
+	assert.NoError(t, SetMfaPolicy(key, []string{"AAS:Administrator"}))
+	defer SetMfaPolicy(nil, nil)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("userPassword"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	users := map[string]*types.User{}
+	for _, name := range []string{"operator", "admin", "enrolled", "guessed"} {
+		users[name] = &types.User{ID: uuid.New().String(), Name: name, PasswordHash: hash}
+	}
+	secret, err := mfa.GenerateSecret()
+	assert.NoError(t, err)
+	encryptedSecret, err := EncryptMfaSecret(secret)
+	assert.NoError(t, err)
+	userMfas := map[string]*types.UserMfa{
+		users["enrolled"].ID: {UserID: users["enrolled"].ID, TotpSecret: encryptedSecret, Enabled: true},
+		users["guessed"].ID:  {UserID: users["guessed"].ID, TotpSecret: encryptedSecret, Enabled: true},
+	}
+	recoveryCodes, err := mfa.GenerateRecoveryCodes()
+	assert.NoError(t, err)
+	usedCodes := map[string]bool{}
+
+	db := &mock.MockDatabase{}
+	db.MockUserStore.RetrieveFunc = func(u types.User) (*types.User, error) {
+		if user, ok := users[u.Name]; ok {
+			return user, nil
+		}
+		return nil, errors.New("user retrieve: failed: record not found")
+	}
+	db.MockUserStore.GetRolesFunc = func(u types.User, rs *types.RoleSearch, includeID bool) ([]types.Role, error) {
+		if u.Name == "admin" {
+			return []types.Role{{RoleInfo: aasModel.RoleInfo{Service: "AAS", Name: "Administrator"}}}, nil
+		}
+		return nil, nil
+	}
+	db.MockMfaStore.RetrieveFunc = func(userID string) (*types.UserMfa, error) {
+		return userMfas[userID], nil
+	}
+	db.MockMfaStore.UseTotpStepFunc = func(userID string, step int64) (bool, error) {
+		if userMfas[userID].LastUsedStep >= step {
+			return false, nil
+		}
+		userMfas[userID].LastUsedStep = step
+		return true, nil
+	}
+	db.MockMfaStore.UseRecoveryCodeFunc = func(userID, codeHash string) (bool, error) {
+		if codeHash != mfa.HashRecoveryCode(recoveryCodes[0]) || usedCodes[codeHash] {
+			return false, nil
+		}
+		usedCodes[codeHash] = true
+		return true, nil
+	}
+
+	// users without an authenticator and without a role that requires MFA authenticate with their password
+	_, err = HttpHandleUserAuthWithMfa(db, "operator", "userPassword", "")
+	assert.NoError(t, err)
+
+	status, err := HttpHandleUserAuthWithMfa(db, "admin", "userPassword", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, ErrMfaEnrollmentRequired, errors.Cause(err))
+
+	status, err = HttpHandleUserAuthWithMfa(db, "enrolled", "userPassword", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, ErrMfaCodeRequired, errors.Cause(err))
+
+	code := mfa.Code(secret, mfa.Step(time.Now()))
+	_, err = HttpHandleUserAuthWithMfa(db, "enrolled", "userPassword", code)
+	assert.NoError(t, err)
+
+	// a recovery code is accepted once
+	_, err = HttpHandleUserAuthWithMfa(db, "enrolled", "userPassword", recoveryCodes[0])
+	assert.NoError(t, err)
+	assert.True(t, usedCodes[mfa.HashRecoveryCode(recoveryCodes[0])])
+
+	// expired and invalid codes are counted like invalid passwords
+	testLockouts := lockouts
+	lockouts = defenderLockout{defend: defender.New(2, time.Minute, time.Minute)}
+	defer func() { lockouts = testLockouts }()
+	for _, expectedStatus := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		status, err = HttpHandleUserAuthWithMfa(db, "guessed", "userPassword", mfa.Code(secret, mfa.Step(time.Now())-5))
+		assert.Error(t, err)
+		assert.Equal(t, expectedStatus, status)
+	}
+	status, err = HttpHandleUserAuthWithMfa(db, "guessed", "userPassword", mfa.Code(secret, mfa.Step(time.Now())))
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, status)
+}
+
+func TestSetMfaPolicyInvalidRole(t *testing.T) {
+	assert.Error(t, SetMfaPolicy(make([]byte, mfaKeyLength), []string{"Administrator"}))
+	assert.Error(t, SetMfaPolicy(make([]byte, 16), nil))
+	assert.Nil(t, mfaSettings)
+}
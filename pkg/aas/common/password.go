@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package common
+
+import (
+	"net/http"
+	"time"
+	"unicode"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrPasswordExpired is returned when the password of a user is older than the maximum password age
+var ErrPasswordExpired = errors.New("password expired, it has to be changed with /users/changepassword")
+
+// passwordCharClasses is the number of character classes: lower case, upper case, digits and others
+const passwordCharClasses = 4
+
+var passwordPolicy config.PasswordPolicy
+
+// SetPasswordPolicy sets the rules of the passwords that are set in AAS, the zero value disables every rule
+func SetPasswordPolicy(p config.PasswordPolicy) error {
+	if p.MinLength < 0 || p.HistoryCount < 0 || p.MaxAgeDays < 0 ||
+		p.MinCharClasses < 0 || p.MinCharClasses > passwordCharClasses {
+		return errors.Errorf("Invalid password policy %+v", p)
+	}
+	passwordPolicy = p
+	return nil
+}
+
+// ValidatePasswordPolicy verifies that a new password is long enough and uses enough character classes
+func ValidatePasswordPolicy(password string) error {
+	if len([]rune(password)) < passwordPolicy.MinLength {
+		return errors.Errorf("Password must be at least %d characters long", passwordPolicy.MinLength)
+	}
+	var lower, upper, digit, other int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+	if lower+upper+digit+other < passwordPolicy.MinCharClasses {
+		return errors.Errorf("Password must use at least %d of lower case letters, upper case letters, digits and "+
+			"special characters", passwordPolicy.MinCharClasses)
+	}
+	return nil
+}
+
+// ValidatePasswordReuse verifies that a new password of a user is not one of its latest passwords
+func ValidatePasswordReuse(db domain.AASDatabase, user types.User, password string) error {
+	if passwordPolicy.HistoryCount == 0 {
+		return nil
+	}
+	history, err := db.PasswordStore().RetrieveHistory(user.ID, passwordPolicy.HistoryCount)
+	if err != nil {
+		return errors.Wrap(err, "Could not retrieve password history")
+	}
+	// the current password is checked as well in case it was set before the history was recorded
+	hashes := [][]byte{user.PasswordHash}
+	for _, entry := range history {
+		hashes = append(hashes, entry.PasswordHash)
+	}
+	for _, hash := range hashes {
+		if len(hash) != 0 && bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil {
+			return errors.Errorf("Password must not be one of the last %d passwords", passwordPolicy.HistoryCount)
+		}
+	}
+	return nil
+}
+
+// RecordPassword adds the password that was set for a user to its history. The latest entry tells the age of the
+// password of the user.
+func RecordPassword(db domain.AASDatabase, user types.User) error {
+	keep := passwordPolicy.HistoryCount
+	if keep == 0 {
+		keep = 1
+	}
+	entry := types.PasswordHistory{UserID: user.ID, PasswordHash: user.PasswordHash}
+	return db.PasswordStore().AddHistory(entry, keep)
+}
+
+// handlePasswordAge rejects the users whose password is older than the maximum password age. The passwords that were
+// set before their history was recorded, like the ones of the users created by the setup tasks, do not expire.
+func handlePasswordAge(db domain.AASDatabase, username string) (int, error) {
+	if passwordPolicy.MaxAgeDays == 0 {
+		return 0, nil
+	}
+	user, err := db.UserStore().Retrieve(types.User{Name: username})
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrapf(err, "Password age failure: could not retrieve user: %s", username)
+	}
+	if len(user.PasswordHash) == 0 {
+		// the password of a directory user is managed by the directory
+		return 0, nil
+	}
+	history, err := db.PasswordStore().RetrieveHistory(user.ID, 1)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrapf(err, "Password age failure: could not retrieve password history of user: %s", username)
+	}
+	maxAge := time.Duration(passwordPolicy.MaxAgeDays) * 24 * time.Hour
+	if len(history) != 0 && time.Since(history[0].CreatedAt) > maxAge {
+		return http.StatusUnauthorized, errors.Wrapf(ErrPasswordExpired, "Password age failure: user: %s", username)
+	}
+	return 0, nil
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package common
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/postgres/mock"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestValidatePasswordPolicy(t *testing.T) {
+	assert.NoError(t, SetPasswordPolicy(config.PasswordPolicy{MinLength: 10, MinCharClasses: 3}))
+	defer SetPasswordPolicy(config.PasswordPolicy{})
+
+	assert.Error(t, ValidatePasswordPolicy("Short1!"))
+	assert.Error(t, ValidatePasswordPolicy("onlylowercaseletters"))
+	assert.Error(t, ValidatePasswordPolicy("lowerAndUpper"))
+	assert.NoError(t, ValidatePasswordPolicy("lowerUpper123"))
+	assert.NoError(t, ValidatePasswordPolicy("lower-and-digits-42"))
+
+	assert.Error(t, SetPasswordPolicy(config.PasswordPolicy{MinCharClasses: 5}))
+	assert.Error(t, SetPasswordPolicy(config.PasswordPolicy{HistoryCount: -1}))
+}
+
+func TestPasswordHistoryAndAge(t *testing.T) {
+	assert.NoError(t, SetPasswordPolicy(config.PasswordPolicy{HistoryCount: 2, MaxAgeDays: 90}))
+	defer SetPasswordPolicy(config.PasswordPolicy{})
+
+	user := &types.User{ID: uuid.New().String(), Name: "operator"}
+	var history []types.PasswordHistory
+	db := &mock.MockDatabase{}
+	db.MockUserStore.RetrieveFunc = func(u types.User) (*types.User, error) {
+		if u.Name == user.Name {
+			return user, nil
+		}
+		return nil, errors.New("user retrieve: failed: record not found")
+	}
+	db.MockPasswordStore.RetrieveHistoryFunc = func(userID string, count int) ([]types.PasswordHistory, error) {
+		if len(history) > count {
+			return history[:count], nil
+		}
+		return history, nil
+	}
+	db.MockPasswordStore.AddHistoryFunc = func(entry types.PasswordHistory, keep int) error {
+		entry.CreatedAt = time.Now()
+		history = append([]types.PasswordHistory{entry}, history...)
+		if len(history) > keep {
+			history = history[:keep]
+		}
+		return nil
+	}
+	setPassword := func(password string) {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+		assert.NoError(t, err)
+		user.PasswordHash = hash
+		assert.NoError(t, RecordPassword(db, *user))
+	}
+
+	// the passwords set before the history was recorded do not expire
+	setPassword("firstPassword")
+	history = nil
+	_, err := handlePasswordAge(db, "operator")
+	assert.NoError(t, err)
+
+	setPassword("secondPassword")
+	setPassword("thirdPassword")
+	assert.Error(t, ValidatePasswordReuse(db, *user, "thirdPassword"))
+	assert.Error(t, ValidatePasswordReuse(db, *user, "secondPassword"))
+	assert.NoError(t, ValidatePasswordReuse(db, *user, "firstPassword"))
+
+	_, err = handlePasswordAge(db, "operator")
+	assert.NoError(t, err)
+	history[0].CreatedAt = time.Now().Add(-91 * 24 * time.Hour)
+	status, err := handlePasswordAge(db, "operator")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, ErrPasswordExpired, errors.Cause(err))
+}
@@ -24,6 +24,9 @@ type Configuration struct {
 	Log              commConfig.LogConfig     `yaml:"log" mapstructure:"log"`
 	AuthDefender     AuthDefender             `yaml:"auth-defender" mapstructure:"auth-defender"`
 	JWT              JWT                      `yaml:"jwt" mapstructure:"jwt"`
+	LDAP             LDAP                     `yaml:"ldap" mapstructure:"ldap"`
+	MFA              MFA                      `yaml:"mfa" mapstructure:"mfa"`
+	PasswordPolicy   PasswordPolicy           `yaml:"password-policy" mapstructure:"password-policy"`
 	TLS              commConfig.TLSCertConfig `yaml:"tls" mapstructure:"tls"`
 	Server           commConfig.ServerConfig  `yaml:"server" mapstructure:"server"`
 }
@@ -37,8 +40,54 @@ type JWT struct {
 	IncludeKid        bool   `yaml:"include-kid" mapstructure:"include-kid"`
 	TokenDurationMins int    `yaml:"token-duration-mins" mapstructure:"token-duration-mins"`
 	CertCommonName    string `yaml:"cert-common-name" mapstructure:"cert-common-name"`
+	// Issuer is the iss claim of the tokens, it should be the external URL of AAS (https://<host>:<port>/aas)
+	// for the OpenID Connect discovery document to be used by third party clients
+	Issuer string `yaml:"issuer" mapstructure:"issuer"`
+	// validity of the access tokens that are issued along with a refresh token
+	AccessTokenDurationMins  int `yaml:"access-token-duration-mins" mapstructure:"access-token-duration-mins"`
+	RefreshTokenDurationMins int `yaml:"refresh-token-duration-mins" mapstructure:"refresh-token-duration-mins"`
 }
 
+// LDAP configures the federation of the users of an LDAP directory or Active Directory. Users that do not have a
+// local password are authenticated by binding to the directory and are granted the roles mapped to their groups.
+type LDAP struct {
+	URL          string `yaml:"url" mapstructure:"url"`
+	StartTLS     bool   `yaml:"start-tls" mapstructure:"start-tls"`
+	CACertFile   string `yaml:"ca-cert-file" mapstructure:"ca-cert-file"`
+	BindDN       string `yaml:"bind-dn" mapstructure:"bind-dn"`
+	BindPassword string `yaml:"bind-password" mapstructure:"bind-password"`
+	UserBaseDN   string `yaml:"user-base-dn" mapstructure:"user-base-dn"`
+	// UserFilter is the search filter of the users, %s is replaced with the escaped user name
+	UserFilter     string             `yaml:"user-filter" mapstructure:"user-filter"`
+	GroupAttribute string             `yaml:"group-attribute" mapstructure:"group-attribute"`
+	GroupRoles     []GroupRoleMapping `yaml:"group-roles" mapstructure:"group-roles"`
+}
+
+// GroupRoleMapping grants AAS roles in the form <service>:<role name>[:<context>] to the members of a directory group
+type GroupRoleMapping struct {
+	Group string   `yaml:"group" mapstructure:"group"`
+	Roles []string `yaml:"roles" mapstructure:"roles"`
+}
+
+// MFA configures the TOTP second factor of the users. Users can enroll an authenticator optionally, the users holding
+// one of the RequiredRoles in the form <service>:<role name>[:<context>] have to enroll one before they get a token.
+type MFA struct {
+	RequiredRoles []string `yaml:"required-roles" mapstructure:"required-roles"`
+}
+
+// PasswordPolicy configures the rules of the passwords that are set in AAS. The passwords are verified when they are
+// set, a password older than MaxAgeDays has to be changed with /users/changepassword before its user gets a token.
+type PasswordPolicy struct {
+	MinLength int `yaml:"min-length" mapstructure:"min-length"`
+	// MinCharClasses is the number of character classes (lower case, upper case, digits, others) a password must use
+	MinCharClasses int `yaml:"min-char-classes" mapstructure:"min-char-classes"`
+	// HistoryCount is the number of the latest passwords of a user, the current one included, that can not be reused
+	HistoryCount int `yaml:"history-count" mapstructure:"history-count"`
+	MaxAgeDays   int `yaml:"max-age-days" mapstructure:"max-age-days"`
+}
+
+// AuthDefender locks out the users and clients that fail to authenticate MaxAttempts times within IntervalMins. The
+// failures are counted in the database so that the lockouts survive restarts and are shared by the AAS replicas.
 type AuthDefender struct {
 	MaxAttempts         int `yaml:"max-attempts" mapstructure:"max-attempts"`
 	IntervalMins        int `yaml:"interval-mins" mapstructure:"interval-mins"`
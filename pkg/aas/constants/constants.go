@@ -25,6 +25,7 @@ const (
 	TokenSignKeysAndCertDir = ConfigDir + "certs/tokensign/"
 	TokenSignKeyFile        = TokenSignKeysAndCertDir + "jwt.key"
 	TokenSignCertFile       = TokenSignKeysAndCertDir + "jwtsigncert.pem"
+	MfaKeyFile              = ConfigDir + "mfa.key"
 
 	TrustedCAsStoreDir = ConfigDir + "certs/trustedca/"
 	PIDFile            = "authservice.pid"
@@ -38,7 +39,12 @@ const (
 	DefaultKeyAlgorithm            = "rsa"
 	DefaultKeyLength               = 3072
 	DefaultAasJwtCn                = "AAS JWT Signing Certificate"
+	DefaultAasJwtIssuer            = "AAS JWT Issuer"
 	DefaultAasJwtDurationMins      = 120
+	DefaultAasAccessTokenMins      = 15
+	DefaultAasRefreshTokenMins     = 1440
+	DefaultRevocationListCacheSecs = 30
+	DefaultRevocationListStaleSecs = 300
 	DefaultJwtValidateCacheKeyMins = 60
 	DefaultLogEntryMaxLength       = 1500
 )
@@ -67,6 +73,13 @@ const (
 	DefaultTLSKeyFile  = ConfigDir + "tls.key"
 )
 
+// ldap constants
+const (
+	DefaultLdapUserFilter     = "(uid=%s)"
+	DefaultLdapGroupAttribute = "memberOf"
+	DefaultLdapTimeout        = 10 * time.Second
+)
+
 const (
 	DefaultAuthDefendMaxAttempts  = 5
 	DefaultAuthDefendIntervalMins = 5
@@ -91,13 +104,14 @@ const (
 	Running State = true
 )
 
-var DefaultRoles = [4]string{Administrator, RoleManager, UserManager, UserRoleManager}
+var DefaultRoles = [5]string{Administrator, RoleManager, UserManager, UserRoleManager, ClientManager}
 
 const (
 	Administrator   = "Administrator"
 	RoleManager     = "RoleManager"
 	UserManager     = "UserManager"
 	UserRoleManager = "UserRoleManager"
+	ClientManager   = "ClientManager"
 )
 
 func GetDefaultAdministratorRoles() []ct.RoleCreate {
@@ -143,5 +157,16 @@ func GetDefaultAdministratorRoles() []ct.RoleCreate {
 				UserRoleCreate + ":*", UserRoleRetrieve + ":*", UserRoleSearch + ":*", UserRoleDelete + ":*",
 			},
 		},
+		{
+			RoleInfo: ct.RoleInfo{
+				Service: ServiceName,
+				Name:    ClientManager,
+				Context: "",
+			},
+			Permissions: []string{
+				ClientCreate + ":*", ClientRetrieve + ":*", ClientSearch + ":*", ClientDelete + ":*",
+				ClientRoleCreate + ":*", ClientRoleSearch + ":*", ClientRoleDelete + ":*",
+			},
+		},
 	}
 }
@@ -21,4 +21,17 @@ const (
 	UserRoleRetrieve = "user_roles:retrieve"
 	UserRoleSearch   = "user_roles:search"
 	UserRoleDelete   = "user_roles:delete"
+
+	ClientCreate   = "clients:create"
+	ClientRetrieve = "clients:retrieve"
+	ClientSearch   = "clients:search"
+	ClientDelete   = "clients:delete"
+
+	ClientRoleCreate = "client_roles:create"
+	ClientRoleSearch = "client_roles:search"
+	ClientRoleDelete = "client_roles:delete"
+
+	TokenRevoke = "tokens:revoke"
+
+	AuditEventSearch = "audit_events:search"
 )
@@ -0,0 +1,145 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	comctx "github.com/intel-secl/intel-secl/v3/pkg/lib/common/context"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/validation"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+
+	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
+)
+
+const defaultAuditEventLimit = 1000
+
+var auditActions = map[string]bool{
+	types.AuditUserCreate:       true,
+	types.AuditUserUpdate:       true,
+	types.AuditUserDelete:       true,
+	types.AuditUserPassword:     true,
+	types.AuditUserRolesAdd:     true,
+	types.AuditUserRoleDelete:   true,
+	types.AuditRoleCreate:       true,
+	types.AuditRoleDelete:       true,
+	types.AuditClientRolesAdd:   true,
+	types.AuditClientRoleDelete: true,
+}
+
+type AuditEventController struct {
+	Database domain.AASDatabase
+}
+
+// SearchAuditEvents returns the changes of the users, roles and permissions, the most recent first. The events are
+// filtered by actor, target (ID or name), action, granted permission and time range.
+func (controller AuditEventController) SearchAuditEvents(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to searchAuditEvents")
+	defer defaultLog.Trace("searchAuditEvents return")
+
+	query := r.URL.Query()
+	search := types.AuditEventSearch{
+		Actor:      query.Get("actor"),
+		Target:     query.Get("target"),
+		Action:     query.Get("action"),
+		Permission: query.Get("permission"),
+		Limit:      defaultAuditEventLimit,
+	}
+	for _, name := range []string{search.Actor, search.Target} {
+		if name == "" {
+			continue
+		}
+		if validationErr := validation.ValidateUserNameString(name); validationErr != nil {
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
+		}
+	}
+	if search.Action != "" && !auditActions[search.Action] {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid audit event action"}
+	}
+	if validationErr := ValidateContextString(search.Permission); validationErr != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid permission"}
+	}
+	var err error
+	if from := query.Get("from"); from != "" {
+		if search.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid from time, RFC3339 format expected"}
+		}
+	}
+	if to := query.Get("to"); to != "" {
+		if search.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid to time, RFC3339 format expected"}
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if search.Limit, err = strconv.Atoi(limit); err != nil || search.Limit <= 0 {
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid limit"}
+		}
+	}
+
+	events, err := controller.Database.AuditStore().Search(&search)
+	if err != nil {
+		defaultLog.WithError(err).Error("failed to search audit events")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "failed to search audit events"}
+	}
+	if events == nil {
+		events = types.AuditEvents{}
+	}
+	eventsBytes, err := json.Marshal(events)
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to marshal audit events"}
+	}
+	secLog.Infof("%s: Return audit event query to: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return string(eventsBytes), http.StatusOK, nil
+}
+
+// recordAuditEvent records a change of the users, roles or permissions in the audit trail. The actor is the subject
+// of the bearer token unless it is set. The change is already done, a failure to record it is logged.
+func recordAuditEvent(db domain.AASDatabase, r *http.Request, event types.AuditEvent) {
+	if event.Actor == "" {
+		event.Actor, _ = comctx.GetTokenSubject(r)
+	}
+	event.SourceIP = r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		event.SourceIP = host
+	}
+	if err := db.AuditStore().Create(event); err != nil {
+		secLog.WithError(err).WithField("event", event).Errorf("%s: Failed to record audit event", commLogMsg.AppRuntimeErr)
+	}
+}
+
+// userAuditPermissions returns the permissions of a user for the audit trail
+func userAuditPermissions(db domain.AASDatabase, user types.User) types.AuditPermissions {
+	permissions, err := db.UserStore().GetPermissions(types.User{ID: user.ID}, nil)
+	if err != nil {
+		defaultLog.WithError(err).WithField("id", user.ID).Error("failed to retrieve the permissions of user for the audit trail")
+	}
+	return permissions
+}
+
+// clientAuditPermissions returns the permissions of a client for the audit trail
+func clientAuditPermissions(db domain.AASDatabase, client types.Client) types.AuditPermissions {
+	permissions, err := db.ClientStore().GetPermissions(client, nil)
+	if err != nil {
+		defaultLog.WithError(err).WithField("id", client.ID).Error("failed to retrieve the permissions of client for the audit trail")
+	}
+	return permissions
+}
+
+// roleAuditPermissions returns the permissions of a role for the audit trail
+func roleAuditPermissions(role types.Role) types.AuditPermissions {
+	permission := aasModel.PermissionInfo{Service: role.Service, Context: role.Context, Rules: []string{}}
+	for _, p := range role.Permissions {
+		permission.Rules = append(permission.Rules, p.Rule)
+	}
+	return types.AuditPermissions{permission}
+}
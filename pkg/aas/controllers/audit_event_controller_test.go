@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/postgres/mock"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	comctx "github.com/intel-secl/intel-secl/v3/pkg/lib/common/context"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateRoleAuditEvent(t *testing.T) {
+	var events []types.AuditEvent
+	db := &mock.MockDatabase{}
+	db.MockRoleStore.RetrieveFunc = func(rs *types.RoleSearch) (*types.Role, error) {
+		return nil, errors.New("record not found")
+	}
+	db.MockRoleStore.CreateFunc = func(role types.Role) (*types.Role, error) {
+		role.ID = uuid.New().String()
+		return &role, nil
+	}
+	db.MockPermissionStore.RetrieveFunc = func(ps *types.PermissionSearch) (*types.Permission, error) {
+		return nil, errors.New("record not found")
+	}
+	db.MockPermissionStore.CreateFunc = func(p types.Permission) (*types.Permission, error) {
+		p.ID = uuid.New().String()
+		return &p, nil
+	}
+	db.MockAuditStore.CreateFunc = func(event types.AuditEvent) error {
+		events = append(events, event)
+		return nil
+	}
+	controller := RolesController{Database: db}
+
+	body, _ := json.Marshal(aasModel.RoleCreate{
+		RoleInfo:    aasModel.RoleInfo{Service: "KBS", Name: "KeyTransfer"},
+		Permissions: []string{"keys:transfer:*"},
+	})
+	r := httptest.NewRequest(http.MethodPost, "/aas/roles", bytes.NewReader(body))
+	r.RemoteAddr = "10.1.2.3:41234"
+	r = comctx.SetTokenSubject(r, "admin")
+	r = comctx.SetUserPermissions(r, []aasModel.PermissionInfo{{Service: consts.ServiceName, Rules: []string{"*:*:*"}}})
+	_, status, err := controller.CreateRole(httptest.NewRecorder(), r)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, status)
+
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "admin", events[0].Actor)
+		assert.Equal(t, types.AuditRoleCreate, events[0].Action)
+		assert.Equal(t, "KeyTransfer", events[0].TargetName)
+		assert.Equal(t, "10.1.2.3", events[0].SourceIP)
+		assert.Empty(t, events[0].Before)
+		assert.Equal(t, types.AuditPermissions{{Service: "KBS", Rules: []string{"keys:transfer:*"}}}, events[0].After)
+	}
+}
+
+func TestSearchAuditEvents(t *testing.T) {
+	var search *types.AuditEventSearch
+	db := &mock.MockDatabase{}
+	db.MockAuditStore.SearchFunc = func(s *types.AuditEventSearch) (types.AuditEvents, error) {
+		search = s
+		return types.AuditEvents{{ID: uuid.New().String(), Actor: "admin", Action: types.AuditUserRolesAdd,
+			TargetType: types.AuditTargetUser, TargetName: "operator"}}, nil
+	}
+	controller := AuditEventController{Database: db}
+
+	data, status, err := controller.SearchAuditEvents(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet,
+		"/aas/audit-events?actor=admin&target=operator&action=user_roles:add&permission=keys:transfer&from=2020-10-01T00:00:00Z", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	var events types.AuditEvents
+	assert.NoError(t, json.Unmarshal([]byte(data.(string)), &events))
+	assert.Len(t, events, 1)
+	if assert.NotNil(t, search) {
+		assert.Equal(t, "admin", search.Actor)
+		assert.Equal(t, "operator", search.Target)
+		assert.Equal(t, types.AuditUserRolesAdd, search.Action)
+		assert.Equal(t, "keys:transfer", search.Permission)
+		assert.Equal(t, time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC), search.From)
+		assert.Equal(t, defaultAuditEventLimit, search.Limit)
+	}
+
+	for _, query := range []string{"action=keys:transfer", "from=yesterday", "limit=0", "permission=keys%0Atransfer"} {
+		_, status, _ = controller.SearchAuditEvents(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet,
+			"/aas/audit-events?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, status, query)
+	}
+}
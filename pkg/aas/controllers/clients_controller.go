@@ -0,0 +1,381 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	consts "github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/validation"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/gorilla/mux"
+
+	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
+)
+
+const (
+	ClientSecretBasic = "client_secret_basic"
+	ClientSecretPost  = "client_secret_post"
+	PrivateKeyJwt     = "private_key_jwt"
+	TlsClientAuth     = "tls_client_auth"
+
+	clientSecretLength = 32
+)
+
+type ClientsController struct {
+	Database domain.AASDatabase
+	// TokenValidity is the longest validity of the tokens issued by AAS
+	TokenValidity time.Duration
+}
+
+func (controller ClientsController) CreateClient(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to createClient")
+	defer defaultLog.Trace("createClient return")
+
+	var cc aasModel.ClientCreate
+
+	if r.ContentLength == 0 {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body was not provided"}
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&cc)
+	if err != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	validationErr := validation.ValidateUserNameString(cc.Name)
+	if validationErr != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
+	}
+
+	client := types.Client{Name: cc.Name, TokenEndpointAuthMethod: cc.TokenEndpointAuthMethod}
+	var secret string
+	switch cc.TokenEndpointAuthMethod {
+	case ClientSecretBasic, ClientSecretPost:
+		if cc.CertificateCommonName != "" {
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "certificate_common_name is only supported for certificate based authentication methods"}
+		}
+		secretBytes := make([]byte, clientSecretLength)
+		if _, err = rand.Read(secretBytes); err != nil {
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "could not generate client secret"}
+		}
+		secret = base64.RawURLEncoding.EncodeToString(secretBytes)
+		client.SecretHash, err = bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: err.Error()}
+		}
+	case PrivateKeyJwt, TlsClientAuth:
+		if cc.CertificateCommonName == "" {
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "certificate_common_name is required for " + cc.TokenEndpointAuthMethod}
+		}
+		if validationErr = validation.ValidateIssuer(cc.CertificateCommonName); validationErr != nil {
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid certificate_common_name"}
+		}
+		client.CertificateCommonName = cc.CertificateCommonName
+	default:
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "token_endpoint_auth_method must be one of " +
+			strings.Join([]string{ClientSecretBasic, ClientSecretPost, PrivateKeyJwt, TlsClientAuth}, ", ")}
+	}
+
+	existingClient, err := controller.Database.ClientStore().Retrieve(types.Client{Name: cc.Name})
+	if existingClient != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "same client exists"}
+	}
+
+	created, err := controller.Database.ClientStore().Create(client)
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: err.Error()}
+	}
+	secLog.WithField("client", created.ID).Infof("%s: Client created by: %s", commLogMsg.UserAdded, r.RemoteAddr)
+
+	createdClientBytes, err := json.Marshal(aasModel.ClientCreateResponse{
+		ID:                      created.ID,
+		Name:                    created.Name,
+		TokenEndpointAuthMethod: created.TokenEndpointAuthMethod,
+		CertificateCommonName:   created.CertificateCommonName,
+		Secret:                  secret,
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: err.Error()}
+	}
+	return string(createdClientBytes), http.StatusCreated, nil
+}
+
+func (controller ClientsController) GetClient(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to getClient")
+	defer defaultLog.Trace("getClient return")
+
+	id := mux.Vars(r)["id"]
+
+	validationErr := validation.ValidateUUIDv4(id)
+	if validationErr != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
+	}
+
+	c, err := controller.Database.ClientStore().Retrieve(types.Client{ID: id})
+	if err != nil {
+		defaultLog.WithError(err).WithField("id", id).Info("Failed to retrieve client")
+		if strings.Contains(err.Error(), commErr.RecordNotFound) {
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Client not found"}
+		}
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "failed to retrieve client"}
+	}
+	clientBytes, err := json.Marshal(c)
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: err.Error()}
+	}
+	secLog.WithField("client", c.ID).Infof("%s: Return get client request to: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return string(clientBytes), http.StatusOK, nil
+}
+
+func (controller ClientsController) DeleteClient(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to deleteClient")
+	defer defaultLog.Trace("deleteClient return")
+
+	id := mux.Vars(r)["id"]
+
+	validationErr := validation.ValidateUUIDv4(id)
+	if validationErr != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
+	}
+
+	delClient, err := controller.Database.ClientStore().Retrieve(types.Client{ID: id})
+	if delClient == nil || err != nil {
+		defaultLog.WithError(err).WithField("id", id).Info("attempt to delete invalid client")
+		return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Client not found"}
+	}
+
+	if err := controller.Database.ClientStore().Delete(*delClient); err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: err.Error()}
+	}
+	if err := revokeSubject(controller.Database, delClient.ID, controller.TokenValidity); err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error("failed to revoke the tokens of deleted client")
+	}
+	secLog.WithField("client", delClient.ID).Infof("%s: Client deleted by: %s", commLogMsg.UserDeleted, r.RemoteAddr)
+
+	return nil, http.StatusNoContent, nil
+}
+
+func (controller ClientsController) QueryClients(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to queryClients")
+	defer defaultLog.Trace("queryClients return")
+
+	defaultLog.WithField("query", r.URL.Query()).Trace("query clients")
+	clientName := r.URL.Query().Get("name")
+
+	if len(clientName) != 0 {
+		if validationErr := validation.ValidateUserNameString(clientName); validationErr != nil {
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
+		}
+	}
+
+	filter := types.Client{
+		Name: clientName,
+	}
+
+	clients, err := controller.Database.ClientStore().RetrieveAll(filter)
+	if err != nil {
+		log.WithError(err).WithField("filter", filter.Name).Error("failed to retrieve clients")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: err.Error()}
+	}
+	if clients == nil {
+		clients = types.Clients{}
+	}
+
+	clientBytes, err := json.Marshal(clients)
+	if err != nil {
+		log.WithError(err).Error("Failed to marshal client content to JSON")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: err.Error()}
+	}
+	secLog.Infof("%s: Return client query to: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return string(clientBytes), http.StatusOK, nil
+}
+
+func (controller ClientsController) AddClientRoles(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to addClientRoles")
+	defer defaultLog.Trace("addClientRoles return")
+
+	// authorize rest api endpoint based on token
+	svcFltr, err := authorizeEndPointAndGetServiceFilter(r, []string{consts.ClientRoleCreate})
+	if err != nil {
+		secLog.Warningf("%s: Unauthorized add client role attempt from: %s", commLogMsg.UnauthorizedAccess, r.RemoteAddr)
+		return nil, http.StatusUnauthorized, err
+	}
+
+	id := mux.Vars(r)["id"]
+
+	validationErr := validation.ValidateUUIDv4(id)
+	if validationErr != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
+	}
+
+	if r.ContentLength == 0 {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body was not provided"}
+	}
+
+	var rids aasModel.RoleIDs
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&rids)
+	if err != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	if len(rids.RoleUUIDs) == 0 {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "At least one role id is required"}
+	}
+
+	for _, rid := range rids.RoleUUIDs {
+		validationErr = validation.ValidateUUIDv4(rid)
+		if validationErr != nil {
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "One or more role ids is not a valid uuid"}
+		}
+	}
+
+	roles, err := controller.Database.RoleStore().RetrieveAll(&types.RoleSearch{
+		IDFilter:      rids.RoleUUIDs,
+		ServiceFilter: svcFltr,
+		AllContexts:   true,
+	})
+	if err != nil {
+		log.WithError(err).Info("failed to retrieve roles")
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "failed to retrieve roles"}
+	}
+
+	if len(roles) != len(rids.RoleUUIDs) {
+		errMsg := fmt.Sprintf("could not find matching role or user does not have authorization - requested roles - %s", rids.RoleUUIDs)
+		log.Error(errMsg)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: errMsg}
+	}
+
+	c, err := controller.Database.ClientStore().Retrieve(types.Client{ID: id})
+	if err != nil {
+		log.WithError(err).WithField("id", id).Info("failed to retrieve client")
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: fmt.Sprintf("failed to retrieve client: %s", id)}
+	}
+
+	permissions := clientAuditPermissions(controller.Database, *c)
+	err = controller.Database.ClientStore().AddRoles(*c, roles)
+	if err != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+	recordAuditEvent(controller.Database, r, types.AuditEvent{Action: types.AuditClientRolesAdd,
+		TargetType: types.AuditTargetClient, TargetID: c.ID, TargetName: c.Name,
+		Before: permissions, After: clientAuditPermissions(controller.Database, *c)})
+	secLog.WithField("client", c.ID).Infof("%s: Client roles added by: %s", commLogMsg.PrivilegeModified, r.RemoteAddr)
+
+	return nil, http.StatusCreated, nil
+}
+
+func (controller ClientsController) QueryClientRoles(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to queryClientRoles")
+	defer defaultLog.Trace("queryClientRoles return")
+
+	// authorize rest api endpoint based on token
+	svcFltr, err := authorizeEndPointAndGetServiceFilter(r, []string{consts.ClientRoleSearch})
+	if err != nil {
+		secLog.Warningf("%s: Unauthorized query client role attempt from: %s", commLogMsg.UnauthorizedAccess, r.RemoteAddr)
+		return nil, http.StatusUnauthorized, err
+	}
+
+	id := mux.Vars(r)["id"]
+
+	validationErr := validation.ValidateUUIDv4(id)
+	if validationErr != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
+	}
+
+	roleSearchFilter := &types.RoleSearch{
+		AllContexts:   true,
+		ServiceFilter: svcFltr,
+	}
+
+	clientRoles, err := controller.Database.ClientStore().GetRoles(types.Client{ID: id}, roleSearchFilter, true)
+	if err != nil {
+		log.WithError(err).Error("failed to retrieve client roles")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: err.Error()}
+	}
+	if clientRoles == nil {
+		clientRoles = []types.Role{}
+	}
+
+	clientRoleBytes, err := json.Marshal(clientRoles)
+	if err != nil {
+		log.WithError(err).Error("Failed to marshal client roles to JSON")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	secLog.Infof("%s: Return client role query request to: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return string(clientRoleBytes), http.StatusOK, nil
+}
+
+func (controller ClientsController) DeleteClientRole(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to deleteClientRole")
+	defer defaultLog.Trace("deleteClientRole return")
+	// authorize rest api endpoint based on token
+	svcFltr, err := authorizeEndPointAndGetServiceFilter(r, []string{consts.ClientRoleDelete})
+	if err != nil {
+		secLog.Warningf("%s: Unauthorized delete client role attempt from: %s", commLogMsg.UnauthorizedAccess, r.RemoteAddr)
+		return nil, http.StatusUnauthorized, err
+	}
+
+	id := mux.Vars(r)["id"]
+	rid := mux.Vars(r)["role_id"]
+
+	validationErr := validation.ValidateUUIDv4(id)
+	if validationErr != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
+	}
+
+	validationErr = validation.ValidateUUIDv4(rid)
+	if validationErr != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
+	}
+
+	c, err := controller.Database.ClientStore().Retrieve(types.Client{ID: id})
+	if err != nil {
+		defaultLog.WithError(err).WithField("id", id).Info("failed to retrieve client")
+		if strings.Contains(err.Error(), commErr.RecordNotFound) {
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Client not found"}
+		}
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "failed to retrieve client"}
+	}
+
+	permissions := clientAuditPermissions(controller.Database, *c)
+	err = controller.Database.ClientStore().DeleteRole(*c, rid, svcFltr)
+	if err != nil {
+		defaultLog.WithError(err).WithField("id", id).WithField("rid", rid).Info("failed to delete role from client")
+		if strings.Contains(err.Error(), commErr.RecordNotFound) {
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Role to be deleted not found"}
+		}
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "failed to delete role from client"}
+	}
+	recordAuditEvent(controller.Database, r, types.AuditEvent{Action: types.AuditClientRoleDelete,
+		TargetType: types.AuditTargetClient, TargetID: c.ID, TargetName: c.Name,
+		Before: permissions, After: clientAuditPermissions(controller.Database, *c)})
+	secLog.WithField("client", c.ID).Infof("%s: Client roles deleted by: %s", commLogMsg.PrivilegeModified, r.RemoteAddr)
+	return nil, http.StatusNoContent, nil
+}
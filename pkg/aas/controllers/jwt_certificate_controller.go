@@ -5,9 +5,13 @@
 package controllers
 
 import (
+	"encoding/json"
 	consts "github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
 	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
+	cos "github.com/intel-secl/intel-secl/v3/pkg/lib/common/os"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/validation"
+	ct "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
 	"io/ioutil"
 	"net/http"
 	"regexp"
@@ -16,6 +20,11 @@ import (
 )
 
 type JwtCertificateController struct {
+	// Issuer is the iss claim of the tokens issued by AAS
+	Issuer string
+	// SigningCertsDir holds the token signing certificates that are published in the JWK set. A certificate that
+	// is about to be used for signing tokens is added to the directory ahead of time for the key rotation
+	SigningCertsDir string
 }
 
 func (controller JwtCertificateController) GetJwtCertificate(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
@@ -38,3 +47,71 @@ func (controller JwtCertificateController) GetJwtCertificate(w http.ResponseWrit
 	secLog.Info(commLogMsg.AuthorizedAccess, r.RemoteAddr)
 	return string(tokenCertificate), http.StatusOK, nil
 }
+
+// GetJwks publishes the public keys of the token signing certificates as a JWK set (RFC 7517)
+func (controller JwtCertificateController) GetJwks(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to getJwks")
+	defer defaultLog.Trace("getJwks return")
+
+	jwks, err := controller.jsonWebKeySet()
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/jwt_certificate_controller:GetJwks() Failed to build the JWK set")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Invalid jwt certificate"}
+	}
+	response, err := json.Marshal(jwks)
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to marshal JWK set"}
+	}
+	secLog.Info(commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return string(response), http.StatusOK, nil
+}
+
+// GetOpenIDConfiguration returns the OpenID Connect discovery document of AAS so that third party OAuth2 clients and
+// API gateways can find the token endpoint and the signing keys without AAS specific configuration
+func (controller JwtCertificateController) GetOpenIDConfiguration(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to getOpenIDConfiguration")
+	defer defaultLog.Trace("getOpenIDConfiguration return")
+
+	jwks, err := controller.jsonWebKeySet()
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/jwt_certificate_controller:GetOpenIDConfiguration() Failed to build the JWK set")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Invalid jwt certificate"}
+	}
+	algs := []string{}
+	seen := map[string]bool{}
+	for _, key := range jwks.Keys {
+		if !seen[key.Alg] {
+			seen[key.Alg] = true
+			algs = append(algs, key.Alg)
+		}
+	}
+
+	baseURL := "https://" + r.Host + "/aas"
+	response, err := json.Marshal(ct.OpenIDConfiguration{
+		Issuer:                            controller.Issuer,
+		JwksURI:                           baseURL + "/noauth/jwks",
+		TokenEndpoint:                     baseURL + "/oauth2/token",
+		RevocationEndpoint:                baseURL + "/oauth2/revoke",
+		GrantTypesSupported:               []string{GrantTypeClientCredentials, GrantTypePassword, GrantTypeRefreshToken},
+		TokenEndpointAuthMethodsSupported: []string{ClientSecretBasic, ClientSecretPost, PrivateKeyJwt, TlsClientAuth},
+		ResponseTypesSupported:            []string{"token"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  algs,
+		ClaimsSupported:                   []string{"iss", "sub", "exp", "iat", "jti", "roles", "permissions"},
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to marshal OpenID configuration"}
+	}
+	secLog.Info(commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return string(response), http.StatusOK, nil
+}
+
+func (controller JwtCertificateController) jsonWebKeySet() (*jwtauth.JSONWebKeySet, error) {
+	certPems, err := cos.GetDirFileContents(controller.SigningCertsDir, "*.pem")
+	if err != nil {
+		return nil, err
+	}
+	return jwtauth.NewJSONWebKeySet(certPems...)
+}
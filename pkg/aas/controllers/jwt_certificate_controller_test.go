@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/stretchr/testify/assert"
+)
+
+func certPem(certs ...*x509.Certificate) []byte {
+	var pemBytes []byte
+	for _, cert := range certs {
+		pemBytes = append(pemBytes, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	return pemBytes
+}
+
+func newSigningTokenFactory(t *testing.T, cert *x509.Certificate, key *ecdsa.PrivateKey) *jwtauth.JwtFactory {
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	tokenFactory, err := jwtauth.NewTokenFactory(pkcs8, true, certPem(cert), "AAS JWT Issuer", 0)
+	assert.NoError(t, err)
+	return tokenFactory
+}
+
+func TestGetJwksKeyRotation(t *testing.T) {
+	ca, caKey := newCertificate(t, "CMS Signing CA", true, nil, nil)
+	current, currentKey := newCertificate(t, "AAS JWT Signing Certificate", false, ca, caKey)
+	next, nextKey := newCertificate(t, "AAS JWT Signing Certificate", false, ca, caKey)
+
+	dir, err := ioutil.TempDir("", "tokensign")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "jwtsigncert.pem"), certPem(current, ca), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "jwtsigncert-next.pem"), certPem(next, ca), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "jwt.key"), []byte("not published"), 0600))
+	controller := JwtCertificateController{Issuer: "https://aas.example.com:8444/aas", SigningCertsDir: dir}
+
+	data, status, err := controller.GetJwks(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/aas/noauth/jwks", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	var jwks jwtauth.JSONWebKeySet
+	assert.NoError(t, json.Unmarshal([]byte(data.(string)), &jwks))
+	assert.Len(t, jwks.Keys, 2)
+	for _, key := range jwks.Keys {
+		assert.Equal(t, "EC", key.Kty)
+		assert.Equal(t, "ES256", key.Alg)
+		assert.Len(t, key.X5c, 2)
+	}
+
+	// tokens of both keys are accepted by a verifier built from the JWK set, the key is selected by the kid
+	verifier, err := jwtauth.NewVerifier(jwks, [][]byte{certPem(ca)}, time.Hour)
+	assert.NoError(t, err)
+	for _, tokenFactory := range []*jwtauth.JwtFactory{
+		newSigningTokenFactory(t, current, currentKey),
+		newSigningTokenFactory(t, next, nextKey),
+	} {
+		token, err := tokenFactory.Create(&aasModel.AuthClaims{}, "admin", time.Minute)
+		assert.NoError(t, err)
+		_, err = verifier.ValidateTokenAndGetClaims(token, &aasModel.AuthClaims{})
+		assert.NoError(t, err)
+	}
+
+	// a key that is not in the set is rejected
+	other, otherKey := newCertificate(t, "AAS JWT Signing Certificate", false, ca, caKey)
+	token, err := newSigningTokenFactory(t, other, otherKey).Create(&aasModel.AuthClaims{}, "admin", time.Minute)
+	assert.NoError(t, err)
+	_, err = verifier.ValidateTokenAndGetClaims(token, &aasModel.AuthClaims{})
+	assert.Error(t, err)
+}
+
+func TestGetOpenIDConfiguration(t *testing.T) {
+	ca, caKey := newCertificate(t, "CMS Signing CA", true, nil, nil)
+	cert, _ := newCertificate(t, "AAS JWT Signing Certificate", false, ca, caKey)
+	dir, err := ioutil.TempDir("", "tokensign")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "jwtsigncert.pem"), certPem(cert, ca), 0600))
+	controller := JwtCertificateController{Issuer: "https://aas.example.com:8444/aas", SigningCertsDir: dir}
+
+	r := httptest.NewRequest(http.MethodGet, "/aas/.well-known/openid-configuration", nil)
+	r.Host = "aas.example.com:8444"
+	data, status, err := controller.GetOpenIDConfiguration(httptest.NewRecorder(), r)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+
+	var discovery aasModel.OpenIDConfiguration
+	assert.NoError(t, json.Unmarshal([]byte(data.(string)), &discovery))
+	assert.Equal(t, controller.Issuer, discovery.Issuer)
+	assert.Equal(t, "https://aas.example.com:8444/aas/noauth/jwks", discovery.JwksURI)
+	assert.Equal(t, "https://aas.example.com:8444/aas/oauth2/token", discovery.TokenEndpoint)
+	assert.Equal(t, []string{"ES256"}, discovery.IDTokenSigningAlgValuesSupported)
+	assert.Contains(t, discovery.GrantTypesSupported, GrantTypeClientCredentials)
+}
@@ -58,7 +58,7 @@ func (controller JwtTokenController) CreateJwtToken(w http.ResponseWriter, r *ht
 
 	u := controller.Database.UserStore()
 
-	if httpStatus, err := authcommon.HttpHandleUserAuth(u, uc.UserName, uc.Password); err != nil {
+	if httpStatus, err := authcommon.HttpHandleUserAuthWithMfa(controller.Database, uc.UserName, uc.Password, uc.TotpCode); err != nil {
 		secLog.Warningf("%s: User [%s] authentication failed, requested from %s: ", commLogMsg.AuthenticationFailed, uc.UserName, r.RemoteAddr)
 		return nil, httpStatus, &commErr.ResourceError{Message: err.Error()}
 	}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	authcommon "github.com/intel-secl/intel-secl/v3/pkg/aas/common"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/validation"
+
+	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
+)
+
+type LockoutController struct {
+	Database domain.AASDatabase
+}
+
+// GetLockouts returns the users and clients that are locked out after too many failed authentications
+func (controller LockoutController) GetLockouts(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to getLockouts")
+	defer defaultLog.Trace("getLockouts return")
+
+	lockouts, err := authcommon.LockedOutAccounts()
+	if err != nil {
+		defaultLog.WithError(err).Error("failed to retrieve lockouts")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "failed to retrieve lockouts"}
+	}
+	lockoutsBytes, err := json.Marshal(lockouts)
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to marshal lockouts"}
+	}
+	secLog.Infof("%s: Return lockouts request to: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return string(lockoutsBytes), http.StatusOK, nil
+}
+
+// UnlockUser clears the failed authentications of a user so that it can authenticate before its lockout expires
+func (controller LockoutController) UnlockUser(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to unlockUser")
+	defer defaultLog.Trace("unlockUser return")
+
+	id := mux.Vars(r)["id"]
+	if validationErr := validation.ValidateUUIDv4(id); validationErr != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
+	}
+
+	user, err := controller.Database.UserStore().Retrieve(types.User{ID: id})
+	if err != nil {
+		defaultLog.WithError(err).WithField("id", id).Info("failed to retrieve user")
+		if strings.Contains(err.Error(), commErr.RecordNotFound) {
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "User not found"}
+		}
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "failed to retrieve user"}
+	}
+	if err = authcommon.UnlockUser(user.Name); err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error("failed to unlock user")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "failed to unlock user"}
+	}
+	secLog.WithField("user", user.Name).Infof("%s: User unlocked by: %s", commLogMsg.PrivilegeModified, r.RemoteAddr)
+	return nil, http.StatusNoContent, nil
+}
+
+// UnlockClient clears the failed authentications of a client so that it can authenticate before its lockout expires
+func (controller LockoutController) UnlockClient(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to unlockClient")
+	defer defaultLog.Trace("unlockClient return")
+
+	id := mux.Vars(r)["id"]
+	if validationErr := validation.ValidateUUIDv4(id); validationErr != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
+	}
+
+	client, err := controller.Database.ClientStore().Retrieve(types.Client{ID: id})
+	if client == nil || err != nil {
+		defaultLog.WithError(err).WithField("id", id).Info("failed to retrieve client")
+		return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Client not found"}
+	}
+	if err = authcommon.UnlockClient(client.ID); err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error("failed to unlock client")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "failed to unlock client"}
+	}
+	secLog.WithField("client", client.ID).Infof("%s: Client unlocked by: %s", commLogMsg.PrivilegeModified, r.RemoteAddr)
+	return nil, http.StatusNoContent, nil
+}
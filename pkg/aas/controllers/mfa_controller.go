@@ -0,0 +1,218 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	authcommon "github.com/intel-secl/intel-secl/v3/pkg/aas/common"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/mfa"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/validation"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/pkg/errors"
+
+	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
+)
+
+type MfaController struct {
+	Database domain.AASDatabase
+}
+
+func (controller MfaController) decodeEnrollmentRequest(r *http.Request) (*aasModel.TotpEnrollmentRequest, int, error) {
+	if r.ContentLength == 0 {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body was not provided"}
+	}
+
+	var er aasModel.TotpEnrollmentRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&er); err != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	if validationErr := validation.ValidateUserNameString(er.UserName); validationErr != nil {
+		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: validationErr.Error()}
+	}
+	if validationErr := validation.ValidatePasswordString(er.Password); validationErr != nil {
+		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: validationErr.Error()}
+	}
+	return &er, 0, nil
+}
+
+// EnrollTotp starts the enrollment of a TOTP authenticator. The user authenticates with its password, and with the
+// code of its current authenticator when it replaces one. The new authenticator is used once it is confirmed.
+func (controller MfaController) EnrollTotp(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to enrollTotp")
+	defer defaultLog.Trace("enrollTotp return")
+
+	if !authcommon.MfaEnabled() {
+		return nil, http.StatusNotFound, &commErr.ResourceError{Message: "MFA is not enabled"}
+	}
+	er, httpStatus, err := controller.decodeEnrollmentRequest(r)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	// the enrollment is allowed to the users that must enroll before they get a token
+	httpStatus, err = authcommon.HttpHandleUserAuthWithMfa(controller.Database, er.UserName, er.Password, er.TotpCode)
+	if err != nil && errors.Cause(err) != authcommon.ErrMfaEnrollmentRequired {
+		secLog.Warningf("%s: User [%s] auth failed, requested from %s: ", commLogMsg.UnauthorizedAccess, er.UserName, r.RemoteAddr)
+		return nil, httpStatus, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	user, err := controller.Database.UserStore().Retrieve(types.User{Name: er.UserName})
+	if err != nil {
+		defaultLog.WithError(err).Error("not able to retrieve existing user though he was just authenticated")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
+	}
+	userMfa, err := controller.Database.MfaStore().Retrieve(user.ID)
+	if err != nil {
+		defaultLog.WithError(err).Error("failed to retrieve MFA of user")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
+	}
+	if userMfa == nil {
+		userMfa = &types.UserMfa{UserID: user.ID}
+	}
+
+	secret, err := mfa.GenerateSecret()
+	if err != nil {
+		defaultLog.WithError(err).Error("failed to generate TOTP secret")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
+	}
+	userMfa.PendingSecret, err = authcommon.EncryptMfaSecret(secret)
+	if err != nil {
+		defaultLog.WithError(err).Error("failed to encrypt TOTP secret")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
+	}
+	if err = controller.Database.MfaStore().Save(*userMfa); err != nil {
+		defaultLog.WithError(err).Error("failed to save TOTP secret")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
+	}
+
+	response, err := json.Marshal(aasModel.TotpEnrollment{
+		Secret: mfa.EncodeSecret(secret),
+		KeyURI: mfa.KeyURI(consts.ServiceName, user.Name, secret),
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to marshal TOTP enrollment"}
+	}
+	secLog.Infof("%s: TOTP enrollment of user [%s] started from: %s", commLogMsg.AuthorizedAccess, user.Name, r.RemoteAddr)
+	return string(response), http.StatusCreated, nil
+}
+
+// ConfirmTotp enables the authenticator of a started enrollment with one of its codes. New recovery codes are
+// returned, they are not retrievable afterwards.
+func (controller MfaController) ConfirmTotp(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to confirmTotp")
+	defer defaultLog.Trace("confirmTotp return")
+
+	if !authcommon.MfaEnabled() {
+		return nil, http.StatusNotFound, &commErr.ResourceError{Message: "MFA is not enabled"}
+	}
+	er, httpStatus, err := controller.decodeEnrollmentRequest(r)
+	if err != nil {
+		return nil, httpStatus, err
+	}
+
+	// the code confirms the new authenticator, the password authenticates the user
+	if httpStatus, err := authcommon.HttpHandleUserAuth(controller.Database.UserStore(), er.UserName, er.Password); err != nil {
+		secLog.Warningf("%s: User [%s] auth failed, requested from %s: ", commLogMsg.UnauthorizedAccess, er.UserName, r.RemoteAddr)
+		return nil, httpStatus, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	user, err := controller.Database.UserStore().Retrieve(types.User{Name: er.UserName})
+	if err != nil {
+		defaultLog.WithError(err).Error("not able to retrieve existing user though he was just authenticated")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
+	}
+	userMfa, err := controller.Database.MfaStore().Retrieve(user.ID)
+	if err != nil {
+		defaultLog.WithError(err).Error("failed to retrieve MFA of user")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
+	}
+	if userMfa == nil || len(userMfa.PendingSecret) == 0 {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "No TOTP enrollment was started"}
+	}
+
+	secret, err := authcommon.DecryptMfaSecret(userMfa.PendingSecret)
+	if err != nil {
+		defaultLog.WithError(err).Error("failed to decrypt TOTP secret")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
+	}
+	step, ok := mfa.Validate(secret, er.TotpCode, time.Now(), 0)
+	if !ok {
+		secLog.Warningf("%s: Invalid TOTP code to confirm enrollment of user [%s], requested from %s", commLogMsg.AuthenticationFailed,
+			user.Name, r.RemoteAddr)
+		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Invalid TOTP code"}
+	}
+
+	recoveryCodes, err := mfa.GenerateRecoveryCodes()
+	if err != nil {
+		defaultLog.WithError(err).Error("failed to generate recovery codes")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
+	}
+	codeHashes := make([]string, 0, len(recoveryCodes))
+	for _, code := range recoveryCodes {
+		codeHashes = append(codeHashes, mfa.HashRecoveryCode(code))
+	}
+
+	userMfa.TotpSecret = userMfa.PendingSecret
+	userMfa.PendingSecret = nil
+	userMfa.Enabled = true
+	userMfa.LastUsedStep = step
+	if err = controller.Database.MfaStore().Save(*userMfa); err != nil {
+		defaultLog.WithError(err).Error("failed to enable TOTP authenticator")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
+	}
+	if err = controller.Database.MfaStore().ReplaceRecoveryCodes(user.ID, codeHashes); err != nil {
+		defaultLog.WithError(err).Error("failed to save recovery codes")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
+	}
+
+	response, err := json.Marshal(aasModel.MfaRecoveryCodes{RecoveryCodes: recoveryCodes})
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to marshal recovery codes"}
+	}
+	secLog.Infof("%s: TOTP authenticator of user [%s] enabled from: %s", commLogMsg.AuthorizedAccess, user.Name, r.RemoteAddr)
+	return string(response), http.StatusOK, nil
+}
+
+// DeleteUserMfa removes the authenticator and the recovery codes of a user, so that a user who lost both can enroll
+// again
+func (controller MfaController) DeleteUserMfa(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to deleteUserMfa")
+	defer defaultLog.Trace("deleteUserMfa return")
+
+	id := mux.Vars(r)["id"]
+	if validationErr := validation.ValidateUUIDv4(id); validationErr != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
+	}
+
+	user, err := controller.Database.UserStore().Retrieve(types.User{ID: id})
+	if err != nil {
+		defaultLog.WithError(err).WithField("id", id).Info("failed to retrieve user")
+		if strings.Contains(err.Error(), commErr.RecordNotFound) {
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "User not found"}
+		}
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "failed to retrieve user"}
+	}
+	if err = controller.Database.MfaStore().Delete(user.ID); err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error("failed to delete MFA of user")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "failed to delete MFA of user"}
+	}
+	secLog.WithField("user", user.Name).Infof("%s: User MFA deleted by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return nil, http.StatusNoContent, nil
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	authcommon "github.com/intel-secl/intel-secl/v3/pkg/aas/common"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/mfa"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/postgres/mock"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func totpRequest(method string, er aasModel.TotpEnrollmentRequest) *http.Request {
+	body, _ := json.Marshal(er)
+	return httptest.NewRequest(method, "/aas/users/mfa/totp", bytes.NewReader(body))
+}
+
+func TestTotpEnrollment(t *testing.T) {
+	assert.NoError(t, authcommon.SetMfaPolicy(make([]byte, 32), nil))
+	defer authcommon.SetMfaPolicy(nil, nil)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("mfaUserPass"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	user := &types.User{ID: uuid.New().String(), Name: "mfauser", PasswordHash: hash}
+	var userMfa *types.UserMfa
+	var recoveryCodeHashes []string
+
+	db := &mock.MockDatabase{}
+	db.MockUserStore.RetrieveFunc = func(u types.User) (*types.User, error) {
+		if u.Name == user.Name || u.ID == user.ID {
+			return user, nil
+		}
+		return nil, errors.New("record not found")
+	}
+	db.MockMfaStore.RetrieveFunc = func(userID string) (*types.UserMfa, error) {
+		if userMfa == nil {
+			return nil, nil
+		}
+		m := *userMfa
+		return &m, nil
+	}
+	db.MockMfaStore.SaveFunc = func(m types.UserMfa) error {
+		userMfa = &m
+		return nil
+	}
+	db.MockMfaStore.ReplaceRecoveryCodesFunc = func(userID string, codeHashes []string) error {
+		recoveryCodeHashes = codeHashes
+		return nil
+	}
+	controller := MfaController{Database: db}
+
+	data, status, err := controller.EnrollTotp(httptest.NewRecorder(),
+		totpRequest(http.MethodPost, aasModel.TotpEnrollmentRequest{UserName: "mfauser", Password: "mfaUserPass"}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, status)
+	var enrollment aasModel.TotpEnrollment
+	assert.NoError(t, json.Unmarshal([]byte(data.(string)), &enrollment))
+	assert.Contains(t, enrollment.KeyURI, "otpauth://totp/AAS:mfauser?")
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	assert.NoError(t, err)
+	// the secret is stored encrypted and is not used before the enrollment is confirmed
+	if assert.NotNil(t, userMfa) {
+		assert.False(t, userMfa.Enabled)
+		assert.NotContains(t, string(userMfa.PendingSecret), string(secret))
+	}
+
+	_, status, _ = controller.ConfirmTotp(httptest.NewRecorder(), totpRequest(http.MethodPut,
+		aasModel.TotpEnrollmentRequest{UserName: "mfauser", Password: "mfaUserPass", TotpCode: mfa.Code(secret, mfa.Step(time.Now())-5)}))
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	data, status, err = controller.ConfirmTotp(httptest.NewRecorder(), totpRequest(http.MethodPut,
+		aasModel.TotpEnrollmentRequest{UserName: "mfauser", Password: "mfaUserPass", TotpCode: mfa.Code(secret, mfa.Step(time.Now()))}))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	var recoveryCodes aasModel.MfaRecoveryCodes
+	assert.NoError(t, json.Unmarshal([]byte(data.(string)), &recoveryCodes))
+	assert.Len(t, recoveryCodes.RecoveryCodes, mfa.RecoveryCodeCount)
+	assert.Len(t, recoveryCodeHashes, mfa.RecoveryCodeCount)
+	assert.True(t, userMfa.Enabled)
+	assert.Empty(t, userMfa.PendingSecret)
+
+	// the authenticator can only be replaced with a code of the current one
+	_, status, _ = controller.EnrollTotp(httptest.NewRecorder(),
+		totpRequest(http.MethodPost, aasModel.TotpEnrollmentRequest{UserName: "mfauser", Password: "mfaUserPass"}))
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
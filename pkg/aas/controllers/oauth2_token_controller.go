@@ -0,0 +1,530 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/Waterdrips/jwt-go"
+	authcommon "github.com/intel-secl/intel-secl/v3/pkg/aas/common"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/validation"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/pkg/errors"
+
+	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
+)
+
+const (
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypePassword          = "password"
+	GrantTypeRefreshToken      = "refresh_token"
+	ClientAssertionTypeJwt     = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+	// error codes of RFC 6749 section 5.2
+	oauth2InvalidRequest       = "invalid_request"
+	oauth2InvalidClient        = "invalid_client"
+	oauth2InvalidGrant         = "invalid_grant"
+	oauth2InvalidScope         = "invalid_scope"
+	oauth2UnauthorizedClient   = "unauthorized_client"
+	oauth2UnsupportedGrantType = "unsupported_grant_type"
+	oauth2ServerError          = "server_error"
+
+	refreshTokenLength = 32
+)
+
+type OAuth2TokenController struct {
+	Database     domain.AASDatabase
+	TokenFactory *jwtauth.JwtFactory
+	// TokenValidity is the validity of the access tokens issued to clients
+	TokenValidity time.Duration
+	// AccessTokenValidity is the validity of the access tokens issued along with a refresh token
+	AccessTokenValidity  time.Duration
+	RefreshTokenValidity time.Duration
+	// ClientCAs are the CAs that issue the certificates of private_key_jwt clients
+	ClientCAs *x509.CertPool
+}
+
+// usedClientAssertions keeps the jti of client assertions until they expire so that they can not be replayed
+var usedClientAssertions = struct {
+	sync.Mutex
+	jtis map[string]time.Time
+}{jtis: map[string]time.Time{}}
+
+// CreateOAuth2Token implements the token endpoint of RFC 6749. The client credentials grant issues tokens to clients,
+// the password grant issues a short lived access token along with a refresh token to users and the refresh token
+// grant exchanges a refresh token for a new access token and refresh token.
+func (controller OAuth2TokenController) CreateOAuth2Token(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to createOAuth2Token")
+	defer defaultLog.Trace("createOAuth2Token return")
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return oauth2Error(w, http.StatusBadRequest, oauth2InvalidRequest, "Content-Type must be application/x-www-form-urlencoded")
+	}
+	if err := r.ParseForm(); err != nil {
+		return oauth2Error(w, http.StatusBadRequest, oauth2InvalidRequest, "Could not parse the request body")
+	}
+
+	switch r.PostForm.Get("grant_type") {
+	case GrantTypeClientCredentials:
+		return controller.clientCredentialsGrant(w, r)
+	case GrantTypePassword:
+		return controller.passwordGrant(w, r)
+	case GrantTypeRefreshToken:
+		return controller.refreshTokenGrant(w, r)
+	default:
+		return oauth2Error(w, http.StatusBadRequest, oauth2UnsupportedGrantType,
+			"grant_type must be one of client_credentials, password or refresh_token")
+	}
+}
+
+// clientCredentialsGrant implements the client credentials grant of RFC 6749 section 4.4. Clients authenticate with
+// client_secret_basic, client_secret_post, private_key_jwt (RFC 7523) or tls_client_auth (RFC 8705). The requested
+// scopes are the client's roles in the form <service>:<role name>[:<context>], all roles of the client are granted
+// when no scope is requested.
+func (controller OAuth2TokenController) clientCredentialsGrant(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	client, status, errCode, err := controller.authenticateClient(r)
+	if err != nil {
+		secLog.WithError(err).Warningf("%s: Client authentication failed, requested from %s", commLogMsg.AuthenticationFailed, r.RemoteAddr)
+		if status == http.StatusInternalServerError {
+			return oauth2Error(w, status, errCode, "")
+		}
+		return oauth2Error(w, status, errCode, "Client authentication failed")
+	}
+	secLog.Infof("%s: Client [%s] authenticated, requested from %s", commLogMsg.AuthenticationSuccess, client.ID, r.RemoteAddr)
+
+	clientRoles, err := controller.Database.ClientStore().GetRoles(*client, nil, true)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/oauth2_token_controller:clientCredentialsGrant() Failed to retrieve client roles")
+		return oauth2Error(w, http.StatusInternalServerError, oauth2ServerError, "")
+	}
+
+	grantedRoles, grantedScopes, err := rolesForScope(clientRoles, r.PostForm.Get("scope"))
+	if err != nil {
+		secLog.WithError(err).Warningf("%s: Client [%s] requested a scope it was not granted", commLogMsg.UnauthorizedAccess, client.ID)
+		return oauth2Error(w, http.StatusBadRequest, oauth2InvalidScope, err.Error())
+	}
+	if len(grantedRoles) == 0 {
+		return oauth2Error(w, http.StatusBadRequest, oauth2UnauthorizedClient, "The client has not been granted any role")
+	}
+
+	roleIDs := make([]string, 0, len(grantedRoles))
+	// the token carries the roles without their ids as in the tokens of users
+	claimRoles := make(types.Roles, 0, len(grantedRoles))
+	for _, role := range grantedRoles {
+		roleIDs = append(roleIDs, role.ID)
+		claimRoles = append(claimRoles, types.Role{RoleInfo: role.RoleInfo})
+	}
+	perms, err := controller.Database.ClientStore().GetPermissions(*client, roleIDs)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/oauth2_token_controller:clientCredentialsGrant() Failed to retrieve client permissions")
+		return oauth2Error(w, http.StatusInternalServerError, oauth2ServerError, "")
+	}
+
+	token, err := controller.TokenFactory.Create(&roleClaims{Roles: claimRoles, Permissions: perms}, client.ID, controller.TokenValidity)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/oauth2_token_controller:clientCredentialsGrant() Failed to create token")
+		return oauth2Error(w, http.StatusInternalServerError, oauth2ServerError, "")
+	}
+
+	response, err := json.Marshal(aasModel.OAuth2TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(controller.TokenValidity / time.Second),
+		Scope:       strings.Join(grantedScopes, " "),
+	})
+	if err != nil {
+		return oauth2Error(w, http.StatusInternalServerError, oauth2ServerError, "")
+	}
+	secLog.Infof("%s: Return access token of client [%s] to: %s", commLogMsg.TokenIssued, client.ID, r.RemoteAddr)
+	return string(response), http.StatusOK, nil
+}
+
+// passwordGrant implements the resource owner password credentials grant of RFC 6749 section 4.3 for the users of AAS
+func (controller OAuth2TokenController) passwordGrant(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	username := r.PostForm.Get("username")
+	password := r.PostForm.Get("password")
+	if validation.ValidateUserNameString(username) != nil || validation.ValidatePasswordString(password) != nil {
+		return oauth2Error(w, http.StatusBadRequest, oauth2InvalidRequest, "Invalid username or password")
+	}
+
+	_, err := authcommon.HttpHandleUserAuthWithMfa(controller.Database, username, password, r.PostForm.Get("totp_code"))
+	if err != nil {
+		secLog.WithError(err).Warningf("%s: User [%s] authentication failed, requested from %s", commLogMsg.AuthenticationFailed, username, r.RemoteAddr)
+		switch errors.Cause(err) {
+		case authcommon.ErrMfaCodeRequired, authcommon.ErrMfaEnrollmentRequired, authcommon.ErrMfaInvalidCode,
+			authcommon.ErrPasswordExpired:
+			// the password was verified, the client is told which second factor is missing or that the password expired
+			return oauth2Error(w, http.StatusBadRequest, oauth2InvalidGrant, errors.Cause(err).Error())
+		}
+		return oauth2Error(w, http.StatusBadRequest, oauth2InvalidGrant, "Invalid username or password")
+	}
+	secLog.Infof("%s: User [%s] authenticated, requested from %s", commLogMsg.AuthenticationSuccess, username, r.RemoteAddr)
+
+	return controller.userTokens(w, r, username)
+}
+
+// refreshTokenGrant implements the refresh of RFC 6749 section 6. The refresh token is rotated, a refresh token that
+// is used again revokes every token of its user as it has most likely been stolen.
+func (controller OAuth2TokenController) refreshTokenGrant(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	refreshToken := r.PostForm.Get("refresh_token")
+	if refreshToken == "" {
+		return oauth2Error(w, http.StatusBadRequest, oauth2InvalidRequest, "refresh_token is required")
+	}
+
+	tokenStore := controller.Database.TokenStore()
+	storedToken, err := tokenStore.RetrieveRefreshToken(hashRefreshToken(refreshToken))
+	if err != nil || storedToken == nil {
+		secLog.Warningf("%s: Unknown refresh token, requested from %s", commLogMsg.AuthenticationFailed, r.RemoteAddr)
+		return oauth2Error(w, http.StatusBadRequest, oauth2InvalidGrant, "Invalid refresh token")
+	}
+	if storedToken.RevokedAt != nil {
+		return controller.refreshTokenReused(w, r, storedToken)
+	}
+	if time.Now().After(storedToken.ExpiresAt) {
+		return oauth2Error(w, http.StatusBadRequest, oauth2InvalidGrant, "Expired refresh token")
+	}
+
+	if _, err = controller.Database.UserStore().Retrieve(types.User{Name: storedToken.Subject}); err != nil {
+		secLog.WithError(err).Warningf("%s: Refresh token of unknown user [%s], requested from %s", commLogMsg.AuthenticationFailed,
+			storedToken.Subject, r.RemoteAddr)
+		return oauth2Error(w, http.StatusBadRequest, oauth2InvalidGrant, "Invalid refresh token")
+	}
+	revoked, err := tokenStore.RevokeRefreshToken(*storedToken)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/oauth2_token_controller:refreshTokenGrant() Failed to revoke refresh token")
+		return oauth2Error(w, http.StatusInternalServerError, oauth2ServerError, "")
+	}
+	if !revoked {
+		// the refresh token was used by a concurrent request since it was retrieved
+		return controller.refreshTokenReused(w, r, storedToken)
+	}
+
+	return controller.userTokens(w, r, storedToken.Subject)
+}
+
+// refreshTokenReused revokes every token of the subject of a refresh token that was used more than once
+func (controller OAuth2TokenController) refreshTokenReused(w http.ResponseWriter, r *http.Request, storedToken *types.RefreshToken) (interface{}, int, error) {
+	secLog.Warningf("%s: Revoked refresh token of [%s] used, requested from %s", commLogMsg.AuthenticationFailed,
+		storedToken.Subject, r.RemoteAddr)
+	err := revokeSubject(controller.Database, storedToken.Subject, maxDuration(controller.TokenValidity, controller.AccessTokenValidity))
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/oauth2_token_controller:refreshTokenReused() Failed to revoke tokens")
+	}
+	return oauth2Error(w, http.StatusBadRequest, oauth2InvalidGrant, "Invalid refresh token")
+}
+
+// userTokens returns an access token with the current roles and permissions of the user along with a refresh token
+func (controller OAuth2TokenController) userTokens(w http.ResponseWriter, r *http.Request, username string) (interface{}, int, error) {
+	userStore := controller.Database.UserStore()
+	roles, err := userStore.GetRoles(types.User{Name: username}, nil, false)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/oauth2_token_controller:userTokens() Failed to retrieve user roles")
+		return oauth2Error(w, http.StatusInternalServerError, oauth2ServerError, "")
+	}
+	perms, err := userStore.GetPermissions(types.User{Name: username}, nil)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/oauth2_token_controller:userTokens() Failed to retrieve user permissions")
+		return oauth2Error(w, http.StatusInternalServerError, oauth2ServerError, "")
+	}
+
+	token, err := controller.TokenFactory.Create(&roleClaims{Roles: roles, Permissions: perms}, username, controller.AccessTokenValidity)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/oauth2_token_controller:userTokens() Failed to create token")
+		return oauth2Error(w, http.StatusInternalServerError, oauth2ServerError, "")
+	}
+
+	refreshTokenBytes := make([]byte, refreshTokenLength)
+	if _, err = rand.Read(refreshTokenBytes); err != nil {
+		return oauth2Error(w, http.StatusInternalServerError, oauth2ServerError, "")
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(refreshTokenBytes)
+	_, err = controller.Database.TokenStore().CreateRefreshToken(types.RefreshToken{
+		TokenHash: hashRefreshToken(refreshToken),
+		Subject:   username,
+		ExpiresAt: time.Now().Add(controller.RefreshTokenValidity),
+	})
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/oauth2_token_controller:userTokens() Failed to store refresh token")
+		return oauth2Error(w, http.StatusInternalServerError, oauth2ServerError, "")
+	}
+
+	response, err := json.Marshal(aasModel.OAuth2TokenResponse{
+		AccessToken:  token,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(controller.AccessTokenValidity / time.Second),
+		RefreshToken: refreshToken,
+	})
+	if err != nil {
+		return oauth2Error(w, http.StatusInternalServerError, oauth2ServerError, "")
+	}
+	secLog.Infof("%s: Return access token and refresh token of user [%s] to: %s", commLogMsg.TokenIssued, username, r.RemoteAddr)
+	return string(response), http.StatusOK, nil
+}
+
+// authenticateClient returns the client of the request once it is authenticated with the authentication method
+// registered for it. On failure the http status and the RFC 6749 error code are returned.
+func (controller OAuth2TokenController) authenticateClient(r *http.Request) (*types.Client, int, string, error) {
+	var clientID, secret, assertion, method string
+	if basicID, basicSecret, ok := r.BasicAuth(); ok {
+		// the credentials are form encoded before they are put in the header, RFC 6749 section 2.3.1
+		var err error
+		if clientID, err = url.QueryUnescape(basicID); err != nil {
+			return nil, http.StatusBadRequest, oauth2InvalidRequest, errors.Wrap(err, "Invalid client_id")
+		}
+		if secret, err = url.QueryUnescape(basicSecret); err != nil {
+			return nil, http.StatusBadRequest, oauth2InvalidRequest, errors.Wrap(err, "Invalid client_secret")
+		}
+		method = ClientSecretBasic
+	} else if assertion = r.PostForm.Get("client_assertion"); assertion != "" {
+		if r.PostForm.Get("client_assertion_type") != ClientAssertionTypeJwt {
+			return nil, http.StatusBadRequest, oauth2InvalidRequest, errors.New("Unsupported client_assertion_type")
+		}
+		if validation.ValidateJWT(assertion) != nil {
+			return nil, http.StatusBadRequest, oauth2InvalidRequest, errors.New("Invalid client_assertion")
+		}
+		// the client is identified by the subject of the assertion, it is verified once the client is known
+		unverifiedClaims := jwt.MapClaims{}
+		if _, _, err := new(jwt.Parser).ParseUnverified(assertion, unverifiedClaims); err != nil {
+			return nil, http.StatusBadRequest, oauth2InvalidRequest, errors.Wrap(err, "Invalid client_assertion")
+		}
+		clientID, _ = unverifiedClaims["sub"].(string)
+		if formClientID := r.PostForm.Get("client_id"); formClientID != "" && formClientID != clientID {
+			return nil, http.StatusBadRequest, oauth2InvalidRequest, errors.New("client_id does not match the subject of the client_assertion")
+		}
+		method = PrivateKeyJwt
+	} else if secret = r.PostForm.Get("client_secret"); secret != "" {
+		clientID = r.PostForm.Get("client_id")
+		method = ClientSecretPost
+	} else if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		clientID = r.PostForm.Get("client_id")
+		method = TlsClientAuth
+	} else {
+		return nil, http.StatusUnauthorized, oauth2InvalidClient, errors.New("No client authentication provided")
+	}
+
+	if err := validation.ValidateUUIDv4(clientID); err != nil {
+		return nil, http.StatusUnauthorized, oauth2InvalidClient, errors.Wrap(err, "Invalid client_id")
+	}
+
+	client, err := controller.Database.ClientStore().Retrieve(types.Client{ID: clientID})
+	if err != nil || client == nil {
+		return nil, http.StatusUnauthorized, oauth2InvalidClient, errors.Errorf("Could not retrieve client %s", clientID)
+	}
+	if client.TokenEndpointAuthMethod != method {
+		return nil, http.StatusUnauthorized, oauth2InvalidClient, errors.Errorf("Client %s is not registered for %s", clientID, method)
+	}
+
+	switch method {
+	case ClientSecretBasic, ClientSecretPost:
+		if status, err := authcommon.HttpHandleClientAuth(controller.Database.ClientStore(), clientID, secret); err != nil {
+			if status == http.StatusTooManyRequests {
+				return nil, status, oauth2InvalidClient, err
+			}
+			return nil, http.StatusUnauthorized, oauth2InvalidClient, err
+		}
+	case PrivateKeyJwt:
+		if err := controller.verifyClientAssertion(client, assertion, r.URL.Path); err != nil {
+			return nil, http.StatusUnauthorized, oauth2InvalidClient, err
+		}
+	case TlsClientAuth:
+		// the chain has been verified against the trusted CAs during the TLS handshake
+		if r.TLS.VerifiedChains[0][0].Subject.CommonName != client.CertificateCommonName {
+			return nil, http.StatusUnauthorized, oauth2InvalidClient, errors.Errorf("Client certificate common name does not match client %s", clientID)
+		}
+	}
+	return client, 0, "", nil
+}
+
+// verifyClientAssertion verifies a private_key_jwt client assertion. The assertion is signed with the key of a
+// certificate issued to the client, the certificate chain is carried in the x5c header.
+func (controller OAuth2TokenController) verifyClientAssertion(client *types.Client, assertion, tokenEndpointPath string) error {
+	if controller.ClientCAs == nil {
+		return errors.New("No trusted CAs to verify client assertions")
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(assertion, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+		default:
+			return nil, errors.Errorf("Unsupported signing algorithm %s", token.Method.Alg())
+		}
+
+		x5c, ok := token.Header["x5c"].([]interface{})
+		if !ok || len(x5c) == 0 {
+			return nil, errors.New("The x5c header is missing")
+		}
+		var certs []*x509.Certificate
+		for _, encodedCert := range x5c {
+			encodedCertString, ok := encodedCert.(string)
+			if !ok {
+				return nil, errors.New("Invalid x5c header")
+			}
+			der, err := base64.StdEncoding.DecodeString(encodedCertString)
+			if err != nil {
+				return nil, errors.Wrap(err, "Invalid x5c header")
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, errors.Wrap(err, "Invalid certificate in x5c header")
+			}
+			certs = append(certs, cert)
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		if _, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         controller.ClientCAs,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); err != nil {
+			return nil, errors.Wrap(err, "Could not verify the client certificate")
+		}
+		if certs[0].Subject.CommonName != client.CertificateCommonName {
+			return nil, errors.Errorf("Client certificate common name does not match client %s", client.ID)
+		}
+		return certs[0].PublicKey, nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "Invalid client assertion")
+	}
+
+	if !claims.VerifyIssuer(client.ID, true) {
+		return errors.New("The issuer of the client assertion must be the client id")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return errors.New("The client assertion has no expiry")
+	}
+	if !assertionAudienceMatches(claims["aud"], tokenEndpointPath) {
+		return errors.New("The audience of the client assertion is not the token endpoint")
+	}
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return errors.New("The client assertion has no jti")
+	}
+	exp := time.Now().Add(controller.TokenValidity)
+	if expClaim, ok := claims["exp"].(float64); ok {
+		exp = time.Unix(int64(expClaim), 0)
+	}
+
+	usedClientAssertions.Lock()
+	defer usedClientAssertions.Unlock()
+	now := time.Now()
+	for usedJti, usedExp := range usedClientAssertions.jtis {
+		if now.After(usedExp) {
+			delete(usedClientAssertions.jtis, usedJti)
+		}
+	}
+	key := client.ID + ":" + jti
+	if _, replayed := usedClientAssertions.jtis[key]; replayed {
+		return errors.New("The client assertion has already been used")
+	}
+	usedClientAssertions.jtis[key] = exp
+	return nil
+}
+
+// assertionAudienceMatches checks the aud claim of a client assertion identifies the token endpoint. The claim must be
+// the URL of the token endpoint, only its path is compared as AAS can be reached by several host names.
+func assertionAudienceMatches(aud interface{}, tokenEndpointPath string) bool {
+	var audiences []string
+	switch a := aud.(type) {
+	case string:
+		audiences = []string{a}
+	case []interface{}:
+		for _, v := range a {
+			if s, ok := v.(string); ok {
+				audiences = append(audiences, s)
+			}
+		}
+	}
+	for _, audience := range audiences {
+		if audURL, err := url.Parse(audience); err == nil && audURL.Path == tokenEndpointPath {
+			return true
+		}
+	}
+	return false
+}
+
+// rolesForScope returns the roles of the client that are requested by the space separated scopes along with the
+// granted scopes. Every role of the client is returned when no scope is requested.
+func rolesForScope(clientRoles []types.Role, scope string) ([]types.Role, []string, error) {
+	roleScope := func(role types.Role) string {
+		s := role.Service + ":" + role.Name
+		if role.Context != "" {
+			s = s + ":" + role.Context
+		}
+		return s
+	}
+
+	requestedScopes := strings.Fields(scope)
+	if len(requestedScopes) == 0 {
+		var scopes []string
+		for _, role := range clientRoles {
+			scopes = append(scopes, roleScope(role))
+		}
+		return clientRoles, scopes, nil
+	}
+
+	var roles []types.Role
+	for _, requested := range requestedScopes {
+		found := false
+		for _, role := range clientRoles {
+			if roleScope(role) == requested {
+				roles = append(roles, role)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil, fmt.Errorf("The scope %s has not been granted to the client", requested)
+		}
+	}
+	return roles, requestedScopes, nil
+}
+
+// hashRefreshToken returns the hash under which a refresh token is stored
+func hashRefreshToken(refreshToken string) string {
+	hash := sha512.Sum384([]byte(refreshToken))
+	return hex.EncodeToString(hash[:])
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// oauth2Error returns an error response as defined by RFC 6749 section 5.2
+func oauth2Error(w http.ResponseWriter, status int, errorCode, description string) (interface{}, int, error) {
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Basic realm="AAS"`)
+	}
+	errorBytes, err := json.Marshal(aasModel.OAuth2Error{Error: errorCode, ErrorDescription: description})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return string(errorBytes), status, nil
+}
@@ -0,0 +1,386 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/Waterdrips/jwt-go"
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/postgres/mock"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const tokenEndpointPath = "/aas/oauth2/token"
+
+type oauth2TestPKI struct {
+	caPool   *x509.CertPool
+	leaf     *x509.Certificate
+	leafKey  *ecdsa.PrivateKey
+	tokenFac *jwtauth.JwtFactory
+}
+
+func newCertificate(t *testing.T, cn string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert, key
+}
+
+func newOAuth2TestPKI(t *testing.T) *oauth2TestPKI {
+	ca, caKey := newCertificate(t, "CMS Root CA", true, nil, nil)
+	leaf, leafKey := newCertificate(t, "IHUB Client", false, ca, caKey)
+
+	signingKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(signingKey)
+	assert.NoError(t, err)
+	tokenFactory, err := jwtauth.NewTokenFactory(pkcs8, false, nil, "AAS JWT Issuer", 0)
+	assert.NoError(t, err)
+
+	caPool := x509.NewCertPool()
+	caPool.AddCert(ca)
+	return &oauth2TestPKI{caPool: caPool, leaf: leaf, leafKey: leafKey, tokenFac: tokenFactory}
+}
+
+func (pki *oauth2TestPKI) clientAssertion(t *testing.T, clientID, audience, jti string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": clientID,
+		"sub": clientID,
+		"aud": audience,
+		"jti": jti,
+		"exp": time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["x5c"] = []string{base64.StdEncoding.EncodeToString(pki.leaf.Raw)}
+	assertion, err := token.SignedString(pki.leafKey)
+	assert.NoError(t, err)
+	return assertion
+}
+
+func newOAuth2TestController(t *testing.T, pki *oauth2TestPKI, clients ...types.Client) OAuth2TokenController {
+	roles := []types.Role{
+		{ID: uuid.New().String(), RoleInfo: aasModel.RoleInfo{Service: "HVS", Name: "ReportRetriever"}},
+		{ID: uuid.New().String(), RoleInfo: aasModel.RoleInfo{Service: "KBS", Name: "KeyTransfer", Context: "permissions=nginx"}},
+	}
+	db := &mock.MockDatabase{}
+	db.MockClientStore.RetrieveFunc = func(c types.Client) (*types.Client, error) {
+		for i := range clients {
+			if clients[i].ID == c.ID {
+				return &clients[i], nil
+			}
+		}
+		return nil, errors.New("record not found")
+	}
+	db.MockClientStore.GetRolesFunc = func(c types.Client, rs *types.RoleSearch, includeID bool) ([]types.Role, error) {
+		return roles, nil
+	}
+	db.MockClientStore.GetPermissionsFunc = func(c types.Client, roleIDs []string) ([]aasModel.PermissionInfo, error) {
+		var perms []aasModel.PermissionInfo
+		for _, role := range roles {
+			for _, id := range roleIDs {
+				if role.ID == id {
+					perms = append(perms, aasModel.PermissionInfo{Service: role.Service, Context: role.Context, Rules: []string{role.Name + ":*"}})
+				}
+			}
+		}
+		return perms, nil
+	}
+	return OAuth2TokenController{
+		Database:      db,
+		TokenFactory:  pki.tokenFac,
+		TokenValidity: 5 * time.Minute,
+		ClientCAs:     pki.caPool,
+	}
+}
+
+func requestOAuth2Token(controller OAuth2TokenController, form url.Values, setup func(*http.Request)) (int, map[string]interface{}, http.Header) {
+	r := httptest.NewRequest(http.MethodPost, tokenEndpointPath, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if setup != nil {
+		setup(r)
+	}
+	w := httptest.NewRecorder()
+	data, status, err := controller.CreateOAuth2Token(w, r)
+	if err != nil {
+		return status, nil, w.Header()
+	}
+	body := map[string]interface{}{}
+	_ = json.Unmarshal([]byte(data.(string)), &body)
+	return status, body, w.Header()
+}
+
+func TestOAuth2TokenClientSecret(t *testing.T) {
+	pki := newOAuth2TestPKI(t)
+	secretHash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	basicClient := types.Client{ID: uuid.New().String(), Name: "ci", TokenEndpointAuthMethod: ClientSecretBasic, SecretHash: secretHash}
+	postClient := types.Client{ID: uuid.New().String(), Name: "ihub", TokenEndpointAuthMethod: ClientSecretPost, SecretHash: secretHash}
+	controller := newOAuth2TestController(t, pki, basicClient, postClient)
+
+	// client_secret_basic with a scope selecting one role of the client
+	status, body, header := requestOAuth2Token(controller,
+		url.Values{"grant_type": {"client_credentials"}, "scope": {"HVS:ReportRetriever"}},
+		func(r *http.Request) { r.SetBasicAuth(basicClient.ID, "s3cret") })
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Bearer", body["token_type"])
+	assert.Equal(t, "HVS:ReportRetriever", body["scope"])
+	assert.Equal(t, float64(300), body["expires_in"])
+	assert.Equal(t, "no-store", header.Get("Cache-Control"))
+
+	claims := jwt.MapClaims{}
+	_, _, err = new(jwt.Parser).ParseUnverified(body["access_token"].(string), claims)
+	assert.NoError(t, err)
+	assert.Equal(t, basicClient.ID, claims["sub"])
+	assert.Len(t, claims["roles"], 1)
+	assert.Len(t, claims["permissions"], 1)
+
+	// client_secret_post without a scope is granted every role of the client
+	status, body, _ = requestOAuth2Token(controller,
+		url.Values{"grant_type": {"client_credentials"}, "client_id": {postClient.ID}, "client_secret": {"s3cret"}}, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "HVS:ReportRetriever KBS:KeyTransfer:permissions=nginx", body["scope"])
+
+	// the client must use the method it is registered for
+	status, body, _ = requestOAuth2Token(controller,
+		url.Values{"grant_type": {"client_credentials"}, "client_id": {basicClient.ID}, "client_secret": {"s3cret"}}, nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_client", body["error"])
+
+	status, body, _ = requestOAuth2Token(controller,
+		url.Values{"grant_type": {"client_credentials"}, "scope": {"KBS:KeyTransfer"}},
+		func(r *http.Request) { r.SetBasicAuth(basicClient.ID, "s3cret") })
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_scope", body["error"])
+
+	status, body, _ = requestOAuth2Token(controller,
+		url.Values{"grant_type": {"client_credentials"}},
+		func(r *http.Request) { r.SetBasicAuth(basicClient.ID, "wrong") })
+	assert.NotEqual(t, http.StatusOK, status)
+	assert.Equal(t, "invalid_client", body["error"])
+
+	status, body, _ = requestOAuth2Token(controller, url.Values{"grant_type": {"authorization_code"}}, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "unsupported_grant_type", body["error"])
+
+	status, body, header = requestOAuth2Token(controller, url.Values{"grant_type": {"client_credentials"}}, nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_client", body["error"])
+	assert.NotEmpty(t, header.Get("WWW-Authenticate"))
+}
+
+func TestOAuth2TokenPrivateKeyJwt(t *testing.T) {
+	pki := newOAuth2TestPKI(t)
+	client := types.Client{ID: uuid.New().String(), Name: "ihub", TokenEndpointAuthMethod: PrivateKeyJwt, CertificateCommonName: "IHUB Client"}
+	otherClient := types.Client{ID: uuid.New().String(), Name: "wla", TokenEndpointAuthMethod: PrivateKeyJwt, CertificateCommonName: "WLA Client"}
+	controller := newOAuth2TestController(t, pki, client, otherClient)
+
+	assertionForm := func(assertion string) url.Values {
+		return url.Values{
+			"grant_type":            {"client_credentials"},
+			"client_assertion_type": {ClientAssertionTypeJwt},
+			"client_assertion":      {assertion},
+		}
+	}
+
+	assertion := pki.clientAssertion(t, client.ID, "https://aas.example.com:8444"+tokenEndpointPath, uuid.New().String())
+	status, body, _ := requestOAuth2Token(controller, assertionForm(assertion), nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, body["access_token"])
+
+	// an assertion can only be used once
+	status, body, _ = requestOAuth2Token(controller, assertionForm(assertion), nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_client", body["error"])
+
+	// the audience must be the token endpoint
+	assertion = pki.clientAssertion(t, client.ID, "https://aas.example.com:8444/aas/token", uuid.New().String())
+	status, _, _ = requestOAuth2Token(controller, assertionForm(assertion), nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// the certificate must have been issued to the client
+	assertion = pki.clientAssertion(t, otherClient.ID, "https://aas.example.com:8444"+tokenEndpointPath, uuid.New().String())
+	status, _, _ = requestOAuth2Token(controller, assertionForm(assertion), nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// the certificate must be issued by a trusted CA
+	controller.ClientCAs = x509.NewCertPool()
+	assertion = pki.clientAssertion(t, client.ID, "https://aas.example.com:8444"+tokenEndpointPath, uuid.New().String())
+	status, _, _ = requestOAuth2Token(controller, assertionForm(assertion), nil)
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestOAuth2TokenTlsClientAuth(t *testing.T) {
+	pki := newOAuth2TestPKI(t)
+	client := types.Client{ID: uuid.New().String(), Name: "ihub", TokenEndpointAuthMethod: TlsClientAuth, CertificateCommonName: "IHUB Client"}
+	otherClient := types.Client{ID: uuid.New().String(), Name: "wla", TokenEndpointAuthMethod: TlsClientAuth, CertificateCommonName: "WLA Client"}
+	controller := newOAuth2TestController(t, pki, client, otherClient)
+
+	withClientCert := func(r *http.Request) {
+		r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{pki.leaf}}}
+	}
+
+	status, body, _ := requestOAuth2Token(controller,
+		url.Values{"grant_type": {"client_credentials"}, "client_id": {client.ID}}, withClientCert)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, body["access_token"])
+
+	status, body, _ = requestOAuth2Token(controller,
+		url.Values{"grant_type": {"client_credentials"}, "client_id": {otherClient.ID}}, withClientCert)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "invalid_client", body["error"])
+}
+
+// withTestUser adds a user and an in memory refresh token store to the mock database of the controller
+func withTestUser(t *testing.T, controller *OAuth2TokenController, username, password string) (map[string]*types.RefreshToken, *[]types.TokenRevocation) {
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	assert.NoError(t, err)
+	controller.AccessTokenValidity = time.Minute
+	controller.RefreshTokenValidity = time.Hour
+
+	refreshTokens := map[string]*types.RefreshToken{}
+	revocations := &[]types.TokenRevocation{}
+	db := controller.Database.(*mock.MockDatabase)
+	db.MockUserStore.RetrieveFunc = func(u types.User) (*types.User, error) {
+		if u.Name == username {
+			return &types.User{ID: uuid.New().String(), Name: username, PasswordHash: passwordHash}, nil
+		}
+		return nil, errors.New("record not found")
+	}
+	db.MockTokenStore.CreateRefreshTokenFunc = func(rt types.RefreshToken) (*types.RefreshToken, error) {
+		refreshTokens[rt.TokenHash] = &rt
+		return &rt, nil
+	}
+	db.MockTokenStore.RetrieveRefreshTokenFunc = func(tokenHash string) (*types.RefreshToken, error) {
+		if rt, ok := refreshTokens[tokenHash]; ok {
+			return rt, nil
+		}
+		return nil, errors.New("record not found")
+	}
+	db.MockTokenStore.RevokeRefreshTokenFunc = func(rt types.RefreshToken) (bool, error) {
+		if refreshTokens[rt.TokenHash].RevokedAt != nil {
+			return false, nil
+		}
+		now := time.Now()
+		refreshTokens[rt.TokenHash].RevokedAt = &now
+		return true, nil
+	}
+	db.MockTokenStore.RevokeFunc = func(tr types.TokenRevocation) error {
+		*revocations = append(*revocations, tr)
+		return nil
+	}
+	return refreshTokens, revocations
+}
+
+func TestOAuth2TokenRefreshToken(t *testing.T) {
+	pki := newOAuth2TestPKI(t)
+	controller := newOAuth2TestController(t, pki)
+	refreshTokens, revocations := withTestUser(t, &controller, "refreshuser", "aasAdminPass")
+
+	status, body, _ := requestOAuth2Token(controller,
+		url.Values{"grant_type": {"password"}, "username": {"refreshuser"}, "password": {"aasAdminPass"}}, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(60), body["expires_in"])
+	assert.NotEmpty(t, body["refresh_token"])
+	assert.Len(t, refreshTokens, 1)
+
+	claims := jwt.MapClaims{}
+	_, _, err := new(jwt.Parser).ParseUnverified(body["access_token"].(string), claims)
+	assert.NoError(t, err)
+	assert.Equal(t, "refreshuser", claims["sub"])
+	assert.NotEmpty(t, claims["jti"])
+
+	// the refresh token is rotated
+	firstRefreshToken := body["refresh_token"].(string)
+	status, body, _ = requestOAuth2Token(controller,
+		url.Values{"grant_type": {"refresh_token"}, "refresh_token": {firstRefreshToken}}, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, body["access_token"])
+	assert.NotEqual(t, firstRefreshToken, body["refresh_token"])
+	assert.Len(t, refreshTokens, 2)
+	assert.Empty(t, *revocations)
+
+	// using a refresh token again revokes every token of the user
+	status, body, _ = requestOAuth2Token(controller,
+		url.Values{"grant_type": {"refresh_token"}, "refresh_token": {firstRefreshToken}}, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"])
+	if assert.Len(t, *revocations, 1) {
+		assert.Equal(t, "refreshuser", (*revocations)[0].Subject)
+		assert.Empty(t, (*revocations)[0].TokenID)
+	}
+
+	status, body, _ = requestOAuth2Token(controller,
+		url.Values{"grant_type": {"refresh_token"}, "refresh_token": {"unknown"}}, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"])
+
+	status, body, _ = requestOAuth2Token(controller,
+		url.Values{"grant_type": {"password"}, "username": {"refreshuser"}, "password": {"wrongPassword"}}, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"])
+}
+
+func TestOAuth2TokenRefreshTokenConcurrentUse(t *testing.T) {
+	pki := newOAuth2TestPKI(t)
+	controller := newOAuth2TestController(t, pki)
+	refreshTokens, revocations := withTestUser(t, &controller, "concurrentuser", "aasAdminPass")
+
+	status, body, _ := requestOAuth2Token(controller,
+		url.Values{"grant_type": {"password"}, "username": {"concurrentuser"}, "password": {"aasAdminPass"}}, nil)
+	assert.Equal(t, http.StatusOK, status)
+	refreshToken := body["refresh_token"].(string)
+
+	// a concurrent request revoked the refresh token after this request retrieved it
+	db := controller.Database.(*mock.MockDatabase)
+	db.MockTokenStore.RetrieveRefreshTokenFunc = func(tokenHash string) (*types.RefreshToken, error) {
+		storedToken := *refreshTokens[tokenHash]
+		now := time.Now()
+		refreshTokens[tokenHash].RevokedAt = &now
+		return &storedToken, nil
+	}
+	status, body, _ = requestOAuth2Token(controller,
+		url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}}, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"])
+	if assert.Len(t, *revocations, 1) {
+		assert.Equal(t, "concurrentuser", (*revocations)[0].Subject)
+		assert.Equal(t, (*revocations)[0].RevokedAt, (*revocations)[0].RevokedAt.Truncate(time.Second),
+			"subject revocations are whole seconds")
+	}
+}
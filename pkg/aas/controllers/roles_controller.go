@@ -16,6 +16,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -24,6 +25,8 @@ import (
 
 type RolesController struct {
 	Database domain.AASDatabase
+	// TokenValidity is the longest validity of the tokens issued by AAS
+	TokenValidity time.Duration
 }
 
 func (controller RolesController) CreateRole(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
@@ -124,6 +127,8 @@ func (controller RolesController) CreateRole(w http.ResponseWriter, r *http.Requ
 		defaultLog.WithError(err).Error("Error creating new role")
 		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error creating new role"}
 	}
+	recordAuditEvent(controller.Database, r, types.AuditEvent{Action: types.AuditRoleCreate,
+		TargetType: types.AuditTargetRole, TargetID: created.ID, TargetName: created.Name, After: roleAuditPermissions(*created)})
 	secLog.WithField("role", rl).Infof("%s: Role created by: %s", commLogMsg.PrivilegeModified, r.RemoteAddr)
 	roleBytes, err := json.Marshal(created)
 	if err != nil {
@@ -220,10 +225,20 @@ func (controller RolesController) DeleteRole(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
+	// the tokens issued to the members of the role carry the role until they expire
+	revokedAt := subjectRevokedAt(time.Now())
+	if err := controller.Database.TokenStore().RevokeRoleMembers(delRl.ID, revokedAt, revokedAt.Add(controller.TokenValidity)); err != nil {
+		log.WithError(err).WithField("id", id).Info("failed to revoke the tokens of the role members")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to delete role"}
+	}
+	waitForRevocation(revokedAt)
+
 	if err := controller.Database.RoleStore().Delete(*delRl); err != nil {
 		log.WithError(err).WithField("id", id).Info("failed to delete role")
 		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to delete role"}
 	}
+	recordAuditEvent(controller.Database, r, types.AuditEvent{Action: types.AuditRoleDelete,
+		TargetType: types.AuditTargetRole, TargetID: delRl.ID, TargetName: delRl.Name, Before: roleAuditPermissions(*delRl)})
 	secLog.WithField("role", delRl).Infof("%s: Role deleted by: %s", commLogMsg.PrivilegeModified, r.RemoteAddr)
 
 	return nil, http.StatusNoContent, nil
@@ -307,7 +322,7 @@ func (controller RolesController) UpdateRole(w http.ResponseWriter, r *http.Requ
 	return nil, http.StatusNotImplemented, &commErr.ResourceError{Message: ""}
 }
 
-func contains(strArr [4]string, str string) bool {
+func contains(strArr [5]string, str string) bool {
 	for _, s := range strArr {
 		if s == str {
 			return true
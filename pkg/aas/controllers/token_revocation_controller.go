@@ -0,0 +1,145 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	jwt "github.com/Waterdrips/jwt-go"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	comctx "github.com/intel-secl/intel-secl/v3/pkg/lib/common/context"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
+	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
+)
+
+type TokenRevocationController struct {
+	Database domain.AASDatabase
+	// TokenFactory signs the access tokens of AAS, only the tokens it signed are revoked
+	TokenFactory *jwtauth.JwtFactory
+	// TokenValidity is the longest validity of the access tokens issued by AAS, an entry of the revocation list is
+	// kept until every token it revokes has expired
+	TokenValidity time.Duration
+}
+
+// RevokeToken implements the token revocation of RFC 7009 for access tokens and refresh tokens. A token can be revoked
+// by its subject or by a user with the tokens:revoke permission. As required by RFC 7009 unknown tokens are ignored.
+func (controller TokenRevocationController) RevokeToken(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to revokeToken")
+	defer defaultLog.Trace("revokeToken return")
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return oauth2Error(w, http.StatusBadRequest, oauth2InvalidRequest, "Content-Type must be application/x-www-form-urlencoded")
+	}
+	if err := r.ParseForm(); err != nil {
+		return oauth2Error(w, http.StatusBadRequest, oauth2InvalidRequest, "Could not parse the request body")
+	}
+	token := r.PostForm.Get("token")
+	if token == "" {
+		return oauth2Error(w, http.StatusBadRequest, oauth2InvalidRequest, "token is required")
+	}
+
+	tokenStore := controller.Database.TokenStore()
+	var revocation *types.TokenRevocation
+	var refreshToken *types.RefreshToken
+	subject := ""
+
+	// a token that was not signed by AAS or has expired is looked up as a refresh token, and ignored as unknown
+	claims := jwt.StandardClaims{}
+	if err := controller.TokenFactory.ParseToken(token, &claims); err == nil {
+		if claims.Id == "" {
+			// tokens without an id can not be revoked individually
+			return nil, http.StatusOK, nil
+		}
+		subject = claims.Subject
+		now := time.Now()
+		// no token issued by AAS is valid for longer than TokenValidity, the revocation is not kept longer either
+		expiresAt := now.Add(controller.TokenValidity)
+		if claims.ExpiresAt != 0 && time.Unix(claims.ExpiresAt, 0).Before(expiresAt) {
+			expiresAt = time.Unix(claims.ExpiresAt, 0)
+		}
+		revocation = &types.TokenRevocation{
+			TokenID:   claims.Id,
+			RevokedAt: now,
+			ExpiresAt: expiresAt,
+		}
+	} else {
+		refreshToken, err = tokenStore.RetrieveRefreshToken(hashRefreshToken(token))
+		if err != nil || refreshToken == nil {
+			return nil, http.StatusOK, nil
+		}
+		subject = refreshToken.Subject
+	}
+
+	caller, err := comctx.GetTokenSubject(r)
+	if err != nil || caller != subject {
+		if _, err := authorizeEndpoint(r, []string{consts.TokenRevoke}, true); err != nil {
+			secLog.Warningf("%s: Unauthorized token revocation attempt from: %s", commLogMsg.UnauthorizedAccess, r.RemoteAddr)
+			return oauth2Error(w, http.StatusForbidden, oauth2UnauthorizedClient, "Not allowed to revoke the token")
+		}
+	}
+
+	if revocation != nil {
+		err = tokenStore.Revoke(*revocation)
+	} else if refreshToken.RevokedAt == nil {
+		_, err = tokenStore.RevokeRefreshToken(*refreshToken)
+	}
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/token_revocation_controller:RevokeToken() Failed to revoke token")
+		return oauth2Error(w, http.StatusServiceUnavailable, oauth2ServerError, "")
+	}
+	secLog.Infof("%s: Token of [%s] revoked by: %s", commLogMsg.PrivilegeModified, subject, r.RemoteAddr)
+
+	return nil, http.StatusOK, nil
+}
+
+// GetRevocationList returns the access tokens and subjects revoked by AAS that have not expired yet, services that
+// validate AAS tokens poll this list
+func (controller TokenRevocationController) GetRevocationList(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+
+	defaultLog.Trace("call to getRevocationList")
+	defer defaultLog.Trace("getRevocationList return")
+
+	revocations, err := controller.Database.TokenStore().RetrieveAllRevoked()
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/token_revocation_controller:GetRevocationList() Failed to retrieve revoked tokens")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve revoked tokens"}
+	}
+
+	response, err := json.Marshal(revocations.RevocationList())
+	if err != nil {
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to marshal revoked tokens"}
+	}
+	return string(response), http.StatusOK, nil
+}
+
+// revokeSubject revokes every token issued to a subject until now. The entry is kept until the tokens have expired.
+func revokeSubject(db domain.AASDatabase, subject string, tokenValidity time.Duration) error {
+	revokedAt := subjectRevokedAt(time.Now())
+	err := db.TokenStore().Revoke(types.TokenRevocation{Subject: subject, RevokedAt: revokedAt, ExpiresAt: revokedAt.Add(tokenValidity)})
+	if err != nil {
+		return err
+	}
+	waitForRevocation(revokedAt)
+	return nil
+}
+
+// subjectRevokedAt returns the revoked_at of the tokens of a subject revoked at the given time. The issue time (iat) of
+// a token only has a precision of one second, so every token issued until the end of that second is revoked.
+func subjectRevokedAt(now time.Time) time.Time {
+	return now.Truncate(time.Second).Add(time.Second)
+}
+
+// waitForRevocation returns once revoked_at has passed, so that the tokens requested after a subject was revoked are
+// not revoked as well
+func waitForRevocation(revokedAt time.Time) {
+	time.Sleep(time.Until(revokedAt))
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/Waterdrips/jwt-go"
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/postgres/mock"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	comctx "github.com/intel-secl/intel-secl/v3/pkg/lib/common/context"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/stretchr/testify/assert"
+)
+
+func revokeTestToken(controller TokenRevocationController, token, caller string, perms []aasModel.PermissionInfo) int {
+	r := httptest.NewRequest(http.MethodPost, "/aas/oauth2/revoke", strings.NewReader(url.Values{"token": {token}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = comctx.SetTokenSubject(r, caller)
+	r = comctx.SetUserPermissions(r, perms)
+	_, status, _ := controller.RevokeToken(httptest.NewRecorder(), r)
+	return status
+}
+
+func TestRevokeToken(t *testing.T) {
+	pki := newOAuth2TestPKI(t)
+	tokenController := newOAuth2TestController(t, pki)
+	refreshTokens, revocations := withTestUser(t, &tokenController, "revokeuser", "aasAdminPass")
+	controller := TokenRevocationController{Database: tokenController.Database, TokenFactory: pki.tokenFac,
+		TokenValidity: time.Hour}
+
+	status, body, _ := requestOAuth2Token(tokenController,
+		url.Values{"grant_type": {"password"}, "username": {"revokeuser"}, "password": {"aasAdminPass"}}, nil)
+	assert.Equal(t, http.StatusOK, status)
+	accessToken := body["access_token"].(string)
+	refreshToken := body["refresh_token"].(string)
+
+	// only the subject of the token or a user with the tokens:revoke permission can revoke it
+	assert.Equal(t, http.StatusForbidden, revokeTestToken(controller, accessToken, "otheruser", nil))
+	assert.Empty(t, *revocations)
+
+	assert.Equal(t, http.StatusOK, revokeTestToken(controller, accessToken, "revokeuser", nil))
+	if assert.Len(t, *revocations, 1) {
+		assert.NotEmpty(t, (*revocations)[0].TokenID)
+		assert.Empty(t, (*revocations)[0].Subject)
+	}
+
+	adminPerms := []aasModel.PermissionInfo{{Service: constants.ServiceName, Rules: []string{constants.TokenRevoke}}}
+	assert.Equal(t, http.StatusOK, revokeTestToken(controller, refreshToken, "admin", adminPerms))
+	assert.NotNil(t, refreshTokens[hashRefreshToken(refreshToken)].RevokedAt)
+
+	// unknown tokens are ignored
+	assert.Equal(t, http.StatusOK, revokeTestToken(controller, "unknown", "revokeuser", nil))
+	assert.Len(t, *revocations, 1)
+}
+
+func TestRevokeTokenVerifiesSignature(t *testing.T) {
+	pki := newOAuth2TestPKI(t)
+	tokenController := newOAuth2TestController(t, pki)
+	_, revocations := withTestUser(t, &tokenController, "revokeuser", "aasAdminPass")
+	controller := TokenRevocationController{Database: tokenController.Database, TokenFactory: pki.tokenFac,
+		TokenValidity: time.Hour}
+
+	// a token that was not signed by AAS is unknown and is not added to the revocation list
+	otherKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	forgedToken, err := jwt.NewWithClaims(jwt.SigningMethodES384, jwt.StandardClaims{
+		Id:        uuid.New().String(),
+		Subject:   "revokeuser",
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	}).SignedString(otherKey)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, revokeTestToken(controller, forgedToken, "revokeuser", nil))
+	assert.Empty(t, *revocations)
+
+	// the revocation of a token is not kept longer than the validity of the tokens issued by AAS
+	longLivedToken, err := pki.tokenFac.Create(map[string]string{"client_id": "revokeuser"}, "revokeuser", 100*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, revokeTestToken(controller, longLivedToken, "revokeuser", nil))
+	if assert.Len(t, *revocations, 1) {
+		assert.False(t, (*revocations)[0].ExpiresAt.After(time.Now().Add(time.Hour)))
+	}
+}
+
+func TestGetRevocationList(t *testing.T) {
+	now := time.Now()
+	db := &mock.MockDatabase{}
+	db.MockTokenStore.RetrieveAllRevokedFunc = func() (types.TokenRevocations, error) {
+		return types.TokenRevocations{
+			{TokenID: "8b1f6a7e-2c3d-4e5f-9a0b-1c2d3e4f5a6b", RevokedAt: now, ExpiresAt: now.Add(time.Hour)},
+			{Subject: "admin", RevokedAt: now, ExpiresAt: now.Add(time.Hour)},
+		}, nil
+	}
+	controller := TokenRevocationController{Database: db}
+
+	data, status, err := controller.GetRevocationList(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/aas/noauth/revoked-tokens", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+
+	var revocationList aasModel.RevocationList
+	assert.NoError(t, json.Unmarshal([]byte(data.(string)), &revocationList))
+	if assert.Len(t, revocationList.RevokedTokens, 2) {
+		assert.Equal(t, "8b1f6a7e-2c3d-4e5f-9a0b-1c2d3e4f5a6b", revocationList.RevokedTokens[0].TokenID)
+		assert.Empty(t, revocationList.RevokedTokens[0].SubjectHash)
+		// the subjects are not disclosed
+		assert.Equal(t, aasModel.SubjectHash("admin"), revocationList.RevokedTokens[1].SubjectHash)
+		assert.NotContains(t, data.(string), "admin")
+	}
+}
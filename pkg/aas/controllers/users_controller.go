@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -29,6 +30,8 @@ import (
 
 type UsersController struct {
 	Database domain.AASDatabase
+	// TokenValidity is the longest validity of the tokens issued by AAS
+	TokenValidity time.Duration
 }
 
 func (controller UsersController) CreateUser(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
@@ -59,6 +62,9 @@ func (controller UsersController) CreateUser(w http.ResponseWriter, r *http.Requ
 	if validationErr != nil {
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
 	}
+	if err := authcommon.ValidatePasswordPolicy(uc.Password); err != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
 
 	existingUser, err := controller.Database.UserStore().Retrieve(types.User{Name: uc.Name})
 	if existingUser != nil {
@@ -74,6 +80,11 @@ func (controller UsersController) CreateUser(w http.ResponseWriter, r *http.Requ
 	if err != nil {
 		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: err.Error()}
 	}
+	if err = authcommon.RecordPassword(controller.Database, *created); err != nil {
+		defaultLog.WithError(err).Error("failed to record the password of created user")
+	}
+	recordAuditEvent(controller.Database, r, types.AuditEvent{Action: types.AuditUserCreate,
+		TargetType: types.AuditTargetUser, TargetID: created.ID, TargetName: created.Name})
 	secLog.WithField("user", created).Infof("%s: User created by: %s", commLogMsg.UserAdded, r.RemoteAddr)
 
 	createdUserBytes, err := json.Marshal(created)
@@ -163,6 +174,12 @@ func (controller UsersController) UpdateUser(w http.ResponseWriter, r *http.Requ
 		if validationErr != nil {
 			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: validationErr.Error()}
 		}
+		if err := authcommon.ValidatePasswordPolicy(uc.Password); err != nil {
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+		}
+		if err := authcommon.ValidatePasswordReuse(controller.Database, *u, uc.Password); err != nil {
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+		}
 		updatedUser.PasswordHash, err = bcrypt.GenerateFromPassword([]byte(uc.Password), bcrypt.DefaultCost)
 		if err != nil {
 			defaultLog.WithError(err).Error("could not generate password when attempting to update user : ", id)
@@ -179,6 +196,13 @@ func (controller UsersController) UpdateUser(w http.ResponseWriter, r *http.Requ
 		defaultLog.WithError(err).Error("database error while attempting to change user:", id)
 		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
 	}
+	if uc.Password != "" {
+		if err = authcommon.RecordPassword(controller.Database, updatedUser); err != nil {
+			defaultLog.WithError(err).Error("failed to record the password of user:", id)
+		}
+	}
+	recordAuditEvent(controller.Database, r, types.AuditEvent{Action: types.AuditUserUpdate,
+		TargetType: types.AuditTargetUser, TargetID: id, TargetName: updatedUser.Name})
 	secLog.Infof("%s: User %s changed by: %s", commLogMsg.PrivilegeModified, id, r.RemoteAddr)
 
 	return nil, http.StatusOK, nil
@@ -203,9 +227,24 @@ func (controller UsersController) DeleteUser(w http.ResponseWriter, r *http.Requ
 		return nil, http.StatusNoContent, nil
 	}
 
+	permissions := userAuditPermissions(controller.Database, *delUsr)
 	if err := controller.Database.UserStore().Delete(*delUsr); err != nil {
 		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: err.Error()}
 	}
+	recordAuditEvent(controller.Database, r, types.AuditEvent{Action: types.AuditUserDelete,
+		TargetType: types.AuditTargetUser, TargetID: delUsr.ID, TargetName: delUsr.Name, Before: permissions})
+	if err := controller.Database.MfaStore().Delete(delUsr.ID); err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error("failed to delete the MFA of deleted user")
+	}
+	if err := controller.Database.PasswordStore().DeleteHistory(delUsr.ID); err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error("failed to delete the password history of deleted user")
+	}
+	if err := authcommon.UnlockUser(delUsr.Name); err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error("failed to delete the lockout of deleted user")
+	}
+	if err := revokeSubject(controller.Database, delUsr.Name, controller.TokenValidity); err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error("failed to revoke the tokens of deleted user")
+	}
 	secLog.WithField("user", delUsr).Infof("%s: User deleted by: %s", commLogMsg.UserDeleted, r.RemoteAddr)
 
 	return nil, http.StatusNoContent, nil
@@ -315,10 +354,14 @@ func (controller UsersController) AddUserRoles(w http.ResponseWriter, r *http.Re
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: errMsg}
 	}
 
+	permissions := userAuditPermissions(controller.Database, *u)
 	err = controller.Database.UserStore().AddRoles(*u, roles, true)
 	if err != nil {
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
 	}
+	recordAuditEvent(controller.Database, r, types.AuditEvent{Action: types.AuditUserRolesAdd,
+		TargetType: types.AuditTargetUser, TargetID: u.ID, TargetName: u.Name,
+		Before: permissions, After: userAuditPermissions(controller.Database, *u)})
 	secLog.WithField("user", u).Infof("%s: Roles added by: %s", commLogMsg.PrivilegeModified, r.RemoteAddr)
 
 	return nil, http.StatusCreated, nil
@@ -552,6 +595,7 @@ func (controller UsersController) DeleteUserRole(w http.ResponseWriter, r *http.
 		}
 	}
 
+	permissions := userAuditPermissions(controller.Database, *u)
 	err = controller.Database.UserStore().DeleteRole(*u, rid, svcFltr)
 	if err != nil {
 		defaultLog.WithError(err).WithField("id", id).WithField("rid", rid).Info("failed to delete role from user")
@@ -561,6 +605,9 @@ func (controller UsersController) DeleteUserRole(w http.ResponseWriter, r *http.
 			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "failed to delete role from user"}
 		}
 	}
+	recordAuditEvent(controller.Database, r, types.AuditEvent{Action: types.AuditUserRoleDelete,
+		TargetType: types.AuditTargetUser, TargetID: u.ID, TargetName: u.Name,
+		Before: permissions, After: userAuditPermissions(controller.Database, *u)})
 	secLog.WithField("user", *u).Infof("%s: User roles deleted by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
 	return nil, http.StatusNoContent, nil
 }
@@ -613,6 +660,16 @@ func (controller UsersController) ChangePassword(w http.ResponseWriter, r *http.
 		defaultLog.WithError(err).Error("not able to retrieve existing user though he was just authenticated")
 		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
 	}
+	if len(existingUser.PasswordHash) == 0 {
+		// the password of a directory user is changed in the directory
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The password of a directory user can not be changed in AAS"}
+	}
+	if err := authcommon.ValidatePasswordPolicy(pc.NewPassword); err != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+	if err := authcommon.ValidatePasswordReuse(controller.Database, *existingUser, pc.NewPassword); err != nil {
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
 
 	passwordHash, err := bcrypt.GenerateFromPassword([]byte(pc.NewPassword), bcrypt.DefaultCost)
 	if err != nil {
@@ -626,6 +683,12 @@ func (controller UsersController) ChangePassword(w http.ResponseWriter, r *http.
 		defaultLog.WithError(err).Error("database error while attempting to change password")
 		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "cannot complete request"}
 	}
+	if err = authcommon.RecordPassword(controller.Database, *existingUser); err != nil {
+		defaultLog.WithError(err).Error("failed to record the changed password")
+	}
+	// the users change their own password without a token
+	recordAuditEvent(controller.Database, r, types.AuditEvent{Actor: existingUser.Name, Action: types.AuditUserPassword,
+		TargetType: types.AuditTargetUser, TargetID: existingUser.ID, TargetName: existingUser.Name})
 	secLog.WithField("user", existingUser.ID).Infof("%s: User %s password changed by: %s", commLogMsg.PrivilegeModified, existingUser.ID, r.RemoteAddr)
 
 	return nil, http.StatusOK, nil
@@ -8,8 +8,10 @@ import (
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/config"
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
 	commConfig "github.com/intel-secl/intel-secl/v3/pkg/lib/common/config"
+	commTls "github.com/intel-secl/intel-secl/v3/pkg/lib/common/tls"
 	"github.com/spf13/viper"
 	"os"
+	"strings"
 )
 
 // this func sets the default values for viper keys
@@ -19,6 +21,8 @@ func init() {
 	viper.SetDefault("tls-key-file", constants.DefaultTLSKeyFile)
 	viper.SetDefault("tls-common-name", constants.DefaultAasTlsCn)
 	viper.SetDefault("tls-san-list", constants.DefaultAasTlsSan)
+	viper.SetDefault("tls-renew-before", commTls.DefaultRenewBefore)
+	viper.SetDefault("tls-reload-interval", commTls.DefaultReloadInterval)
 
 	// set default values for log
 	viper.SetDefault("log-max-length", constants.DefaultLogEntryMaxLength)
@@ -47,6 +51,12 @@ func init() {
 	viper.SetDefault("jwt-include-kid", true)
 	viper.SetDefault("jwt-cert-common-name", constants.DefaultAasJwtCn)
 	viper.SetDefault("jwt-token-duration-mins", constants.DefaultAasJwtDurationMins)
+	viper.SetDefault("jwt-issuer", constants.DefaultAasJwtIssuer)
+	viper.SetDefault("jwt-access-duration-mins", constants.DefaultAasAccessTokenMins)
+	viper.SetDefault("jwt-refresh-duration-mins", constants.DefaultAasRefreshTokenMins)
+
+	viper.SetDefault("ldap-user-filter", constants.DefaultLdapUserFilter)
+	viper.SetDefault("ldap-group-attribute", constants.DefaultLdapGroupAttribute)
 
 	viper.SetDefault("auth-defender-max-attempts", constants.DefaultAuthDefendMaxAttempts)
 	viper.SetDefault("auth-defender-interval-mins", constants.DefaultAuthDefendIntervalMins)
@@ -61,10 +71,14 @@ func defaultConfig() *config.Configuration {
 		CMSBaseURL:       viper.GetString("cms-base-url"),
 		CmsTlsCertDigest: viper.GetString("cms-tls-cert-sha384"),
 		TLS: commConfig.TLSCertConfig{
-			CertFile:   viper.GetString("tls-cert-file"),
-			KeyFile:    viper.GetString("tls-key-file"),
-			CommonName: viper.GetString("tls-common-name"),
-			SANList:    viper.GetString("tls-san-list"),
+			CertFile:       viper.GetString("tls-cert-file"),
+			KeyFile:        viper.GetString("tls-key-file"),
+			CommonName:     viper.GetString("tls-common-name"),
+			SANList:        viper.GetString("tls-san-list"),
+			RenewBefore:    viper.GetDuration("tls-renew-before"),
+			ReloadInterval: viper.GetDuration("tls-reload-interval"),
+			RenewUsername:  viper.GetString("tls-renew-username"),
+			RenewPassword:  viper.GetString("tls-renew-password"),
 		},
 		Log: commConfig.LogConfig{
 			MaxLength:    viper.GetInt("log-max-length"),
@@ -75,6 +89,29 @@ func defaultConfig() *config.Configuration {
 			IncludeKid:        viper.GetBool("jwt-include-kid"),
 			TokenDurationMins: viper.GetInt("jwt-token-duration-mins"),
 			CertCommonName:    viper.GetString("jwt-cert-common-name"),
+			Issuer:            viper.GetString("jwt-issuer"),
+
+			AccessTokenDurationMins:  viper.GetInt("jwt-access-duration-mins"),
+			RefreshTokenDurationMins: viper.GetInt("jwt-refresh-duration-mins"),
+		},
+		LDAP: config.LDAP{
+			URL:            viper.GetString("ldap-url"),
+			StartTLS:       viper.GetBool("ldap-start-tls"),
+			CACertFile:     viper.GetString("ldap-ca-cert-file"),
+			BindDN:         viper.GetString("ldap-bind-dn"),
+			BindPassword:   viper.GetString("ldap-bind-password"),
+			UserBaseDN:     viper.GetString("ldap-user-base-dn"),
+			UserFilter:     viper.GetString("ldap-user-filter"),
+			GroupAttribute: viper.GetString("ldap-group-attribute"),
+		},
+		MFA: config.MFA{
+			RequiredRoles: listOrNil(viper.GetString("mfa-required-roles")),
+		},
+		PasswordPolicy: config.PasswordPolicy{
+			MinLength:      viper.GetInt("password-min-length"),
+			MinCharClasses: viper.GetInt("password-min-char-classes"),
+			HistoryCount:   viper.GetInt("password-history-count"),
+			MaxAgeDays:     viper.GetInt("password-max-age-days"),
 		},
 		AuthDefender: config.AuthDefender{
 			MaxAttempts:         viper.GetInt("auth-defender-max-attempts"),
@@ -84,6 +121,20 @@ func defaultConfig() *config.Configuration {
 	}
 }
 
+// listOrNil splits a comma separated list
+func listOrNil(list string) []string {
+	if strings.TrimSpace(list) == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func loadAlias() {
 	alias := map[string]string{
 		"db-host":                    "AAS_DB_HOSTNAME",
@@ -106,8 +157,21 @@ func loadAlias() {
 		"aas-service-username":       "AAS_ADMIN_USERNAME",
 		"aas-service-password":       "AAS_ADMIN_PASSWORD",
 		"jwt-token-duration-mins":    "AAS_JWT_TOKEN_DURATION_MINS",
+		"jwt-access-duration-mins":   "AAS_JWT_ACCESS_TOKEN_DURATION_MINS",
+		"jwt-refresh-duration-mins":  "AAS_JWT_REFRESH_TOKEN_DURATION_MINS",
 		"jwt-include-kid":            "AAS_JWT_INCLUDE_KEYID",
 		"jwt-cert-common-name":       "AAS_JWT_CERT_CN",
+		"jwt-issuer":                 "AAS_JWT_ISSUER",
+		"ldap-url":                   "AAS_LDAP_URL",
+		"ldap-bind-dn":               "AAS_LDAP_BIND_DN",
+		"ldap-bind-password":         "AAS_LDAP_BIND_PASSWORD",
+		"ldap-user-base-dn":          "AAS_LDAP_USER_BASE_DN",
+		"ldap-user-filter":           "AAS_LDAP_USER_FILTER",
+		"mfa-required-roles":         "AAS_MFA_REQUIRED_ROLES",
+		"password-min-length":        "AAS_PASSWORD_MIN_LENGTH",
+		"password-min-char-classes":  "AAS_PASSWORD_MIN_CHAR_CLASSES",
+		"password-history-count":     "AAS_PASSWORD_HISTORY_COUNT",
+		"password-max-age-days":      "AAS_PASSWORD_MAX_AGE_DAYS",
 		"tls-cert-file":              "CERT_PATH",
 		"tls-key-file":               "KEY_PATH",
 	}
@@ -6,6 +6,8 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
 	ct "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
 )
@@ -16,6 +18,12 @@ type (
 		UserStore() UserStore
 		RoleStore() RoleStore
 		PermissionStore() PermissionStore
+		ClientStore() ClientStore
+		TokenStore() TokenStore
+		MfaStore() MfaStore
+		PasswordStore() PasswordStore
+		LockoutStore() LockoutStore
+		AuditStore() AuditStore
 		Close()
 	}
 
@@ -47,4 +55,70 @@ type (
 		GetUserRoleByID(types.User, string) (types.Role, error)
 		DeleteRole(types.User, string, []string) error
 	}
+
+	ClientStore interface {
+		Create(types.Client) (*types.Client, error)
+		Retrieve(types.Client) (*types.Client, error)
+		RetrieveAll(types.Client) (types.Clients, error)
+		Delete(types.Client) error
+		GetRoles(types.Client, *types.RoleSearch, bool) ([]types.Role, error)
+		GetPermissions(types.Client, []string) ([]ct.PermissionInfo, error)
+		AddRoles(types.Client, types.Roles) error
+		DeleteRole(types.Client, string, []string) error
+	}
+
+	// IdentityProvider authenticates the users of an external user directory
+	IdentityProvider interface {
+		// Authenticate verifies the password of the user and returns the groups of the user
+		Authenticate(username, password string) ([]string, error)
+	}
+
+	TokenStore interface {
+		CreateRefreshToken(types.RefreshToken) (*types.RefreshToken, error)
+		RetrieveRefreshToken(string) (*types.RefreshToken, error)
+		// RevokeRefreshToken returns false when the refresh token was revoked already
+		RevokeRefreshToken(types.RefreshToken) (bool, error)
+		Revoke(types.TokenRevocation) error
+		RevokeRoleMembers(string, time.Time, time.Time) error
+		RetrieveAllRevoked() (types.TokenRevocations, error)
+	}
+
+	MfaStore interface {
+		// Retrieve returns nil when the user did not enroll an authenticator
+		Retrieve(string) (*types.UserMfa, error)
+		Save(types.UserMfa) error
+		Delete(string) error
+		// UseTotpStep records the time step of an accepted code, it returns false when the step was already used
+		UseTotpStep(string, int64) (bool, error)
+		ReplaceRecoveryCodes(string, []string) error
+		// UseRecoveryCode marks a recovery code as used, it returns false when the code is unknown or was used
+		UseRecoveryCode(string, string) (bool, error)
+	}
+
+	PasswordStore interface {
+		// RetrieveHistory returns the latest password hashes of a user, the most recent first
+		RetrieveHistory(string, int) ([]types.PasswordHistory, error)
+		// AddHistory records the new password of a user and keeps the given number of latest entries
+		AddHistory(types.PasswordHistory, int) error
+		DeleteHistory(string) error
+	}
+
+	LockoutStore interface {
+		// Retrieve returns nil when the subject did not fail to authenticate
+		Retrieve(string) (*types.AuthLockout, error)
+		RetrieveAllLocked(time.Time) ([]types.AuthLockout, error)
+		// RecordFailure counts a failed authentication at the given time, the count restarts from one when the first
+		// counted failure happened before the start of the window
+		RecordFailure(string, time.Time, time.Time) (*types.AuthLockout, error)
+		Lock(string, time.Time) error
+		Delete(string) error
+		// DeleteExpired removes the entries that are not locked and whose first failure happened before the time
+		DeleteExpired(time.Time) error
+	}
+
+	AuditStore interface {
+		Create(types.AuditEvent) error
+		// Search returns the matching events, the most recent first
+		Search(*types.AuditEventSearch) (types.AuditEvents, error)
+	}
 )
@@ -0,0 +1,148 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package ldap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	ldapv3 "github.com/go-ldap/ldap/v3"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
+	"github.com/pkg/errors"
+)
+
+var defaultLog = log.GetDefaultLogger()
+
+// ErrInvalidCredentials is returned when the user does not exist in the directory or the password is wrong
+var ErrInvalidCredentials = errors.New("invalid directory credentials")
+
+// Directory authenticates users by binding to an LDAP directory or Active Directory with their distinguished name
+// and password. The distinguished name is searched with the service account of BindDN, or anonymously when no
+// BindDN is configured.
+type Directory struct {
+	URL            string
+	StartTLS       bool
+	TLSConfig      *tls.Config
+	BindDN         string
+	BindPassword   string
+	UserBaseDN     string
+	UserFilter     string
+	GroupAttribute string
+	Timeout        time.Duration
+}
+
+// NewDirectory returns the Directory of the LDAP configuration. The server certificate is verified with the
+// CA certificate of the configuration or with the system CAs when there is none.
+func NewDirectory(cfg config.LDAP) (*Directory, error) {
+	defaultLog.Trace("ldap/ldap:NewDirectory() Entering")
+	defer defaultLog.Trace("ldap/ldap:NewDirectory() Leaving")
+
+	ldapURL, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "ldap/ldap:NewDirectory() Invalid LDAP URL")
+	}
+	if ldapURL.Scheme != "ldap" && ldapURL.Scheme != "ldaps" {
+		return nil, errors.Errorf("ldap/ldap:NewDirectory() Unsupported LDAP URL scheme %s", ldapURL.Scheme)
+	}
+	if cfg.UserBaseDN == "" {
+		return nil, errors.New("ldap/ldap:NewDirectory() The user base DN is required")
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: ldapURL.Hostname(),
+	}
+	if cfg.CACertFile != "" {
+		caPem, err := ioutil.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "ldap/ldap:NewDirectory() Could not read the LDAP CA certificate")
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPem) {
+			return nil, errors.New("ldap/ldap:NewDirectory() Could not parse the LDAP CA certificate")
+		}
+	}
+
+	directory := &Directory{
+		URL:            cfg.URL,
+		StartTLS:       cfg.StartTLS,
+		TLSConfig:      tlsConfig,
+		BindDN:         cfg.BindDN,
+		BindPassword:   cfg.BindPassword,
+		UserBaseDN:     cfg.UserBaseDN,
+		UserFilter:     cfg.UserFilter,
+		GroupAttribute: cfg.GroupAttribute,
+		Timeout:        constants.DefaultLdapTimeout,
+	}
+	if directory.UserFilter == "" {
+		directory.UserFilter = constants.DefaultLdapUserFilter
+	}
+	if directory.GroupAttribute == "" {
+		directory.GroupAttribute = constants.DefaultLdapGroupAttribute
+	}
+	if !strings.Contains(directory.UserFilter, "%s") {
+		return nil, errors.New("ldap/ldap:NewDirectory() The user filter must contain %s for the user name")
+	}
+	return directory, nil
+}
+
+// Authenticate verifies the password of the user with a bind to the directory and returns the distinguished names
+// of the groups of the user
+func (d *Directory) Authenticate(username, password string) ([]string, error) {
+	defaultLog.Trace("ldap/ldap:Authenticate() Entering")
+	defer defaultLog.Trace("ldap/ldap:Authenticate() Leaving")
+
+	// an empty password would be an unauthenticated bind that succeeds for every user
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := ldapv3.DialURL(d.URL, ldapv3.DialWithDialer(&net.Dialer{Timeout: d.Timeout}),
+		ldapv3.DialWithTLSConfig(d.TLSConfig))
+	if err != nil {
+		return nil, errors.Wrap(err, "ldap/ldap:Authenticate() Could not connect to the directory")
+	}
+	defer conn.Close()
+	conn.SetTimeout(d.Timeout)
+
+	if d.StartTLS {
+		if err = conn.StartTLS(d.TLSConfig); err != nil {
+			return nil, errors.Wrap(err, "ldap/ldap:Authenticate() Could not start TLS")
+		}
+	}
+	if d.BindDN != "" {
+		if err = conn.Bind(d.BindDN, d.BindPassword); err != nil {
+			return nil, errors.Wrap(err, "ldap/ldap:Authenticate() Could not bind with the service account")
+		}
+	}
+
+	result, err := conn.Search(ldapv3.NewSearchRequest(d.UserBaseDN, ldapv3.ScopeWholeSubtree, ldapv3.NeverDerefAliases,
+		2, int(d.Timeout/time.Second), false, fmt.Sprintf(d.UserFilter, ldapv3.EscapeFilter(username)),
+		[]string{d.GroupAttribute}, nil))
+	if err != nil && !ldapv3.IsErrorWithCode(err, ldapv3.LDAPResultSizeLimitExceeded) {
+		return nil, errors.Wrap(err, "ldap/ldap:Authenticate() Could not search the user")
+	}
+	if result == nil || len(result.Entries) != 1 {
+		// an ambiguous filter must not let one user log in as another
+		return nil, ErrInvalidCredentials
+	}
+	user := result.Entries[0]
+
+	if err = conn.Bind(user.DN, password); err != nil {
+		if ldapv3.IsErrorWithCode(err, ldapv3.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, errors.Wrap(err, "ldap/ldap:Authenticate() Could not bind with the user credentials")
+	}
+	return user.GetAttributeValues(d.GroupAttribute), nil
+}
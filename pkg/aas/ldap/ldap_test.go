@@ -0,0 +1,189 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package ldap
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	ldapv3 "github.com/go-ldap/ldap/v3"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type directoryEntry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// testDirectory is an in-process LDAP stand-in that implements the simple bind and equality match searches
+type testDirectory struct {
+	listener net.Listener
+	entries  []directoryEntry
+}
+
+func newTestDirectory(t *testing.T, entries ...directoryEntry) *testDirectory {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	directory := &testDirectory{listener: listener, entries: entries}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go directory.serve(conn)
+		}
+	}()
+	return directory
+}
+
+func (d *testDirectory) url() string {
+	return "ldap://" + d.listener.Addr().String()
+}
+
+func (d *testDirectory) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		request, err := ber.ReadPacket(conn)
+		if err != nil || len(request.Children) < 2 {
+			return
+		}
+		messageID := request.Children[0].Value
+		op := request.Children[1]
+		switch op.Tag {
+		case ldapv3.ApplicationBindRequest:
+			resultCode := uint16(ldapv3.LDAPResultInvalidCredentials)
+			dn, password := op.Children[1].Data.String(), op.Children[2].Data.String()
+			for _, entry := range d.entries {
+				if entry.dn == dn && entry.password == password {
+					resultCode = ldapv3.LDAPResultSuccess
+				}
+			}
+			d.write(conn, messageID, ldapResult(ldapv3.ApplicationBindResponse, resultCode))
+		case ldapv3.ApplicationSearchRequest:
+			baseDN, filter := op.Children[0].Data.String(), op.Children[6]
+			if filter.Tag == ldapv3.FilterEqualityMatch {
+				attribute, value := filter.Children[0].Data.String(), filter.Children[1].Data.String()
+				for _, entry := range d.entries {
+					if !strings.HasSuffix(entry.dn, baseDN) || !contains(entry.attributes[attribute], value) {
+						continue
+					}
+					d.write(conn, messageID, searchResultEntry(entry))
+				}
+			}
+			d.write(conn, messageID, ldapResult(ldapv3.ApplicationSearchResultDone, ldapv3.LDAPResultSuccess))
+		default:
+			return
+		}
+	}
+}
+
+func (d *testDirectory) write(conn net.Conn, messageID interface{}, op *ber.Packet) {
+	response := ber.NewSequence("LDAP Response")
+	response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	response.AppendChild(op)
+	_, _ = conn.Write(response.Bytes())
+}
+
+func ldapResult(application ber.Tag, resultCode uint16) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, application, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(resultCode), "Result Code"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return result
+}
+
+func searchResultEntry(entry directoryEntry) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldapv3.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "DN"))
+	attributes := ber.NewSequence("Attributes")
+	for name, values := range entry.attributes {
+		attribute := ber.NewSequence("Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+	result.AppendChild(attributes)
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDirectoryAuthenticate(t *testing.T) {
+	testDir := newTestDirectory(t,
+		directoryEntry{dn: "cn=aas,dc=example,dc=com", password: "bindPassword"},
+		directoryEntry{dn: "uid=alice,ou=people,dc=example,dc=com", password: "alicePassword", attributes: map[string][]string{
+			"uid":      {"alice"},
+			"memberOf": {"cn=hvs-admins,ou=groups,dc=example,dc=com", "cn=operators,ou=groups,dc=example,dc=com"},
+		}},
+		directoryEntry{dn: "uid=bob,ou=people,dc=example,dc=com", password: "bobPassword", attributes: map[string][]string{
+			"uid": {"bob"},
+		}},
+		// two entries matching the same filter are ambiguous
+		directoryEntry{dn: "uid=carol,ou=people,dc=example,dc=com", password: "carolPassword", attributes: map[string][]string{"uid": {"carol"}}},
+		directoryEntry{dn: "uid=carol,ou=contractors,dc=example,dc=com", password: "carolPassword", attributes: map[string][]string{"uid": {"carol"}}},
+	)
+	defer testDir.listener.Close()
+
+	directory, err := NewDirectory(config.LDAP{
+		URL:          testDir.url(),
+		BindDN:       "cn=aas,dc=example,dc=com",
+		BindPassword: "bindPassword",
+		UserBaseDN:   "dc=example,dc=com",
+	})
+	assert.NoError(t, err)
+
+	groups, err := directory.Authenticate("alice", "alicePassword")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cn=hvs-admins,ou=groups,dc=example,dc=com", "cn=operators,ou=groups,dc=example,dc=com"}, groups)
+
+	groups, err = directory.Authenticate("bob", "bobPassword")
+	assert.NoError(t, err)
+	assert.Empty(t, groups)
+
+	_, err = directory.Authenticate("alice", "bobPassword")
+	assert.Equal(t, ErrInvalidCredentials, err)
+	_, err = directory.Authenticate("alice", "")
+	assert.Equal(t, ErrInvalidCredentials, err)
+	_, err = directory.Authenticate("dave", "davePassword")
+	assert.Equal(t, ErrInvalidCredentials, err)
+	_, err = directory.Authenticate("carol", "carolPassword")
+	assert.Equal(t, ErrInvalidCredentials, err)
+
+	directory.BindPassword = "wrong"
+	_, err = directory.Authenticate("alice", "alicePassword")
+	assert.Error(t, err)
+	assert.NotEqual(t, ErrInvalidCredentials, err)
+}
+
+func TestNewDirectory(t *testing.T) {
+	directory, err := NewDirectory(config.LDAP{URL: "ldaps://ad.example.com", UserBaseDN: "dc=example,dc=com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "(uid=%s)", directory.UserFilter)
+	assert.Equal(t, "memberOf", directory.GroupAttribute)
+	assert.Equal(t, "ad.example.com", directory.TLSConfig.ServerName)
+
+	_, err = NewDirectory(config.LDAP{URL: "https://ad.example.com", UserBaseDN: "dc=example,dc=com"})
+	assert.Error(t, err)
+	_, err = NewDirectory(config.LDAP{URL: "ldap://ad.example.com"})
+	assert.Error(t, err)
+	_, err = NewDirectory(config.LDAP{URL: "ldap://ad.example.com", UserBaseDN: "dc=example,dc=com", UserFilter: "(sAMAccountName=admin)"})
+	assert.Error(t, err)
+}
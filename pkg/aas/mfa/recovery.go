@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mfa
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+)
+
+// RecoveryCodeCount is the number of recovery codes that are issued when a user enrolls an authenticator
+const RecoveryCodeCount = 10
+
+// recovery codes are two groups of 5 base32 characters separated by a dash
+const recoveryCodeLength = 10
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateRecoveryCodes returns new single use recovery codes that replace the TOTP code when the authenticator of a
+// user is lost
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		random := make([]byte, recoveryCodeLength*5/8)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(random))
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+	}
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(code), "-", "", -1))
+}
+
+// IsRecoveryCode tells whether a code has the format of a recovery code
+func IsRecoveryCode(code string) bool {
+	code = normalizeRecoveryCode(code)
+	if len(code) != recoveryCodeLength {
+		return false
+	}
+	_, err := recoveryCodeEncoding.DecodeString(strings.ToUpper(code))
+	return err == nil
+}
+
+// HashRecoveryCode returns the hash of a recovery code that is stored in place of the code
+func HashRecoveryCode(code string) string {
+	hash := sha512.Sum384([]byte(normalizeRecoveryCode(code)))
+	return hex.EncodeToString(hash[:])
+}
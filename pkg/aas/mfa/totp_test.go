@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mfa
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// test vectors of RFC 6238 appendix B for SHA1, truncated to 6 digits
+func TestCode(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, code := range vectors {
+		assert.Equal(t, code, Code(secret, Step(time.Unix(unix, 0))), "time %d", unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+	now := time.Now()
+
+	step, ok := Validate(secret, Code(secret, Step(now)), now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	// codes of the previous and next time steps are accepted
+	_, ok = Validate(secret, Code(secret, Step(now)-1), now, 0)
+	assert.True(t, ok)
+	_, ok = Validate(secret, Code(secret, Step(now)+1), now, 0)
+	assert.True(t, ok)
+	_, ok = Validate(secret, Code(secret, Step(now)-3), now, 0)
+	assert.False(t, ok)
+
+	// a code can not be used twice
+	_, ok = Validate(secret, Code(secret, Step(now)), now, Step(now))
+	assert.False(t, ok)
+	_, ok = Validate(secret, "12345", now, 0)
+	assert.False(t, ok)
+}
+
+func TestKeyURI(t *testing.T) {
+	uri, err := url.Parse(KeyURI("AAS", "admin@aas", []byte("12345678901234567890")))
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/AAS:admin@aas", uri.Path)
+	assert.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", uri.Query().Get("secret"))
+	assert.Equal(t, "AAS", uri.Query().Get("issuer"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes()
+	assert.NoError(t, err)
+	assert.Len(t, codes, RecoveryCodeCount)
+	for _, code := range codes {
+		assert.True(t, IsRecoveryCode(code), code)
+		assert.Len(t, code, 11)
+	}
+	assert.NotEqual(t, codes[0], codes[1])
+
+	// the dash and the case are ignored
+	assert.Equal(t, HashRecoveryCode(codes[0]), HashRecoveryCode(strings.ToUpper(strings.Replace(codes[0], "-", "", 1))))
+	assert.False(t, IsRecoveryCode("123456"))
+}
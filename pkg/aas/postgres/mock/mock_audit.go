@@ -0,0 +1,28 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mock
+
+import (
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+)
+
+type MockAuditStore struct {
+	CreateFunc func(types.AuditEvent) error
+	SearchFunc func(*types.AuditEventSearch) (types.AuditEvents, error)
+}
+
+func (m *MockAuditStore) Create(event types.AuditEvent) error {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(event)
+	}
+	return nil
+}
+
+func (m *MockAuditStore) Search(search *types.AuditEventSearch) (types.AuditEvents, error) {
+	if m.SearchFunc != nil {
+		return m.SearchFunc(search)
+	}
+	return nil, nil
+}
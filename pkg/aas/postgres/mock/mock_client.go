@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mock
+
+import (
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	ct "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+)
+
+type MockClientStore struct {
+	CreateFunc         func(types.Client) (*types.Client, error)
+	RetrieveFunc       func(types.Client) (*types.Client, error)
+	RetrieveAllFunc    func(types.Client) (types.Clients, error)
+	DeleteFunc         func(types.Client) error
+	GetRolesFunc       func(types.Client, *types.RoleSearch, bool) ([]types.Role, error)
+	GetPermissionsFunc func(types.Client, []string) ([]ct.PermissionInfo, error)
+	AddRolesFunc       func(types.Client, types.Roles) error
+	DeleteRoleFunc     func(types.Client, string, []string) error
+}
+
+func (m *MockClientStore) Create(client types.Client) (*types.Client, error) {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(client)
+	}
+	return nil, nil
+}
+
+func (m *MockClientStore) Retrieve(client types.Client) (*types.Client, error) {
+	if m.RetrieveFunc != nil {
+		return m.RetrieveFunc(client)
+	}
+	return nil, nil
+}
+
+func (m *MockClientStore) RetrieveAll(client types.Client) (types.Clients, error) {
+	if m.RetrieveAllFunc != nil {
+		return m.RetrieveAllFunc(client)
+	}
+	return nil, nil
+}
+
+func (m *MockClientStore) Delete(client types.Client) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(client)
+	}
+	return nil
+}
+
+func (m *MockClientStore) GetRoles(client types.Client, rs *types.RoleSearch, includeID bool) ([]types.Role, error) {
+	if m.GetRolesFunc != nil {
+		return m.GetRolesFunc(client, rs, includeID)
+	}
+	return nil, nil
+}
+
+func (m *MockClientStore) GetPermissions(client types.Client, roleIDs []string) ([]ct.PermissionInfo, error) {
+	if m.GetPermissionsFunc != nil {
+		return m.GetPermissionsFunc(client, roleIDs)
+	}
+	return nil, nil
+}
+
+func (m *MockClientStore) AddRoles(client types.Client, roles types.Roles) error {
+	if m.AddRolesFunc != nil {
+		return m.AddRolesFunc(client, roles)
+	}
+	return nil
+}
+
+func (m *MockClientStore) DeleteRole(client types.Client, roleID string, svcFltr []string) error {
+	if m.DeleteRoleFunc != nil {
+		return m.DeleteRoleFunc(client, roleID, svcFltr)
+	}
+	return nil
+}
@@ -12,6 +12,12 @@ type MockDatabase struct {
 	MockUserStore       MockUserStore
 	MockRoleStore       MockRoleStore
 	MockPermissionStore MockPermissionStore
+	MockClientStore     MockClientStore
+	MockTokenStore      MockTokenStore
+	MockMfaStore        MockMfaStore
+	MockPasswordStore   MockPasswordStore
+	MockLockoutStore    MockLockoutStore
+	MockAuditStore      MockAuditStore
 }
 
 func (m *MockDatabase) Migrate() error {
@@ -30,6 +36,30 @@ func (m *MockDatabase) PermissionStore() domain.PermissionStore {
 	return &m.MockPermissionStore
 }
 
+func (m *MockDatabase) ClientStore() domain.ClientStore {
+	return &m.MockClientStore
+}
+
+func (m *MockDatabase) TokenStore() domain.TokenStore {
+	return &m.MockTokenStore
+}
+
+func (m *MockDatabase) MfaStore() domain.MfaStore {
+	return &m.MockMfaStore
+}
+
+func (m *MockDatabase) PasswordStore() domain.PasswordStore {
+	return &m.MockPasswordStore
+}
+
+func (m *MockDatabase) LockoutStore() domain.LockoutStore {
+	return &m.MockLockoutStore
+}
+
+func (m *MockDatabase) AuditStore() domain.AuditStore {
+	return &m.MockAuditStore
+}
+
 func (m *MockDatabase) Close() {
 
 }
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mock
+
+import (
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+)
+
+type MockLockoutStore struct {
+	RetrieveFunc          func(string) (*types.AuthLockout, error)
+	RetrieveAllLockedFunc func(time.Time) ([]types.AuthLockout, error)
+	RecordFailureFunc     func(string, time.Time, time.Time) (*types.AuthLockout, error)
+	LockFunc              func(string, time.Time) error
+	DeleteFunc            func(string) error
+	DeleteExpiredFunc     func(time.Time) error
+}
+
+func (m *MockLockoutStore) Retrieve(subject string) (*types.AuthLockout, error) {
+	if m.RetrieveFunc != nil {
+		return m.RetrieveFunc(subject)
+	}
+	return nil, nil
+}
+
+func (m *MockLockoutStore) RetrieveAllLocked(t time.Time) ([]types.AuthLockout, error) {
+	if m.RetrieveAllLockedFunc != nil {
+		return m.RetrieveAllLockedFunc(t)
+	}
+	return nil, nil
+}
+
+func (m *MockLockoutStore) RecordFailure(subject string, windowStart, t time.Time) (*types.AuthLockout, error) {
+	if m.RecordFailureFunc != nil {
+		return m.RecordFailureFunc(subject, windowStart, t)
+	}
+	return &types.AuthLockout{Subject: subject, FailedAttempts: 1, FirstFailedAt: t}, nil
+}
+
+func (m *MockLockoutStore) Lock(subject string, until time.Time) error {
+	if m.LockFunc != nil {
+		return m.LockFunc(subject, until)
+	}
+	return nil
+}
+
+func (m *MockLockoutStore) Delete(subject string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(subject)
+	}
+	return nil
+}
+
+func (m *MockLockoutStore) DeleteExpired(before time.Time) error {
+	if m.DeleteExpiredFunc != nil {
+		return m.DeleteExpiredFunc(before)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mock
+
+import (
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+)
+
+type MockMfaStore struct {
+	RetrieveFunc             func(string) (*types.UserMfa, error)
+	SaveFunc                 func(types.UserMfa) error
+	DeleteFunc               func(string) error
+	UseTotpStepFunc          func(string, int64) (bool, error)
+	ReplaceRecoveryCodesFunc func(string, []string) error
+	UseRecoveryCodeFunc      func(string, string) (bool, error)
+}
+
+func (m *MockMfaStore) Retrieve(userID string) (*types.UserMfa, error) {
+	if m.RetrieveFunc != nil {
+		return m.RetrieveFunc(userID)
+	}
+	return nil, nil
+}
+
+func (m *MockMfaStore) Save(mfa types.UserMfa) error {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(mfa)
+	}
+	return nil
+}
+
+func (m *MockMfaStore) Delete(userID string) error {
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(userID)
+	}
+	return nil
+}
+
+func (m *MockMfaStore) UseTotpStep(userID string, step int64) (bool, error) {
+	if m.UseTotpStepFunc != nil {
+		return m.UseTotpStepFunc(userID, step)
+	}
+	return true, nil
+}
+
+func (m *MockMfaStore) ReplaceRecoveryCodes(userID string, codeHashes []string) error {
+	if m.ReplaceRecoveryCodesFunc != nil {
+		return m.ReplaceRecoveryCodesFunc(userID, codeHashes)
+	}
+	return nil
+}
+
+func (m *MockMfaStore) UseRecoveryCode(userID, codeHash string) (bool, error) {
+	if m.UseRecoveryCodeFunc != nil {
+		return m.UseRecoveryCodeFunc(userID, codeHash)
+	}
+	return false, nil
+}
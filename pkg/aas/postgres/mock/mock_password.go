@@ -0,0 +1,36 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mock
+
+import (
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+)
+
+type MockPasswordStore struct {
+	RetrieveHistoryFunc func(string, int) ([]types.PasswordHistory, error)
+	AddHistoryFunc      func(types.PasswordHistory, int) error
+	DeleteHistoryFunc   func(string) error
+}
+
+func (m *MockPasswordStore) RetrieveHistory(userID string, count int) ([]types.PasswordHistory, error) {
+	if m.RetrieveHistoryFunc != nil {
+		return m.RetrieveHistoryFunc(userID, count)
+	}
+	return nil, nil
+}
+
+func (m *MockPasswordStore) AddHistory(entry types.PasswordHistory, keep int) error {
+	if m.AddHistoryFunc != nil {
+		return m.AddHistoryFunc(entry, keep)
+	}
+	return nil
+}
+
+func (m *MockPasswordStore) DeleteHistory(userID string) error {
+	if m.DeleteHistoryFunc != nil {
+		return m.DeleteHistoryFunc(userID)
+	}
+	return nil
+}
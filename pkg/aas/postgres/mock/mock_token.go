@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mock
+
+import (
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+)
+
+type MockTokenStore struct {
+	CreateRefreshTokenFunc   func(types.RefreshToken) (*types.RefreshToken, error)
+	RetrieveRefreshTokenFunc func(string) (*types.RefreshToken, error)
+	RevokeRefreshTokenFunc   func(types.RefreshToken) (bool, error)
+	RevokeFunc               func(types.TokenRevocation) error
+	RevokeRoleMembersFunc    func(string, time.Time, time.Time) error
+	RetrieveAllRevokedFunc   func() (types.TokenRevocations, error)
+}
+
+func (m *MockTokenStore) CreateRefreshToken(t types.RefreshToken) (*types.RefreshToken, error) {
+	if m.CreateRefreshTokenFunc != nil {
+		return m.CreateRefreshTokenFunc(t)
+	}
+	return &t, nil
+}
+
+func (m *MockTokenStore) RetrieveRefreshToken(tokenHash string) (*types.RefreshToken, error) {
+	if m.RetrieveRefreshTokenFunc != nil {
+		return m.RetrieveRefreshTokenFunc(tokenHash)
+	}
+	return nil, nil
+}
+
+func (m *MockTokenStore) RevokeRefreshToken(t types.RefreshToken) (bool, error) {
+	if m.RevokeRefreshTokenFunc != nil {
+		return m.RevokeRefreshTokenFunc(t)
+	}
+	return true, nil
+}
+
+func (m *MockTokenStore) Revoke(t types.TokenRevocation) error {
+	if m.RevokeFunc != nil {
+		return m.RevokeFunc(t)
+	}
+	return nil
+}
+
+func (m *MockTokenStore) RevokeRoleMembers(roleID string, revokedAt, expiresAt time.Time) error {
+	if m.RevokeRoleMembersFunc != nil {
+		return m.RevokeRoleMembersFunc(roleID, revokedAt, expiresAt)
+	}
+	return nil
+}
+
+func (m *MockTokenStore) RetrieveAllRevoked() (types.TokenRevocations, error) {
+	if m.RetrieveAllRevokedFunc != nil {
+		return m.RetrieveAllRevokedFunc()
+	}
+	return nil, nil
+}
@@ -15,6 +15,9 @@ type MockUserStore struct {
 	RetrieveAllFunc func(types.User) (types.Users, error)
 	UpdateFunc      func(types.User) error
 	DeleteFunc      func(types.User) error
+	GetRolesFunc    func(types.User, *types.RoleSearch, bool) ([]types.Role, error)
+	AddRolesFunc    func(types.User, types.Roles, bool) error
+	DeleteRoleFunc  func(types.User, string, []string) error
 }
 
 func (m *MockUserStore) Create(user types.User) (*types.User, error) {
@@ -57,6 +60,9 @@ func (m *MockUserStore) GetUserRoleByID(u types.User, roleID string) (types.Role
 }
 
 func (m *MockUserStore) GetRoles(user types.User, rs *types.RoleSearch, includeID bool) ([]types.Role, error) {
+	if m.GetRolesFunc != nil {
+		return m.GetRolesFunc(user, rs, includeID)
+	}
 	return nil, nil
 }
 
@@ -65,9 +71,15 @@ func (m *MockUserStore) GetPermissions(user types.User, rs *types.RoleSearch) ([
 }
 
 func (m *MockUserStore) AddRoles(u types.User, roleList types.Roles, mustAddAllRoles bool) error {
+	if m.AddRolesFunc != nil {
+		return m.AddRolesFunc(u, roleList, mustAddAllRoles)
+	}
 	return nil
 }
 
 func (m *MockUserStore) DeleteRole(u types.User, roleID string, svcFltr []string) error {
+	if m.DeleteRoleFunc != nil {
+		return m.DeleteRoleFunc(u, roleID, svcFltr)
+	}
 	return nil
 }
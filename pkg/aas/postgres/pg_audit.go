@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"strings"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+type PostgresAuditStore struct {
+	db *gorm.DB
+}
+
+func (r *PostgresAuditStore) Create(event types.AuditEvent) error {
+	defaultLog.Trace("audit Create")
+	defer defaultLog.Trace("audit Create done")
+
+	uuid, err := UUID()
+	if err != nil {
+		return errors.Wrap(err, "audit create: failed to get UUID")
+	}
+	event.ID = uuid
+	if err = r.db.Create(&event).Error; err != nil {
+		return errors.Wrap(err, "audit create: failed")
+	}
+	return nil
+}
+
+func (r *PostgresAuditStore) Search(search *types.AuditEventSearch) (types.AuditEvents, error) {
+	defaultLog.Trace("audit Search")
+	defer defaultLog.Trace("audit Search done")
+
+	tx := r.db
+	if search != nil {
+		if search.Actor != "" {
+			tx = tx.Where("actor = ?", search.Actor)
+		}
+		if search.Target != "" {
+			tx = tx.Where("target_id = ? OR target_name = ?", search.Target, search.Target)
+		}
+		if search.Action != "" {
+			tx = tx.Where("action = ?", search.Action)
+		}
+		if search.Permission != "" {
+			rulePrefix := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(search.Permission) + "%"
+			// the events granting a matching rule, the target did not hold the rule for that service and context before
+			tx = tx.Where(`EXISTS (SELECT 1 FROM jsonb_array_elements(after) AS p, jsonb_array_elements_text(p->'rules') AS rule
+				WHERE rule LIKE ? AND NOT EXISTS (SELECT 1 FROM jsonb_array_elements(COALESCE(before, '[]'::jsonb)) AS bp,
+					jsonb_array_elements_text(bp->'rules') AS before_rule
+					WHERE before_rule = rule AND bp->'service' = p->'service'
+						AND bp->'context' IS NOT DISTINCT FROM p->'context'))`, rulePrefix)
+		}
+		if !search.From.IsZero() {
+			tx = tx.Where("created_at >= ?", search.From)
+		}
+		if !search.To.IsZero() {
+			tx = tx.Where("created_at <= ?", search.To)
+		}
+		if search.Limit > 0 {
+			tx = tx.Limit(search.Limit)
+		}
+	}
+
+	var events types.AuditEvents
+	if err := tx.Order("created_at desc").Find(&events).Error; err != nil {
+		return nil, errors.Wrap(err, "audit search: failed")
+	}
+	return events, nil
+}
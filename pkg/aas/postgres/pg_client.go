@@ -0,0 +1,174 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+	ct "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+type PostgresClientStore struct {
+	db *gorm.DB
+}
+
+func (r *PostgresClientStore) Create(c types.Client) (*types.Client, error) {
+	defaultLog.Trace("client Create")
+	defer defaultLog.Trace("client Create done")
+
+	uuid, err := UUID()
+	if err == nil {
+		c.ID = uuid
+	} else {
+		return &c, errors.Wrap(err, "client create: failed to get UUID")
+	}
+	err = r.db.Create(&c).Error
+	if err != nil {
+		return &c, errors.Wrap(err, "client create: failed")
+	}
+	return &c, nil
+}
+
+func (r *PostgresClientStore) Retrieve(c types.Client) (*types.Client, error) {
+	defaultLog.Trace("client Retrieve")
+	defer defaultLog.Trace("client Retrieve done")
+
+	err := r.db.Where(&c).First(&c).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "client retrieve: failed")
+	}
+	return &c, nil
+}
+
+func (r *PostgresClientStore) RetrieveAll(c types.Client) (types.Clients, error) {
+	defaultLog.Trace("client RetrieveAll")
+	defer defaultLog.Trace("client RetrieveAll done")
+
+	var clients types.Clients
+	err := r.db.Where(&c).Find(&clients).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "client retrieve: failed")
+	}
+
+	return clients, nil
+}
+
+func (r *PostgresClientStore) Delete(c types.Client) error {
+	defaultLog.Trace("client Delete")
+	defer defaultLog.Trace("client Delete done")
+	if err := r.db.Model(&c).Association("Roles").Clear().Error; err != nil {
+		return errors.Wrap(err, "client delete: failed to clear client-role mapping")
+	}
+	if err := r.db.Delete(&c).Error; err != nil {
+		return errors.Wrap(err, "client delete: failed")
+	}
+	return nil
+}
+
+func (r *PostgresClientStore) GetRoles(c types.Client, rs *types.RoleSearch, includeID bool) (clientRoles []types.Role, err error) {
+	defaultLog.Trace("client GetRoles")
+	defer defaultLog.Trace("client GetRoles done")
+
+	var cols string
+
+	if includeID {
+		cols = "roles.id, "
+	}
+	cols = cols + "roles.service, roles.name, roles.context"
+	tx := r.db.Joins("INNER JOIN client_roles on client_roles.role_id = roles.id INNER JOIN clients on client_roles.client_id = clients.id").
+		Where("clients.id = ?", c.ID)
+
+	if rs != nil {
+		tx = buildRoleSearchQuery(tx, rs)
+	}
+	tx = tx.Select(cols)
+
+	if err := tx.Find(&clientRoles).Error; err != nil {
+		return clientRoles, errors.Wrap(err, "client get roles: failed")
+	}
+	return clientRoles, nil
+}
+
+// GetPermissions returns the permissions of the roles of the client. If role ids are provided, only the permissions
+// of these roles are returned.
+func (r *PostgresClientStore) GetPermissions(c types.Client, roleIDs []string) (clientPerms []ct.PermissionInfo, err error) {
+	defaultLog.Trace("client GetPermissions")
+	defer defaultLog.Trace("client GetPermissions done")
+
+	type Result struct {
+		Service string
+		Context string
+		Rule    string
+	}
+
+	var res = []Result{}
+	query := `
+	SELECT DISTINCT r.service as service, r.context as context, p.rule as rule
+	FROM clients c
+	INNER JOIN client_roles cr ON c.id = cr.client_id
+	INNER JOIN roles r ON cr.role_id = r.id
+	INNER JOIN role_permissions rp ON r.id = rp.role_id
+	INNER JOIN permissions p ON rp.permission_id = p.id
+	WHERE c.id = ?`
+	args := []interface{}{c.ID}
+	if len(roleIDs) > 0 {
+		query = query + ` AND r.id IN (?)`
+		args = append(args, roleIDs)
+	}
+	query = query + ` ORDER BY service, context`
+
+	if err := r.db.Raw(query, args...).Scan(&res).Error; err != nil {
+		return nil, errors.Wrap(err, "client get permissions: failed")
+	}
+
+	if len(res) == 0 {
+		return nil, nil
+	}
+
+	curr := ct.PermissionInfo{Service: res[0].Service, Context: res[0].Context, Rules: []string{res[0].Rule}}
+	for i := 1; i < len(res); i++ {
+		if res[i].Service == curr.Service && res[i].Context == curr.Context {
+			curr.Rules = append(curr.Rules, res[i].Rule)
+		} else {
+			clientPerms = append(clientPerms, curr)
+			curr = ct.PermissionInfo{Service: res[i].Service, Context: res[i].Context, Rules: []string{res[i].Rule}}
+		}
+	}
+	clientPerms = append(clientPerms, curr)
+
+	return clientPerms, nil
+}
+
+func (r *PostgresClientStore) AddRoles(c types.Client, roles types.Roles) error {
+	defaultLog.Trace("client AddRoles")
+	defer defaultLog.Trace("client AddRoles done")
+
+	if err := r.db.Model(&c).Association("Roles").Append(roles).Error; err != nil {
+		return errors.Wrap(err, "client add roles: failed")
+	}
+	return nil
+}
+
+func (r *PostgresClientStore) DeleteRole(c types.Client, roleID string, svcFltr []string) error {
+	defaultLog.Trace("client DeleteRole")
+	defer defaultLog.Trace("client DeleteRole done")
+
+	var role types.Role
+	tx := r.db.Where("id IN (?) ", roleID)
+	if len(svcFltr) > 0 {
+		tx = tx.Where("service in (?) ", svcFltr)
+	}
+
+	err := tx.Find(&role).Error
+	if err != nil {
+		return errors.Wrapf(err, "client delete roles: could not find role id %s in database", roleID)
+	}
+	if err = r.db.Model(&c).Association("Roles").Delete(role).Error; err != nil {
+		return errors.Wrap(err, "client delete role: failed")
+	}
+	return nil
+}
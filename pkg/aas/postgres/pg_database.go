@@ -175,7 +175,9 @@ func (pd *PostgresDatabase) Migrate() error {
 	defaultLog.Trace("Migrate")
 	defer defaultLog.Trace("Migrate done")
 
-	pd.Db.AutoMigrate(types.User{}, types.Role{}, types.Permission{})
+	pd.Db.AutoMigrate(types.User{}, types.Role{}, types.Permission{}, types.Client{}, types.RefreshToken{}, types.TokenRevocation{},
+		types.UserMfa{}, types.MfaRecoveryCode{}, types.PasswordHistory{}, types.AuthLockout{},
+		types.AuditEvent{})
 	return nil
 }
 
@@ -191,6 +193,14 @@ func (pd *PostgresDatabase) PermissionStore() domain.PermissionStore {
 	return &PostgresPermissionStore{db: pd.Db}
 }
 
+func (pd *PostgresDatabase) ClientStore() domain.ClientStore {
+	return &PostgresClientStore{db: pd.Db}
+}
+
+func (pd *PostgresDatabase) TokenStore() domain.TokenStore {
+	return &PostgresTokenStore{db: pd.Db}
+}
+
 func (pd *PostgresDatabase) Close() {
 	if pd.Db != nil {
 		err := pd.Db.Close()
@@ -235,3 +245,19 @@ func Open(host string, port int, dbname, user, password, sslMode, sslCert string
 	}
 	return &PostgresDatabase{Db: db}, nil
 }
+
+func (pd *PostgresDatabase) MfaStore() domain.MfaStore {
+	return &PostgresMfaStore{db: pd.Db}
+}
+
+func (pd *PostgresDatabase) PasswordStore() domain.PasswordStore {
+	return &PostgresPasswordStore{db: pd.Db}
+}
+
+func (pd *PostgresDatabase) LockoutStore() domain.LockoutStore {
+	return &PostgresLockoutStore{db: pd.Db}
+}
+
+func (pd *PostgresDatabase) AuditStore() domain.AuditStore {
+	return &PostgresAuditStore{db: pd.Db}
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+type PostgresLockoutStore struct {
+	db *gorm.DB
+}
+
+func (r *PostgresLockoutStore) Retrieve(subject string) (*types.AuthLockout, error) {
+	defaultLog.Trace("lockout Retrieve")
+	defer defaultLog.Trace("lockout Retrieve done")
+
+	var l types.AuthLockout
+	err := r.db.Where("subject = ?", subject).First(&l).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "lockout retrieve: failed")
+	}
+	return &l, nil
+}
+
+func (r *PostgresLockoutStore) RetrieveAllLocked(t time.Time) ([]types.AuthLockout, error) {
+	defaultLog.Trace("lockout RetrieveAllLocked")
+	defer defaultLog.Trace("lockout RetrieveAllLocked done")
+
+	var lockouts []types.AuthLockout
+	if err := r.db.Where("locked_until > ?", t).Order("subject").Find(&lockouts).Error; err != nil {
+		return nil, errors.Wrap(err, "lockout retrieve all locked: failed")
+	}
+	return lockouts, nil
+}
+
+func (r *PostgresLockoutStore) RecordFailure(subject string, windowStart, t time.Time) (*types.AuthLockout, error) {
+	defaultLog.Trace("lockout RecordFailure")
+	defer defaultLog.Trace("lockout RecordFailure done")
+
+	// the failure is counted with a single statement so that the AAS replicas can not miss each other's failures
+	query := `INSERT INTO auth_lockouts (subject, failed_attempts, first_failed_at) VALUES (?, 1, ?)
+		ON CONFLICT (subject) DO UPDATE SET
+		failed_attempts = CASE WHEN auth_lockouts.first_failed_at < ? THEN 1 ELSE auth_lockouts.failed_attempts + 1 END,
+		locked_until = CASE WHEN auth_lockouts.first_failed_at < ? THEN NULL ELSE auth_lockouts.locked_until END,
+		first_failed_at = CASE WHEN auth_lockouts.first_failed_at < ? THEN EXCLUDED.first_failed_at ELSE auth_lockouts.first_failed_at END
+		RETURNING subject, failed_attempts, first_failed_at, locked_until`
+	var l types.AuthLockout
+	if err := r.db.Raw(query, subject, t, windowStart, windowStart, windowStart).Scan(&l).Error; err != nil {
+		return nil, errors.Wrap(err, "lockout record failure: failed")
+	}
+	return &l, nil
+}
+
+func (r *PostgresLockoutStore) Lock(subject string, until time.Time) error {
+	defaultLog.Trace("lockout Lock")
+	defer defaultLog.Trace("lockout Lock done")
+
+	err := r.db.Model(&types.AuthLockout{}).Where("subject = ?", subject).Update("locked_until", until).Error
+	if err != nil {
+		return errors.Wrap(err, "lockout lock: failed")
+	}
+	return nil
+}
+
+func (r *PostgresLockoutStore) Delete(subject string) error {
+	defaultLog.Trace("lockout Delete")
+	defer defaultLog.Trace("lockout Delete done")
+
+	if err := r.db.Where("subject = ?", subject).Delete(&types.AuthLockout{}).Error; err != nil {
+		return errors.Wrap(err, "lockout delete: failed")
+	}
+	return nil
+}
+
+func (r *PostgresLockoutStore) DeleteExpired(before time.Time) error {
+	defaultLog.Trace("lockout DeleteExpired")
+	defer defaultLog.Trace("lockout DeleteExpired done")
+
+	err := r.db.Where("first_failed_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, time.Now()).
+		Delete(&types.AuthLockout{}).Error
+	if err != nil {
+		return errors.Wrap(err, "lockout delete expired: failed")
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+type PostgresMfaStore struct {
+	db *gorm.DB
+}
+
+func (r *PostgresMfaStore) Retrieve(userID string) (*types.UserMfa, error) {
+	defaultLog.Trace("mfa Retrieve")
+	defer defaultLog.Trace("mfa Retrieve done")
+
+	var m types.UserMfa
+	err := r.db.Where(&types.UserMfa{UserID: userID}).First(&m).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "mfa retrieve: failed")
+	}
+	return &m, nil
+}
+
+func (r *PostgresMfaStore) Save(m types.UserMfa) error {
+	defaultLog.Trace("mfa Save")
+	defer defaultLog.Trace("mfa Save done")
+
+	if err := r.db.Save(&m).Error; err != nil {
+		return errors.Wrap(err, "mfa save: failed")
+	}
+	return nil
+}
+
+func (r *PostgresMfaStore) Delete(userID string) error {
+	defaultLog.Trace("mfa Delete")
+	defer defaultLog.Trace("mfa Delete done")
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&types.MfaRecoveryCode{}).Error; err != nil {
+			return errors.Wrap(err, "mfa delete: failed to remove recovery codes")
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&types.UserMfa{}).Error; err != nil {
+			return errors.Wrap(err, "mfa delete: failed")
+		}
+		return nil
+	})
+}
+
+func (r *PostgresMfaStore) UseTotpStep(userID string, step int64) (bool, error) {
+	defaultLog.Trace("mfa UseTotpStep")
+	defer defaultLog.Trace("mfa UseTotpStep done")
+
+	// the conditional update makes sure that concurrent requests can not use the same code
+	tx := r.db.Model(&types.UserMfa{}).Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if tx.Error != nil {
+		return false, errors.Wrap(tx.Error, "mfa use totp step: failed")
+	}
+	return tx.RowsAffected == 1, nil
+}
+
+func (r *PostgresMfaStore) ReplaceRecoveryCodes(userID string, codeHashes []string) error {
+	defaultLog.Trace("mfa ReplaceRecoveryCodes")
+	defer defaultLog.Trace("mfa ReplaceRecoveryCodes done")
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&types.MfaRecoveryCode{}).Error; err != nil {
+			return errors.Wrap(err, "mfa recovery codes: failed to remove previous codes")
+		}
+		for _, codeHash := range codeHashes {
+			uuid, err := UUID()
+			if err != nil {
+				return errors.Wrap(err, "mfa recovery codes: failed to get UUID")
+			}
+			if err = tx.Create(&types.MfaRecoveryCode{ID: uuid, UserID: userID, CodeHash: codeHash}).Error; err != nil {
+				return errors.Wrap(err, "mfa recovery codes: failed to create code")
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PostgresMfaStore) UseRecoveryCode(userID, codeHash string) (bool, error) {
+	defaultLog.Trace("mfa UseRecoveryCode")
+	defer defaultLog.Trace("mfa UseRecoveryCode done")
+
+	tx := r.db.Model(&types.MfaRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if tx.Error != nil {
+		return false, errors.Wrap(tx.Error, "mfa use recovery code: failed")
+	}
+	return tx.RowsAffected == 1, nil
+}
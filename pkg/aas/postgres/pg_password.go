@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+type PostgresPasswordStore struct {
+	db *gorm.DB
+}
+
+func (r *PostgresPasswordStore) RetrieveHistory(userID string, count int) ([]types.PasswordHistory, error) {
+	defaultLog.Trace("password RetrieveHistory")
+	defer defaultLog.Trace("password RetrieveHistory done")
+
+	var history []types.PasswordHistory
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").Limit(count).Find(&history).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "password retrieve history: failed")
+	}
+	return history, nil
+}
+
+func (r *PostgresPasswordStore) AddHistory(entry types.PasswordHistory, keep int) error {
+	defaultLog.Trace("password AddHistory")
+	defer defaultLog.Trace("password AddHistory done")
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		uuid, err := UUID()
+		if err != nil {
+			return errors.Wrap(err, "password add history: failed to get UUID")
+		}
+		entry.ID = uuid
+		if err = tx.Create(&entry).Error; err != nil {
+			return errors.Wrap(err, "password add history: failed")
+		}
+		// the entries beyond the latest ones are not used anymore
+		err = tx.Where("user_id = ? AND id NOT IN (?)", entry.UserID,
+			tx.Model(&types.PasswordHistory{}).Select("id").Where("user_id = ?", entry.UserID).
+				Order("created_at desc").Limit(keep).SubQuery()).
+			Delete(&types.PasswordHistory{}).Error
+		if err != nil {
+			return errors.Wrap(err, "password add history: failed to remove previous entries")
+		}
+		return nil
+	})
+}
+
+func (r *PostgresPasswordStore) DeleteHistory(userID string) error {
+	defaultLog.Trace("password DeleteHistory")
+	defer defaultLog.Trace("password DeleteHistory done")
+
+	if err := r.db.Where("user_id = ?", userID).Delete(&types.PasswordHistory{}).Error; err != nil {
+		return errors.Wrap(err, "password delete history: failed")
+	}
+	return nil
+}
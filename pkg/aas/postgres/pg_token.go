@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/types"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+type PostgresTokenStore struct {
+	db *gorm.DB
+}
+
+func (r *PostgresTokenStore) CreateRefreshToken(t types.RefreshToken) (*types.RefreshToken, error) {
+	defaultLog.Trace("token CreateRefreshToken")
+	defer defaultLog.Trace("token CreateRefreshToken done")
+
+	uuid, err := UUID()
+	if err != nil {
+		return nil, errors.Wrap(err, "refresh token create: failed to get UUID")
+	}
+	t.ID = uuid
+	if err = r.db.Create(&t).Error; err != nil {
+		return nil, errors.Wrap(err, "refresh token create: failed")
+	}
+	return &t, nil
+}
+
+func (r *PostgresTokenStore) RetrieveRefreshToken(tokenHash string) (*types.RefreshToken, error) {
+	defaultLog.Trace("token RetrieveRefreshToken")
+	defer defaultLog.Trace("token RetrieveRefreshToken done")
+
+	var t types.RefreshToken
+	if err := r.db.Where(&types.RefreshToken{TokenHash: tokenHash}).First(&t).Error; err != nil {
+		return nil, errors.Wrap(err, "refresh token retrieve: failed")
+	}
+	return &t, nil
+}
+
+// RevokeRefreshToken revokes the refresh token unless it was revoked already, e.g. by a concurrent refresh with the
+// same token. It returns false in that case.
+func (r *PostgresTokenStore) RevokeRefreshToken(t types.RefreshToken) (bool, error) {
+	defaultLog.Trace("token RevokeRefreshToken")
+	defer defaultLog.Trace("token RevokeRefreshToken done")
+
+	db := r.db.Model(&types.RefreshToken{}).Where("id = ? AND revoked_at IS NULL", t.ID).Update("revoked_at", time.Now())
+	if db.Error != nil {
+		return false, errors.Wrap(db.Error, "refresh token revoke: failed")
+	}
+	return db.RowsAffected == 1, nil
+}
+
+// Revoke adds an entry to the revocation list. When the entry revokes every token of a subject, the refresh tokens
+// of the subject are revoked as well. Entries and refresh tokens that expired are removed.
+func (r *PostgresTokenStore) Revoke(t types.TokenRevocation) error {
+	defaultLog.Trace("token Revoke")
+	defer defaultLog.Trace("token Revoke done")
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return revoke(tx, t)
+	})
+}
+
+// RevokeRoleMembers revokes every token of the users and clients that have the role
+func (r *PostgresTokenStore) RevokeRoleMembers(roleID string, revokedAt, expiresAt time.Time) error {
+	defaultLog.Trace("token RevokeRoleMembers")
+	defer defaultLog.Trace("token RevokeRoleMembers done")
+
+	type Result struct {
+		Subject string
+	}
+	var res []Result
+	query := `
+	SELECT u.name AS subject FROM users u INNER JOIN user_roles ur ON u.id = ur.user_id WHERE ur.role_id = ?
+	UNION
+	SELECT CAST(c.id AS text) AS subject FROM clients c INNER JOIN client_roles cr ON c.id = cr.client_id WHERE cr.role_id = ?`
+	if err := r.db.Raw(query, roleID, roleID).Scan(&res).Error; err != nil {
+		return errors.Wrap(err, "revoke role members: failed to retrieve role members")
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, member := range res {
+			if err := revoke(tx, types.TokenRevocation{Subject: member.Subject, RevokedAt: revokedAt, ExpiresAt: expiresAt}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PostgresTokenStore) RetrieveAllRevoked() (types.TokenRevocations, error) {
+	defaultLog.Trace("token RetrieveAllRevoked")
+	defer defaultLog.Trace("token RetrieveAllRevoked done")
+
+	var revoked types.TokenRevocations
+	if err := r.db.Where("expires_at > ?", time.Now()).Find(&revoked).Error; err != nil {
+		return nil, errors.Wrap(err, "revoked tokens retrieve: failed")
+	}
+	return revoked, nil
+}
+
+func revoke(tx *gorm.DB, t types.TokenRevocation) error {
+	uuid, err := UUID()
+	if err != nil {
+		return errors.Wrap(err, "token revoke: failed to get UUID")
+	}
+	t.ID = uuid
+	if err = tx.Create(&t).Error; err != nil {
+		return errors.Wrap(err, "token revoke: failed")
+	}
+	if t.TokenID == "" {
+		err = tx.Model(&types.RefreshToken{}).Where("subject = ? AND revoked_at IS NULL", t.Subject).
+			Update("revoked_at", t.RevokedAt).Error
+		if err != nil {
+			return errors.Wrap(err, "token revoke: failed to revoke refresh tokens")
+		}
+	}
+
+	now := time.Now()
+	if err = tx.Where("expires_at <= ?", now).Delete(&types.TokenRevocation{}).Error; err != nil {
+		return errors.Wrap(err, "token revoke: failed to remove expired entries")
+	}
+	if err = tx.Where("expires_at <= ?", now).Delete(&types.RefreshToken{}).Error; err != nil {
+		return errors.Wrap(err, "token revoke: failed to remove expired refresh tokens")
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package router
+
+import (
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/controllers"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+)
+
+func SetAuditEventRoutes(r *mux.Router, db domain.AASDatabase) *mux.Router {
+	defaultLog.Trace("router/audit_event:SetAuditEventRoutes() Entering")
+	defer defaultLog.Trace("router/audit_event:SetAuditEventRoutes() Leaving")
+
+	controller := controllers.AuditEventController{Database: db}
+	r.Handle("/audit-events", ErrorHandler(permissionsHandler(ResponseHandler(controller.SearchAuditEvents,
+		"application/json"), []string{consts.AuditEventSearch}))).Methods("GET")
+	return r
+}
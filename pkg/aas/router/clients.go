@@ -0,0 +1,39 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package router
+
+import (
+	"time"
+
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/controllers"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+)
+
+func SetClientsRoutes(r *mux.Router, db domain.AASDatabase, tokenValidity time.Duration) *mux.Router {
+	defaultLog.Trace("router/clients:SetClientsRoutes() Entering")
+	defer defaultLog.Trace("router/clients:SetClientsRoutes() Leaving")
+
+	controller := controllers.ClientsController{Database: db, TokenValidity: tokenValidity}
+
+	r.Handle("/clients", ErrorHandler(permissionsHandler(ResponseHandler(controller.CreateClient,
+		"application/json"), []string{consts.ClientCreate}))).Methods("POST")
+	r.Handle("/clients", ErrorHandler(permissionsHandler(ResponseHandler(controller.QueryClients,
+		"application/json"), []string{consts.ClientSearch}))).Methods("GET")
+	r.Handle("/clients/{id}", ErrorHandler(permissionsHandler(ResponseHandler(controller.DeleteClient,
+		""), []string{consts.ClientDelete}))).Methods("DELETE")
+	r.Handle("/clients/{id}", ErrorHandler(permissionsHandler(ResponseHandler(controller.GetClient,
+		"application/json"), []string{consts.ClientRetrieve}))).Methods("GET")
+	r.Handle("/clients/{id}/roles", ErrorHandler(ResponseHandler(controller.AddClientRoles,
+		"application/json"))).Methods("POST")
+	r.Handle("/clients/{id}/roles", ErrorHandler(ResponseHandler(controller.QueryClientRoles,
+		"application/json"))).Methods("GET")
+	r.Handle("/clients/{id}/roles/{role_id}", ErrorHandler(ResponseHandler(controller.DeleteClientRole,
+		""))).Methods("DELETE")
+
+	return r
+}
@@ -7,6 +7,7 @@ package router
 
 import (
 	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/controllers"
 )
 
@@ -14,7 +15,22 @@ func SetJwtCertificateRoutes(r *mux.Router) *mux.Router {
 	defaultLog.Trace("router/jwt_certificate:SetJwtCertificateRoutes() Entering")
 	defer defaultLog.Trace("router/jwt_certificate:SetJwtCertificateRoutes() Leaving")
 
-	controller := controllers.JwtCertificateController{}
+	controller := controllers.JwtCertificateController{SigningCertsDir: consts.TokenSignKeysAndCertDir}
 	r.Handle("/jwt-certificates", ErrorHandler(ResponseHandler(controller.GetJwtCertificate, "application/x-pem-file"))).Methods("GET")
+	r.Handle("/jwks", ErrorHandler(ResponseHandler(controller.GetJwks, "application/json"))).Methods("GET")
+	return r
+}
+
+// SetOpenIDConfigurationRoutes registers the OpenID Connect discovery document, it is served without authentication
+func SetOpenIDConfigurationRoutes(r *mux.Router, issuer string) *mux.Router {
+	defaultLog.Trace("router/jwt_certificate:SetOpenIDConfigurationRoutes() Entering")
+	defer defaultLog.Trace("router/jwt_certificate:SetOpenIDConfigurationRoutes() Leaving")
+
+	controller := controllers.JwtCertificateController{
+		Issuer:          issuer,
+		SigningCertsDir: consts.TokenSignKeysAndCertDir,
+	}
+	r.Handle("/.well-known/openid-configuration", ErrorHandler(ResponseHandler(controller.GetOpenIDConfiguration,
+		"application/json"))).Methods("GET")
 	return r
 }
@@ -0,0 +1,27 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package router
+
+import (
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/controllers"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+)
+
+func SetLockoutRoutes(r *mux.Router, db domain.AASDatabase) *mux.Router {
+	defaultLog.Trace("router/lockout:SetLockoutRoutes() Entering")
+	defer defaultLog.Trace("router/lockout:SetLockoutRoutes() Leaving")
+
+	controller := controllers.LockoutController{Database: db}
+	r.Handle("/lockouts", ErrorHandler(permissionsHandler(ResponseHandler(controller.GetLockouts,
+		"application/json"), []string{consts.UserSearch}))).Methods("GET")
+	r.Handle("/users/{id}/lockout", ErrorHandler(permissionsHandler(ResponseHandler(controller.UnlockUser,
+		""), []string{consts.UserStore}))).Methods("DELETE")
+	r.Handle("/clients/{id}/lockout", ErrorHandler(permissionsHandler(ResponseHandler(controller.UnlockClient,
+		""), []string{consts.ClientCreate}))).Methods("DELETE")
+	return r
+}
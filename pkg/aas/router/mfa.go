@@ -0,0 +1,37 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package router
+
+import (
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/controllers"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+)
+
+func SetMfaRoutes(r *mux.Router, db domain.AASDatabase) *mux.Router {
+	defaultLog.Trace("router/mfa:SetMfaRoutes() Entering")
+	defer defaultLog.Trace("router/mfa:SetMfaRoutes() Leaving")
+
+	controller := controllers.MfaController{Database: db}
+	r.Handle("/users/{id}/mfa", ErrorHandler(permissionsHandler(ResponseHandler(controller.DeleteUserMfa,
+		""), []string{consts.UserStore}))).Methods("DELETE")
+	return r
+}
+
+// SetMfaNoAuthRoutes registers the enrollment of the TOTP authenticators, the users authenticate with their password
+// as they might not be able to get a token before they enrolled
+func SetMfaNoAuthRoutes(r *mux.Router, db domain.AASDatabase) *mux.Router {
+	defaultLog.Trace("router/mfa:SetMfaNoAuthRoutes() Entering")
+	defer defaultLog.Trace("router/mfa:SetMfaNoAuthRoutes() Leaving")
+
+	controller := controllers.MfaController{Database: db}
+	r.Handle("/users/mfa/totp", ErrorHandler(ResponseHandler(controller.EnrollTotp,
+		"application/json"))).Methods("POST")
+	r.Handle("/users/mfa/totp", ErrorHandler(ResponseHandler(controller.ConfirmTotp,
+		"application/json"))).Methods("PUT")
+	return r
+}
@@ -0,0 +1,42 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package router
+
+import (
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/controllers"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
+)
+
+func SetOAuth2TokenRoutes(r *mux.Router, db domain.AASDatabase, tokFactory *jwtauth.JwtFactory,
+	tokenValidity, accessTokenValidity, refreshTokenValidity time.Duration) *mux.Router {
+	defaultLog.Trace("router/oauth2_token:SetOAuth2TokenRoutes() Entering")
+	defer defaultLog.Trace("router/oauth2_token:SetOAuth2TokenRoutes() Leaving")
+
+	controller := controllers.OAuth2TokenController{
+		Database:             db,
+		TokenFactory:         tokFactory,
+		TokenValidity:        tokenValidity,
+		AccessTokenValidity:  accessTokenValidity,
+		RefreshTokenValidity: refreshTokenValidity,
+	}
+
+	caCerts, err := crypt.GetCertsFromDir(constants.TrustedCAsStoreDir)
+	if err != nil {
+		defaultLog.WithError(err).Warn("router/oauth2_token:SetOAuth2TokenRoutes() Could not load the trusted CAs, " +
+			"private_key_jwt client authentication is disabled")
+	} else {
+		controller.ClientCAs = crypt.GetCertPool(caCerts)
+	}
+
+	r.Handle("/oauth2/token", ErrorHandler(ResponseHandler(controller.CreateOAuth2Token, "application/json"))).Methods("POST")
+	return r
+}
@@ -6,16 +6,18 @@
 package router
 
 import (
+	"time"
+
 	"github.com/gorilla/mux"
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/controllers"
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
 )
 
-func SetRolesRoutes(r *mux.Router, db domain.AASDatabase) *mux.Router {
+func SetRolesRoutes(r *mux.Router, db domain.AASDatabase, tokenValidity time.Duration) *mux.Router {
 	defaultLog.Trace("router/roles:SetRolesRoutes() Entering")
 	defer defaultLog.Trace("router/roles:SetRolesRoutes() Leaving")
 
-	controller := controllers.RolesController{Database: db}
+	controller := controllers.RolesController{Database: db, TokenValidity: tokenValidity}
 
 	r.Handle("/roles", ErrorHandler(ResponseHandler(controller.CreateRole, "application/json"))).Methods("POST")
 	r.Handle("/roles", ErrorHandler(ResponseHandler(controller.QueryRoles, "application/json"))).Methods("GET")
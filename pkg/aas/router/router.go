@@ -12,9 +12,11 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/config"
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/postgres"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
 	cmw "github.com/intel-secl/intel-secl/v3/pkg/lib/common/middleware"
+	model "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
 )
 
 var defaultLog = log.GetDefaultLogger()
@@ -44,23 +46,65 @@ func defineSubRoutes(router *mux.Router, service string, cfg *config.Configurati
 	defaultLog.Trace("router/router:defineSubRoutes() Entering")
 	defer defaultLog.Trace("router/router:defineSubRoutes() Leaving")
 
+	tokenValidity := durationMinsOrDefault(cfg.JWT.TokenDurationMins, constants.DefaultAasJwtDurationMins)
+	accessTokenValidity := durationMinsOrDefault(cfg.JWT.AccessTokenDurationMins, constants.DefaultAasAccessTokenMins)
+	refreshTokenValidity := durationMinsOrDefault(cfg.JWT.RefreshTokenDurationMins, constants.DefaultAasRefreshTokenMins)
+	// revocations are kept until every token they revoke has expired
+	maxTokenValidity := tokenValidity
+	if accessTokenValidity > maxTokenValidity {
+		maxTokenValidity = accessTokenValidity
+	}
+
 	serviceApi := "/" + service
 	subRouter := router.PathPrefix(serviceApi + "/noauth").Subrouter()
 	subRouter = SetVersionRoutes(subRouter)
 	subRouter = SetJwtCertificateRoutes(subRouter)
+	subRouter = SetRevocationListRoutes(subRouter, dataStore)
 
 	subRouter = router.PathPrefix(serviceApi).Subrouter()
 	subRouter = SetJwtTokenRoutes(subRouter, dataStore, tokenFactory)
 	subRouter = SetUsersNoAuthRoutes(subRouter, dataStore)
+	subRouter = SetMfaNoAuthRoutes(subRouter, dataStore)
+	subRouter = SetOAuth2TokenRoutes(subRouter, dataStore, tokenFactory, tokenValidity, accessTokenValidity, refreshTokenValidity)
+	issuer := cfg.JWT.Issuer
+	if issuer == "" {
+		issuer = constants.DefaultAasJwtIssuer
+	}
+	subRouter = SetOpenIDConfigurationRoutes(subRouter, issuer)
 
 	subRouter = router.PathPrefix(serviceApi).Subrouter()
 	cfgRouter := Router{cfg: cfg}
-	subRouter.Use(cmw.NewTokenAuth(constants.TokenSignKeysAndCertDir,
+	subRouter.Use(cmw.NewTokenAuthWithRevocationCheck(constants.TokenSignKeysAndCertDir,
 		constants.TrustedCAsStoreDir, cfgRouter.retrieveJWTSigningCerts,
-		time.Minute*constants.DefaultJwtValidateCacheKeyMins))
-	subRouter = SetRolesRoutes(subRouter, dataStore)
-	subRouter = SetUsersRoutes(subRouter, dataStore)
+		time.Minute*constants.DefaultJwtValidateCacheKeyMins,
+		cmw.NewRevocationListCache(revocationListRetriever(dataStore), time.Second*constants.DefaultRevocationListCacheSecs,
+			time.Second*constants.DefaultRevocationListStaleSecs)))
+	subRouter = SetRolesRoutes(subRouter, dataStore, maxTokenValidity)
+	subRouter = SetUsersRoutes(subRouter, dataStore, maxTokenValidity)
+	subRouter = SetMfaRoutes(subRouter, dataStore)
+	subRouter = SetLockoutRoutes(subRouter, dataStore)
+	subRouter = SetAuditEventRoutes(subRouter, dataStore)
+	subRouter = SetClientsRoutes(subRouter, dataStore, maxTokenValidity)
+	subRouter = SetTokenRevocationRoutes(subRouter, dataStore, tokenFactory, maxTokenValidity)
+
+}
+
+func durationMinsOrDefault(mins, defaultMins int) time.Duration {
+	if mins == 0 {
+		mins = defaultMins
+	}
+	return time.Duration(mins) * time.Minute
+}
 
+// revocationListRetriever reads the revocation list used by the AAS token validation directly from the database
+func revocationListRetriever(db domain.AASDatabase) cmw.RetrieveRevocationListFn {
+	return func() (*model.RevocationList, error) {
+		revocations, err := db.TokenStore().RetrieveAllRevoked()
+		if err != nil {
+			return nil, err
+		}
+		return revocations.RevocationList(), nil
+	}
 }
 
 func (router Router) retrieveJWTSigningCerts() error {
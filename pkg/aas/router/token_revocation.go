@@ -0,0 +1,36 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package router
+
+import (
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/controllers"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
+)
+
+func SetTokenRevocationRoutes(r *mux.Router, db domain.AASDatabase, tokenFactory *jwtauth.JwtFactory,
+	tokenValidity time.Duration) *mux.Router {
+	defaultLog.Trace("router/token_revocation:SetTokenRevocationRoutes() Entering")
+	defer defaultLog.Trace("router/token_revocation:SetTokenRevocationRoutes() Leaving")
+
+	controller := controllers.TokenRevocationController{Database: db, TokenFactory: tokenFactory, TokenValidity: tokenValidity}
+
+	r.Handle("/oauth2/revoke", ErrorHandler(ResponseHandler(controller.RevokeToken, "application/json"))).Methods("POST")
+	return r
+}
+
+func SetRevocationListRoutes(r *mux.Router, db domain.AASDatabase) *mux.Router {
+	defaultLog.Trace("router/token_revocation:SetRevocationListRoutes() Entering")
+	defer defaultLog.Trace("router/token_revocation:SetRevocationListRoutes() Leaving")
+
+	controller := controllers.TokenRevocationController{Database: db}
+
+	r.Handle("/revoked-tokens", ErrorHandler(ResponseHandler(controller.GetRevocationList, "application/json"))).Methods("GET")
+	return r
+}
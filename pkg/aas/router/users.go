@@ -6,17 +6,19 @@
 package router
 
 import (
+	"time"
+
 	"github.com/gorilla/mux"
 	consts "github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/controllers"
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/domain"
 )
 
-func SetUsersRoutes(r *mux.Router, db domain.AASDatabase) *mux.Router {
+func SetUsersRoutes(r *mux.Router, db domain.AASDatabase, tokenValidity time.Duration) *mux.Router {
 	defaultLog.Trace("router/users:SetUsersRoutes() Entering")
 	defer defaultLog.Trace("router/users:SetUsersRoutes() Leaving")
 
-	controller := controllers.UsersController{Database: db}
+	controller := controllers.UsersController{Database: db, TokenValidity: tokenValidity}
 
 	r.Handle("/users", ErrorHandler(permissionsHandler(ResponseHandler(controller.CreateUser,
 		"application/json"), []string{consts.UserCreate}))).Methods("POST")
@@ -8,21 +8,27 @@ package aas
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509/pkix"
 	"fmt"
 	"github.com/gorilla/handlers"
+	authcommon "github.com/intel-secl/intel-secl/v3/pkg/aas/common"
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/aas/ldap"
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/postgres"
 	"github.com/intel-secl/intel-secl/v3/pkg/aas/router"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
 	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
 	commLog "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
 	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/setup"
+	commTls "github.com/intel-secl/intel-secl/v3/pkg/lib/common/tls"
 	"github.com/pkg/errors"
 	"io/ioutil"
 	stdlog "log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -51,9 +57,13 @@ func (a *App) initJwtTokenFactory() (*jwtauth.JwtFactory, error) {
 		}
 	}
 
+	issuer := cfg.JWT.Issuer
+	if issuer == "" {
+		issuer = constants.DefaultAasJwtIssuer
+	}
 	return jwtauth.NewTokenFactory(privKeyDer,
 		cfg.JWT.IncludeKid, certPemBytes,
-		"AAS JWT Issuer",
+		issuer,
 		time.Duration(cfg.JWT.TokenDurationMins)*time.Minute)
 }
 
@@ -81,18 +91,77 @@ func (a *App) startServer() error {
 		return err
 	}
 
+	// users without a local password are authenticated by the LDAP directory when it is configured
+	if c.LDAP.URL != "" {
+		directory, err := ldap.NewDirectory(c.LDAP)
+		if err != nil {
+			return errors.Wrap(err, "An error occurred while initializing LDAP user federation")
+		}
+		if err = authcommon.SetIdentityProvider(directory, dataStore.RoleStore(), dataStore.AuditStore(), c.LDAP.GroupRoles); err != nil {
+			return errors.Wrap(err, "An error occurred while initializing LDAP user federation")
+		}
+		defaultLog.Infof("LDAP user federation with %s enabled", c.LDAP.URL)
+	}
+
+	// the TOTP secrets of the users are encrypted with the MFA key
+	mfaKey, err := authcommon.LoadMfaKey(constants.MfaKeyFile)
+	if err != nil {
+		return errors.Wrap(err, "An error occurred while initializing MFA")
+	}
+	if err = authcommon.SetMfaPolicy(mfaKey, c.MFA.RequiredRoles); err != nil {
+		return errors.Wrap(err, "An error occurred while initializing MFA")
+	}
+
+	if err = authcommon.SetPasswordPolicy(c.PasswordPolicy); err != nil {
+		return errors.Wrap(err, "An error occurred while initializing password policy")
+	}
+	// the failed authentications are counted in the database, the lockouts are shared by the AAS replicas
+	lockoutQuit := make(chan struct{})
+	defer close(lockoutQuit)
+	authcommon.SetLockoutStore(dataStore.LockoutStore(), c.AuthDefender, lockoutQuit)
+
 	// Initialize routes
 	routes := router.InitRoutes(c, dataStore, jwtFactory)
 
 	// ISECL-8715 - Prevent potential open redirects to external URLs
 	routes.SkipClean(true)
 
+	// Load the TLS certificate, it is reloaded when the files change and renewed with CMS before it expires. The
+	// token for CMS is issued by this AAS instance for the renewal user
+	certReloader, err := commTls.NewCertReloader(c.TLS.CertFile, c.TLS.KeyFile, c.TLS.RenewBefore, c.TLS.ReloadInterval,
+		setup.RenewCertWithUser(setup.DownloadCert{
+			KeyFile:       c.TLS.KeyFile,
+			CertFile:      c.TLS.CertFile,
+			KeyAlgorithm:  constants.DefaultKeyAlgorithm,
+			KeyLength:     constants.DefaultKeyLength,
+			Subject:       pkix.Name{CommonName: c.TLS.CommonName},
+			SanList:       c.TLS.SANList,
+			CertType:      "tls",
+			CaCertDirPath: constants.TrustedCAsStoreDir,
+			CmsBaseURL:    c.CMSBaseURL,
+		}, fmt.Sprintf("https://127.0.0.1:%d/%s/", c.Server.Port, strings.ToLower(constants.ServiceName)),
+			c.TLS.RenewUsername, c.TLS.RenewPassword))
+	if err != nil {
+		return errors.Wrap(err, "An error occurred while loading TLS certificate")
+	}
+	certReloader.Start()
+	defer certReloader.Stop()
+
 	tlsconfig := &tls.Config{
 		MinVersion: tls.VersionTLS12,
 		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
 			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
 			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
 			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+		GetCertificate: certReloader.GetCertificate,
+	}
+	// clients registered for tls_client_auth present a CMS issued certificate when requesting a token
+	caCerts, err := crypt.GetCertsFromDir(constants.TrustedCAsStoreDir)
+	if err != nil {
+		defaultLog.WithError(err).Warn("Could not load the trusted CAs, TLS client authentication is disabled")
+	} else {
+		tlsconfig.ClientAuth = tls.VerifyClientCertIfGiven
+		tlsconfig.ClientCAs = crypt.GetCertPool(caCerts)
 	}
 	// Setup signal handlers to gracefully handle termination
 	stop := make(chan os.Signal)
@@ -112,9 +181,7 @@ func (a *App) startServer() error {
 
 	// dispatch web server go routine
 	go func() {
-		tlsCert := c.TLS.CertFile
-		tlsKey := c.TLS.KeyFile
-		if err := h.ListenAndServeTLS(tlsCert, tlsKey); err != nil {
+		if err := h.ListenAndServeTLS("", ""); err != nil {
 			defaultLog.WithError(err).Info("Failed to start HTTPS server")
 			stop <- syscall.SIGTERM
 		}
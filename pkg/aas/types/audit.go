@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/pkg/errors"
+)
+
+// actions recorded in the audit trail
+const (
+	AuditUserCreate       = "user:create"
+	AuditUserUpdate       = "user:update"
+	AuditUserDelete       = "user:delete"
+	AuditUserPassword     = "user:change_password"
+	AuditUserRolesAdd     = "user_roles:add"
+	AuditUserRoleDelete   = "user_roles:delete"
+	AuditRoleCreate       = "role:create"
+	AuditRoleDelete       = "role:delete"
+	AuditClientRolesAdd   = "client_roles:add"
+	AuditClientRoleDelete = "client_roles:delete"
+	AuditUserRolesSync    = "user_roles:sync"
+)
+
+// AuditActorFederation is the actor of the changes made by the user federation when a directory user logs in
+const AuditActorFederation = "ldap-federation"
+
+// types of the targets of the audit events
+const (
+	AuditTargetUser   = "user"
+	AuditTargetRole   = "role"
+	AuditTargetClient = "client"
+)
+
+// AuditPermissions is a permission set recorded in the audit trail, it is stored as JSONB
+type AuditPermissions []aasModel.PermissionInfo
+
+func (p AuditPermissions) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(p)
+}
+
+func (p *AuditPermissions) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("types/audit:AuditPermissions_Scan() - type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, p)
+}
+
+// AuditEvent struct is the database schema of an AuditEvents table, it records a change of the users, roles or
+// permissions. Before and After are the permissions of the target user, client or role around the change.
+type AuditEvent struct {
+	ID         string           `json:"id" gorm:"primary_key;type:uuid"`
+	CreatedAt  time.Time        `json:"time" gorm:"index"`
+	Actor      string           `json:"actor" gorm:"index"`
+	Action     string           `json:"action"`
+	TargetType string           `json:"target_type"`
+	TargetID   string           `json:"target_id" gorm:"index"`
+	TargetName string           `json:"target_name" gorm:"index"`
+	Before     AuditPermissions `json:"before,omitempty" sql:"type:JSONB"`
+	After      AuditPermissions `json:"after,omitempty" sql:"type:JSONB"`
+	SourceIP   string           `json:"source_ip"`
+}
+
+type AuditEvents []AuditEvent
+
+// AuditEventSearch filters the audit events. Target matches the ID or the name of the target, Permission matches the
+// events that granted the target a rule starting with it, the rule is in After but not in Before.
+type AuditEventSearch struct {
+	Actor      string
+	Target     string
+	Action     string
+	Permission string
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
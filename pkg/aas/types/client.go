@@ -0,0 +1,31 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package types
+
+import (
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Client struct is the database schema of a Clients table. A client is a service account that authenticates
+// with the OAuth2 client credentials grant
+type Client struct {
+	ID                      string     `json:"client_id" gorm:"primary_key;type:uuid"`
+	CreatedAt               time.Time  `json:"-"`
+	UpdatedAt               time.Time  `json:"-"`
+	DeletedAt               *time.Time `json:"-"`
+	Name                    string     `json:"client_name" gorm:"unique;not null"`
+	TokenEndpointAuthMethod string     `json:"token_endpoint_auth_method"`
+	SecretHash              []byte     `json:"-"`
+	CertificateCommonName   string     `json:"certificate_common_name,omitempty"`
+	Roles                   []Role     `json:"roles,omitempty" gorm:"many2many:client_roles"`
+}
+
+type Clients []Client
+
+func (c *Client) CheckSecret(secret []byte) error {
+	return bcrypt.CompareHashAndPassword(c.SecretHash, secret)
+}
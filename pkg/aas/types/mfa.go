@@ -0,0 +1,30 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package types
+
+import (
+	"time"
+)
+
+// UserMfa struct is the database schema of a UserMfas table, it holds the TOTP authenticator of a user. The secrets
+// are encrypted with the MFA key of AAS. PendingSecret is the secret of an enrollment that was not confirmed yet.
+type UserMfa struct {
+	UserID        string `gorm:"primary_key;type:uuid"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	TotpSecret    []byte
+	PendingSecret []byte
+	Enabled       bool
+	// LastUsedStep is the time step of the last accepted code, a code is accepted only once
+	LastUsedStep int64
+}
+
+// MfaRecoveryCode struct is the database schema of a MfaRecoveryCodes table. Only the hash of the code is stored
+type MfaRecoveryCode struct {
+	ID       string `gorm:"primary_key;type:uuid"`
+	UserID   string `gorm:"type:uuid;not null;index"`
+	CodeHash string `gorm:"not null"`
+	UsedAt   *time.Time
+}
@@ -0,0 +1,32 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package types
+
+import (
+	"time"
+)
+
+// PasswordHistory struct is the database schema of a PasswordHistories table, it holds the hashes of the latest
+// passwords of a user, the current one included. The latest entry tells when the password was last changed.
+type PasswordHistory struct {
+	ID           string `gorm:"primary_key;type:uuid"`
+	UserID       string `gorm:"type:uuid;not null;index"`
+	CreatedAt    time.Time
+	PasswordHash []byte `gorm:"not null"`
+}
+
+// AuthLockout struct is the database schema of an AuthLockouts table, it counts the failed authentications of a user
+// or a client. The subject is the user name, or client:<client id> for the clients.
+type AuthLockout struct {
+	Subject        string `gorm:"primary_key"`
+	FailedAttempts int
+	FirstFailedAt  time.Time
+	LockedUntil    *time.Time
+}
+
+// Locked tells whether the subject is locked out at the given time
+func (l *AuthLockout) Locked(t time.Time) bool {
+	return l.LockedUntil != nil && l.LockedUntil.After(t)
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package types
+
+import (
+	. "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"time"
+)
+
+// RefreshToken struct is the database schema of a RefreshTokens table. Only the hash of the refresh token is stored
+type RefreshToken struct {
+	ID        string `gorm:"primary_key;type:uuid"`
+	CreatedAt time.Time
+	TokenHash string `gorm:"unique;not null"`
+	Subject   string `gorm:"not null;index"`
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+// TokenRevocation struct is the database schema of a TokenRevocations table. An entry without TokenID revokes every
+// token of the Subject issued before RevokedAt, which is a whole second
+type TokenRevocation struct {
+	ID        string `gorm:"primary_key;type:uuid"`
+	CreatedAt time.Time
+	TokenID   string `gorm:"index"`
+	Subject   string `gorm:"index"`
+	RevokedAt time.Time
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+type TokenRevocations []TokenRevocation
+
+// RevocationList returns the revocations as they are published to the services that validate AAS tokens
+func (revocations TokenRevocations) RevocationList() *RevocationList {
+	revocationList := &RevocationList{RevokedTokens: []RevokedToken{}}
+	for _, revocation := range revocations {
+		revokedToken := RevokedToken{
+			TokenID:   revocation.TokenID,
+			RevokedAt: revocation.RevokedAt,
+			ExpiresAt: revocation.ExpiresAt,
+		}
+		if revocation.TokenID == "" {
+			revokedToken.SubjectHash = SubjectHash(revocation.Subject)
+		}
+		revocationList.RevokedTokens = append(revocationList.RevokedTokens, revokedToken)
+	}
+	return revocationList
+}
@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/intel-secl/intel-secl/v3/pkg/clients"
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
 	types "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
 	"io/ioutil"
 	"net/http"
@@ -48,6 +49,12 @@ var (
 	ErrHTTPGetRolesForUser = &clients.HTTPClientErr{
 		ErrMessage: "Failed to get roles for user",
 	}
+	ErrHTTPGetRevokedTokens = &clients.HTTPClientErr{
+		ErrMessage: "Failed to get revoked tokens",
+	}
+	ErrHTTPGetJwks = &clients.HTTPClientErr{
+		ErrMessage: "Failed to get JWK set",
+	}
 )
 
 func (c *Client) prepReqHeader(req *http.Request) {
@@ -331,3 +338,67 @@ func (c *Client) AddRoleToUser(userID string, r types.RoleIDs) error {
 	}
 	return nil
 }
+
+// GetRevokedTokens retrieves the token revocation list of AAS, no token is required to retrieve it
+func (c *Client) GetRevokedTokens() (*types.RevocationList, error) {
+
+	revokedTokensURL := clients.ResolvePath(c.BaseURL, "noauth/revoked-tokens")
+
+	req, err := http.NewRequest(http.MethodGet, revokedTokensURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if c.HTTPClient == nil {
+		return nil, errors.New("aasClient.GetRevokedTokens: HTTPClient should not be null")
+	}
+	rsp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		ErrHTTPGetRevokedTokens.RetCode = rsp.StatusCode
+		return nil, ErrHTTPGetRevokedTokens
+	}
+
+	var revocationList types.RevocationList
+	err = json.NewDecoder(rsp.Body).Decode(&revocationList)
+	if err != nil {
+		return nil, err
+	}
+	return &revocationList, nil
+}
+
+// GetJwks retrieves the JWK set of the token signing keys of AAS, no token is required to retrieve it
+func (c *Client) GetJwks() (*jwtauth.JSONWebKeySet, error) {
+
+	jwksURL := clients.ResolvePath(c.BaseURL, "noauth/jwks")
+
+	req, err := http.NewRequest(http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if c.HTTPClient == nil {
+		return nil, errors.New("aasClient.GetJwks: HTTPClient should not be null")
+	}
+	rsp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		ErrHTTPGetJwks.RetCode = rsp.StatusCode
+		return nil, ErrHTTPGetJwks
+	}
+
+	var jwks jwtauth.JSONWebKeySet
+	err = json.NewDecoder(rsp.Body).Decode(&jwks)
+	if err != nil {
+		return nil, err
+	}
+	return &jwks, nil
+}
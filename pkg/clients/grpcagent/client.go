@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package grpcagent
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/clients"
+	"github.com/intel-secl/intel-secl/v3/pkg/clients/util"
+	commLog "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+var log = commLog.GetDefaultLogger()
+var secLog = commLog.GetSecurityLogger()
+
+const requestTimeout = 2 * time.Minute
+
+// AgentClient calls the AttestationAgent service of a gRPC quote agent
+type AgentClient interface {
+	GetHostInfo() (*HostInfo, error)
+	GetQuote(nonce []byte, pcrList []int, pcrBankList []string) (*QuoteResponse, error)
+}
+
+func NewAgentClient(aasApiUrl string, agentApiUrl *url.URL, serviceUserName, serviceUserPassword string,
+	trustedCaCerts []x509.Certificate) (AgentClient, error) {
+
+	if agentApiUrl == nil || agentApiUrl.Scheme != "https" {
+		return nil, errors.New("client/grpc_agent_client:NewAgentClient() gRPC agent URL must use https")
+	}
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				MinVersion: tls.VersionTLS12,
+				RootCAs:    clients.GetCertPool(trustedCaCerts),
+			},
+			// gRPC requires HTTP/2, which is not negotiated by default with a custom TLS configuration
+			ForceAttemptHTTP2: true,
+		},
+		Timeout: requestTimeout,
+	}
+
+	return &agentClient{
+		AasURL:          aasApiUrl,
+		BaseURL:         agentApiUrl,
+		ServiceUsername: serviceUserName,
+		ServicePassword: serviceUserPassword,
+		TrustedCaCerts:  trustedCaCerts,
+		httpClient:      httpClient,
+	}, nil
+}
+
+type agentClient struct {
+	AasURL          string
+	BaseURL         *url.URL
+	ServiceUsername string
+	ServicePassword string
+	TrustedCaCerts  []x509.Certificate
+	httpClient      *http.Client
+}
+
+func (ac *agentClient) GetHostInfo() (*HostInfo, error) {
+	log.Trace("clients/grpc_agent_client:GetHostInfo() Entering")
+	defer log.Trace("clients/grpc_agent_client:GetHostInfo() Leaving")
+
+	hostInfo := &HostInfo{}
+	err := ac.invoke("GetHostInfo", &HostInfoRequest{}, hostInfo)
+	if err != nil {
+		return nil, errors.Wrap(err, "client/grpc_agent_client:GetHostInfo() Error while getting host info from agent")
+	}
+	log.Info("client/grpc_agent_client:GetHostInfo() Successfully received host details from agent")
+	return hostInfo, nil
+}
+
+func (ac *agentClient) GetQuote(nonce []byte, pcrList []int, pcrBankList []string) (*QuoteResponse, error) {
+	log.Trace("clients/grpc_agent_client:GetQuote() Entering")
+	defer log.Trace("clients/grpc_agent_client:GetQuote() Leaving")
+
+	quoteRequest := &QuoteRequest{
+		Nonce:    nonce,
+		PcrBanks: pcrBankList,
+	}
+	for _, pcr := range pcrList {
+		quoteRequest.Pcrs = append(quoteRequest.Pcrs, uint32(pcr))
+	}
+
+	quoteResponse := &QuoteResponse{}
+	err := ac.invoke("GetQuote", quoteRequest, quoteResponse)
+	if err != nil {
+		return nil, errors.Wrap(err, "client/grpc_agent_client:GetQuote() Error while getting TPM quote from agent")
+	}
+	log.Info("client/grpc_agent_client:GetQuote() Successfully received TPM quote from agent")
+	return quoteResponse, nil
+}
+
+// invoke makes a unary call to the agent. A call that is rejected as unauthenticated is retried once with a token
+// fetched again from AAS.
+func (ac *agentClient) invoke(method string, request, response proto.Message) error {
+	requestURL := ac.BaseURL.ResolveReference(&url.URL{Path: grpcPath(method)})
+	requestMessage, err := proto.Marshal(request)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal request")
+	}
+	requestBody := encodeGrpcMessage(requestMessage)
+
+	for attempt := 0; ; attempt++ {
+		httpRequest, err := http.NewRequest(http.MethodPost, requestURL.String(), bytes.NewReader(requestBody))
+		if err != nil {
+			return err
+		}
+		httpRequest.Header.Set("Content-Type", grpcContentType+"+proto")
+		httpRequest.Header.Set("TE", "trailers")
+		if ac.ServiceUsername != "" {
+			err = util.AddJWTToken(httpRequest, ac.AasURL, ac.ServiceUsername, ac.ServicePassword, ac.TrustedCaCerts,
+				attempt > 0)
+			if err != nil {
+				return errors.Wrap(err, "Failed to add JWT token")
+			}
+		}
+
+		log.Debugf("clients/grpc_agent_client:invoke() gRPC request URL: %s", requestURL.String())
+		httpResponse, err := ac.httpClient.Do(httpRequest)
+		if err != nil {
+			return errors.Wrap(err, "Error from response")
+		}
+		// a body longer than the largest accepted message is not read further
+		responseBody, err := ioutil.ReadAll(io.LimitReader(httpResponse.Body, grpcMaxMessageSize+grpcMessagePrefixSize+1))
+		closeErr := httpResponse.Body.Close()
+		if closeErr != nil {
+			log.WithError(closeErr).Error("Error closing response body")
+		}
+		if err != nil {
+			return errors.Wrap(err, "Error reading response")
+		}
+		if httpResponse.StatusCode != http.StatusOK {
+			return errors.Errorf("HTTP Status :%d", httpResponse.StatusCode)
+		}
+		if len(responseBody) > grpcMaxMessageSize+grpcMessagePrefixSize {
+			return errors.Errorf("gRPC response exceeds the maximum message size of %d bytes", grpcMaxMessageSize)
+		}
+
+		if status := grpcStatus(httpResponse); status != nil {
+			if status.Code == GrpcStatusUnauthenticated && ac.ServiceUsername != "" && attempt == 0 {
+				secLog.Debug("clients/grpc_agent_client:invoke() Agent rejected token, retrying with a new token")
+				continue
+			}
+			return status
+		}
+		message, err := decodeGrpcMessage(responseBody)
+		if err != nil {
+			return err
+		}
+		return proto.Unmarshal(message, response)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package grpcagent
+
+import (
+	"crypto/x509"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAgentClient(t *testing.T, server *httptest.Server) AgentClient {
+	assertions := assert.New(t)
+	serverURL, err := url.Parse(server.URL)
+	assertions.NoError(err)
+	client, err := NewAgentClient("", serverURL, "", "", []x509.Certificate{*server.Certificate()})
+	assertions.NoError(err)
+	return client
+}
+
+func TestAgentClientGetHostInfo(t *testing.T) {
+	assertions := assert.New(t)
+	mockAgent, err := NewMockAgentServer(&HostInfo{HostName: "host1", PcrBanks: []string{"SHA256"}}, nil)
+	assertions.NoError(err)
+	defer mockAgent.Close()
+
+	hostInfo, err := newTestAgentClient(t, mockAgent.Server).GetHostInfo()
+	assertions.NoError(err)
+	assertions.Equal("host1", hostInfo.HostName)
+	assertions.Equal([]string{"SHA256"}, hostInfo.PcrBanks)
+}
+
+func TestAgentClientRejectsOversizedResponse(t *testing.T) {
+	assertions := assert.New(t)
+
+	// the agent announces a message above the size limit and keeps sending data
+	written := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", grpcContentType+"+proto")
+		w.WriteHeader(http.StatusOK)
+		prefix := make([]byte, grpcMessagePrefixSize)
+		binary.BigEndian.PutUint32(prefix[1:], 4*grpcMaxMessageSize)
+		if _, err := w.Write(prefix); err != nil {
+			return
+		}
+		chunk := make([]byte, 64*1024)
+		for written < 4*grpcMaxMessageSize {
+			n, err := w.Write(chunk)
+			written += n
+			if err != nil {
+				return
+			}
+		}
+		writeGrpcStatus(w, true, nil)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	_, err := newTestAgentClient(t, server).GetHostInfo()
+	assertions.Error(err)
+	assertions.Contains(err.Error(), "exceeds the maximum message size")
+}
+
+func TestAgentClientRejectsMessageSizeMismatch(t *testing.T) {
+	assertions := assert.New(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", grpcContentType+"+proto")
+		w.WriteHeader(http.StatusOK)
+		message := encodeGrpcMessage([]byte{0x0a, 0x05, 'h', 'o', 's', 't', '1'})
+		binary.BigEndian.PutUint32(message[1:], grpcMaxMessageSize+1)
+		_, _ = w.Write(message)
+		writeGrpcStatus(w, true, nil)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	_, err := newTestAgentClient(t, server).GetHostInfo()
+	assertions.Error(err)
+}
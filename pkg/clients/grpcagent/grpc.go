@@ -0,0 +1,125 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package grpcagent
+
+// The messages of the agent protocol in agent.pb.go are generated from agent.proto
+//go:generate protoc --go_out=. --go_opt=paths=source_relative agent.proto
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Unary gRPC calls are sent as HTTP/2 POST requests to /<service>/<method>. Request and response bodies carry one
+// length prefixed protobuf message each and the status of the call is returned in the grpc-status and grpc-message
+// trailers, or in the headers of a response without a body.
+// See https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md
+
+const (
+	agentService = "attestation.v1.AttestationAgent"
+
+	grpcContentType       = "application/grpc"
+	grpcMessagePrefixSize = 5
+	grpcStatusHeader      = "Grpc-Status"
+	grpcMessageHeader     = "Grpc-Message"
+
+	// maximum size of the messages accepted from an agent, TPM quotes and event logs are far below
+	grpcMaxMessageSize = 16 * 1024 * 1024
+)
+
+// gRPC status codes used by the agent protocol, see https://github.com/grpc/grpc/blob/master/doc/statuscodes.md
+const (
+	GrpcStatusOk              = 0
+	GrpcStatusInvalidArgument = 3
+	GrpcStatusUnimplemented   = 12
+	GrpcStatusInternal        = 13
+	GrpcStatusUnauthenticated = 16
+)
+
+// GrpcError is returned for a call that the agent completed with a status other than OK
+type GrpcError struct {
+	Code    int
+	Message string
+}
+
+func (e *GrpcError) Error() string {
+	return fmt.Sprintf("gRPC status %d: %s", e.Code, e.Message)
+}
+
+func grpcPath(method string) string {
+	return "/" + agentService + "/" + method
+}
+
+func encodeGrpcMessage(message []byte) []byte {
+	b := make([]byte, grpcMessagePrefixSize, grpcMessagePrefixSize+len(message))
+	binary.BigEndian.PutUint32(b[1:], uint32(len(message)))
+	return append(b, message...)
+}
+
+func decodeGrpcMessage(b []byte) ([]byte, error) {
+	if len(b) < grpcMessagePrefixSize {
+		return nil, errors.New("gRPC message is truncated")
+	}
+	if b[0] != 0 {
+		return nil, errors.New("Compressed gRPC messages are not supported")
+	}
+	size := binary.BigEndian.Uint32(b[1:grpcMessagePrefixSize])
+	if size > grpcMaxMessageSize || int(size) != len(b)-grpcMessagePrefixSize {
+		return nil, errors.Errorf("gRPC message size %d does not match the body", size)
+	}
+	return b[grpcMessagePrefixSize:], nil
+}
+
+// grpcStatus reads the status of a call from the response trailers or, for a response without a body, the headers
+func grpcStatus(response *http.Response) *GrpcError {
+	header := response.Trailer
+	if header.Get(grpcStatusHeader) == "" {
+		header = response.Header
+	}
+	code, err := strconv.Atoi(header.Get(grpcStatusHeader))
+	if err != nil {
+		return &GrpcError{Code: GrpcStatusInternal, Message: "Missing gRPC status in response"}
+	}
+	if code == GrpcStatusOk {
+		return nil
+	}
+	message, err := url.PathUnescape(header.Get(grpcMessageHeader))
+	if err != nil {
+		message = header.Get(grpcMessageHeader)
+	}
+	return &GrpcError{Code: code, Message: message}
+}
+
+// writeGrpcStatus completes a call on the server side. Without a message written, the status is sent in the
+// headers as a trailers-only response.
+func writeGrpcStatus(w http.ResponseWriter, messageWritten bool, status *GrpcError) {
+	code, message := GrpcStatusOk, ""
+	if status != nil {
+		code, message = status.Code, status.Message
+	}
+	prefix := http.TrailerPrefix
+	if !messageWritten {
+		prefix = ""
+		w.Header().Set("Content-Type", grpcContentType)
+	}
+	w.Header().Set(prefix+grpcStatusHeader, strconv.Itoa(code))
+	if message != "" {
+		w.Header().Set(prefix+grpcMessageHeader, url.PathEscape(message))
+	}
+	if !messageWritten {
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func isGrpcContentType(contentType string) bool {
+	return contentType == grpcContentType || strings.HasPrefix(contentType, grpcContentType+"+") ||
+		strings.HasPrefix(contentType, grpcContentType+";")
+}
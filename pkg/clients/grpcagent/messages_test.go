@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package grpcagent
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestQuoteMessagesRoundTrip(t *testing.T) {
+	assertions := assert.New(t)
+
+	quoteRequest := &QuoteRequest{
+		Nonce:    []byte("0123456789abcdefghij"),
+		Pcrs:     []uint32{0, 7, 23},
+		PcrBanks: []string{"SHA1", "SHA256"},
+	}
+	requestBytes, err := proto.Marshal(quoteRequest)
+	assertions.NoError(err)
+	decodedRequest := &QuoteRequest{}
+	assertions.NoError(proto.Unmarshal(requestBytes, decodedRequest))
+	assertions.True(proto.Equal(quoteRequest, decodedRequest))
+
+	quoteResponse := &QuoteResponse{
+		Quote:          []byte{0xff, 0x54, 0x43, 0x47},
+		Signature:      []byte{0x00, 0x14},
+		PcrValues:      make([]byte, 32),
+		AikCertificate: []byte{0x30, 0x82},
+		EventLog:       []byte("[]"),
+		HostInfo: &HostInfo{
+			HostName:            "host1",
+			HardwareUuid:        "00000000-0000-0000-0000-000000000001",
+			OsName:              "RedHatEnterprise",
+			NumberOfSockets:     2,
+			TpmVersion:          "2.0",
+			PcrBanks:            []string{"SHA1", "SHA256"},
+			TpmEnabled:          true,
+			SecureBootEnabled:   true,
+			InstalledComponents: []string{"tagent"},
+		},
+	}
+	responseBytes, err := proto.Marshal(quoteResponse)
+	assertions.NoError(err)
+	decodedResponse := &QuoteResponse{}
+	assertions.NoError(proto.Unmarshal(responseBytes, decodedResponse))
+	assertions.True(proto.Equal(quoteResponse, decodedResponse))
+}
+
+func TestUnmarshalSkipsUnknownFields(t *testing.T) {
+	assertions := assert.New(t)
+
+	b := protowire.AppendTag(nil, 100, protowire.VarintType)
+	b = protowire.AppendVarint(b, 42)
+	b = protowire.AppendTag(b, 101, protowire.BytesType)
+	b = protowire.AppendString(b, "unknown")
+	// unpacked repeated values are accepted as well
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, 17)
+
+	quoteRequest := &QuoteRequest{}
+	assertions.NoError(proto.Unmarshal(b, quoteRequest))
+	assertions.Equal([]uint32{17}, quoteRequest.GetPcrs())
+
+	assertions.Error(proto.Unmarshal([]byte{0x0a, 0x05, 0x01}, quoteRequest), "truncated field must fail")
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package grpcagent
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	tpmGeneratedValue = 0xff544347
+	tpmStAttestQuote  = 0x8018
+	tpmAlgRsassa      = 0x0014
+	tpmAlgSha1        = 0x0004
+	tpmAlgSha256      = 0x000b
+
+	mockPcrCount = 24
+)
+
+// MockAgentServer is a gRPC quote agent for tests. It quotes simulated PCR values with a software AIK, so that the
+// quotes can be verified like the quotes of a TPM.
+type MockAgentServer struct {
+	Server *httptest.Server
+	// AikCertificate is the self signed certificate of the AIK returned with the quotes
+	AikCertificate []byte
+	HostInfo       *HostInfo
+	// PcrValues holds the 24 simulated PCR values of each bank by bank name, e.g. "SHA256"
+	PcrValues map[string][][]byte
+	EventLog  []byte
+	// BearerToken is required in the authorization metadata of calls when set
+	BearerToken string
+	aik         *rsa.PrivateKey
+}
+
+// NewMockAgentServer starts a mock agent with PCR values of all zeros for the SHA1 and SHA256 banks
+func NewMockAgentServer(hostInfo *HostInfo, eventLog []byte) (*MockAgentServer, error) {
+	aik, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not generate AIK")
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mock agent AIK"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	aikCertificate, err := x509.CreateCertificate(rand.Reader, &template, &template, &aik.PublicKey, aik)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not create AIK certificate")
+	}
+
+	mockAgent := &MockAgentServer{
+		AikCertificate: aikCertificate,
+		HostInfo:       hostInfo,
+		PcrValues: map[string][][]byte{
+			"SHA1":   make([][]byte, mockPcrCount),
+			"SHA256": make([][]byte, mockPcrCount),
+		},
+		EventLog: eventLog,
+		aik:      aik,
+	}
+	for i := 0; i < mockPcrCount; i++ {
+		mockAgent.PcrValues["SHA1"][i] = make([]byte, sha1.Size)
+		mockAgent.PcrValues["SHA256"][i] = make([]byte, sha256.Size)
+	}
+
+	mockAgent.Server = httptest.NewUnstartedServer(http.HandlerFunc(mockAgent.serveGrpc))
+	mockAgent.Server.EnableHTTP2 = true
+	mockAgent.Server.StartTLS()
+	return mockAgent, nil
+}
+
+// ConnectionString returns the HVS connection string of the mock agent
+func (s *MockAgentServer) ConnectionString(username, password string) string {
+	return "grpc:" + s.Server.URL + ";u=" + username + ";p=" + password
+}
+
+func (s *MockAgentServer) Close() {
+	s.Server.Close()
+}
+
+func (s *MockAgentServer) serveGrpc(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ProtoMajor != 2 || !isGrpcContentType(r.Header.Get("Content-Type")) {
+		http.Error(w, "Not a gRPC request", http.StatusUnsupportedMediaType)
+		return
+	}
+	if s.BearerToken != "" && r.Header.Get("Authorization") != "Bearer "+s.BearerToken {
+		writeGrpcStatus(w, false, &GrpcError{Code: GrpcStatusUnauthenticated, Message: "Invalid bearer token"})
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeGrpcStatus(w, false, &GrpcError{Code: GrpcStatusInternal, Message: err.Error()})
+		return
+	}
+	message, err := decodeGrpcMessage(body)
+	if err != nil {
+		writeGrpcStatus(w, false, &GrpcError{Code: GrpcStatusInvalidArgument, Message: err.Error()})
+		return
+	}
+
+	var response proto.Message
+	switch r.URL.Path {
+	case grpcPath("GetHostInfo"):
+		response = s.HostInfo
+	case grpcPath("GetQuote"):
+		quoteRequest := &QuoteRequest{}
+		if err = proto.Unmarshal(message, quoteRequest); err == nil {
+			response, err = s.Quote(quoteRequest)
+		}
+		if err != nil {
+			writeGrpcStatus(w, false, &GrpcError{Code: GrpcStatusInvalidArgument, Message: err.Error()})
+			return
+		}
+	default:
+		writeGrpcStatus(w, false, &GrpcError{Code: GrpcStatusUnimplemented, Message: "Unknown method " + r.URL.Path})
+		return
+	}
+
+	responseMessage, err := proto.Marshal(response)
+	if err != nil {
+		writeGrpcStatus(w, false, &GrpcError{Code: GrpcStatusInternal, Message: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", grpcContentType+"+proto")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(encodeGrpcMessage(responseMessage))
+	writeGrpcStatus(w, true, nil)
+}
+
+// Quote returns the response of the mock agent to a quote request, it can be used to simulate hosts that push their
+// quotes
+func (s *MockAgentServer) Quote(request *QuoteRequest) (*QuoteResponse, error) {
+	var pcrSelections, pcrValues bytes.Buffer
+	for _, pcrBank := range request.PcrBanks {
+		var algorithm uint16
+		switch strings.ToUpper(pcrBank) {
+		case "SHA1":
+			algorithm = tpmAlgSha1
+		case "SHA256":
+			algorithm = tpmAlgSha256
+		default:
+			return nil, errors.Errorf("Unsupported PCR bank %s", pcrBank)
+		}
+		selection := make([]byte, 3)
+		for _, pcr := range request.Pcrs {
+			if pcr >= mockPcrCount {
+				return nil, errors.Errorf("Invalid PCR index %d", pcr)
+			}
+			selection[pcr/8] |= 1 << (pcr % 8)
+		}
+		_ = binary.Write(&pcrSelections, binary.BigEndian, algorithm)
+		pcrSelections.WriteByte(byte(len(selection)))
+		pcrSelections.Write(selection)
+		for pcr := 0; pcr < mockPcrCount; pcr++ {
+			if selection[pcr/8]&(1<<(pcr%8)) != 0 {
+				pcrValues.Write(s.PcrValues[strings.ToUpper(pcrBank)][pcr])
+			}
+		}
+	}
+	pcrDigest := sha256.Sum256(pcrValues.Bytes())
+
+	// TPMS_ATTEST of a quote without signer name, clock or firmware information
+	var attest bytes.Buffer
+	_ = binary.Write(&attest, binary.BigEndian, uint32(tpmGeneratedValue))
+	_ = binary.Write(&attest, binary.BigEndian, uint16(tpmStAttestQuote))
+	_ = binary.Write(&attest, binary.BigEndian, uint16(0))
+	_ = binary.Write(&attest, binary.BigEndian, uint16(len(request.Nonce)))
+	attest.Write(request.Nonce)
+	attest.Write(make([]byte, 17+8))
+	_ = binary.Write(&attest, binary.BigEndian, uint32(len(request.PcrBanks)))
+	attest.Write(pcrSelections.Bytes())
+	_ = binary.Write(&attest, binary.BigEndian, uint16(len(pcrDigest)))
+	attest.Write(pcrDigest[:])
+
+	attestDigest := sha256.Sum256(attest.Bytes())
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, s.aik, crypto.SHA256, attestDigest[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not sign quote")
+	}
+	var signature bytes.Buffer
+	_ = binary.Write(&signature, binary.BigEndian, uint16(tpmAlgRsassa))
+	_ = binary.Write(&signature, binary.BigEndian, uint16(tpmAlgSha256))
+	_ = binary.Write(&signature, binary.BigEndian, uint16(len(rsaSignature)))
+	signature.Write(rsaSignature)
+
+	return &QuoteResponse{
+		Quote:          attest.Bytes(),
+		Signature:      signature.Bytes(),
+		PcrValues:      pcrValues.Bytes(),
+		AikCertificate: s.AikCertificate,
+		EventLog:       s.EventLog,
+		HostInfo:       s.HostInfo,
+	}, nil
+}
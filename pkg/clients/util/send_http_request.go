@@ -66,6 +66,34 @@ func addJWTToken(req *http.Request, aasURL, serviceUsername, servicePassword str
 	return nil
 }
 
+//AddJWTToken adds the bearer token of the service user to a request that is not sent with SendRequest, such as a
+//gRPC call. With refresh set, the cached tokens are fetched again from AAS before the token is added.
+func AddJWTToken(req *http.Request, aasURL, serviceUsername, servicePassword string,
+	trustedCaCerts []x509.Certificate, refresh bool) error {
+	log.Trace("clients/send_http_request:AddJWTToken() Entering")
+	defer log.Trace("clients/send_http_request:AddJWTToken() Leaving")
+
+	var err error
+	//This has to be done for dynamic loading or unloading of certificates
+	if len(trustedCaCerts) == 0 {
+		aasClient.HTTPClient = clients.HTTPClientTLSNoVerify()
+	} else {
+		aasClient.HTTPClient, err = clients.HTTPClientWithCA(trustedCaCerts)
+		if err != nil {
+			return errors.Wrap(err, "clients/send_http_request.go:AddJWTToken() Failed to create http client")
+		}
+	}
+	if refresh && aasClient.BaseURL != "" {
+		aasRWLock.Lock()
+		err = aasClient.FetchAllTokens()
+		aasRWLock.Unlock()
+		if err != nil {
+			log.WithError(err).Error("clients/send_http_request.go:AddJWTToken() Failed to fetch all JWT token")
+		}
+	}
+	return addJWTToken(req, aasURL, serviceUsername, servicePassword, trustedCaCerts)
+}
+
 //SendRequest method is used to create an http client object and send the request to the server
 func SendRequest(req *http.Request, aasURL, serviceUsername, servicePassword string,
 	trustedCaCerts []x509.Certificate) ([]byte, error) {
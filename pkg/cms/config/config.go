@@ -12,6 +12,7 @@ import (
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v2"
 	"os"
+	"time"
 )
 
 // Configuration is the global configuration struct that is marshalled/unmarshalled to a persisted yaml file
@@ -21,11 +22,15 @@ type Configuration struct {
 	CACert            CACertConfig            `yaml:"cms-ca" mapstructure:"cms-ca"`
 	TlsCertDigest     string                  `yaml:"tls-cert-digest" mapstructure:"tls-cert-digest"`
 	TlsSanList        string                  `yaml:"san-list" mapstructure:"san-list"`
+	TlsRenewBefore    time.Duration           `yaml:"tls-renew-before" mapstructure:"tls-renew-before"`
+	TlsReloadInterval time.Duration           `yaml:"tls-reload-interval" mapstructure:"tls-reload-interval"`
 	TokenDurationMins int                     `yaml:"token-duration-mins" mapstructure:"token-duration-mins"`
 	Server            commConfig.ServerConfig `yaml:"server" mapstructure:"server"`
 	AasJwtCn          string                  `yaml:"aas-jwt-cn" mapstructure:"aas-jwt-cn"`
 	AasTlsCn          string                  `yaml:"aas-tls-cn" mapstructure:"aas-tls-cn"`
 	AasTlsSan         string                  `yaml:"aas-tls-san" mapstructure:"aas-tls-san"`
+	Revocation        RevocationConfig        `yaml:"revocation" mapstructure:"revocation"`
+	Acme              AcmeConfig              `yaml:"acme" mapstructure:"acme"`
 }
 
 type CACertConfig struct {
@@ -34,6 +39,33 @@ type CACertConfig struct {
 	Locality     string `yaml:"locality" mapstructure:"locality"`
 	Province     string `yaml:"province" mapstructure:"province"`
 	Country      string `yaml:"country" mapstructure:"country"`
+	// Pkcs11 configures the token in which the CA keys are generated and kept
+	Pkcs11 Pkcs11Config `yaml:"pkcs11" mapstructure:"pkcs11"`
+}
+
+type Pkcs11Config struct {
+	// Module is the path of the PKCS#11 library, such as libsofthsm2.so. The CA keys are stored as PKCS8 files when
+	// it is empty
+	Module     string `yaml:"module" mapstructure:"module"`
+	TokenLabel string `yaml:"token-label" mapstructure:"token-label"`
+	// PinFile holds the user PIN of the token, the PIN is taken from CMS_CA_PKCS11_PIN instead when it is set
+	PinFile string `yaml:"pin-file" mapstructure:"pin-file"`
+	// Pin is only taken from the environment during setup and written to PinFile, it is not saved in config.yml
+	Pin string `yaml:"-" mapstructure:"-"`
+}
+
+type RevocationConfig struct {
+	// BaseURL is the externally reachable CMS API url used for the CRL distribution point and OCSP responder
+	// embedded in issued certificates. Revocation information is not embedded if it is empty.
+	BaseURL          string `yaml:"base-url" mapstructure:"base-url"`
+	CrlValidityHours int    `yaml:"crl-validity-hours" mapstructure:"crl-validity-hours"`
+}
+
+type AcmeConfig struct {
+	// Enabled turns on the ACME (RFC 8555) server endpoints under /acme
+	Enabled            bool `yaml:"enabled" mapstructure:"enabled"`
+	EabKeyValidityMins int  `yaml:"eab-key-validity-mins" mapstructure:"eab-key-validity-mins"`
+	OrderValidityMins  int  `yaml:"order-validity-mins" mapstructure:"order-validity-mins"`
 }
 
 // this function sets the configuration file name and type
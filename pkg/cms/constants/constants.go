@@ -31,6 +31,12 @@ const (
 	TLSCertPath                    = ConfigDir + "tls-cert.pem"
 	TLSKeyPath                     = ConfigDir + "tls.key"
 	SerialNumberPath               = ConfigDir + "serial-number"
+	RevokedCertsDir                = ConfigDir + "revoked-certificates/"
+	CrlDirPath                     = ConfigDir + "crl/"
+	IssuedCertsDir                 = ConfigDir + "issued-certificates/"
+	AcmeDirPath                    = ConfigDir + "acme/"
+	DefaultCaPkcs11PinFile         = ConfigDir + "ca-pkcs11-pin"
+	CaPkcs11PinEnv                 = "CMS_CA_PKCS11_PIN"
 	ServiceRemoveCmd               = "systemctl disable cms"
 	DefaultRootCACommonName        = "CMSCA"
 	DefaultPort                    = 8445
@@ -47,12 +53,31 @@ const (
 	DefaultTlsSan                  = "127.0.0.1,localhost"
 	DefaultTokenDurationMins       = 240
 	DefaultJwtValidateCacheKeyMins = 60
+	DefaultJwksTimeout             = 10 * time.Second
 	DefaultReadTimeout             = 30 * time.Second
 	DefaultReadHeaderTimeout       = 10 * time.Second
 	DefaultWriteTimeout            = 10 * time.Second
 	DefaultIdleTimeout             = 10 * time.Second
 	DefaultMaxHeaderBytes          = 1 << 20
 	DefaultLogEntryMaxlength       = 300
+	DefaultCrlValidityHours        = 24
+	HTTPMediaTypePkixCrl           = "application/pkix-crl"
+	HTTPMediaTypeOcspRequest       = "application/ocsp-request"
+	HTTPMediaTypeOcspResponse      = "application/ocsp-response"
+	HTTPMediaTypeJoseJson          = "application/jose+json"
+	HTTPMediaTypeProblemJson       = "application/problem+json"
+	HTTPMediaTypePemCertChain      = "application/pem-certificate-chain"
+	DefaultAcmeEabKeyValidityMins  = 60
+	DefaultAcmeOrderValidityMins   = 24 * 60
+	AcmeNonceValidity              = 10 * time.Minute
+)
+
+// the token revocation list of AAS is retrieved again once cached for DefaultRevocationListCacheTime, requests are
+// refused when it could not be retrieved for DefaultRevocationListMaxStaleness
+const (
+	DefaultRevocationListCacheTime    = time.Minute
+	DefaultRevocationListMaxStaleness = 10 * time.Minute
+	DefaultRevocationListTimeout      = 10 * time.Second
 )
 
 type CaAttrib struct {
@@ -75,6 +100,17 @@ var mp = map[string]CaAttrib{
 	Signing:   {"CMS Signing CA", IntermediateCADirPath + "signing-ca.pem", IntermediateCADirPath + "signing-ca.key"},
 }
 
+// revocationReasons maps the supported revocation reasons to the CRLReason codes defined in RFC 5280
+var revocationReasons = map[string]int{
+	"unspecified":          0,
+	"keyCompromise":        1,
+	"cACompromise":         2,
+	"affiliationChanged":   3,
+	"superseded":           4,
+	"cessationOfOperation": 5,
+	"privilegeWithdrawn":   9,
+}
+
 func GetIntermediateCAs() []string {
 	log.Trace("constants/constants:GetIntermediateCAs() Entering")
 	defer log.Trace("constants/constants:GetIntermediateCAs() Leaving")
@@ -91,3 +127,20 @@ func GetCaAttribs(t string) CaAttrib {
 	}
 	return CaAttrib{}
 }
+
+// GetIssuingCAs returns all the CAs that sign certificates or CRLs, including the root CA
+func GetIssuingCAs() []string {
+	log.Trace("constants/constants:GetIssuingCAs() Entering")
+	defer log.Trace("constants/constants:GetIssuingCAs() Leaving")
+
+	return append([]string{Root}, GetIntermediateCAs()...)
+}
+
+// GetRevocationReasonCode returns the RFC 5280 CRLReason code for the provided revocation reason
+func GetRevocationReasonCode(reason string) (int, bool) {
+	log.Trace("constants/constants:GetRevocationReasonCode() Entering")
+	defer log.Trace("constants/constants:GetRevocationReasonCode() Leaving")
+
+	code, found := revocationReasons[reason]
+	return code, found
+}
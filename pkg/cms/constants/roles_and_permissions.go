@@ -0,0 +1,17 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package constants
+
+// Roles and permissions
+const (
+	Administrator = "*:*:*"
+
+	RevocationCreate   = "revocations:create"
+	RevocationRetrieve = "revocations:retrieve"
+	RevocationSearch   = "revocations:search"
+
+	CertificateRetrieve = "certificates:retrieve"
+	CertificateSearch   = "certificates:search"
+)
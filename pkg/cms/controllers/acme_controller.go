@@ -0,0 +1,805 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/config"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/utils"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/validation"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/auth"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/context"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
+	ct "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+)
+
+// maxAcmeRequestBytes limits the size of the JWS accepted by the ACME endpoints
+const maxAcmeRequestBytes = 64 * 1024
+
+// AcmeController implements an ACME (RFC 8555) server for issuing TLS certificates. Accounts can only be registered
+// with an external account binding key, which is handed out to callers holding CMS CertApprover roles. The account
+// inherits these roles, and they decide the identifiers and CSRs the account is authorized for.
+type AcmeController struct {
+	Config    *config.Configuration
+	Store     domain.AcmeStore
+	CertStore domain.CertificateStore
+	nonces    *nonceStore
+}
+
+func NewAcmeController(cfg *config.Configuration, store domain.AcmeStore, certStore domain.CertificateStore) *AcmeController {
+	return &AcmeController{
+		Config:    cfg,
+		Store:     store,
+		CertStore: certStore,
+		nonces:    &nonceStore{nonces: make(map[string]time.Time)},
+	}
+}
+
+// nonceStore keeps the replay nonces handed out to ACME clients until they are used or expire
+type nonceStore struct {
+	mutex  sync.Mutex
+	nonces map[string]time.Time
+}
+
+func (ns *nonceStore) create() (string, error) {
+	nonceBytes := make([]byte, 16)
+	_, err := rand.Read(nonceBytes)
+	if err != nil {
+		return "", err
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(nonceBytes)
+
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	now := time.Now()
+	for n, expiry := range ns.nonces {
+		if now.After(expiry) {
+			delete(ns.nonces, n)
+		}
+	}
+	ns.nonces[nonce] = now.Add(consts.AcmeNonceValidity)
+	return nonce, nil
+}
+
+func (ns *nonceStore) consume(nonce string) bool {
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	expiry, ok := ns.nonces[nonce]
+	if !ok {
+		return false
+	}
+	delete(ns.nonces, nonce)
+	return time.Now().Before(expiry)
+}
+
+//SetResponseHeaders adds a fresh replay nonce and the directory link to the response of an ACME endpoint
+func (controller *AcmeController) SetResponseHeaders(httpWriter http.ResponseWriter, httpRequest *http.Request) error {
+	nonce, err := controller.nonces.create()
+	if err != nil {
+		return err
+	}
+	httpWriter.Header().Set("Replay-Nonce", nonce)
+	httpWriter.Header().Set("Cache-Control", "no-store")
+	httpWriter.Header().Add("Link", "<"+acmeBaseUrl(httpRequest)+"/directory>;rel=\"index\"")
+	return nil
+}
+
+//Directory is used to get the ACME directory object listing the ACME endpoints
+func (controller *AcmeController) Directory(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/acme:Directory() Entering")
+	defer log.Trace("resource/acme:Directory() Leaving")
+
+	baseUrl := acmeBaseUrl(httpRequest)
+	return cms.AcmeDirectory{
+		NewNonce:   baseUrl + "/new-nonce",
+		NewAccount: baseUrl + "/new-account",
+		NewOrder:   baseUrl + "/new-order",
+		Meta:       cms.AcmeDirectoryMeta{ExternalAccountRequired: true},
+	}, http.StatusOK, nil
+}
+
+//NewNonce is used to get a replay nonce, which is returned in the Replay-Nonce header
+func (controller *AcmeController) NewNonce(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/acme:NewNonce() Entering")
+	defer log.Trace("resource/acme:NewNonce() Leaving")
+
+	if httpRequest.Method == http.MethodHead {
+		return nil, http.StatusOK, nil
+	}
+	return nil, http.StatusNoContent, nil
+}
+
+//CreateEabKey is used to get an external account binding key for registering an ACME account. The account gets the
+//CertApprover roles of the token used for this request
+func (controller *AcmeController) CreateEabKey(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/acme:CreateEabKey() Entering")
+	defer log.Trace("resource/acme:CreateEabKey() Leaving")
+
+	privileges, err := context.GetUserRoles(httpRequest)
+	if err != nil {
+		slog.WithError(err).Warn("resource/acme:CreateEabKey() Failed to read roles and permissions")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Could not get user roles from http context"}
+	}
+
+	ctxMap, foundRole := auth.ValidatePermissionAndGetRoleContext(privileges,
+		[]ct.RoleInfo{{Service: consts.ServiceName, Name: consts.CertApproverGroupName}},
+		false)
+	if !foundRole || len(*ctxMap) == 0 {
+		slog.Warningf("resource/acme:CreateEabKey() %s: No CertApprover role with context in token", commLogMsg.UnauthorizedAccess)
+		return nil, http.StatusUnauthorized, &commErr.PrivilegeError{Message: "Insufficient privileges to access " + httpRequest.RequestURI, StatusCode: http.StatusUnauthorized}
+	}
+
+	hmacKey := make([]byte, 32)
+	_, err = rand.Read(hmacKey)
+	if err != nil {
+		log.WithError(err).Error("resource/acme:CreateEabKey() Failed to generate EAB key")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to create EAB key"}
+	}
+	requestedBy, err := context.GetTokenSubject(httpRequest)
+	if err != nil {
+		log.WithError(err).Warn("resource/acme:CreateEabKey() Could not get token subject from http context")
+	}
+
+	eabKey := models.EabKey{
+		KeyId:       uuid.New().String(),
+		HmacKey:     hmacKey,
+		RequestedBy: requestedBy,
+		Expires:     time.Now().UTC().Add(time.Duration(controller.eabKeyValidityMins()) * time.Minute),
+	}
+	for _, role := range *ctxMap {
+		eabKey.Roles = append(eabKey.Roles, role)
+	}
+	_, err = controller.Store.CreateEabKey(&eabKey)
+	if err != nil {
+		log.WithError(err).Error("resource/acme:CreateEabKey() EAB key create failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to create EAB key"}
+	}
+
+	slog.WithField("KeyId", eabKey.KeyId).Infof("resource/acme:CreateEabKey() %s: EAB key created by: %s", commLogMsg.PrivilegeModified, httpRequest.RemoteAddr)
+	return cms.EabKey{
+		KeyId:   eabKey.KeyId,
+		HmacKey: base64.RawURLEncoding.EncodeToString(hmacKey),
+		Expires: eabKey.Expires,
+	}, http.StatusCreated, nil
+}
+
+//NewAccount is used to register an ACME account, or to look up the account of a key
+func (controller *AcmeController) NewAccount(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/acme:NewAccount() Entering")
+	defer log.Trace("resource/acme:NewAccount() Leaving")
+
+	jws, _, err := controller.verifyRequest(httpRequest, true)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var accountRequest cms.AcmeNewAccountRequest
+	err = json.Unmarshal(jws.Payload, &accountRequest)
+	if err != nil {
+		return nil, 0, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "Unable to decode new account request")
+	}
+
+	baseUrl := acmeBaseUrl(httpRequest)
+	accountId, err := utils.JwkThumbprint(jws.Header.Jwk)
+	if err != nil {
+		return nil, 0, acmeProblem(cms.AcmeErrorBadPublicKey, http.StatusBadRequest, "Invalid account key")
+	}
+	account, err := controller.Store.RetrieveAccount(accountId)
+	if err == nil {
+		httpWriter.Header().Set("Location", baseUrl+"/account/"+account.ID)
+		return acmeAccountResponse(baseUrl, account), http.StatusOK, nil
+	} else if err.Error() != commErr.RecordNotFound {
+		log.WithError(err).Error("resource/acme:NewAccount() ACME account retrieve failed")
+		return nil, 0, acmeProblem(cms.AcmeErrorServerInternal, http.StatusInternalServerError, "Failed to retrieve account")
+	}
+	if accountRequest.OnlyReturnExisting {
+		return nil, 0, acmeProblem(cms.AcmeErrorAccountDoesNotExist, http.StatusBadRequest, "No account exists for the provided key")
+	}
+	for _, contact := range accountRequest.Contact {
+		if !strings.HasPrefix(contact, "mailto:") {
+			return nil, 0, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "Only mailto contacts are supported")
+		}
+	}
+
+	eabKey, err := controller.verifyExternalAccountBinding(accountRequest.ExternalAccountBinding, jws, accountId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	account, err = controller.Store.CreateAccount(&models.AcmeAccount{
+		ID:          accountId,
+		Key:         jws.Header.Jwk,
+		Status:      cms.AcmeStatusValid,
+		Contact:     accountRequest.Contact,
+		Roles:       eabKey.Roles,
+		EabKeyId:    eabKey.KeyId,
+		RequestedBy: eabKey.RequestedBy,
+		CreatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		log.WithError(err).Error("resource/acme:NewAccount() ACME account create failed")
+		return nil, 0, acmeProblem(cms.AcmeErrorServerInternal, http.StatusInternalServerError, "Failed to create account")
+	}
+
+	slog.WithField("AccountId", accountId).Infof("resource/acme:NewAccount() %s: ACME account created with EAB key %s by: %s", commLogMsg.PrivilegeModified, eabKey.KeyId, httpRequest.RemoteAddr)
+	httpWriter.Header().Set("Location", baseUrl+"/account/"+account.ID)
+	return acmeAccountResponse(baseUrl, account), http.StatusCreated, nil
+}
+
+//Account is used to retrieve, update the contacts of or deactivate an ACME account
+func (controller *AcmeController) Account(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/acme:Account() Entering")
+	defer log.Trace("resource/acme:Account() Leaving")
+
+	jws, account, err := controller.verifyRequest(httpRequest, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	if mux.Vars(httpRequest)["id"] != account.ID {
+		return nil, 0, acmeProblem(cms.AcmeErrorUnauthorized, http.StatusForbidden, "Account does not belong to the requester")
+	}
+
+	// an empty payload is a POST-as-GET request
+	if len(jws.Payload) > 0 {
+		var updateRequest cms.AcmeAccountUpdateRequest
+		err = json.Unmarshal(jws.Payload, &updateRequest)
+		if err != nil {
+			return nil, 0, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "Unable to decode account update request")
+		}
+		if updateRequest.Status != "" {
+			if updateRequest.Status != cms.AcmeStatusDeactivated {
+				return nil, 0, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "Account status can only be changed to deactivated")
+			}
+			// the roles granted by the EAB key end with the account, the ready orders can no longer be finalized
+			account.Status = cms.AcmeStatusDeactivated
+			account.Roles = nil
+			err = controller.invalidateReadyOrders(account.ID)
+			if err != nil {
+				log.WithError(err).Error("resource/acme:Account() Failed to invalidate the orders of the ACME account")
+				return nil, 0, acmeProblem(cms.AcmeErrorServerInternal, http.StatusInternalServerError, "Failed to update account")
+			}
+		}
+		if updateRequest.Contact != nil {
+			for _, contact := range updateRequest.Contact {
+				if !strings.HasPrefix(contact, "mailto:") {
+					return nil, 0, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "Only mailto contacts are supported")
+				}
+			}
+			account.Contact = updateRequest.Contact
+		}
+		account, err = controller.Store.UpdateAccount(account)
+		if err != nil {
+			log.WithError(err).Error("resource/acme:Account() ACME account update failed")
+			return nil, 0, acmeProblem(cms.AcmeErrorServerInternal, http.StatusInternalServerError, "Failed to update account")
+		}
+		slog.WithField("AccountId", account.ID).Infof("resource/acme:Account() %s: ACME account updated by: %s", commLogMsg.PrivilegeModified, httpRequest.RemoteAddr)
+	}
+
+	return acmeAccountResponse(acmeBaseUrl(httpRequest), account), http.StatusOK, nil
+}
+
+//AccountOrders is used to list the orders of an ACME account
+func (controller *AcmeController) AccountOrders(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/acme:AccountOrders() Entering")
+	defer log.Trace("resource/acme:AccountOrders() Leaving")
+
+	_, account, err := controller.verifyRequest(httpRequest, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	if mux.Vars(httpRequest)["id"] != account.ID {
+		return nil, 0, acmeProblem(cms.AcmeErrorUnauthorized, http.StatusForbidden, "Account does not belong to the requester")
+	}
+
+	orders, err := controller.Store.SearchOrders(account.ID)
+	if err != nil {
+		log.WithError(err).Error("resource/acme:AccountOrders() ACME order search failed")
+		return nil, 0, acmeProblem(cms.AcmeErrorServerInternal, http.StatusInternalServerError, "Failed to search orders")
+	}
+	baseUrl := acmeBaseUrl(httpRequest)
+	orderList := cms.AcmeOrderList{Orders: []string{}}
+	for _, order := range orders {
+		orderList.Orders = append(orderList.Orders, baseUrl+"/order/"+order.ID)
+	}
+	return orderList, http.StatusOK, nil
+}
+
+//NewOrder is used to create an ACME order. The identifiers are authorized right away when they are part of the SAN
+//list of the CertApprover roles of the account, so the order is created in ready state
+func (controller *AcmeController) NewOrder(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/acme:NewOrder() Entering")
+	defer log.Trace("resource/acme:NewOrder() Leaving")
+
+	jws, account, err := controller.verifyRequest(httpRequest, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var orderRequest cms.AcmeNewOrderRequest
+	err = json.Unmarshal(jws.Payload, &orderRequest)
+	if err != nil {
+		return nil, 0, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "Unable to decode new order request")
+	}
+	if len(orderRequest.Identifiers) == 0 {
+		return nil, 0, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "At least one identifier must be provided")
+	}
+	if orderRequest.NotBefore != "" || orderRequest.NotAfter != "" {
+		return nil, 0, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "notBefore and notAfter are not supported")
+	}
+
+	ctxMap := roleContextMap(account.Roles)
+	for i, identifier := range orderRequest.Identifiers {
+		switch identifier.Type {
+		case cms.AcmeIdentifierDns:
+			if identifier.Value == "" || net.ParseIP(identifier.Value) != nil {
+				return nil, 0, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "Invalid dns identifier: "+identifier.Value)
+			}
+			orderRequest.Identifiers[i].Value = strings.ToLower(identifier.Value)
+		case cms.AcmeIdentifierIp:
+			ip := net.ParseIP(identifier.Value)
+			if ip == nil {
+				return nil, 0, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "Invalid ip identifier: "+identifier.Value)
+			}
+			orderRequest.Identifiers[i].Value = ip.String()
+		default:
+			return nil, 0, acmeProblem(cms.AcmeErrorUnsupportedIdentifier, http.StatusBadRequest, "Unsupported identifier type: "+identifier.Type)
+		}
+		if !validation.IsSanAuthorized(&ctxMap, orderRequest.Identifiers[i].Value) {
+			slog.WithField("AccountId", account.ID).Warningf("resource/acme:NewOrder() %s: Identifier %s is not authorized for the account", commLogMsg.UnauthorizedAccess, identifier.Value)
+			return nil, 0, acmeProblem(cms.AcmeErrorRejectedIdentifier, http.StatusBadRequest, "Identifier is not authorized for the account: "+identifier.Value)
+		}
+	}
+
+	now := time.Now().UTC()
+	order, err := controller.Store.CreateOrder(&models.AcmeOrder{
+		ID:          uuid.New().String(),
+		AccountID:   account.ID,
+		Status:      cms.AcmeStatusReady,
+		Identifiers: orderRequest.Identifiers,
+		CreatedAt:   now,
+		Expires:     now.Add(time.Duration(controller.orderValidityMins()) * time.Minute),
+	})
+	if err != nil {
+		log.WithError(err).Error("resource/acme:NewOrder() ACME order create failed")
+		return nil, 0, acmeProblem(cms.AcmeErrorServerInternal, http.StatusInternalServerError, "Failed to create order")
+	}
+
+	baseUrl := acmeBaseUrl(httpRequest)
+	httpWriter.Header().Set("Location", baseUrl+"/order/"+order.ID)
+	return acmeOrderResponse(baseUrl, order), http.StatusCreated, nil
+}
+
+//Order is used to get the status of an ACME order
+func (controller *AcmeController) Order(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/acme:Order() Entering")
+	defer log.Trace("resource/acme:Order() Leaving")
+
+	_, account, err := controller.verifyRequest(httpRequest, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	order, err := controller.retrieveOrder(mux.Vars(httpRequest)["id"], account)
+	if err != nil {
+		return nil, 0, err
+	}
+	return acmeOrderResponse(acmeBaseUrl(httpRequest), order), http.StatusOK, nil
+}
+
+//Authorization is used to get an authorization of an ACME order
+func (controller *AcmeController) Authorization(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/acme:Authorization() Entering")
+	defer log.Trace("resource/acme:Authorization() Leaving")
+
+	_, account, err := controller.verifyRequest(httpRequest, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	order, err := controller.retrieveOrder(mux.Vars(httpRequest)["id"], account)
+	if err != nil {
+		return nil, 0, err
+	}
+	index, err := strconv.Atoi(mux.Vars(httpRequest)["index"])
+	if err != nil || index >= len(order.Identifiers) {
+		return nil, 0, acmeProblem(cms.AcmeErrorMalformed, http.StatusNotFound, "Authorization does not exist")
+	}
+	return acmeAuthorizationResponse(acmeBaseUrl(httpRequest), order, index), http.StatusOK, nil
+}
+
+//Challenge is used to get or respond to a challenge of an authorization. The challenges are validated when the order
+//is created, so responding to a challenge does not change its state
+func (controller *AcmeController) Challenge(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/acme:Challenge() Entering")
+	defer log.Trace("resource/acme:Challenge() Leaving")
+
+	_, account, err := controller.verifyRequest(httpRequest, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	order, err := controller.retrieveOrder(mux.Vars(httpRequest)["id"], account)
+	if err != nil {
+		return nil, 0, err
+	}
+	index, err := strconv.Atoi(mux.Vars(httpRequest)["index"])
+	if err != nil || index >= len(order.Identifiers) {
+		return nil, 0, acmeProblem(cms.AcmeErrorMalformed, http.StatusNotFound, "Challenge does not exist")
+	}
+	baseUrl := acmeBaseUrl(httpRequest)
+	httpWriter.Header().Add("Link", "<"+baseUrl+"/authz/"+order.ID+"/"+strconv.Itoa(index)+">;rel=\"up\"")
+	return acmeAuthorizationResponse(baseUrl, order, index).Challenges[0], http.StatusOK, nil
+}
+
+//Finalize is used to submit the CSR of a ready ACME order. The CSR goes through the same validation as the CSRs
+//submitted to the certificates endpoint, using the roles of the account
+func (controller *AcmeController) Finalize(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/acme:Finalize() Entering")
+	defer log.Trace("resource/acme:Finalize() Leaving")
+
+	jws, account, err := controller.verifyRequest(httpRequest, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	order, err := controller.retrieveOrder(mux.Vars(httpRequest)["id"], account)
+	if err != nil {
+		return nil, 0, err
+	}
+	if orderStatus(order) != cms.AcmeStatusReady {
+		return nil, 0, acmeProblem(cms.AcmeErrorOrderNotReady, http.StatusForbidden, "Order is not ready for finalization")
+	}
+
+	var finalizeRequest cms.AcmeFinalizeRequest
+	err = json.Unmarshal(jws.Payload, &finalizeRequest)
+	if err != nil {
+		return nil, 0, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "Unable to decode finalize request")
+	}
+	csrBytes, err := base64.RawURLEncoding.DecodeString(finalizeRequest.Csr)
+	if err != nil {
+		return nil, 0, acmeProblem(cms.AcmeErrorBadCsr, http.StatusBadRequest, "Unable to decode CSR")
+	}
+	csr, err := x509.ParseCertificateRequest(csrBytes)
+	if err != nil || csr.CheckSignature() != nil {
+		slog.Warning(commLogMsg.InvalidInputBadParam)
+		return nil, 0, acmeProblem(cms.AcmeErrorBadCsr, http.StatusBadRequest, "Invalid CSR provided")
+	}
+	if !csrMatchesIdentifiers(csr, order.Identifiers) {
+		return nil, 0, acmeProblem(cms.AcmeErrorBadCsr, http.StatusBadRequest, "SAN list in CSR does not match the order identifiers")
+	}
+	ctxMap := roleContextMap(account.Roles)
+	err = validation.ValidateCertificateRequest(controller.Config, csr, consts.Tls, &ctxMap)
+	if err != nil {
+		slog.WithError(err).Warningf("resource/acme:Finalize() %s: CSR is not authorized for the account", commLogMsg.InvalidInputBadParam)
+		return nil, 0, acmeProblem(cms.AcmeErrorBadCsr, http.StatusBadRequest, "Invalid CSR provided")
+	}
+
+	// only one of concurrent finalize requests can move the order to processing and get the certificate issued
+	updated, err := controller.Store.UpdateOrderStatus(order.ID, cms.AcmeStatusReady, cms.AcmeStatusProcessing)
+	if err != nil {
+		log.WithError(err).Error("resource/acme:Finalize() ACME order update failed")
+		return nil, 0, acmeProblem(cms.AcmeErrorServerInternal, http.StatusInternalServerError, "Failed to update order")
+	}
+	if !updated {
+		return nil, 0, acmeProblem(cms.AcmeErrorOrderNotReady, http.StatusForbidden, "Order is not ready for finalization")
+	}
+
+	certificate, caCert, err := issueCertificate(controller.Config, controller.CertStore, csr, consts.Tls, account.RequestedBy)
+	if err != nil {
+		order.Status = cms.AcmeStatusInvalid
+		order.Error = acmeProblem(cms.AcmeErrorServerInternal, http.StatusInternalServerError, "Failed to issue certificate")
+		if _, uerr := controller.Store.UpdateOrder(order); uerr != nil {
+			log.WithError(uerr).Error("resource/acme:Finalize() ACME order update failed")
+		}
+		return nil, 0, order.Error
+	}
+	issuedCert, err := x509.ParseCertificate(certificate)
+	if err != nil {
+		log.WithError(err).Error("resource/acme:Finalize() Failed to parse issued certificate")
+		return nil, 0, acmeProblem(cms.AcmeErrorServerInternal, http.StatusInternalServerError, "Failed to issue certificate")
+	}
+
+	order.Status = cms.AcmeStatusValid
+	order.CertSerialNumber = issuedCert.SerialNumber.Text(16)
+	order.CertificateChain = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})) +
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}))
+	order, err = controller.Store.UpdateOrder(order)
+	if err != nil {
+		log.WithError(err).Error("resource/acme:Finalize() ACME order update failed")
+		return nil, 0, acmeProblem(cms.AcmeErrorServerInternal, http.StatusInternalServerError, "Failed to update order")
+	}
+
+	log.Infof("resource/acme:Finalize() Issued certificate %s for ACME order %s", order.CertSerialNumber, order.ID)
+	baseUrl := acmeBaseUrl(httpRequest)
+	httpWriter.Header().Set("Location", baseUrl+"/order/"+order.ID)
+	return acmeOrderResponse(baseUrl, order), http.StatusOK, nil
+}
+
+//Certificate is used to download the certificate chain of a valid ACME order
+func (controller *AcmeController) Certificate(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/acme:Certificate() Entering")
+	defer log.Trace("resource/acme:Certificate() Leaving")
+
+	_, account, err := controller.verifyRequest(httpRequest, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	order, err := controller.retrieveOrder(mux.Vars(httpRequest)["id"], account)
+	if err != nil {
+		return nil, 0, err
+	}
+	if order.Status != cms.AcmeStatusValid {
+		return nil, 0, acmeProblem(cms.AcmeErrorMalformed, http.StatusNotFound, "Certificate does not exist")
+	}
+	httpWriter.Header().Set("Content-Type", consts.HTTPMediaTypePemCertChain)
+	return []byte(order.CertificateChain), http.StatusOK, nil
+}
+
+// verifyRequest authenticates a JWS request to an ACME endpoint. Requests to register an account are signed by the
+// key embedded in the request, all other requests are signed by the key of an existing account.
+func (controller *AcmeController) verifyRequest(httpRequest *http.Request, newAccount bool) (*utils.Jws, *models.AcmeAccount, error) {
+	if httpRequest.Header.Get("Content-Type") != consts.HTTPMediaTypeJoseJson {
+		return nil, nil, acmeProblem(cms.AcmeErrorMalformed, http.StatusUnsupportedMediaType, "Invalid Content-Type")
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(httpRequest.Body, maxAcmeRequestBytes))
+	if err != nil {
+		return nil, nil, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "Unable to read request body")
+	}
+	jws, err := utils.ParseJws(body)
+	if err != nil {
+		slog.WithError(err).Warn(commLogMsg.InvalidInputBadEncoding)
+		return nil, nil, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "Unable to decode JWS")
+	}
+	if !controller.nonces.consume(jws.Header.Nonce) {
+		return nil, nil, acmeProblem(cms.AcmeErrorBadNonce, http.StatusBadRequest, "Invalid or expired nonce")
+	}
+	if jws.Header.Url != "https://"+httpRequest.Host+httpRequest.URL.Path {
+		return nil, nil, acmeProblem(cms.AcmeErrorUnauthorized, http.StatusUnauthorized, "JWS url does not match the request url")
+	}
+
+	var account *models.AcmeAccount
+	var keyData []byte
+	if newAccount {
+		if len(jws.Header.Jwk) == 0 || jws.Header.Kid != "" {
+			return nil, nil, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "New account requests must be signed with jwk")
+		}
+		keyData = jws.Header.Jwk
+	} else {
+		accountPrefix := acmeBaseUrl(httpRequest) + "/account/"
+		if len(jws.Header.Jwk) != 0 || !strings.HasPrefix(jws.Header.Kid, accountPrefix) {
+			return nil, nil, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "Requests must be signed with the kid of an account")
+		}
+		account, err = controller.Store.RetrieveAccount(strings.TrimPrefix(jws.Header.Kid, accountPrefix))
+		if err != nil {
+			if err.Error() == commErr.RecordNotFound {
+				return nil, nil, acmeProblem(cms.AcmeErrorAccountDoesNotExist, http.StatusBadRequest, "Account does not exist")
+			}
+			log.WithError(err).Error("resource/acme:verifyRequest() ACME account retrieve failed")
+			return nil, nil, acmeProblem(cms.AcmeErrorServerInternal, http.StatusInternalServerError, "Failed to retrieve account")
+		}
+		if account.Status != cms.AcmeStatusValid {
+			return nil, nil, acmeProblem(cms.AcmeErrorUnauthorized, http.StatusUnauthorized, "Account is "+account.Status)
+		}
+		keyData = account.Key
+	}
+
+	publicKey, err := utils.ParseJwk(keyData)
+	if err != nil {
+		return nil, nil, acmeProblem(cms.AcmeErrorBadPublicKey, http.StatusBadRequest, "Invalid account key")
+	}
+	err = jws.Verify(publicKey)
+	if err != nil {
+		slog.WithError(err).Warnf("resource/acme:verifyRequest() %s: JWS verification failed for request from %s", commLogMsg.AuthenticationFailed, httpRequest.RemoteAddr)
+		return nil, nil, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "JWS verification failed")
+	}
+	return jws, account, nil
+}
+
+// verifyExternalAccountBinding checks that the binding is a MAC of the account key made with a valid EAB key and
+// consumes the EAB key (RFC 8555 section 7.3.4)
+func (controller *AcmeController) verifyExternalAccountBinding(binding json.RawMessage, jws *utils.Jws, accountId string) (*models.EabKey, error) {
+	if len(binding) == 0 {
+		return nil, acmeProblem(cms.AcmeErrorExternalAccountRequired, http.StatusBadRequest, "External account binding is required")
+	}
+	eabJws, err := utils.ParseJws(binding)
+	if err != nil {
+		return nil, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "Unable to decode external account binding")
+	}
+	if eabJws.Header.Kid == "" || eabJws.Header.Nonce != "" || eabJws.Header.Url != jws.Header.Url {
+		return nil, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "Invalid external account binding header")
+	}
+	boundAccountId, err := utils.JwkThumbprint(eabJws.Payload)
+	if err != nil || boundAccountId != accountId {
+		return nil, acmeProblem(cms.AcmeErrorMalformed, http.StatusBadRequest, "External account binding does not match the account key")
+	}
+
+	eabKey, err := controller.Store.RetrieveEabKey(eabJws.Header.Kid)
+	if err != nil {
+		if err.Error() == commErr.RecordNotFound {
+			slog.Warningf("resource/acme:verifyExternalAccountBinding() %s: Unknown EAB key %s", commLogMsg.AuthenticationFailed, eabJws.Header.Kid)
+			return nil, acmeProblem(cms.AcmeErrorUnauthorized, http.StatusUnauthorized, "Invalid external account binding")
+		}
+		log.WithError(err).Error("resource/acme:verifyExternalAccountBinding() EAB key retrieve failed")
+		return nil, acmeProblem(cms.AcmeErrorServerInternal, http.StatusInternalServerError, "Failed to verify external account binding")
+	}
+	err = eabJws.VerifyHmac(eabKey.HmacKey)
+	if err != nil || time.Now().After(eabKey.Expires) {
+		slog.WithError(err).Warningf("resource/acme:verifyExternalAccountBinding() %s: Invalid or expired EAB key %s", commLogMsg.AuthenticationFailed, eabKey.KeyId)
+		return nil, acmeProblem(cms.AcmeErrorUnauthorized, http.StatusUnauthorized, "Invalid external account binding")
+	}
+
+	// EAB keys are single use. Deleting the key before creating the account makes sure that concurrent requests can
+	// not register more than one account with it
+	err = controller.Store.DeleteEabKey(eabKey.KeyId)
+	if err != nil {
+		if err.Error() == commErr.RecordNotFound {
+			return nil, acmeProblem(cms.AcmeErrorUnauthorized, http.StatusUnauthorized, "Invalid external account binding")
+		}
+		log.WithError(err).Error("resource/acme:verifyExternalAccountBinding() EAB key delete failed")
+		return nil, acmeProblem(cms.AcmeErrorServerInternal, http.StatusInternalServerError, "Failed to verify external account binding")
+	}
+	return eabKey, nil
+}
+
+// invalidateReadyOrders makes the orders of the account that are waiting for finalization invalid
+func (controller *AcmeController) invalidateReadyOrders(accountId string) error {
+	orders, err := controller.Store.SearchOrders(accountId)
+	if err != nil {
+		return err
+	}
+	for _, order := range orders {
+		if order.Status != cms.AcmeStatusReady {
+			continue
+		}
+		_, err = controller.Store.UpdateOrderStatus(order.ID, cms.AcmeStatusReady, cms.AcmeStatusInvalid)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (controller *AcmeController) retrieveOrder(orderId string, account *models.AcmeAccount) (*models.AcmeOrder, error) {
+	order, err := controller.Store.RetrieveOrder(orderId)
+	if err != nil {
+		if err.Error() == commErr.RecordNotFound {
+			return nil, acmeProblem(cms.AcmeErrorMalformed, http.StatusNotFound, "Order does not exist")
+		}
+		log.WithError(err).Error("resource/acme:retrieveOrder() ACME order retrieve failed")
+		return nil, acmeProblem(cms.AcmeErrorServerInternal, http.StatusInternalServerError, "Failed to retrieve order")
+	}
+	if order.AccountID != account.ID {
+		return nil, acmeProblem(cms.AcmeErrorUnauthorized, http.StatusForbidden, "Order does not belong to the requester")
+	}
+	return order, nil
+}
+
+func (controller *AcmeController) eabKeyValidityMins() int {
+	if controller.Config.Acme.EabKeyValidityMins <= 0 {
+		return consts.DefaultAcmeEabKeyValidityMins
+	}
+	return controller.Config.Acme.EabKeyValidityMins
+}
+
+func (controller *AcmeController) orderValidityMins() int {
+	if controller.Config.Acme.OrderValidityMins <= 0 {
+		return consts.DefaultAcmeOrderValidityMins
+	}
+	return controller.Config.Acme.OrderValidityMins
+}
+
+func acmeProblem(errorType string, status int, detail string) *cms.AcmeProblem {
+	return &cms.AcmeProblem{Type: errorType, Detail: detail, Status: status}
+}
+
+func acmeBaseUrl(httpRequest *http.Request) string {
+	return "https://" + httpRequest.Host + "/" + strings.ToLower(consts.ServiceName) + consts.ApiVersion + "/acme"
+}
+
+func acmeAccountResponse(baseUrl string, account *models.AcmeAccount) cms.AcmeAccount {
+	return cms.AcmeAccount{
+		Status:  account.Status,
+		Contact: account.Contact,
+		Orders:  baseUrl + "/account/" + account.ID + "/orders",
+	}
+}
+
+func acmeOrderResponse(baseUrl string, order *models.AcmeOrder) cms.AcmeOrder {
+	acmeOrder := cms.AcmeOrder{
+		Status:      orderStatus(order),
+		Expires:     order.Expires,
+		Identifiers: order.Identifiers,
+		Finalize:    baseUrl + "/order/" + order.ID + "/finalize",
+		Error:       order.Error,
+	}
+	for i := range order.Identifiers {
+		acmeOrder.Authorizations = append(acmeOrder.Authorizations, baseUrl+"/authz/"+order.ID+"/"+strconv.Itoa(i))
+	}
+	if order.Status == cms.AcmeStatusValid {
+		acmeOrder.Certificate = baseUrl + "/cert/" + order.ID
+	}
+	return acmeOrder
+}
+
+func acmeAuthorizationResponse(baseUrl string, order *models.AcmeOrder, index int) cms.AcmeAuthorization {
+	status := cms.AcmeStatusValid
+	if orderStatus(order) == cms.AcmeStatusInvalid && order.Status != cms.AcmeStatusInvalid {
+		status = "expired"
+	}
+	token := sha256.Sum256([]byte(order.ID + "/" + strconv.Itoa(index)))
+	validated := order.CreatedAt
+	return cms.AcmeAuthorization{
+		Identifier: order.Identifiers[index],
+		Status:     status,
+		Expires:    order.Expires,
+		Challenges: []cms.AcmeChallenge{{
+			Type:      cms.AcmeChallengeAasRole,
+			Url:       baseUrl + "/challenge/" + order.ID + "/" + strconv.Itoa(index),
+			Status:    cms.AcmeStatusValid,
+			Token:     base64.RawURLEncoding.EncodeToString(token[:]),
+			Validated: &validated,
+		}},
+	}
+}
+
+// orderStatus returns the status of the order, taking into account that orders which were not finalized in time
+// become invalid
+func orderStatus(order *models.AcmeOrder) string {
+	if order.Status == cms.AcmeStatusReady && time.Now().After(order.Expires) {
+		return cms.AcmeStatusInvalid
+	}
+	return order.Status
+}
+
+func roleContextMap(roles []ct.RoleInfo) map[string]ct.RoleInfo {
+	ctxMap := make(map[string]ct.RoleInfo)
+	for _, role := range roles {
+		ctxMap[strings.TrimSpace(role.Context)] = role
+	}
+	return ctxMap
+}
+
+// csrMatchesIdentifiers checks that the SAN list of the CSR contains exactly the identifiers of the order
+func csrMatchesIdentifiers(csr *x509.CertificateRequest, identifiers []cms.AcmeIdentifier) bool {
+	var csrSans, orderSans []string
+	for _, dnsName := range csr.DNSNames {
+		csrSans = append(csrSans, cms.AcmeIdentifierDns+":"+strings.ToLower(dnsName))
+	}
+	for _, ip := range csr.IPAddresses {
+		csrSans = append(csrSans, cms.AcmeIdentifierIp+":"+ip.String())
+	}
+	for _, identifier := range identifiers {
+		orderSans = append(orderSans, identifier.Type+":"+identifier.Value)
+	}
+	if len(csrSans) != len(orderSans) {
+		return false
+	}
+	sort.Strings(csrSans)
+	sort.Strings(orderSans)
+	for i := range csrSans {
+		if csrSans[i] != orderSans[i] {
+			return false
+		}
+	}
+	return true
+}
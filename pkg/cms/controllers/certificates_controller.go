@@ -9,24 +9,31 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"github.com/gorilla/mux"
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/config"
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/domain/models"
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/utils"
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/validation"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/auth"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/context"
-	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
 	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
 	v "github.com/intel-secl/intel-secl/v3/pkg/lib/common/validation"
 	ct "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+	"github.com/pkg/errors"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
 
 type CertificatesController struct {
 	Config *config.Configuration
+	Store  domain.CertificateStore
 }
 
 //GetCertificates is used to get the JWT Signing/TLS certificate upon JWT validation
@@ -157,17 +164,65 @@ func (controller CertificatesController) GetCertificates(httpWriter http.Respons
 	}
 	log.Debug("resource/certificates:GetCertificates() Received valid CSR")
 
-	serialNumber, err := utils.GetNextSerialNumber()
+	requestedBy, err := context.GetTokenSubject(httpRequest)
 	if err != nil {
-		log.WithError(err).Error("resource/certificates:GetCertificates() Failed to read next Serial Number")
-		httpWriter.WriteHeader(http.StatusInternalServerError)
-		_, err = httpWriter.Write([]byte("Failed to read next Serial Number"))
+		log.WithError(err).Warn("resource/certificates:GetCertificates() Could not get token subject from http context")
+	}
+	certificate, caCert, err := issueCertificate(controller.Config, controller.Store, clientCSR, certType, requestedBy)
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := "Cannot create certificate"
+		if handledErr, ok := err.(*commErr.HandledError); ok {
+			status = handledErr.StatusCode
+			message = handledErr.Message
+		}
+		httpWriter.WriteHeader(status)
+		_, err = httpWriter.Write([]byte(message))
 		if err != nil {
 			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
 		}
 		return
 	}
 
+	httpWriter.Header().Add("Content-Type", "application/x-pem-file")
+	httpWriter.WriteHeader(http.StatusOK)
+	// encode the certificate first
+	err = pem.Encode(httpWriter, &pem.Block{Type: "CERTIFICATE", Bytes: certificate})
+	if err != nil {
+		log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to encode certificate")
+		httpWriter.WriteHeader(http.StatusInternalServerError)
+		_, err = httpWriter.Write([]byte("Cannot encode issued certificate"))
+		if err != nil {
+			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
+		}
+	}
+	// include the issuing CA as well since clients would need the entire chain minus the root.
+	err = pem.Encode(httpWriter, &pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})
+	if err != nil {
+		log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to encode certificate")
+		httpWriter.WriteHeader(http.StatusInternalServerError)
+		_, err = httpWriter.Write([]byte("Cannot encode Issuing CA"))
+		if err != nil {
+			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
+		}
+	}
+	log.Infof("resource/certificates:GetCertificates() Issued certificate for requested CSR with CN - %v", clientCSR.Subject.String())
+	return
+}
+
+// issueCertificate signs a validated CSR with the CA matching the requested cert type and records the certificate in
+// the inventory. It returns the DER encoded certificate along with the issuing CA certificate
+func issueCertificate(cfg *config.Configuration, store domain.CertificateStore, clientCSR *x509.CertificateRequest,
+	certType, requestedBy string) ([]byte, *x509.Certificate, error) {
+	log.Trace("resource/certificates:issueCertificate() Entering")
+	defer log.Trace("resource/certificates:issueCertificate() Leaving")
+
+	serialNumber, err := utils.GetNextSerialNumber()
+	if err != nil {
+		log.WithError(err).Error("resource/certificates:issueCertificate() Failed to read next Serial Number")
+		return nil, nil, &commErr.HandledError{StatusCode: http.StatusInternalServerError, Message: "Failed to read next Serial Number"}
+	}
+
 	clientCRTTemplate := x509.Certificate{
 		Signature:          clientCSR.Signature,
 		SignatureAlgorithm: clientCSR.SignatureAlgorithm,
@@ -187,7 +242,7 @@ func (controller CertificatesController) GetCertificates(httpWriter http.Respons
 	// in the CSR and that the the CN is not in the form of a domain name/ IP address
 
 	var issuingCa string
-	log.Debugf("resource/certificates:GetCertificates() Processing CSR with cert type - %v", certType)
+	log.Debugf("resource/certificates:issueCertificate() Processing CSR with cert type - %v", certType)
 	if strings.EqualFold(certType, "TLS") {
 		issuingCa = constants.Tls
 		clientCRTTemplate.DNSNames = clientCSR.DNSNames
@@ -204,58 +259,134 @@ func (controller CertificatesController) GetCertificates(httpWriter http.Respons
 		clientCRTTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
 
 	} else {
-		log.Errorf("Invalid certType provided")
-		httpWriter.WriteHeader(http.StatusBadRequest)
-		_, err = httpWriter.Write([]byte("Invalid certType provided"))
-		if err != nil {
-			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
-		}
-		return
+		log.Errorf("resource/certificates:issueCertificate() Invalid certType provided")
+		return nil, nil, &commErr.HandledError{StatusCode: http.StatusBadRequest, Message: "Invalid certType provided"}
 	}
-	caAttr := constants.GetCaAttribs(issuingCa)
-
-	caCert, caPrivKey, err := crypt.LoadX509CertAndPrivateKey(caAttr.CertPath, caAttr.KeyPath)
+	if cfg.Revocation.BaseURL != "" {
+		revocationBaseUrl := strings.TrimSuffix(cfg.Revocation.BaseURL, "/")
+		clientCRTTemplate.CRLDistributionPoints = []string{revocationBaseUrl + "/crl?issuingCa=" + url.QueryEscape(issuingCa)}
+		clientCRTTemplate.OCSPServer = []string{revocationBaseUrl + "/ocsp"}
+	}
+	caCert, caPrivKey, err := utils.LoadCaSigner(&cfg.CACert, issuingCa)
 	if err != nil {
-		log.WithError(err).Error("resource/certificates:GetCertificates() Could not load Issuing CA")
-		httpWriter.WriteHeader(http.StatusInternalServerError)
-		_, err = httpWriter.Write([]byte("Cannot load Issuing CA"))
-		if err != nil {
-			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
-		}
+		log.WithError(err).Error("resource/certificates:issueCertificate() Could not load Issuing CA")
+		return nil, nil, &commErr.HandledError{StatusCode: http.StatusInternalServerError, Message: "Cannot load Issuing CA"}
 	}
 
 	certificate, err := x509.CreateCertificate(rand.Reader, &clientCRTTemplate, caCert, clientCSR.PublicKey, caPrivKey)
 	if err != nil {
-		log.WithError(err).Error("resource/certificates:GetCertificates() Cannot create certificate from CSR")
-		httpWriter.WriteHeader(http.StatusInternalServerError)
-		_, err = httpWriter.Write([]byte("Cannot create certificate"))
-		if err != nil {
-			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
-		}
+		log.WithError(err).Error("resource/certificates:issueCertificate() Cannot create certificate from CSR")
+		return nil, nil, &commErr.HandledError{StatusCode: http.StatusInternalServerError, Message: "Cannot create certificate"}
 	}
 
-	httpWriter.Header().Add("Content-Type", "application/x-pem-file")
-	httpWriter.WriteHeader(http.StatusOK)
-	// encode the certificate first
-	err = pem.Encode(httpWriter, &pem.Block{Type: "CERTIFICATE", Bytes: certificate})
+	// record the certificate in the inventory before handing it out so that every issued certificate can be
+	// accounted for
+	issuedCert := cms.IssuedCertificate{
+		SerialNumber: serialNumber.Text(16),
+		Subject:      clientCRTTemplate.Subject.CommonName,
+		CertType:     certType,
+		IssuingCa:    issuingCa,
+		RequestedBy:  requestedBy,
+		NotBefore:    clientCRTTemplate.NotBefore.UTC(),
+		NotAfter:     clientCRTTemplate.NotAfter.UTC(),
+		Certificate:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})),
+	}
+	issuedCert.SanList = append(issuedCert.SanList, clientCRTTemplate.DNSNames...)
+	for _, ip := range clientCRTTemplate.IPAddresses {
+		issuedCert.SanList = append(issuedCert.SanList, ip.String())
+	}
+	_, err = store.Create(&issuedCert)
 	if err != nil {
-		log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to encode certificate")
-		httpWriter.WriteHeader(http.StatusInternalServerError)
-		_, err = httpWriter.Write([]byte("Cannot encode issued certificate"))
-		if err != nil {
-			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
+		log.WithError(err).Error("resource/certificates:issueCertificate() Failed to record issued certificate")
+		return nil, nil, &commErr.HandledError{StatusCode: http.StatusInternalServerError, Message: "Cannot record issued certificate"}
+	}
+
+	return certificate, caCert, nil
+}
+
+//Retrieve is used to get the details of a certificate issued by CMS
+func (controller CertificatesController) Retrieve(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/certificates:Retrieve() Entering")
+	defer log.Trace("resource/certificates:Retrieve() Leaving")
+
+	serialNumber := mux.Vars(httpRequest)["serialNumber"]
+	issuedCert, err := controller.Store.Retrieve(serialNumber)
+	if err != nil {
+		if err.Error() == commErr.RecordNotFound {
+			log.Errorf("resource/certificates:Retrieve() Certificate with specified serial number could not be located")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Certificate with specified serial number does not exist"}
 		}
+		log.WithError(err).Error("resource/certificates:Retrieve() Issued certificate retrieve failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve issued certificate"}
 	}
-	// include the issuing CA as well since clients would need the entire chain minus the root.
-	err = pem.Encode(httpWriter, &pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})
+
+	slog.WithField("SerialNumber", serialNumber).Infof("resource/certificates:Retrieve() %s: Issued certificate retrieved by: %s", commLogMsg.AuthorizedAccess, httpRequest.RemoteAddr)
+	return issuedCert, http.StatusOK, nil
+}
+
+//Search is used to list the certificates issued by CMS
+func (controller CertificatesController) Search(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/certificates:Search() Entering")
+	defer log.Trace("resource/certificates:Search() Leaving")
+
+	criteria, err := getCertificateFilterCriteria(httpRequest)
 	if err != nil {
-		log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to encode certificate")
-		httpWriter.WriteHeader(http.StatusInternalServerError)
-		_, err = httpWriter.Write([]byte("Cannot encode Issuing CA"))
+		slog.WithError(err).Errorf("resource/certificates:Search() %s : Invalid filter criteria", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	issuedCerts, err := controller.Store.Search(criteria)
+	if err != nil {
+		log.WithError(err).Error("resource/certificates:Search() Issued certificate search failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to search issued certificates"}
+	}
+
+	slog.Infof("resource/certificates:Search() %s: Issued certificates searched by: %s", commLogMsg.AuthorizedAccess, httpRequest.RemoteAddr)
+	return issuedCerts, http.StatusOK, nil
+}
+
+func getCertificateFilterCriteria(httpRequest *http.Request) (*models.CertificateFilterCriteria, error) {
+	log.Trace("resource/certificates:getCertificateFilterCriteria() Entering")
+	defer log.Trace("resource/certificates:getCertificateFilterCriteria() Leaving")
+
+	criteria := models.CertificateFilterCriteria{}
+	params := httpRequest.URL.Query()
+
+	if certType := strings.TrimSpace(params.Get("certType")); certType != "" {
+		if err := v.ValidateStrings([]string{certType}); err != nil {
+			return nil, errors.New("Invalid certType query parameter value")
+		}
+		criteria.CertType = certType
+	}
+
+	if subjectEqualTo := strings.TrimSpace(params.Get("subjectEqualTo")); subjectEqualTo != "" {
+		if err := v.ValidateStrings([]string{subjectEqualTo}); err != nil {
+			return nil, errors.New("Invalid subjectEqualTo query parameter value")
+		}
+		criteria.SubjectEqualTo = subjectEqualTo
+	}
+
+	if subjectContains := strings.TrimSpace(params.Get("subjectContains")); subjectContains != "" {
+		if err := v.ValidateStrings([]string{subjectContains}); err != nil {
+			return nil, errors.New("Invalid subjectContains query parameter value")
+		}
+		criteria.SubjectContains = subjectContains
+	}
+
+	if issuingCa := strings.TrimSpace(params.Get("issuingCa")); issuingCa != "" {
+		if constants.GetCaAttribs(issuingCa).CommonName == "" {
+			return nil, errors.New("Invalid issuingCa query parameter value")
+		}
+		criteria.IssuingCa = issuingCa
+	}
+
+	if expiringBefore := strings.TrimSpace(params.Get("expiringBefore")); expiringBefore != "" {
+		parsedTime, err := time.Parse(time.RFC3339, expiringBefore)
 		if err != nil {
-			log.WithError(err).Errorf("resource/certificates:GetCertificates() Failed to write response")
+			return nil, errors.New("Invalid expiringBefore query parameter value, must be in RFC3339 format")
 		}
+		criteria.ExpiringBefore = parsedTime
 	}
-	log.Infof("resource/certificates:GetCertificates() Issued certificate for requested CSR with CN - %v", clientCSR.Subject.String())
-	return
+
+	return &criteria, nil
 }
@@ -0,0 +1,344 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/config"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/utils"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/constants"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ocsp"
+)
+
+type RevocationsController struct {
+	Config *config.Configuration
+	Store  domain.RevocationStore
+	// CertStore is the inventory of the issued certificates, the issuing CA of a revoked certificate is taken from it
+	CertStore domain.CertificateStore
+}
+
+func NewRevocationsController(cfg *config.Configuration, store domain.RevocationStore, certStore domain.CertificateStore) *RevocationsController {
+	return &RevocationsController{
+		Config:    cfg,
+		Store:     store,
+		CertStore: certStore,
+	}
+}
+
+//Create is used to revoke a certificate issued by CMS and republish the CRL of the issuing CA
+func (controller RevocationsController) Create(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/revocations:Create() Entering")
+	defer log.Trace("resource/revocations:Create() Leaving")
+
+	if httpRequest.Header.Get("Content-Type") != constants.HTTPMediaTypeJson {
+		return nil, http.StatusUnsupportedMediaType, &commErr.ResourceError{Message: "Invalid Content-Type"}
+	}
+
+	if httpRequest.ContentLength == 0 {
+		slog.Error("resource/revocations:Create() The request body was not provided")
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body was not provided"}
+	}
+
+	var revocationRequest cms.RevocationRequest
+	dec := json.NewDecoder(httpRequest.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&revocationRequest)
+	if err != nil {
+		slog.WithError(err).Errorf("resource/revocations:Create() %s : Failed to decode request body as RevocationRequest", commLogMsg.InvalidInputBadEncoding)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Unable to decode JSON request body"}
+	}
+
+	serialNumber, err := validateRevocationRequest(&revocationRequest)
+	if err != nil {
+		slog.WithError(err).Errorf("resource/revocations:Create() %s : Invalid revocation request", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	// the certificate is revoked on the CRL of the CA that issued it, whatever the request says
+	issuedCert, err := controller.CertStore.Retrieve(serialNumber)
+	if err == nil {
+		if revocationRequest.IssuingCa != "" && revocationRequest.IssuingCa != issuedCert.IssuingCa {
+			slog.Errorf("resource/revocations:Create() %s : Certificate with serial number %s was issued by %s, not %s", commLogMsg.InvalidInputBadParam, serialNumber, issuedCert.IssuingCa, revocationRequest.IssuingCa)
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Certificate with specified serial_number was not issued by the specified issuing_ca"}
+		}
+		revocationRequest.IssuingCa = issuedCert.IssuingCa
+	} else if err.Error() != commErr.RecordNotFound {
+		log.WithError(err).Error("resource/revocations:Create() Issued certificate retrieve failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to revoke certificate"}
+	} else if revocationRequest.IssuingCa == "" {
+		// the CA certificates issued by root and the certificates issued before the inventory are not listed
+		slog.Errorf("resource/revocations:Create() %s : Certificate with serial number %s is not in the inventory and no issuing_ca was provided", commLogMsg.InvalidInputBadParam, serialNumber)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "issuing_ca must be provided for a certificate that is not in the issued certificate inventory"}
+	}
+
+	_, err = controller.Store.Retrieve(serialNumber)
+	if err == nil {
+		log.Errorf("resource/revocations:Create() Certificate with serial number %s is already revoked", serialNumber)
+		return nil, http.StatusConflict, &commErr.ResourceError{Message: "Certificate with specified serial number is already revoked"}
+	} else if err.Error() != commErr.RecordNotFound {
+		log.WithError(err).Error("resource/revocations:Create() Revoked certificate retrieve failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to revoke certificate"}
+	}
+
+	revokedCert, err := controller.Store.Create(&cms.RevokedCertificate{
+		SerialNumber: serialNumber,
+		IssuingCa:    revocationRequest.IssuingCa,
+		Reason:       revocationRequest.Reason,
+	})
+	if err != nil {
+		log.WithError(err).Error("resource/revocations:Create() Revoked certificate create failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to revoke certificate"}
+	}
+
+	// republish the CRL right away so that relying parties see the revocation on their next download
+	_, err = controller.publishCrl(revocationRequest.IssuingCa)
+	if err != nil {
+		log.WithError(err).Errorf("resource/revocations:Create() Failed to publish CRL for %s, it will be published on next download", revocationRequest.IssuingCa)
+	}
+
+	slog.WithField("SerialNumber", serialNumber).Infof("resource/revocations:Create() %s: Certificate revoked by: %s", commLogMsg.PrivilegeModified, httpRequest.RemoteAddr)
+	return revokedCert, http.StatusCreated, nil
+}
+
+//Retrieve is used to get the revocation details of a certificate
+func (controller RevocationsController) Retrieve(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/revocations:Retrieve() Entering")
+	defer log.Trace("resource/revocations:Retrieve() Leaving")
+
+	serialNumber := mux.Vars(httpRequest)["serialNumber"]
+	revokedCert, err := controller.Store.Retrieve(serialNumber)
+	if err != nil {
+		if err.Error() == commErr.RecordNotFound {
+			log.Errorf("resource/revocations:Retrieve() Revoked certificate with specified serial number could not be located")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Revoked certificate with specified serial number does not exist"}
+		}
+		log.WithError(err).Error("resource/revocations:Retrieve() Revoked certificate retrieve failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve revoked certificate"}
+	}
+
+	slog.WithField("SerialNumber", serialNumber).Infof("resource/revocations:Retrieve() %s: Revoked certificate retrieved by: %s", commLogMsg.AuthorizedAccess, httpRequest.RemoteAddr)
+	return revokedCert, http.StatusOK, nil
+}
+
+//Search is used to list the revoked certificates
+func (controller RevocationsController) Search(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/revocations:Search() Entering")
+	defer log.Trace("resource/revocations:Search() Leaving")
+
+	criteria, err := getRevocationFilterCriteria(httpRequest)
+	if err != nil {
+		slog.WithError(err).Errorf("resource/revocations:Search() %s : Invalid filter criteria", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	revokedCerts, err := controller.Store.Search(criteria)
+	if err != nil {
+		log.WithError(err).Error("resource/revocations:Search() Revoked certificate search failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to search revoked certificates"}
+	}
+
+	slog.Infof("resource/revocations:Search() %s: Revoked certificates searched by: %s", commLogMsg.AuthorizedAccess, httpRequest.RemoteAddr)
+	return revokedCerts, http.StatusOK, nil
+}
+
+//GetCrl is used to download the DER encoded CRL of an issuing CA
+func (controller RevocationsController) GetCrl(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/revocations:GetCrl() Entering")
+	defer log.Trace("resource/revocations:GetCrl() Leaving")
+
+	issuingCa := httpRequest.URL.Query().Get("issuingCa")
+	if issuingCa == "" {
+		issuingCa = consts.Root
+	}
+	if consts.GetCaAttribs(issuingCa).CommonName == "" {
+		slog.Warning(commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid Query parameter provided"}
+	}
+
+	crlBytes, crl, err := utils.LoadCrl(issuingCa)
+	if err != nil || time.Until(crl.NextUpdate) < controller.crlValidity()/2 {
+		log.Debugf("resource/revocations:GetCrl() Publishing new CRL for %s", issuingCa)
+		crlBytes, err = controller.publishCrl(issuingCa)
+		if err != nil {
+			log.WithError(err).Errorf("resource/revocations:GetCrl() Failed to publish CRL for %s", issuingCa)
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to publish CRL"}
+		}
+	}
+
+	httpWriter.Header().Set("Content-Type", consts.HTTPMediaTypePkixCrl)
+	log.Infof("resource/revocations:GetCrl() Returned CRL for %s", issuingCa)
+	return crlBytes, http.StatusOK, nil
+}
+
+//Ocsp is used to answer OCSP requests (RFC 6960) for certificates issued by CMS. Requests can be POSTed or sent
+//base64 encoded in the path of a GET request
+func (controller RevocationsController) Ocsp(httpWriter http.ResponseWriter, httpRequest *http.Request) (interface{}, int, error) {
+	log.Trace("resource/revocations:Ocsp() Entering")
+	defer log.Trace("resource/revocations:Ocsp() Leaving")
+
+	var requestBytes []byte
+	var err error
+	if httpRequest.Method == http.MethodGet {
+		requestBytes, err = base64.StdEncoding.DecodeString(mux.Vars(httpRequest)["ocspRequest"])
+	} else {
+		if httpRequest.Header.Get("Content-Type") != consts.HTTPMediaTypeOcspRequest {
+			return nil, http.StatusUnsupportedMediaType, &commErr.ResourceError{Message: "Invalid Content-Type"}
+		}
+		requestBytes, err = ioutil.ReadAll(httpRequest.Body)
+	}
+	if err != nil {
+		slog.WithError(err).Errorf("resource/revocations:Ocsp() %s : Could not read OCSP request", commLogMsg.InvalidInputBadEncoding)
+		return ocsp.MalformedRequestErrorResponse, http.StatusOK, nil
+	}
+
+	httpWriter.Header().Set("Content-Type", consts.HTTPMediaTypeOcspResponse)
+	ocspRequest, err := ocsp.ParseRequest(requestBytes)
+	if err != nil {
+		slog.WithError(err).Errorf("resource/revocations:Ocsp() %s : Could not parse OCSP request", commLogMsg.InvalidInputBadEncoding)
+		return ocsp.MalformedRequestErrorResponse, http.StatusOK, nil
+	}
+
+	issuingCa, err := utils.GetOcspIssuingCa(ocspRequest.IssuerKeyHash, ocspRequest.HashAlgorithm)
+	if err != nil {
+		log.WithError(err).Error("resource/revocations:Ocsp() Could not find issuing CA for OCSP request")
+		return ocsp.UnauthorizedErrorResponse, http.StatusOK, nil
+	}
+	caCert, caSigner, err := utils.LoadCaSigner(controller.caConfig(), issuingCa)
+	if err != nil {
+		log.WithError(err).Errorf("resource/revocations:Ocsp() Could not load issuing CA %s", issuingCa)
+		return ocsp.InternalErrorErrorResponse, http.StatusOK, nil
+	}
+
+	now := time.Now()
+	responseTemplate := ocsp.Response{
+		SerialNumber: ocspRequest.SerialNumber,
+		ThisUpdate:   now,
+		NextUpdate:   now.Add(controller.crlValidity()),
+		Status:       ocsp.Good,
+	}
+
+	revokedCert, err := controller.Store.Retrieve(ocspRequest.SerialNumber.Text(16))
+	if err == nil && revokedCert.IssuingCa == issuingCa {
+		responseTemplate.Status = ocsp.Revoked
+		responseTemplate.RevokedAt = revokedCert.RevokedAt
+		responseTemplate.RevocationReason, _ = consts.GetRevocationReasonCode(revokedCert.Reason)
+	} else if err != nil && err.Error() != commErr.RecordNotFound {
+		log.WithError(err).Error("resource/revocations:Ocsp() Revoked certificate retrieve failed")
+		return ocsp.TryLaterErrorResponse, http.StatusOK, nil
+	} else if lastSerialNumber, err := utils.ReadSerialNumber(); err != nil || ocspRequest.SerialNumber.Cmp(lastSerialNumber) > 0 {
+		// CMS has not issued a certificate with this serial number yet
+		responseTemplate.Status = ocsp.Unknown
+	}
+
+	response, err := ocsp.CreateResponse(caCert, caCert, responseTemplate, caSigner)
+	if err != nil {
+		log.WithError(err).Error("resource/revocations:Ocsp() Could not create OCSP response")
+		return ocsp.InternalErrorErrorResponse, http.StatusOK, nil
+	}
+
+	log.Infof("resource/revocations:Ocsp() Returned OCSP response for certificate with serial number %s", ocspRequest.SerialNumber.Text(16))
+	return response, http.StatusOK, nil
+}
+
+func (controller RevocationsController) publishCrl(issuingCa string) ([]byte, error) {
+	log.Trace("resource/revocations:publishCrl() Entering")
+	defer log.Trace("resource/revocations:publishCrl() Leaving")
+
+	revokedCerts, err := controller.Store.Search(&models.RevocationFilterCriteria{
+		IssuingCa: issuingCa,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "resource/revocations:publishCrl() Revoked certificate search failed")
+	}
+	return utils.CreateCrl(controller.caConfig(), issuingCa, revokedCerts, controller.crlValidity())
+}
+
+func (controller RevocationsController) crlValidity() time.Duration {
+	if controller.Config == nil || controller.Config.Revocation.CrlValidityHours <= 0 {
+		return consts.DefaultCrlValidityHours * time.Hour
+	}
+	return time.Duration(controller.Config.Revocation.CrlValidityHours) * time.Hour
+}
+
+func (controller RevocationsController) caConfig() *config.CACertConfig {
+	if controller.Config == nil {
+		return nil
+	}
+	return &controller.Config.CACert
+}
+
+func validateRevocationRequest(revocationRequest *cms.RevocationRequest) (string, error) {
+	log.Trace("resource/revocations:validateRevocationRequest() Entering")
+	defer log.Trace("resource/revocations:validateRevocationRequest() Leaving")
+
+	serialNumber, ok := new(big.Int).SetString(strings.TrimPrefix(strings.ToLower(revocationRequest.SerialNumber), "0x"), 16)
+	if !ok || serialNumber.Sign() <= 0 {
+		return "", errors.New("Invalid serial_number provided, it must be hex encoded")
+	}
+	lastSerialNumber, err := utils.ReadSerialNumber()
+	if err != nil || serialNumber.Cmp(lastSerialNumber) > 0 {
+		return "", errors.New("Certificate with specified serial_number was not issued by CMS")
+	}
+
+	if revocationRequest.IssuingCa != "" && consts.GetCaAttribs(revocationRequest.IssuingCa).CommonName == "" {
+		return "", errors.New("Invalid issuing_ca provided")
+	}
+
+	if revocationRequest.Reason == "" {
+		revocationRequest.Reason = "unspecified"
+	}
+	if _, found := consts.GetRevocationReasonCode(revocationRequest.Reason); !found {
+		return "", errors.New("Invalid reason provided")
+	}
+	return serialNumber.Text(16), nil
+}
+
+func getRevocationFilterCriteria(httpRequest *http.Request) (*models.RevocationFilterCriteria, error) {
+	log.Trace("resource/revocations:getRevocationFilterCriteria() Entering")
+	defer log.Trace("resource/revocations:getRevocationFilterCriteria() Leaving")
+
+	criteria := models.RevocationFilterCriteria{}
+	params := httpRequest.URL.Query()
+
+	if issuingCa := strings.TrimSpace(params.Get("issuingCa")); issuingCa != "" {
+		if consts.GetCaAttribs(issuingCa).CommonName == "" {
+			return nil, errors.New("Invalid issuingCa query parameter value")
+		}
+		criteria.IssuingCa = issuingCa
+	}
+
+	if revokedBefore := strings.TrimSpace(params.Get("revokedBefore")); revokedBefore != "" {
+		parsedTime, err := time.Parse(time.RFC3339, revokedBefore)
+		if err != nil {
+			return nil, errors.New("Invalid revokedBefore query parameter value, must be in RFC3339 format")
+		}
+		criteria.RevokedBefore = parsedTime
+	}
+
+	if revokedAfter := strings.TrimSpace(params.Get("revokedAfter")); revokedAfter != "" {
+		parsedTime, err := time.Parse(time.RFC3339, revokedAfter)
+		if err != nil {
+			return nil, errors.New("Invalid revokedAfter query parameter value, must be in RFC3339 format")
+		}
+		criteria.RevokedAfter = parsedTime
+	}
+
+	return &criteria, nil
+}
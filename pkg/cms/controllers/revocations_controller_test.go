@@ -0,0 +1,260 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/directory"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/utils"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ocsp"
+)
+
+// setupRevocationTest creates a CA for every issuing CA of CMS and the serial number file, it returns the controller
+// with an empty revocation store, an inventory listing the certificates 0x11 and 0x1f issued by the TLS CA and the
+// certificates and keys of the CAs
+func setupRevocationTest(t *testing.T) (*RevocationsController, map[string]*x509.Certificate, map[string]*ecdsa.PrivateKey, func()) {
+	assertions := assert.New(t)
+
+	for _, dir := range []string{consts.ConfigDir, consts.RootCADirPath, consts.IntermediateCADirPath, consts.CrlDirPath} {
+		assertions.NoError(os.MkdirAll(dir, os.ModePerm))
+	}
+	caCerts := map[string]*x509.Certificate{}
+	caKeys := map[string]*ecdsa.PrivateKey{}
+	for i, issuingCa := range consts.GetIssuingCAs() {
+		caAttr := consts.GetCaAttribs(issuingCa)
+		caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assertions.NoError(err)
+		caTemplate := &x509.Certificate{
+			SerialNumber:          big.NewInt(int64(i + 1)),
+			Subject:               pkix.Name{CommonName: caAttr.CommonName},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}
+		caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+		assertions.NoError(err)
+		caCerts[issuingCa], err = x509.ParseCertificate(caDer)
+		assertions.NoError(err)
+		caKeys[issuingCa] = caKey
+
+		keyDer, err := x509.MarshalPKCS8PrivateKey(caKey)
+		assertions.NoError(err)
+		assertions.NoError(ioutil.WriteFile(caAttr.CertPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDer}), 0600))
+		assertions.NoError(ioutil.WriteFile(caAttr.KeyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}), 0600))
+	}
+	// the certificates up to serial number 0x20 were issued by CMS
+	assertions.NoError(utils.WriteSerialNumber(big.NewInt(0x20)))
+
+	storeDir, err := ioutil.TempDir("", "revoked-certificates")
+	assertions.NoError(err)
+	inventoryDir, err := ioutil.TempDir("", "issued-certificates")
+	assertions.NoError(err)
+	certStore := directory.NewCertificateStore(inventoryDir)
+	for _, serialNumber := range []string{"11", "1f"} {
+		_, err = certStore.Create(&cms.IssuedCertificate{SerialNumber: serialNumber, Subject: "CN=leaf", IssuingCa: consts.Tls})
+		assertions.NoError(err)
+	}
+	cleanup := func() {
+		os.RemoveAll(storeDir)
+		os.RemoveAll(inventoryDir)
+		for _, issuingCa := range consts.GetIssuingCAs() {
+			os.Remove(consts.CrlDirPath + issuingCa + ".crl")
+		}
+	}
+	return NewRevocationsController(nil, directory.NewRevocationStore(storeDir), certStore), caCerts, caKeys, cleanup
+}
+
+func revoke(controller *RevocationsController, body string) (interface{}, int, error) {
+	request := httptest.NewRequest(http.MethodPost, "/revocations", strings.NewReader(body))
+	request.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
+	return controller.Create(httptest.NewRecorder(), request)
+}
+
+func TestRevokeCertificate(t *testing.T) {
+	assertions := assert.New(t)
+	controller, _, _, cleanup := setupRevocationTest(t)
+	defer cleanup()
+
+	response, status, err := revoke(controller, `{"serial_number": "0x11", "issuing_ca": "TLS", "reason": "keyCompromise"}`)
+	assertions.NoError(err)
+	assertions.Equal(http.StatusCreated, status)
+	revokedCert := response.(*cms.RevokedCertificate)
+	assertions.Equal("11", revokedCert.SerialNumber)
+	assertions.Equal("keyCompromise", revokedCert.Reason)
+
+	request := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/revocations/11", nil), map[string]string{"serialNumber": "11"})
+	_, status, err = controller.Retrieve(httptest.NewRecorder(), request)
+	assertions.NoError(err)
+	assertions.Equal(http.StatusOK, status)
+
+	_, status, _ = revoke(controller, `{"serial_number": "11", "issuing_ca": "TLS"}`)
+	assertions.Equal(http.StatusConflict, status, "a certificate should not be revoked twice")
+
+	_, status, _ = revoke(controller, `{"serial_number": "21", "issuing_ca": "TLS"}`)
+	assertions.Equal(http.StatusBadRequest, status, "a certificate not issued by CMS should not be revoked")
+
+	_, status, _ = revoke(controller, `{"serial_number": "12", "issuing_ca": "TLS", "reason": "removeFromCRL"}`)
+	assertions.Equal(http.StatusBadRequest, status)
+
+	_, status, _ = revoke(controller, `{"serial_number": "12", "issuing_ca": "unknown"}`)
+	assertions.Equal(http.StatusBadRequest, status)
+
+	request = mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/revocations/12", nil), map[string]string{"serialNumber": "12"})
+	_, status, _ = controller.Retrieve(httptest.NewRecorder(), request)
+	assertions.Equal(http.StatusNotFound, status)
+}
+
+func TestRevokeCertificateIssuingCa(t *testing.T) {
+	assertions := assert.New(t)
+	controller, _, _, cleanup := setupRevocationTest(t)
+	defer cleanup()
+
+	_, status, _ := revoke(controller, `{"serial_number": "11", "issuing_ca": "Signing"}`)
+	assertions.Equal(http.StatusBadRequest, status, "the issuing_ca should match the inventory")
+
+	_, status, err := revoke(controller, `{"serial_number": "11", "issuing_ca": "TLS"}`)
+	assertions.NoError(err)
+	assertions.Equal(http.StatusCreated, status, "the rejected request should not have revoked the certificate")
+
+	// the issuing CA of an inventoried certificate is taken from the inventory
+	_, status, err = revoke(controller, `{"serial_number": "1f"}`)
+	assertions.NoError(err)
+	assertions.Equal(http.StatusCreated, status)
+	revokedCert, err := controller.Store.Retrieve("1f")
+	assertions.NoError(err)
+	assertions.Equal(consts.Tls, revokedCert.IssuingCa)
+
+	_, status, _ = revoke(controller, `{"serial_number": "12"}`)
+	assertions.Equal(http.StatusBadRequest, status, "the issuing_ca is required for a certificate missing from the inventory")
+
+	_, status, err = revoke(controller, `{"serial_number": "12", "issuing_ca": "Signing"}`)
+	assertions.NoError(err)
+	assertions.Equal(http.StatusCreated, status)
+}
+
+func TestGetCrl(t *testing.T) {
+	assertions := assert.New(t)
+	controller, caCerts, _, cleanup := setupRevocationTest(t)
+	defer cleanup()
+
+	getCrl := func(issuingCa string) *x509.RevocationList {
+		request := httptest.NewRequest(http.MethodGet, "/crl?issuingCa="+issuingCa, nil)
+		response, status, err := controller.GetCrl(httptest.NewRecorder(), request)
+		assertions.NoError(err)
+		assertions.Equal(http.StatusOK, status)
+		crl, err := x509.ParseRevocationList(response.([]byte))
+		assertions.NoError(err)
+		assertions.NoError(crl.CheckSignatureFrom(caCerts[issuingCa]), "the CRL should be signed by the issuing CA")
+		return crl
+	}
+
+	crl := getCrl(consts.Tls)
+	assertions.Empty(crl.RevokedCertificateEntries)
+	assertions.True(crl.NextUpdate.After(time.Now()))
+
+	_, status, err := revoke(controller, `{"serial_number": "1f", "issuing_ca": "TLS", "reason": "superseded"}`)
+	assertions.NoError(err)
+	assertions.Equal(http.StatusCreated, status)
+
+	// the revocation republishes the CRL with the next CRL number
+	crl = getCrl(consts.Tls)
+	assertions.Equal(1, len(crl.RevokedCertificateEntries))
+	assertions.Equal(int64(0x1f), crl.RevokedCertificateEntries[0].SerialNumber.Int64())
+	assertions.Equal(4, crl.RevokedCertificateEntries[0].ReasonCode)
+	assertions.Equal(int64(2), crl.Number.Int64())
+
+	// the CRLs of the other CAs do not list the revocation
+	crl = getCrl(consts.Signing)
+	assertions.Empty(crl.RevokedCertificateEntries)
+
+	request := httptest.NewRequest(http.MethodGet, "/crl?issuingCa=unknown", nil)
+	_, status, _ = controller.GetCrl(httptest.NewRecorder(), request)
+	assertions.Equal(http.StatusBadRequest, status)
+}
+
+func TestOcsp(t *testing.T) {
+	assertions := assert.New(t)
+	controller, caCerts, caKeys, cleanup := setupRevocationTest(t)
+	defer cleanup()
+
+	issuer := caCerts[consts.Tls]
+	ocspRequest := func(serialNumber int64) []byte {
+		leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assertions.NoError(err)
+		leafTemplate := &x509.Certificate{
+			SerialNumber: big.NewInt(serialNumber),
+			Subject:      pkix.Name{CommonName: "leaf"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		leafDer, err := x509.CreateCertificate(rand.Reader, leafTemplate, issuer, &leafKey.PublicKey, caKeys[consts.Tls])
+		assertions.NoError(err)
+		leaf, err := x509.ParseCertificate(leafDer)
+		assertions.NoError(err)
+		requestBytes, err := ocsp.CreateRequest(leaf, issuer, nil)
+		assertions.NoError(err)
+		return requestBytes
+	}
+	postOcsp := func(requestBytes []byte) *ocsp.Response {
+		request := httptest.NewRequest(http.MethodPost, "/ocsp", bytes.NewReader(requestBytes))
+		request.Header.Set("Content-Type", consts.HTTPMediaTypeOcspRequest)
+		response, status, err := controller.Ocsp(httptest.NewRecorder(), request)
+		assertions.NoError(err)
+		assertions.Equal(http.StatusOK, status)
+		ocspResponse, err := ocsp.ParseResponse(response.([]byte), issuer)
+		assertions.NoError(err, "the OCSP response should be signed by the issuing CA")
+		return ocspResponse
+	}
+
+	_, status, err := revoke(controller, `{"serial_number": "11", "issuing_ca": "TLS", "reason": "keyCompromise"}`)
+	assertions.NoError(err)
+	assertions.Equal(http.StatusCreated, status)
+
+	assertions.Equal(ocsp.Good, postOcsp(ocspRequest(0x10)).Status)
+
+	revokedResponse := postOcsp(ocspRequest(0x11))
+	assertions.Equal(ocsp.Revoked, revokedResponse.Status)
+	assertions.Equal(ocsp.KeyCompromise, revokedResponse.RevocationReason)
+
+	assertions.Equal(ocsp.Unknown, postOcsp(ocspRequest(0x30)).Status, "CMS has not issued this serial number yet")
+
+	// the request can be sent base64 encoded in the path of a GET request
+	encodedRequest := base64.StdEncoding.EncodeToString(ocspRequest(0x11))
+	request := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/ocsp", nil), map[string]string{"ocspRequest": encodedRequest})
+	response, status, err := controller.Ocsp(httptest.NewRecorder(), request)
+	assertions.NoError(err)
+	assertions.Equal(http.StatusOK, status)
+	ocspResponse, err := ocsp.ParseResponse(response.([]byte), issuer)
+	assertions.NoError(err)
+	assertions.Equal(ocsp.Revoked, ocspResponse.Status)
+
+	request = httptest.NewRequest(http.MethodPost, "/ocsp", strings.NewReader("not an ocsp request"))
+	request.Header.Set("Content-Type", consts.HTTPMediaTypeOcspRequest)
+	response, _, _ = controller.Ocsp(httptest.NewRecorder(), request)
+	assertions.Equal(ocsp.MalformedRequestErrorResponse, response)
+}
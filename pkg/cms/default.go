@@ -10,6 +10,7 @@ import (
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/config"
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/constants"
 	commConfig "github.com/intel-secl/intel-secl/v3/pkg/lib/common/config"
+	commTls "github.com/intel-secl/intel-secl/v3/pkg/lib/common/tls"
 	"github.com/spf13/viper"
 )
 
@@ -19,6 +20,8 @@ func init() {
 	viper.SetDefault("tls-cert-file", constants.TLSCertPath)
 	viper.SetDefault("tls-key-file", constants.TLSKeyPath)
 	viper.SetDefault("san-list", constants.DefaultTlsSan)
+	viper.SetDefault("tls-renew-before", commTls.DefaultRenewBefore)
+	viper.SetDefault("tls-reload-interval", commTls.DefaultReloadInterval)
 
 	// set default values for log
 	viper.SetDefault("log-max-length", constants.DefaultLogEntryMaxlength)
@@ -38,12 +41,19 @@ func init() {
 	viper.SetDefault("cms-ca-locality", constants.DefaultLocality)
 	viper.SetDefault("cms-ca-province", constants.DefaultProvince)
 	viper.SetDefault("cms-ca-country", constants.DefaultCountry)
+	viper.SetDefault("cms-ca-pkcs11-pin-file", constants.DefaultCaPkcs11PinFile)
 
 	viper.SetDefault("aas-tls-cn", constants.DefaultAasTlsCn)
 	viper.SetDefault("aas-jwt-cn", constants.DefaultAasJwtCn)
 	viper.SetDefault("aas-tls-san", constants.DefaultTlsSan)
 
 	viper.SetDefault("token-duration-mins", constants.DefaultTokenDurationMins)
+
+	viper.SetDefault("revocation-crl-validity-hours", constants.DefaultCrlValidityHours)
+
+	viper.SetDefault("acme-enabled", false)
+	viper.SetDefault("acme-eab-key-validity-mins", constants.DefaultAcmeEabKeyValidityMins)
+	viper.SetDefault("acme-order-validity-mins", constants.DefaultAcmeOrderValidityMins)
 }
 
 func defaultConfig() *config.Configuration {
@@ -61,12 +71,29 @@ func defaultConfig() *config.Configuration {
 			Locality:     viper.GetString("cms-ca-locality"),
 			Province:     viper.GetString("cms-ca-province"),
 			Country:      viper.GetString("cms-ca-country"),
+			Pkcs11: config.Pkcs11Config{
+				Module:     viper.GetString("cms-ca-pkcs11-module"),
+				TokenLabel: viper.GetString("cms-ca-pkcs11-token-label"),
+				PinFile:    viper.GetString("cms-ca-pkcs11-pin-file"),
+				Pin:        viper.GetString("cms-ca-pkcs11-pin"),
+			},
 		},
 		AasJwtCn:          viper.GetString("aas-jwt-cn"),
 		AasTlsCn:          viper.GetString("aas-tls-cn"),
 		AasTlsSan:         viper.GetString("aas-tls-san"),
 		TlsSanList:        viper.GetString("san-list"),
+		TlsRenewBefore:    viper.GetDuration("tls-renew-before"),
+		TlsReloadInterval: viper.GetDuration("tls-reload-interval"),
 		TokenDurationMins: viper.GetInt("token-duration-mins"),
+		Revocation: config.RevocationConfig{
+			BaseURL:          viper.GetString("revocation-base-url"),
+			CrlValidityHours: viper.GetInt("revocation-crl-validity-hours"),
+		},
+		Acme: config.AcmeConfig{
+			Enabled:            viper.GetBool("acme-enabled"),
+			EabKeyValidityMins: viper.GetInt("acme-eab-key-validity-mins"),
+			OrderValidityMins:  viper.GetInt("acme-order-validity-mins"),
+		},
 	}
 }
 
@@ -80,6 +107,8 @@ func loadAlias() {
 		"server-max-header-bytes":    "CMS_SERVER_MAX_HEADER_BYTES",
 		"log-enable-stdout":          "CMS_ENABLE_CONSOLE_LOG",
 		"aas-base-url":               "AAS_API_URL",
+		"revocation-base-url":        "CMS_REVOCATION_BASE_URL",
+		"acme-enabled":               "CMS_ACME_ENABLED",
 	}
 	for k, v := range alias {
 		if env := os.Getenv(v); env != "" {
@@ -0,0 +1,238 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package directory
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/pkg/errors"
+)
+
+const (
+	eabKeysDir  = "eab-keys"
+	accountsDir = "accounts"
+	ordersDir   = "orders"
+)
+
+// AcmeStore keeps the ACME external account binding keys, accounts and orders in sub directories of dir
+type AcmeStore struct {
+	dir string
+	// orderLock serializes the status changes of the orders, the store is only used by a single CMS process
+	orderLock sync.Mutex
+}
+
+func NewAcmeStore(dir string) *AcmeStore {
+	return &AcmeStore{dir: dir}
+}
+
+func (as *AcmeStore) CreateEabKey(eabKey *models.EabKey) (*models.EabKey, error) {
+	defaultLog.Trace("directory/acme_store:CreateEabKey() Entering")
+	defer defaultLog.Trace("directory/acme_store:CreateEabKey() Leaving")
+
+	err := as.write(eabKeysDir, eabKey.KeyId, eabKey, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/acme_store:CreateEabKey() Error in saving EAB key")
+	}
+	return eabKey, nil
+}
+
+func (as *AcmeStore) RetrieveEabKey(keyId string) (*models.EabKey, error) {
+	defaultLog.Trace("directory/acme_store:RetrieveEabKey() Entering")
+	defer defaultLog.Trace("directory/acme_store:RetrieveEabKey() Leaving")
+
+	var eabKey models.EabKey
+	err := as.read(eabKeysDir, keyId, &eabKey)
+	if err != nil {
+		return nil, err
+	}
+	return &eabKey, nil
+}
+
+func (as *AcmeStore) DeleteEabKey(keyId string) error {
+	defaultLog.Trace("directory/acme_store:DeleteEabKey() Entering")
+	defer defaultLog.Trace("directory/acme_store:DeleteEabKey() Leaving")
+
+	if !isValidId(keyId) {
+		return errors.New(commErr.RecordNotFound)
+	}
+	err := os.Remove(filepath.Join(as.dir, eabKeysDir, keyId))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.New(commErr.RecordNotFound)
+		}
+		return errors.Wrapf(err, "directory/acme_store:DeleteEabKey() Unable to delete EAB key : %s", keyId)
+	}
+	return nil
+}
+
+func (as *AcmeStore) CreateAccount(account *models.AcmeAccount) (*models.AcmeAccount, error) {
+	defaultLog.Trace("directory/acme_store:CreateAccount() Entering")
+	defer defaultLog.Trace("directory/acme_store:CreateAccount() Leaving")
+
+	err := as.write(accountsDir, account.ID, account, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/acme_store:CreateAccount() Error in saving ACME account")
+	}
+	return account, nil
+}
+
+func (as *AcmeStore) RetrieveAccount(id string) (*models.AcmeAccount, error) {
+	defaultLog.Trace("directory/acme_store:RetrieveAccount() Entering")
+	defer defaultLog.Trace("directory/acme_store:RetrieveAccount() Leaving")
+
+	var account models.AcmeAccount
+	err := as.read(accountsDir, id, &account)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (as *AcmeStore) UpdateAccount(account *models.AcmeAccount) (*models.AcmeAccount, error) {
+	defaultLog.Trace("directory/acme_store:UpdateAccount() Entering")
+	defer defaultLog.Trace("directory/acme_store:UpdateAccount() Leaving")
+
+	err := as.write(accountsDir, account.ID, account, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/acme_store:UpdateAccount() Error in saving ACME account")
+	}
+	return account, nil
+}
+
+func (as *AcmeStore) CreateOrder(order *models.AcmeOrder) (*models.AcmeOrder, error) {
+	defaultLog.Trace("directory/acme_store:CreateOrder() Entering")
+	defer defaultLog.Trace("directory/acme_store:CreateOrder() Leaving")
+
+	err := as.write(ordersDir, order.ID, order, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/acme_store:CreateOrder() Error in saving ACME order")
+	}
+	return order, nil
+}
+
+func (as *AcmeStore) RetrieveOrder(id string) (*models.AcmeOrder, error) {
+	defaultLog.Trace("directory/acme_store:RetrieveOrder() Entering")
+	defer defaultLog.Trace("directory/acme_store:RetrieveOrder() Leaving")
+
+	var order models.AcmeOrder
+	err := as.read(ordersDir, id, &order)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (as *AcmeStore) UpdateOrder(order *models.AcmeOrder) (*models.AcmeOrder, error) {
+	defaultLog.Trace("directory/acme_store:UpdateOrder() Entering")
+	defer defaultLog.Trace("directory/acme_store:UpdateOrder() Leaving")
+
+	err := as.write(ordersDir, order.ID, order, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/acme_store:UpdateOrder() Error in saving ACME order")
+	}
+	return order, nil
+}
+
+func (as *AcmeStore) UpdateOrderStatus(id, expectedStatus, status string) (bool, error) {
+	defaultLog.Trace("directory/acme_store:UpdateOrderStatus() Entering")
+	defer defaultLog.Trace("directory/acme_store:UpdateOrderStatus() Leaving")
+
+	as.orderLock.Lock()
+	defer as.orderLock.Unlock()
+
+	order, err := as.RetrieveOrder(id)
+	if err != nil {
+		return false, err
+	}
+	if order.Status != expectedStatus {
+		return false, nil
+	}
+	order.Status = status
+	err = as.write(ordersDir, order.ID, order, false)
+	if err != nil {
+		return false, errors.Wrap(err, "directory/acme_store:UpdateOrderStatus() Error in saving ACME order")
+	}
+	return true, nil
+}
+
+func (as *AcmeStore) SearchOrders(accountId string) ([]models.AcmeOrder, error) {
+	defaultLog.Trace("directory/acme_store:SearchOrders() Entering")
+	defer defaultLog.Trace("directory/acme_store:SearchOrders() Leaving")
+
+	var orders = []models.AcmeOrder{}
+	orderFiles, err := ioutil.ReadDir(filepath.Join(as.dir, ordersDir))
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/acme_store:SearchOrders() Error in reading the ACME orders directory")
+	}
+
+	for _, orderFile := range orderFiles {
+		order, err := as.RetrieveOrder(orderFile.Name())
+		if err != nil {
+			return nil, errors.Wrapf(err, "directory/acme_store:SearchOrders() Error in retrieving ACME order from file : %s", orderFile.Name())
+		}
+		if order.AccountID == accountId {
+			orders = append(orders, *order)
+		}
+	}
+	return orders, nil
+}
+
+func (as *AcmeStore) write(subDir, id string, record interface{}, create bool) error {
+	if !isValidId(id) {
+		return errors.Errorf("Invalid record id : %s", id)
+	}
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal record")
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if create {
+		flags |= os.O_EXCL
+	}
+	recordFile, err := os.OpenFile(filepath.Join(as.dir, subDir, id), flags, 0600)
+	if err != nil {
+		return errors.Wrap(err, "Error in opening record file")
+	}
+	defer func() {
+		derr := recordFile.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("directory/acme_store:write() Error closing record file")
+		}
+	}()
+
+	_, err = recordFile.Write(bytes)
+	return err
+}
+
+func (as *AcmeStore) read(subDir, id string, record interface{}) error {
+	if !isValidId(id) {
+		return errors.New(commErr.RecordNotFound)
+	}
+	bytes, err := ioutil.ReadFile(filepath.Join(as.dir, subDir, id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.New(commErr.RecordNotFound)
+		}
+		return errors.Wrapf(err, "directory/acme_store:read() Unable to read record file : %s", id)
+	}
+	err = json.Unmarshal(bytes, record)
+	if err != nil {
+		return errors.Wrapf(err, "directory/acme_store:read() Failed to unmarshal record : %s", id)
+	}
+	return nil
+}
+
+// isValidId makes sure that an id taken from a request can not be used to reach files outside of the store
+func isValidId(id string) bool {
+	return id != "" && id != "." && id != ".." && !strings.ContainsAny(id, `/\`)
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package directory
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcmeStore(t *testing.T) {
+	assertions := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "acme")
+	assertions.NoError(err)
+	defer os.RemoveAll(dir)
+	for _, subDir := range []string{eabKeysDir, accountsDir, ordersDir} {
+		assertions.NoError(os.Mkdir(filepath.Join(dir, subDir), 0700))
+	}
+	store := NewAcmeStore(dir)
+
+	_, err = store.CreateEabKey(&models.EabKey{KeyId: "key-1", HmacKey: []byte("secret"), Expires: time.Now().Add(time.Hour)})
+	assertions.NoError(err)
+	eabKey, err := store.RetrieveEabKey("key-1")
+	assertions.NoError(err)
+	assertions.Equal([]byte("secret"), eabKey.HmacKey)
+	assertions.NoError(store.DeleteEabKey("key-1"))
+	assertions.EqualError(store.DeleteEabKey("key-1"), commErr.RecordNotFound)
+
+	_, err = store.CreateAccount(&models.AcmeAccount{ID: "thumbprint", Status: cms.AcmeStatusValid})
+	assertions.NoError(err)
+	_, err = store.CreateAccount(&models.AcmeAccount{ID: "thumbprint", Status: cms.AcmeStatusValid})
+	assertions.Error(err, "an account should not be created twice")
+	_, err = store.UpdateAccount(&models.AcmeAccount{ID: "thumbprint", Status: cms.AcmeStatusDeactivated})
+	assertions.NoError(err)
+	account, err := store.RetrieveAccount("thumbprint")
+	assertions.NoError(err)
+	assertions.Equal(cms.AcmeStatusDeactivated, account.Status)
+
+	_, err = store.RetrieveAccount("../" + accountsDir + "/thumbprint")
+	assertions.EqualError(err, commErr.RecordNotFound)
+
+	_, err = store.CreateOrder(&models.AcmeOrder{ID: "order-1", AccountID: "thumbprint", Status: cms.AcmeStatusReady})
+	assertions.NoError(err)
+	_, err = store.CreateOrder(&models.AcmeOrder{ID: "order-2", AccountID: "another", Status: cms.AcmeStatusReady})
+	assertions.NoError(err)
+	_, err = store.UpdateOrder(&models.AcmeOrder{ID: "order-1", AccountID: "thumbprint", Status: cms.AcmeStatusValid})
+	assertions.NoError(err)
+
+	updated, err := store.UpdateOrderStatus("order-2", cms.AcmeStatusReady, cms.AcmeStatusProcessing)
+	assertions.NoError(err)
+	assertions.True(updated)
+	updated, err = store.UpdateOrderStatus("order-2", cms.AcmeStatusReady, cms.AcmeStatusProcessing)
+	assertions.NoError(err)
+	assertions.False(updated, "the status should only change when the order has the expected status")
+	order, err := store.RetrieveOrder("order-2")
+	assertions.NoError(err)
+	assertions.Equal(cms.AcmeStatusProcessing, order.Status)
+
+	orders, err := store.SearchOrders("thumbprint")
+	assertions.NoError(err)
+	assertions.Len(orders, 1)
+	assertions.Equal(cms.AcmeStatusValid, orders[0].Status)
+
+	_, err = store.RetrieveOrder("order-3")
+	assertions.EqualError(err, commErr.RecordNotFound)
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package directory
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+	"github.com/pkg/errors"
+)
+
+type CertificateStore struct {
+	dir string
+}
+
+func NewCertificateStore(dir string) *CertificateStore {
+	return &CertificateStore{dir}
+}
+
+func (cs *CertificateStore) Create(issuedCert *cms.IssuedCertificate) (*cms.IssuedCertificate, error) {
+	defaultLog.Trace("directory/certificate_store:Create() Entering")
+	defer defaultLog.Trace("directory/certificate_store:Create() Leaving")
+
+	issuedCert.SerialNumber = strings.ToLower(issuedCert.SerialNumber)
+	bytes, err := json.Marshal(issuedCert)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/certificate_store:Create() Failed to marshal issued certificate")
+	}
+
+	// serial numbers are never reused, so an existing entry must not be overwritten
+	certFile, err := os.OpenFile(filepath.Join(cs.dir, issuedCert.SerialNumber), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/certificate_store:Create() Error in creating issued certificate file")
+	}
+	defer func() {
+		derr := certFile.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("directory/certificate_store:Create() Error closing issued certificate file")
+		}
+	}()
+
+	_, err = certFile.Write(bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/certificate_store:Create() Error in saving issued certificate")
+	}
+
+	return issuedCert, nil
+}
+
+func (cs *CertificateStore) Retrieve(serialNumber string) (*cms.IssuedCertificate, error) {
+	defaultLog.Trace("directory/certificate_store:Retrieve() Entering")
+	defer defaultLog.Trace("directory/certificate_store:Retrieve() Leaving")
+
+	bytes, err := ioutil.ReadFile(filepath.Join(cs.dir, strings.ToLower(serialNumber)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New(commErr.RecordNotFound)
+		} else {
+			return nil, errors.Wrapf(err, "directory/certificate_store:Retrieve() Unable to read issued certificate file : %s", serialNumber)
+		}
+	}
+
+	var issuedCert cms.IssuedCertificate
+	err = json.Unmarshal(bytes, &issuedCert)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/certificate_store:Retrieve() Failed to unmarshal issued certificate")
+	}
+
+	return &issuedCert, nil
+}
+
+func (cs *CertificateStore) Search(criteria *models.CertificateFilterCriteria) ([]cms.IssuedCertificate, error) {
+	defaultLog.Trace("directory/certificate_store:Search() Entering")
+	defer defaultLog.Trace("directory/certificate_store:Search() Leaving")
+
+	var issuedCerts = []cms.IssuedCertificate{}
+	certFiles, err := ioutil.ReadDir(cs.dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "directory/certificate_store:Search() Error in reading the issued certificates directory : %s", cs.dir)
+	}
+
+	for _, certFile := range certFiles {
+		issuedCert, err := cs.Retrieve(certFile.Name())
+		if err != nil {
+			return nil, errors.Wrapf(err, "directory/certificate_store:Search() Error in retrieving issued certificate from file : %s", certFile.Name())
+		}
+
+		issuedCerts = append(issuedCerts, *issuedCert)
+	}
+
+	if len(issuedCerts) > 0 {
+		issuedCerts = filterIssuedCertificates(issuedCerts, criteria)
+	}
+
+	return issuedCerts, nil
+}
+
+// helper function to filter the issued certificates based on given filter criteria.
+func filterIssuedCertificates(issuedCerts []cms.IssuedCertificate, criteria *models.CertificateFilterCriteria) []cms.IssuedCertificate {
+	defaultLog.Trace("directory/certificate_store:filterIssuedCertificates() Entering")
+	defer defaultLog.Trace("directory/certificate_store:filterIssuedCertificates() Leaving")
+
+	if criteria == nil || reflect.DeepEqual(*criteria, models.CertificateFilterCriteria{}) {
+		return issuedCerts
+	}
+
+	// CertType filter
+	if criteria.CertType != "" {
+		var filteredCerts []cms.IssuedCertificate
+		for _, issuedCert := range issuedCerts {
+			if strings.EqualFold(issuedCert.CertType, criteria.CertType) {
+				filteredCerts = append(filteredCerts, issuedCert)
+			}
+		}
+		issuedCerts = filteredCerts
+	}
+
+	// SubjectEqualTo filter
+	if criteria.SubjectEqualTo != "" {
+		var filteredCerts []cms.IssuedCertificate
+		for _, issuedCert := range issuedCerts {
+			if issuedCert.Subject == criteria.SubjectEqualTo {
+				filteredCerts = append(filteredCerts, issuedCert)
+			}
+		}
+		issuedCerts = filteredCerts
+	}
+
+	// SubjectContains filter
+	if criteria.SubjectContains != "" {
+		var filteredCerts []cms.IssuedCertificate
+		for _, issuedCert := range issuedCerts {
+			if strings.Contains(issuedCert.Subject, criteria.SubjectContains) {
+				filteredCerts = append(filteredCerts, issuedCert)
+			}
+		}
+		issuedCerts = filteredCerts
+	}
+
+	// IssuingCa filter
+	if criteria.IssuingCa != "" {
+		var filteredCerts []cms.IssuedCertificate
+		for _, issuedCert := range issuedCerts {
+			if strings.EqualFold(issuedCert.IssuingCa, criteria.IssuingCa) {
+				filteredCerts = append(filteredCerts, issuedCert)
+			}
+		}
+		issuedCerts = filteredCerts
+	}
+
+	// ExpiringBefore filter
+	if !criteria.ExpiringBefore.IsZero() {
+		var filteredCerts []cms.IssuedCertificate
+		for _, issuedCert := range issuedCerts {
+			if issuedCert.NotAfter.Before(criteria.ExpiringBefore) {
+				filteredCerts = append(filteredCerts, issuedCert)
+			}
+		}
+		issuedCerts = filteredCerts
+	}
+
+	return issuedCerts
+}
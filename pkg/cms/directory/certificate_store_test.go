@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package directory
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertificateStore(t *testing.T) {
+	assertions := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "issued-certificates")
+	assertions.NoError(err)
+	defer os.RemoveAll(dir)
+
+	now := time.Now().UTC()
+	store := NewCertificateStore(dir)
+	created, err := store.Create(&cms.IssuedCertificate{
+		SerialNumber: "1A",
+		Subject:      "HVS TLS Certificate",
+		SanList:      []string{"hvs.example.com"},
+		CertType:     "TLS",
+		IssuingCa:    "TLS",
+		RequestedBy:  "admin",
+		NotBefore:    now,
+		NotAfter:     now.AddDate(0, 0, 20),
+	})
+	assertions.NoError(err)
+	assertions.Equal("1a", created.SerialNumber)
+
+	_, err = store.Create(&cms.IssuedCertificate{SerialNumber: "1a", CertType: "TLS"})
+	assertions.Error(err, "a serial number should not be recorded twice")
+
+	_, err = store.Create(&cms.IssuedCertificate{
+		SerialNumber: "2b",
+		Subject:      "HVS Flavor Signing Certificate",
+		CertType:     "Flavor-Signing",
+		IssuingCa:    "Signing",
+		NotBefore:    now,
+		NotAfter:     now.AddDate(1, 0, 0),
+	})
+	assertions.NoError(err)
+
+	retrieved, err := store.Retrieve("1A")
+	assertions.NoError(err)
+	assertions.Equal("admin", retrieved.RequestedBy)
+	assertions.Equal([]string{"hvs.example.com"}, retrieved.SanList)
+
+	_, err = store.Retrieve("ff")
+	assertions.EqualError(err, commErr.RecordNotFound)
+
+	issuedCerts, err := store.Search(nil)
+	assertions.NoError(err)
+	assertions.Len(issuedCerts, 2)
+
+	issuedCerts, err = store.Search(&models.CertificateFilterCriteria{CertType: "flavor-signing"})
+	assertions.NoError(err)
+	assertions.Len(issuedCerts, 1)
+	assertions.Equal("2b", issuedCerts[0].SerialNumber)
+
+	issuedCerts, err = store.Search(&models.CertificateFilterCriteria{SubjectContains: "HVS", IssuingCa: "tls"})
+	assertions.NoError(err)
+	assertions.Len(issuedCerts, 1)
+	assertions.Equal("1a", issuedCerts[0].SerialNumber)
+
+	issuedCerts, err = store.Search(&models.CertificateFilterCriteria{SubjectEqualTo: "HVS"})
+	assertions.NoError(err)
+	assertions.Empty(issuedCerts)
+
+	issuedCerts, err = store.Search(&models.CertificateFilterCriteria{ExpiringBefore: now.AddDate(0, 1, 0)})
+	assertions.NoError(err)
+	assertions.Len(issuedCerts, 1)
+	assertions.Equal("1a", issuedCerts[0].SerialNumber)
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package directory
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+	"github.com/pkg/errors"
+)
+
+var defaultLog = log.GetDefaultLogger()
+
+type RevocationStore struct {
+	dir string
+}
+
+func NewRevocationStore(dir string) *RevocationStore {
+	return &RevocationStore{dir}
+}
+
+func (rs *RevocationStore) Create(revokedCert *cms.RevokedCertificate) (*cms.RevokedCertificate, error) {
+	defaultLog.Trace("directory/revocation_store:Create() Entering")
+	defer defaultLog.Trace("directory/revocation_store:Create() Leaving")
+
+	revokedCert.SerialNumber = strings.ToLower(revokedCert.SerialNumber)
+	revokedCert.RevokedAt = time.Now().UTC()
+	bytes, err := json.Marshal(revokedCert)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/revocation_store:Create() Failed to marshal revoked certificate")
+	}
+
+	// a certificate can only be revoked once, so do not overwrite an existing entry
+	revocationFile, err := os.OpenFile(filepath.Join(rs.dir, revokedCert.SerialNumber), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/revocation_store:Create() Error in creating revoked certificate file")
+	}
+	defer func() {
+		derr := revocationFile.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("directory/revocation_store:Create() Error closing revoked certificate file")
+		}
+	}()
+
+	_, err = revocationFile.Write(bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/revocation_store:Create() Error in saving revoked certificate")
+	}
+
+	return revokedCert, nil
+}
+
+func (rs *RevocationStore) Retrieve(serialNumber string) (*cms.RevokedCertificate, error) {
+	defaultLog.Trace("directory/revocation_store:Retrieve() Entering")
+	defer defaultLog.Trace("directory/revocation_store:Retrieve() Leaving")
+
+	bytes, err := ioutil.ReadFile(filepath.Join(rs.dir, strings.ToLower(serialNumber)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New(commErr.RecordNotFound)
+		} else {
+			return nil, errors.Wrapf(err, "directory/revocation_store:Retrieve() Unable to read revoked certificate file : %s", serialNumber)
+		}
+	}
+
+	var revokedCert cms.RevokedCertificate
+	err = json.Unmarshal(bytes, &revokedCert)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/revocation_store:Retrieve() Failed to unmarshal revoked certificate")
+	}
+
+	return &revokedCert, nil
+}
+
+func (rs *RevocationStore) Search(criteria *models.RevocationFilterCriteria) ([]cms.RevokedCertificate, error) {
+	defaultLog.Trace("directory/revocation_store:Search() Entering")
+	defer defaultLog.Trace("directory/revocation_store:Search() Leaving")
+
+	var revokedCerts = []cms.RevokedCertificate{}
+	revocationFiles, err := ioutil.ReadDir(rs.dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "directory/revocation_store:Search() Error in reading the revoked certificates directory : %s", rs.dir)
+	}
+
+	for _, revocationFile := range revocationFiles {
+		revokedCert, err := rs.Retrieve(revocationFile.Name())
+		if err != nil {
+			return nil, errors.Wrapf(err, "directory/revocation_store:Search() Error in retrieving revoked certificate from file : %s", revocationFile.Name())
+		}
+
+		revokedCerts = append(revokedCerts, *revokedCert)
+	}
+
+	if len(revokedCerts) > 0 {
+		revokedCerts = filterRevokedCertificates(revokedCerts, criteria)
+	}
+
+	return revokedCerts, nil
+}
+
+// helper function to filter the revoked certificates based on given filter criteria.
+func filterRevokedCertificates(revokedCerts []cms.RevokedCertificate, criteria *models.RevocationFilterCriteria) []cms.RevokedCertificate {
+	defaultLog.Trace("directory/revocation_store:filterRevokedCertificates() Entering")
+	defer defaultLog.Trace("directory/revocation_store:filterRevokedCertificates() Leaving")
+
+	if criteria == nil || reflect.DeepEqual(*criteria, models.RevocationFilterCriteria{}) {
+		return revokedCerts
+	}
+
+	// IssuingCa filter
+	if criteria.IssuingCa != "" {
+		var filteredCerts []cms.RevokedCertificate
+		for _, revokedCert := range revokedCerts {
+			if strings.EqualFold(revokedCert.IssuingCa, criteria.IssuingCa) {
+				filteredCerts = append(filteredCerts, revokedCert)
+			}
+		}
+		revokedCerts = filteredCerts
+	}
+
+	// RevokedBefore filter
+	if !criteria.RevokedBefore.IsZero() {
+		var filteredCerts []cms.RevokedCertificate
+		for _, revokedCert := range revokedCerts {
+			if revokedCert.RevokedAt.Before(criteria.RevokedBefore) {
+				filteredCerts = append(filteredCerts, revokedCert)
+			}
+		}
+		revokedCerts = filteredCerts
+	}
+
+	// RevokedAfter filter
+	if !criteria.RevokedAfter.IsZero() {
+		var filteredCerts []cms.RevokedCertificate
+		for _, revokedCert := range revokedCerts {
+			if revokedCert.RevokedAt.After(criteria.RevokedAfter) {
+				filteredCerts = append(filteredCerts, revokedCert)
+			}
+		}
+		revokedCerts = filteredCerts
+	}
+
+	return revokedCerts
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package directory
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevocationStore(t *testing.T) {
+	assertions := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "revoked-certificates")
+	assertions.NoError(err)
+	defer os.RemoveAll(dir)
+
+	store := NewRevocationStore(dir)
+	created, err := store.Create(&cms.RevokedCertificate{
+		SerialNumber: "1A",
+		IssuingCa:    "TLS",
+		Reason:       "keyCompromise",
+	})
+	assertions.NoError(err)
+	assertions.Equal("1a", created.SerialNumber)
+	assertions.False(created.RevokedAt.IsZero())
+
+	_, err = store.Create(&cms.RevokedCertificate{SerialNumber: "1a", IssuingCa: "TLS"})
+	assertions.Error(err, "a certificate should not be revoked twice")
+
+	_, err = store.Create(&cms.RevokedCertificate{SerialNumber: "2b", IssuingCa: "Signing", Reason: "superseded"})
+	assertions.NoError(err)
+
+	retrieved, err := store.Retrieve("1A")
+	assertions.NoError(err)
+	assertions.Equal("TLS", retrieved.IssuingCa)
+	assertions.Equal("keyCompromise", retrieved.Reason)
+
+	_, err = store.Retrieve("ff")
+	assertions.EqualError(err, commErr.RecordNotFound)
+
+	revokedCerts, err := store.Search(nil)
+	assertions.NoError(err)
+	assertions.Len(revokedCerts, 2)
+
+	revokedCerts, err = store.Search(&models.RevocationFilterCriteria{IssuingCa: "signing"})
+	assertions.NoError(err)
+	assertions.Len(revokedCerts, 1)
+	assertions.Equal("2b", revokedCerts[0].SerialNumber)
+
+	revokedCerts, err = store.Search(&models.RevocationFilterCriteria{RevokedAfter: time.Now().Add(time.Hour)})
+	assertions.NoError(err)
+	assertions.Empty(revokedCerts)
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package domain
+
+import (
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+)
+
+type (
+	RevocationStore interface {
+		Create(*cms.RevokedCertificate) (*cms.RevokedCertificate, error)
+		Retrieve(serialNumber string) (*cms.RevokedCertificate, error)
+		Search(criteria *models.RevocationFilterCriteria) ([]cms.RevokedCertificate, error)
+	}
+
+	CertificateStore interface {
+		Create(*cms.IssuedCertificate) (*cms.IssuedCertificate, error)
+		Retrieve(serialNumber string) (*cms.IssuedCertificate, error)
+		Search(criteria *models.CertificateFilterCriteria) ([]cms.IssuedCertificate, error)
+	}
+
+	AcmeStore interface {
+		CreateEabKey(*models.EabKey) (*models.EabKey, error)
+		RetrieveEabKey(keyId string) (*models.EabKey, error)
+		DeleteEabKey(keyId string) error
+		CreateAccount(*models.AcmeAccount) (*models.AcmeAccount, error)
+		RetrieveAccount(id string) (*models.AcmeAccount, error)
+		UpdateAccount(*models.AcmeAccount) (*models.AcmeAccount, error)
+		CreateOrder(*models.AcmeOrder) (*models.AcmeOrder, error)
+		RetrieveOrder(id string) (*models.AcmeOrder, error)
+		UpdateOrder(*models.AcmeOrder) (*models.AcmeOrder, error)
+		// UpdateOrderStatus changes the status of the order only when it still has the expected status, it returns
+		// false when the status of the order was changed by someone else
+		UpdateOrderStatus(id, expectedStatus, status string) (bool, error)
+		SearchOrders(accountId string) ([]models.AcmeOrder, error)
+	}
+)
@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+)
+
+// EabKey is an external account binding key that can be used once to register an ACME account
+type EabKey struct {
+	KeyId   string `json:"key_id"`
+	HmacKey []byte `json:"hmac_key"`
+	// Roles are the CertApprover roles of the token that requested the key. They are inherited by the ACME account
+	// and decide the identifiers the account can get certificates for
+	Roles       []aas.RoleInfo `json:"roles"`
+	RequestedBy string         `json:"requested_by"`
+	Expires     time.Time      `json:"expires"`
+}
+
+// AcmeAccount is an ACME account. The ID is the JWK thumbprint of the account key
+type AcmeAccount struct {
+	ID      string          `json:"id"`
+	Key     json.RawMessage `json:"key"`
+	Status  string          `json:"status"`
+	Contact []string        `json:"contact,omitempty"`
+	// Roles are the roles granted by the EAB key the account was registered with. They are removed when the account
+	// is deactivated
+	Roles       []aas.RoleInfo `json:"roles"`
+	EabKeyId    string         `json:"eab_key_id"`
+	RequestedBy string         `json:"requested_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// AcmeOrder is an ACME order. All identifiers of an order are authorized when the order is created, so the
+// authorizations are not stored separately
+type AcmeOrder struct {
+	ID               string               `json:"id"`
+	AccountID        string               `json:"account_id"`
+	Status           string               `json:"status"`
+	Identifiers      []cms.AcmeIdentifier `json:"identifiers"`
+	CreatedAt        time.Time            `json:"created_at"`
+	Expires          time.Time            `json:"expires"`
+	CertSerialNumber string               `json:"cert_serial_number,omitempty"`
+	// CertificateChain is the PEM encoded certificate followed by the issuing CA certificate
+	CertificateChain string           `json:"certificate_chain,omitempty"`
+	Error            *cms.AcmeProblem `json:"error,omitempty"`
+}
@@ -0,0 +1,16 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package models
+
+import "time"
+
+// CertificateFilterCriteria stores the parameters for filtering the issued certificates
+type CertificateFilterCriteria struct {
+	CertType        string
+	SubjectEqualTo  string
+	SubjectContains string
+	IssuingCa       string
+	ExpiringBefore  time.Time
+}
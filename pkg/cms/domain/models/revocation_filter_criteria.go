@@ -0,0 +1,14 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package models
+
+import "time"
+
+// RevocationFilterCriteria stores the parameters for filtering the revoked certificates
+type RevocationFilterCriteria struct {
+	IssuingCa     string
+	RevokedBefore time.Time
+	RevokedAfter  time.Time
+}
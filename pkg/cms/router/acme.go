@@ -0,0 +1,54 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package router
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/controllers"
+)
+
+const (
+	acmeAccountIdExpr = "{id:[A-Za-z0-9_-]+}"
+	acmeOrderIdExpr   = "{id:[0-9a-f-]+}"
+)
+
+// SetAcmeRoutes is used to set the ACME (RFC 8555) endpoints. These do not use bearer tokens, the requests are
+// signed with the key of the ACME account instead
+func SetAcmeRoutes(router *mux.Router, acmeController *controllers.AcmeController) *mux.Router {
+	defaultLog.Trace("router/acme:SetAcmeRoutes() Entering")
+	defer defaultLog.Trace("router/acme:SetAcmeRoutes() Leaving")
+
+	acmeHandler := func(h func(w http.ResponseWriter, r *http.Request) (interface{}, int, error)) http.HandlerFunc {
+		return ErrorHandler(AcmeResponseHandler(h, acmeController.SetResponseHeaders))
+	}
+
+	router.Handle("/acme/directory", acmeHandler(acmeController.Directory)).Methods("GET")
+	router.Handle("/acme/new-nonce", acmeHandler(acmeController.NewNonce)).Methods("HEAD", "GET")
+	router.Handle("/acme/new-account", acmeHandler(acmeController.NewAccount)).Methods("POST")
+	router.Handle("/acme/account/"+acmeAccountIdExpr, acmeHandler(acmeController.Account)).Methods("POST")
+	router.Handle("/acme/account/"+acmeAccountIdExpr+"/orders", acmeHandler(acmeController.AccountOrders)).Methods("POST")
+	router.Handle("/acme/new-order", acmeHandler(acmeController.NewOrder)).Methods("POST")
+	router.Handle("/acme/order/"+acmeOrderIdExpr, acmeHandler(acmeController.Order)).Methods("POST")
+	router.Handle("/acme/order/"+acmeOrderIdExpr+"/finalize", acmeHandler(acmeController.Finalize)).Methods("POST")
+	router.Handle("/acme/authz/"+acmeOrderIdExpr+"/{index:[0-9]+}", acmeHandler(acmeController.Authorization)).Methods("POST")
+	router.Handle("/acme/challenge/"+acmeOrderIdExpr+"/{index:[0-9]+}", acmeHandler(acmeController.Challenge)).Methods("POST")
+	router.Handle("/acme/cert/"+acmeOrderIdExpr, acmeHandler(acmeController.Certificate)).Methods("POST")
+
+	return router
+}
+
+// SetAcmeEabKeysRoutes is used to set the endpoint handing out external account binding keys to callers holding
+// CertApprover roles
+func SetAcmeEabKeysRoutes(router *mux.Router, acmeController *controllers.AcmeController) *mux.Router {
+	defaultLog.Trace("router/acme:SetAcmeEabKeysRoutes() Entering")
+	defer defaultLog.Trace("router/acme:SetAcmeEabKeysRoutes() Leaving")
+
+	router.Handle("/acme/eab-keys", ErrorHandler(JsonResponseHandler(acmeController.CreateEabKey))).Methods("POST")
+
+	return router
+}
@@ -8,7 +8,9 @@ package router
 import (
 	"github.com/gorilla/mux"
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/constants"
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/controllers"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/directory"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -17,7 +19,18 @@ func SetCertificatesRoutes(router *mux.Router, config *config.Configuration) *mu
 	log.Trace("router/certificates:SetCertificatesRoutes() Entering")
 	defer log.Trace("router/certificates:SetCertificatesRoutes() Leaving")
 
-	certController := controllers.CertificatesController{Config: config}
+	certController := controllers.CertificatesController{
+		Config: config,
+		Store:  directory.NewCertificateStore(constants.IssuedCertsDir),
+	}
 	router.HandleFunc("/certificates", certController.GetCertificates).Methods("POST")
+
+	router.Handle("/certificates/{serialNumber:[0-9a-fA-F]+}",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(certController.Retrieve),
+			[]string{constants.CertificateRetrieve}))).Methods("GET")
+
+	router.Handle("/certificates",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(certController.Search),
+			[]string{constants.CertificateSearch}))).Methods("GET")
 	return router
 }
@@ -0,0 +1,55 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package router
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/controllers"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/directory"
+)
+
+const serialNumberExpr = "/revocations/{serialNumber:[0-9a-fA-F]+}"
+
+// SetRevocationsRoutes is used to set the endpoints for certificate revocation APIs
+func SetRevocationsRoutes(router *mux.Router, cfg *config.Configuration) *mux.Router {
+	defaultLog.Trace("router/revocations:SetRevocationsRoutes() Entering")
+	defer defaultLog.Trace("router/revocations:SetRevocationsRoutes() Leaving")
+
+	revocationsController := controllers.NewRevocationsController(cfg, directory.NewRevocationStore(constants.RevokedCertsDir),
+		directory.NewCertificateStore(constants.IssuedCertsDir))
+
+	router.Handle("/revocations",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(revocationsController.Create),
+			[]string{constants.RevocationCreate}))).Methods("POST")
+
+	router.Handle(serialNumberExpr,
+		ErrorHandler(permissionsHandler(JsonResponseHandler(revocationsController.Retrieve),
+			[]string{constants.RevocationRetrieve}))).Methods("GET")
+
+	router.Handle("/revocations",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(revocationsController.Search),
+			[]string{constants.RevocationSearch}))).Methods("GET")
+
+	return router
+}
+
+// SetCrlRoutes is used to set the unauthenticated endpoints used by relying parties to check the revocation status
+// of certificates
+func SetCrlRoutes(router *mux.Router, cfg *config.Configuration) *mux.Router {
+	defaultLog.Trace("router/revocations:SetCrlRoutes() Entering")
+	defer defaultLog.Trace("router/revocations:SetCrlRoutes() Leaving")
+
+	revocationsController := controllers.NewRevocationsController(cfg, directory.NewRevocationStore(constants.RevokedCertsDir),
+		directory.NewCertificateStore(constants.IssuedCertsDir))
+
+	router.Handle("/crl", ErrorHandler(ResponseHandler(revocationsController.GetCrl))).Methods("GET")
+	router.Handle("/ocsp", ErrorHandler(ResponseHandler(revocationsController.Ocsp))).Methods("POST")
+	router.Handle("/ocsp/{ocspRequest:.+}", ErrorHandler(ResponseHandler(revocationsController.Ocsp))).Methods("GET")
+
+	return router
+}
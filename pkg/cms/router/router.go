@@ -5,23 +5,25 @@
 package router
 
 import (
-	"crypto/tls"
-	"crypto/x509"
 	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/clients"
+	"github.com/intel-secl/intel-secl/v3/pkg/clients/aas"
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/config"
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/controllers"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/directory"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/middleware"
-	cos "github.com/intel-secl/intel-secl/v3/pkg/lib/common/os"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
 	"github.com/pkg/errors"
-	"io/ioutil"
-	"net/http"
 	"strings"
 	"time"
 )
 
 var defaultLog = log.GetDefaultLogger()
+var secLog = log.GetSecurityLogger()
 
 type Router struct {
 	cfg *config.Configuration
@@ -48,76 +50,72 @@ func defineSubRoutes(router *mux.Router, service string, cfg *config.Configurati
 	subRouter := router.PathPrefix(serviceApi).Subrouter()
 	subRouter = SetVersionRoutes(subRouter)
 	subRouter = SetCACertificatesRoutes(subRouter)
+	subRouter = SetCrlRoutes(subRouter, cfg)
+
+	// ACME requests are authenticated with the JWS signature of the account instead of a bearer token
+	var acmeController *controllers.AcmeController
+	if cfg.Acme.Enabled {
+		acmeController = controllers.NewAcmeController(cfg, directory.NewAcmeStore(constants.AcmeDirPath),
+			directory.NewCertificateStore(constants.IssuedCertsDir))
+		subRouter = SetAcmeRoutes(subRouter, acmeController)
+	}
 
 	subRouter = router.PathPrefix(serviceApi).Subrouter()
 	cfgRouter := Router{cfg: cfg}
-	subRouter.Use(middleware.NewTokenAuth(constants.TrustedJWTSigningCertsDir, constants.ConfigDir, cfgRouter.fnGetJwtCerts,
-		time.Minute*constants.DefaultJwtValidateCacheKeyMins))
+	// the token signing certificates of AAS are issued by the CMS signing CA that chains to the CMS root CA
+	subRouter.Use(middleware.NewTokenAuthWithJwks(constants.TrustedJWTSigningCertsDir, constants.RootCADirPath, cfgRouter.fnGetJwks,
+		time.Minute*constants.DefaultJwtValidateCacheKeyMins, middleware.NewRevocationListCache(cfgRouter.fnGetRevocationList,
+			constants.DefaultRevocationListCacheTime, constants.DefaultRevocationListMaxStaleness)))
 	subRouter = SetCertificatesRoutes(subRouter, cfg)
+	subRouter = SetRevocationsRoutes(subRouter, cfg)
+	if acmeController != nil {
+		subRouter = SetAcmeEabKeysRoutes(subRouter, acmeController)
+	}
 }
 
-// Fetch JWT certificate from AAS
-//TODO: use interface to store save certificates
-func (r *Router) fnGetJwtCerts() error {
-	defaultLog.Trace("router/router:fnGetJwtCerts() Entering")
-	defer defaultLog.Trace("router/router:fnGetJwtCerts() Leaving")
+// Fetch the token revocation list from AAS
+func (r *Router) fnGetRevocationList() (*aasModel.RevocationList, error) {
+	defaultLog.Trace("router/router:fnGetRevocationList() Entering")
+	defer defaultLog.Trace("router/router:fnGetRevocationList() Leaving")
 
-	cfg := r.cfg
-	if !strings.HasSuffix(cfg.AASApiUrl, "/") {
-		cfg.AASApiUrl = cfg.AASApiUrl + "/"
-	}
-	url := cfg.AASApiUrl + "noauth/jwt-certificates"
-	req, err := http.NewRequest("GET", url, nil)
+	caCerts, err := crypt.GetCertsFromDir(constants.RootCADirPath)
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Could not create http request")
+		return nil, errors.Wrap(err, "router/router:fnGetRevocationList() Could not read root CA certificates")
 	}
-	req.Header.Add("accept", "application/x-pem-file")
-	rootCaCertPems, err := cos.GetDirFileContents(constants.RootCADirPath, "*.pem")
+	httpClient, err := clients.HTTPClientWithCA(caCerts)
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Could not read root CA certificate")
+		return nil, errors.Wrap(err, "router/router:fnGetRevocationList() Could not create http client")
 	}
-
-	// Get the SystemCertPool, continue with an empty pool on error
-	rootCAs, err := x509.SystemCertPool()
+	// the requests are checked against the previously retrieved list meanwhile, an unresponsive AAS must not
+	// keep it from being retrieved again
+	httpClient.Timeout = constants.DefaultRevocationListTimeout
+	aasClient := aas.Client{BaseURL: r.cfg.AASApiUrl, HTTPClient: httpClient}
+	revocationList, err := aasClient.GetRevokedTokens()
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Could not initiate certificate pool")
-	}
-	if rootCAs == nil {
-		rootCAs = x509.NewCertPool()
-	}
-	for _, rootCACert := range rootCaCertPems {
-		if ok := rootCAs.AppendCertsFromPEM(rootCACert); !ok {
-			return err
-		}
-	}
-	httpClient := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				MinVersion:         tls.VersionTLS12,
-				InsecureSkipVerify: false,
-				RootCAs:            rootCAs,
-			},
-		},
+		return nil, errors.Wrap(err, "router/router:fnGetRevocationList() Could not retrieve token revocation list")
 	}
+	return revocationList, nil
+}
 
-	res, err := httpClient.Do(req)
+// Fetch the JWK set of the token signing keys from AAS
+func (r *Router) fnGetJwks() (*jwtauth.JSONWebKeySet, error) {
+	defaultLog.Trace("router/router:fnGetJwks() Entering")
+	defer defaultLog.Trace("router/router:fnGetJwks() Leaving")
+
+	caCerts, err := crypt.GetCertsFromDir(constants.RootCADirPath)
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Could not retrieve jwt certificate")
+		return nil, errors.Wrap(err, "router/router:fnGetJwks() Could not read root CA certificates")
 	}
-	defer func() {
-		derr := res.Body.Close()
-		if derr != nil {
-			defaultLog.WithError(derr).Error("Error closing response body")
-		}
-	}()
-
-	body, err := ioutil.ReadAll(res.Body)
+	httpClient, err := clients.HTTPClientWithCA(caCerts)
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Could not read response body")
+		return nil, errors.Wrap(err, "router/router:fnGetJwks() Could not create http client")
 	}
-	err = crypt.SavePemCertWithShortSha1FileName(body, constants.TrustedJWTSigningCertsDir)
+	// the requests signed by a key that is not in the cached JWK set wait for the retrieval
+	httpClient.Timeout = constants.DefaultJwksTimeout
+	aasClient := aas.Client{BaseURL: r.cfg.AASApiUrl, HTTPClient: httpClient}
+	jwks, err := aasClient.GetJwks()
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Could not store Certificate")
+		return nil, errors.Wrap(err, "router/router:fnGetJwks() Could not retrieve JWK set")
 	}
-	return nil
+	return jwks, nil
 }
@@ -9,8 +9,11 @@ import (
 	"crypto/tls"
 	"fmt"
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/router"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/tasks"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
+	commTls "github.com/intel-secl/intel-secl/v3/pkg/lib/common/tls"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"os/signal"
@@ -45,12 +48,22 @@ func (a *App) startServer() error {
 	// Initialize routes
 	routes := router.InitRoutes(c)
 
+	// Load the TLS certificate, it is reloaded when the files change and reissued by the TLS CA before it expires
+	certReloader, err := commTls.NewCertReloader(constants.TLSCertPath, constants.TLSKeyPath, c.TlsRenewBefore,
+		c.TlsReloadInterval, a.renewTLSCert)
+	if err != nil {
+		return errors.Wrap(err, "app:startServer() Failed to load TLS certificate")
+	}
+	certReloader.Start()
+	defer certReloader.Stop()
+
 	tlsconfig := &tls.Config{
 		MinVersion: tls.VersionTLS12,
 		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
 			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
 			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
 			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+		GetCertificate: certReloader.GetCertificate,
 	}
 	// Setup signal handlers to gracefully handle termination
 	stop := make(chan os.Signal)
@@ -69,12 +82,9 @@ func (a *App) startServer() error {
 		MaxHeaderBytes:    c.Server.MaxHeaderBytes,
 	}
 
-	tlsCert := constants.TLSCertPath
-	tlsKey := constants.TLSKeyPath
-
 	// dispatch web server go routine
 	go func() {
-		if err := h.ListenAndServeTLS(tlsCert, tlsKey); err != nil {
+		if err := h.ListenAndServeTLS("", ""); err != nil {
 			log.WithError(err).Fatal("app:startServer() Failed to start HTTPS server")
 			stop <- syscall.SIGTERM
 		}
@@ -91,6 +101,30 @@ func (a *App) startServer() error {
 	return nil
 }
 
+// renewTLSCert issues a new TLS certificate for CMS from the TLS CA and saves the digest of the new certificate,
+// which is used by other services to trust CMS during setup
+func (a *App) renewTLSCert() error {
+	log.Trace("app:renewTLSCert() Entering")
+	defer log.Trace("app:renewTLSCert() Leaving")
+
+	c := a.configuration()
+	err := tasks.TLS{
+		ConsoleWriter:    ioutil.Discard,
+		TLSCertDigestPtr: &c.TlsCertDigest,
+		TLSSanList:       c.TlsSanList,
+		CACertConfig:     &c.CACert,
+	}.Run()
+	if err != nil {
+		return errors.Wrap(err, "app:renewTLSCert() Could not issue TLS certificate")
+	}
+	err = c.Save(constants.DefaultConfigFilePath)
+	if err != nil {
+		return errors.Wrap(err, "app:renewTLSCert() Could not save TLS certificate digest")
+	}
+	slog.Infof("app:renewTLSCert() TLS certificate renewed, new TLS certificate digest: %s", c.TlsCertDigest)
+	return nil
+}
+
 func (a *App) loadCertPathStore() *models.CertificatesPathStore {
 	return &models.CertificatesPathStore{
 		models.CaCertTypesRootCa.String(): models.CertLocation{
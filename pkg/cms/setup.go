@@ -136,6 +136,12 @@ func (a *App) setupTaskRunner() (*setup.Runner, error) {
 			Locality:     viper.GetString("cms-ca-locality"),
 			Province:     viper.GetString("cms-ca-province"),
 			Country:      viper.GetString("cms-ca-country"),
+			Pkcs11: config.Pkcs11Config{
+				Module:     viper.GetString("cms-ca-pkcs11-module"),
+				TokenLabel: viper.GetString("cms-ca-pkcs11-token-label"),
+				PinFile:    viper.GetString("cms-ca-pkcs11-pin-file"),
+				Pin:        viper.GetString("cms-ca-pkcs11-pin"),
+			},
 		},
 	})
 	runner.AddTask("intermediate_ca", "", &tasks.IntermediateCa{
@@ -146,6 +152,7 @@ func (a *App) setupTaskRunner() (*setup.Runner, error) {
 		ConsoleWriter:    a.consoleWriter(),
 		TLSCertDigestPtr: &a.Config.TlsCertDigest,
 		TLSSanList:       a.Config.TlsSanList,
+		CACertConfig:     &a.Config.CACert,
 	})
 	runner.AddTask("cms_auth_token", "", &tasks.CmsAuthToken{
 		ConsoleWriter: a.consoleWriter(),
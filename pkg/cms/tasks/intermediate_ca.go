@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/config"
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/utils"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/setup"
 	"github.com/pkg/errors"
@@ -28,22 +29,24 @@ type IntermediateCa struct {
 	commandName   string
 }
 
-func createIntermediateCACert(cfg *config.CACertConfig, cn string) (privKey crypto.PrivateKey, cert []byte, err error) {
+func createIntermediateCACert(cfg *config.CACertConfig, interCa string) (privKey crypto.Signer, cert []byte, err error) {
 	log.Trace("tasks/intermediate_ca:createIntermediateCACert() Entering")
 	defer log.Trace("tasks/intermediate_ca:createIntermediateCACert() Leaving")
 
 	rCaAttr := constants.GetCaAttribs(constants.Root)
+	caAttr := constants.GetCaAttribs(interCa)
 
-	privKey, pubKey, err := crypt.GenerateKeyPair(constants.DefaultKeyAlgorithm, constants.DefaultKeyAlgorithmLength)
+	privKey, err = utils.GenerateCaKey(cfg, caAttr)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "tasks/intermediate_ca:createIntermediateCACert() Could not generate key pair")
 	}
-	caCertTemplate, err := getCACertTemplate(cfg, cn, rCaAttr.CommonName, pubKey)
+	pubKey := privKey.Public()
+	caCertTemplate, err := getCACertTemplate(cfg, caAttr.CommonName, rCaAttr.CommonName, pubKey)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "tasks/intermediate_ca:createIntermediateCACert() Could not generate Certificate Template")
 	}
 
-	rootCert, rootCAPrivKey, err := crypt.LoadX509CertAndPrivateKey(rCaAttr.CertPath, rCaAttr.KeyPath)
+	rootCert, rootCAPrivKey, err := utils.LoadCaSigner(cfg, constants.Root)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "tasks/intermediate_ca:createIntermediateCACert() Could not load root CA certificate")
 	}
@@ -82,18 +85,13 @@ func (ca IntermediateCa) Run() error {
 	for _, interCa := range cas {
 		fmt.Fprintln(ca.ConsoleWriter, "Creating intermediate CA ", interCa)
 		caAttr := constants.GetCaAttribs(interCa)
-		privKey, cert, err := createIntermediateCACert(ca.Config, caAttr.CommonName)
+		privKey, cert, err := createIntermediateCACert(ca.Config, interCa)
 		if err != nil {
 			return errors.Wrap(err, "tasks/intermediate_ca:Run() Could not create intemediate CA")
 		}
 
-		key, err := x509.MarshalPKCS8PrivateKey(privKey)
-		if err != nil {
-			return errors.Wrap(err, "tasks/intermediate_ca:Run() Could not marshal private key to pkcs8 format error")
-		}
-
 		//Store key and certificate
-		err = crypt.SavePrivateKeyAsPKCS8(key, caAttr.KeyPath)
+		err = utils.SaveCaKey(ca.Config, caAttr, privKey)
 		if err != nil {
 			return errors.Wrapf(err, "tasks/intermediate_ca:Run() -%v - Could not save private key", interCa)
 		}
@@ -118,8 +116,8 @@ func (ca IntermediateCa) Validate() error {
 		if os.IsNotExist(err) {
 			return errors.Wrapf(err, "tasks/intermediate_ca:Validate() -%v - Intermediary CA Certificate is not configured", interCa)
 		}
-		_, err = os.Stat(caAttr.KeyPath)
-		if os.IsNotExist(err) {
+		err = utils.ValidateCaKey(ca.Config, caAttr)
+		if err != nil {
 			return errors.Wrapf(err, "tasks/intermediate_ca:Validate() -%v - Intermediary CA Key is not configured", interCa)
 		}
 	}
@@ -15,6 +15,7 @@ import (
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/utils"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
 	clog "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/pkcs11"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/setup"
 	"github.com/pkg/errors"
 	"io"
@@ -35,11 +36,15 @@ type RootCa struct {
 const rootCAEnvHelpPrompt = "Following environment variables are required for root_ca setup:"
 
 var rootCAEnvHelp = map[string]string{
-	"CMS_CA_CERT_VALIDITY": "CA Certificate Validity",
-	"CMS_CA_ORGANIZATION":  "CA Certificate Organization",
-	"CMS_CA_LOCALITY":      "CA Certificate Locality",
-	"CMS_CA_PROVINCE":      "CA Certificate Province",
-	"CMS_CA_COUNTRY":       "CA Certificate Country",
+	"CMS_CA_CERT_VALIDITY":      "CA Certificate Validity",
+	"CMS_CA_ORGANIZATION":       "CA Certificate Organization",
+	"CMS_CA_LOCALITY":           "CA Certificate Locality",
+	"CMS_CA_PROVINCE":           "CA Certificate Province",
+	"CMS_CA_COUNTRY":            "CA Certificate Country",
+	"CMS_CA_PKCS11_MODULE":      "PKCS#11 module to keep the CA keys in, for example /usr/lib/softhsm/libsofthsm2.so (optional)",
+	"CMS_CA_PKCS11_TOKEN_LABEL": "Label of the PKCS#11 token for the CA keys",
+	"CMS_CA_PKCS11_PIN":         "User PIN of the PKCS#11 token for the CA keys, it is saved in the PIN file",
+	"CMS_CA_PKCS11_PIN_FILE":    "File keeping the user PIN of the PKCS#11 token, readable only by the owner",
 }
 
 func GetCACertDefaultTemplate(cfg *config.CACertConfig, cn string, parent string) (x509.Certificate, error) {
@@ -85,14 +90,15 @@ func getCACertTemplate(cfg *config.CACertConfig, cn string, parCn string, pubKey
 	return tmplt, err
 }
 
-func createRootCACert(cfg *config.CACertConfig) (privKey crypto.PrivateKey, cert []byte, err error) {
+func createRootCACert(cfg *config.CACertConfig) (privKey crypto.Signer, cert []byte, err error) {
 	log.Trace("tasks/rootca:createRootCACert() Entering")
 	defer log.Trace("tasks/rootca:createRootCACert() Leaving")
 
-	privKey, pubKey, err := crypt.GenerateKeyPair(constants.DefaultKeyAlgorithm, constants.DefaultKeyAlgorithmLength)
+	privKey, err = utils.GenerateCaKey(cfg, constants.GetCaAttribs(constants.Root))
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "tasks/rootca:createRootCACert() Could not create root key pair")
 	}
+	pubKey := privKey.Public()
 	caCertTemplate, err := getCACertTemplate(cfg, constants.GetCaAttribs(constants.Root).CommonName,
 		constants.GetCaAttribs(constants.Root).CommonName, pubKey)
 	if err != nil {
@@ -121,6 +127,13 @@ func (ca RootCa) updateConfig() error {
 	ca.CACertConfigPtr.Locality = ca.CACertConfig.Locality
 	ca.CACertConfigPtr.Province = ca.CACertConfig.Province
 	ca.CACertConfigPtr.Country = ca.CACertConfig.Country
+	ca.CACertConfigPtr.Pkcs11 = ca.CACertConfig.Pkcs11
+	if ca.CACertConfig.Pkcs11.Module != "" && ca.CACertConfig.Pkcs11.Pin != "" {
+		err := pkcs11.SavePin(ca.CACertConfig.Pkcs11.PinFile, ca.CACertConfig.Pkcs11.Pin)
+		if err != nil {
+			return errors.Wrap(err, "tasks/rootca:updateConfig() Could not save PKCS#11 token PIN")
+		}
+	}
 
 	return nil
 }
@@ -139,13 +152,9 @@ func (ca RootCa) Run() error {
 	if err != nil {
 		return errors.Wrap(err, "tasks/rootca:Run() Could not create root certificate")
 	}
-	key, err := x509.MarshalPKCS8PrivateKey(privKey)
-	if err != nil {
-		return errors.Wrap(err, "tasks/rootca:Run() Could not marshal private key to pkcs8 format error")
-	}
 
 	//Store key and certificate
-	err = crypt.SavePrivateKeyAsPKCS8(key, constants.RootCAKeyPath)
+	err = utils.SaveCaKey(&ca.CACertConfig, constants.GetCaAttribs(constants.Root), privKey)
 	if err != nil {
 		return errors.Wrap(err, "tasks/rootca:Run() Could not save root private key")
 	}
@@ -165,8 +174,8 @@ func (ca RootCa) Validate() error {
 	if os.IsNotExist(err) {
 		return errors.Wrap(err, "tasks/rootca:Validate() RootCACertFile is not configured")
 	}
-	_, err = os.Stat(constants.RootCAKeyPath)
-	if os.IsNotExist(err) {
+	err = utils.ValidateCaKey(ca.CACertConfigPtr, constants.GetCaAttribs(constants.Root))
+	if err != nil {
 		return errors.Wrap(err, "tasks/rootca:Validate() RootCAKeyFile is not configured")
 	}
 	return nil
@@ -10,6 +10,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"fmt"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/config"
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/constants"
 	"github.com/intel-secl/intel-secl/v3/pkg/cms/utils"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
@@ -30,6 +31,7 @@ type TLS struct {
 	ConsoleWriter    io.Writer
 	TLSCertDigestPtr *string
 	TLSSanList       string
+	CACertConfig     *config.CACertConfig
 	envPrefix        string
 	commandName      string
 }
@@ -125,8 +127,10 @@ func (ts TLS) Run() error {
 		}
 	}
 
-	tlsCaAttr := constants.GetCaAttribs(constants.Tls)
-	tlsCaCert, tlsCaPrivKey, err := crypt.LoadX509CertAndPrivateKey(tlsCaAttr.CertPath, tlsCaAttr.KeyPath)
+	tlsCaCert, tlsCaPrivKey, err := utils.LoadCaSigner(ts.CACertConfig, constants.Tls)
+	if err != nil {
+		return errors.Wrap(err, "tasks/tls:Run() Could not load TLS CA")
+	}
 	key, cert, err := createTLSCert(ts.TLSSanList, tlsCaCert, tlsCaPrivKey)
 	if err != nil {
 		return errors.Wrap(err, "tasks/tls:Run() Could not create TLS certificate")
@@ -152,7 +156,7 @@ func (ts TLS) Run() error {
 		return errors.Wrap(err, "tasks/tls:Run() Unable to get digest of TLS certificate")
 	}
 	*ts.TLSCertDigestPtr = tlsDigest
-	fmt.Println("TLS Certificate Digest : ", tlsDigest)
+	fmt.Fprintln(ts.ConsoleWriter, "TLS Certificate Digest : ", tlsDigest)
 
 	return nil
 }
@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package utils
+
+import (
+	"crypto"
+	"crypto/x509"
+	"os"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/pkcs11"
+	"github.com/pkg/errors"
+)
+
+// The CA keys are kept as PKCS8 files in the CMS configuration directory, or in a PKCS#11 token when one is
+// configured in the CA configuration. Keys in the token are labeled with the common name of the CA.
+
+// LoadCaSigner loads the certificate and the signing key of a CMS CA
+func LoadCaSigner(caCfg *config.CACertConfig, issuingCa string) (*x509.Certificate, crypto.Signer, error) {
+	caAttr := constants.GetCaAttribs(issuingCa)
+	if caAttr.CommonName == "" {
+		return nil, nil, errors.Errorf("utils/ca_keys:LoadCaSigner() Invalid issuing CA: %s", issuingCa)
+	}
+	return loadCaSigner(caCfg, caAttr)
+}
+
+func loadCaSigner(caCfg *config.CACertConfig, caAttr constants.CaAttrib) (*x509.Certificate, crypto.Signer, error) {
+	if !usePkcs11(caCfg) {
+		caCert, caPrivKey, err := crypt.LoadX509CertAndPrivateKey(caAttr.CertPath, caAttr.KeyPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		caSigner, ok := caPrivKey.(crypto.Signer)
+		if !ok {
+			return nil, nil, errors.New("utils/ca_keys:loadCaSigner() CA private key can not be used for signing")
+		}
+		return caCert, caSigner, nil
+	}
+
+	caCert, err := crypt.GetCertFromPemFile(caAttr.CertPath)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "utils/ca_keys:loadCaSigner() Could not load CA certificate")
+	}
+	token, err := openCaToken(caCfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	caSigner, err := token.FindKey(caAttr.CommonName)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "utils/ca_keys:loadCaSigner() Could not find CA key in PKCS#11 token")
+	}
+	return caCert, caSigner, nil
+}
+
+// GenerateCaKey generates a new key pair for a CMS CA. A key in the PKCS#11 token replaces the existing key of the CA.
+func GenerateCaKey(caCfg *config.CACertConfig, caAttr constants.CaAttrib) (crypto.Signer, error) {
+	if !usePkcs11(caCfg) {
+		privKey, _, err := crypt.GenerateKeyPair(constants.DefaultKeyAlgorithm, constants.DefaultKeyAlgorithmLength)
+		if err != nil {
+			return nil, errors.Wrap(err, "utils/ca_keys:GenerateCaKey() Could not generate key pair")
+		}
+		caSigner, ok := privKey.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("utils/ca_keys:GenerateCaKey() Generated key can not be used for signing")
+		}
+		return caSigner, nil
+	}
+
+	if constants.DefaultKeyAlgorithm != "rsa" {
+		return nil, errors.Errorf("utils/ca_keys:GenerateCaKey() Key algorithm %s is not supported with PKCS#11", constants.DefaultKeyAlgorithm)
+	}
+	token, err := openCaToken(caCfg)
+	if err != nil {
+		return nil, err
+	}
+	caSigner, err := token.GenerateRsaKey(caAttr.CommonName, constants.DefaultKeyAlgorithmLength)
+	if err != nil {
+		return nil, errors.Wrap(err, "utils/ca_keys:GenerateCaKey() Could not generate key pair in PKCS#11 token")
+	}
+	return caSigner, nil
+}
+
+// SaveCaKey stores a key generated with GenerateCaKey as the PKCS8 key file of the CA. Keys in a PKCS#11 token are
+// stored when they are generated, so that they never leave the token.
+func SaveCaKey(caCfg *config.CACertConfig, caAttr constants.CaAttrib, caKey crypto.Signer) error {
+	if usePkcs11(caCfg) {
+		return nil
+	}
+	key, err := x509.MarshalPKCS8PrivateKey(caKey)
+	if err != nil {
+		return errors.Wrap(err, "utils/ca_keys:SaveCaKey() Could not marshal private key to pkcs8 format")
+	}
+	return crypt.SavePrivateKeyAsPKCS8(key, caAttr.KeyPath)
+}
+
+// ValidateCaKey checks that the key of a CA exists
+func ValidateCaKey(caCfg *config.CACertConfig, caAttr constants.CaAttrib) error {
+	if !usePkcs11(caCfg) {
+		if _, err := os.Stat(caAttr.KeyPath); os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	token, err := openCaToken(caCfg)
+	if err != nil {
+		return err
+	}
+	_, err = token.FindKey(caAttr.CommonName)
+	return err
+}
+
+func usePkcs11(caCfg *config.CACertConfig) bool {
+	return caCfg != nil && caCfg.Pkcs11.Module != ""
+}
+
+func openCaToken(caCfg *config.CACertConfig) (*pkcs11.Token, error) {
+	pin, err := pkcs11.ReadPin(constants.CaPkcs11PinEnv, caCfg.Pkcs11.PinFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "utils/ca_keys:openCaToken() Could not read PKCS#11 token PIN")
+	}
+	token, err := pkcs11.OpenToken(caCfg.Pkcs11.Module, caCfg.Pkcs11.TokenLabel, pin)
+	if err != nil {
+		return nil, errors.Wrap(err, "utils/ca_keys:openCaToken() Could not open PKCS#11 token")
+	}
+	return token, nil
+}
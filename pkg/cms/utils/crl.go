@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package utils
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/cms/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/cms"
+	"github.com/pkg/errors"
+)
+
+// crlLock serializes the regeneration of CRLs so that CRL numbers are never reused
+var crlLock sync.Mutex
+
+// LoadCrl reads the last CRL published for the issuing CA
+func LoadCrl(issuingCa string) ([]byte, *x509.RevocationList, error) {
+	crlBytes, err := ioutil.ReadFile(getCrlPath(issuingCa))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "utils/crl:LoadCrl() Could not read CRL")
+	}
+	crl, err := x509.ParseRevocationList(crlBytes)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "utils/crl:LoadCrl() Could not parse CRL")
+	}
+	return crlBytes, crl, nil
+}
+
+// CreateCrl signs and publishes a new CRL for the issuing CA containing the provided revoked certificates
+func CreateCrl(caCfg *config.CACertConfig, issuingCa string, revokedCerts []cms.RevokedCertificate, validity time.Duration) ([]byte, error) {
+	crlLock.Lock()
+	defer crlLock.Unlock()
+
+	caAttr := constants.GetCaAttribs(issuingCa)
+	if caAttr.CommonName == "" {
+		return nil, errors.Errorf("utils/crl:CreateCrl() Invalid issuing CA: %s", issuingCa)
+	}
+	caCert, caSigner, err := loadCaSigner(caCfg, caAttr)
+	if err != nil {
+		return nil, errors.Wrap(err, "utils/crl:CreateCrl() Could not load issuing CA")
+	}
+
+	crlNumber := big.NewInt(1)
+	if _, lastCrl, err := LoadCrl(issuingCa); err == nil && lastCrl.Number != nil {
+		crlNumber.Add(crlNumber, lastCrl.Number)
+	}
+
+	var entries []x509.RevocationListEntry
+	for _, revokedCert := range revokedCerts {
+		serialNumber, ok := new(big.Int).SetString(revokedCert.SerialNumber, 16)
+		if !ok {
+			return nil, errors.Errorf("utils/crl:CreateCrl() Invalid serial number for revoked certificate: %s", revokedCert.SerialNumber)
+		}
+		reasonCode, _ := constants.GetRevocationReasonCode(revokedCert.Reason)
+		entries = append(entries, x509.RevocationListEntry{
+			SerialNumber:   serialNumber,
+			RevocationTime: revokedCert.RevokedAt,
+			ReasonCode:     reasonCode,
+		})
+	}
+
+	now := time.Now()
+	crlTemplate := &x509.RevocationList{
+		Number:                    crlNumber,
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(validity),
+		RevokedCertificateEntries: entries,
+	}
+	crlBytes, err := x509.CreateRevocationList(rand.Reader, crlTemplate, caCert, caSigner)
+	if err != nil {
+		return nil, errors.Wrap(err, "utils/crl:CreateCrl() Could not create CRL")
+	}
+
+	err = ioutil.WriteFile(getCrlPath(issuingCa), crlBytes, 0640)
+	if err != nil {
+		return nil, errors.Wrap(err, "utils/crl:CreateCrl() Could not save CRL")
+	}
+	return crlBytes, nil
+}
+
+// GetOcspIssuingCa returns the CMS CA whose public key hash matches the issuer key hash of an OCSP request
+func GetOcspIssuingCa(issuerKeyHash []byte, hashAlg crypto.Hash) (string, error) {
+	if !hashAlg.Available() {
+		return "", errors.New("utils/crl:GetOcspIssuingCa() Unsupported hash algorithm in OCSP request")
+	}
+	for _, issuingCa := range constants.GetIssuingCAs() {
+		caCert, err := crypt.GetCertFromPemFile(constants.GetCaAttribs(issuingCa).CertPath)
+		if err != nil {
+			return "", errors.Wrapf(err, "utils/crl:GetOcspIssuingCa() Could not load CA certificate - %s", issuingCa)
+		}
+		var publicKeyInfo struct {
+			Algorithm pkix.AlgorithmIdentifier
+			PublicKey asn1.BitString
+		}
+		if _, err := asn1.Unmarshal(caCert.RawSubjectPublicKeyInfo, &publicKeyInfo); err != nil {
+			return "", errors.Wrapf(err, "utils/crl:GetOcspIssuingCa() Could not parse public key of CA - %s", issuingCa)
+		}
+		hash := hashAlg.New()
+		hash.Write(publicKeyInfo.PublicKey.RightAlign())
+		if bytes.Equal(hash.Sum(nil), issuerKeyHash) {
+			return issuingCa, nil
+		}
+	}
+	return "", errors.New("utils/crl:GetOcspIssuingCa() OCSP request is not for a certificate issued by CMS")
+}
+
+func getCrlPath(issuingCa string) string {
+	return filepath.Join(constants.CrlDirPath, issuingCa+".crl")
+}
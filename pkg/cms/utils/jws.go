@@ -0,0 +1,239 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"hash"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+const minRsaKeyBits = 2048
+
+// JwsHeader is the protected header of a JWS sent to the ACME endpoints
+type JwsHeader struct {
+	Alg   string          `json:"alg"`
+	Nonce string          `json:"nonce,omitempty"`
+	Url   string          `json:"url"`
+	Kid   string          `json:"kid,omitempty"`
+	Jwk   json.RawMessage `json:"jwk,omitempty"`
+}
+
+// Jws is a JSON web signature in the flattened JSON serialization (RFC 7515 section 7.2.2)
+type Jws struct {
+	Header    JwsHeader
+	Payload   []byte
+	signature []byte
+	// signingInput is the protected header and payload in compact form over which the signature is computed
+	signingInput []byte
+}
+
+// ParseJws decodes a JWS in the flattened JSON serialization. The signature is not verified.
+func ParseJws(data []byte) (*Jws, error) {
+	var flattened struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+		Signature string `json:"signature"`
+	}
+	if err := json.Unmarshal(data, &flattened); err != nil {
+		return nil, errors.Wrap(err, "utils/jws:ParseJws() Could not decode JWS")
+	}
+	if flattened.Protected == "" || flattened.Signature == "" {
+		return nil, errors.New("utils/jws:ParseJws() JWS is missing the protected header or the signature")
+	}
+
+	protected, err := base64.RawURLEncoding.DecodeString(flattened.Protected)
+	if err != nil {
+		return nil, errors.Wrap(err, "utils/jws:ParseJws() Could not decode JWS protected header")
+	}
+	jws := Jws{signingInput: []byte(flattened.Protected + "." + flattened.Payload)}
+	if err = json.Unmarshal(protected, &jws.Header); err != nil {
+		return nil, errors.Wrap(err, "utils/jws:ParseJws() Could not decode JWS protected header")
+	}
+	if jws.Payload, err = base64.RawURLEncoding.DecodeString(flattened.Payload); err != nil {
+		return nil, errors.Wrap(err, "utils/jws:ParseJws() Could not decode JWS payload")
+	}
+	if jws.signature, err = base64.RawURLEncoding.DecodeString(flattened.Signature); err != nil {
+		return nil, errors.Wrap(err, "utils/jws:ParseJws() Could not decode JWS signature")
+	}
+	return &jws, nil
+}
+
+// Verify checks the signature of the JWS with the public key of the signer
+func (jws *Jws) Verify(publicKey crypto.PublicKey) error {
+	var hashAlg crypto.Hash
+	switch jws.Header.Alg {
+	case "RS256", "ES256":
+		hashAlg = crypto.SHA256
+	case "RS384", "ES384":
+		hashAlg = crypto.SHA384
+	case "RS512", "ES512":
+		hashAlg = crypto.SHA512
+	default:
+		return errors.Errorf("utils/jws:Verify() Unsupported JWS algorithm: %s", jws.Header.Alg)
+	}
+	hasher := hashAlg.New()
+	hasher.Write(jws.signingInput)
+	digest := hasher.Sum(nil)
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if jws.Header.Alg[:2] != "RS" {
+			return errors.New("utils/jws:Verify() JWS algorithm does not match the RSA key")
+		}
+		if err := rsa.VerifyPKCS1v15(key, hashAlg, digest, jws.signature); err != nil {
+			return errors.Wrap(err, "utils/jws:Verify() JWS signature verification failed")
+		}
+	case *ecdsa.PublicKey:
+		keyBytes := (key.Curve.Params().BitSize + 7) / 8
+		if jws.Header.Alg[:2] != "ES" || len(jws.signature) != 2*keyBytes {
+			return errors.New("utils/jws:Verify() JWS algorithm does not match the EC key")
+		}
+		r := new(big.Int).SetBytes(jws.signature[:keyBytes])
+		s := new(big.Int).SetBytes(jws.signature[keyBytes:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return errors.New("utils/jws:Verify() JWS signature verification failed")
+		}
+	default:
+		return errors.New("utils/jws:Verify() Unsupported JWS public key type")
+	}
+	return nil
+}
+
+// VerifyHmac checks the MAC of a JWS protected with a symmetric key, as used for external account binding
+func (jws *Jws) VerifyHmac(key []byte) error {
+	var newHash func() hash.Hash
+	switch jws.Header.Alg {
+	case "HS256":
+		newHash = sha256.New
+	case "HS384":
+		newHash = sha512.New384
+	case "HS512":
+		newHash = sha512.New
+	default:
+		return errors.Errorf("utils/jws:VerifyHmac() Unsupported JWS algorithm: %s", jws.Header.Alg)
+	}
+	mac := hmac.New(newHash, key)
+	mac.Write(jws.signingInput)
+	if !hmac.Equal(mac.Sum(nil), jws.signature) {
+		return errors.New("utils/jws:VerifyHmac() JWS MAC verification failed")
+	}
+	return nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJwk returns the public key of a RSA or EC JSON web key (RFC 7517)
+func ParseJwk(data []byte) (crypto.PublicKey, error) {
+	var jwk jsonWebKey
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return nil, errors.Wrap(err, "utils/jws:ParseJwk() Could not decode JWK")
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeJwkInt(jwk.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "utils/jws:ParseJwk() Invalid RSA modulus")
+		}
+		e, err := decodeJwkInt(jwk.E)
+		if err != nil || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("utils/jws:ParseJwk() Invalid RSA exponent")
+		}
+		if n.BitLen() < minRsaKeyBits {
+			return nil, errors.Errorf("utils/jws:ParseJwk() RSA key must be at least %d bits", minRsaKeyBits)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("utils/jws:ParseJwk() Unsupported EC curve: %s", jwk.Crv)
+		}
+		x, err := decodeJwkInt(jwk.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "utils/jws:ParseJwk() Invalid EC x coordinate")
+		}
+		y, err := decodeJwkInt(jwk.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, "utils/jws:ParseJwk() Invalid EC y coordinate")
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("utils/jws:ParseJwk() EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errors.Errorf("utils/jws:ParseJwk() Unsupported JWK key type: %s", jwk.Kty)
+	}
+}
+
+// JwkThumbprint computes the base64url encoded SHA-256 thumbprint of a JSON web key (RFC 7638)
+func JwkThumbprint(data []byte) (string, error) {
+	var jwk jsonWebKey
+	if err := json.Unmarshal(data, &jwk); err != nil {
+		return "", errors.Wrap(err, "utils/jws:JwkThumbprint() Could not decode JWK")
+	}
+
+	// only the required members of the key are hashed, serialized in lexicographic order without whitespace. The
+	// struct fields below are declared in that order
+	var canonical []byte
+	var err error
+	switch jwk.Kty {
+	case "RSA":
+		canonical, err = json.Marshal(struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N})
+	case "EC":
+		canonical, err = json.Marshal(struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Crv, jwk.Kty, jwk.X, jwk.Y})
+	default:
+		return "", errors.Errorf("utils/jws:JwkThumbprint() Unsupported JWK key type: %s", jwk.Kty)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "utils/jws:JwkThumbprint() Could not serialize JWK")
+	}
+	digest := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(digest[:]), nil
+}
+
+func decodeJwkInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, errors.New("missing value")
+	}
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
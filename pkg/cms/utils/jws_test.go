@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeJws(protected map[string]interface{}, payload []byte, sign func([]byte) []byte) []byte {
+	header, _ := json.Marshal(protected)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	jws, _ := json.Marshal(map[string]string{
+		"protected": base64.RawURLEncoding.EncodeToString(header),
+		"payload":   base64.RawURLEncoding.EncodeToString(payload),
+		"signature": base64.RawURLEncoding.EncodeToString(sign([]byte(signingInput))),
+	})
+	return jws
+}
+
+func TestJwkThumbprint(t *testing.T) {
+	// example key from RFC 7638 section 3.1
+	thumbprint, err := JwkThumbprint([]byte(`{"kty":"RSA","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw","e":"AQAB","alg":"RS256","kid":"2011-04-29"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+}
+
+func TestJwsVerifyRsa(t *testing.T) {
+	assertions := assert.New(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assertions.NoError(err)
+	jwk, _ := json.Marshal(map[string]string{
+		"kty": "RSA",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   "AQAB",
+	})
+	data := encodeJws(map[string]interface{}{"alg": "RS256", "nonce": "abc", "url": "https://cms/acme/new-account", "jwk": json.RawMessage(jwk)},
+		[]byte(`{"termsOfServiceAgreed":true}`), func(signingInput []byte) []byte {
+			digest := sha256.Sum256(signingInput)
+			signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+			return signature
+		})
+
+	jws, err := ParseJws(data)
+	assertions.NoError(err)
+	assertions.Equal("abc", jws.Header.Nonce)
+	assertions.Equal(`{"termsOfServiceAgreed":true}`, string(jws.Payload))
+
+	publicKey, err := ParseJwk(jws.Header.Jwk)
+	assertions.NoError(err)
+	assertions.NoError(jws.Verify(publicKey))
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	assertions.Error(jws.Verify(&otherKey.PublicKey))
+
+	jws.Header.Alg = "ES256"
+	assertions.Error(jws.Verify(publicKey), "algorithm must match the key type")
+}
+
+func TestJwsVerifyEcdsa(t *testing.T) {
+	assertions := assert.New(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assertions.NoError(err)
+	jwk, _ := json.Marshal(map[string]string{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	})
+	data := encodeJws(map[string]interface{}{"alg": "ES256", "nonce": "abc", "url": "https://cms/acme/new-order", "kid": "https://cms/acme/account/1"},
+		[]byte(`{}`), func(signingInput []byte) []byte {
+			digest := sha256.Sum256(signingInput)
+			r, s, _ := ecdsa.Sign(rand.Reader, key, digest[:])
+			return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		})
+
+	jws, err := ParseJws(data)
+	assertions.NoError(err)
+	publicKey, err := ParseJwk(jwk)
+	assertions.NoError(err)
+	assertions.NoError(jws.Verify(publicKey))
+
+	_, err = ParseJwk([]byte(`{"kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}`))
+	assertions.Error(err, "point that is not on the curve must be rejected")
+}
+
+func TestJwsVerifyHmac(t *testing.T) {
+	assertions := assert.New(t)
+
+	hmacKey := []byte("0123456789abcdef0123456789abcdef")
+	data := encodeJws(map[string]interface{}{"alg": "HS256", "kid": "eab-key", "url": "https://cms/acme/new-account"},
+		[]byte(`{"kty":"EC"}`), func(signingInput []byte) []byte {
+			mac := hmac.New(sha256.New, hmacKey)
+			mac.Write(signingInput)
+			return mac.Sum(nil)
+		})
+
+	jws, err := ParseJws(data)
+	assertions.NoError(err)
+	assertions.NoError(jws.VerifyHmac(hmacKey))
+	assertions.Error(jws.VerifyHmac([]byte("another key")))
+}
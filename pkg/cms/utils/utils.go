@@ -11,13 +11,20 @@ import (
 	"math/big"
 	"os"
 	"strings"
+	"sync"
 )
 
 func Message(status bool, message string) map[string]interface{} {
 	return map[string]interface{}{"status": status, "message": message}
 }
 
+// serialNumberLock makes sure that concurrent signing requests never get the same serial number
+var serialNumberLock sync.Mutex
+
 func GetNextSerialNumber() (*big.Int, error) {
+	serialNumberLock.Lock()
+	defer serialNumberLock.Unlock()
+
 	serialNumberNew, err := ReadSerialNumber()
 	if err != nil && strings.Contains(err.Error(), "no such file") {
 		serialNumberNew = big.NewInt(0)
@@ -94,6 +94,31 @@ func ValidateCertificateRequest(conf *config.Configuration, csr *x509.Certificat
 	return nil
 }
 
+//IsSanAuthorized is used to check if a SAN entry is part of the SAN list of a TLS CertApprover role
+func IsSanAuthorized(ctxMap *map[string]types.RoleInfo, san string) bool {
+	log.Trace("validation/validate_CSR:IsSanAuthorized() Entering")
+	defer log.Trace("validation/validate_CSR:IsSanAuthorized() Leaving")
+
+	if ctxMap == nil {
+		return false
+	}
+	for k, _ := range *ctxMap {
+		params := strings.Split(k, ";")
+		if len(params) < 3 || !strings.EqualFold(params[2], "CERTTYPE="+constants.Tls) {
+			continue
+		}
+		sans := strings.Split(params[1], "=")
+		if len(sans) > 1 {
+			for _, tokenSan := range strings.Split(sans[1], ",") {
+				if strings.EqualFold(tokenSan, san) || search.WildcardMatched(san, tokenSan) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 func validateDNSNames(list []string) error {
 	log.Trace("validation/validate_CSR:validateDNSNames() Entering")
 	defer log.Trace("validation/validate_CSR:validateDNSNames() Leaving")
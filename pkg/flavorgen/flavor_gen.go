@@ -18,11 +18,10 @@ import (
 	"github.com/intel-secl/intel-secl/v3/pkg/flavorgen/version"
 	controller "github.com/intel-secl/intel-secl/v3/pkg/hvs/controllers"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/flavor/types"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/flavor/util"
 	hcType "github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector/types"
 	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
 	"github.com/pkg/errors"
-
-	"github.com/antchfx/jsonquery"
 )
 
 type FlavorGen struct{}
@@ -89,9 +88,8 @@ func processJsonFile(manifestFilepath string, flavorTemplates []string) (hcType.
 		return hcType.HostManifest{}, nil, errors.New("flavorgen/flavor_gen:processJsonFile() Could not unmarshal host manifest json")
 	}
 
-	manifest, err := jsonquery.Parse(bytes.NewReader(hostManifestJSON))
+	conditionEvaluator, err := util.NewFlavorTemplateConditionEvaluator(&hostManifest)
 	if err != nil {
-		fmt.Errorf("Could not parse host manifest json %s", err)
 		return hcType.HostManifest{}, nil, errors.Wrap(err, "flavorgen/flavor_gen:processJsonFile() Could not parse host manifest json")
 	}
 
@@ -116,19 +114,11 @@ func processJsonFile(manifestFilepath string, flavorTemplates []string) (hcType.
 			}
 		}
 
-		conditionEval := false
-		for _, condition := range flavorTemplate.Condition {
-			expectedData, err := jsonquery.Query(manifest, condition)
-			if err != nil {
-				return hcType.HostManifest{}, nil, errors.Wrap(err, "flavorgen/flavor_gen:processJsonFile() Failed to query search condition with hostmanifest")
-			}
-			if expectedData == nil {
-				fmt.Println(flavorTemplate.Condition)
-				conditionEval = true
-				break
-			}
+		conditionEval, err := conditionEvaluator.Matches(&flavorTemplate)
+		if err != nil {
+			return hcType.HostManifest{}, nil, errors.Wrap(err, "flavorgen/flavor_gen:processJsonFile() Failed to query search condition with hostmanifest")
 		}
-		if !conditionEval {
+		if conditionEval {
 			flavors = append(flavors, flavorTemplate)
 		}
 	}
@@ -165,6 +155,7 @@ flavorgen <command> [arguments]
 Available Commands:
 	-f                     To provide Flavor template json file
 	-m                     To provide Hostmanifest json file
+	-e                     To provide the TCG binary event log of the host, replaces the event log of the Hostmanifest
 	help|-h|--help         Show this help message
 	-log                   To log the execution
 	-version               print the current version
@@ -213,6 +204,7 @@ func (flavorgen FlavorGen) GenerateFlavors() {
 	// the flag's name, the default value, and a short description (displayed whith the option --help)
 	flag.Var(&flavortemplateargs, "f", "flavor-template json file")
 	manifestFilePath := flag.String("m", "", "host-manifest json file")
+	eventLogFilePath := flag.String("e", "", "TCG binary event log file")
 	versionFlag := flag.Bool("version", false, "Print the current version and exit")
 
 	// Showing useful information when the user enters the --help option
@@ -264,6 +256,14 @@ func (flavorgen FlavorGen) GenerateFlavors() {
 		exitGracefully(errors.New("Error finding matching templates"))
 	}
 
+	if *eventLogFilePath != "" {
+		err = loadTcgEventLog(*eventLogFilePath, &hostmanifest)
+		if err != nil {
+			defaultLog.Infof("flavorgen/flavor_gen:main() Error loading the TCG event log %s", err)
+			exitGracefully(errors.Wrap(err, "Error loading the TCG event log"))
+		}
+	}
+
 	var rp types.PlatformFlavor
 	rp = types.NewHostPlatformFlavor(&hostmanifest, nil, flavorTemplates)
 
@@ -275,6 +275,30 @@ func (flavorgen FlavorGen) GenerateFlavors() {
 	}
 }
 
+// loadTcgEventLog replaces the event log of the host manifest by the TCG binary event log read from the file, like
+// /sys/kernel/security/tpm0/binary_bios_measurements, after checking that it replays to the PCRs of the manifest
+func loadTcgEventLog(eventLogFilePath string, hostManifest *hcType.HostManifest) error {
+	defaultLog.Trace("flavorgen/flavor_gen:loadTcgEventLog() Entering")
+	defer defaultLog.Trace("flavorgen/flavor_gen:loadTcgEventLog() Leaving")
+
+	eventLog, err := ioutil.ReadFile(eventLogFilePath)
+	if err != nil {
+		return errors.Wrap(err, "flavorgen/flavor_gen:loadTcgEventLog() Could not read the event log file")
+	}
+
+	pcrEventLogMap, err := hcType.ParseTcgEventLog(eventLog)
+	if err != nil {
+		return errors.Wrap(err, "flavorgen/flavor_gen:loadTcgEventLog() Could not parse the event log")
+	}
+	hostManifest.PcrManifest.PcrEventLogMap = pcrEventLogMap
+
+	err = hostManifest.PcrManifest.VerifyEventLogReplay()
+	if err != nil {
+		return errors.Wrap(err, "flavorgen/flavor_gen:loadTcgEventLog() Event log does not match the host manifest")
+	}
+	return nil
+}
+
 //readJson method is used to read the file from input file path and validate
 func readJson(filePath string) ([]byte, error) {
 	// Read the input file
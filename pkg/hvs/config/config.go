@@ -40,6 +40,8 @@ type Configuration struct {
 	HRRS   hrrs.HRRSConfig         `yaml:"hrrs" mapstructure:"hrrs"`
 	FVS    FVSConfig               `yaml:"fvs" mapstructure:"fvs"`
 	VCSS   VCSSConfig              `yaml:"vcss" mapstructure:"vcss"`
+
+	Notification NotificationConfig `yaml:"notification" mapstructure:"notification"`
 }
 
 type HVSConfig struct {
@@ -66,6 +68,11 @@ type AuditLogConfig struct {
 	BufferSize  int `yaml:"buffer-size" mapstructure:"buffer-size"`
 }
 
+type NotificationConfig struct {
+	// AllowedNetworks lists the private networks, in CIDR notation, the trust change notifications can be posted to
+	AllowedNetworks []string `yaml:"allowed-networks" mapstructure:"allowed-networks"`
+}
+
 type VCSSConfig struct {
 	// RefreshPeriod determines how frequently the VCSS checks the vCenter cluster for updated hosts
 	RefreshPeriod time.Duration `yaml:"refresh-period" mapstructure:"refresh-period"`
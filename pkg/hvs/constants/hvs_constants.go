@@ -146,7 +146,13 @@ const (
 
 // jwt constants
 const (
-	JWTCertsCacheTime = "1m"
+	JWTCertsCacheTime       = "1m"
+	RevocationListCacheTime = "1m"
+	// every token is rejected once the revocation list could not be retrieved for this long
+	RevocationListMaxStaleness = "10m"
+	RevocationListTimeout      = "10s"
+	// the JWK set of AAS is retrieved with this timeout, the token validation waits for it
+	JwksTimeout = "10s"
 )
 
 // FVS constants
@@ -156,6 +162,11 @@ const (
 	DefaultSkipFlavorSignatureVerification = false
 )
 
+// push-mode host constants
+const (
+	DefaultHostQuoteNonceValidity = time.Duration(5) * time.Minute
+)
+
 //VCSS constants
 const (
 	DefaultVcssRefreshPeriod = time.Duration(2) * time.Minute
@@ -168,6 +179,29 @@ const (
 	DefaultChannelBufferSize = 5000
 )
 
+// trust change notification constants
+const (
+	DefaultNotificationQueueSize    = 1000
+	DefaultNotificationMaxAttempts  = 5
+	DefaultNotificationRetryBackoff = time.Duration(5) * time.Second
+	DefaultNotificationTimeout      = time.Duration(10) * time.Second
+	DefaultNotificationSearchLimit  = 100
+	NotificationSecretLength        = 32
+
+	NotificationEventHeader     = "X-Hvs-Event"
+	NotificationDeliveryHeader  = "X-Hvs-Delivery"
+	NotificationSignatureHeader = "X-Hvs-Signature"
+)
+
+// report stream constants
+const (
+	DefaultReportStreamBufferSize = 100
+	ReportStreamHeartbeatInterval = time.Duration(15) * time.Second
+	// streams are closed this long before the server write timeout, the clients reconnect with the last event ID
+	ReportStreamWriteTimeoutMargin = time.Duration(5) * time.Second
+	ReportStreamRetryMilliseconds  = 3000
+)
+
 // Search APIs filter constants
 const (
 	MaxNumDaysSearchLimit = 365
@@ -29,6 +29,8 @@ const (
 	HostDelete   = "hosts:delete"
 	HostSearch   = "hosts:search"
 
+	HostQuoteCreate = "host_quotes:create"
+
 	//FlavorTemplate Permissions.
 	FlavorTemplateCreate   = "flavor-template:create"
 	FlavorTemplateRetrieve = "flavor-template:retrieve"
@@ -61,6 +63,11 @@ const (
 	ReportRetrieve = "reports:retrieve"
 	ReportSearch   = "reports:search"
 
+	NotificationSubscriptionCreate   = "notification_subscriptions:create"
+	NotificationSubscriptionRetrieve = "notification_subscriptions:retrieve"
+	NotificationSubscriptionSearch   = "notification_subscriptions:search"
+	NotificationSubscriptionDelete   = "notification_subscriptions:delete"
+
 	// AssetTagAPI
 	TagCertificateCreate = "tag_certificates:create"
 	TagCertificateDelete = "tag_certificates:delete"
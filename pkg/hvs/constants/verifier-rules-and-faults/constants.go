@@ -22,6 +22,7 @@ const (
 	RuleXmlMeasurementLogEquals     = RulePrefix + "XmlMeasurementLogEquals"
 	RulePcrEventLogEqualsExcluding  = RulePrefix + "PcrEventLogEqualsExcluding"
 	RuleXmlMeasurementLogIntegrity  = RulePrefix + "XmlMeasurementLogIntegrity"
+	RulePcrEventLogReplayAllBanks   = RulePrefix + "PcrEventLogReplayAllBanks"
 )
 
 // Verifier Faults
@@ -71,7 +72,8 @@ const (
 
 //Rule names
 const (
-	EventlogEqualRule    = "EventlogEqual"
-	EventlogIncludesRule = "EventlogIncludes"
-	PCRMatchesRule       = "PCRMatches"
+	EventlogEqualRule          = "EventlogEqual"
+	EventlogIncludesRule       = "EventlogIncludes"
+	PCRMatchesRule             = "PCRMatches"
+	EventlogReplayAllBanksRule = "EventlogReplayAllBanks"
 )
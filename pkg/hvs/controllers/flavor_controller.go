@@ -14,7 +14,6 @@ import (
 	"reflect"
 	"strings"
 
-	"github.com/antchfx/jsonquery"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	consts "github.com/intel-secl/intel-secl/v3/pkg/hvs/constants"
@@ -388,27 +387,19 @@ func (fcon *FlavorController) findTemplatesToApply(hostManifest *hcType.HostMani
 		return nil, errors.Wrap(err, "controllers/flavor_controller:findTemplatesToApply() Error retrieving all flavor templates")
 	}
 
-	hostManifestBytes, err := json.Marshal(hostManifest)
-	if err != nil {
-		return nil, errors.Wrap(err, "controllers/flavor_controller:findTemplatesToApply() Error Marshalling hostmanifest")
-	}
-
-	hostManifestJSON, err := jsonquery.Parse(strings.NewReader(string(hostManifestBytes)))
+	conditionEvaluator, err := fu.NewFlavorTemplateConditionEvaluator(hostManifest)
 	if err != nil {
 		return nil, errors.Wrap(err, "controllers/flavor_controller:findTemplatesToApply() Error in parsing the host manifest")
 	}
 
-	for _, flavorTemplate := range flavorTemplates {
-		conditionEval := true
-		for _, condition := range flavorTemplate.Condition {
-			expectedData, _ := jsonquery.Query(hostManifestJSON, condition)
-			if expectedData == nil {
-				conditionEval = false
-				break
-			}
+	for i := range flavorTemplates {
+		conditionEval, err := conditionEvaluator.Matches(&flavorTemplates[i])
+		if err != nil {
+			defaultLog.WithError(err).Warnf("controllers/flavor_controller:findTemplatesToApply() Skipping flavor template %s", flavorTemplates[i].ID)
+			continue
 		}
 		if conditionEval == true {
-			filteredTemplates = append(filteredTemplates, flavorTemplate)
+			filteredTemplates = append(filteredTemplates, flavorTemplates[i])
 		}
 	}
 
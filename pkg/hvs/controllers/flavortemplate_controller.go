@@ -25,6 +25,7 @@ import (
 	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
 	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/validation"
+	fu "github.com/intel-secl/intel-secl/v3/pkg/lib/flavor/util"
 	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
 	"github.com/pkg/errors"
 	"github.com/xeipuuv/gojsonschema"
@@ -229,7 +230,7 @@ func (ftc *FlavorTemplateController) getFlavorTemplateCreateReq(r *http.Request)
 		return createFlavorTemplateReq, &commErr.BadRequestError{Message: errMsg}
 	}
 
-	if len(createFlavorTemplateReq.Condition) == 0 {
+	if len(createFlavorTemplateReq.Condition) == 0 && createFlavorTemplateReq.ConditionExpression == "" {
 		defaultLog.WithError(err).Error("controllers/flavortemplate_controller:getFlavorTemplateCreateReq() Unable to create flavor template, empty condition field provided")
 		return hvs.FlavorTemplate{}, &commErr.BadRequestError{Message: "Unable to create flavor template, empty condition field provided"}
 	}
@@ -299,6 +300,13 @@ func (ftc *FlavorTemplateController) ValidateFlavorTemplateCreateRequest(FlvrTem
 		}
 	}
 
+	if FlvrTemp.ConditionExpression != "" {
+		_, err := fu.CompileConditionExpression(FlvrTemp.ConditionExpression)
+		if err != nil {
+			return "Invalid condition expression:\n" + err.Error(), errors.Wrap(err, "controllers/flavortemplate_controller:validateFlavorTemplateCreateRequest() Invalid condition expression")
+		}
+	}
+
 	//Check whether each pcr index is associated with not more than one bank.
 	pcrMap := make(map[*hvs.FlavorPart][]hvs.PCR)
 	flavorParts := []*hvs.FlavorPart{FlvrTemp.FlavorParts.Platform, FlvrTemp.FlavorParts.OS, FlvrTemp.FlavorParts.HostUnique}
@@ -148,6 +148,123 @@ var _ = Describe("FlavorTemplateController", func() {
 			})
 		})
 
+		Context("Provide a FlavorTemplate data with a condition expression instead of a condition", func() {
+			It("Should create a new Flavortemplate and get HTTP Status: 201", func() {
+				router.Handle("/flavor-templates", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(flavorTemplateController.Create))).Methods("POST")
+				flavorTemplateJson := `{
+					"label": "default-linux",
+					"condition_expression": "host_manifest.host_info.os_name == 'RedHatEnterprise' && host_manifest.host_info.hardware_features.TPM.meta.tpm_version == '2.0'",
+					"flavor_parts": {
+						"PLATFORM": {
+							"meta": {
+								"tpm_version": "2.0",
+								"vendor": "Linux"
+							},
+							"pcr_rules": [
+								{
+									"pcr": {
+										"index": 0,
+										"bank": "SHA256"
+									},
+									"pcr_matches": true
+								}
+							]
+						}
+					}
+				}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/flavor-templates",
+					strings.NewReader(flavorTemplateJson),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusCreated))
+			})
+		})
+
+		Context("Provide a FlavorTemplate data with a condition expression that does not compile", func() {
+			It("Should get HTTP Status: 400 with the compile error", func() {
+				router.Handle("/flavor-templates", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(flavorTemplateController.Create))).Methods("POST")
+				flavorTemplateJson := `{
+					"label": "default-linux",
+					"condition_expression": "host_manifest.host_info.os_name = 'RedHatEnterprise'",
+					"flavor_parts": {
+						"PLATFORM": {
+							"meta": {
+								"tpm_version": "2.0",
+								"vendor": "Linux"
+							},
+							"pcr_rules": [
+								{
+									"pcr": {
+										"index": 0,
+										"bank": "SHA256"
+									},
+									"pcr_matches": true
+								}
+							]
+						}
+					}
+				}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/flavor-templates",
+					strings.NewReader(flavorTemplateJson),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(400))
+				Expect(w.Body.String()).To(ContainSubstring("Syntax error"))
+			})
+		})
+
+		Context("Provide a FlavorTemplate data without a condition or a condition expression", func() {
+			It("Should get HTTP Status: 400", func() {
+				router.Handle("/flavor-templates", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(flavorTemplateController.Create))).Methods("POST")
+				flavorTemplateJson := `{
+					"label": "default-linux",
+					"flavor_parts": {
+						"PLATFORM": {
+							"meta": {
+								"tpm_version": "2.0",
+								"vendor": "Linux"
+							},
+							"pcr_rules": [
+								{
+									"pcr": {
+										"index": 0,
+										"bank": "SHA256"
+									},
+									"pcr_matches": true
+								}
+							]
+						}
+					}
+				}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/flavor-templates",
+					strings.NewReader(flavorTemplateJson),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(400))
+			})
+		})
+
 		Context("Provide a empty data that should give bad request error", func() {
 			It("Should get HTTP Status: 400", func() {
 				router.Handle("/flavor-templates", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(flavorTemplateController.Create))).Methods("POST")
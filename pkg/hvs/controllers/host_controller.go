@@ -216,11 +216,16 @@ func (hc *HostController) CreateHost(reqHost hvs.HostCreateRequest) (interface{}
 	defaultLog.Trace("controllers/host_controller:CreateHost() Entering")
 	defer defaultLog.Trace("controllers/host_controller:CreateHost() Leaving")
 
-	if reqHost.HostName == "" || reqHost.ConnectionString == "" {
+	if reqHost.HostName == "" || (reqHost.ConnectionString == "" && !reqHost.PushMode) {
 		secLog.Error("controllers/host_controller:CreateHost() Host connection string and host name must be specified")
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Host connection string and host name must be specified"}
 	}
 
+	if reqHost.PushMode && reqHost.ConnectionString != "" {
+		secLog.Error("controllers/host_controller:CreateHost() Host connection string must not be specified for push-mode hosts")
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Host connection string must not be specified for push-mode hosts"}
+	}
+
 	if err := validateHostCreateCriteria(reqHost); err != nil {
 		secLog.WithError(err).Errorf("controllers/host_controller:CreateHost() %s Invalid host data", commLogMsg.InvalidInputBadParam)
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid host data"}
@@ -239,6 +244,10 @@ func (hc *HostController) CreateHost(reqHost hvs.HostCreateRequest) (interface{}
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Host with this name already exist"}
 	}
 
+	if reqHost.PushMode {
+		return hc.createPushModeHost(reqHost)
+	}
+
 	connectionString, credential, err := GenerateConnectionString(reqHost.ConnectionString,
 		hc.HCConfig.Username,
 		hc.HCConfig.Password,
@@ -337,15 +346,54 @@ func (hc *HostController) CreateHost(reqHost hvs.HostCreateRequest) (interface{}
 	return createdHost, http.StatusCreated, nil
 }
 
+// createPushModeHost registers a host that submits its quotes to HVS. HVS never connects to these hosts, so there
+// are no credentials to store and the host is verified once it submits its first quote.
+func (hc *HostController) createPushModeHost(reqHost hvs.HostCreateRequest) (interface{}, int, error) {
+	defaultLog.Trace("controllers/host_controller:createPushModeHost() Entering")
+	defer defaultLog.Trace("controllers/host_controller:createPushModeHost() Leaving")
+
+	fgNames := reqHost.FlavorgroupNames
+	if len(fgNames) == 0 {
+		defaultLog.Debug("Flavorgroup names not present in request, associating with default ones")
+		fgNames = []string{models.FlavorGroupsAutomatic.String()}
+	}
+
+	createdHost, err := hc.HStore.Create(&hvs.Host{
+		HostName:         reqHost.HostName,
+		Description:      reqHost.Description,
+		FlavorgroupNames: fgNames,
+		PushMode:         true,
+	})
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/host_controller:createPushModeHost() Host create failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to create Host"}
+	}
+
+	defaultLog.Debugf("Associating host %s with flavorgroups %+q", reqHost.HostName, fgNames)
+	if err := hc.linkFlavorgroupsToHost(fgNames, createdHost.Id); err != nil {
+		defaultLog.WithError(err).Error("controllers/host_controller:createPushModeHost() Host FlavorGroup association failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to associate Host with flavorgroups"}
+	}
+
+	return createdHost, http.StatusCreated, nil
+}
+
 func (hc *HostController) UpdateHost(reqHost hvs.Host) (interface{}, int, error) {
 	defaultLog.Trace("controllers/host_controller:UpdateHost() Entering")
 	defer defaultLog.Trace("controllers/host_controller:UpdateHost() Leaving")
 
-	_, status, err := hc.retrieveHost(reqHost.Id)
+	existingHost, status, err := hc.retrieveHost(reqHost.Id)
 	if err != nil {
 		return nil, status, err
 	}
 
+	// the push mode of a host can only be chosen when the host is created
+	reqHost.PushMode = existingHost.(*hvs.Host).PushMode
+	if reqHost.PushMode && reqHost.ConnectionString != "" {
+		secLog.Error("controllers/host_controller:UpdateHost() Host connection string must not be specified for push-mode hosts")
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Host connection string must not be specified for push-mode hosts"}
+	}
+
 	if reqHost.ConnectionString != "" {
 		connectionString, credential, err := GenerateConnectionString(reqHost.ConnectionString,
 			hc.HCConfig.Username,
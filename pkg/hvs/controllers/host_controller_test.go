@@ -132,6 +132,56 @@ var _ = Describe("HostController", func() {
 				Expect(w.Code).To(Equal(http.StatusBadRequest))
 			})
 		})
+		Context("Provide a Create request for a push-mode host", func() {
+			It("Should create a new push-mode Host without connecting to it", func() {
+				router.Handle("/hosts", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostController.Create))).Methods("POST")
+				hostJson := `{
+								"host_name": "edge-host1",
+								"push_mode": true,
+								"description": "Host behind NAT"
+							}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/hosts",
+					strings.NewReader(hostJson),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusCreated))
+
+				var host hvs.Host
+				err = json.Unmarshal(w.Body.Bytes(), &host)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(host.PushMode).To(BeTrue())
+				Expect(host.ConnectionString).To(BeEmpty())
+			})
+		})
+		Context("Provide a Create request for a push-mode host with connection string", func() {
+			It("Should fail to create new Host", func() {
+				router.Handle("/hosts", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostController.Create))).Methods("POST")
+				hostJson := `{
+								"host_name": "edge-host1",
+								"push_mode": true,
+								"connection_string": "intel:https://ta.ip.com:1443"
+							}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/hosts",
+					strings.NewReader(hostJson),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
 		Context("Provide a Create request that contains malformed connection string", func() {
 			It("Should fail to create new Host", func() {
 				router.Handle("/hosts", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostController.Create))).Methods("POST")
@@ -0,0 +1,250 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"context"
+	"crypto"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/lib/common/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector/types"
+	hcUtil "github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector/util"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// HostQuoteController receives the quotes of push-mode hosts, which HVS cannot connect to. The host first requests
+// a nonce and then submits a quote over it, which is verified and queued for flavor verification like the manifests
+// fetched from other hosts. A host is bound to the hardware UUID and the AIK of its first accepted quote.
+type HostQuoteController struct {
+	HStore    domain.HostStore
+	HSStore   domain.HostStatusStore
+	HQStore   domain.HostQuoteStore
+	HTManager domain.HostTrustManager
+	CertStore *models.CertificatesStore
+	// NonceValidity is the time a host has to submit its quote, requesting a new nonce replaces the previous one
+	NonceValidity time.Duration
+}
+
+func NewHostQuoteController(hs domain.HostStore, hss domain.HostStatusStore, hqs domain.HostQuoteStore,
+	htm domain.HostTrustManager, certStore *models.CertificatesStore, nonceValidity time.Duration) *HostQuoteController {
+	return &HostQuoteController{
+		HStore:        hs,
+		HSStore:       hss,
+		HQStore:       hqs,
+		HTManager:     htm,
+		CertStore:     certStore,
+		NonceValidity: nonceValidity,
+	}
+}
+
+func (controller *HostQuoteController) CreateNonce(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/host_quote_controller:CreateNonce() Entering")
+	defer defaultLog.Trace("controllers/host_quote_controller:CreateNonce() Leaving")
+
+	id := uuid.MustParse(mux.Vars(r)["hId"])
+	if _, status, err := controller.retrievePushModeHost(id); err != nil {
+		return nil, status, err
+	}
+
+	nonce, err := hcUtil.GenerateNonce(20)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/host_quote_controller:CreateNonce() Error generating nonce")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to create nonce"}
+	}
+	quoteNonce := hvs.HostQuoteNonce{
+		Nonce:      nonce,
+		Expiration: time.Now().Add(controller.NonceValidity),
+	}
+
+	if err := controller.HQStore.CreateNonce(id, &quoteNonce); err != nil {
+		defaultLog.WithError(err).Error("controllers/host_quote_controller:CreateNonce() Quote nonce create failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to create nonce"}
+	}
+
+	secLog.WithField("host_id", id).Infof("Quote nonce created by: %s", r.RemoteAddr)
+	return quoteNonce, http.StatusCreated, nil
+}
+
+func (controller *HostQuoteController) Submit(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/host_quote_controller:Submit() Entering")
+	defer defaultLog.Trace("controllers/host_quote_controller:Submit() Leaving")
+
+	if r.Header.Get("Content-Type") != consts.HTTPMediaTypeJson {
+		return nil, http.StatusUnsupportedMediaType, &commErr.ResourceError{Message: "Invalid Content-Type"}
+	}
+
+	if r.ContentLength == 0 {
+		secLog.Error("controllers/host_quote_controller:Submit() The request body was not provided")
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body was not provided"}
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	var hostQuote hvs.HostQuote
+	if err := dec.Decode(&hostQuote); err != nil {
+		secLog.WithError(err).Errorf("controllers/host_quote_controller:Submit() %s :  Failed to decode request body as HostQuote", commLogMsg.InvalidInputBadEncoding)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Unable to decode JSON request body"}
+	}
+
+	id := uuid.MustParse(mux.Vars(r)["hId"])
+	host, status, err := controller.retrievePushModeHost(id)
+	if err != nil {
+		return nil, status, err
+	}
+
+	// a nonce can only be used once, whether the quote verifies or not
+	issuedNonce, err := controller.HQStore.ConsumeNonce(id)
+	if err != nil && !strings.Contains(err.Error(), commErr.RowsNotFound) {
+		defaultLog.WithError(err).Error("controllers/host_quote_controller:Submit() Quote nonce retrieve failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve quote nonce"}
+	}
+	if issuedNonce == nil || time.Now().After(issuedNonce.Expiration) ||
+		subtle.ConstantTimeCompare([]byte(issuedNonce.Nonce), []byte(hostQuote.Nonce)) != 1 {
+		secLog.WithField("host_id", id).Errorf("controllers/host_quote_controller:Submit() %s : Quote nonce is invalid or expired", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Quote nonce is invalid or expired"}
+	}
+
+	hostManifest, aikCertificate, err := controller.verifyQuote(issuedNonce.Nonce, &hostQuote)
+	if err != nil {
+		secLog.WithError(err).WithField("host_id", id).Errorf("controllers/host_quote_controller:Submit() %s : Quote verification failed", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Failed to verify host quote"}
+	}
+
+	// the hardware UUID is not covered by the quote, a host must not report the UUID of another host
+	hwUuid, err := uuid.Parse(hostManifest.HostInfo.HardwareUUID)
+	if err != nil || (host.HardwareUuid != nil && *host.HardwareUuid != hwUuid) {
+		secLog.WithField("host_id", id).Errorf("controllers/host_quote_controller:Submit() %s : Hardware UUID %s does not match the host",
+			commLogMsg.InvalidInputBadParam, hostManifest.HostInfo.HardwareUUID)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Hardware UUID does not match the host"}
+	}
+
+	// the first accepted AIK is pinned, a quote signed by any other AIK certified by the Privacy CA is rejected
+	aikCertificateDigest, err := crypt.GetCertHashInHex(aikCertificate, crypto.SHA384)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/host_quote_controller:Submit() Error computing AIK certificate digest")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to verify host quote"}
+	}
+	pinnedDigest, err := controller.HQStore.PinAikCertificate(id, aikCertificateDigest)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/host_quote_controller:Submit() AIK certificate pin failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to verify host quote"}
+	}
+	if subtle.ConstantTimeCompare([]byte(pinnedDigest), []byte(aikCertificateDigest)) != 1 {
+		secLog.WithField("host_id", id).Errorf("controllers/host_quote_controller:Submit() %s : AIK certificate does not match the AIK pinned for the host",
+			commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "AIK certificate does not match the host"}
+	}
+
+	if host.HardwareUuid == nil {
+		host.HardwareUuid = &hwUuid
+		if err := controller.HStore.Update(host); err != nil {
+			defaultLog.WithError(err).Error("controllers/host_quote_controller:Submit() Host update failed")
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to update Host"}
+		}
+	}
+
+	err = controller.HSStore.Persist(&hvs.HostStatus{
+		HostID: id,
+		HostStatusInformation: hvs.HostStatusInformation{
+			HostState:         hvs.HostStateConnected,
+			LastTimeConnected: time.Now(),
+		},
+		HostManifest: *hostManifest,
+	})
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/host_quote_controller:Submit() Host status persist failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to store host manifest"}
+	}
+
+	if err := controller.HTManager.ProcessHostData(context.Background(), *host, hostManifest, nil); err != nil {
+		defaultLog.WithError(err).Error("controllers/host_quote_controller:Submit() Host to Flavor Verify Queue addition failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to add Host to Flavor Verify Queue"}
+	}
+
+	secLog.WithField("host_id", id).Infof("Host quote submitted by: %s", r.RemoteAddr)
+	return nil, http.StatusAccepted, nil
+}
+
+func (controller *HostQuoteController) retrievePushModeHost(id uuid.UUID) (*hvs.Host, int, error) {
+	defaultLog.Trace("controllers/host_quote_controller:retrievePushModeHost() Entering")
+	defer defaultLog.Trace("controllers/host_quote_controller:retrievePushModeHost() Leaving")
+
+	hosts, err := controller.HStore.Search(&models.HostFilterCriteria{Id: id})
+	if err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error("controllers/host_quote_controller:retrievePushModeHost() Host search failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve Host from database"}
+	}
+	if len(hosts) == 0 {
+		defaultLog.WithField("id", id).Error("controllers/host_quote_controller:retrievePushModeHost() Host with specified id could not be located")
+		return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Host with specified id does not exist"}
+	}
+	if !hosts[0].PushMode {
+		secLog.WithField("id", id).Errorf("controllers/host_quote_controller:retrievePushModeHost() %s : Host is not a push-mode host", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Host is not a push-mode host"}
+	}
+	return hosts[0], http.StatusOK, nil
+}
+
+// verifyQuote checks that the AIK was certified by the Privacy CA and that it signed the quote over the nonce, then
+// builds the host manifest from the quote
+func (controller *HostQuoteController) verifyQuote(nonce string, hostQuote *hvs.HostQuote) (*types.HostManifest, *x509.Certificate, error) {
+	defaultLog.Trace("controllers/host_quote_controller:verifyQuote() Entering")
+	defer defaultLog.Trace("controllers/host_quote_controller:verifyQuote() Leaving")
+
+	aikCertificate, err := x509.ParseCertificate(hostQuote.AikCertificate)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Could not parse AIK certificate")
+	}
+	_, privacyCaCerts, err := controller.CertStore.GetKeyAndCertificates(models.CaCertTypesPrivacyCa.String())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Could not get Privacy CA certificates")
+	}
+	privacyCaPool := x509.NewCertPool()
+	for i := range privacyCaCerts {
+		privacyCaPool.AddCert(&privacyCaCerts[i])
+	}
+	if _, err := aikCertificate.Verify(x509.VerifyOptions{Roots: privacyCaPool}); err != nil {
+		return nil, nil, errors.Wrap(err, "AIK certificate is not issued by the Privacy CA")
+	}
+
+	if len(hostQuote.Quote) == 0 || len(hostQuote.Quote) > 0xffff || len(hostQuote.Signature) == 0 {
+		return nil, nil, errors.New("Quote and signature must be specified")
+	}
+	nonceInBytes, err := base64.StdEncoding.DecodeString(nonce)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Could not decode nonce")
+	}
+	eventLog := hostQuote.EventLog
+	if eventLog == "" {
+		eventLog = "[]"
+	}
+	pcrManifest, err := hcUtil.VerifyQuoteAndGetPCRManifest(eventLog, nonceInBytes,
+		hcUtil.EncodeTpmQuote(hostQuote.Quote, hostQuote.Signature, hostQuote.PcrValues), aikCertificate)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Could not verify quote")
+	}
+
+	return &types.HostManifest{
+		HostInfo:       hostQuote.HostInfo,
+		PcrManifest:    pcrManifest,
+		AIKCertificate: base64.StdEncoding.EncodeToString(aikCertificate.Raw),
+	}, aikCertificate, nil
+}
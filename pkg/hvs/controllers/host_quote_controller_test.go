@@ -0,0 +1,237 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers_test
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/clients/grpcagent"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/mocks"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
+	hvsRoutes "github.com/intel-secl/intel-secl/v3/pkg/hvs/router"
+	smocks "github.com/intel-secl/intel-secl/v3/pkg/hvs/services/hosttrust/mocks"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/lib/common/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HostQuoteController", func() {
+	var router *mux.Router
+	var hostStore *mocks.MockHostStore
+	var certStore *models.CertificatesStore
+	var hostQuoteController *controllers.HostQuoteController
+	var mockAgent *grpcagent.MockAgentServer
+	var pushHostId uuid.UUID
+	var pullHostId uuid.UUID
+
+	BeforeEach(func() {
+		var err error
+		mockAgent, err = grpcagent.NewMockAgentServer(&grpcagent.HostInfo{}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		// the mock agent AIK certificate is self signed, trust it as the Privacy CA
+		aikCertificate, err := x509.ParseCertificate(mockAgent.AikCertificate)
+		Expect(err).NotTo(HaveOccurred())
+		certStore = &models.CertificatesStore{
+			models.CaCertTypesPrivacyCa.String(): &models.CertificateStore{
+				Certificates: []x509.Certificate{*aikCertificate},
+			},
+		}
+
+		hostStore = mocks.NewMockHostStore()
+		pushHostId = uuid.MustParse("204466f6-8611-4e03-934d-832172a41917")
+		_, _ = hostStore.Create(&hvs.Host{
+			Id:       pushHostId,
+			HostName: "edge-host",
+			PushMode: true,
+		})
+		pullHostId = uuid.New()
+		_, _ = hostStore.Create(&hvs.Host{
+			Id:               pullHostId,
+			HostName:         "datacenter-host",
+			ConnectionString: "intel:https://ta.ip.com:1443",
+		})
+
+		hostQuoteController = controllers.NewHostQuoteController(hostStore, mocks.NewMockHostStatusStore(),
+			mocks.NewMockHostQuoteStore(), &smocks.MockHostTrustManager{}, certStore, time.Minute)
+
+		router = mux.NewRouter()
+		router.Handle("/hosts/{hId}/quote-nonce", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(hostQuoteController.CreateNonce))).Methods("POST")
+		router.Handle("/hosts/{hId}/quotes", hvsRoutes.ErrorHandler(hvsRoutes.ResponseHandler(hostQuoteController.Submit))).Methods("POST")
+	})
+
+	AfterEach(func() {
+		mockAgent.Close()
+	})
+
+	createNonce := func(hostId uuid.UUID) (*httptest.ResponseRecorder, hvs.HostQuoteNonce) {
+		req, err := http.NewRequest("POST", "/hosts/"+hostId.String()+"/quote-nonce", nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var quoteNonce hvs.HostQuoteNonce
+		if w.Code == http.StatusCreated {
+			Expect(json.Unmarshal(w.Body.Bytes(), &quoteNonce)).To(Succeed())
+		}
+		return w, quoteNonce
+	}
+
+	createQuote := func(nonce string) hvs.HostQuote {
+		nonceInBytes, err := base64.StdEncoding.DecodeString(nonce)
+		Expect(err).NotTo(HaveOccurred())
+		quoteResponse, err := mockAgent.Quote(&grpcagent.QuoteRequest{
+			Nonce:    nonceInBytes,
+			Pcrs:     []uint32{0, 1, 2, 3, 4, 5, 6, 7},
+			PcrBanks: []string{"SHA256"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		hostQuote := hvs.HostQuote{
+			Nonce:          nonce,
+			Quote:          quoteResponse.Quote,
+			Signature:      quoteResponse.Signature,
+			PcrValues:      quoteResponse.PcrValues,
+			AikCertificate: quoteResponse.AikCertificate,
+		}
+		hostQuote.HostInfo.HostName = "edge-host"
+		hostQuote.HostInfo.HardwareUUID = "00ecd3ab-9af4-e711-906e-001560a04062"
+		return hostQuote
+	}
+
+	submitQuote := func(hostId uuid.UUID, hostQuote hvs.HostQuote) *httptest.ResponseRecorder {
+		body, err := json.Marshal(hostQuote)
+		Expect(err).NotTo(HaveOccurred())
+		req, err := http.NewRequest("POST", "/hosts/"+hostId.String()+"/quotes", strings.NewReader(string(body)))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Specs for HTTP Post to "/hosts/{hId}/quote-nonce"
+	Describe("Create a quote nonce", func() {
+		Context("For a push-mode host", func() {
+			It("Should create a nonce", func() {
+				w, quoteNonce := createNonce(pushHostId)
+				Expect(w.Code).To(Equal(http.StatusCreated))
+				Expect(quoteNonce.Nonce).NotTo(BeEmpty())
+				Expect(quoteNonce.Expiration.After(time.Now())).To(BeTrue())
+			})
+		})
+		Context("For a host that is not in push mode", func() {
+			It("Should fail to create a nonce", func() {
+				w, _ := createNonce(pullHostId)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+		Context("For a host that does not exist", func() {
+			It("Should fail to create a nonce", func() {
+				w, _ := createNonce(uuid.New())
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	// Specs for HTTP Post to "/hosts/{hId}/quotes"
+	Describe("Submit a host quote", func() {
+		Context("Quote over the issued nonce", func() {
+			It("Should accept the quote once", func() {
+				_, quoteNonce := createNonce(pushHostId)
+				hostQuote := createQuote(quoteNonce.Nonce)
+				Expect(submitQuote(pushHostId, hostQuote).Code).To(Equal(http.StatusAccepted))
+
+				host, err := hostStore.Retrieve(pushHostId)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(host.HardwareUuid).NotTo(BeNil())
+				Expect(host.HardwareUuid.String()).To(Equal(hostQuote.HostInfo.HardwareUUID))
+
+				// the nonce is single use
+				Expect(submitQuote(pushHostId, hostQuote).Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+		Context("Quote over a different nonce", func() {
+			It("Should reject the quote", func() {
+				_, quoteNonce := createNonce(pushHostId)
+				hostQuote := createQuote(base64.StdEncoding.EncodeToString([]byte("0123456789abcdefghij")))
+				hostQuote.Nonce = quoteNonce.Nonce
+				Expect(submitQuote(pushHostId, hostQuote).Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+		Context("Quote with a nonce that was not issued", func() {
+			It("Should reject the quote", func() {
+				hostQuote := createQuote(base64.StdEncoding.EncodeToString([]byte("0123456789abcdefghij")))
+				Expect(submitQuote(pushHostId, hostQuote).Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+		Context("Quote signed by an AIK that is not certified by the Privacy CA", func() {
+			It("Should reject the quote", func() {
+				otherAgent, err := grpcagent.NewMockAgentServer(&grpcagent.HostInfo{}, nil)
+				Expect(err).NotTo(HaveOccurred())
+				otherAgent.Close()
+				trustedAgent := mockAgent
+				mockAgent = otherAgent
+				_, quoteNonce := createNonce(pushHostId)
+				hostQuote := createQuote(quoteNonce.Nonce)
+				mockAgent = trustedAgent
+
+				Expect(submitQuote(pushHostId, hostQuote).Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+		Context("Quote reporting a different hardware UUID", func() {
+			It("Should reject the quote", func() {
+				_, quoteNonce := createNonce(pushHostId)
+				Expect(submitQuote(pushHostId, createQuote(quoteNonce.Nonce)).Code).To(Equal(http.StatusAccepted))
+
+				_, quoteNonce = createNonce(pushHostId)
+				hostQuote := createQuote(quoteNonce.Nonce)
+				hostQuote.HostInfo.HardwareUUID = "8032632b-8fa4-e811-906e-00163566263e"
+				Expect(submitQuote(pushHostId, hostQuote).Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+		Context("Quote signed by an AIK other than the pinned AIK", func() {
+			It("Should reject the quote", func() {
+				_, quoteNonce := createNonce(pushHostId)
+				Expect(submitQuote(pushHostId, createQuote(quoteNonce.Nonce)).Code).To(Equal(http.StatusAccepted))
+
+				// the other AIK is certified by the Privacy CA as well
+				otherAgent, err := grpcagent.NewMockAgentServer(&grpcagent.HostInfo{}, nil)
+				Expect(err).NotTo(HaveOccurred())
+				defer otherAgent.Close()
+				otherAikCertificate, err := x509.ParseCertificate(otherAgent.AikCertificate)
+				Expect(err).NotTo(HaveOccurred())
+				privacyCa := (*certStore)[models.CaCertTypesPrivacyCa.String()]
+				privacyCa.Certificates = append(privacyCa.Certificates, *otherAikCertificate)
+
+				trustedAgent := mockAgent
+				mockAgent = otherAgent
+				_, quoteNonce = createNonce(pushHostId)
+				hostQuote := createQuote(quoteNonce.Nonce)
+				mockAgent = trustedAgent
+				Expect(submitQuote(pushHostId, hostQuote).Code).To(Equal(http.StatusBadRequest))
+
+				// the pinned AIK is still accepted
+				_, quoteNonce = createNonce(pushHostId)
+				Expect(submitQuote(pushHostId, createQuote(quoteNonce.Nonce)).Code).To(Equal(http.StatusAccepted))
+			})
+		})
+		Context("Quote of a host that is not in push mode", func() {
+			It("Should reject the quote", func() {
+				hostQuote := createQuote(base64.StdEncoding.EncodeToString([]byte("0123456789abcdefghij")))
+				Expect(submitQuote(pullHostId, hostQuote).Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+})
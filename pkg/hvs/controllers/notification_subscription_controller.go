@@ -0,0 +1,288 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/services/notification"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/utils"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/lib/common/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
+	cf "github.com/intel-secl/intel-secl/v3/pkg/lib/flavor/common"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+type NotificationSubscriptionController struct {
+	Store         domain.NotificationSubscriptionStore
+	AddressFilter *notification.AddressFilter
+}
+
+func NewNotificationSubscriptionController(store domain.NotificationSubscriptionStore, addressFilter *notification.AddressFilter) *NotificationSubscriptionController {
+	return &NotificationSubscriptionController{
+		Store:         store,
+		AddressFilter: addressFilter,
+	}
+}
+
+var notificationSubscriptionSearchParams = map[string]bool{"hostId": true, "flavorgroupId": true, "flavorPart": true}
+var notificationDeliverySearchParams = map[string]bool{"delivered": true, "limit": true}
+
+func (controller NotificationSubscriptionController) Create(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/notification_subscription_controller:Create() Entering")
+	defer defaultLog.Trace("controllers/notification_subscription_controller:Create() Leaving")
+
+	if r.Header.Get("Content-Type") != consts.HTTPMediaTypeJson {
+		return nil, http.StatusUnsupportedMediaType, &commErr.ResourceError{Message: "Invalid Content-Type"}
+	}
+
+	if r.ContentLength == 0 {
+		secLog.Error("controllers/notification_subscription_controller:Create() The request body is not provided")
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body is not provided"}
+	}
+
+	var reqSubscription hvs.NotificationSubscriptionCreateRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&reqSubscription); err != nil {
+		secLog.WithError(err).Errorf("controllers/notification_subscription_controller:Create() %s :  Failed to decode"+
+			" request body as notification subscription type", commLogMsg.InvalidInputBadEncoding)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Unable to decode JSON request body"}
+	}
+
+	if err := validateNotificationSubscriptionRequest(reqSubscription, controller.AddressFilter); err != nil {
+		secLog.WithError(err).Errorf("controllers/notification_subscription_controller:Create() %s Error while validating"+
+			" the notification subscription", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	secret, err := crypt.GetHexRandomString(constants.NotificationSecretLength)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/notification_subscription_controller:Create() Failed to generate secret")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error while creating notification subscription"}
+	}
+
+	subscription, err := controller.Store.Create(&hvs.NotificationSubscription{
+		URL:            reqSubscription.URL,
+		Secret:         secret,
+		HostIds:        reqSubscription.HostIds,
+		FlavorgroupIds: reqSubscription.FlavorgroupIds,
+		FlavorParts:    reqSubscription.FlavorParts,
+	})
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/notification_subscription_controller:Create() Notification subscription creation failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error while creating notification subscription"}
+	}
+
+	secLog.WithField("id", subscription.Id).Infof("%s: Notification subscription created by: %s",
+		commLogMsg.PrivilegeModified, r.RemoteAddr)
+	return subscription, http.StatusCreated, nil
+}
+
+func (controller NotificationSubscriptionController) Retrieve(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/notification_subscription_controller:Retrieve() Entering")
+	defer defaultLog.Trace("controllers/notification_subscription_controller:Retrieve() Leaving")
+
+	subscription, status, err := controller.retrieveSubscription(uuid.MustParse(mux.Vars(r)["id"]))
+	if err != nil {
+		return nil, status, err
+	}
+	subscription.Secret = ""
+
+	secLog.WithField("id", subscription.Id).Infof("Notification subscription retrieved by: %s", r.RemoteAddr)
+	return subscription, http.StatusOK, nil
+}
+
+func (controller NotificationSubscriptionController) Search(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/notification_subscription_controller:Search() Entering")
+	defer defaultLog.Trace("controllers/notification_subscription_controller:Search() Leaving")
+
+	if err := utils.ValidateQueryParams(r.URL.Query(), notificationSubscriptionSearchParams); err != nil {
+		secLog.Errorf("controllers/notification_subscription_controller:Search() %s", err.Error())
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	filter, err := getNotificationSubscriptionCriteria(r.URL.Query())
+	if err != nil {
+		secLog.WithError(err).Errorf("controllers/notification_subscription_controller:Search() %s", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid search criteria provided"}
+	}
+
+	subscriptions, err := controller.Store.Search(filter)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/notification_subscription_controller:Search() Notification subscription search failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Unable to search notification subscriptions"}
+	}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+
+	secLog.Infof("%s: Return notification subscription query result to: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return hvs.NotificationSubscriptionCollection{NotificationSubscriptions: subscriptions}, http.StatusOK, nil
+}
+
+func (controller NotificationSubscriptionController) Delete(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/notification_subscription_controller:Delete() Entering")
+	defer defaultLog.Trace("controllers/notification_subscription_controller:Delete() Leaving")
+
+	id := uuid.MustParse(mux.Vars(r)["id"])
+	if _, status, err := controller.retrieveSubscription(id); err != nil {
+		return nil, status, err
+	}
+
+	if err := controller.Store.Delete(id); err != nil {
+		defaultLog.WithError(err).WithField("id", id).Error(
+			"controllers/notification_subscription_controller:Delete() Failed to delete notification subscription")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to delete notification subscription"}
+	}
+
+	secLog.WithField("id", id).Infof("Notification subscription deleted by: %s", r.RemoteAddr)
+	return nil, http.StatusNoContent, nil
+}
+
+// SearchDeliveries returns the log of the deliveries to a subscription, the most recent first
+func (controller NotificationSubscriptionController) SearchDeliveries(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/notification_subscription_controller:SearchDeliveries() Entering")
+	defer defaultLog.Trace("controllers/notification_subscription_controller:SearchDeliveries() Leaving")
+
+	id := uuid.MustParse(mux.Vars(r)["id"])
+	if _, status, err := controller.retrieveSubscription(id); err != nil {
+		return nil, status, err
+	}
+
+	if err := utils.ValidateQueryParams(r.URL.Query(), notificationDeliverySearchParams); err != nil {
+		secLog.Errorf("controllers/notification_subscription_controller:SearchDeliveries() %s", err.Error())
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	filter, err := getNotificationDeliveryCriteria(id, r.URL.Query())
+	if err != nil {
+		secLog.WithError(err).Errorf("controllers/notification_subscription_controller:SearchDeliveries() %s", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid search criteria provided"}
+	}
+
+	deliveries, err := controller.Store.SearchDeliveries(filter)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/notification_subscription_controller:SearchDeliveries() Notification delivery search failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Unable to search notification deliveries"}
+	}
+
+	secLog.Infof("%s: Return notification delivery query result to: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+	return hvs.NotificationDeliveryCollection{NotificationDeliveries: deliveries}, http.StatusOK, nil
+}
+
+func (controller NotificationSubscriptionController) retrieveSubscription(id uuid.UUID) (*hvs.NotificationSubscription, int, error) {
+	subscription, err := controller.Store.Retrieve(id)
+	if err != nil {
+		if strings.Contains(err.Error(), commErr.RowsNotFound) {
+			defaultLog.WithError(err).WithField("id", id).Info(
+				"controllers/notification_subscription_controller:retrieveSubscription() Notification subscription with given ID does not exist")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Notification subscription with given ID does not exist"}
+		}
+		defaultLog.WithError(err).WithField("id", id).Error(
+			"controllers/notification_subscription_controller:retrieveSubscription() Failed to retrieve notification subscription")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve notification subscription"}
+	}
+	return subscription, http.StatusOK, nil
+}
+
+func validateNotificationSubscriptionRequest(subscription hvs.NotificationSubscriptionCreateRequest, addressFilter *notification.AddressFilter) error {
+	defaultLog.Trace("controllers/notification_subscription_controller:validateNotificationSubscriptionRequest() Entering")
+	defer defaultLog.Trace("controllers/notification_subscription_controller:validateNotificationSubscriptionRequest() Leaving")
+
+	if strings.TrimSpace(subscription.URL) == "" {
+		return errors.New("Notification URL must be specified")
+	}
+	notificationURL, err := url.Parse(subscription.URL)
+	if err != nil || notificationURL.Scheme != "https" || notificationURL.Host == "" || notificationURL.User != nil {
+		return errors.New("Valid https notification URL must be specified")
+	}
+	if err := addressFilter.CheckHost(notificationURL.Hostname()); err != nil {
+		defaultLog.WithError(err).Warn("controllers/notification_subscription_controller:validateNotificationSubscriptionRequest() Notification URL host is not permitted")
+		return errors.New("Notification URL must not target a loopback, link-local or private address")
+	}
+
+	for _, flavorPart := range subscription.FlavorParts {
+		var fp cf.FlavorPart
+		if err := fp.Parse(flavorPart); err != nil {
+			return errors.New("Valid flavor parts must be specified")
+		}
+	}
+	for _, id := range append(subscription.HostIds, subscription.FlavorgroupIds...) {
+		if id == uuid.Nil {
+			return errors.New("Valid host and flavorgroup IDs must be specified")
+		}
+	}
+	return nil
+}
+
+func getNotificationSubscriptionCriteria(params url.Values) (*models.NotificationSubscriptionFilterCriteria, error) {
+	defaultLog.Trace("controllers/notification_subscription_controller:getNotificationSubscriptionCriteria() Entering")
+	defer defaultLog.Trace("controllers/notification_subscription_controller:getNotificationSubscriptionCriteria() Leaving")
+
+	criteria := models.NotificationSubscriptionFilterCriteria{}
+
+	if hostId := strings.TrimSpace(params.Get("hostId")); hostId != "" {
+		parsedId, err := uuid.Parse(hostId)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid host UUID provided in search criteria")
+		}
+		criteria.HostId = parsedId
+	}
+	if flavorgroupId := strings.TrimSpace(params.Get("flavorgroupId")); flavorgroupId != "" {
+		parsedId, err := uuid.Parse(flavorgroupId)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid flavorgroup UUID provided in search criteria")
+		}
+		criteria.FlavorgroupId = parsedId
+	}
+	if flavorPart := strings.TrimSpace(params.Get("flavorPart")); flavorPart != "" {
+		var fp cf.FlavorPart
+		if err := fp.Parse(flavorPart); err != nil {
+			return nil, errors.Wrap(err, "Invalid flavor part provided in search criteria")
+		}
+		criteria.FlavorPart = fp.String()
+	}
+	return &criteria, nil
+}
+
+func getNotificationDeliveryCriteria(subscriptionId uuid.UUID, params url.Values) (*models.NotificationDeliveryFilterCriteria, error) {
+	defaultLog.Trace("controllers/notification_subscription_controller:getNotificationDeliveryCriteria() Entering")
+	defer defaultLog.Trace("controllers/notification_subscription_controller:getNotificationDeliveryCriteria() Leaving")
+
+	criteria := models.NotificationDeliveryFilterCriteria{
+		SubscriptionId: subscriptionId,
+		Limit:          constants.DefaultNotificationSearchLimit,
+	}
+
+	if delivered := strings.TrimSpace(params.Get("delivered")); delivered != "" {
+		parsed, err := strconv.ParseBool(delivered)
+		if err != nil {
+			return nil, errors.Wrap(err, "delivered must be true or false")
+		}
+		criteria.Delivered = &parsed
+	}
+	if limit := strings.TrimSpace(params.Get("limit")); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed <= 0 || parsed > constants.DefaultSearchResultRowLimit {
+			return nil, errors.New("Limit must be an integer > 0")
+		}
+		criteria.Limit = parsed
+	}
+	return &criteria, nil
+}
@@ -6,36 +6,57 @@ package controllers
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	consts "github.com/intel-secl/intel-secl/v3/pkg/hvs/constants"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/utils"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/auth"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/constants"
+	comctx "github.com/intel-secl/intel-secl/v3/pkg/lib/common/context"
 	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
 	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/validation"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/flavor/common"
+	ct "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
 	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
 	"github.com/pkg/errors"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type ReportController struct {
-	ReportStore     domain.ReportStore
-	HostStore       domain.HostStore
-	HostStatusStore domain.HostStatusStore
-	HTManager       domain.HostTrustManager
+	ReportStore      domain.ReportStore
+	HostStore        domain.HostStore
+	HostStatusStore  domain.HostStatusStore
+	FlavorGroupStore domain.FlavorGroupStore
+	HTManager        domain.HostTrustManager
+	// ReportBroadcaster and StreamTimeout are only needed by the report stream, which is closed after StreamTimeout
+	// when it is set. ReplayLimit bounds the missed reports sent to a resuming client, DefaultSearchResultRowLimit
+	// when it is not set
+	ReportBroadcaster domain.ReportBroadcaster
+	StreamTimeout     time.Duration
+	ReplayLimit       int
 }
 
-func NewReportController(rs domain.ReportStore, hs domain.HostStore, hsts domain.HostStatusStore, ht domain.HostTrustManager) *ReportController {
-	return &ReportController{rs, hs, hsts, ht}
+func NewReportController(rs domain.ReportStore, hs domain.HostStore, hsts domain.HostStatusStore, fgs domain.FlavorGroupStore, ht domain.HostTrustManager) *ReportController {
+	return &ReportController{
+		ReportStore:      rs,
+		HostStore:        hs,
+		HostStatusStore:  hsts,
+		FlavorGroupStore: fgs,
+		HTManager:        ht,
+	}
 }
 
+var reportStreamParams = map[string]bool{"lastEventId": true}
+
 func (controller ReportController) Create(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
 	defaultLog.Trace("controllers/report_controller:Create() Entering")
 	defer defaultLog.Trace("controllers/report_controller:Create() Leaving")
@@ -65,10 +86,18 @@ func (controller ReportController) Create(w http.ResponseWriter, r *http.Request
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Bad input given in input request"}
 	}
 
-	hvsReport, err := controller.createReport(reqReportCreateRequest)
+	policy, status, err := reportPolicy(r, consts.ReportCreate)
 	if err != nil {
-		defaultLog.WithError(err).Error("controllers/report_controller:Create() Error while creating report")
-		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+		return nil, status, err
+	}
+	hvsReport, status, err := controller.createReport(reqReportCreateRequest, policy)
+	if err != nil {
+		if status == http.StatusUnauthorized {
+			secLog.WithError(err).Errorf("controllers/report_controller:Create() %s", commLogMsg.UnauthorizedAccess)
+		} else {
+			defaultLog.WithError(err).Error("controllers/report_controller:Create() Error while creating report")
+		}
+		return nil, status, &commErr.ResourceError{Message: err.Error()}
 	}
 	if hvsReport == nil {
 		defaultLog.WithError(err).Error("controllers/report_controller:Create() The report was not created")
@@ -80,20 +109,32 @@ func (controller ReportController) Create(w http.ResponseWriter, r *http.Request
 	return report, http.StatusCreated, nil
 }
 
-func (controller ReportController) createReport(rsCriteria hvs.ReportCreateRequest) (*models.HVSReport, error) {
+// createReport verifies the host selected by the criteria and returns its report, once the policy of the
+// reports:create permissions of the user allows it on the host
+func (controller ReportController) createReport(rsCriteria hvs.ReportCreateRequest, policy *auth.Policy) (*models.HVSReport, int, error) {
 	defaultLog.Trace("controllers/report_controller:createReport() Entering")
 	defer defaultLog.Trace("controllers/report_controller:createReport() Leaving")
 	hsCriteria := getHostFilterCriteria(rsCriteria)
 	hosts, err := controller.HostStore.Search(&hsCriteria)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error while searching host")
+		return nil, http.StatusBadRequest, errors.Wrap(err, "Error while searching host")
 	}
 
 	if hosts == nil || len(hosts) == 0 {
-		return nil, errors.New("Host for given criteria does not exist")
+		return nil, http.StatusBadRequest, errors.New("Host for given criteria does not exist")
 	}
 	//Always only one record is returned for the particular criteria
 	hostId := hosts[0].Id
+	if !policy.Unrestricted() {
+		attributes, err := controller.hostAttributes(hostId, hosts[0].HostName)
+		if err != nil {
+			defaultLog.WithError(err).Error("controllers/report_controller:createReport() Error while retrieving the host attributes")
+			return nil, http.StatusInternalServerError, errors.New("Error while creating report")
+		}
+		if !policy.Allows(attributes) {
+			return nil, http.StatusUnauthorized, errors.New("Insufficient privileges to access /v2/hvs/reports")
+		}
+	}
 	hvsReport, err := controller.HTManager.VerifyHost(hostId, true, false)
 	if err != nil {
 		defaultLog.WithError(err).Errorf("controllers/report_controller:createReport() Failed to create a trust report, flavor verification failed")
@@ -104,10 +145,10 @@ func (controller ReportController) createReport(rsCriteria hvs.ReportCreateReque
 		Limit:         1,
 	})
 	if len(hostStatusCollection) == 0 || hostStatusCollection[0].HostStatusInformation.HostState != hvs.HostStateConnected {
-		return nil, errors.New("Host is not in CONNECTED state")
+		return nil, http.StatusBadRequest, errors.New("Host is not in CONNECTED state")
 	}
 
-	return hvsReport, nil
+	return hvsReport, http.StatusCreated, nil
 }
 
 func (controller ReportController) CreateSaml(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
@@ -143,10 +184,18 @@ func (controller ReportController) CreateSaml(w http.ResponseWriter, r *http.Req
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Bad input given in input request"}
 	}
 
-	hvsReport, err := controller.createReport(reqReportCreateRequest)
+	policy, status, err := reportPolicy(r, consts.ReportCreate)
 	if err != nil {
-		defaultLog.WithError(err).Error("controllers/report_controller:CreateSaml() Error while creating SAML report")
-		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+		return nil, status, err
+	}
+	hvsReport, status, err := controller.createReport(reqReportCreateRequest, policy)
+	if err != nil {
+		if status == http.StatusUnauthorized {
+			secLog.WithError(err).Errorf("controllers/report_controller:CreateSaml() %s", commLogMsg.UnauthorizedAccess)
+		} else {
+			defaultLog.WithError(err).Error("controllers/report_controller:CreateSaml() Error while creating SAML report")
+		}
+		return nil, status, &commErr.ResourceError{Message: err.Error()}
 	}
 	if hvsReport == nil {
 		defaultLog.WithError(err).Error("controllers/report_controller:CreateSaml() The report was not created")
@@ -176,6 +225,22 @@ func (controller ReportController) Retrieve(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
+	policy, status, err := reportPolicy(r, consts.ReportRetrieve)
+	if err != nil {
+		return nil, status, err
+	}
+	if !policy.Unrestricted() {
+		attributes, err := controller.reportAttributes(hvsReport)
+		if err != nil {
+			defaultLog.WithError(err).WithField("id", id).Error("controllers/report_controller:Retrieve() failed to retrieve Report attributes")
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve Report"}
+		}
+		if !policy.Allows(attributes) {
+			secLog.WithField("id", id).Errorf("controllers/report_controller:Retrieve() %s", commLogMsg.UnauthorizedAccess)
+			return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Insufficient privileges to access /v2/hvs/reports"}
+		}
+	}
+
 	report := ConvertToReport(hvsReport)
 	secLog.WithField("report", report).Infof("%s: Report retrieved by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
 	return report, http.StatusOK, nil
@@ -202,6 +267,10 @@ func (controller ReportController) Search(w http.ResponseWriter, r *http.Request
 		defaultLog.WithError(err).Warnf("controllers/report_controller:Search() HVSReport search operation failed")
 		return nil, http.StatusInternalServerError, errors.Errorf("HVSReport search operation failed")
 	}
+	hvsReportCollection, status, err := controller.filterReports(r, hvsReportCollection)
+	if err != nil {
+		return nil, status, err
+	}
 
 	reportCollection := hvs.ReportCollection{
 		Reports: []*hvs.Report{},
@@ -241,6 +310,10 @@ func (controller ReportController) SearchSaml(w http.ResponseWriter, r *http.Req
 		defaultLog.WithError(err).Warnf("controllers/report_controller:SearchSaml() HVSReport search operation failed")
 		return nil, http.StatusInternalServerError, errors.Errorf("HVSReport search operation failed")
 	}
+	hvsReportCollection, status, err := controller.filterReports(r, hvsReportCollection)
+	if err != nil {
+		return nil, status, err
+	}
 
 	var samlCollection strings.Builder
 	for _, hvsReport := range hvsReportCollection {
@@ -252,6 +325,310 @@ func (controller ReportController) SearchSaml(w http.ResponseWriter, r *http.Req
 	return samlCollection.String(), http.StatusOK, nil
 }
 
+// Stream sends the summaries of the reports as server-sent events as the verifier stores them. A client that
+// reconnects with the ID of the last event it received, in the Last-Event-ID header or the lastEventId parameter,
+// first receives the reports stored since then.
+func (controller ReportController) Stream(w http.ResponseWriter, r *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/report_controller:Stream() Entering")
+	defer defaultLog.Trace("controllers/report_controller:Stream() Leaving")
+
+	if err := utils.ValidateQueryParams(r.URL.Query(), reportStreamParams); err != nil {
+		secLog.Errorf("controllers/report_controller:Stream() %s", err.Error())
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	lastEventId := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if lastEventId == "" {
+		lastEventId = strings.TrimSpace(r.URL.Query().Get("lastEventId"))
+	}
+	var lastCreated time.Time
+	var lastId uuid.UUID
+	if lastEventId != "" {
+		var err error
+		if lastCreated, lastId, err = parseReportEventId(lastEventId); err != nil {
+			secLog.WithError(err).Warnf("controllers/report_controller:Stream() %s", commLogMsg.InvalidInputBadParam)
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid format of the last event ID specified"}
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok || controller.ReportBroadcaster == nil {
+		defaultLog.Error("controllers/report_controller:Stream() Streaming is not supported by the response writer")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Report stream is not available"}
+	}
+
+	policy, status, err := reportPolicy(r, consts.ReportSearch)
+	if err != nil {
+		return nil, status, err
+	}
+
+	// subscribe before retrieving the missed reports so that none is lost in between
+	reports, cancel := controller.ReportBroadcaster.Subscribe()
+	defer cancel()
+
+	var missedReports []models.HVSReport
+	var truncated bool
+	if lastId != uuid.Nil {
+		missedReports, truncated, err = controller.missedReports(lastCreated, lastId)
+		if err != nil {
+			defaultLog.WithError(err).Error("controllers/report_controller:Stream() Failed to retrieve the reports since the last event")
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve the reports since the last event"}
+		}
+	}
+
+	w.Header().Set("Content-Type", constants.HTTPMediaTypeEventStream)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", consts.ReportStreamRetryMilliseconds); err != nil {
+		return nil, http.StatusOK, nil
+	}
+	flusher.Flush()
+	secLog.Infof("%s: Report stream opened by: %s", commLogMsg.AuthorizedAccess, r.RemoteAddr)
+
+	stream := reportStream{
+		controller:   controller,
+		w:            w,
+		flusher:      flusher,
+		policy:       policy,
+		allowedHosts: make(map[uuid.UUID]bool),
+		replayed:     make(map[uuid.UUID]bool),
+	}
+	for i := range missedReports {
+		stream.replayed[missedReports[i].ID] = true
+		if err := stream.send(&missedReports[i]); err != nil {
+			defaultLog.WithError(err).Debug("controllers/report_controller:Stream() Report stream closed")
+			return nil, http.StatusOK, nil
+		}
+	}
+	// the client reconnects from the last replayed report to receive the rest of the missed reports
+	if truncated {
+		if _, err := fmt.Fprintf(w, "id: %s\nevent: truncated\ndata: %d\n\n", reportEventId(&missedReports[len(missedReports)-1]),
+			len(missedReports)); err == nil {
+			flusher.Flush()
+		}
+		return nil, http.StatusOK, nil
+	}
+
+	heartbeat := time.NewTicker(consts.ReportStreamHeartbeatInterval)
+	defer heartbeat.Stop()
+	var timeout <-chan time.Time
+	if controller.StreamTimeout > 0 {
+		timer := time.NewTimer(controller.StreamTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for {
+		select {
+		case report, ok := <-reports:
+			if !ok {
+				defaultLog.Warnf("controllers/report_controller:Stream() Report stream of %s fell behind and was closed", r.RemoteAddr)
+				return nil, http.StatusOK, nil
+			}
+			if stream.replayed[report.ID] {
+				continue
+			}
+			if err := stream.send(report); err != nil {
+				defaultLog.WithError(err).Debug("controllers/report_controller:Stream() Report stream closed")
+				return nil, http.StatusOK, nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return nil, http.StatusOK, nil
+			}
+			flusher.Flush()
+		case <-timeout:
+			return nil, http.StatusOK, nil
+		case <-r.Context().Done():
+			return nil, http.StatusOK, nil
+		}
+	}
+}
+
+// missedReports returns the reports created since the report of the last event was created, the oldest first, and
+// whether there were more than the search limit. The reports are searched in the report history of the audit log, so
+// the reports that have been replaced by a newer report of the same host since are returned as well. A legacy event ID
+// holds the report ID alone: the reports created since that report are returned while it is stored, the latest
+// report of each host otherwise.
+func (controller ReportController) missedReports(lastCreated time.Time, lastId uuid.UUID) ([]models.HVSReport, bool, error) {
+	defaultLog.Trace("controllers/report_controller:missedReports() Entering")
+	defer defaultLog.Trace("controllers/report_controller:missedReports() Leaving")
+
+	replayLimit := controller.ReplayLimit
+	if replayLimit <= 0 {
+		replayLimit = consts.DefaultSearchResultRowLimit
+	}
+	// one more than the limit to tell whether the reports were truncated and one for the report of the last event
+	criteria := models.ReportFilterCriteria{FromDate: lastCreated, CreatedOnly: true, Limit: replayLimit + 2}
+	if lastCreated.IsZero() {
+		lastReport, err := controller.ReportStore.Retrieve(lastId)
+		if err != nil {
+			if !strings.Contains(err.Error(), commErr.RowsNotFound) {
+				return nil, false, errors.Wrap(err, "Failed to retrieve the report of the last event")
+			}
+			criteria = models.ReportFilterCriteria{LatestPerHost: true, Limit: replayLimit + 2}
+		} else {
+			criteria.FromDate = lastReport.CreatedAt
+		}
+	}
+
+	hvsReports, err := controller.ReportStore.Search(&criteria)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "Failed to search the reports")
+	}
+	var missedReports []models.HVSReport
+	for _, hvsReport := range hvsReports {
+		if hvsReport.ID != lastId {
+			missedReports = append(missedReports, hvsReport)
+		}
+	}
+	sort.SliceStable(missedReports, func(i, j int) bool {
+		return missedReports[i].CreatedAt.Before(missedReports[j].CreatedAt)
+	})
+	truncated := len(missedReports) > replayLimit
+	if truncated {
+		missedReports = missedReports[:replayLimit]
+	}
+	return missedReports, truncated, nil
+}
+
+// reportEventId returns the ID of the event of a report, the creation time of the report followed by its ID so that
+// a client resuming the stream receives the reports stored since then even when the report has been replaced
+func reportEventId(hvsReport *models.HVSReport) string {
+	return hvsReport.CreatedAt.UTC().Format(time.RFC3339Nano) + "_" + hvsReport.ID.String()
+}
+
+// parseReportEventId returns the creation time and the ID of the report of an event ID, the creation time is zero for
+// the legacy event IDs that hold the report ID alone
+func parseReportEventId(eventId string) (time.Time, uuid.UUID, error) {
+	separator := strings.LastIndex(eventId, "_")
+	if separator < 0 {
+		id, err := uuid.Parse(eventId)
+		if err != nil {
+			return time.Time{}, uuid.Nil, errors.Wrap(err, "The event ID is not in the form <creation time>_<report ID>")
+		}
+		return time.Time{}, id, nil
+	}
+	created, err := time.Parse(time.RFC3339Nano, eventId[:separator])
+	if err != nil {
+		return time.Time{}, uuid.Nil, errors.Wrap(err, "Invalid creation time in the event ID")
+	}
+	id, err := uuid.Parse(eventId[separator+1:])
+	if err != nil {
+		return time.Time{}, uuid.Nil, errors.Wrap(err, "Invalid report ID in the event ID")
+	}
+	return created, id, nil
+}
+
+// reportStream writes the report summaries the reports:search permissions of the user allow as server-sent events
+type reportStream struct {
+	controller   ReportController
+	w            http.ResponseWriter
+	flusher      http.Flusher
+	policy       *auth.Policy
+	allowedHosts map[uuid.UUID]bool
+	replayed     map[uuid.UUID]bool
+}
+
+func (s *reportStream) send(hvsReport *models.HVSReport) error {
+	if !s.policy.Unrestricted() {
+		allowed, ok := s.allowedHosts[hvsReport.HostID]
+		if !ok {
+			attributes, err := s.controller.reportAttributes(hvsReport)
+			if err != nil {
+				defaultLog.WithError(err).Error("controllers/report_controller:send() failed to retrieve Report attributes")
+				return nil
+			}
+			allowed = s.policy.Allows(attributes)
+			s.allowedHosts[hvsReport.HostID] = allowed
+		}
+		if !allowed {
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(ConvertToReportSummary(hvsReport))
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal report summary")
+	}
+	if _, err := fmt.Fprintf(s.w, "id: %s\nevent: report\ndata: %s\n\n", reportEventId(hvsReport), data); err != nil {
+		return errors.Wrap(err, "Failed to write report summary")
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// filterReports returns the reports of the hosts the constraints of the reports:search permissions of the user allow
+func (controller ReportController) filterReports(r *http.Request, hvsReports []models.HVSReport) ([]models.HVSReport, int, error) {
+	defaultLog.Trace("controllers/report_controller:filterReports() Entering")
+	defer defaultLog.Trace("controllers/report_controller:filterReports() Leaving")
+
+	policy, status, err := reportPolicy(r, consts.ReportSearch)
+	if err != nil {
+		return nil, status, err
+	}
+	if policy.Unrestricted() {
+		return hvsReports, http.StatusOK, nil
+	}
+
+	allowedHosts := make(map[uuid.UUID]bool)
+	var allowedReports []models.HVSReport
+	for i := range hvsReports {
+		allowed, ok := allowedHosts[hvsReports[i].HostID]
+		if !ok {
+			attributes, err := controller.reportAttributes(&hvsReports[i])
+			if err != nil {
+				defaultLog.WithError(err).Error("controllers/report_controller:filterReports() failed to retrieve Report attributes")
+				return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "HVSReport search operation failed"}
+			}
+			allowed = policy.Allows(attributes)
+			allowedHosts[hvsReports[i].HostID] = allowed
+		}
+		if allowed {
+			allowedReports = append(allowedReports, hvsReports[i])
+		}
+	}
+	return allowedReports, http.StatusOK, nil
+}
+
+// reportAttributeNames are the attributes returned by hostAttributes
+var reportAttributeNames = []string{"host", "flavorgroup"}
+
+// reportAttributes returns the attributes of the host of a report that the constraints of the permissions are
+// evaluated against
+func (controller ReportController) reportAttributes(hvsReport *models.HVSReport) (auth.Attributes, error) {
+	return controller.hostAttributes(hvsReport.HostID, hvsReport.TrustReport.HostManifest.HostInfo.HostName)
+}
+
+// hostAttributes returns the attributes of a host that the constraints of the permissions on its reports are evaluated
+// against: the host name and the IDs and names of the flavorgroups of the host
+func (controller ReportController) hostAttributes(hostId uuid.UUID, hostName string) (auth.Attributes, error) {
+	attributes := auth.Attributes{"host": {hostName}}
+	fgIds, err := controller.HostStore.SearchFlavorgroups(hostId)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to retrieve the flavorgroups of the host")
+	}
+	for _, fgId := range fgIds {
+		flavorgroup, err := controller.FlavorGroupStore.Retrieve(fgId)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to retrieve flavorgroup")
+		}
+		attributes["flavorgroup"] = append(attributes["flavorgroup"], fgId.String(), flavorgroup.Name)
+	}
+	return attributes, nil
+}
+
+// reportPolicy returns the policy of the permissions of the user that grant the rule on the reports
+func reportPolicy(r *http.Request, rule string) (*auth.Policy, int, error) {
+	privileges, err := comctx.GetUserPermissions(r)
+	if err != nil {
+		secLog.Errorf("controllers/report_controller:reportPolicy() %s", commLogMsg.AuthenticationFailed)
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Could not get user permissions from http context"}
+	}
+	policy, _ := auth.GetPolicy(privileges, ct.PermissionInfo{Service: consts.ServiceName, Rules: []string{rule}},
+		reportAttributeNames)
+	return policy, http.StatusOK, nil
+}
+
 // getReportFilterCriteria checks for set filter params in the Search request and returns a valid ReportFilterCriteria
 func getReportFilterCriteria(params url.Values) (*models.ReportFilterCriteria, error) {
 	defaultLog.Trace("controllers/report_controller:getReportFilterCriteria() Entering")
@@ -394,6 +771,35 @@ func ConvertToReport(hvsReport *models.HVSReport) *hvs.Report {
 	return &report
 }
 
+// ConvertToReportSummary summarizes a report with the trust of the host and of each flavor part and the faults
+func ConvertToReportSummary(hvsReport *models.HVSReport) *hvs.ReportSummary {
+	trustInformation := buildTrustInformation(hvsReport.TrustReport)
+
+	summary := hvs.ReportSummary{
+		ID:          hvsReport.ID,
+		HostID:      hvsReport.HostID,
+		HostName:    hvsReport.TrustReport.HostManifest.HostInfo.HostName,
+		Overall:     trustInformation.Overall,
+		FlavorTrust: make(map[common.FlavorPart]bool),
+		CreatedAt:   hvsReport.CreatedAt,
+		Expiration:  hvsReport.Expiration,
+	}
+	for flavorPart, flavorTrust := range trustInformation.FlavorTrust {
+		summary.FlavorTrust[flavorPart] = flavorTrust.Trust
+	}
+	for _, result := range hvsReport.TrustReport.Results {
+		for _, fault := range result.Faults {
+			summary.Faults = append(summary.Faults, hvs.ReportFault{
+				RuleName:    result.Rule.Name,
+				Markers:     result.Rule.Markers,
+				FaultName:   fault.Name,
+				Description: fault.Description,
+			})
+		}
+	}
+	return &summary
+}
+
 func buildTrustInformation(trustReport hvs.TrustReport) *hvs.TrustInformation {
 
 	flavorParts := common.GetFlavorTypes()
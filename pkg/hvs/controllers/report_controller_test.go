@@ -7,18 +7,24 @@ package controllers_test
 import (
 	"encoding/json"
 	"encoding/xml"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/hvs/constants"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/controllers"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/mocks"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
 	hvsRoutes "github.com/intel-secl/intel-secl/v3/pkg/hvs/router"
 	smocks "github.com/intel-secl/intel-secl/v3/pkg/hvs/services/hosttrust/mocks"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/context"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/aas"
 	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 )
 
 var _ = Describe("ReportController", func() {
@@ -31,13 +37,16 @@ var _ = Describe("ReportController", func() {
 	var hostTrustManager *smocks.MockHostTrustManager
 
 	var hostStatusStore *mocks.MockHostStatusStore
+	var flavorGroupStore *mocks.MockFlavorgroupStore
+	reportPermissions := []aas.PermissionInfo{{Service: consts.ServiceName, Rules: []string{"reports:*:*"}}}
 
 	BeforeEach(func() {
 		router = mux.NewRouter()
 		hostStore = mocks.NewMockHostStore()
 		hostStatusStore = mocks.NewMockHostStatusStore()
 		reportStore = mocks.NewMockReportStore()
-		reportController = controllers.NewReportController(reportStore, hostStore, hostStatusStore, hostTrustManager)
+		flavorGroupStore = mocks.NewFakeFlavorgroupStore()
+		reportController = controllers.NewReportController(reportStore, hostStore, hostStatusStore, flavorGroupStore, hostTrustManager)
 	})
 
 	// Specs for HTTP Post to "/reports"
@@ -55,6 +64,7 @@ var _ = Describe("ReportController", func() {
 					strings.NewReader(body),
 				)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
 				req.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
@@ -76,6 +86,7 @@ var _ = Describe("ReportController", func() {
 					strings.NewReader(body),
 				)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
 				req.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
@@ -98,6 +109,7 @@ var _ = Describe("ReportController", func() {
 					strings.NewReader(body),
 				)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
 				req.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
@@ -119,6 +131,7 @@ var _ = Describe("ReportController", func() {
 					strings.NewReader(hostJson),
 				)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
 				req.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
@@ -137,6 +150,7 @@ var _ = Describe("ReportController", func() {
 					strings.NewReader(hostJson),
 				)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
 				req.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
 
@@ -154,6 +168,7 @@ var _ = Describe("ReportController", func() {
 				router.Handle("/reports/{id}", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Retrieve))).Methods("GET")
 				req, err := http.NewRequest("GET", "/reports/15701f03-7b1d-49f9-ac62-6b9b0728bdb3", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -166,6 +181,7 @@ var _ = Describe("ReportController", func() {
 				router.Handle("/reports/{id}", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Retrieve))).Methods("GET")
 				req, err := http.NewRequest("GET", "/reports/73755fda-c910-46be-821f-e8ddeab189e9", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -181,6 +197,7 @@ var _ = Describe("ReportController", func() {
 				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/reports", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -199,6 +216,7 @@ var _ = Describe("ReportController", func() {
 				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/reports?hostHardwareId=e57e5ea0-d465-461e-882d-1600090caa0d", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -217,6 +235,7 @@ var _ = Describe("ReportController", func() {
 				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/reports?hostName=localhost1", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -235,6 +254,7 @@ var _ = Describe("ReportController", func() {
 				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/reports?hostId=ee37c360-7eae-4250-a677-6ee12adce8e2", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -253,6 +273,7 @@ var _ = Describe("ReportController", func() {
 				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/reports?hostStatus=CONNECTED", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -271,6 +292,7 @@ var _ = Describe("ReportController", func() {
 				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/reports?id=ee37c360-7eae-4250-a677-6ee12adce", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -295,6 +317,7 @@ var _ = Describe("ReportController", func() {
 					strings.NewReader(body),
 				)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeSaml)
 				req.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
@@ -317,6 +340,7 @@ var _ = Describe("ReportController", func() {
 					strings.NewReader(hostJson),
 				)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeSaml)
 				req.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
@@ -333,6 +357,7 @@ var _ = Describe("ReportController", func() {
 				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.ResponseHandler(reportController.SearchSaml))).Methods("GET")
 				req, err := http.NewRequest("GET", "/reports", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
 				req.Header.Set("Accept", constants.HTTPMediaTypeSaml)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -347,4 +372,295 @@ var _ = Describe("ReportController", func() {
 			})
 		})
 	})
+
+	// Specs for HTTP Get to "/reports/stream"
+	Describe("Stream the Reports", func() {
+		var reportBroadcaster *fakeReportBroadcaster
+
+		BeforeEach(func() {
+			reportBroadcaster = &fakeReportBroadcaster{reports: make(chan *models.HVSReport, 2)}
+			reportController.ReportBroadcaster = reportBroadcaster
+			reportController.StreamTimeout = 100 * time.Millisecond
+		})
+
+		Context("Resume the stream from a stored Report", func() {
+			It("Should send the missed and the new Reports once", func() {
+				router.Handle("/reports/stream", hvsRoutes.ErrorHandler(hvsRoutes.StreamResponseHandler(reportController.Stream))).Methods("GET")
+				missedReport, err := reportStore.Retrieve(uuid.MustParse("15701f03-7b1d-49f9-ac62-6b9b0728bdb4"))
+				Expect(err).NotTo(HaveOccurred())
+				newReport := *missedReport
+				newReport.ID = uuid.MustParse("5a0d8ec4-2f0b-4e0c-8a43-3d8f5d6c1e27")
+				reportBroadcaster.reports <- missedReport
+				reportBroadcaster.reports <- &newReport
+
+				req, err := http.NewRequest("GET", "/reports/stream", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
+				req.Header.Set("Accept", constants.HTTPMediaTypeEventStream)
+				lastReport, err := reportStore.Retrieve(uuid.MustParse("15701f03-7b1d-49f9-ac62-6b9b0728bdb3"))
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Last-Event-ID", lastReport.CreatedAt.UTC().Format(time.RFC3339Nano)+"_"+lastReport.ID.String())
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Header().Get("Content-Type")).To(Equal(constants.HTTPMediaTypeEventStream))
+
+				body := w.Body.String()
+				Expect(body).NotTo(ContainSubstring("_15701f03-7b1d-49f9-ac62-6b9b0728bdb3\n"))
+				Expect(strings.Count(body, "_15701f03-7b1d-49f9-ac62-6b9b0728bdb4\n")).To(Equal(1))
+				Expect(strings.Index(body, "_15701f03-7b1d-49f9-ac62-6b9b0728bdb4\n")).To(BeNumerically("<",
+					strings.Index(body, "_5a0d8ec4-2f0b-4e0c-8a43-3d8f5d6c1e27\n")))
+
+				data := body[strings.LastIndex(body, "data: ")+len("data: "):]
+				var summary hvs.ReportSummary
+				err = json.Unmarshal([]byte(strings.TrimSpace(data)), &summary)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summary.ID).To(Equal(newReport.ID))
+				Expect(summary.HostID).To(Equal(newReport.HostID))
+			})
+		})
+
+		Context("Resume the stream after the Report of a host was replaced", func() {
+			It("Should not send the replaced Report again", func() {
+				router.Handle("/reports/stream", hvsRoutes.ErrorHandler(hvsRoutes.StreamResponseHandler(reportController.Stream))).Methods("GET")
+				stored, err := reportStore.Retrieve(uuid.MustParse("15701f03-7b1d-49f9-ac62-6b9b0728bdb3"))
+				Expect(err).NotTo(HaveOccurred())
+				deliveredReport := *stored
+				deliveredReport.ID = uuid.MustParse("2b3c9a1e-6f4d-4e7a-9c1b-0d5e8f7a6b01")
+				deliveredReport.CreatedAt = stored.CreatedAt.Add(time.Minute)
+				_, err = reportStore.Update(&deliveredReport)
+				Expect(err).NotTo(HaveOccurred())
+				lastReport := *stored
+				lastReport.ID = uuid.MustParse("2b3c9a1e-6f4d-4e7a-9c1b-0d5e8f7a6b02")
+				lastReport.HostID = uuid.MustParse("e57e5ea0-d465-461e-882d-1600090caa0d")
+				lastReport.CreatedAt = stored.CreatedAt.Add(2 * time.Minute)
+				_, err = reportStore.Update(&lastReport)
+				Expect(err).NotTo(HaveOccurred())
+				// the report of the first host is replaced after the client received the last event
+				newReport := *stored
+				newReport.ID = uuid.MustParse("2b3c9a1e-6f4d-4e7a-9c1b-0d5e8f7a6b03")
+				newReport.CreatedAt = stored.CreatedAt.Add(3 * time.Minute)
+				_, err = reportStore.Update(&newReport)
+				Expect(err).NotTo(HaveOccurred())
+
+				req, err := http.NewRequest("GET", "/reports/stream", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
+				req.Header.Set("Accept", constants.HTTPMediaTypeEventStream)
+				req.Header.Set("Last-Event-ID", lastReport.CreatedAt.UTC().Format(time.RFC3339Nano)+"_"+lastReport.ID.String())
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				body := w.Body.String()
+				Expect(body).NotTo(ContainSubstring("_2b3c9a1e-6f4d-4e7a-9c1b-0d5e8f7a6b01\n"))
+				Expect(body).NotTo(ContainSubstring("_2b3c9a1e-6f4d-4e7a-9c1b-0d5e8f7a6b02\n"))
+				Expect(strings.Count(body, "_2b3c9a1e-6f4d-4e7a-9c1b-0d5e8f7a6b03\n")).To(Equal(1))
+				Expect(body).NotTo(ContainSubstring("event: truncated"))
+			})
+		})
+
+		Context("Resume the stream with more missed Reports than the replay limit", func() {
+			It("Should send the oldest missed Reports and tell the client to resume from the last one", func() {
+				reportController.ReplayLimit = 1
+				router.Handle("/reports/stream", hvsRoutes.ErrorHandler(hvsRoutes.StreamResponseHandler(reportController.Stream))).Methods("GET")
+				stored, err := reportStore.Retrieve(uuid.MustParse("15701f03-7b1d-49f9-ac62-6b9b0728bdb3"))
+				Expect(err).NotTo(HaveOccurred())
+				newReport := *stored
+				newReport.ID = uuid.MustParse("2b3c9a1e-6f4d-4e7a-9c1b-0d5e8f7a6b04")
+				newReport.CreatedAt = stored.CreatedAt.Add(time.Minute)
+				_, err = reportStore.Update(&newReport)
+				Expect(err).NotTo(HaveOccurred())
+
+				req, err := http.NewRequest("GET", "/reports/stream", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
+				req.Header.Set("Accept", constants.HTTPMediaTypeEventStream)
+				req.Header.Set("Last-Event-ID", stored.CreatedAt.UTC().Format(time.RFC3339Nano)+"_"+stored.ID.String())
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				body := w.Body.String()
+				Expect(strings.Count(body, "_15701f03-7b1d-49f9-ac62-6b9b0728bdb4\n")).To(Equal(2))
+				Expect(body).To(HaveSuffix("_15701f03-7b1d-49f9-ac62-6b9b0728bdb4\nevent: truncated\ndata: 1\n\n"))
+				Expect(body).NotTo(ContainSubstring("_2b3c9a1e-6f4d-4e7a-9c1b-0d5e8f7a6b04\n"))
+			})
+		})
+
+		Context("Resume the stream with a legacy last event ID", func() {
+			It("Should send the Reports stored since the Report of that event", func() {
+				router.Handle("/reports/stream", hvsRoutes.ErrorHandler(hvsRoutes.StreamResponseHandler(reportController.Stream))).Methods("GET")
+				// the event IDs used to hold the ID of the report alone
+				req, err := http.NewRequest("GET", "/reports/stream?lastEventId=15701f03-7b1d-49f9-ac62-6b9b0728bdb3", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
+				req.Header.Set("Accept", constants.HTTPMediaTypeEventStream)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Body.String()).NotTo(ContainSubstring("_15701f03-7b1d-49f9-ac62-6b9b0728bdb3\n"))
+				Expect(strings.Count(w.Body.String(), "_15701f03-7b1d-49f9-ac62-6b9b0728bdb4\n")).To(Equal(1))
+			})
+		})
+
+		Context("Resume the stream with an invalid last event ID", func() {
+			It("Should get a HTTP bad request status", func() {
+				router.Handle("/reports/stream", hvsRoutes.ErrorHandler(hvsRoutes.StreamResponseHandler(reportController.Stream))).Methods("GET")
+				req, err := http.NewRequest("GET", "/reports/stream?lastEventId=2020-06-21_15701f03-7b1d-49f9-ac62-6b9b0728bdb3", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
+				req.Header.Set("Accept", constants.HTTPMediaTypeEventStream)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("Stream the Reports as JSON", func() {
+			It("Should get a HTTP unsupported media type status", func() {
+				router.Handle("/reports/stream", hvsRoutes.ErrorHandler(hvsRoutes.StreamResponseHandler(reportController.Stream))).Methods("GET")
+				req, err := http.NewRequest("GET", "/reports/stream", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, reportPermissions)
+				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusUnsupportedMediaType))
+			})
+		})
+	})
+
+	// Specs for the constraints set in the context of the report permissions
+	Describe("Access Reports with constrained permissions", func() {
+		BeforeEach(func() {
+			err := hostStore.AddFlavorgroups(uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2"),
+				[]uuid.UUID{uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2")})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("Create a Report for a host in an allowed flavorgroup", func() {
+			It("Should create a new Report", func() {
+				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Create))).Methods("POST")
+				req, err := http.NewRequest("POST", "/reports", strings.NewReader(`{"host_name": "localhost1"}`))
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{{Service: consts.ServiceName,
+					Context: "flavorgroup=hvs_flavorgroup_test1", Rules: []string{consts.ReportCreate}}})
+				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusCreated))
+			})
+		})
+
+		Context("Create a Report for a host the constraints do not allow", func() {
+			It("Should fail to create Report", func() {
+				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Create))).Methods("POST")
+				req, err := http.NewRequest("POST", "/reports", strings.NewReader(`{"host_name": "localhost1"}`))
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{{Service: consts.ServiceName,
+					Context: "host=edge-*", Rules: []string{consts.ReportCreate}}})
+				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusUnauthorized))
+			})
+		})
+
+		Context("Create a SAML Report for a host the constraints do not allow", func() {
+			It("Should fail to create Report", func() {
+				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.ResponseHandler(reportController.CreateSaml))).Methods("POST")
+				req, err := http.NewRequest("POST", "/reports", strings.NewReader(`{"host_name": "localhost1"}`))
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{{Service: consts.ServiceName,
+					Context: "flavorgroup=automatic", Rules: []string{consts.ReportCreate}}})
+				req.Header.Set("Accept", constants.HTTPMediaTypeSaml)
+				req.Header.Set("Content-Type", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusUnauthorized))
+			})
+		})
+
+		Context("Retrieve a Report of a host in an allowed flavorgroup", func() {
+			It("Should retrieve a Report", func() {
+				router.Handle("/reports/{id}", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Retrieve))).Methods("GET")
+				req, err := http.NewRequest("GET", "/reports/15701f03-7b1d-49f9-ac62-6b9b0728bdb3", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{{Service: consts.ServiceName,
+					Context: "flavorgroup=hvs_flavorgroup_test1", Rules: []string{consts.ReportRetrieve}}})
+				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+			})
+		})
+
+		Context("Retrieve a Report of a host in no allowed flavorgroup", func() {
+			It("Should fail to retrieve Report", func() {
+				router.Handle("/reports/{id}", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Retrieve))).Methods("GET")
+				req, err := http.NewRequest("GET", "/reports/15701f03-7b1d-49f9-ac62-6b9b0728bdb4", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{{Service: consts.ServiceName,
+					Context: "flavorgroup=hvs_flavorgroup_test1", Rules: []string{consts.ReportRetrieve}}})
+				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusUnauthorized))
+			})
+		})
+
+		Context("Search Reports with constrained permissions", func() {
+			It("Should get the Reports of the hosts in the allowed flavorgroups", func() {
+				router.Handle("/reports", hvsRoutes.ErrorHandler(hvsRoutes.JsonResponseHandler(reportController.Search))).Methods("GET")
+				req, err := http.NewRequest("GET", "/reports", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{{Service: consts.ServiceName,
+					Context: "flavorgroup=hvs_flavorgroup_*", Rules: []string{consts.ReportSearch}}})
+				req.Header.Set("Accept", constants.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var reportCollection hvs.ReportCollection
+				err = json.Unmarshal(w.Body.Bytes(), &reportCollection)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(reportCollection.Reports)).To(Equal(1))
+				Expect(reportCollection.Reports[0].HostID.String()).To(Equal("ee37c360-7eae-4250-a677-6ee12adce8e2"))
+			})
+		})
+		Context("Stream Reports with constrained permissions", func() {
+			It("Should only send the Reports of the hosts in the allowed flavorgroups", func() {
+				reportController.ReportBroadcaster = &fakeReportBroadcaster{reports: make(chan *models.HVSReport)}
+				reportController.StreamTimeout = 100 * time.Millisecond
+				router.Handle("/reports/stream", hvsRoutes.ErrorHandler(hvsRoutes.StreamResponseHandler(reportController.Stream))).Methods("GET")
+				// the reports stored since the last event are sent, the report of that event does not need to be kept
+				req, err := http.NewRequest("GET", "/reports/stream?lastEventId=2020-06-21T07:18:00.57Z_73755fda-c910-46be-821f-e8ddeab189e9", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{{Service: consts.ServiceName,
+					Context: "flavorgroup=hvs_flavorgroup_*", Rules: []string{consts.ReportSearch}}})
+				req.Header.Set("Accept", constants.HTTPMediaTypeEventStream)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(w.Body.String()).To(ContainSubstring("_15701f03-7b1d-49f9-ac62-6b9b0728bdb3\n"))
+				Expect(w.Body.String()).NotTo(ContainSubstring("_15701f03-7b1d-49f9-ac62-6b9b0728bdb4\n"))
+			})
+		})
+	})
 })
+
+// fakeReportBroadcaster hands the reports queued in its channel to the subscriber
+type fakeReportBroadcaster struct {
+	reports chan *models.HVSReport
+}
+
+func (b *fakeReportBroadcaster) Publish(report *models.HVSReport) {
+	b.reports <- report
+}
+
+func (b *fakeReportBroadcaster) Subscribe() (<-chan *models.HVSReport, func()) {
+	return b.reports, func() {}
+}
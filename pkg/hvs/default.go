@@ -9,6 +9,7 @@ import (
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/constants"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/services/hrrs"
 	commConfig "github.com/intel-secl/intel-secl/v3/pkg/lib/common/config"
+	commTls "github.com/intel-secl/intel-secl/v3/pkg/lib/common/tls"
 	"github.com/spf13/viper"
 	"os"
 )
@@ -19,6 +20,7 @@ const (
 	fvsSkipFlavorSignatureVerification = "fvs-skip-flavor-signature-verification"
 	hrrsRefreshPeriod                  = "hrrs-refresh-period"
 	vcssRefreshPeriod                  = "vcss-refresh-period"
+	notificationAllowedNetworks        = "notification-allowed-networks"
 )
 
 // this func sets the default values for viper keys
@@ -28,6 +30,8 @@ func init() {
 	viper.SetDefault("tls-key-file", constants.DefaultTLSKeyFile)
 	viper.SetDefault("tls-common-name", constants.DefaultHvsTlsCn)
 	viper.SetDefault("tls-san-list", constants.DefaultHvsTlsSan)
+	viper.SetDefault("tls-renew-before", commTls.DefaultRenewBefore)
+	viper.SetDefault("tls-reload-interval", commTls.DefaultReloadInterval)
 
 	// set default values for all other certs
 	viper.SetDefault("saml-cert-file", constants.SAMLCertFile)
@@ -122,10 +126,14 @@ func defaultConfig() *config.Configuration {
 			Dek:      viper.GetString("hvs-data-encryption-key"),
 		},
 		TLS: commConfig.TLSCertConfig{
-			CertFile:   viper.GetString("tls-cert-file"),
-			KeyFile:    viper.GetString("tls-key-file"),
-			CommonName: viper.GetString("tls-common-name"),
-			SANList:    viper.GetString("tls-san-list"),
+			CertFile:       viper.GetString("tls-cert-file"),
+			KeyFile:        viper.GetString("tls-key-file"),
+			CommonName:     viper.GetString("tls-common-name"),
+			SANList:        viper.GetString("tls-san-list"),
+			RenewBefore:    viper.GetDuration("tls-renew-before"),
+			ReloadInterval: viper.GetDuration("tls-reload-interval"),
+			RenewUsername:  viper.GetString("tls-renew-username"),
+			RenewPassword:  viper.GetString("tls-renew-password"),
 		},
 		SAML: config.SAMLConfig{
 			CommonConfig: commConfig.SigningCertConfig{
@@ -173,6 +181,9 @@ func defaultConfig() *config.Configuration {
 		VCSS: config.VCSSConfig{
 			RefreshPeriod: viper.GetDuration(vcssRefreshPeriod),
 		},
+		Notification: config.NotificationConfig{
+			AllowedNetworks: viper.GetStringSlice(notificationAllowedNetworks),
+		},
 		FVS: config.FVSConfig{
 			NumberOfVerifiers:               viper.GetInt(fvsNumberOfVerifiers),
 			NumberOfDataFetchers:            viper.GetInt(fvsNumberOfDataFetchers),
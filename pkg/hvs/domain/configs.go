@@ -6,6 +6,9 @@
 package domain
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/saml"
@@ -21,6 +24,8 @@ type HostTrustVerifierConfig struct {
 	CertsStore                      models.CertificatesStore
 	SamlIssuerConfig                saml.IssuerConfiguration
 	SkipFlavorSignatureVerification bool
+	Notifier                        HostTrustNotifier
+	ReportBroadcaster               ReportBroadcaster
 }
 
 type HostTrustMgrConfig struct {
@@ -58,3 +63,12 @@ type HostConnectionConfig struct {
 	ServiceUsername string
 	ServicePassword string
 }
+
+type NotifierConfig struct {
+	SubscriptionStore NotificationSubscriptionStore
+	HostStore         HostStore
+	Client            *http.Client
+	QueueSize         int
+	MaxAttempts       int
+	RetryBackoff      time.Duration
+}
@@ -124,6 +124,28 @@ type (
 		SearchHosts(uuid.UUID) ([]string, error)
 	}
 
+	// HostQuoteStore holds the quote nonces issued to push-mode hosts and the AIK certificates they are pinned to
+	HostQuoteStore interface {
+		// CreateNonce replaces the outstanding nonce of the host
+		CreateNonce(uuid.UUID, *hvs.HostQuoteNonce) error
+		// ConsumeNonce removes the outstanding nonce of the host and returns it
+		ConsumeNonce(uuid.UUID) (*hvs.HostQuoteNonce, error)
+		// PinAikCertificate stores the AIK certificate digest of the host unless one is stored already, it returns
+		// the stored digest
+		PinAikCertificate(uuid.UUID, string) (string, error)
+	}
+
+	// NotificationSubscriptionStore holds the webhook subscriptions and the log of their deliveries
+	NotificationSubscriptionStore interface {
+		Create(*hvs.NotificationSubscription) (*hvs.NotificationSubscription, error)
+		Retrieve(uuid.UUID) (*hvs.NotificationSubscription, error)
+		Search(*models.NotificationSubscriptionFilterCriteria) ([]hvs.NotificationSubscription, error)
+		Delete(uuid.UUID) error
+		CreateDelivery(*hvs.NotificationDelivery) (*hvs.NotificationDelivery, error)
+		UpdateDelivery(*hvs.NotificationDelivery) error
+		SearchDeliveries(*models.NotificationDeliveryFilterCriteria) ([]hvs.NotificationDelivery, error)
+	}
+
 	// TagCertificateStore enumerates the operations expected to be performed on a TagCertificate backend
 	TagCertificateStore interface {
 		Create(*hvs.TagCertificate) (*hvs.TagCertificate, error)
@@ -148,6 +170,10 @@ type (
 
 		//Process all records stuck in queue post service restart
 		ProcessQueue() error
+
+		// Queues the verification of host data that was not fetched by the manager, like the manifest
+		// submitted by a push-mode host
+		HostDataReceiver
 	}
 
 	HostDataReceiver interface {
@@ -172,6 +198,20 @@ type (
 		Verify(uuid.UUID, *types.HostManifest, bool) (*models.HVSReport, error)
 	}
 
+	// HostTrustNotifier delivers the trust changes of the hosts to the subscriptions asynchronously
+	HostTrustNotifier interface {
+		Notify(*hvs.HostTrustChange)
+		Stop()
+	}
+
+	// ReportBroadcaster fans the reports stored by the verifier out to the subscribers of the report stream. The
+	// channel of a subscriber that does not keep up is closed.
+	ReportBroadcaster interface {
+		Publish(*models.HVSReport)
+		// Subscribe returns the channel of the published reports and the function that cancels the subscription
+		Subscribe() (<-chan *models.HVSReport, func())
+	}
+
 	AuditLogWriter interface {
 		// creates an entry of auditlog
 		CreateEntry(string, ...interface{}) (*models.AuditLogEntry, error)
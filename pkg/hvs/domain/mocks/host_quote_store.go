@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package mocks
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// MockHostQuoteStore provides a mocked implementation of interface domain.HostQuoteStore
+type MockHostQuoteStore struct {
+	Nonces                map[uuid.UUID]hvs.HostQuoteNonce
+	AikCertificateDigests map[uuid.UUID]string
+	lock                  sync.Mutex
+}
+
+func NewMockHostQuoteStore() *MockHostQuoteStore {
+	return &MockHostQuoteStore{
+		Nonces:                make(map[uuid.UUID]hvs.HostQuoteNonce),
+		AikCertificateDigests: make(map[uuid.UUID]string),
+	}
+}
+
+func (store *MockHostQuoteStore) CreateNonce(hostId uuid.UUID, nonce *hvs.HostQuoteNonce) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	store.Nonces[hostId] = *nonce
+	return nil
+}
+
+func (store *MockHostQuoteStore) ConsumeNonce(hostId uuid.UUID) (*hvs.HostQuoteNonce, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	nonce, found := store.Nonces[hostId]
+	if !found {
+		return nil, errors.New(commErr.RowsNotFound)
+	}
+	delete(store.Nonces, hostId)
+	return &nonce, nil
+}
+
+func (store *MockHostQuoteStore) PinAikCertificate(hostId uuid.UUID, aikCertificateDigest string) (string, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	if pinnedDigest, found := store.AikCertificateDigests[hostId]; found {
+		return pinnedDigest, nil
+	}
+	store.AikCertificateDigests[hostId] = aikCertificateDigest
+	return aikCertificateDigest, nil
+}
@@ -0,0 +1,181 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package mocks
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// MockNotificationSubscriptionStore provides a mocked implementation of interface domain.NotificationSubscriptionStore
+type MockNotificationSubscriptionStore struct {
+	Subscriptions []hvs.NotificationSubscription
+	Deliveries    []hvs.NotificationDelivery
+	lock          sync.Mutex
+}
+
+func (store *MockNotificationSubscriptionStore) Create(s *hvs.NotificationSubscription) (*hvs.NotificationSubscription, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	s.Id = uuid.New()
+	s.CreatedAt = time.Now().UTC()
+	store.Subscriptions = append(store.Subscriptions, *s)
+	return s, nil
+}
+
+func (store *MockNotificationSubscriptionStore) Retrieve(id uuid.UUID) (*hvs.NotificationSubscription, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	for _, s := range store.Subscriptions {
+		if s.Id == id {
+			return &s, nil
+		}
+	}
+	return nil, errors.New(commErr.RowsNotFound)
+}
+
+func (store *MockNotificationSubscriptionStore) Search(criteria *models.NotificationSubscriptionFilterCriteria) ([]hvs.NotificationSubscription, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	subscriptions := []hvs.NotificationSubscription{}
+	for _, s := range store.Subscriptions {
+		if criteria != nil {
+			if criteria.HostId != uuid.Nil && !containsUUID(s.HostIds, criteria.HostId) {
+				continue
+			}
+			if criteria.FlavorgroupId != uuid.Nil && !containsUUID(s.FlavorgroupIds, criteria.FlavorgroupId) {
+				continue
+			}
+			if criteria.FlavorPart != "" && !containsString(s.FlavorParts, criteria.FlavorPart) {
+				continue
+			}
+		}
+		subscriptions = append(subscriptions, s)
+	}
+	return subscriptions, nil
+}
+
+func (store *MockNotificationSubscriptionStore) Delete(id uuid.UUID) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	for i, s := range store.Subscriptions {
+		if s.Id == id {
+			store.Subscriptions = append(store.Subscriptions[:i], store.Subscriptions[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New(commErr.RowsNotFound)
+}
+
+func (store *MockNotificationSubscriptionStore) CreateDelivery(d *hvs.NotificationDelivery) (*hvs.NotificationDelivery, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	d.Id = uuid.New()
+	d.CreatedAt = time.Now().UTC()
+	d.UpdatedAt = d.CreatedAt
+	store.Deliveries = append(store.Deliveries, *d)
+	return d, nil
+}
+
+func (store *MockNotificationSubscriptionStore) UpdateDelivery(d *hvs.NotificationDelivery) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	for i, delivery := range store.Deliveries {
+		if delivery.Id == d.Id {
+			d.UpdatedAt = time.Now().UTC()
+			store.Deliveries[i] = *d
+			return nil
+		}
+	}
+	return errors.New(commErr.RowsNotFound)
+}
+
+func (store *MockNotificationSubscriptionStore) SearchDeliveries(criteria *models.NotificationDeliveryFilterCriteria) ([]hvs.NotificationDelivery, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	deliveries := []hvs.NotificationDelivery{}
+	for i := len(store.Deliveries) - 1; i >= 0; i-- {
+		d := store.Deliveries[i]
+		if criteria != nil {
+			if criteria.SubscriptionId != uuid.Nil && d.SubscriptionId != criteria.SubscriptionId {
+				continue
+			}
+			if criteria.Delivered != nil && d.Delivered != *criteria.Delivered {
+				continue
+			}
+			if criteria.Limit > 0 && len(deliveries) == criteria.Limit {
+				break
+			}
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
+}
+
+// GetDeliveries returns a copy of the delivery log, safe to call while deliveries are in progress
+func (store *MockNotificationSubscriptionStore) GetDeliveries() []hvs.NotificationDelivery {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	return append([]hvs.NotificationDelivery{}, store.Deliveries...)
+}
+
+func containsUUID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// NewFakeNotificationSubscriptionStore provides two subscriptions, the first one filtering on a host and the second
+// one on the PLATFORM flavor part, and a failed delivery to the first one
+func NewFakeNotificationSubscriptionStore() *MockNotificationSubscriptionStore {
+	store := &MockNotificationSubscriptionStore{
+		Subscriptions: []hvs.NotificationSubscription{
+			{
+				Id:      uuid.MustParse("2f1c7a4e-5d0b-4a8e-9f3e-1b6c2d7e8a90"),
+				URL:     "https://hooks.example.com/trust",
+				Secret:  "8c2d1e0f3b4a59687766554433221100",
+				HostIds: []uuid.UUID{uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2")},
+			},
+			{
+				Id:          uuid.MustParse("7b3e9d2a-1c4f-4e6b-8a5d-0f2e3c4b5a69"),
+				URL:         "https://hooks.example.com/platform",
+				Secret:      "00112233445566778899aabbccddeeff",
+				FlavorParts: []string{"PLATFORM"},
+			},
+		},
+		Deliveries: []hvs.NotificationDelivery{
+			{
+				Id:             uuid.MustParse("c6a2b1d0-3e4f-4a5b-9c8d-7e6f5a4b3c2d"),
+				SubscriptionId: uuid.MustParse("2f1c7a4e-5d0b-4a8e-9f3e-1b6c2d7e8a90"),
+				HostId:         uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2"),
+				Event:          hvs.HostTrustChangedEvent,
+				Attempts:       5,
+				StatusCode:     http.StatusServiceUnavailable,
+				Error:          "Unexpected response status 503",
+			},
+		},
+	}
+	return store
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,6 +23,14 @@ import (
 // MockReportStore provides a mocked implementation of interface postgres.ReportStore
 type MockReportStore struct {
 	reportStore map[uuid.UUID]models.HVSReport
+	// history holds the reports logged in the audit log when they are created, and again when they are deleted
+	history []mockReportHistoryEntry
+}
+
+type mockReportHistoryEntry struct {
+	action string
+	logged time.Time
+	report models.HVSReport
 }
 
 // Create inserts a HVSReport
@@ -34,12 +43,18 @@ func (store *MockReportStore) Create(report *models.HVSReport) (*models.HVSRepor
 		report.ID = newUuid
 	}
 	store.reportStore[report.ID] = *report
+	store.history = append(store.history, mockReportHistoryEntry{action: "create", logged: report.CreatedAt, report: *report})
 	return report, nil
 }
 
+// Update replaces the HVSReport of the host
 func (store *MockReportStore) Update(report *models.HVSReport) (*models.HVSReport, error) {
-	store.reportStore[report.ID] = *report
-	return report, nil
+	for _, r := range store.reportStore {
+		if r.HostID == report.HostID {
+			_ = store.Delete(r.ID)
+		}
+	}
+	return store.Create(report)
 }
 
 // Retrieve returns HVSReport
@@ -55,6 +70,8 @@ func (store *MockReportStore) Delete(id uuid.UUID) error {
 	for _, t := range store.reportStore {
 		if t.ID == id {
 			delete(store.reportStore, id)
+			store.history = append(store.history, mockReportHistoryEntry{action: "delete", logged: time.Now().UTC(), report: t})
+			return nil
 		}
 	}
 	return errors.New("record not found")
@@ -108,6 +125,23 @@ func (store *MockReportStore) Search(criteria *models.ReportFilterCriteria) ([]m
 				}
 			}
 		}
+	} else if !criteria.FromDate.IsZero() {
+		// the reports logged since a date are searched in the report history, the oldest entries first
+		var entries []mockReportHistoryEntry
+		for _, entry := range store.history {
+			if (!criteria.CreatedOnly || entry.action == "create") && !entry.logged.Before(criteria.FromDate) {
+				entries = append(entries, entry)
+			}
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].logged.Before(entries[j].logged)
+		})
+		for _, entry := range entries {
+			reports = append(reports, entry.report)
+		}
+		if criteria.Limit > 0 && len(reports) > criteria.Limit {
+			reports = reports[:criteria.Limit]
+		}
 	} else if !criteria.ToDate.IsZero() {
 		for _, r := range store.reportStore {
 			if r.Expiration.Before(criteria.ToDate) {
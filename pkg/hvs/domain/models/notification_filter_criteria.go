@@ -0,0 +1,22 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package models
+
+import "github.com/google/uuid"
+
+// NotificationSubscriptionFilterCriteria selects the subscriptions that explicitly filter on the given host,
+// flavorgroup or flavor part
+type NotificationSubscriptionFilterCriteria struct {
+	HostId        uuid.UUID
+	FlavorgroupId uuid.UUID
+	FlavorPart    string
+}
+
+type NotificationDeliveryFilterCriteria struct {
+	SubscriptionId uuid.UUID
+	Delivered      *bool
+	Limit          int
+}
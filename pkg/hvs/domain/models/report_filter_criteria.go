@@ -21,6 +21,8 @@ type ReportFilterCriteria struct {
 	ToDate         time.Time
 	LatestPerHost  bool
 	Limit          int
+	// CreatedOnly leaves out the copies of the reports the report history logs when they are replaced or deleted
+	CreatedOnly bool
 }
 
 type ReportLocator struct {
@@ -69,10 +69,11 @@ func (ft *FlavorTemplateStore) Retrieve(templateID uuid.UUID, includeDeleted boo
 
 	if includeDeleted || (!includeDeleted && !sf.Deleted) {
 		flavorTemplate := hvs.FlavorTemplate{
-			ID:          sf.ID,
-			Label:       sf.Content.Label,
-			Condition:   sf.Content.Condition,
-			FlavorParts: sf.Content.FlavorParts,
+			ID:                  sf.ID,
+			Label:               sf.Content.Label,
+			Condition:           sf.Content.Condition,
+			ConditionExpression: sf.Content.ConditionExpression,
+			FlavorParts:         sf.Content.FlavorParts,
 		}
 		return &flavorTemplate, nil
 	}
@@ -111,10 +112,11 @@ func (ft *FlavorTemplateStore) Search(criteria *models.FlavorTemplateFilterCrite
 		}
 
 		flavorTemplate := hvs.FlavorTemplate{
-			ID:          template.ID,
-			Label:       template.Content.Label,
-			Condition:   template.Content.Condition,
-			FlavorParts: template.Content.FlavorParts,
+			ID:                  template.ID,
+			Label:               template.Content.Label,
+			Condition:           template.Content.Condition,
+			ConditionExpression: template.Content.ConditionExpression,
+			FlavorParts:         template.Content.FlavorParts,
 		}
 		flavortemplates = append(flavortemplates, flavorTemplate)
 	}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package postgres
+
+import (
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// HostQuoteStore keeps the quote nonces and pinned AIK certificates of push-mode hosts in the database, so that
+// they are shared by all HVS instances and survive a restart
+type HostQuoteStore struct {
+	Store *DataStore
+}
+
+func NewHostQuoteStore(store *DataStore) *HostQuoteStore {
+	return &HostQuoteStore{store}
+}
+
+func (hqs *HostQuoteStore) CreateNonce(hostId uuid.UUID, nonce *hvs.HostQuoteNonce) error {
+	defaultLog.Trace("postgres/host_quote_store:CreateNonce() Entering")
+	defer defaultLog.Trace("postgres/host_quote_store:CreateNonce() Leaving")
+
+	err := hqs.Store.Db.Exec("INSERT INTO host_quote_nonce (host_id, nonce, expiration) VALUES (?, ?, ?) "+
+		"ON CONFLICT (host_id) DO UPDATE SET nonce = EXCLUDED.nonce, expiration = EXCLUDED.expiration",
+		hostId, nonce.Nonce, nonce.Expiration).Error
+	if err != nil {
+		return errors.Wrap(err, "postgres/host_quote_store:CreateNonce() failed to create quote nonce")
+	}
+	return nil
+}
+
+func (hqs *HostQuoteStore) ConsumeNonce(hostId uuid.UUID) (*hvs.HostQuoteNonce, error) {
+	defaultLog.Trace("postgres/host_quote_store:ConsumeNonce() Entering")
+	defer defaultLog.Trace("postgres/host_quote_store:ConsumeNonce() Leaving")
+
+	// the nonce is deleted by the same statement that reads it, so concurrent requests cannot both use it
+	nonce := hvs.HostQuoteNonce{}
+	row := hqs.Store.Db.Raw("DELETE FROM host_quote_nonce WHERE host_id = ? RETURNING nonce, expiration", hostId).Row()
+	if err := row.Scan(&nonce.Nonce, &nonce.Expiration); err != nil {
+		return nil, errors.Wrap(err, "postgres/host_quote_store:ConsumeNonce() failed to consume quote nonce")
+	}
+	return &nonce, nil
+}
+
+func (hqs *HostQuoteStore) PinAikCertificate(hostId uuid.UUID, aikCertificateDigest string) (string, error) {
+	defaultLog.Trace("postgres/host_quote_store:PinAikCertificate() Entering")
+	defer defaultLog.Trace("postgres/host_quote_store:PinAikCertificate() Leaving")
+
+	// the conflicting update keeps the digest already pinned and makes the statement return it
+	var pinnedDigest string
+	row := hqs.Store.Db.Raw("INSERT INTO host_aik (host_id, aik_certificate_digest) VALUES (?, ?) "+
+		"ON CONFLICT (host_id) DO UPDATE SET aik_certificate_digest = host_aik.aik_certificate_digest "+
+		"RETURNING aik_certificate_digest", hostId, aikCertificateDigest).Row()
+	if err := row.Scan(&pinnedDigest); err != nil {
+		return "", errors.Wrap(err, "postgres/host_quote_store:PinAikCertificate() failed to pin AIK certificate")
+	}
+	return pinnedDigest, nil
+}
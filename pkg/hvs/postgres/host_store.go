@@ -37,6 +37,7 @@ func (hs *HostStore) Create(h *hvs.Host) (*hvs.Host, error) {
 		Name:             h.HostName,
 		Description:      h.Description,
 		ConnectionString: h.ConnectionString,
+		PushMode:         h.PushMode,
 	}
 
 	if h.HardwareUuid != nil {
@@ -55,7 +56,7 @@ func (hs *HostStore) Retrieve(id uuid.UUID) (*hvs.Host, error) {
 
 	h := hvs.Host{}
 	row := hs.Store.Db.Model(&host{}).Where(&host{Id: id}).Row()
-	if err := row.Scan(&h.Id, &h.HostName, &h.Description, &h.ConnectionString, &h.HardwareUuid); err != nil {
+	if err := row.Scan(&h.Id, &h.HostName, &h.Description, &h.ConnectionString, &h.HardwareUuid, &h.PushMode); err != nil {
 		return nil, errors.Wrap(err, "postgres/host_store:Retrieve() failed to scan record")
 	}
 	return &h, nil
@@ -130,7 +131,7 @@ func (hs *HostStore) Search(criteria *models.HostFilterCriteria) ([]*hvs.Host, e
 	hosts := []*hvs.Host{}
 	for rows.Next() {
 		host := hvs.Host{}
-		if err := rows.Scan(&host.Id, &host.HostName, &host.Description, &host.ConnectionString, &host.HardwareUuid); err != nil {
+		if err := rows.Scan(&host.Id, &host.HostName, &host.Description, &host.ConnectionString, &host.HardwareUuid, &host.PushMode); err != nil {
 			return nil, errors.Wrap(err, "postgres/host_store:Search() failed to scan record")
 		}
 		hosts = append(hosts, &host)
@@ -48,6 +48,18 @@ type (
 		Description      string
 		ConnectionString string        `gorm:"not null"`
 		HardwareUuid     models.HwUUID `gorm:"type:uuid;index:idx_host_hardware_uuid"`
+		PushMode         bool          `gorm:"not null;default:false"`
+	}
+
+	hostQuoteNonce struct {
+		HostId     uuid.UUID `gorm:"primary_key;type:uuid REFERENCES host(Id) ON UPDATE CASCADE ON DELETE CASCADE"`
+		Nonce      string    `gorm:"not null"`
+		Expiration time.Time `gorm:"not null"`
+	}
+
+	hostAik struct {
+		HostId               uuid.UUID `gorm:"primary_key;type:uuid REFERENCES host(Id) ON UPDATE CASCADE ON DELETE CASCADE"`
+		AikCertificateDigest string    `gorm:"not null"`
 	}
 
 	hostFlavorgroup struct {
@@ -147,6 +159,33 @@ type (
 		Data       PGAuditLogData `sql:"type:JSONB"`
 	}
 
+	PGNotificationFilter notificationFilter
+	notificationFilter   struct {
+		HostIds        []uuid.UUID `json:"host_ids,omitempty"`
+		FlavorgroupIds []uuid.UUID `json:"flavorgroup_ids,omitempty"`
+		FlavorParts    []string    `json:"flavor_parts,omitempty"`
+	}
+	notificationSubscription struct {
+		ID        uuid.UUID            `gorm:"primary_key;type:uuid"`
+		URL       string               `gorm:"column:url;not null"`
+		Secret    string               `gorm:"column:secret;not null"`
+		Filter    PGNotificationFilter `gorm:"column:filter" sql:"type:JSONB NOT NULL DEFAULT '{}'::JSONB"`
+		CreatedAt time.Time            `gorm:"column:created;not null"`
+	}
+
+	notificationDelivery struct {
+		ID             uuid.UUID `gorm:"primary_key;type:uuid"`
+		SubscriptionID uuid.UUID `gorm:"column:subscription_id;type:uuid REFERENCES notification_subscription(id) ON UPDATE CASCADE ON DELETE CASCADE;not null;index:idx_notification_delivery_subscription_id"`
+		HostID         uuid.UUID `gorm:"column:host_id;type:uuid;not null"`
+		Event          string    `gorm:"column:event;type:varchar(50);not null"`
+		Attempts       int       `gorm:"column:attempts;not null"`
+		StatusCode     int       `gorm:"column:status_code"`
+		Delivered      bool      `gorm:"column:delivered;not null"`
+		Error          string    `gorm:"column:error"`
+		CreatedAt      time.Time `gorm:"column:created;not null"`
+		UpdatedAt      time.Time `gorm:"column:updated;not null"`
+	}
+
 	tagCertificate struct {
 		ID           uuid.UUID `gorm:"primary_key; type:uuid"`
 		HardwareUUID uuid.UUID `gorm:"not null; type:uuid; column:hardware_uuid"`
@@ -172,6 +211,19 @@ func (qp *PGJsonStrMap) Scan(value interface{}) error {
 	return json.Unmarshal(b, &qp)
 }
 
+func (nf PGNotificationFilter) Value() (driver.Value, error) {
+	return json.Marshal(nf)
+}
+
+func (nf *PGNotificationFilter) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("postgres/models:PGNotificationFilter_Scan() - type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &nf)
+}
+
 func (phm PGHostManifest) Value() (driver.Value, error) {
 	return json.Marshal(phm)
 }
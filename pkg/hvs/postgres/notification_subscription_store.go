@@ -0,0 +1,243 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/utils"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// NotificationSubscriptionStore stores the webhook subscriptions, their secrets are encrypted with the data
+// encryption key
+type NotificationSubscriptionStore struct {
+	Store *DataStore
+	Dek   []byte
+}
+
+func NewNotificationSubscriptionStore(store *DataStore, dek []byte) *NotificationSubscriptionStore {
+	return &NotificationSubscriptionStore{store, dek}
+}
+
+func (n *NotificationSubscriptionStore) Create(s *hvs.NotificationSubscription) (*hvs.NotificationSubscription, error) {
+	defaultLog.Trace("postgres/notification_subscription_store:Create() Entering")
+	defer defaultLog.Trace("postgres/notification_subscription_store:Create() Leaving")
+
+	if s.Id == uuid.Nil {
+		newUuid, err := uuid.NewRandom()
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/notification_subscription_store:Create() failed to create new UUID")
+		}
+		s.Id = newUuid
+	}
+	s.CreatedAt = time.Now().UTC()
+
+	encSecret, err := utils.EncryptString(s.Secret, n.Dek)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/notification_subscription_store:Create() Failed to encrypt secret")
+	}
+	dbSubscription := notificationSubscription{
+		ID:     s.Id,
+		URL:    s.URL,
+		Secret: encSecret,
+		Filter: PGNotificationFilter{
+			HostIds:        s.HostIds,
+			FlavorgroupIds: s.FlavorgroupIds,
+			FlavorParts:    s.FlavorParts,
+		},
+		CreatedAt: s.CreatedAt,
+	}
+	if err := n.Store.Db.Create(&dbSubscription).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/notification_subscription_store:Create() Failed to create subscription")
+	}
+	return s, nil
+}
+
+func (n *NotificationSubscriptionStore) Retrieve(id uuid.UUID) (*hvs.NotificationSubscription, error) {
+	defaultLog.Trace("postgres/notification_subscription_store:Retrieve() Entering")
+	defer defaultLog.Trace("postgres/notification_subscription_store:Retrieve() Leaving")
+
+	dbSubscription := notificationSubscription{}
+	if err := n.Store.Db.Where(&notificationSubscription{ID: id}).First(&dbSubscription).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/notification_subscription_store:Retrieve() Failed to retrieve subscription")
+	}
+	return n.toSubscription(dbSubscription)
+}
+
+func (n *NotificationSubscriptionStore) Search(criteria *models.NotificationSubscriptionFilterCriteria) ([]hvs.NotificationSubscription, error) {
+	defaultLog.Trace("postgres/notification_subscription_store:Search() Entering")
+	defer defaultLog.Trace("postgres/notification_subscription_store:Search() Leaving")
+
+	tx := n.Store.Db.Model(&notificationSubscription{})
+	if criteria != nil {
+		if criteria.HostId != uuid.Nil {
+			tx = tx.Where("filter->'host_ids' @> ?", fmt.Sprintf(`["%s"]`, criteria.HostId))
+		}
+		if criteria.FlavorgroupId != uuid.Nil {
+			tx = tx.Where("filter->'flavorgroup_ids' @> ?", fmt.Sprintf(`["%s"]`, criteria.FlavorgroupId))
+		}
+		if criteria.FlavorPart != "" {
+			tx = tx.Where("filter->'flavor_parts' @> ?", fmt.Sprintf(`[%q]`, criteria.FlavorPart))
+		}
+	}
+
+	var dbSubscriptions []notificationSubscription
+	if err := tx.Order("created").Find(&dbSubscriptions).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/notification_subscription_store:Search() Failed to retrieve records from db")
+	}
+
+	subscriptions := []hvs.NotificationSubscription{}
+	for _, dbSubscription := range dbSubscriptions {
+		subscription, err := n.toSubscription(dbSubscription)
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/notification_subscription_store:Search() Failed to read subscription")
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+	return subscriptions, nil
+}
+
+func (n *NotificationSubscriptionStore) Delete(id uuid.UUID) error {
+	defaultLog.Trace("postgres/notification_subscription_store:Delete() Entering")
+	defer defaultLog.Trace("postgres/notification_subscription_store:Delete() Leaving")
+
+	if err := n.Store.Db.Delete(&notificationSubscription{ID: id}).Error; err != nil {
+		return errors.Wrap(err, "postgres/notification_subscription_store:Delete() Failed to delete subscription")
+	}
+	return nil
+}
+
+func (n *NotificationSubscriptionStore) CreateDelivery(d *hvs.NotificationDelivery) (*hvs.NotificationDelivery, error) {
+	defaultLog.Trace("postgres/notification_subscription_store:CreateDelivery() Entering")
+	defer defaultLog.Trace("postgres/notification_subscription_store:CreateDelivery() Leaving")
+
+	if d.Id == uuid.Nil {
+		newUuid, err := uuid.NewRandom()
+		if err != nil {
+			return nil, errors.Wrap(err, "postgres/notification_subscription_store:CreateDelivery() failed to create new UUID")
+		}
+		d.Id = newUuid
+	}
+	d.CreatedAt = time.Now().UTC()
+	d.UpdatedAt = d.CreatedAt
+
+	dbDelivery := toDBNotificationDelivery(d)
+	if err := n.Store.Db.Create(&dbDelivery).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/notification_subscription_store:CreateDelivery() Failed to create delivery")
+	}
+	return d, nil
+}
+
+func (n *NotificationSubscriptionStore) UpdateDelivery(d *hvs.NotificationDelivery) error {
+	defaultLog.Trace("postgres/notification_subscription_store:UpdateDelivery() Entering")
+	defer defaultLog.Trace("postgres/notification_subscription_store:UpdateDelivery() Leaving")
+
+	if d.Id == uuid.Nil {
+		return errors.New("postgres/notification_subscription_store:UpdateDelivery() ID must be specified")
+	}
+	d.UpdatedAt = time.Now().UTC()
+
+	dbDelivery := toDBNotificationDelivery(d)
+	if err := n.Store.Db.Save(&dbDelivery).Error; err != nil {
+		return errors.Wrap(err, "postgres/notification_subscription_store:UpdateDelivery() Failed to update delivery")
+	}
+	return nil
+}
+
+func (n *NotificationSubscriptionStore) SearchDeliveries(criteria *models.NotificationDeliveryFilterCriteria) ([]hvs.NotificationDelivery, error) {
+	defaultLog.Trace("postgres/notification_subscription_store:SearchDeliveries() Entering")
+	defer defaultLog.Trace("postgres/notification_subscription_store:SearchDeliveries() Leaving")
+
+	tx := buildNotificationDeliverySearchQuery(n.Store.Db, criteria)
+	if tx == nil {
+		return nil, errors.New("postgres/notification_subscription_store:SearchDeliveries() Unexpected Error. Could not build" +
+			" a gorm query object.")
+	}
+
+	var dbDeliveries []notificationDelivery
+	if err := tx.Find(&dbDeliveries).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/notification_subscription_store:SearchDeliveries() Failed to retrieve records from db")
+	}
+
+	deliveries := []hvs.NotificationDelivery{}
+	for _, d := range dbDeliveries {
+		deliveries = append(deliveries, hvs.NotificationDelivery{
+			Id:             d.ID,
+			SubscriptionId: d.SubscriptionID,
+			HostId:         d.HostID,
+			Event:          d.Event,
+			Attempts:       d.Attempts,
+			StatusCode:     d.StatusCode,
+			Delivered:      d.Delivered,
+			Error:          d.Error,
+			CreatedAt:      d.CreatedAt,
+			UpdatedAt:      d.UpdatedAt,
+		})
+	}
+	return deliveries, nil
+}
+
+func (n *NotificationSubscriptionStore) toSubscription(s notificationSubscription) (*hvs.NotificationSubscription, error) {
+	secret, err := utils.DecryptString(s.Secret, n.Dek)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to decrypt secret")
+	}
+	return &hvs.NotificationSubscription{
+		Id:             s.ID,
+		URL:            s.URL,
+		Secret:         secret,
+		HostIds:        s.Filter.HostIds,
+		FlavorgroupIds: s.Filter.FlavorgroupIds,
+		FlavorParts:    s.Filter.FlavorParts,
+		CreatedAt:      s.CreatedAt,
+	}, nil
+}
+
+func toDBNotificationDelivery(d *hvs.NotificationDelivery) notificationDelivery {
+	return notificationDelivery{
+		ID:             d.Id,
+		SubscriptionID: d.SubscriptionId,
+		HostID:         d.HostId,
+		Event:          d.Event,
+		Attempts:       d.Attempts,
+		StatusCode:     d.StatusCode,
+		Delivered:      d.Delivered,
+		Error:          d.Error,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+}
+
+// Helper function to build the query object for a notification delivery search, the most recent deliveries first.
+func buildNotificationDeliverySearchQuery(tx *gorm.DB, criteria *models.NotificationDeliveryFilterCriteria) *gorm.DB {
+	defaultLog.Trace("postgres/notification_subscription_store:buildNotificationDeliverySearchQuery() Entering")
+	defer defaultLog.Trace("postgres/notification_subscription_store:buildNotificationDeliverySearchQuery() Leaving")
+
+	if tx == nil {
+		return nil
+	}
+	tx = tx.Model(&notificationDelivery{})
+	if criteria == nil {
+		return tx.Order("created desc")
+	}
+	if criteria.SubscriptionId != uuid.Nil {
+		tx = tx.Where("subscription_id = ?", criteria.SubscriptionId)
+	}
+	if criteria.Delivered != nil {
+		tx = tx.Where("delivered = ?", *criteria.Delivered)
+	}
+	tx = tx.Order("created desc")
+	if criteria.Limit > 0 {
+		tx = tx.Limit(criteria.Limit)
+	}
+	return tx
+}
@@ -193,7 +193,7 @@ func (r *ReportStore) Search(criteria *models.ReportFilterCriteria) ([]models.HV
 
 		return reports, nil
 	} else {
-		tx = buildReportSearchQuery(r.Store.Db, hostID, hostHardwareUUID, hostName, hostStatus, fromDate, toDate, latestPerHost, criteria.Limit)
+		tx = buildReportSearchQuery(r.Store.Db, hostID, hostHardwareUUID, hostName, hostStatus, fromDate, toDate, latestPerHost, criteria.CreatedOnly, criteria.Limit)
 		if tx == nil {
 			return nil, errors.New("postgres/report_store:Search() Unexpected Error. Could not build" +
 				" a gorm query object in HVSReport Search function.")
@@ -231,12 +231,14 @@ func (r *ReportStore) Search(criteria *models.ReportFilterCriteria) ([]models.HV
 }
 
 // FindHostIdsFromExpiredReports searches the report table for reports that have an
-// 'expiration' between 'fromTime' and 'toTime'.
+// 'expiration' between 'fromTime' and 'toTime'. Push-mode hosts are skipped since their
+// reports are refreshed when they submit a new quote.
 func (r *ReportStore) FindHostIdsFromExpiredReports(fromTime time.Time, toTime time.Time) ([]uuid.UUID, error) {
 
 	var tx *gorm.DB
 	tx = r.Store.Db.Table("host h").Select("h.id")
 	tx = tx.Joins("INNER JOIN report r on h.id = r.host_id")
+	tx = tx.Where("h.push_mode = ?", false)
 	tx = tx.Where("CAST(expiration AS TIMESTAMP) > CAST(? AS TIMESTAMP)", fromTime)
 	tx = tx.Where("CAST(expiration AS TIMESTAMP) <= CAST(? AS TIMESTAMP)", toTime)
 
@@ -337,7 +339,7 @@ func (r *ReportStore) Delete(reportId uuid.UUID) error {
 }
 
 // buildReportSearchQuery is a helper function to build the query object for a report search.
-func buildReportSearchQuery(tx *gorm.DB, hostHardwareID, hostID uuid.UUID, hostName, hostState string, fromDate, toDate time.Time, latestPerHost bool, limit int) *gorm.DB {
+func buildReportSearchQuery(tx *gorm.DB, hostHardwareID, hostID uuid.UUID, hostName, hostState string, fromDate, toDate time.Time, latestPerHost, createdOnly bool, limit int) *gorm.DB {
 	defaultLog.Trace("postgres/report_store:buildReportSearchQuery() Entering")
 	defer defaultLog.Trace("postgres/report_store:buildReportSearchQuery() Leaving")
 	if tx == nil {
@@ -354,6 +356,11 @@ func buildReportSearchQuery(tx *gorm.DB, hostHardwareID, hostID uuid.UUID, hostN
 		entity := "au"
 		tx = tx.Table("audit_log_entry au").Select("au.*")
 		tx = buildReportSearchQueryWithCriteria(tx, hostHardwareID, hostID, entity, hostName, hostState, fromDate, toDate)
+		if createdOnly {
+			tx = tx.Where(entity + ".action = 'create'")
+		}
+		// the oldest entries first so that the limit keeps a continuous range of the history
+		tx = tx.Order(entity + ".created")
 	}
 	tx = tx.Limit(limit)
 	return tx
@@ -0,0 +1,35 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package postgres
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportSearchCreatedSinceDate(t *testing.T) {
+	assert := assert.New(t)
+
+	dataStore, mock := NewSQLMockDataStore()
+	reportStore := NewReportStore(dataStore)
+
+	// the copies of the replaced reports are left out and the oldest entries are kept by the limit
+	fromDate := time.Date(2020, 6, 21, 7, 18, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT au.* FROM audit_log_entry au WHERE (au.entity_type = 'report') AND ` +
+		`(CAST(au.created AS TIMESTAMP) >= CAST($1 AS TIMESTAMP)) AND (au.action = 'create') ORDER BY "au"."created" LIMIT 3`)).
+		WithArgs(fromDate).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "entity_id", "entity_type", "created", "action", "data"}))
+
+	reports, err := reportStore.Search(&models.ReportFilterCriteria{FromDate: fromDate, CreatedOnly: true, Limit: 3})
+	assert.NoError(err)
+	assert.Empty(reports)
+	assert.NoError(mock.ExpectationsWereMet())
+}
@@ -96,6 +96,25 @@ func XMLResponseHandler(h func(http.ResponseWriter, *http.Request) (interface{},
 	}
 }
 
+// StreamResponseHandler handler for the handler functions that write a stream of server-sent events, the handler
+// function writes the response itself unless it returns an error
+func StreamResponseHandler(h func(http.ResponseWriter, *http.Request) (interface{}, int, error)) endpointHandler {
+	defaultLog.Trace("router/handlers:StreamResponseHandler() Entering")
+	defer defaultLog.Trace("router/handlers:StreamResponseHandler() Leaving")
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if r.Header.Get("Accept") != constants.HTTPMediaTypeEventStream {
+			return errorFormatter(&commErr.EndpointError{
+				Message: "Invalid Accept type",
+			}, http.StatusUnsupportedMediaType)
+		}
+		if _, status, err := h(w, r); err != nil {
+			return errorFormatter(err, status)
+		}
+		return nil
+	}
+}
+
 func errorFormatter(err error, status int) error {
 	defaultLog.Trace("router/handlers:errorFormatter() Entering")
 	defer defaultLog.Trace("router/handlers:errorFormatter() Leaving")
@@ -0,0 +1,38 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package router
+
+import (
+	"fmt"
+
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/postgres"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/validation"
+)
+
+// SetHostQuoteRoutes registers routes for push-mode hosts to submit their quotes
+func SetHostQuoteRoutes(router *mux.Router, store *postgres.DataStore, certStore *models.CertificatesStore, hostTrustManager domain.HostTrustManager) *mux.Router {
+	defaultLog.Trace("router/host_quotes:SetHostQuoteRoutes() Entering")
+	defer defaultLog.Trace("router/host_quotes:SetHostQuoteRoutes() Leaving")
+
+	hostStore := postgres.NewHostStore(store)
+	hostStatusStore := postgres.NewHostStatusStore(store)
+	hostQuoteStore := postgres.NewHostQuoteStore(store)
+	hostQuoteController := controllers.NewHostQuoteController(hostStore, hostStatusStore, hostQuoteStore, hostTrustManager,
+		certStore, constants.DefaultHostQuoteNonceValidity)
+
+	hostIdExpr := fmt.Sprintf("/hosts/{hId:%s}", validation.UUIDReg)
+
+	router.Handle(hostIdExpr+"/quote-nonce", ErrorHandler(permissionsHandler(JsonResponseHandler(hostQuoteController.CreateNonce),
+		[]string{constants.HostQuoteCreate}))).Methods("POST")
+	router.Handle(hostIdExpr+"/quotes", ErrorHandler(permissionsHandler(ResponseHandler(hostQuoteController.Submit),
+		[]string{constants.HostQuoteCreate}))).Methods("POST")
+
+	return router
+}
@@ -0,0 +1,50 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package router
+
+import (
+	"fmt"
+
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/controllers"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/postgres"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/services/notification"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/validation"
+)
+
+// SetNotificationSubscriptionRoutes registers routes for the webhook subscriptions to host trust changes
+func SetNotificationSubscriptionRoutes(router *mux.Router, store *postgres.DataStore, dek []byte, addressFilter *notification.AddressFilter) *mux.Router {
+	defaultLog.Trace("router/notification_subscription:SetNotificationSubscriptionRoutes() Entering")
+	defer defaultLog.Trace("router/notification_subscription:SetNotificationSubscriptionRoutes() Leaving")
+
+	subscriptionStore := postgres.NewNotificationSubscriptionStore(store, dek)
+	subscriptionController := controllers.NewNotificationSubscriptionController(subscriptionStore, addressFilter)
+
+	subscriptionIdExpr := fmt.Sprintf("%s%s", "/notification-subscriptions/", validation.IdReg)
+
+	router.Handle("/notification-subscriptions",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(subscriptionController.Create),
+			[]string{constants.NotificationSubscriptionCreate}))).Methods("POST")
+
+	router.Handle("/notification-subscriptions",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(subscriptionController.Search),
+			[]string{constants.NotificationSubscriptionSearch}))).Methods("GET")
+
+	router.Handle(subscriptionIdExpr,
+		ErrorHandler(permissionsHandler(JsonResponseHandler(subscriptionController.Retrieve),
+			[]string{constants.NotificationSubscriptionRetrieve}))).Methods("GET")
+
+	router.Handle(subscriptionIdExpr,
+		ErrorHandler(permissionsHandler(ResponseHandler(subscriptionController.Delete),
+			[]string{constants.NotificationSubscriptionDelete}))).Methods("DELETE")
+
+	router.Handle(subscriptionIdExpr+"/deliveries",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(subscriptionController.SearchDeliveries),
+			[]string{constants.NotificationSubscriptionRetrieve}))).Methods("GET")
+
+	return router
+}
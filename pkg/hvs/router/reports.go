@@ -6,6 +6,7 @@ package router
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/constants"
@@ -16,15 +17,18 @@ import (
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/validation"
 )
 
-// SetReportRoutes registers routes for reports
-func SetReportRoutes(router *mux.Router, store *postgres.DataStore, hostTrustManager domain.HostTrustManager) *mux.Router {
+// SetReportRoutes registers routes for reports, the report stream is closed after streamTimeout when it is set
+func SetReportRoutes(router *mux.Router, store *postgres.DataStore, hostTrustManager domain.HostTrustManager, reportBroadcaster domain.ReportBroadcaster, streamTimeout time.Duration) *mux.Router {
 	defaultLog.Trace("router/reports:SetReportRoutes() Entering")
 	defer defaultLog.Trace("router/reports:SetReportRoutes() Leaving")
 
 	reportStore := postgres.NewReportStore(store)
 	hostStore := postgres.NewHostStore(store)
 	hostStatusStore := postgres.NewHostStatusStore(store)
-	reportController := controllers.NewReportController(reportStore, hostStore, hostStatusStore, hostTrustManager)
+	flavorGroupStore := postgres.NewFlavorGroupStore(store)
+	reportController := controllers.NewReportController(reportStore, hostStore, hostStatusStore, flavorGroupStore, hostTrustManager)
+	reportController.ReportBroadcaster = reportBroadcaster
+	reportController.StreamTimeout = streamTimeout
 
 	reportIdExpr := fmt.Sprintf("%s%s", "/reports/", validation.IdReg)
 
@@ -40,6 +44,10 @@ func SetReportRoutes(router *mux.Router, store *postgres.DataStore, hostTrustMan
 		ErrorHandler(permissionsHandler(ResponseHandler(reportController.SearchSaml),
 			[]string{constants.ReportSearch}))).Methods("GET").Headers("Accept", consts.HTTPMediaTypeSaml)
 
+	router.Handle("/reports/stream",
+		ErrorHandler(permissionsHandler(StreamResponseHandler(reportController.Stream),
+			[]string{constants.ReportSearch}))).Methods("GET")
+
 	router.Handle(reportIdExpr,
 		ErrorHandler(permissionsHandler(JsonResponseHandler(reportController.Retrieve),
 			[]string{constants.ReportRetrieve}))).Methods("GET")
@@ -5,24 +5,24 @@
 package router
 
 import (
-	"crypto/tls"
-	"crypto/x509"
-	"io/ioutil"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain"
 
 	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/clients"
+	"github.com/intel-secl/intel-secl/v3/pkg/clients/aas"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/config"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/constants"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/postgres"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/services/notification"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
 	cmw "github.com/intel-secl/intel-secl/v3/pkg/lib/common/middleware"
-	cos "github.com/intel-secl/intel-secl/v3/pkg/lib/common/os"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
 	"github.com/pkg/errors"
 )
 
@@ -34,7 +34,7 @@ type Router struct {
 }
 
 // InitRoutes registers all routes for the application.
-func InitRoutes(cfg *config.Configuration, dataStore *postgres.DataStore, fgs *postgres.FlavorGroupStore, certStore *models.CertificatesStore, hostTrustManager domain.HostTrustManager, hostControllerConfig domain.HostControllerConfig) (*mux.Router, error) {
+func InitRoutes(cfg *config.Configuration, dataStore *postgres.DataStore, fgs *postgres.FlavorGroupStore, certStore *models.CertificatesStore, hostTrustManager domain.HostTrustManager, reportBroadcaster domain.ReportBroadcaster, hostControllerConfig domain.HostControllerConfig) (*mux.Router, error) {
 	defaultLog.Trace("router/router:InitRoutes() Entering")
 	defer defaultLog.Trace("router/router:InitRoutes() Leaving")
 
@@ -43,18 +43,18 @@ func InitRoutes(cfg *config.Configuration, dataStore *postgres.DataStore, fgs *p
 
 	// ISECL-8715 - Prevent potential open redirects to external URLs
 	router.SkipClean(true)
-	err := defineSubRoutes(router, constants.OldServiceName, cfg, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig)
+	err := defineSubRoutes(router, constants.OldServiceName, cfg, dataStore, fgs, certStore, hostTrustManager, reportBroadcaster, hostControllerConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "Could not define sub routes")
 	}
-	err = defineSubRoutes(router, strings.ToLower(constants.ServiceName), cfg, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig)
+	err = defineSubRoutes(router, strings.ToLower(constants.ServiceName), cfg, dataStore, fgs, certStore, hostTrustManager, reportBroadcaster, hostControllerConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "Could not define sub routes")
 	}
 	return router, nil
 }
 
-func defineSubRoutes(router *mux.Router, service string, cfg *config.Configuration, dataStore *postgres.DataStore, fgs *postgres.FlavorGroupStore, certStore *models.CertificatesStore, hostTrustManager domain.HostTrustManager, hostControllerConfig domain.HostControllerConfig) error {
+func defineSubRoutes(router *mux.Router, service string, cfg *config.Configuration, dataStore *postgres.DataStore, fgs *postgres.FlavorGroupStore, certStore *models.CertificatesStore, hostTrustManager domain.HostTrustManager, reportBroadcaster domain.ReportBroadcaster, hostControllerConfig domain.HostControllerConfig) error {
 	defaultLog.Trace("router/router:defineSubRoutes() Entering")
 	defer defaultLog.Trace("router/router:defineSubRoutes() Leaving")
 
@@ -69,9 +69,17 @@ func defineSubRoutes(router *mux.Router, service string, cfg *config.Configurati
 	if err != nil {
 		return errors.Wrap(err, "Could not parse JWT Certificate cache time")
 	}
-	subRouter.Use(cmw.NewTokenAuth(constants.TrustedJWTSigningCertsDir,
-		constants.TrustedRootCACertsDir, cfgRouter.fnGetJwtCerts,
-		cacheTime))
+	revocationListCacheTime, err := time.ParseDuration(constants.RevocationListCacheTime)
+	if err != nil {
+		return errors.Wrap(err, "Could not parse token revocation list cache time")
+	}
+	revocationListMaxStaleness, err := time.ParseDuration(constants.RevocationListMaxStaleness)
+	if err != nil {
+		return errors.Wrap(err, "Could not parse token revocation list max staleness")
+	}
+	subRouter.Use(cmw.NewTokenAuthWithJwks(constants.TrustedJWTSigningCertsDir,
+		constants.TrustedRootCACertsDir, cfgRouter.fnGetJwks,
+		cacheTime, cmw.NewRevocationListCache(cfgRouter.fnGetRevocationList, revocationListCacheTime, revocationListMaxStaleness)))
 	subRouter = SetFlavorGroupRoutes(subRouter, dataStore, fgs, hostTrustManager)
 	subRouter = SetFlavorTemplateRoutes(subRouter, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig)
 	subRouter = SetFlavorRoutes(subRouter, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig)
@@ -80,78 +88,76 @@ func defineSubRoutes(router *mux.Router, service string, cfg *config.Configurati
 	subRouter = SetHostStatusRoutes(subRouter, dataStore)
 	subRouter = SetCertifyHostKeysRoutes(subRouter, certStore)
 	subRouter = SetHostRoutes(subRouter, dataStore, hostTrustManager, hostControllerConfig)
-	subRouter = SetReportRoutes(subRouter, dataStore, hostTrustManager)
+	subRouter = SetHostQuoteRoutes(subRouter, dataStore, certStore, hostTrustManager)
+	// close the report streams before the server write timeout cuts them off, clients resume from the last event
+	var reportStreamTimeout time.Duration
+	if cfg.Server.WriteTimeout > constants.ReportStreamWriteTimeoutMargin {
+		reportStreamTimeout = cfg.Server.WriteTimeout - constants.ReportStreamWriteTimeoutMargin
+	}
+	subRouter = SetReportRoutes(subRouter, dataStore, hostTrustManager, reportBroadcaster, reportStreamTimeout)
 	subRouter = SetCreateCaCertificatesRoutes(subRouter, certStore)
 	subRouter = SetTagCertificateRoutes(subRouter, cfg, fgs, certStore, hostTrustManager, dataStore)
 	subRouter = SetESXiClusterRoutes(subRouter, dataStore, hostTrustManager, hostControllerConfig)
+	notificationAddressFilter, err := notification.NewAddressFilter(cfg.Notification.AllowedNetworks)
+	if err != nil {
+		return errors.Wrap(err, "Could not parse notification allowed networks")
+	}
+	subRouter = SetNotificationSubscriptionRoutes(subRouter, dataStore, hostControllerConfig.DataEncryptionKey, notificationAddressFilter)
 	subRouter = SetDeploySoftwareManifestRoute(subRouter, dataStore, hostTrustManager, hostControllerConfig)
 	subRouter = SetManifestsRoute(subRouter, dataStore)
 	subRouter = SetFlavorFromAppManifestRoute(subRouter, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig)
 	return nil
 }
 
-// Fetch JWT certificate from AAS
-//TODO: use interface to store save certificates
-func (r *Router) fnGetJwtCerts() error {
-	defaultLog.Trace("router/router:fnGetJwtCerts() Entering")
-	defer defaultLog.Trace("router/router:fnGetJwtCerts() Leaving")
+// Fetch the token revocation list from AAS
+func (r *Router) fnGetRevocationList() (*aasModel.RevocationList, error) {
+	defaultLog.Trace("router/router:fnGetRevocationList() Entering")
+	defer defaultLog.Trace("router/router:fnGetRevocationList() Leaving")
 
-	cfg := r.cfg
-	if !strings.HasSuffix(cfg.AASApiUrl, "/") {
-		cfg.AASApiUrl = cfg.AASApiUrl + "/"
-	}
-	url := cfg.AASApiUrl + "noauth/jwt-certificates"
-	req, err := http.NewRequest("GET", url, nil)
+	caCerts, err := crypt.GetCertsFromDir(constants.TrustedRootCACertsDir)
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Could not create http request")
+		return nil, errors.Wrap(err, "router/router:fnGetRevocationList() Could not read root CA certificates")
 	}
-	req.Header.Add("accept", "application/x-pem-file")
-	rootCaCertPems, err := cos.GetDirFileContents(constants.TrustedRootCACertsDir, "*.pem")
+	httpClient, err := clients.HTTPClientWithCA(caCerts)
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Could not read root CA certificate")
+		return nil, errors.Wrap(err, "router/router:fnGetRevocationList() Could not create http client")
 	}
-
-	// Get the SystemCertPool, continue with an empty pool on error
-	rootCAs, err := x509.SystemCertPool()
+	// the requests are checked against the previously retrieved list meanwhile, an unresponsive AAS must not
+	// keep it from being retrieved again
+	httpClient.Timeout, err = time.ParseDuration(constants.RevocationListTimeout)
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Failed defining certificate pool")
+		return nil, errors.Wrap(err, "router/router:fnGetRevocationList() Could not parse token revocation list timeout")
 	}
-	if rootCAs == nil {
-		rootCAs = x509.NewCertPool()
-	}
-	for _, rootCACert := range rootCaCertPems {
-		if ok := rootCAs.AppendCertsFromPEM(rootCACert); !ok {
-			return err
-		}
-	}
-	httpClient := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				MinVersion:         tls.VersionTLS12,
-				InsecureSkipVerify: false,
-				RootCAs:            rootCAs,
-			},
-		},
+	aasClient := aas.Client{BaseURL: r.cfg.AASApiUrl, HTTPClient: httpClient}
+	revocationList, err := aasClient.GetRevokedTokens()
+	if err != nil {
+		return nil, errors.Wrap(err, "router/router:fnGetRevocationList() Could not retrieve token revocation list")
 	}
+	return revocationList, nil
+}
+
+// Fetch the JWK set of the token signing keys from AAS
+func (r *Router) fnGetJwks() (*jwtauth.JSONWebKeySet, error) {
+	defaultLog.Trace("router/router:fnGetJwks() Entering")
+	defer defaultLog.Trace("router/router:fnGetJwks() Leaving")
 
-	res, err := httpClient.Do(req)
+	caCerts, err := crypt.GetCertsFromDir(constants.TrustedRootCACertsDir)
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Could not retrieve jwt certificate")
+		return nil, errors.Wrap(err, "router/router:fnGetJwks() Could not read root CA certificates")
 	}
-	defer func() {
-		derr := res.Body.Close()
-		if derr != nil {
-			defaultLog.WithError(derr).Error("Error closing response body")
-		}
-	}()
-
-	body, err := ioutil.ReadAll(res.Body)
+	httpClient, err := clients.HTTPClientWithCA(caCerts)
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Failed read response")
+		return nil, errors.Wrap(err, "router/router:fnGetJwks() Could not create http client")
 	}
-	err = crypt.SavePemCertWithShortSha1FileName(body, constants.TrustedJWTSigningCertsDir)
+	// the requests signed by a key that is not in the cached JWK set wait for the retrieval
+	httpClient.Timeout, err = time.ParseDuration(constants.JwksTimeout)
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Could not store Certificate")
+		return nil, errors.Wrap(err, "router/router:fnGetJwks() Could not parse JWK set timeout")
 	}
-	return nil
+	aasClient := aas.Client{BaseURL: r.cfg.AASApiUrl, HTTPClient: httpClient}
+	jwks, err := aasClient.GetJwks()
+	if err != nil {
+		return nil, errors.Wrap(err, "router/router:fnGetJwks() Could not retrieve JWK set")
+	}
+	return jwks, nil
 }
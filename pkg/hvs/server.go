@@ -8,6 +8,7 @@ import (
 	"context"
 	"crypto/rsa"
 	"crypto/tls"
+	"crypto/x509/pkix"
 	"encoding/base64"
 	"fmt"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/services/vcss"
@@ -26,7 +27,10 @@ import (
 	hostfetcher "github.com/intel-secl/intel-secl/v3/pkg/hvs/services/host-fetcher"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/services/hosttrust"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/services/hrrs"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/services/notification"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/setup"
+	commTls "github.com/intel-secl/intel-secl/v3/pkg/lib/common/tls"
 	hostconnector "github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/saml"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/verifier"
@@ -72,9 +76,27 @@ func (a *App) startServer() error {
 	// Load Certificates
 	certStore := utils.LoadCertificates(a.loadCertPathStore())
 
+	// Initialize the notifier of the host trust changes, only the public and the allowed networks are notified
+	notificationAddressFilter, err := notification.NewAddressFilter(c.Notification.AllowedNetworks)
+	if err != nil {
+		return errors.Wrap(err, "An error occurred while initializing trust change notifier")
+	}
+	notifier, err := notification.NewNotifier(domain.NotifierConfig{
+		SubscriptionStore: postgres.NewNotificationSubscriptionStore(dataStore, getDecodedDek(c)),
+		HostStore:         postgres.NewHostStore(dataStore),
+		Client:            notificationAddressFilter.NewClient(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "An error occurred while initializing trust change notifier")
+	}
+	defer notifier.Stop()
+
+	// Initialize the broadcaster of the trust reports to the report streams
+	reportBroadcaster := notification.NewReportBroadcaster(constants.DefaultReportStreamBufferSize)
+
 	// Initialize Host trust manager
 	fgs := postgres.NewFlavorGroupStore(dataStore)
-	hostTrustManager := initHostTrustManager(c, dataStore, fgs, certStore, alw)
+	hostTrustManager := initHostTrustManager(c, dataStore, fgs, certStore, alw, notifier, reportBroadcaster)
 	go hostTrustManager.ProcessQueue()
 
 	// create an instance of the HRRS and start it...
@@ -105,11 +127,30 @@ func (a *App) startServer() error {
 	}
 
 	// Initialize routes
-	routes, err := router.InitRoutes(c, dataStore, fgs, certStore, hostTrustManager, hostControllerConfig)
+	routes, err := router.InitRoutes(c, dataStore, fgs, certStore, hostTrustManager, reportBroadcaster, hostControllerConfig)
 	if err != nil {
 		return errors.Wrap(err, "An error occurred while initializing routes")
 	}
 
+	// Load the TLS certificate, it is reloaded when the files change and renewed with CMS before it expires
+	certReloader, err := commTls.NewCertReloader(c.TLS.CertFile, c.TLS.KeyFile, c.TLS.RenewBefore, c.TLS.ReloadInterval,
+		setup.RenewCertWithUser(setup.DownloadCert{
+			KeyFile:       c.TLS.KeyFile,
+			CertFile:      c.TLS.CertFile,
+			KeyAlgorithm:  constants.DefaultKeyAlgorithm,
+			KeyLength:     constants.DefaultKeyLength,
+			Subject:       pkix.Name{CommonName: c.TLS.CommonName},
+			SanList:       c.TLS.SANList,
+			CertType:      "tls",
+			CaCertDirPath: constants.TrustedCaCertsDir,
+			CmsBaseURL:    c.CMSBaseURL,
+		}, c.AASApiUrl, c.TLS.RenewUsername, c.TLS.RenewPassword))
+	if err != nil {
+		return errors.Wrap(err, "An error occurred while loading TLS certificate")
+	}
+	certReloader.Start()
+	defer certReloader.Stop()
+
 	defaultLog.Info("Starting server")
 	tlsConfig := &tls.Config{
 		MinVersion: tls.VersionTLS12,
@@ -117,6 +158,7 @@ func (a *App) startServer() error {
 			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
 			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
 			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+		GetCertificate: certReloader.GetCertificate,
 	}
 	// Setup signal handlers to gracefully handle termination
 	stop := make(chan os.Signal)
@@ -134,11 +176,9 @@ func (a *App) startServer() error {
 		MaxHeaderBytes:    c.Server.MaxHeaderBytes,
 	}
 
-	tlsCert := c.TLS.CertFile
-	tlsKey := c.TLS.KeyFile
 	// dispatch web server go routine
 	go func() {
-		if err := h.ListenAndServeTLS(tlsCert, tlsKey); err != nil {
+		if err := h.ListenAndServeTLS("", ""); err != nil {
 			defaultLog.WithError(err).Info("Failed to start HTTPS server")
 			stop <- syscall.SIGTERM
 		}
@@ -192,7 +232,7 @@ func getDecodedDek(cfg *config.Configuration) []byte {
 	return dek
 }
 
-func initHostTrustManager(cfg *config.Configuration, dataStore *postgres.DataStore, fgs *postgres.FlavorGroupStore, certStore *models.CertificatesStore, alw domain.AuditLogWriter) domain.HostTrustManager {
+func initHostTrustManager(cfg *config.Configuration, dataStore *postgres.DataStore, fgs *postgres.FlavorGroupStore, certStore *models.CertificatesStore, alw domain.AuditLogWriter, notifier domain.HostTrustNotifier, reportBroadcaster domain.ReportBroadcaster) domain.HostTrustManager {
 	defaultLog.Trace("server:InitHostTrustManager() Entering")
 	defer defaultLog.Trace("server:InitHostTrustManager() Leaving")
 
@@ -242,6 +282,8 @@ func initHostTrustManager(cfg *config.Configuration, dataStore *postgres.DataSto
 		CertsStore:                      *certStore,
 		SamlIssuerConfig:                samlIssuerConfig,
 		SkipFlavorSignatureVerification: cfg.FVS.SkipFlavorSignatureVerification,
+		Notifier:                        notifier,
+		ReportBroadcaster:               reportBroadcaster,
 	}
 
 	// Initialize Host Fetcher service
@@ -119,15 +119,20 @@ func (svc *Service) startWorkers(workers int) {
 
 func (svc *Service) VerifyHost(hostId uuid.UUID, fetchHostData, preferHashMatch bool) (*models.HVSReport, error) {
 	var hostData *types.HostManifest
+	var host *hvs.Host
 
 	if fetchHostData {
-		var host *hvs.Host
-		host, err := svc.hostStore.Retrieve(hostId)
+		var err error
+		host, err = svc.hostStore.Retrieve(hostId)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not retrieve host id "+hostId.String())
 		}
+		// push-mode hosts cannot be connected to, verify the last manifest they submitted instead
+		fetchHostData = !host.PushMode
+	}
 
-		hostData, err = svc.hdFetcher.Retrieve(context.Background(), hvs.Host{
+	if fetchHostData {
+		hostData, _ = svc.hdFetcher.Retrieve(context.Background(), hvs.Host{
 			Id:               host.Id,
 			ConnectionString: host.ConnectionString})
 
@@ -267,6 +272,8 @@ func (svc *Service) submitHostDataFetch(hostLists ...[]uuid.UUID) {
 			if host, err := svc.hostStore.Retrieve(hId); err != nil {
 				defaultLog.Info("hosttrust/manager:submitHostDataFetch() - error retrieving host data for id", hId)
 				continue
+			} else if host.PushMode {
+				svc.verifyPushedHostData(hId)
 			} else {
 				svc.mapmtx.Lock() //  need to update the record - so take a write lock
 				vtj, ok := svc.hosts[hId]
@@ -288,6 +295,30 @@ func (svc *Service) submitHostDataFetch(hostLists ...[]uuid.UUID) {
 	}
 }
 
+// verifyPushedHostData verifies the last manifest submitted by a push-mode host, since there is no data to fetch
+// from it. The job is dropped if the host has not submitted any data yet.
+func (svc *Service) verifyPushedHostData(hostId uuid.UUID) {
+	defaultLog.Trace("hosttrust/manager:verifyPushedHostData() Entering")
+	defer defaultLog.Trace("hosttrust/manager:verifyPushedHostData() Leaving")
+
+	hostStatusCollection, err := svc.hostStatusStore.Search(&models.HostStatusFilterCriteria{
+		HostId:        hostId,
+		LatestPerHost: true,
+	})
+	if err != nil || len(hostStatusCollection) == 0 || hostStatusCollection[0].HostStatusInformation.HostState != hvs.HostStateConnected {
+		defaultLog.Debugf("hosttrust/manager:verifyPushedHostData() Push-mode host %s has not submitted a manifest yet", hostId)
+		svc.deleteEntry(hostId)
+		return
+	}
+
+	svc.mapmtx.Lock()
+	if vtj, ok := svc.hosts[hostId]; ok {
+		vtj.getNewHostData = false
+	}
+	svc.mapmtx.Unlock()
+	svc.rqstChan <- hostId
+}
+
 func (svc *Service) queueFlavorVerify(hostsLists ...[]uuid.UUID) {
 	defaultLog.Trace("hosttrust/manager:queueFlavorVerify() Entering")
 	defer defaultLog.Trace("hosttrust/manager:queueFlavorVerify() Leaving")
@@ -493,6 +524,20 @@ func (svc *Service) ProcessHostData(ctx context.Context, host hvs.Host, data *ty
 		svc.deleteEntry(host.Id)
 	}
 
+	// data that was not requested by a queued job, like a manifest pushed by the host, gets a job of its own so that
+	// the verification is picked up again by ProcessQueue if the service is restarted
+	svc.mapmtx.RLock()
+	_, jobFound := svc.hosts[host.Id]
+	svc.mapmtx.RUnlock()
+	if !jobFound && err == nil {
+		if err := svc.persistToStore([]uuid.UUID{host.Id}, nil, false, false); err != nil {
+			return errors.Wrap(err, "hosttrust/manager:ProcessHostData() Error queueing host data for verification")
+		}
+		svc.mapmtx.RLock()
+		ctx = svc.hosts[host.Id].ctx
+		svc.mapmtx.RUnlock()
+	}
+
 	// queue the new data to be processed by one of the worker threads by adding this to the queue
 	taskstage.StoreInContext(ctx, taskstage.FlavorVerifyQueued)
 	svc.hfRqstChan <- newHostFetch{
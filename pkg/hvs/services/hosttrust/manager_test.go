@@ -6,6 +6,7 @@
 package hosttrust_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -30,6 +31,7 @@ var (
 	qs             domain.QueueStore
 	hs             *mocks.MockHostStore
 	hss            *mocks.MockHostStatusStore
+	rs             domain.ReportStore
 	cfg            domain.HostDataFetcherConfig
 	ht             domain.HostTrustManager
 	f              domain.HostDataFetcher
@@ -46,6 +48,7 @@ func SetupManagerTests() {
 	hs = mocks.NewMockHostStore()
 	hss = mocks.NewMockHostStatusStore()
 	hcs = mocks.NewMockHostCredentialStore()
+	rs = mocks.NewEmptyMockReportStore()
 
 	hwUuid = uuid.MustParse("0005AE6E-36D6-E711-906E-001560A04062")
 	hostId = uuid.MustParse("204466f6-8611-4e03-934d-832172a41917")
@@ -111,7 +114,7 @@ func SetupManagerTests() {
 		FlavorStore:                     flavorStore,
 		FlavorGroupStore:                flavorgroupStore,
 		HostStore:                       hs,
-		ReportStore:                     mocks.NewEmptyMockReportStore(),
+		ReportStore:                     rs,
 		FlavorVerifier:                  flvrVerifier,
 		SamlIssuerConfig:                *getIssuer(),
 		SkipFlavorSignatureVerification: true,
@@ -216,6 +219,26 @@ func TestManager_VerifyHostAsync(t *testing.T) {
 		"VerifyHostAsync should not return an error")
 }
 
+func TestManager_VerifyPushModeHost(t *testing.T) {
+	SetupManagerTests()
+	host, err := hs.Retrieve(hostId)
+	assert.NoError(t, err)
+	host.PushMode = true
+
+	_, err = service.VerifyHost(hostId, true, false)
+	assert.Error(t, err, "VerifyHost should not fetch data from push-mode hosts")
+
+	assert.NoError(t, service.ProcessHostData(context.Background(), *host, &hostManifest, nil),
+		"ProcessHostData should queue the data pushed by the host")
+	time.Sleep(time.Duration(2 * time.Second))
+	qrecs, err := qs.Search(&models.QueueFilterCriteria{})
+	assert.NoError(t, err)
+	assert.Empty(t, qrecs, "Verification of the pushed data should be completed")
+	reports, err := rs.Search(&models.ReportFilterCriteria{HostID: hostId})
+	assert.NoError(t, err)
+	assert.Len(t, reports, 1, "A report should be created from the pushed data")
+}
+
 func TestManager_VerifyQueueLogic(t *testing.T) {
 	SetupManagerTests()
 
@@ -5,9 +5,12 @@
 package mocks
 
 import (
+	"context"
 	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/mocks"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector/types"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
 	"time"
 )
 
@@ -28,3 +31,7 @@ func (mock *MockHostTrustManager) VerifyHostsAsync(hostIds []uuid.UUID, fetchHos
 func (mock *MockHostTrustManager) ProcessQueue() error {
 	return nil
 }
+
+func (mock *MockHostTrustManager) ProcessHostData(ctx context.Context, host hvs.Host, data *types.HostManifest, err error) error {
+	return nil
+}
@@ -30,6 +30,8 @@ type Verifier struct {
 	CertsStore                      models.CertificatesStore
 	SamlIssuer                      saml.IssuerConfiguration
 	SkipFlavorSignatureVerification bool
+	Notifier                        domain.HostTrustNotifier
+	ReportBroadcaster               domain.ReportBroadcaster
 }
 
 func NewVerifier(cfg domain.HostTrustVerifierConfig) domain.HostTrustVerifier {
@@ -42,6 +44,8 @@ func NewVerifier(cfg domain.HostTrustVerifierConfig) domain.HostTrustVerifier {
 		CertsStore:                      cfg.CertsStore,
 		SamlIssuer:                      cfg.SamlIssuerConfig,
 		SkipFlavorSignatureVerification: cfg.SkipFlavorSignatureVerification,
+		Notifier:                        cfg.Notifier,
+		ReportBroadcaster:               cfg.ReportBroadcaster,
 	}
 }
 
@@ -196,9 +200,58 @@ func (v *Verifier) storeTrustReport(hostID uuid.UUID, trustReport *hvs.TrustRepo
 		Expiration:  samlReport.ExpiryTime,
 		Saml:        samlReport.Assertion,
 	}
+	var previousReports []models.HVSReport
+	if v.Notifier != nil {
+		var err error
+		previousReports, err = v.ReportStore.Search(&models.ReportFilterCriteria{HostID: hostID, LatestPerHost: true})
+		if err != nil {
+			log.WithError(err).Errorf("hosttrust/verifier:storeTrustReport() Failed to retrieve previous Report")
+		}
+	}
 	report, err := v.ReportStore.Update(&hvsReport)
 	if err != nil {
 		log.WithError(err).Errorf("hosttrust/verifier:storeTrustReport() Failed to store Report")
+		return report
+	}
+	if v.ReportBroadcaster != nil {
+		v.ReportBroadcaster.Publish(report)
+	}
+	if len(previousReports) > 0 {
+		if change := getTrustChange(&previousReports[0].TrustReport, report); change != nil {
+			v.Notifier.Notify(change)
+		}
 	}
 	return report
 }
+
+// getTrustChange compares the overall trust and the trust of each flavor part of a new report with the previous
+// report of the host, nil is returned when none of them changed
+func getTrustChange(previous *hvs.TrustReport, report *models.HVSReport) *hvs.HostTrustChange {
+	var flavorParts []hvs.FlavorPartTrustChange
+	for _, flavorPart := range common.GetFlavorTypesString(common.GetFlavorTypes()) {
+		if len(previous.GetResultsForMarker(flavorPart)) == 0 && len(report.TrustReport.GetResultsForMarker(flavorPart)) == 0 {
+			continue
+		}
+		trusted := report.TrustReport.IsTrustedForMarker(flavorPart)
+		previouslyTrusted := previous.IsTrustedForMarker(flavorPart)
+		if trusted != previouslyTrusted {
+			flavorParts = append(flavorParts, hvs.FlavorPartTrustChange{
+				FlavorPart:    flavorPart,
+				Trusted:       trusted,
+				PreviousTrust: previouslyTrusted,
+			})
+		}
+	}
+	if report.TrustReport.Trusted == previous.Trusted && len(flavorParts) == 0 {
+		return nil
+	}
+	return &hvs.HostTrustChange{
+		Event:         hvs.HostTrustChangedEvent,
+		HostId:        report.HostID,
+		Trusted:       report.TrustReport.Trusted,
+		PreviousTrust: previous.Trusted,
+		FlavorParts:   flavorParts,
+		ReportId:      report.ID,
+		Time:          report.CreatedAt,
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package hosttrust
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/flavor/common"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTrustReport returns a report with a PLATFORM and an OS rule, the untrusted rules have a fault
+func newTrustReport(platformTrusted, osTrusted bool) hvs.TrustReport {
+	report := hvs.TrustReport{}
+	for flavorPart, trusted := range map[common.FlavorPart]bool{common.FlavorPartPlatform: platformTrusted, common.FlavorPartOs: osTrusted} {
+		result := hvs.RuleResult{Rule: hvs.RuleInfo{Markers: []common.FlavorPart{flavorPart}}, Trusted: trusted}
+		if !trusted {
+			result.Faults = []hvs.Fault{{Name: "PcrValueMismatch"}}
+		}
+		report.Results = append(report.Results, result)
+	}
+	report.Trusted = report.IsTrusted()
+	return report
+}
+
+func TestGetTrustChange(t *testing.T) {
+	a := assert.New(t)
+
+	previous := newTrustReport(true, true)
+	report := &models.HVSReport{ID: uuid.New(), HostID: uuid.New(), TrustReport: newTrustReport(true, true)}
+	a.Nil(getTrustChange(&previous, report))
+
+	report.TrustReport = newTrustReport(true, false)
+	change := getTrustChange(&previous, report)
+	if !a.NotNil(change) {
+		return
+	}
+	a.Equal(hvs.HostTrustChangedEvent, change.Event)
+	a.Equal(report.HostID, change.HostId)
+	a.Equal(report.ID, change.ReportId)
+	a.False(change.Trusted)
+	a.True(change.PreviousTrust)
+	a.Equal([]hvs.FlavorPartTrustChange{{FlavorPart: "OS", Trusted: false, PreviousTrust: true}}, change.FlavorParts)
+
+	// a flavor part can change while the host stays untrusted
+	previous = newTrustReport(false, false)
+	report.TrustReport = newTrustReport(true, false)
+	change = getTrustChange(&previous, report)
+	if !a.NotNil(change) {
+		return
+	}
+	a.False(change.Trusted)
+	a.False(change.PreviousTrust)
+	a.Equal([]hvs.FlavorPartTrustChange{{FlavorPart: "PLATFORM", Trusted: true, PreviousTrust: false}}, change.FlavorParts)
+}
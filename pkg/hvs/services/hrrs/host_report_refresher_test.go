@@ -6,6 +6,7 @@
 package hrrs
 
 import (
+	"context"
 	log "github.com/sirupsen/logrus"
 	"testing"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/mocks"
 	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector/types"
 	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -115,6 +117,10 @@ func (htm MockHostTrustManager) ProcessQueue() error {
 	return errors.New("ProcessQueue is not implemented")
 }
 
+func (htm MockHostTrustManager) ProcessHostData(ctx context.Context, host hvs.Host, data *types.HostManifest, err error) error {
+	return errors.New("ProcessHostData is not implemented")
+}
+
 func (htm MockHostTrustManager) VerifyHostsAsync(hostIDs []uuid.UUID, fetchHostData, preferHashMatch bool) error {
 
 	for _, hostID := range hostIDs {
@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package notification
+
+import (
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/constants"
+	"github.com/pkg/errors"
+)
+
+// AddressFilter keeps the notifications away from the loopback, link-local, private and other non public addresses
+// unless their network has been explicitly allowed in the configuration
+type AddressFilter struct {
+	allowedNetworks []*net.IPNet
+}
+
+// NewAddressFilter creates a filter that permits the public addresses and the addresses in the allowed networks,
+// given in CIDR notation
+func NewAddressFilter(allowedNetworks []string) (*AddressFilter, error) {
+	filter := &AddressFilter{}
+	for _, cidr := range allowedNetworks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "notification/address_filter:NewAddressFilter() Invalid allowed network %s", cidr)
+		}
+		filter.allowedNetworks = append(filter.allowedNetworks, network)
+	}
+	return filter, nil
+}
+
+// Permits checks that the address is public or belongs to one of the allowed networks
+func (filter *AddressFilter) Permits(ip net.IP) bool {
+	for _, network := range filter.allowedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return !(ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() || isPrivate(ip))
+}
+
+// CheckHost rejects a host given as a non permitted address or resolving to one. A host that cannot be resolved is
+// accepted as the address is checked again when connecting to it
+func (filter *AddressFilter) CheckHost(host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !filter.Permits(ip) {
+			return errors.Errorf("notification/address_filter:CheckHost() Address %s is not permitted", ip)
+		}
+		return nil
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		defaultLog.WithError(err).Debugf("notification/address_filter:CheckHost() Could not resolve %s", host)
+		return nil
+	}
+	for _, ip := range ips {
+		if !filter.Permits(ip) {
+			return errors.Errorf("notification/address_filter:CheckHost() Host %s resolves to address %s which is not permitted", host, ip)
+		}
+	}
+	return nil
+}
+
+// control is used by the dialer of the notification client so that the address is checked after the host has been
+// resolved, a host cannot be rebound to a non permitted address once its subscription has been created
+func (filter *AddressFilter) control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.Wrap(err, "notification/address_filter:control() Invalid address")
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !filter.Permits(ip) {
+		return errors.Errorf("notification/address_filter:control() Connection to %s is not permitted", address)
+	}
+	return nil
+}
+
+// NewClient creates the client posting the notifications, it connects to the permitted addresses only and does not
+// follow redirects as the payloads are signed
+func (filter *AddressFilter) NewClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout: constants.DefaultNotificationTimeout,
+		Control: filter.control,
+	}).DialContext
+	return &http.Client{
+		Transport: transport,
+		Timeout:   constants.DefaultNotificationTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isPrivate reports whether the address belongs to the RFC 1918, RFC 6598 shared or RFC 4193 unique local networks,
+// net.IP.IsPrivate is not available before go 1.17
+func isPrivate(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4[0] == 10 ||
+			(ip4[0] == 172 && ip4[1]&0xf0 == 16) ||
+			(ip4[0] == 192 && ip4[1] == 168) ||
+			(ip4[0] == 100 && ip4[1]&0xc0 == 64)
+	}
+	return len(ip) == net.IPv6len && ip[0]&0xfe == 0xfc
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package notification
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddressFilterPermits(t *testing.T) {
+	a := assert.New(t)
+
+	filter, err := NewAddressFilter([]string{"10.10.0.0/16", "fd00:1::/32"})
+	a.NoError(err)
+	for _, address := range []string{"127.0.0.1", "::1", "::ffff:127.0.0.1", "169.254.169.254", "fe80::1", "10.1.2.3",
+		"172.16.0.1", "172.31.255.255", "192.168.1.1", "100.64.0.1", "fd00:2::1", "0.0.0.0", "::", "224.0.0.1"} {
+		a.False(filter.Permits(net.ParseIP(address)), address)
+	}
+	for _, address := range []string{"8.8.8.8", "172.32.0.1", "2001:4860:4860::8888", "10.10.1.2", "fd00:1::1"} {
+		a.True(filter.Permits(net.ParseIP(address)), address)
+	}
+
+	a.Error(filter.CheckHost("127.0.0.1"))
+	a.Error(filter.CheckHost("localhost"))
+	a.NoError(filter.CheckHost("10.10.1.2"))
+
+	_, err = NewAddressFilter([]string{"10.10.0.0"})
+	a.Error(err)
+}
+
+func TestAddressFilterClientRefusesLoopback(t *testing.T) {
+	a := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// the host is checked once resolved, a subscription host rebound to the loopback address is not notified
+	_, err := (&AddressFilter{}).NewClient().Post(server.URL, "application/json", nil)
+	a.Error(err)
+	a.Contains(err.Error(), "is not permitted")
+
+	filter, err := NewAddressFilter([]string{"127.0.0.0/8"})
+	a.NoError(err)
+	resp, err := filter.NewClient().Post(server.URL, "application/json", nil)
+	a.NoError(err)
+	defer resp.Body.Close()
+	a.Equal(http.StatusNoContent, resp.StatusCode)
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package notification
+
+import (
+	"sync"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
+)
+
+// broadcaster publishes the reports to the subscribers of the report stream without blocking the verifier: the
+// channel of a subscriber whose buffer is full is closed, the subscriber resumes from its last report.
+type broadcaster struct {
+	bufferSize  int
+	subscribers map[chan *models.HVSReport]struct{}
+	lock        sync.Mutex
+}
+
+// NewReportBroadcaster returns a broadcaster buffering up to bufferSize reports for each subscriber
+func NewReportBroadcaster(bufferSize int) domain.ReportBroadcaster {
+	if bufferSize <= 0 {
+		bufferSize = constants.DefaultReportStreamBufferSize
+	}
+	return &broadcaster{
+		bufferSize:  bufferSize,
+		subscribers: make(map[chan *models.HVSReport]struct{}),
+	}
+}
+
+func (b *broadcaster) Publish(report *models.HVSReport) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- report:
+		default:
+			defaultLog.Warn("notification/broadcaster:Publish() Report stream subscriber is too slow, closing its stream")
+			delete(b.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+func (b *broadcaster) Subscribe() (<-chan *models.HVSReport, func()) {
+	subscriber := make(chan *models.HVSReport, b.bufferSize)
+	b.lock.Lock()
+	b.subscribers[subscriber] = struct{}{}
+	b.lock.Unlock()
+
+	return subscriber, func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		if _, ok := b.subscribers[subscriber]; ok {
+			delete(b.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package notification
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroadcasterPublishesToSubscribers(t *testing.T) {
+	a := assert.New(t)
+
+	b := NewReportBroadcaster(1)
+	first, cancelFirst := b.Subscribe()
+	second, cancelSecond := b.Subscribe()
+	defer cancelSecond()
+
+	report := &models.HVSReport{ID: uuid.New(), HostID: hostId}
+	b.Publish(report)
+	a.Equal(report, <-first)
+	a.Equal(report, <-second)
+
+	// a cancelled subscriber no longer receives the reports
+	cancelFirst()
+	cancelFirst()
+	b.Publish(report)
+	_, ok := <-first
+	a.False(ok)
+	a.Equal(report, <-second)
+}
+
+func TestBroadcasterClosesSlowSubscribers(t *testing.T) {
+	a := assert.New(t)
+
+	b := NewReportBroadcaster(1)
+	reports, cancel := b.Subscribe()
+	defer cancel()
+
+	b.Publish(&models.HVSReport{ID: uuid.New()})
+	b.Publish(&models.HVSReport{ID: uuid.New()})
+	_, ok := <-reports
+	a.True(ok)
+	_, ok = <-reports
+	a.False(ok)
+}
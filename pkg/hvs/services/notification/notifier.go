@@ -0,0 +1,260 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package notification
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/lib/common/constants"
+	commLog "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+var defaultLog = commLog.GetDefaultLogger()
+
+// notifier posts the trust changes of the hosts to the URLs of the matching subscriptions. The changes are queued
+// and dispatched by a single routine, each delivery is retried with an exponential backoff in its own routine so
+// that a slow subscriber does not delay the others.
+type notifier struct {
+	subscriptionStore domain.NotificationSubscriptionStore
+	hostStore         domain.HostStore
+	client            *http.Client
+	maxAttempts       int
+	retryBackoff      time.Duration
+
+	queue      chan *hvs.HostTrustChange
+	stopChan   chan struct{}
+	doneChan   chan struct{}
+	deliveries sync.WaitGroup
+}
+
+func NewNotifier(cfg domain.NotifierConfig) (domain.HostTrustNotifier, error) {
+	if cfg.SubscriptionStore == nil || cfg.HostStore == nil {
+		return nil, errors.New("notification/notifier:NewNotifier() subscription and host stores must be provided")
+	}
+	n := &notifier{
+		subscriptionStore: cfg.SubscriptionStore,
+		hostStore:         cfg.HostStore,
+		client:            cfg.Client,
+		maxAttempts:       cfg.MaxAttempts,
+		retryBackoff:      cfg.RetryBackoff,
+		stopChan:          make(chan struct{}),
+		doneChan:          make(chan struct{}),
+	}
+	if n.client == nil {
+		n.client = (&AddressFilter{}).NewClient()
+	}
+	if n.maxAttempts <= 0 {
+		n.maxAttempts = constants.DefaultNotificationMaxAttempts
+	}
+	if n.retryBackoff <= 0 {
+		n.retryBackoff = constants.DefaultNotificationRetryBackoff
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = constants.DefaultNotificationQueueSize
+	}
+	n.queue = make(chan *hvs.HostTrustChange, queueSize)
+
+	go n.run()
+	return n, nil
+}
+
+// Notify queues a trust change without blocking, the change is dropped when the queue is full
+func (n *notifier) Notify(change *hvs.HostTrustChange) {
+	select {
+	case n.queue <- change:
+	default:
+		defaultLog.Errorf("notification/notifier:Notify() Notification queue is full, dropping trust change of host %s", change.HostId)
+	}
+}
+
+// Stop dispatches the queued changes and waits for the pending deliveries, which are not retried any more
+func (n *notifier) Stop() {
+	close(n.stopChan)
+	<-n.doneChan
+	n.deliveries.Wait()
+}
+
+func (n *notifier) run() {
+	for {
+		select {
+		case change := <-n.queue:
+			n.dispatch(change)
+		case <-n.stopChan:
+			for len(n.queue) > 0 {
+				n.dispatch(<-n.queue)
+			}
+			close(n.doneChan)
+			return
+		}
+	}
+}
+
+func (n *notifier) dispatch(change *hvs.HostTrustChange) {
+	defaultLog.Trace("notification/notifier:dispatch() Entering")
+	defer defaultLog.Trace("notification/notifier:dispatch() Leaving")
+
+	if host, err := n.hostStore.Retrieve(change.HostId); err == nil {
+		change.HostName = host.HostName
+	} else {
+		defaultLog.WithError(err).Warnf("notification/notifier:dispatch() Failed to retrieve host %s", change.HostId)
+	}
+	flavorgroupIds, err := n.hostStore.SearchFlavorgroups(change.HostId)
+	if err != nil {
+		defaultLog.WithError(err).Errorf("notification/notifier:dispatch() Failed to retrieve flavorgroups of host %s", change.HostId)
+		return
+	}
+	change.FlavorgroupIds = flavorgroupIds
+
+	subscriptions, err := n.subscriptionStore.Search(nil)
+	if err != nil {
+		defaultLog.WithError(err).Error("notification/notifier:dispatch() Failed to retrieve notification subscriptions")
+		return
+	}
+	payload, err := json.Marshal(change)
+	if err != nil {
+		defaultLog.WithError(err).Error("notification/notifier:dispatch() Failed to marshal trust change")
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		if !matches(subscription, change) {
+			continue
+		}
+		n.deliveries.Add(1)
+		go func(subscription hvs.NotificationSubscription) {
+			defer n.deliveries.Done()
+			n.deliver(subscription, change, payload)
+		}(subscription)
+	}
+}
+
+func (n *notifier) deliver(subscription hvs.NotificationSubscription, change *hvs.HostTrustChange, payload []byte) {
+	defaultLog.Trace("notification/notifier:deliver() Entering")
+	defer defaultLog.Trace("notification/notifier:deliver() Leaving")
+
+	delivery, err := n.subscriptionStore.CreateDelivery(&hvs.NotificationDelivery{
+		SubscriptionId: subscription.Id,
+		HostId:         change.HostId,
+		Event:          change.Event,
+	})
+	if err != nil {
+		defaultLog.WithError(err).Errorf("notification/notifier:deliver() Failed to log delivery to subscription %s", subscription.Id)
+		return
+	}
+
+	backoff := n.retryBackoff
+	for {
+		delivery.Attempts++
+		delivery.StatusCode, err = n.post(subscription, delivery.Id, change.Event, payload)
+		delivery.Delivered = err == nil
+		delivery.Error = ""
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		if err := n.subscriptionStore.UpdateDelivery(delivery); err != nil {
+			defaultLog.WithError(err).Errorf("notification/notifier:deliver() Failed to update delivery %s", delivery.Id)
+		}
+		if delivery.Delivered {
+			return
+		}
+		if delivery.Attempts >= n.maxAttempts {
+			defaultLog.Warnf("notification/notifier:deliver() Giving up delivery %s to subscription %s after %d attempts: %s",
+				delivery.Id, subscription.Id, delivery.Attempts, delivery.Error)
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-n.stopChan:
+			defaultLog.Warnf("notification/notifier:deliver() Service stopped before delivery %s to subscription %s succeeded",
+				delivery.Id, subscription.Id)
+			return
+		}
+	}
+}
+
+func (n *notifier) post(subscription hvs.NotificationSubscription, deliveryId uuid.UUID, event string, payload []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, errors.Wrap(err, "Failed to create request")
+	}
+	req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+	req.Header.Set(constants.NotificationEventHeader, event)
+	req.Header.Set(constants.NotificationDeliveryHeader, deliveryId.String())
+	req.Header.Set(constants.NotificationSignatureHeader, Sign(subscription.Secret, payload))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "Failed to post notification")
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+		derr := resp.Body.Close()
+		if derr != nil {
+			defaultLog.WithError(derr).Error("Error closing response body")
+		}
+	}()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, errors.Errorf("Unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature of a payload sent with the X-Hvs-Signature header: sha256= followed by the hex encoded
+// HMAC-SHA256 of the payload keyed with the secret of the subscription.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// matches tells whether a trust change passes each filter of the subscription, an empty filter matches every change
+func matches(subscription hvs.NotificationSubscription, change *hvs.HostTrustChange) bool {
+	if len(subscription.HostIds) > 0 && !containsAny(subscription.HostIds, []uuid.UUID{change.HostId}) {
+		return false
+	}
+	if len(subscription.FlavorgroupIds) > 0 && !containsAny(subscription.FlavorgroupIds, change.FlavorgroupIds) {
+		return false
+	}
+	if len(subscription.FlavorParts) > 0 {
+		for _, flavorPart := range subscription.FlavorParts {
+			for _, changed := range change.FlavorParts {
+				if flavorPart == changed.FlavorPart {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func containsAny(ids, values []uuid.UUID) bool {
+	for _, id := range ids {
+		for _, value := range values {
+			if id == value {
+				return true
+			}
+		}
+	}
+	return false
+}
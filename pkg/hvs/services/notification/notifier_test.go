@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package notification
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/hvs/domain/mocks"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	hostId        = uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2")
+	flavorgroupId = uuid.MustParse("e57e5ea0-d465-461e-882d-1600090caa0d")
+)
+
+func TestMatches(t *testing.T) {
+	a := assert.New(t)
+
+	change := &hvs.HostTrustChange{
+		HostId:         hostId,
+		FlavorgroupIds: []uuid.UUID{flavorgroupId},
+		FlavorParts:    []hvs.FlavorPartTrustChange{{FlavorPart: "OS", Trusted: false, PreviousTrust: true}},
+	}
+	a.True(matches(hvs.NotificationSubscription{}, change))
+	a.True(matches(hvs.NotificationSubscription{HostIds: []uuid.UUID{uuid.New(), hostId}}, change))
+	a.False(matches(hvs.NotificationSubscription{HostIds: []uuid.UUID{uuid.New()}}, change))
+	a.True(matches(hvs.NotificationSubscription{FlavorgroupIds: []uuid.UUID{flavorgroupId}, FlavorParts: []string{"PLATFORM", "OS"}}, change))
+	a.False(matches(hvs.NotificationSubscription{FlavorgroupIds: []uuid.UUID{uuid.New()}}, change))
+	a.False(matches(hvs.NotificationSubscription{HostIds: []uuid.UUID{hostId}, FlavorParts: []string{"PLATFORM"}}, change))
+}
+
+func TestNotifierRetriesSignedDeliveries(t *testing.T) {
+	a := assert.New(t)
+
+	var requests int32
+	var signature, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		signature, body = r.Header.Get(constants.NotificationSignatureHeader), string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	subscriptionStore := &mocks.MockNotificationSubscriptionStore{
+		Subscriptions: []hvs.NotificationSubscription{
+			{Id: uuid.New(), URL: server.URL, Secret: "secret", FlavorgroupIds: []uuid.UUID{flavorgroupId}},
+			{Id: uuid.New(), URL: server.URL, Secret: "secret", FlavorParts: []string{"PLATFORM"}},
+		},
+	}
+	n, err := NewNotifier(domain.NotifierConfig{
+		SubscriptionStore: subscriptionStore,
+		HostStore:         mocks.NewMockHostStore(),
+		Client:            server.Client(),
+		RetryBackoff:      10 * time.Millisecond,
+	})
+	a.NoError(err)
+	defer n.Stop()
+
+	n.Notify(&hvs.HostTrustChange{
+		Event:         hvs.HostTrustChangedEvent,
+		HostId:        hostId,
+		Trusted:       false,
+		PreviousTrust: true,
+		FlavorParts:   []hvs.FlavorPartTrustChange{{FlavorPart: "OS", Trusted: false, PreviousTrust: true}},
+	})
+
+	a.Eventually(func() bool {
+		deliveries := subscriptionStore.GetDeliveries()
+		return len(deliveries) == 1 && deliveries[0].Delivered
+	}, 5*time.Second, 10*time.Millisecond)
+
+	delivery := subscriptionStore.GetDeliveries()[0]
+	a.Equal(subscriptionStore.Subscriptions[0].Id, delivery.SubscriptionId)
+	a.Equal(2, delivery.Attempts)
+	a.Equal(http.StatusNoContent, delivery.StatusCode)
+	a.Empty(delivery.Error)
+	a.Contains(body, `"host_name":"localhost1"`)
+	a.Equal(Sign("secret", []byte(body)), signature)
+}
+
+func TestNotifierGivesUp(t *testing.T) {
+	a := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	subscriptionStore := &mocks.MockNotificationSubscriptionStore{
+		Subscriptions: []hvs.NotificationSubscription{{Id: uuid.New(), URL: server.URL, Secret: "secret"}},
+	}
+	n, err := NewNotifier(domain.NotifierConfig{
+		SubscriptionStore: subscriptionStore,
+		HostStore:         mocks.NewMockHostStore(),
+		Client:            server.Client(),
+		MaxAttempts:       3,
+		RetryBackoff:      time.Millisecond,
+	})
+	a.NoError(err)
+	defer n.Stop()
+
+	n.Notify(&hvs.HostTrustChange{Event: hvs.HostTrustChangedEvent, HostId: hostId, Trusted: true})
+
+	a.Eventually(func() bool {
+		deliveries := subscriptionStore.GetDeliveries()
+		return len(deliveries) == 1 && deliveries[0].Attempts == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	delivery := subscriptionStore.GetDeliveries()[0]
+	a.False(delivery.Delivered)
+	a.Equal(http.StatusInternalServerError, delivery.StatusCode)
+	a.Contains(delivery.Error, "500")
+}
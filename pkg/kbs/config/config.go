@@ -7,6 +7,7 @@ package config
 import (
 	log "github.com/sirupsen/logrus"
 	"os"
+	"time"
 
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
 	commConfig "github.com/intel-secl/intel-secl/v3/pkg/lib/common/config"
@@ -24,13 +25,20 @@ type Configuration struct {
 
 	EndpointURL string `yaml:"endpoint-url" mapstructure:"endpoint-url"`
 	KeyManager  string `yaml:"key-manager" mapstructure:"key-manager"`
+	// Store keeps the keys, key transfer policies and certificates: directory or postgres
+	Store string `yaml:"store" mapstructure:"store"`
 
 	TLS    commConfig.TLSCertConfig `yaml:"tls" mapstructure:"tls"`
 	Log    commConfig.LogConfig     `yaml:"log" mapstructure:"log"`
 	Server commConfig.ServerConfig  `yaml:"server" mapstructure:"server"`
+	DB     commConfig.DBConfig      `yaml:"db" mapstructure:"db"`
 
 	Kmip KmipConfig `yaml:"kmip" mapstructure:"kmip"`
 	Skc  SKCConfig  `yaml:"skc" mapstructure:"skc"`
+	Kek  KekConfig  `yaml:"kek" mapstructure:"kek"`
+
+	KeyRotation        KeyRotationConfig        `yaml:"key-rotation" mapstructure:"key-rotation"`
+	KeyTransferRecords KeyTransferRecordsConfig `yaml:"key-transfer-records" mapstructure:"key-transfer-records"`
 }
 
 type KBSConfig struct {
@@ -46,6 +54,40 @@ type KmipConfig struct {
 	RootCert   string `yaml:"root-cert-path" mapstructure:"root-cert-path"`
 }
 
+// KekConfig configures the key encryption key wrapping the data encryption keys with which the secrets of the stored
+// keys are encrypted
+type KekConfig struct {
+	// Provider holds the KEKs: file, pkcs11 or kmip, the kmip provider uses the kmip configuration
+	Provider string `yaml:"provider" mapstructure:"provider"`
+	// ID of the current KEK, a KEK is created on first start when it is empty
+	ID     string       `yaml:"id" mapstructure:"id"`
+	Pkcs11 Pkcs11Config `yaml:"pkcs11" mapstructure:"pkcs11"`
+}
+
+type Pkcs11Config struct {
+	// Module is the path of the PKCS#11 library, such as libsofthsm2.so
+	Module     string `yaml:"module" mapstructure:"module"`
+	TokenLabel string `yaml:"token-label" mapstructure:"token-label"`
+	// PinFile holds the user PIN of the token, the PIN is taken from KEK_PKCS11_PIN instead when it is set
+	PinFile string `yaml:"pin-file" mapstructure:"pin-file"`
+	// Pin is only taken from the environment during setup and written to PinFile, it is not saved in config.yml
+	Pin string `yaml:"-" mapstructure:"-"`
+}
+
+// KeyRotationConfig configures the rotation of the keys
+type KeyRotationConfig struct {
+	// PriorVersionValidity is how long the version replaced by a rotation stays transferable, unless the rotate request
+	// sets it
+	PriorVersionValidity time.Duration `yaml:"prior-version-validity" mapstructure:"prior-version-validity"`
+}
+
+// KeyTransferRecordsConfig configures the records of the key transfers
+type KeyTransferRecordsConfig struct {
+	// MaxCount is the number of records kept by the directory store, the oldest records are deleted beyond it. The
+	// postgres store keeps all the records.
+	MaxCount int `yaml:"max-count" mapstructure:"max-count"`
+}
+
 type SKCConfig struct {
 	StmLabel string `yaml:"challenge-type" mapstructure:"challenge-type"`
 	SQVSUrl  string `yaml:"sqvs-url" mapstructure:"sqvs-url"`
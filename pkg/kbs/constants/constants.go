@@ -22,6 +22,8 @@ const (
 
 	KeysDir               = HomeDir + "keys/"
 	KeysTransferPolicyDir = HomeDir + "keys-transfer-policy/"
+	KeyTransferRecordsDir = HomeDir + "key-transfers/"
+	KekDir                = ConfigDir + "kek/"
 
 	// certificates' path
 	TrustedJWTSigningCertsDir = ConfigDir + "certs/trustedjwt/"
@@ -50,7 +52,13 @@ const (
 	DefaultKeyLength    = 3072
 
 	// jwt constants
-	JWTCertsCacheTime = "1m"
+	JWTCertsCacheTime       = "1m"
+	RevocationListCacheTime = "1m"
+	// every token is rejected once the revocation list could not be retrieved for this long
+	RevocationListMaxStaleness = "10m"
+	RevocationListTimeout      = "10s"
+	// the JWK set of AAS is retrieved with this timeout, the token validation waits for it
+	JwksTimeout = "10s"
 
 	// log constants
 	DefaultLogLevel     = "info"
@@ -68,6 +76,58 @@ const (
 	DirectoryKeyManager = "directory"
 	KmipKeyManager      = "kmip"
 
+	// key encryption key constants
+	FileKekProvider    = "file"
+	Pkcs11KekProvider  = "pkcs11"
+	KmipKekProvider    = "kmip"
+	DefaultKekProvider = FileKekProvider
+	KekLength          = 256
+	DekLength          = 256
+
+	// the PIN of the pkcs11 provider token is read from KekPkcs11PinEnv, or from the PIN file when it is not set
+	KekPkcs11PinEnv         = "KEK_PKCS11_PIN"
+	DefaultKekPkcs11PinFile = ConfigDir + "kek-pkcs11-pin"
+
+	// store constants
+	DirectoryStore = "directory"
+	PostgresStore  = "postgres"
+	DefaultStore   = DirectoryStore
+
+	// certificate types of the postgres store
+	SamlCertType        = "saml"
+	TpmIdentityCertType = "tpm-identity"
+
+	// database constants
+	DefaultDbConnRetryAttempts = 4
+	DefaultDbConnRetryTime     = 1
+	DBTypePostgres             = "postgres"
+
+	// the number of records returned by the searches, a lower limit can be requested
+	DefaultSearchResultRowLimit = 1000
+	MaxSearchResultRowLimit     = 10000
+
+	// Postgres connection SslModes
+	SslModeAllow      = "allow"
+	SslModePrefer     = "prefer"
+	SslModeVerifyCa   = "verify-ca"
+	SslModeRequire    = "require"
+	SslModeVerifyFull = "verify-full"
+
+	// key transfer record constants
+	KeyTransferAllowed        = "allowed"
+	KeyTransferDenied         = "denied"
+	KeyTransferChallenged     = "challenged"
+	AttestationTypeNone       = "NONE"
+	AttestationTypeSaml       = "SAML"
+	SamlHardwareUUIDAttribute = "HardwareUUID"
+
+	// the number of key transfer records kept by the directory store
+	DefaultKeyTransferRecordsMaxCount = 100000
+
+	// key rotation constants
+	DefaultPriorKeyVersionValidity = 24 * time.Hour
+	KeyVersionHeader               = "Key-Version"
+
 	// algorithm constants
 	CRYPTOALG_AES = "AES"
 	CRYPTOALG_RSA = "RSA"
@@ -14,6 +14,8 @@ const (
 	KeySearch   = "keys:search"
 	KeyRegister = "keys:register"
 	KeyTransfer = "keys:transfer"
+	KeyRotate   = "keys:rotate"
+	KeyUpdate   = "keys:update"
 
 	SamlCertCreate   = "saml_certificates:create"
 	SamlCertRetrieve = "saml_certificates:retrieve"
@@ -30,5 +32,7 @@ const (
 	KeyTransferPolicyDelete   = "key_transfer_policies:delete"
 	KeyTransferPolicySearch   = "key_transfer_policies:search"
 
+	KeyTransferRecordSearch = "key_transfers:search"
+
 	SessionCreate = "key-session-api:create"
 )
@@ -18,6 +18,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -55,10 +56,19 @@ func NewKeyController(rm *keymanager.RemoteManager, ps domain.KeyTransferPolicyS
 }
 
 var keySearchParams = map[string]bool{"algorithm": true, "keyLength": true, "curveType": true, "transferPolicyId": true}
+var keyTransferParams = map[string]bool{"version": true}
 var allowedAlgorithms = map[string]bool{"AES": true, "RSA": true, "EC": true, "aes": true, "rsa": true, "ec": true}
 var allowedCurveTypes = map[string]bool{"secp256r1": true, "secp384r1": true, "secp521r1": true, "prime256v1": true}
 var allowedKeyLengths = map[int]bool{128: true, 192: true, 256: true, 2048: true, 3072: true, 4096: true, 7680: true, 15360: true}
 
+// keyUsagePolicyErrors are the reasons the usage policy of a key refuses a transfer
+var keyUsagePolicyErrors = map[error]bool{
+	keymanager.ErrKeyDisabled:             true,
+	keymanager.ErrKeyNotYetValid:          true,
+	keymanager.ErrKeyExpired:              true,
+	keymanager.ErrKeyTransferLimitReached: true,
+}
+
 //Create : Function to create key
 func (kc KeyController) Create(responseWriter http.ResponseWriter, request *http.Request) (interface{}, int, error) {
 	defaultLog.Trace("controllers/key_controller:Create() Entering")
@@ -113,9 +123,10 @@ func (kc KeyController) Create(responseWriter http.ResponseWriter, request *http
 	}
 
 	var createdKey *kbs.KeyResponse
+	attributes := keyAttributes(requestKey.Label, requestKey.KeyInformation.Algorithm)
 	if requestKey.KeyInformation.KeyString == "" && requestKey.KeyInformation.KmipKeyID == "" {
 
-		if !checkValidKeyPermission(privileges, []string{consts.KeyCreate}) {
+		if !checkValidKeyPermission(privileges, []string{consts.KeyCreate}, attributes) {
 			secLog.Errorf("controllers/key_controller:Create() %s", commLogMsg.UnauthorizedAccess)
 			return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Insufficient privileges to access /v1/keys"}
 		}
@@ -130,7 +141,7 @@ func (kc KeyController) Create(responseWriter http.ResponseWriter, request *http
 		secLog.WithField("Id", createdKey.KeyInformation.ID).Infof("controllers/key_controller:Create() %s: Key created by: %s", commLogMsg.PrivilegeModified, request.RemoteAddr)
 	} else {
 
-		if !checkValidKeyPermission(privileges, []string{consts.KeyRegister}) {
+		if !checkValidKeyPermission(privileges, []string{consts.KeyRegister}, attributes) {
 			secLog.Errorf("controllers/key_controller:Create() %s", commLogMsg.UnauthorizedAccess)
 			return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Insufficient privileges to access /v1/keys"}
 		}
@@ -154,15 +165,9 @@ func (kc KeyController) Retrieve(responseWriter http.ResponseWriter, request *ht
 	defer defaultLog.Trace("controllers/key_controller:Retrieve() Leaving")
 
 	id := uuid.MustParse(mux.Vars(request)["id"])
-	key, err := kc.remoteManager.RetrieveKey(id)
+	key, status, err := kc.authorizeKeyAccess(request, id, consts.KeyRetrieve)
 	if err != nil {
-		if err.Error() == commErr.RecordNotFound {
-			defaultLog.Error("controllers/key_controller:Retrieve() Key with specified id could not be located")
-			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Key with specified id does not exist"}
-		} else {
-			defaultLog.WithError(err).Error("controllers/key_controller:Retrieve() Key retrieve failed")
-			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve key"}
-		}
+		return nil, status, err
 	}
 
 	secLog.WithField("Id", id).Infof("controllers/key_controller:Retrieve() Key Retrieved by: %s", request.RemoteAddr)
@@ -175,6 +180,10 @@ func (kc KeyController) Delete(responseWriter http.ResponseWriter, request *http
 	defer defaultLog.Trace("controllers/key_controller:Delete() Leaving")
 
 	id := uuid.MustParse(mux.Vars(request)["id"])
+	if _, status, err := kc.authorizeKeyAccess(request, id, consts.KeyDelete); err != nil {
+		return nil, status, err
+	}
+
 	err := kc.remoteManager.DeleteKey(id)
 	if err != nil {
 		if err.Error() == commErr.RecordNotFound {
@@ -190,6 +199,132 @@ func (kc KeyController) Delete(responseWriter http.ResponseWriter, request *http
 	return nil, http.StatusNoContent, nil
 }
 
+//Rotate : Function to rotate key, the key keeps its ID and the prior version stays transferable for a while
+func (kc KeyController) Rotate(responseWriter http.ResponseWriter, request *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/key_controller:Rotate() Entering")
+	defer defaultLog.Trace("controllers/key_controller:Rotate() Leaving")
+
+	id := uuid.MustParse(mux.Vars(request)["id"])
+	if _, status, err := kc.authorizeKeyAccess(request, id, consts.KeyRotate); err != nil {
+		return nil, status, err
+	}
+
+	// the request body is optional, the configured validity applies to the prior version without it
+	priorVersionValidity := kc.config.PriorKeyVersionValidity
+	if request.ContentLength != 0 {
+		if request.Header.Get("Content-Type") != constants.HTTPMediaTypeJson {
+			return nil, http.StatusUnsupportedMediaType, &commErr.ResourceError{Message: "Invalid Content-Type"}
+		}
+
+		var rotateRequest kbs.KeyRotateRequest
+		dec := json.NewDecoder(request.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&rotateRequest); err != nil {
+			secLog.WithError(err).Errorf("controllers/key_controller:Rotate() %s : Failed to decode request body as KeyRotateRequest", commLogMsg.InvalidInputBadEncoding)
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Unable to decode JSON request body"}
+		}
+
+		if rotateRequest.PriorVersionValidityMinutes != nil {
+			if *rotateRequest.PriorVersionValidityMinutes < 0 {
+				secLog.Errorf("controllers/key_controller:Rotate() %s : Invalid prior version validity", commLogMsg.InvalidInputBadParam)
+				return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "prior_version_validity_minutes must not be negative"}
+			}
+			priorVersionValidity = time.Duration(*rotateRequest.PriorVersionValidityMinutes) * time.Minute
+		}
+	}
+
+	rotatedKey, err := kc.remoteManager.RotateKey(id, priorVersionValidity)
+	if err != nil {
+		if err.Error() == commErr.RecordNotFound {
+			defaultLog.Error("controllers/key_controller:Rotate() Key with specified id could not be located")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Key with specified id does not exist"}
+		}
+		if err.Error() == commErr.RecordConflict {
+			defaultLog.Error("controllers/key_controller:Rotate() Key with specified id was updated concurrently")
+			return nil, http.StatusConflict, &commErr.ResourceError{Message: "Key was updated concurrently, retry the request"}
+		}
+		defaultLog.WithError(err).Error("controllers/key_controller:Rotate() Key rotate failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to rotate key"}
+	}
+
+	secLog.WithField("Id", id).Infof("controllers/key_controller:Rotate() %s: Key rotated to version %d by: %s", commLogMsg.PrivilegeModified, rotatedKey.Version, request.RemoteAddr)
+	return rotatedKey, http.StatusOK, nil
+}
+
+//RetrieveVersions : Function to list the versions of a key
+func (kc KeyController) RetrieveVersions(responseWriter http.ResponseWriter, request *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/key_controller:RetrieveVersions() Entering")
+	defer defaultLog.Trace("controllers/key_controller:RetrieveVersions() Leaving")
+
+	id := uuid.MustParse(mux.Vars(request)["id"])
+	if _, status, err := kc.authorizeKeyAccess(request, id, consts.KeyRetrieve); err != nil {
+		return nil, status, err
+	}
+
+	versions, err := kc.remoteManager.ListKeyVersions(id)
+	if err != nil {
+		if err.Error() == commErr.RecordNotFound {
+			defaultLog.Error("controllers/key_controller:RetrieveVersions() Key with specified id could not be located")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Key with specified id does not exist"}
+		}
+		defaultLog.WithError(err).Error("controllers/key_controller:RetrieveVersions() Key versions retrieve failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve key versions"}
+	}
+
+	secLog.WithField("Id", id).Infof("controllers/key_controller:RetrieveVersions() Key versions retrieved by: %s", request.RemoteAddr)
+	return versions, http.StatusOK, nil
+}
+
+//UpdateUsagePolicy : Function to replace the usage policy of a key
+func (kc KeyController) UpdateUsagePolicy(responseWriter http.ResponseWriter, request *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/key_controller:UpdateUsagePolicy() Entering")
+	defer defaultLog.Trace("controllers/key_controller:UpdateUsagePolicy() Leaving")
+
+	if request.Header.Get("Content-Type") != constants.HTTPMediaTypeJson {
+		return nil, http.StatusUnsupportedMediaType, &commErr.ResourceError{Message: "Invalid Content-Type"}
+	}
+
+	if request.ContentLength == 0 {
+		secLog.Error("controllers/key_controller:UpdateUsagePolicy() The request body was not provided")
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body was not provided"}
+	}
+
+	var usagePolicy kbs.KeyUsagePolicy
+	dec := json.NewDecoder(request.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&usagePolicy); err != nil {
+		secLog.WithError(err).Errorf("controllers/key_controller:UpdateUsagePolicy() %s : Failed to decode request body as KeyUsagePolicy", commLogMsg.InvalidInputBadEncoding)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Unable to decode JSON request body"}
+	}
+
+	if err := validateKeyUsagePolicy(usagePolicy); err != nil {
+		secLog.WithError(err).Errorf("controllers/key_controller:UpdateUsagePolicy() %s : Invalid usage policy", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	id := uuid.MustParse(mux.Vars(request)["id"])
+	if _, status, err := kc.authorizeKeyAccess(request, id, consts.KeyUpdate); err != nil {
+		return nil, status, err
+	}
+
+	updatedKey, err := kc.remoteManager.UpdateKeyUsagePolicy(id, usagePolicy)
+	if err != nil {
+		if err.Error() == commErr.RecordNotFound {
+			defaultLog.Error("controllers/key_controller:UpdateUsagePolicy() Key with specified id could not be located")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Key with specified id does not exist"}
+		}
+		if err.Error() == commErr.RecordConflict {
+			defaultLog.Error("controllers/key_controller:UpdateUsagePolicy() Key with specified id was updated concurrently")
+			return nil, http.StatusConflict, &commErr.ResourceError{Message: "Key was updated concurrently, retry the request"}
+		}
+		defaultLog.WithError(err).Error("controllers/key_controller:UpdateUsagePolicy() Key usage policy update failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to update key usage policy"}
+	}
+
+	secLog.WithField("Id", id).Infof("controllers/key_controller:UpdateUsagePolicy() %s: Key usage policy updated by: %s", commLogMsg.PrivilegeModified, request.RemoteAddr)
+	return updatedKey, http.StatusOK, nil
+}
+
 //Search : Function to search keys
 func (kc KeyController) Search(responseWriter http.ResponseWriter, request *http.Request) (interface{}, int, error) {
 	defaultLog.Trace("controllers/key_controller:Search() Entering")
@@ -208,12 +343,31 @@ func (kc KeyController) Search(responseWriter http.ResponseWriter, request *http
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Invalid filter criteria"}
 	}
 
+	privileges, err := comctx.GetUserPermissions(request)
+	if err != nil {
+		secLog.Errorf("controllers/key_controller:Search() %s", commLogMsg.AuthenticationFailed)
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Could not get user permissions from http context"}
+	}
+
 	keys, err := kc.remoteManager.SearchKeys(criteria)
 	if err != nil {
 		defaultLog.WithError(err).Error("controllers/key_controller:Search() Key search failed")
 		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to search keys"}
 	}
 
+	// only the keys the constraints of the permissions allow are returned
+	policy, _ := auth.GetPolicy(privileges, ct.PermissionInfo{Service: consts.ServiceName, Rules: []string{consts.KeySearch}},
+		keyAttributeNames)
+	if !policy.Unrestricted() {
+		allowedKeys := []*kbs.KeyResponse{}
+		for _, key := range keys {
+			if policy.Allows(keyResponseAttributes(key)) {
+				allowedKeys = append(allowedKeys, key)
+			}
+		}
+		keys = allowedKeys
+	}
+
 	secLog.Infof("controllers/key_controller:Search() %s: Keys searched by: %s", commLogMsg.AuthorizedAccess, request.RemoteAddr)
 	return keys, http.StatusOK, nil
 }
@@ -223,12 +377,23 @@ func (kc KeyController) Transfer(responseWriter http.ResponseWriter, request *ht
 	defaultLog.Trace("controllers/key_controller:Transfer() Entering")
 	defer defaultLog.Trace("controllers/key_controller:Transfer() Leaving")
 
+	// the transfer requests refused for an invalid request are recorded as well
+	id := uuid.MustParse(mux.Vars(request)["id"])
+	audit := keytransfer.NewTransferAudit(kc.config.KeyTransferRecordStore, id, consts.AttestationTypeNone)
+	requester, err := comctx.GetTokenSubject(request)
+	if err != nil {
+		defaultLog.WithError(err).Warn("controllers/key_controller:Transfer() Could not get token subject from http context")
+	}
+	audit.SetRequester(requester)
+
 	if request.Header.Get("Content-Type") != constants.HTTPMediaTypePlain {
+		audit.Deny("Invalid Content-Type")
 		return nil, http.StatusUnsupportedMediaType, &commErr.ResourceError{Message: "Invalid Content-Type"}
 	}
 
 	if request.ContentLength == 0 {
 		secLog.Error("controllers/key_controller:Transfer() The request body was not provided")
+		audit.Deny("The request body was not provided")
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body was not provided"}
 	}
 
@@ -236,6 +401,7 @@ func (kc KeyController) Transfer(responseWriter http.ResponseWriter, request *ht
 	bytes, err := ioutil.ReadAll(request.Body)
 	if err != nil {
 		secLog.WithError(err).Errorf("controllers/key_controller:Transfer() %s : Unable to read request body", commLogMsg.InvalidInputBadEncoding)
+		audit.Deny("Unable to read request body")
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Unable to read request body"}
 	}
 
@@ -243,23 +409,42 @@ func (kc KeyController) Transfer(responseWriter http.ResponseWriter, request *ht
 	key, err := crypt.GetPublicKeyFromPem(bytes)
 	if err != nil {
 		secLog.WithError(err).Errorf("controllers/key_controller:Transfer() %s : Public key decode failed", commLogMsg.InvalidInputBadParam)
+		audit.Deny("Failed to decode public key")
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Failed to decode public key"}
 	}
 	envelopeKey := key.(*rsa.PublicKey)
 
+	version, err := getKeyVersion(request.URL.Query())
+	if err != nil {
+		secLog.WithError(err).Errorf("controllers/key_controller:Transfer() %s : Invalid key version", commLogMsg.InvalidInputBadParam)
+		audit.Deny(err.Error())
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
 	// Wrap key with public key
-	id := uuid.MustParse(mux.Vars(request)["id"])
-	wrappedKey, status, err := kc.wrapSecretKey(id, envelopeKey, sha512.New384(), nil)
+	transferredKey, status, err := kc.authorizeKeyAccess(request, id, consts.KeyTransfer)
+	if err != nil {
+		audit.Deny(err.Error())
+		return nil, status, err
+	}
+	audit.SetTransferPolicy(transferredKey.TransferPolicyID)
+	wrappedKey, version, status, err := kc.wrapSecretKey(id, version, envelopeKey, sha512.New384(), nil)
 	if err != nil {
+		audit.Deny(err.Error())
 		return nil, status, err
 	}
+	if err := audit.Allow(version); err != nil {
+		defaultLog.WithError(err).Error("controllers/key_controller:Transfer() Failed to record the key transfer")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to record the key transfer"}
+	}
 
 	transferKeyResponse := kbs.KeyTransferAttributes{
 		KeyId:   id,
-		KeyData: base64.StdEncoding.EncodeToString(wrappedKey.([]byte)),
+		KeyData: base64.StdEncoding.EncodeToString(wrappedKey),
+		Version: version,
 	}
 
-	secLog.WithField("Id", id).Infof("controllers/key_controller:Transfer() %s: Key transferred using Envelope key by: %s", commLogMsg.PrivilegeModified, request.RemoteAddr)
+	secLog.WithField("Id", id).Infof("controllers/key_controller:Transfer() %s: Key version %d transferred using Envelope key by: %s", commLogMsg.PrivilegeModified, version, request.RemoteAddr)
 	return transferKeyResponse, http.StatusOK, nil
 }
 
@@ -268,12 +453,18 @@ func (kc KeyController) TransferWithSaml(responseWriter http.ResponseWriter, req
 	defaultLog.Trace("controllers/key_controller:TransferWithSaml() Entering")
 	defer defaultLog.Trace("controllers/key_controller:TransferWithSaml() Leaving")
 
+	// the transfer requests refused for an invalid request are recorded as well
+	id := uuid.MustParse(mux.Vars(request)["id"])
+	audit := keytransfer.NewTransferAudit(kc.config.KeyTransferRecordStore, id, consts.AttestationTypeSaml)
+
 	if request.Header.Get("Content-Type") != constants.HTTPMediaTypeSaml {
+		audit.Deny("Invalid Content-Type")
 		return nil, http.StatusUnsupportedMediaType, &commErr.ResourceError{Message: "Invalid Content-Type"}
 	}
 
 	if request.ContentLength == 0 {
 		secLog.Error("controllers/key_controller:Create() The request body was not provided")
+		audit.Deny("The request body was not provided")
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "The request body was not provided"}
 	}
 
@@ -281,6 +472,7 @@ func (kc KeyController) TransferWithSaml(responseWriter http.ResponseWriter, req
 	bytes, err := ioutil.ReadAll(request.Body)
 	if err != nil {
 		secLog.WithError(err).Errorf("controllers/key_controller:TransferWithSaml() %s : Unable to read request body", commLogMsg.InvalidInputBadEncoding)
+		audit.Deny("Unable to read request body")
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Unable to read request body"}
 	}
 
@@ -289,12 +481,19 @@ func (kc KeyController) TransferWithSaml(responseWriter http.ResponseWriter, req
 	err = xml.Unmarshal(bytes, &samlReport)
 	if err != nil {
 		secLog.WithError(err).Errorf("controllers/key_controller:TransferWithSaml() %s : Saml report unmarshal failed", commLogMsg.InvalidInputBadParam)
+		audit.Deny("Failed to unmarshal saml report")
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Failed to unmarshal saml report"}
 	}
 
+	version, err := getKeyVersion(request.URL.Query())
+	if err != nil {
+		secLog.WithError(err).Errorf("controllers/key_controller:TransferWithSaml() %s : Invalid key version", commLogMsg.InvalidInputBadParam)
+		audit.Deny(err.Error())
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
 	// Validate saml report in request
-	id := uuid.MustParse(mux.Vars(request)["id"])
-	trusted, bindingCert := keytransfer.IsTrustedByHvs(string(bytes), samlReport, id, kc.config, kc.remoteManager)
+	trusted, bindingCert := keytransfer.IsTrustedByHvs(string(bytes), samlReport, id, kc.config, kc.remoteManager, audit)
 	if !trusted {
 		secLog.Error("controllers/key_controller:TransferWithSaml() Saml report is not trusted")
 		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Client not trusted by Hvs"}
@@ -302,38 +501,75 @@ func (kc KeyController) TransferWithSaml(responseWriter http.ResponseWriter, req
 	envelopeKey := bindingCert.PublicKey.(*rsa.PublicKey)
 
 	// Wrap key with binding key
-	wrappedKey, status, err := kc.wrapSecretKey(id, envelopeKey, sha256.New(), []byte("TPM2\000"))
+	wrappedKey, version, status, err := kc.wrapSecretKey(id, version, envelopeKey, sha256.New(), []byte("TPM2\000"))
 	if err != nil {
+		audit.Deny(err.Error())
 		return nil, status, err
 	}
+	if err := audit.Allow(version); err != nil {
+		defaultLog.WithError(err).Error("controllers/key_controller:TransferWithSaml() Failed to record the key transfer")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to record the key transfer"}
+	}
 
-	secLog.WithField("Id", id).Infof("controllers/key_controller:TransferWithSaml() %s: Key transferred using saml report by: %s", commLogMsg.PrivilegeModified, request.RemoteAddr)
+	// the response is the wrapped key only, the version is returned in a header
+	responseWriter.Header().Set(consts.KeyVersionHeader, strconv.Itoa(version))
+	secLog.WithField("Id", id).Infof("controllers/key_controller:TransferWithSaml() %s: Key version %d transferred using saml report by: %s", commLogMsg.PrivilegeModified, version, request.RemoteAddr)
 	return wrappedKey, http.StatusOK, nil
 }
 
-func (kc KeyController) wrapSecretKey(id uuid.UUID, publicKey *rsa.PublicKey, hash hash.Hash, label []byte) (interface{}, int, error) {
+// wrapSecretKey returns the given version of the key wrapped with the public key along with the version number, the
+// primary version is wrapped when the version is 0
+func (kc KeyController) wrapSecretKey(id uuid.UUID, version int, publicKey *rsa.PublicKey, hash hash.Hash, label []byte) ([]byte, int, int, error) {
 	defaultLog.Trace("controllers/key_controller:wrapSecretKey() Entering")
 	defer defaultLog.Trace("controllers/key_controller:wrapSecretKey() Leaving")
 
-	secretKey, err := kc.remoteManager.TransferKey(id)
+	// the secret key is wrapped with the public key before the transfer is counted
+	wrappedKey, version, err := kc.remoteManager.TransferKey(id, version, func(secretKey []byte) ([]byte, error) {
+		return rsa.EncryptOAEP(hash, rand.Reader, publicKey, secretKey, label)
+	})
 	if err != nil {
-		if err.Error() == commErr.RecordNotFound {
+		if wrapErr, ok := err.(*keymanager.KeyWrapError); ok {
+			defaultLog.WithError(wrapErr.Err).Error("controllers/key_controller:wrapSecretKey() Wrap key failed")
+			return nil, 0, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to wrap key"}
+		} else if err.Error() == commErr.RecordNotFound {
 			defaultLog.Error("controllers/key_controller:wrapSecretKey() Key with specified id could not be located")
-			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Key with specified id does not exist"}
+			return nil, 0, http.StatusNotFound, &commErr.ResourceError{Message: "Key with specified id does not exist"}
+		} else if err == keymanager.ErrKeyVersionNotFound {
+			defaultLog.Error("controllers/key_controller:wrapSecretKey() Key version could not be located")
+			return nil, 0, http.StatusNotFound, &commErr.ResourceError{Message: "Key version does not exist"}
+		} else if err == keymanager.ErrKeyVersionNotTransferable {
+			defaultLog.Error("controllers/key_controller:wrapSecretKey() Key version is no longer transferable")
+			return nil, 0, http.StatusBadRequest, &commErr.ResourceError{Message: "Key version is no longer transferable"}
+		} else if keyUsagePolicyErrors[err] {
+			secLog.WithError(err).Errorf("controllers/key_controller:wrapSecretKey() %s : Key usage policy does not allow the transfer", commLogMsg.UnauthorizedAccess)
+			return nil, 0, http.StatusForbidden, &commErr.ResourceError{Message: "Key usage policy does not allow the transfer: " + err.Error()}
 		} else {
 			defaultLog.WithError(err).Error("controllers/key_controller:wrapSecretKey() Key transfer failed")
-			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to transfer Key"}
+			return nil, 0, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to transfer Key"}
 		}
 	}
 
-	// Wrap secret key with public key
-	wrappedKey, err := rsa.EncryptOAEP(hash, rand.Reader, publicKey, secretKey, label)
-	if err != nil {
-		defaultLog.WithError(err).Error("controllers/key_controller:wrapSecretKey() Wrap key failed")
-		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to wrap key"}
+	return wrappedKey, version, http.StatusOK, nil
+}
+
+// getKeyVersion returns the version of the key requested for transfer, 0 when the primary version is requested
+func getKeyVersion(params url.Values) (int, error) {
+	defaultLog.Trace("controllers/key_controller:getKeyVersion() Entering")
+	defer defaultLog.Trace("controllers/key_controller:getKeyVersion() Leaving")
+
+	if err := utils.ValidateQueryParams(params, keyTransferParams); err != nil {
+		return 0, err
 	}
 
-	return wrappedKey, http.StatusOK, nil
+	param := strings.TrimSpace(params.Get("version"))
+	if param == "" {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(param)
+	if err != nil || version < 1 {
+		return 0, errors.New("Valid version must be specified")
+	}
+	return version, nil
 }
 
 //validateKeyCreateRequest checks for various attributes in the Key Create request and returns a boolean value
@@ -385,6 +621,22 @@ func validateKeyCreateRequest(requestKey kbs.KeyRequest) error {
 		}
 	}
 
+	return validateKeyUsagePolicy(requestKey.KeyUsagePolicy)
+}
+
+//validateKeyUsagePolicy checks that the transfer window of the usage policy is not empty and the transfer count is valid
+func validateKeyUsagePolicy(usagePolicy kbs.KeyUsagePolicy) error {
+	defaultLog.Trace("controllers/key_controller:validateKeyUsagePolicy() Entering")
+	defer defaultLog.Trace("controllers/key_controller:validateKeyUsagePolicy() Leaving")
+
+	if usagePolicy.MaxTransferCount < 0 {
+		return errors.New("max_transfer_count must not be negative")
+	}
+
+	if usagePolicy.NotBefore != nil && usagePolicy.NotAfter != nil && !usagePolicy.NotAfter.After(*usagePolicy.NotBefore) {
+		return errors.New("not_after must be later than not_before")
+	}
+
 	return nil
 }
 
@@ -435,13 +687,61 @@ func getKeyFilterCriteria(params url.Values) (*models.KeyFilterCriteria, error)
 	return &criteria, nil
 }
 
-func checkValidKeyPermission(privileges []ct.PermissionInfo, requiredPermission []string) bool {
+// authorizeKeyAccess retrieves a key and verifies that the constraints of the permissions of the user allow the rule
+// on it
+func (kc KeyController) authorizeKeyAccess(request *http.Request, id uuid.UUID, rule string) (*kbs.KeyResponse, int, error) {
+	defaultLog.Trace("controllers/key_controller:authorizeKeyAccess() Entering")
+	defer defaultLog.Trace("controllers/key_controller:authorizeKeyAccess() Leaving")
+
+	privileges, err := comctx.GetUserPermissions(request)
+	if err != nil {
+		secLog.Errorf("controllers/key_controller:authorizeKeyAccess() %s", commLogMsg.AuthenticationFailed)
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Could not get user permissions from http context"}
+	}
+
+	key, err := kc.remoteManager.RetrieveKey(id)
+	if err != nil {
+		if err.Error() == commErr.RecordNotFound {
+			defaultLog.Error("controllers/key_controller:authorizeKeyAccess() Key with specified id could not be located")
+			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Key with specified id does not exist"}
+		}
+		defaultLog.WithError(err).Error("controllers/key_controller:authorizeKeyAccess() Key retrieve failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve key"}
+	}
+
+	if !checkValidKeyPermission(privileges, []string{rule}, keyResponseAttributes(key)) {
+		secLog.Errorf("controllers/key_controller:authorizeKeyAccess() %s", commLogMsg.UnauthorizedAccess)
+		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "Insufficient privileges to access /v1/keys"}
+	}
+	return key, http.StatusOK, nil
+}
+
+// keyAttributeNames are the attributes returned by keyAttributes
+var keyAttributeNames = []string{"label", "algorithm"}
+
+// keyAttributes returns the attributes of a key that the constraints of the permissions are evaluated against
+func keyAttributes(label, algorithm string) auth.Attributes {
+	return auth.Attributes{
+		"label":     {label},
+		"algorithm": {strings.ToUpper(algorithm)},
+	}
+}
+
+func keyResponseAttributes(key *kbs.KeyResponse) auth.Attributes {
+	if key.KeyInformation == nil {
+		return keyAttributes(key.Label, "")
+	}
+	return keyAttributes(key.Label, key.KeyInformation.Algorithm)
+}
+
+// checkValidKeyPermission verifies that the permissions of the user grant one of the rules on a key with the given
+// attributes
+func checkValidKeyPermission(privileges []ct.PermissionInfo, requiredPermission []string, attributes auth.Attributes) bool {
 	defaultLog.Trace("controllers/key_controller:checkValidKeyPermission() Entering")
 	defer defaultLog.Trace("controllers/key_controller:checkValidKeyPermission() Leaving")
 	reqPermissions := ct.PermissionInfo{Service: consts.ServiceName, Rules: requiredPermission}
-	_, foundMatchingPermission := auth.ValidatePermissionAndGetPermissionsContext(privileges, reqPermissions,
-		true)
-	if !foundMatchingPermission {
+	policy, foundMatchingPermission := auth.GetPolicy(privileges, reqPermissions, keyAttributeNames)
+	if !foundMatchingPermission || !policy.Allows(attributes) {
 		secLog.Errorf("controllers/key_controller:checkValidKeyPermission() %s Insufficient privileges to access /v1/keys", commLogMsg.UnauthorizedAccess)
 		return false
 	}
@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -45,6 +46,7 @@ var _ = Describe("KeyController", func() {
 	var w *httptest.ResponseRecorder
 	var keyStore *mocks.MockKeyStore
 	var policyStore *mocks.MockKeyTransferPolicyStore
+	var transferRecordStore *mocks.MockKeyTransferRecordStore
 	var remoteManager *keymanager.RemoteManager
 	var keyController *controllers.KeyController
 	var keyControllerConfig domain.KeyControllerConfig
@@ -60,18 +62,22 @@ var _ = Describe("KeyController", func() {
 	invalidEnvelopeKey := strings.Replace(strings.Replace(string(validEnvelopeKey), "-----BEGIN PUBLIC KEY-----\n", "", 1), "-----END PUBLIC KEY-----", "", 1)
 	validSamlReport, _ := ioutil.ReadFile(validSamlReportPath)
 	invalidSamlReport, _ := ioutil.ReadFile(invalidSamlReportPath)
+	keyPermissions := []aas.PermissionInfo{{Service: constants.ServiceName, Rules: []string{"keys:*:*"}}}
 
 	BeforeEach(func() {
 		router = mux.NewRouter()
 		keyStore = mocks.NewFakeKeyStore()
 		policyStore = mocks.NewFakeKeyTransferPolicyStore()
+		transferRecordStore = mocks.NewFakeKeyTransferRecordStore()
 		newId, err := uuid.NewRandom()
 		Expect(err).NotTo(HaveOccurred())
 		keyControllerConfig = domain.KeyControllerConfig{
-			SamlCertsDir:            samlCertsDir,
+			SamlCertStore:           mocks.NewFakeCertificateStore(),
 			TrustedCaCertsDir:       trustedCaCertsDir,
-			TpmIdentityCertsDir:     tpmIdentityCertsDir,
+			TpmIdentityCertStore:    mocks.NewFakeCertificateStore(),
 			DefaultTransferPolicyId: newId,
+			PriorKeyVersionValidity: time.Hour,
+			KeyTransferRecordStore:  transferRecordStore,
 		}
 
 		keyManager := &keymanager.DirectoryManager{}
@@ -342,6 +348,7 @@ var _ = Describe("KeyController", func() {
 				)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				req.Header.Set("Content-Type", consts.HTTPMediaTypePlain)
+				req = context.SetUserPermissions(req, keyPermissions)
 				Expect(err).NotTo(HaveOccurred())
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -360,10 +367,16 @@ var _ = Describe("KeyController", func() {
 				)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				req.Header.Set("Content-Type", consts.HTTPMediaTypePlain)
+				req = context.SetUserPermissions(req, keyPermissions)
 				Expect(err).NotTo(HaveOccurred())
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
 				Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+				records, _ := transferRecordStore.Search(nil)
+				Expect(len(records)).To(Equal(1))
+				Expect(records[0].Decision).To(Equal(constants.KeyTransferDenied))
+				Expect(records[0].Reason).To(Equal("Failed to decode public key"))
 			})
 		})
 		Context("Provide a public key without DER data", func() {
@@ -379,6 +392,7 @@ var _ = Describe("KeyController", func() {
 				)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				req.Header.Set("Content-Type", consts.HTTPMediaTypePlain)
+				req = context.SetUserPermissions(req, keyPermissions)
 				Expect(err).NotTo(HaveOccurred())
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -397,6 +411,7 @@ var _ = Describe("KeyController", func() {
 				)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				req.Header.Set("Content-Type", consts.HTTPMediaTypePlain)
+				req = context.SetUserPermissions(req, keyPermissions)
 				Expect(err).NotTo(HaveOccurred())
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -458,6 +473,13 @@ var _ = Describe("KeyController", func() {
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
 				Expect(w.Code).To(Equal(http.StatusUnauthorized))
+
+				records, _ := transferRecordStore.Search(nil)
+				Expect(len(records)).To(Equal(1))
+				Expect(records[0].AttestationType).To(Equal(constants.AttestationTypeSaml))
+				Expect(records[0].Decision).To(Equal(constants.KeyTransferDenied))
+				Expect(records[0].Reason).To(Equal("Invalid signature on trust report"))
+				Expect(records[0].Requester).To(BeEmpty())
 			})
 		})
 		Context("Provide an invalid saml report", func() {
@@ -476,6 +498,11 @@ var _ = Describe("KeyController", func() {
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
 				Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+				records, _ := transferRecordStore.Search(nil)
+				Expect(len(records)).To(Equal(1))
+				Expect(records[0].AttestationType).To(Equal(constants.AttestationTypeSaml))
+				Expect(records[0].Reason).To(Equal("Failed to unmarshal saml report"))
 			})
 		})
 	})
@@ -487,6 +514,7 @@ var _ = Describe("KeyController", func() {
 				router.Handle("/keys/{id}", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Retrieve))).Methods("GET")
 				req, err := http.NewRequest("GET", "/keys/ee37c360-7eae-4250-a677-6ee12adce8e2", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -498,6 +526,7 @@ var _ = Describe("KeyController", func() {
 				router.Handle("/keys/{id}", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Retrieve))).Methods("GET")
 				req, err := http.NewRequest("GET", "/keys/73755fda-c910-46be-821f-e8ddeab189e9", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -513,6 +542,7 @@ var _ = Describe("KeyController", func() {
 				router.Handle("/keys/{id}", kbsRoutes.ErrorHandler(kbsRoutes.ResponseHandler(keyController.Delete))).Methods("DELETE")
 				req, err := http.NewRequest("DELETE", "/keys/ee37c360-7eae-4250-a677-6ee12adce8e2", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
 				Expect(w.Code).To(Equal(http.StatusNoContent))
@@ -523,9 +553,304 @@ var _ = Describe("KeyController", func() {
 				router.Handle("/keys/{id}", kbsRoutes.ErrorHandler(kbsRoutes.ResponseHandler(keyController.Delete))).Methods("DELETE")
 				req, err := http.NewRequest("DELETE", "/keys/73755fda-c910-46be-821f-e8ddeab189e9", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	// Specs for HTTP Post to "/keys/{id}/rotate"
+	Describe("Rotate an existing Key", func() {
+		transfer := func(path string) *httptest.ResponseRecorder {
+			router.Handle("/keys/{id}/transfer", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Transfer))).Methods("POST")
+			req, err := http.NewRequest("POST", path, strings.NewReader(string(validEnvelopeKey)))
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+			req.Header.Set("Content-Type", consts.HTTPMediaTypePlain)
+			req = context.SetUserPermissions(req, keyPermissions)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		Context("Rotate Key by ID", func() {
+			It("Should transfer the new version and keep the prior version transferable", func() {
+				router.Handle("/keys/{id}/rotate", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Rotate))).Methods("POST")
+				req, err := http.NewRequest("POST", "/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/rotate", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var rotatedKey kbs.KeyResponse
+				Expect(json.Unmarshal(w.Body.Bytes(), &rotatedKey)).To(Succeed())
+				Expect(rotatedKey.KeyInformation.ID.String()).To(Equal("ee37c360-7eae-4250-a677-6ee12adce8e2"))
+				Expect(rotatedKey.Version).To(Equal(2))
+
+				w = transfer("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer")
+				Expect(w.Code).To(Equal(http.StatusOK))
+				var transferredKey kbs.KeyTransferAttributes
+				Expect(json.Unmarshal(w.Body.Bytes(), &transferredKey)).To(Succeed())
+				Expect(transferredKey.Version).To(Equal(2))
+
+				w = transfer("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer?version=1")
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(json.Unmarshal(w.Body.Bytes(), &transferredKey)).To(Succeed())
+				Expect(transferredKey.Version).To(Equal(1))
+
+				router.Handle("/keys/{id}/versions", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.RetrieveVersions))).Methods("GET")
+				req, err = http.NewRequest("GET", "/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/versions", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var versions []kbs.KeyVersionResponse
+				Expect(json.Unmarshal(w.Body.Bytes(), &versions)).To(Succeed())
+				Expect(versions).To(HaveLen(2))
+				Expect(versions[0].Version).To(Equal(2))
+				Expect(versions[0].Primary).To(BeTrue())
+				Expect(versions[1].Version).To(Equal(1))
+				Expect(versions[1].Transferable).To(BeTrue())
+			})
+		})
+		Context("Rotate Key with a prior version validity of 0 minutes", func() {
+			It("Should fail to transfer the prior version", func() {
+				router.Handle("/keys/{id}/rotate", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Rotate))).Methods("POST")
+				req, err := http.NewRequest("POST", "/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/rotate",
+					strings.NewReader(`{"prior_version_validity_minutes": 0}`))
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				w = transfer("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer?version=1")
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+		Context("Rotate Key with a negative prior version validity", func() {
+			It("Should fail to rotate Key", func() {
+				router.Handle("/keys/{id}/rotate", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Rotate))).Methods("POST")
+				req, err := http.NewRequest("POST", "/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/rotate",
+					strings.NewReader(`{"prior_version_validity_minutes": -1}`))
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+		Context("Rotate Key by non-existent ID", func() {
+			It("Should fail to rotate Key", func() {
+				router.Handle("/keys/{id}/rotate", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Rotate))).Methods("POST")
+				req, err := http.NewRequest("POST", "/keys/73755fda-c910-46be-821f-e8ddeab189e9/rotate", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+		Context("Transfer a non-existent version of a Key", func() {
+			It("Should fail to transfer Key", func() {
+				w = transfer("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer?version=5")
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+
+				w = transfer("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer?version=latest")
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+
+	// Specs for HTTP Put to "/keys/{id}/usage-policy"
+	Describe("Limit the transfers of a Key with a usage policy", func() {
+		transfer := func(path string) *httptest.ResponseRecorder {
+			router.Handle("/keys/{id}/transfer", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Transfer))).Methods("POST")
+			req, err := http.NewRequest("POST", path, strings.NewReader(string(validEnvelopeKey)))
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+			req.Header.Set("Content-Type", consts.HTTPMediaTypePlain)
+			req = context.SetUserPermissions(req, keyPermissions)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			return recorder
+		}
+		updateUsagePolicy := func(path, usagePolicy string) *httptest.ResponseRecorder {
+			router.Handle("/keys/{id}/usage-policy", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.UpdateUsagePolicy))).Methods("PUT")
+			req, err := http.NewRequest("PUT", path, strings.NewReader(usagePolicy))
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+			req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+			req = context.SetUserPermissions(req, keyPermissions)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		Context("Transfer a Key more often than its maximum transfer count", func() {
+			It("Should count the transfers and fail once the limit is reached", func() {
+				w = updateUsagePolicy("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/usage-policy", `{"max_transfer_count": 1}`)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				w = transfer("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer")
+				Expect(w.Code).To(Equal(http.StatusOK))
+				w = transfer("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer")
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+
+				router.Handle("/keys/{id}", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Retrieve))).Methods("GET")
+				req, err := http.NewRequest("GET", "/keys/ee37c360-7eae-4250-a677-6ee12adce8e2", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var key kbs.KeyResponse
+				Expect(json.Unmarshal(w.Body.Bytes(), &key)).To(Succeed())
+				Expect(key.MaxTransferCount).To(Equal(1))
+				Expect(key.TransferCount).To(Equal(1))
+				Expect(key.LastTransferredAt).NotTo(BeNil())
+			})
+		})
+		Context("Transfer a Key with a public key too small to wrap it", func() {
+			It("Should fail to transfer Key without counting the transfer", func() {
+				w = updateUsagePolicy("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/usage-policy", `{"max_transfer_count": 1}`)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				// the OAEP padding with SHA-384 leaves 30 bytes of a 1024 bits modulus for the 32 bytes of the key
+				keyStore.KeyStore[uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2")].KeyData = "c2VjcmV0IGtleSBkYXRhIG9mIDMyIGJ5dGVzIGxvbmc="
+				smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+				Expect(err).NotTo(HaveOccurred())
+				publicKeyBytes, err := x509.MarshalPKIXPublicKey(&smallKey.PublicKey)
+				Expect(err).NotTo(HaveOccurred())
+				smallEnvelopeKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes})
+
+				router.Handle("/keys/{id}/transfer", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Transfer))).Methods("POST")
+				req, err := http.NewRequest("POST", "/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer", strings.NewReader(string(smallEnvelopeKey)))
+				Expect(err).NotTo(HaveOccurred())
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypePlain)
+				req = context.SetUserPermissions(req, keyPermissions)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusInternalServerError))
+
+				w = transfer("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer")
+				Expect(w.Code).To(Equal(http.StatusOK))
+			})
+		})
+		Context("Transfer a disabled Key", func() {
+			It("Should fail to transfer Key", func() {
+				w = updateUsagePolicy("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/usage-policy", `{"disabled": true}`)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				w = transfer("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer")
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+
+				w = updateUsagePolicy("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/usage-policy", `{"disabled": false}`)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				w = transfer("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer")
+				Expect(w.Code).To(Equal(http.StatusOK))
+			})
+		})
+		Context("Transfer a Key outside of its activation window", func() {
+			It("Should fail to transfer Key", func() {
+				notBefore := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+				w = updateUsagePolicy("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/usage-policy", `{"not_before": "`+notBefore+`"}`)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				w = transfer("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer")
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+
+				notAfter := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+				w = updateUsagePolicy("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/usage-policy", `{"not_after": "`+notAfter+`"}`)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				w = transfer("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer")
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+			})
+		})
+		Context("Provide an invalid usage policy", func() {
+			It("Should fail to update the usage policy", func() {
+				w = updateUsagePolicy("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/usage-policy", `{"max_transfer_count": -1}`)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+				w = updateUsagePolicy("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/usage-policy",
+					`{"not_before": "2030-01-02T00:00:00Z", "not_after": "2030-01-01T00:00:00Z"}`)
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+		Context("Update the usage policy of a non-existent Key", func() {
+			It("Should fail to update the usage policy", func() {
+				w = updateUsagePolicy("/keys/73755fda-c910-46be-821f-e8ddeab189e9/usage-policy", `{"disabled": true}`)
+				Expect(w.Code).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+
+	Describe("Record the transfers of a Key", func() {
+		transfer := func(path string) *httptest.ResponseRecorder {
+			router.Handle("/keys/{id}/transfer", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Transfer))).Methods("POST")
+			req, err := http.NewRequest("POST", path, strings.NewReader(string(validEnvelopeKey)))
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+			req.Header.Set("Content-Type", consts.HTTPMediaTypePlain)
+			req = context.SetUserPermissions(req, keyPermissions)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		Context("Transfer an existing Key", func() {
+			It("Should record the allowed transfer", func() {
+				w = transfer("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer")
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				records, _ := transferRecordStore.Search(nil)
+				Expect(len(records)).To(Equal(1))
+				Expect(records[0].KeyID).To(Equal(uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2")))
+				Expect(records[0].AttestationType).To(Equal(constants.AttestationTypeNone))
+				Expect(records[0].Decision).To(Equal(constants.KeyTransferAllowed))
+				Expect(records[0].Version).To(Equal(1))
+			})
+		})
+		Context("Transfer a Key refused by its usage policy", func() {
+			It("Should record the denied transfer with the reason", func() {
+				keyAttributes, _ := keyStore.Retrieve(uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2"))
+				keyAttributes.Disabled = true
+
+				w = transfer("/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/transfer")
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+
+				records, _ := transferRecordStore.Search(nil)
+				Expect(len(records)).To(Equal(1))
+				Expect(records[0].Decision).To(Equal(constants.KeyTransferDenied))
+				Expect(records[0].Reason).To(ContainSubstring(keymanager.ErrKeyDisabled.Error()))
+			})
+		})
+		Context("Transfer a non-existent Key", func() {
+			It("Should record the denied transfer", func() {
+				w = transfer("/keys/73755fda-c910-46be-821f-e8ddeab189e9/transfer")
 				Expect(w.Code).To(Equal(http.StatusNotFound))
+
+				records, _ := transferRecordStore.Search(nil)
+				Expect(len(records)).To(Equal(1))
+				Expect(records[0].KeyID).To(Equal(uuid.MustParse("73755fda-c910-46be-821f-e8ddeab189e9")))
+				Expect(records[0].Decision).To(Equal(constants.KeyTransferDenied))
 			})
 		})
 	})
@@ -537,6 +862,7 @@ var _ = Describe("KeyController", func() {
 				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/keys", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
 				w = httptest.NewRecorder()
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				router.ServeHTTP(w, req)
@@ -553,6 +879,7 @@ var _ = Describe("KeyController", func() {
 				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/keys?badparam=value", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -564,6 +891,7 @@ var _ = Describe("KeyController", func() {
 				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/keys?algorithm=AES", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -580,6 +908,7 @@ var _ = Describe("KeyController", func() {
 				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/keys?algorithm=AE$", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -591,6 +920,7 @@ var _ = Describe("KeyController", func() {
 				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/keys?keyLength=256", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -607,6 +937,7 @@ var _ = Describe("KeyController", func() {
 				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/keys?keyLength=abc", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -618,6 +949,7 @@ var _ = Describe("KeyController", func() {
 				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/keys?curveType=prime256v1", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -634,6 +966,7 @@ var _ = Describe("KeyController", func() {
 				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/keys?curveType=primev!", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -645,6 +978,7 @@ var _ = Describe("KeyController", func() {
 				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/keys?transferPolicyId=ee37c360-7eae-4250-a677-6ee12adce8e2", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -661,6 +995,7 @@ var _ = Describe("KeyController", func() {
 				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Search))).Methods("GET")
 				req, err := http.NewRequest("GET", "/keys?transferPolicyId=e57e5ea0-d465-461e-882d-", nil)
 				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, keyPermissions)
 				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
@@ -668,4 +1003,91 @@ var _ = Describe("KeyController", func() {
 			})
 		})
 	})
+
+	// Specs for the constraints set in the context of the key permissions
+	Describe("Access Keys with constrained permissions", func() {
+		Context("Create a Key with a label the constraints do not allow", func() {
+			It("Should fail to create new Key", func() {
+				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Create))).Methods("POST")
+				keyJson := `{
+								"key_information": {
+									"algorithm": "AES",
+									"key_length": 256
+								},
+								"label": "test-key"
+							}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/keys",
+					strings.NewReader(keyJson),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{{Service: constants.ServiceName,
+					Context: "label=prod-*", Rules: []string{constants.KeyCreate}}})
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusUnauthorized))
+			})
+		})
+		Context("Create a Key with a label the constraints allow", func() {
+			It("Should create a new Key", func() {
+				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Create))).Methods("POST")
+				keyJson := `{
+								"key_information": {
+									"algorithm": "AES",
+									"key_length": 256
+								},
+								"label": "prod-db"
+							}`
+
+				req, err := http.NewRequest(
+					"POST",
+					"/keys",
+					strings.NewReader(keyJson),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{{Service: constants.ServiceName,
+					Context: "label=prod-*", Rules: []string{constants.KeyCreate}}})
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Content-Type", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusCreated))
+			})
+		})
+		Context("Retrieve a Key the constraints do not allow", func() {
+			It("Should fail to retrieve Key", func() {
+				router.Handle("/keys/{id}", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Retrieve))).Methods("GET")
+				req, err := http.NewRequest("GET", "/keys/e57e5ea0-d465-461e-882d-1600090caa0d", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{{Service: constants.ServiceName,
+					Context: "algorithm=AES", Rules: []string{constants.KeyRetrieve}}})
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusUnauthorized))
+			})
+		})
+		Context("Search Keys with constrained permissions", func() {
+			It("Should get the Keys the constraints allow", func() {
+				router.Handle("/keys", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(keyController.Search))).Methods("GET")
+				req, err := http.NewRequest("GET", "/keys", nil)
+				Expect(err).NotTo(HaveOccurred())
+				req = context.SetUserPermissions(req, []aas.PermissionInfo{{Service: constants.ServiceName,
+					Context: "algorithm=AES", Rules: []string{constants.KeySearch}}})
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var keyResponses []kbs.KeyResponse
+				json.Unmarshal(w.Body.Bytes(), &keyResponses)
+				Expect(len(keyResponses)).To(Equal(1))
+				Expect(keyResponses[0].KeyInformation.Algorithm).To(Equal("AES"))
+			})
+		})
+	})
 })
@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/utils"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/validation"
+	"github.com/pkg/errors"
+)
+
+type KeyTransferRecordController struct {
+	store domain.KeyTransferRecordStore
+}
+
+func NewKeyTransferRecordController(rs domain.KeyTransferRecordStore) *KeyTransferRecordController {
+	return &KeyTransferRecordController{store: rs}
+}
+
+var keyTransferRecordSearchParams = map[string]bool{"keyId": true, "requester": true, "attestationType": true, "decision": true,
+	"fromDate": true, "toDate": true, "limit": true}
+var allowedKeyTransferDecisions = map[string]bool{consts.KeyTransferAllowed: true, consts.KeyTransferDenied: true, consts.KeyTransferChallenged: true}
+
+//Search : Function to search the records of the key transfers
+func (krc KeyTransferRecordController) Search(responseWriter http.ResponseWriter, request *http.Request) (interface{}, int, error) {
+	defaultLog.Trace("controllers/key_transfer_record_controller:Search() Entering")
+	defer defaultLog.Trace("controllers/key_transfer_record_controller:Search() Leaving")
+
+	// get the KeyTransferRecordFilterCriteria
+	criteria, err := getKeyTransferRecordFilterCriteria(request.URL.Query())
+	if err != nil {
+		secLog.WithError(err).Errorf("controllers/key_transfer_record_controller:Search() %s : Invalid filter criteria", commLogMsg.InvalidInputBadParam)
+		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: err.Error()}
+	}
+
+	records, err := krc.store.Search(criteria)
+	if err != nil {
+		defaultLog.WithError(err).Error("controllers/key_transfer_record_controller:Search() Key transfer records search failed")
+		return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to search key transfer records"}
+	}
+
+	secLog.Infof("controllers/key_transfer_record_controller:Search() %s: Key transfer records searched by: %s", commLogMsg.AuthorizedAccess, request.RemoteAddr)
+	return records, http.StatusOK, nil
+}
+
+//getKeyTransferRecordFilterCriteria checks for set filter params in the Search request and returns a valid KeyTransferRecordFilterCriteria
+func getKeyTransferRecordFilterCriteria(params url.Values) (*models.KeyTransferRecordFilterCriteria, error) {
+	defaultLog.Trace("controllers/key_transfer_record_controller:getKeyTransferRecordFilterCriteria() Entering")
+	defer defaultLog.Trace("controllers/key_transfer_record_controller:getKeyTransferRecordFilterCriteria() Leaving")
+
+	criteria := models.KeyTransferRecordFilterCriteria{}
+	if err := utils.ValidateQueryParams(params, keyTransferRecordSearchParams); err != nil {
+		return nil, err
+	}
+
+	// keyId
+	if param := strings.TrimSpace(params.Get("keyId")); param != "" {
+		id, err := uuid.Parse(param)
+		if err != nil {
+			return nil, errors.New("Valid UUID for keyId must be specified")
+		}
+		criteria.KeyId = id
+	}
+
+	// requester
+	if param := strings.TrimSpace(params.Get("requester")); param != "" {
+		if err := validation.ValidateStrings([]string{param}); err != nil {
+			return nil, errors.New("Valid contents for requester must be specified")
+		}
+		criteria.Requester = param
+	}
+
+	// attestationType
+	if param := strings.TrimSpace(params.Get("attestationType")); param != "" {
+		if err := validation.ValidateStrings([]string{param}); err != nil {
+			return nil, errors.New("Valid contents for attestationType must be specified")
+		}
+		criteria.AttestationType = param
+	}
+
+	// decision
+	if param := strings.TrimSpace(params.Get("decision")); param != "" {
+		if !allowedKeyTransferDecisions[param] {
+			return nil, errors.New("Valid decision (allowed, denied or challenged) must be specified")
+		}
+		criteria.Decision = param
+	}
+
+	// fromDate
+	if param := strings.TrimSpace(params.Get("fromDate")); param != "" {
+		pTime, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			return nil, errors.Wrap(err, "Valid date (YYYY-MM-DDThh:mm:ssZ) for fromDate must be specified")
+		}
+		criteria.FromDate = pTime
+	}
+
+	// toDate
+	if param := strings.TrimSpace(params.Get("toDate")); param != "" {
+		pTime, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			return nil, errors.Wrap(err, "Valid date (YYYY-MM-DDThh:mm:ssZ) for toDate must be specified")
+		}
+		criteria.ToDate = pTime
+	}
+
+	if !criteria.FromDate.IsZero() && !criteria.ToDate.IsZero() && criteria.ToDate.Before(criteria.FromDate) {
+		return nil, errors.New("toDate must not be before fromDate")
+	}
+
+	// limit - defaults per set limit
+	if param := strings.TrimSpace(params.Get("limit")); param != "" {
+		limit, err := strconv.Atoi(param)
+		if err != nil || limit <= 0 || limit > consts.MaxSearchResultRowLimit {
+			return nil, errors.Errorf("Limit must be an integer > 0 and <= %d", consts.MaxSearchResultRowLimit)
+		}
+		criteria.Limit = limit
+	} else {
+		criteria.Limit = consts.DefaultSearchResultRowLimit
+	}
+
+	return &criteria, nil
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/controllers"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/mocks"
+	kbsRoutes "github.com/intel-secl/intel-secl/v3/pkg/kbs/router"
+	consts "github.com/intel-secl/intel-secl/v3/pkg/lib/common/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KeyTransferRecordController", func() {
+	var router *mux.Router
+	var w *httptest.ResponseRecorder
+	var recordStore *mocks.MockKeyTransferRecordStore
+	var recordController *controllers.KeyTransferRecordController
+
+	keyId := uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2")
+	otherKeyId := uuid.MustParse("87d59b82-33b7-47e7-8fcb-6f7f12c82719")
+	now := time.Now().UTC()
+
+	BeforeEach(func() {
+		router = mux.NewRouter()
+		recordStore = mocks.NewFakeKeyTransferRecordStore()
+		recordStore.Create(&kbs.KeyTransferRecord{KeyID: keyId, Requester: "admin", AttestationType: constants.AttestationTypeNone,
+			Decision: constants.KeyTransferAllowed, Version: 1, CreatedAt: now.Add(-2 * time.Hour)})
+		recordStore.Create(&kbs.KeyTransferRecord{KeyID: keyId, Requester: "host-1", AttestationType: constants.AttestationTypeSaml,
+			Decision: constants.KeyTransferDenied, Reason: "Host is not trusted", CreatedAt: now.Add(-time.Hour)})
+		recordStore.Create(&kbs.KeyTransferRecord{KeyID: otherKeyId, Requester: "host-1", AttestationType: constants.AttestationTypeSaml,
+			Decision: constants.KeyTransferAllowed, Version: 2, CreatedAt: now})
+		recordController = controllers.NewKeyTransferRecordController(recordStore)
+	})
+
+	search := func(path string) []kbs.KeyTransferRecord {
+		router.Handle("/key-transfers", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(recordController.Search))).Methods("GET")
+		req, err := http.NewRequest("GET", path, nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var records []kbs.KeyTransferRecord
+		json.Unmarshal(w.Body.Bytes(), &records)
+		return records
+	}
+
+	// Specs for HTTP Get to "/key-transfers"
+	Describe("Search for the key transfer records", func() {
+		Context("Get all the key transfer records", func() {
+			It("Should get the list of all the records, the latest first", func() {
+				records := search("/key-transfers")
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(len(records)).To(Equal(3))
+				Expect(records[0].KeyID).To(Equal(otherKeyId))
+			})
+		})
+		Context("Get the key transfer records of a Key", func() {
+			It("Should get the list of the filtered records", func() {
+				records := search("/key-transfers?keyId=" + keyId.String())
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(len(records)).To(Equal(2))
+			})
+		})
+		Context("Get the key transfer records with requester and decision params", func() {
+			It("Should get the list of the filtered records", func() {
+				records := search("/key-transfers?requester=host-1&decision=denied")
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(len(records)).To(Equal(1))
+				Expect(records[0].Reason).To(Equal("Host is not trusted"))
+			})
+		})
+		Context("Get the key transfer records with date params", func() {
+			It("Should get the list of the filtered records", func() {
+				fromDate := now.Add(-90 * time.Minute).Format(time.RFC3339)
+				toDate := now.Add(-30 * time.Minute).Format(time.RFC3339)
+				records := search("/key-transfers?fromDate=" + fromDate + "&toDate=" + toDate)
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(len(records)).To(Equal(1))
+				Expect(records[0].Decision).To(Equal(constants.KeyTransferDenied))
+			})
+		})
+		Context("Get the key transfer records with limit param", func() {
+			It("Should get the latest records up to the limit", func() {
+				records := search("/key-transfers?limit=2")
+				Expect(w.Code).To(Equal(http.StatusOK))
+				Expect(len(records)).To(Equal(2))
+				Expect(records[0].KeyID).To(Equal(otherKeyId))
+			})
+		})
+		Context("Get the key transfer records with invalid params", func() {
+			It("Should fail to get the list of the records", func() {
+				search("/key-transfers?decision=unknown")
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+				search("/key-transfers?keyId=invalid")
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+				search("/key-transfers?fromDate=yesterday")
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+				search("/key-transfers?badparam=value")
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+				search("/key-transfers?limit=0")
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+				search("/key-transfers?limit=10001")
+				Expect(w.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+	})
+})
@@ -28,14 +28,16 @@ import (
 type SKCController struct {
 	remoteManager    *keymanager.RemoteManager
 	policyStore      domain.KeyTransferPolicyStore
+	transferStore    domain.KeyTransferRecordStore
 	config           *config.Configuration
 	trustedCaCertDir string
 }
 
-func NewSKCController(rm *keymanager.RemoteManager, ps domain.KeyTransferPolicyStore, kc *config.Configuration, caCertDir string) *SKCController {
+func NewSKCController(rm *keymanager.RemoteManager, ps domain.KeyTransferPolicyStore, ts domain.KeyTransferRecordStore, kc *config.Configuration, caCertDir string) *SKCController {
 	return &SKCController{
 		remoteManager:    rm,
 		policyStore:      ps,
+		transferStore:    ts,
 		config:           kc,
 		trustedCaCertDir: caCertDir,
 	}
@@ -45,19 +47,23 @@ func (kc *SKCController) TransferApplicationKey(responseWriter http.ResponseWrit
 	defaultLog.Trace("controllers/skc_controller:TransferApplicationKey() Entering")
 	defer defaultLog.Trace("controllers/skc_controller:TransferApplicationKey() Leaving")
 
+	// every refused transfer request is recorded, the invalid ones as well
+	keyID := uuid.MustParse(mux.Vars(request)["id"])
+	audit := keytransfer.NewTransferAudit(kc.transferStore, keyID, request.Header.Get("Accept-Challenge"))
+
 	stmChallenge, sessionId, err := validateKeyTransferRequest(request.Header)
 	if err != nil {
 		secLog.WithError(err).Error("controllers/skc_controller:TransferApplicationKey() Invalid transfer request")
+		audit.Deny("Transfer request is invalid")
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Transfer request is invalid"}
 	}
 
-	keyID := uuid.MustParse(mux.Vars(request)["id"])
-
 	keyInfo := keytransfer.GetKeyInfo()
 
 	keyInfo.PopulateStmLabels(stmChallenge, kc.config.Skc.StmLabel)
 
 	if len(keyInfo.FinalStmLabels) == 0 {
+		audit.Deny("Stm module requested by skc_library not supported by kbs")
 		secLog.Errorf("controllers/skc_controller:TransferApplicationKey() %s :Stm module requested by skc_library not supported by kbs", commLogMsg.InvalidInputBadParam)
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Stm module requested by skc_library not supported by kbs"}
 	}
@@ -68,9 +74,11 @@ func (kc *SKCController) TransferApplicationKey(responseWriter http.ResponseWrit
 
 	keyInfo.IssuerCommonName = request.TLS.PeerCertificates[0].Issuer.CommonName
 	userCommonName := request.TLS.PeerCertificates[0].Subject.CommonName
+	audit.SetRequester(userCommonName)
 
 	err = keyInfo.SetUserContext(userCommonName, kc.config, kc.trustedCaCertDir)
 	if err != nil {
+		audit.Deny("Couldn't fetch common name for specified user")
 		secLog.WithError(err).Error("controllers/skc_controller:TransferApplicationKey() error while getting common name")
 		return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Couldn't fetch common name for specified user"}
 	}
@@ -78,19 +86,24 @@ func (kc *SKCController) TransferApplicationKey(responseWriter http.ResponseWrit
 	key, err := kc.remoteManager.RetrieveKey(keyID)
 	if err != nil {
 		if err.Error() == commErr.RecordNotFound {
+			audit.Deny("Key with specified id does not exist")
 			defaultLog.Error("controllers/skc_controller:TransferApplicationKey() Key with specified id could not be located")
 			return nil, http.StatusNotFound, &commErr.ResourceError{Message: "Key with specified id does not exist"}
 		} else {
+			audit.Deny("Failed to retrieve key")
 			defaultLog.WithError(err).Error("controllers/skc_controller:TransferApplicationKey() Key retrieve failed")
 			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve key"}
 		}
 	}
+	audit.SetTransferPolicy(key.TransferPolicyID)
 	transferPolicy, err := kc.policyStore.Retrieve(key.TransferPolicyID)
 	if err != nil {
 		if err.Error() == commErr.RecordNotFound {
+			audit.Deny("specified transfer policy id does not exist")
 			defaultLog.Error("controllers/skc_controller:TransferApplicationKey() specified transfer policy id could not be located")
 			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "specified transfer policy id does not exist"}
 		} else {
+			audit.Deny("Failed to retrieve key transfer policy")
 			defaultLog.WithError(err).Error("controllers/skc_controller:TransferApplicationKey() Key transfer policy retrieve failed")
 			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve key transfer policy"}
 		}
@@ -98,7 +111,9 @@ func (kc *SKCController) TransferApplicationKey(responseWriter http.ResponseWrit
 	keyInfo.TransferPolicyAttributes = transferPolicy
 
 	isValidClient := keyInfo.IsValidClient()
+	audit.SetAttestationType(keyInfo.ActiveStmLabel)
 	if !isValidClient {
+		audit.Deny("client is not valid")
 		secLog.WithError(err).Error("controllers/skc_controller:TransferApplicationKey() client is not valid")
 		return nil, http.StatusUnauthorized, &commErr.ResourceError{Message: "client is not valid"}
 	}
@@ -107,6 +122,7 @@ func (kc *SKCController) TransferApplicationKey(responseWriter http.ResponseWrit
 		challenge, err := keyInfo.BuildChallengeJsonRequest(kc.config)
 		if err != nil {
 			secLog.WithError(err).Errorf("controllers/skc_controller:TransferApplicationKey() Failed to generate challenge")
+			audit.Deny("Error in building the challenge request")
 			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error in building the challenge request"}
 		} else if !(reflect.DeepEqual(challenge, kbs.ChallengeRequest{})) {
 			var t kbs.Fault ///if session is  not valid then NOt Authorized.
@@ -115,6 +131,7 @@ func (kc *SKCController) TransferApplicationKey(responseWriter http.ResponseWrit
 			challenge.Operation = constants.KeyTransferOpertaion
 			challenge.Status = constants.FailureStatus
 
+			audit.Challenge("session is not valid")
 			secLog.Info("controllers/skc_controller:TransferApplicationKey() Unauthorized: Generated Challenge")
 			return challenge, http.StatusUnauthorized, nil
 		}
@@ -132,46 +149,66 @@ func (kc *SKCController) TransferApplicationKey(responseWriter http.ResponseWrit
 			challenge.Operation = constants.KeyTransferOpertaion
 			challenge.Status = constants.FailureStatus
 
+			audit.Deny("sgx attributes verification failed")
 			secLog.Info("controllers/skc_controller:TransferApplicationKey() NotFound: sgx attributes verification failed")
 			return challenge, http.StatusNotFound, nil
 		}
 
+		sessionID, err := base64.StdEncoding.DecodeString(keyInfo.ActiveSessionID)
+		if err != nil {
+			secLog.WithError(err).Errorf("controllers/skc_controller:TransferApplicationKey() Failed to decode the active session id")
+			audit.Deny("Error in decoding the active session id")
+			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Error in decoding the active session id"}
+		}
+
 		defaultLog.Debug("Session is valid. Hence directly transfer the key")
-		keyData, err := kc.remoteManager.TransferKey(keyID)
+		// the key is wrapped for the application before the transfer is counted
+		applicationKey, version, err := kc.remoteManager.TransferKey(keyID, 0, func(keyData []byte) ([]byte, error) {
+			applicationKey, err := keyInfo.FetchApplicationKey(keyData, key.KeyInformation.Algorithm)
+			return []byte(applicationKey), err
+		})
 		if err != nil {
+			if wrapErr, ok := err.(*keymanager.KeyWrapError); ok {
+				secLog.WithError(wrapErr.Err).WithField("id", keyID).Error(
+					"controllers/skc_controller:TransferApplicationKey() Failed to fetch the application key")
+				audit.Deny("Error in fetching the application key")
+				return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error in fetching the application key"}
+			}
+			if keyUsagePolicyErrors[err] {
+				audit.Deny("Key usage policy does not allow the transfer: " + err.Error())
+				secLog.WithError(err).Errorf("controllers/skc_controller:TransferApplicationKey() %s : Key usage policy does not allow the transfer", commLogMsg.UnauthorizedAccess)
+				return nil, http.StatusForbidden, &commErr.ResourceError{Message: "Key usage policy does not allow the transfer: " + err.Error()}
+			}
+			audit.Deny("Failed to retrieve key")
 			defaultLog.WithError(err).Error("controllers/skc_controller:TransferApplicationKey() Key retrieve failed")
 			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to retrieve key"}
 		}
-		applicationKey, err := keyInfo.FetchApplicationKey(keyData, key.KeyInformation.Algorithm)
-		if err != nil {
-			secLog.WithError(err).WithField("id", keyID).Error(
-				"controllers/skc_controller:TransferApplicationKey() Failed to fetch the application key")
-			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error in fetching the application key"}
-		}
 
 		url := kc.config.EndpointURL + "/key-transfer-policies/" + key.TransferPolicyID.String()
 		var outputKeyData kbs.KeyTransferResponse
 		outputKeyData.KeyInfo.KeyAlgorithm = key.KeyInformation.Algorithm
 		outputKeyData.KeyInfo.CreatedAt = &key.CreatedAt
 		outputKeyData.KeyInfo.KeyId = keyID
-		outputKeyData.KeyInfo.KeyData = applicationKey
+		outputKeyData.KeyInfo.KeyData = string(applicationKey)
 		outputKeyData.KeyInfo.KeyLength = key.KeyInformation.KeyLength
+		outputKeyData.KeyInfo.Version = version
 		outputKeyData.KeyInfo.Policy.Link.KeyTransfer.Href = url
 		outputKeyData.KeyInfo.Policy.Link.KeyTransfer.Method = "get"
 		outputKeyData.Operation = constants.KeyTransferOpertaion
 		outputKeyData.Status = constants.SuccessStatus
 
-		sessionID, err := base64.StdEncoding.DecodeString(keyInfo.ActiveSessionID)
-		if err != nil {
-			secLog.WithError(err).Errorf("controllers/skc_controller:TransferApplicationKey() Failed to decode the active session id")
-			return nil, http.StatusBadRequest, &commErr.ResourceError{Message: "Error in decoding the active session id"}
+		if err := audit.Allow(version); err != nil {
+			defaultLog.WithError(err).Error("controllers/skc_controller:TransferApplicationKey() Failed to record the key transfer")
+			return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Failed to record the key transfer"}
 		}
+
 		sessionIDStr := fmt.Sprintf("%s:%s", keyInfo.ActiveStmLabel, sessionID)
 		responseWriter.Header().Add("Session-Id", sessionIDStr)
 		secLog.WithField("Key", keyID).Infof("controllers/skc_controller:TransferApplicationKey(): Successfully transferred the key: %s", request.RemoteAddr)
 		delete(keyInfo.SessionIDMap, keyInfo.ActiveStmLabel+keyInfo.ActiveSessionID)
 		return outputKeyData, http.StatusOK, nil
 	}
+	audit.Deny("session is not valid")
 	return nil, http.StatusInternalServerError, &commErr.ResourceError{Message: "Error in transferring the application key"}
 }
 
@@ -8,8 +8,10 @@ package controllers_test
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/controllers"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/mocks"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/keymanager"
@@ -31,6 +33,7 @@ var _ = Describe("SKCKeyTransferController", func() {
 	var w *httptest.ResponseRecorder
 	var keyStore *mocks.MockKeyStore
 	var policyStore *mocks.MockKeyTransferPolicyStore
+	var transferRecordStore *mocks.MockKeyTransferRecordStore
 	var remoteManager *keymanager.RemoteManager
 	var skcController *controllers.SKCController
 	var kbsConfig *config.Configuration
@@ -42,6 +45,7 @@ var _ = Describe("SKCKeyTransferController", func() {
 		server = ghttp.NewServer()
 		keyStore = mocks.NewFakeKeyStore()
 		policyStore = mocks.NewFakeKeyTransferPolicyStore()
+		transferRecordStore = mocks.NewFakeKeyTransferRecordStore()
 		kbsConfig = &config.Configuration{
 			AASApiUrl: "http://" + server.Addr() + "/aas/",
 			KBS: config.KBSConfig{
@@ -60,7 +64,7 @@ var _ = Describe("SKCKeyTransferController", func() {
 
 		keyManager := &keymanager.DirectoryManager{}
 		remoteManager = keymanager.NewRemoteManager(keyStore, keyManager, endpointUrl)
-		skcController = controllers.NewSKCController(remoteManager, policyStore, kbsConfig, trustedCaCertsDir)
+		skcController = controllers.NewSKCController(remoteManager, policyStore, transferRecordStore, kbsConfig, trustedCaCertsDir)
 		setupServer(server)
 	})
 
@@ -115,6 +119,31 @@ var _ = Describe("SKCKeyTransferController", func() {
 				w = httptest.NewRecorder()
 				router.ServeHTTP(w, req)
 				Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+				records, _ := transferRecordStore.Search(nil)
+				Expect(len(records)).To(Equal(1))
+				Expect(records[0].Decision).To(Equal(constants.KeyTransferDenied))
+				Expect(records[0].Reason).To(Equal("Transfer request is invalid"))
+			})
+		})
+		Context("Provide a Transfer request for a Key whose transfer policy does not exist", func() {
+			It("Should fail to transfer the Key", func() {
+				Expect(policyStore.Delete(uuid.MustParse("ee37c360-7eae-4250-a677-6ee12adce8e2"))).To(Succeed())
+
+				router.Handle("/keys/{id}/dhsm2-transfer", kbsRoutes.ErrorHandler(kbsRoutes.JsonResponseHandler(skcController.TransferApplicationKey))).Methods("GET")
+				req, err := http.NewRequest("GET", "/keys/ee37c360-7eae-4250-a677-6ee12adce8e2/dhsm2-transfer", nil)
+				req.Header.Set("Accept", consts.HTTPMediaTypeJson)
+				req.Header.Set("Accept-Challenge", "SGX")
+				req.TLS = &cs
+				Expect(err).NotTo(HaveOccurred())
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				Expect(w.Code).To(Equal(http.StatusInternalServerError))
+
+				records, _ := transferRecordStore.Search(nil)
+				Expect(len(records)).To(Equal(1))
+				Expect(records[0].Decision).To(Equal(constants.KeyTransferDenied))
+				Expect(records[0].Reason).To(Equal("specified transfer policy id does not exist"))
 			})
 		})
 		Context("Provide a Transfer request without Session-Id Header", func() {
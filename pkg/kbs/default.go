@@ -10,6 +10,7 @@ import (
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/config"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
 	commConfig "github.com/intel-secl/intel-secl/v3/pkg/lib/common/config"
+	commTls "github.com/intel-secl/intel-secl/v3/pkg/lib/common/tls"
 	"github.com/spf13/viper"
 )
 
@@ -17,12 +18,25 @@ import (
 func init() {
 	viper.SetDefault("endpoint-url", constants.DefaultEndpointUrl)
 	viper.SetDefault("key-manager", constants.DefaultKeyManager)
+	viper.SetDefault("store", constants.DefaultStore)
+
+	// Set default values for the database, it is only used by the postgres store
+	viper.SetDefault("db-vendor", constants.DBTypePostgres)
+	viper.SetDefault("db-host", "localhost")
+	viper.SetDefault("db-port", "5432")
+	viper.SetDefault("db-name", "kbs_db")
+	viper.SetDefault("db-ssl-mode", constants.SslModeVerifyFull)
+	viper.SetDefault("db-ssl-cert", constants.ConfigDir+"kbsdbsslcert.pem")
+	viper.SetDefault("db-conn-retry-attempts", constants.DefaultDbConnRetryAttempts)
+	viper.SetDefault("db-conn-retry-time", constants.DefaultDbConnRetryTime)
 
 	// Set default values for tls
 	viper.SetDefault("tls-cert-file", constants.DefaultTLSCertPath)
 	viper.SetDefault("tls-key-file", constants.DefaultTLSKeyPath)
 	viper.SetDefault("tls-common-name", constants.DefaultKbsTlsCn)
 	viper.SetDefault("tls-san-list", constants.DefaultKbsTlsSan)
+	viper.SetDefault("tls-renew-before", commTls.DefaultRenewBefore)
+	viper.SetDefault("tls-reload-interval", commTls.DefaultReloadInterval)
 
 	// Set default values for log
 	viper.SetDefault("log-max-length", constants.DefaultLogMaxlength)
@@ -37,6 +51,16 @@ func init() {
 	viper.SetDefault("server-idle-timeout", constants.DefaultIdleTimeout)
 	viper.SetDefault("server-max-header-bytes", constants.DefaultMaxHeaderBytes)
 
+	// Set default values for the key encryption key
+	viper.SetDefault("kek-provider", constants.DefaultKekProvider)
+	viper.SetDefault("kek-pkcs11-pin-file", constants.DefaultKekPkcs11PinFile)
+
+	// Set default values for key rotation
+	viper.SetDefault("key-rotation-prior-version-validity", constants.DefaultPriorKeyVersionValidity)
+
+	// Set default values for key transfer records
+	viper.SetDefault("key-transfer-records-max-count", constants.DefaultKeyTransferRecordsMaxCount)
+
 }
 
 func defaultConfig() *config.Configuration {
@@ -48,16 +72,21 @@ func defaultConfig() *config.Configuration {
 
 		EndpointURL: viper.GetString("endpoint-url"),
 		KeyManager:  viper.GetString("key-manager"),
+		Store:       viper.GetString("store"),
 
 		KBS: config.KBSConfig{
 			UserName: viper.GetString("kbs-service-username"),
 			Password: viper.GetString("kbs-service-password"),
 		},
 		TLS: commConfig.TLSCertConfig{
-			CertFile:   viper.GetString("tls-cert-file"),
-			KeyFile:    viper.GetString("tls-key-file"),
-			CommonName: viper.GetString("tls-common-name"),
-			SANList:    viper.GetString("tls-san-list"),
+			CertFile:       viper.GetString("tls-cert-file"),
+			KeyFile:        viper.GetString("tls-key-file"),
+			CommonName:     viper.GetString("tls-common-name"),
+			SANList:        viper.GetString("tls-san-list"),
+			RenewBefore:    viper.GetDuration("tls-renew-before"),
+			ReloadInterval: viper.GetDuration("tls-reload-interval"),
+			RenewUsername:  viper.GetString("tls-renew-username"),
+			RenewPassword:  viper.GetString("tls-renew-password"),
 		},
 		Log: commConfig.LogConfig{
 			MaxLength:    viper.GetInt("log-max-length"),
@@ -83,6 +112,21 @@ func defaultConfig() *config.Configuration {
 			StmLabel: viper.GetString("skc-challenge-type"),
 			SQVSUrl:  viper.GetString("sqvs-url"),
 		},
+		Kek: config.KekConfig{
+			Provider: viper.GetString("kek-provider"),
+			Pkcs11: config.Pkcs11Config{
+				Module:     viper.GetString("kek-pkcs11-module"),
+				TokenLabel: viper.GetString("kek-pkcs11-token-label"),
+				PinFile:    viper.GetString("kek-pkcs11-pin-file"),
+				Pin:        viper.GetString("kek-pkcs11-pin"),
+			},
+		},
+		KeyRotation: config.KeyRotationConfig{
+			PriorVersionValidity: viper.GetDuration("key-rotation-prior-version-validity"),
+		},
+		KeyTransferRecords: config.KeyTransferRecordsConfig{
+			MaxCount: viper.GetInt("key-transfer-records-max-count"),
+		},
 	}
 }
 
@@ -10,31 +10,53 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/kek"
 	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
 	"github.com/pkg/errors"
 )
 
+// tmpFilePrefix prefixes the key files being written, they are ignored when listing the keys
+const tmpFilePrefix = ".tmp-"
+
 type KeyStore struct {
 	dir string
+	// lock serializes the updates and deletions of the key files, a file is not replaced once it changed
+	lock sync.Mutex
+	// sealer encrypts the secrets of the keys in the files, new files are wrapped by its current KEK
+	sealer *kek.Sealer
+}
+
+func NewKeyStore(dir string, kekProvider domain.KeyEncryptionKeyProvider, kekId string) *KeyStore {
+	return &KeyStore{
+		dir:    dir,
+		sealer: kek.NewSealer(kekProvider, kekId),
+	}
 }
 
-func NewKeyStore(dir string) *KeyStore {
-	return &KeyStore{dir}
+// keyFile is the content of a key file, the secrets of the key are only stored encrypted in the envelope. The files
+// written before the envelope encryption hold the secrets in plaintext, without envelope.
+type keyFile struct {
+	models.KeyAttributes
+	Envelope *kek.Envelope `json:"envelope,omitempty"`
 }
 
 func (ks *KeyStore) Create(key *models.KeyAttributes) (*models.KeyAttributes, error) {
 	defaultLog.Trace("directory/key_store:Create() Entering")
 	defer defaultLog.Trace("directory/key_store:Create() Leaving")
 
-	bytes, err := json.Marshal(key)
+	file, err := ks.seal(key)
 	if err != nil {
-		return nil, errors.Wrap(err, "directory/key_store:Create() Failed to marshal key attributes")
+		return nil, errors.Wrap(err, "directory/key_store:Create() Failed to encrypt key attributes")
 	}
 
-	err = ioutil.WriteFile(filepath.Join(ks.dir, key.ID.String()), bytes, 0600)
+	err = ks.writeKeyFile(file)
 	if err != nil {
 		return nil, errors.Wrap(err, "directory/key_store:Create() Failed to store key attributes in file")
 	}
@@ -46,28 +68,256 @@ func (ks *KeyStore) Retrieve(id uuid.UUID) (*models.KeyAttributes, error) {
 	defaultLog.Trace("directory/key_store:Retrieve() Entering")
 	defer defaultLog.Trace("directory/key_store:Retrieve() Leaving")
 
+	file, err := ks.readKeyFile(id)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ks.open(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "directory/key_store:Retrieve() Failed to decrypt key attributes : %s", id.String())
+	}
+
+	return key, nil
+}
+
+// Update replaces the attributes of an existing key, the secrets are encrypted with a new data encryption key. The key
+// is only replaced while it is still at the revision it was read at, the update of a key updated by another request in
+// the meantime fails with RecordConflict. The transfers counted by CountTransfer are kept.
+func (ks *KeyStore) Update(key *models.KeyAttributes) (*models.KeyAttributes, error) {
+	defaultLog.Trace("directory/key_store:Update() Entering")
+	defer defaultLog.Trace("directory/key_store:Update() Leaving")
+
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	storedFile, err := ks.readKeyFile(key.ID)
+	if err != nil {
+		return nil, err
+	}
+	if storedFile.Revision != key.Revision {
+		return nil, errors.New(commErr.RecordConflict)
+	}
+
+	updatedKey := *key
+	updatedKey.Revision++
+	updatedKey.TransferCount = storedFile.TransferCount
+	updatedKey.LastTransferredAt = storedFile.LastTransferredAt
+	file, err := ks.seal(&updatedKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/key_store:Update() Failed to encrypt key attributes")
+	}
+
+	err = ks.writeKeyFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/key_store:Update() Failed to store key attributes in file")
+	}
+
+	return &updatedKey, nil
+}
+
+// CountTransfer counts a transfer of the key unless the key was already transferred maxTransferCount times, the
+// secrets of the key are not encrypted again
+func (ks *KeyStore) CountTransfer(id uuid.UUID, maxTransferCount int, transferredAt time.Time) (bool, error) {
+	defaultLog.Trace("directory/key_store:CountTransfer() Entering")
+	defer defaultLog.Trace("directory/key_store:CountTransfer() Leaving")
+
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	file, err := ks.readKeyFile(id)
+	if err != nil {
+		return false, err
+	}
+	if maxTransferCount > 0 && file.TransferCount >= maxTransferCount {
+		return false, nil
+	}
+
+	file.TransferCount++
+	file.LastTransferredAt = &transferredAt
+	if err = ks.writeKeyFile(file); err != nil {
+		return false, errors.Wrap(err, "directory/key_store:CountTransfer() Failed to store key attributes in file")
+	}
+	return true, nil
+}
+
+// Rewrap encrypts the secrets of the keys stored in plaintext and rewraps the data encryption keys wrapped by another
+// KEK with the current KEK. It returns the number of keys updated. Keys can be created, retrieved, updated and deleted
+// while the files are rewrapped.
+func (ks *KeyStore) Rewrap() (int, error) {
+	defaultLog.Trace("directory/key_store:Rewrap() Entering")
+	defer defaultLog.Trace("directory/key_store:Rewrap() Leaving")
+
+	ids, err := ks.Stale()
+	if err != nil {
+		return 0, err
+	}
+
+	rewrapped := 0
+	for _, id := range ids {
+		ok, err := ks.rewrapKeyFile(id)
+		if err != nil {
+			return rewrapped, err
+		}
+		if ok {
+			rewrapped++
+		}
+	}
+
+	return rewrapped, nil
+}
+
+// rewrapKeyFile rewraps the file of the key, it returns false if the key was deleted in the meantime
+func (ks *KeyStore) rewrapKeyFile(id uuid.UUID) (bool, error) {
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	file, err := ks.readKeyFile(id)
+	if err != nil {
+		if err.Error() == commErr.RecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var rewrappedFile *keyFile
+	if file.Envelope == nil {
+		rewrappedFile, err = ks.seal(&file.KeyAttributes)
+	} else {
+		rewrappedFile, err = ks.rewrap(file)
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "directory/key_store:rewrapKeyFile() Failed to rewrap key : %s", id.String())
+	}
+
+	if err = ks.writeKeyFile(rewrappedFile); err != nil {
+		return false, errors.Wrapf(err, "directory/key_store:rewrapKeyFile() Failed to store key : %s", id.String())
+	}
+	return true, nil
+}
+
+// Stale returns the IDs of the keys stored in plaintext or wrapped by another KEK than the current one
+func (ks *KeyStore) Stale() ([]uuid.UUID, error) {
+	defaultLog.Trace("directory/key_store:Stale() Entering")
+	defer defaultLog.Trace("directory/key_store:Stale() Leaving")
+
+	ids, err := ks.keyIds()
+	if err != nil {
+		return nil, err
+	}
+
+	var staleIds []uuid.UUID
+	for _, id := range ids {
+		file, err := ks.readKeyFile(id)
+		if err != nil {
+			if err.Error() == commErr.RecordNotFound {
+				continue
+			}
+			return nil, err
+		}
+		if file.Envelope == nil || file.Envelope.KekID != ks.sealer.KekID() {
+			staleIds = append(staleIds, id)
+		}
+	}
+
+	return staleIds, nil
+}
+
+// seal returns the file of the key with its secrets encrypted with a new data encryption key wrapped by the current KEK
+func (ks *KeyStore) seal(key *models.KeyAttributes) (*keyFile, error) {
+	sealedKey, envelope, err := ks.sealer.SealKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &keyFile{KeyAttributes: *sealedKey, Envelope: envelope}, nil
+}
+
+// open returns the attributes of the key with the secrets decrypted from the envelope
+func (ks *KeyStore) open(file *keyFile) (*models.KeyAttributes, error) {
+	return ks.sealer.OpenKey(&file.KeyAttributes, file.Envelope)
+}
+
+// rewrap returns the file with its data encryption key wrapped by the current KEK, the secrets are not decrypted
+func (ks *KeyStore) rewrap(file *keyFile) (*keyFile, error) {
+	envelope, err := ks.sealer.Rewrap(file.Envelope, []byte(file.ID.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	rewrappedFile := *file
+	rewrappedFile.Envelope = envelope
+	return &rewrappedFile, nil
+}
+
+func (ks *KeyStore) readKeyFile(id uuid.UUID) (*keyFile, error) {
 	bytes, err := ioutil.ReadFile(filepath.Join(ks.dir, id.String()))
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, errors.New(commErr.RecordNotFound)
 		} else {
-			return nil, errors.Wrapf(err, "directory/key_store:Retrieve() Unable to read key file : %s", id.String())
+			return nil, errors.Wrapf(err, "directory/key_store:readKeyFile() Unable to read key file : %s", id.String())
 		}
 	}
 
-	var key models.KeyAttributes
-	err = json.Unmarshal(bytes, &key)
+	var file keyFile
+	err = json.Unmarshal(bytes, &file)
 	if err != nil {
-		return nil, errors.Wrap(err, "directory/key_store:Retrieve() Failed to unmarshal key attributes")
+		return nil, errors.Wrap(err, "directory/key_store:readKeyFile() Failed to unmarshal key attributes")
 	}
 
-	return &key, nil
+	return &file, nil
+}
+
+// writeKeyFile replaces the key file atomically, the file is never read partially written
+func (ks *KeyStore) writeKeyFile(file *keyFile) error {
+	bytes, err := json.Marshal(file)
+	if err != nil {
+		return errors.Wrap(err, "directory/key_store:writeKeyFile() Failed to marshal key attributes")
+	}
+
+	tmpFile, err := ioutil.TempFile(ks.dir, tmpFilePrefix)
+	if err != nil {
+		return errors.Wrap(err, "directory/key_store:writeKeyFile() Failed to create temporary key file")
+	}
+	defer os.Remove(tmpFile.Name())
+	_, err = tmpFile.Write(bytes)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "directory/key_store:writeKeyFile() Failed to write temporary key file")
+	}
+	return os.Rename(tmpFile.Name(), filepath.Join(ks.dir, file.ID.String()))
+}
+
+// keyIds returns the IDs of the stored keys
+func (ks *KeyStore) keyIds() ([]uuid.UUID, error) {
+	keyFiles, err := ioutil.ReadDir(ks.dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "directory/key_store:keyIds() Error in reading the keys directory : %s", ks.dir)
+	}
+
+	var ids []uuid.UUID
+	for _, keyFile := range keyFiles {
+		if strings.HasPrefix(keyFile.Name(), tmpFilePrefix) {
+			continue
+		}
+		id, err := uuid.Parse(keyFile.Name())
+		if err != nil {
+			return nil, errors.Wrapf(err, "directory/key_store:keyIds() Error in parsing key file name : %s", keyFile.Name())
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
 }
 
 func (ks *KeyStore) Delete(id uuid.UUID) error {
 	defaultLog.Trace("directory/key_store:Delete() Entering")
 	defer defaultLog.Trace("directory/key_store:Delete() Leaving")
 
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
 	if err := os.Remove(filepath.Join(ks.dir, id.String())); err != nil {
 		if os.IsNotExist(err) {
 			return errors.New(commErr.RecordNotFound)
@@ -84,19 +334,19 @@ func (ks *KeyStore) Search(criteria *models.KeyFilterCriteria) ([]models.KeyAttr
 	defer defaultLog.Trace("directory/key_store:Search() Leaving")
 
 	var keys = []models.KeyAttributes{}
-	keyFiles, err := ioutil.ReadDir(ks.dir)
+	ids, err := ks.keyIds()
 	if err != nil {
-		return nil, errors.Wrapf(err, "directory/key_store:Search() Error in reading the keys directory : %s", ks.dir)
+		return nil, errors.Wrap(err, "directory/key_store:Search() Error in listing keys")
 	}
 
-	for _, keyFile := range keyFiles {
-		filename, err := uuid.Parse(keyFile.Name())
+	for _, id := range ids {
+		key, err := ks.Retrieve(id)
 		if err != nil {
-			return nil, errors.Wrapf(err, "directory/key_store:Search() Error in parsing key file name : %s", keyFile.Name())
-		}
-		key, err := ks.Retrieve(filename)
-		if err != nil {
-			return nil, errors.Wrapf(err, "directory/key_store:Search() Error in retrieving key from file : %s", keyFile.Name())
+			// the key may have been deleted since the directory was read
+			if err.Error() == commErr.RecordNotFound {
+				continue
+			}
+			return nil, errors.Wrapf(err, "directory/key_store:Search() Error in retrieving key from file : %s", id.String())
 		}
 
 		keys = append(keys, *key)
@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package directory
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/kek"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyStoreEncryptsSecrets(t *testing.T) {
+	a := assert.New(t)
+
+	keysDir, kekProvider, cleanup := newTestDirs(t)
+	defer cleanup()
+	kekId, err := kekProvider.Create()
+	a.NoError(err)
+	keyStore := NewKeyStore(keysDir, kekProvider, kekId)
+
+	key := &models.KeyAttributes{ID: uuid.New(), Algorithm: "AES", KeyLength: 256, KeyData: "c2VjcmV0IGtleSBkYXRh"}
+	_, err = keyStore.Create(key)
+	a.NoError(err)
+
+	bytes, err := ioutil.ReadFile(filepath.Join(keysDir, key.ID.String()))
+	a.NoError(err)
+	a.NotContains(string(bytes), key.KeyData)
+
+	stored, err := keyStore.Retrieve(key.ID)
+	a.NoError(err)
+	a.Equal(key, stored)
+
+	// the secrets of a key can not be moved to another key
+	var file keyFile
+	a.NoError(json.Unmarshal(bytes, &file))
+	file.ID = uuid.New()
+	a.NoError(keyStore.writeKeyFile(&file))
+	_, err = keyStore.Retrieve(file.ID)
+	a.Error(err)
+}
+
+func TestKeyStoreEncryptsPriorVersions(t *testing.T) {
+	a := assert.New(t)
+
+	keysDir, kekProvider, cleanup := newTestDirs(t)
+	defer cleanup()
+	kekId, err := kekProvider.Create()
+	a.NoError(err)
+	keyStore := NewKeyStore(keysDir, kekProvider, kekId)
+
+	key := &models.KeyAttributes{ID: uuid.New(), Algorithm: "AES", KeyLength: 256, KeyData: "c2VjcmV0IGtleSBkYXRh"}
+	_, err = keyStore.Create(key)
+	a.NoError(err)
+
+	now := time.Now().UTC()
+	rotatedKey := *key
+	rotatedKey.KeyData = "cm90YXRlZCBrZXkgZGF0YQ=="
+	rotatedKey.Version = 2
+	rotatedKey.RotatedAt = &now
+	rotatedKey.PriorVersions = []models.KeyVersion{key.RetireVersion(now, time.Hour)}
+	updatedKey, err := keyStore.Update(&rotatedKey)
+	a.NoError(err)
+	a.Equal(1, updatedKey.Revision)
+
+	// the key read before the rotation is not updated
+	_, err = keyStore.Update(key)
+	a.EqualError(err, commErr.RecordConflict)
+
+	bytes, err := ioutil.ReadFile(filepath.Join(keysDir, key.ID.String()))
+	a.NoError(err)
+	a.NotContains(string(bytes), key.KeyData)
+	a.NotContains(string(bytes), rotatedKey.KeyData)
+
+	stored, err := keyStore.Retrieve(key.ID)
+	a.NoError(err)
+	a.Equal(rotatedKey.KeyData, stored.KeyData)
+	a.Len(stored.PriorVersions, 1)
+	a.Equal(key.KeyData, stored.PriorVersions[0].KeyData)
+	a.True(stored.PriorVersions[0].IsTransferable(now))
+	a.Equal(1, stored.Revision)
+
+	// only existing keys are updated
+	rotatedKey.ID = uuid.New()
+	_, err = keyStore.Update(&rotatedKey)
+	a.Error(err)
+}
+
+func TestKeyStoreCountTransfer(t *testing.T) {
+	a := assert.New(t)
+
+	keysDir, kekProvider, cleanup := newTestDirs(t)
+	defer cleanup()
+	kekId, err := kekProvider.Create()
+	a.NoError(err)
+	keyStore := NewKeyStore(keysDir, kekProvider, kekId)
+
+	key := &models.KeyAttributes{ID: uuid.New(), Algorithm: "AES", KeyLength: 256, KeyData: "c2VjcmV0IGtleSBkYXRh"}
+	_, err = keyStore.Create(key)
+	a.NoError(err)
+	sealedFile, err := keyStore.readKeyFile(key.ID)
+	a.NoError(err)
+
+	now := time.Now().UTC()
+	for i := 0; i < 2; i++ {
+		counted, err := keyStore.CountTransfer(key.ID, 2, now)
+		a.NoError(err)
+		a.True(counted)
+	}
+	counted, err := keyStore.CountTransfer(key.ID, 2, now)
+	a.NoError(err)
+	a.False(counted)
+
+	// the secrets are not encrypted again
+	countedFile, err := keyStore.readKeyFile(key.ID)
+	a.NoError(err)
+	a.Equal(sealedFile.Envelope, countedFile.Envelope)
+	a.Equal(2, countedFile.TransferCount)
+
+	// the updates of the key keep the transfers counted
+	updatedKey, err := keyStore.Update(key)
+	a.NoError(err)
+	a.Equal(2, updatedKey.TransferCount)
+	stored, err := keyStore.Retrieve(key.ID)
+	a.NoError(err)
+	a.Equal(2, stored.TransferCount)
+	a.True(now.Equal(*stored.LastTransferredAt))
+
+	_, err = keyStore.CountTransfer(uuid.New(), 0, now)
+	a.EqualError(err, commErr.RecordNotFound)
+}
+
+func TestKeyStoreRewrap(t *testing.T) {
+	a := assert.New(t)
+
+	keysDir, kekProvider, cleanup := newTestDirs(t)
+	defer cleanup()
+
+	// a key stored in plaintext before the envelope encryption
+	plaintextKey := models.KeyAttributes{ID: uuid.New(), Algorithm: "RSA", KeyLength: 3072, PrivateKey: "cHJpdmF0ZSBrZXk="}
+	bytes, err := json.Marshal(plaintextKey)
+	a.NoError(err)
+	a.NoError(ioutil.WriteFile(filepath.Join(keysDir, plaintextKey.ID.String()), bytes, 0600))
+
+	previousKekId, err := kekProvider.Create()
+	a.NoError(err)
+	keyStore := NewKeyStore(keysDir, kekProvider, previousKekId)
+	rewrapped, err := keyStore.Rewrap()
+	a.NoError(err)
+	a.Equal(1, rewrapped)
+	key := &models.KeyAttributes{ID: uuid.New(), Algorithm: "AES", KeyLength: 128, KeyData: "a2V5IGRhdGE="}
+	_, err = keyStore.Create(key)
+	a.NoError(err)
+
+	// the keys rewrapped with a new KEK stay readable by the store using the previous one
+	kekId, err := kekProvider.Create()
+	a.NoError(err)
+	rewrapStore := NewKeyStore(keysDir, kekProvider, kekId)
+	stale, err := rewrapStore.Stale()
+	a.NoError(err)
+	a.Len(stale, 2)
+	rewrapped, err = rewrapStore.Rewrap()
+	a.NoError(err)
+	a.Equal(2, rewrapped)
+	stale, err = rewrapStore.Stale()
+	a.NoError(err)
+	a.Empty(stale)
+
+	keys, err := keyStore.Search(nil)
+	a.NoError(err)
+	a.Len(keys, 2)
+	stored, err := keyStore.Retrieve(plaintextKey.ID)
+	a.NoError(err)
+	a.Equal(plaintextKey, *stored)
+	stored, err = rewrapStore.Retrieve(key.ID)
+	a.NoError(err)
+	a.Equal(key, stored)
+}
+
+func newTestDirs(t *testing.T) (string, *kek.FileProvider, func()) {
+	keysDir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kekDir, err := ioutil.TempDir("", "kek")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keysDir, kek.NewFileProvider(kekDir), func() {
+		os.RemoveAll(keysDir)
+		os.RemoveAll(kekDir)
+	}
+}
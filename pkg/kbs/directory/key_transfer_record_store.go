@@ -0,0 +1,182 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package directory
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
+	"github.com/pkg/errors"
+)
+
+// KeyTransferRecordStore keeps a record per file, the modification time of a file is the creation time of its record.
+// The oldest records are deleted once the store holds more than the maximum number of records.
+type KeyTransferRecordStore struct {
+	dir      string
+	maxCount int
+}
+
+// NewKeyTransferRecordStore creates a store keeping at most maxCount records, the records are kept forever when
+// maxCount is 0
+func NewKeyTransferRecordStore(dir string, maxCount int) *KeyTransferRecordStore {
+	return &KeyTransferRecordStore{dir, maxCount}
+}
+
+func (ktrs *KeyTransferRecordStore) Create(record *kbs.KeyTransferRecord) (*kbs.KeyTransferRecord, error) {
+	defaultLog.Trace("directory/key_transfer_record_store:Create() Entering")
+	defer defaultLog.Trace("directory/key_transfer_record_store:Create() Leaving")
+
+	newUuid, err := uuid.NewRandom()
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/key_transfer_record_store:Create() failed to create new UUID")
+	}
+	record.ID = newUuid
+	record.CreatedAt = time.Now().UTC()
+
+	createdRecord, err := ktrs.Import(record)
+	if err != nil {
+		return nil, err
+	}
+	if err = ktrs.deleteOldest(); err != nil {
+		defaultLog.WithError(err).Error("directory/key_transfer_record_store:Create() Failed to delete the oldest key transfer records")
+	}
+	return createdRecord, nil
+}
+
+// Import stores the key transfer record with its ID and creation time, the records are imported when they are
+// migrated from another store
+func (ktrs *KeyTransferRecordStore) Import(record *kbs.KeyTransferRecord) (*kbs.KeyTransferRecord, error) {
+	defaultLog.Trace("directory/key_transfer_record_store:Import() Entering")
+	defer defaultLog.Trace("directory/key_transfer_record_store:Import() Leaving")
+
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/key_transfer_record_store:Import() Failed to marshal key transfer record")
+	}
+
+	recordFile := filepath.Join(ktrs.dir, record.ID.String())
+	err = ioutil.WriteFile(recordFile, bytes, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/key_transfer_record_store:Import() Error in saving key transfer record")
+	}
+	err = os.Chtimes(recordFile, record.CreatedAt, record.CreatedAt)
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/key_transfer_record_store:Import() Error in setting key transfer record time")
+	}
+
+	return record, nil
+}
+
+// Search returns the key transfer records matching the criteria, the latest first. The files are read from the latest
+// one and only until the limit is reached, the files out of the dates of the criteria are not read.
+func (ktrs *KeyTransferRecordStore) Search(criteria *models.KeyTransferRecordFilterCriteria) ([]kbs.KeyTransferRecord, error) {
+	defaultLog.Trace("directory/key_transfer_record_store:Search() Entering")
+	defer defaultLog.Trace("directory/key_transfer_record_store:Search() Leaving")
+
+	var records = []kbs.KeyTransferRecord{}
+	recordFiles, err := ktrs.readRecordFiles()
+	if err != nil {
+		return nil, errors.Wrap(err, "directory/key_transfer_record_store:Search() Unable to read the key transfer record directory")
+	}
+
+	for _, recordFile := range recordFiles {
+		if criteria != nil && criteria.Limit > 0 && len(records) == criteria.Limit {
+			break
+		}
+		if criteria != nil && !criteria.ToDate.IsZero() && recordFile.ModTime().After(criteria.ToDate) {
+			continue
+		}
+		// the modification times are truncated to the second on some file systems
+		if criteria != nil && !criteria.FromDate.IsZero() && recordFile.ModTime().Before(criteria.FromDate.Add(-time.Second)) {
+			break
+		}
+
+		bytes, err := ioutil.ReadFile(filepath.Join(ktrs.dir, recordFile.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "directory/key_transfer_record_store:Search() Unable to read key transfer record file : %s", recordFile.Name())
+		}
+
+		var record kbs.KeyTransferRecord
+		if err = json.Unmarshal(bytes, &record); err != nil {
+			return nil, errors.Wrapf(err, "directory/key_transfer_record_store:Search() Failed to unmarshal key transfer record : %s", recordFile.Name())
+		}
+
+		if matchesKeyTransferRecord(&record, criteria) {
+			records = append(records, record)
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].CreatedAt.After(records[j].CreatedAt)
+	})
+	return records, nil
+}
+
+// readRecordFiles lists the record files, the latest first
+func (ktrs *KeyTransferRecordStore) readRecordFiles() ([]os.FileInfo, error) {
+	recordFiles, err := ioutil.ReadDir(ktrs.dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(recordFiles, func(i, j int) bool {
+		return recordFiles[i].ModTime().After(recordFiles[j].ModTime())
+	})
+	return recordFiles, nil
+}
+
+// deleteOldest deletes the oldest records beyond the maximum number of records
+func (ktrs *KeyTransferRecordStore) deleteOldest() error {
+	if ktrs.maxCount <= 0 {
+		return nil
+	}
+	recordFiles, err := ktrs.readRecordFiles()
+	if err != nil {
+		return errors.Wrap(err, "directory/key_transfer_record_store:deleteOldest() Unable to read the key transfer record directory")
+	}
+	if len(recordFiles) <= ktrs.maxCount {
+		return nil
+	}
+	for _, recordFile := range recordFiles[ktrs.maxCount:] {
+		err = os.Remove(filepath.Join(ktrs.dir, recordFile.Name()))
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "directory/key_transfer_record_store:deleteOldest() Unable to delete key transfer record file : %s", recordFile.Name())
+		}
+	}
+	defaultLog.Debugf("directory/key_transfer_record_store:deleteOldest() Deleted %d key transfer records", len(recordFiles)-ktrs.maxCount)
+	return nil
+}
+
+// helper function to check if the key transfer record matches the given filter criteria.
+func matchesKeyTransferRecord(record *kbs.KeyTransferRecord, criteria *models.KeyTransferRecordFilterCriteria) bool {
+	if criteria == nil {
+		return true
+	}
+	if criteria.KeyId != uuid.Nil && record.KeyID != criteria.KeyId {
+		return false
+	}
+	if criteria.Requester != "" && record.Requester != criteria.Requester {
+		return false
+	}
+	if criteria.AttestationType != "" && record.AttestationType != criteria.AttestationType {
+		return false
+	}
+	if criteria.Decision != "" && record.Decision != criteria.Decision {
+		return false
+	}
+	if !criteria.FromDate.IsZero() && record.CreatedAt.Before(criteria.FromDate) {
+		return false
+	}
+	if !criteria.ToDate.IsZero() && record.CreatedAt.After(criteria.ToDate) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package directory
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyTransferRecordStoreDeletesOldest(t *testing.T) {
+	a := assert.New(t)
+
+	recordsDir, err := ioutil.TempDir("", "key-transfers")
+	a.NoError(err)
+	defer os.RemoveAll(recordsDir)
+	recordStore := NewKeyTransferRecordStore(recordsDir, 3)
+
+	// the records imported from another store keep their creation time
+	keyId := uuid.New()
+	createdAt := time.Now().UTC().Add(-time.Hour)
+	for i := 0; i < 3; i++ {
+		_, err = recordStore.Import(&kbs.KeyTransferRecord{ID: uuid.New(), KeyID: keyId, CreatedAt: createdAt.Add(time.Duration(i) * time.Minute),
+			Decision: constants.KeyTransferAllowed})
+		a.NoError(err)
+	}
+	_, err = recordStore.Create(&kbs.KeyTransferRecord{KeyID: keyId, Decision: constants.KeyTransferDenied})
+	a.NoError(err)
+
+	records, err := recordStore.Search(nil)
+	a.NoError(err)
+	a.Len(records, 3)
+	a.Equal(constants.KeyTransferDenied, records[0].Decision)
+	a.Equal(createdAt.Add(2*time.Minute), records[1].CreatedAt)
+	a.Equal(createdAt.Add(time.Minute), records[2].CreatedAt)
+
+	// the search stops at the limit and at the date the records are searched from
+	records, err = recordStore.Search(&models.KeyTransferRecordFilterCriteria{Limit: 1})
+	a.NoError(err)
+	a.Len(records, 1)
+	a.Equal(constants.KeyTransferDenied, records[0].Decision)
+
+	records, err = recordStore.Search(&models.KeyTransferRecordFilterCriteria{FromDate: createdAt.Add(90 * time.Second),
+		ToDate: createdAt.Add(30 * time.Minute)})
+	a.NoError(err)
+	a.Len(records, 1)
+	a.Equal(createdAt.Add(2*time.Minute), records[0].CreatedAt)
+}
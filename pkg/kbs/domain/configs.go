@@ -4,11 +4,19 @@
  */
 package domain
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type KeyControllerConfig struct {
-	SamlCertsDir            string
+	SamlCertStore           CertificateStore
 	TrustedCaCertsDir       string
-	TpmIdentityCertsDir     string
+	TpmIdentityCertStore    CertificateStore
 	DefaultTransferPolicyId uuid.UUID
+	// PriorKeyVersionValidity is how long the version of a key replaced by a rotation stays transferable by default
+	PriorKeyVersionValidity time.Duration
+	// KeyTransferRecordStore keeps the records of the transfer attempts
+	KeyTransferRecordStore KeyTransferRecordStore
 }
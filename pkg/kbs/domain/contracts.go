@@ -5,6 +5,8 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
 	"github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
@@ -14,8 +16,21 @@ type (
 	KeyStore interface {
 		Create(*models.KeyAttributes) (*models.KeyAttributes, error)
 		Retrieve(uuid.UUID) (*models.KeyAttributes, error)
+		Update(*models.KeyAttributes) (*models.KeyAttributes, error)
 		Delete(uuid.UUID) error
 		Search(criteria *models.KeyFilterCriteria) ([]models.KeyAttributes, error)
+		// CountTransfer counts a transfer of the key at the given time unless the key was already transferred
+		// maxTransferCount times, the transfers are not limited when it is 0. It returns false when the limit is reached.
+		CountTransfer(id uuid.UUID, maxTransferCount int, transferredAt time.Time) (bool, error)
+	}
+
+	// SealedKeyStore stores the secrets of the keys encrypted with data encryption keys wrapped by a KEK
+	SealedKeyStore interface {
+		KeyStore
+		// Rewrap rewraps the keys wrapped by another KEK with the current one and returns the number of keys updated
+		Rewrap() (int, error)
+		// Stale returns the IDs of the keys not wrapped by the current KEK
+		Stale() ([]uuid.UUID, error)
 	}
 
 	KeyTransferPolicyStore interface {
@@ -25,6 +40,24 @@ type (
 		Search(criteria *models.KeyTransferPolicyFilterCriteria) ([]kbs.KeyTransferPolicyAttributes, error)
 	}
 
+	// KeyTransferRecordStore keeps the records of the attempts to transfer keys, the records are never updated
+	KeyTransferRecordStore interface {
+		Create(record *kbs.KeyTransferRecord) (*kbs.KeyTransferRecord, error)
+		Search(criteria *models.KeyTransferRecordFilterCriteria) ([]kbs.KeyTransferRecord, error)
+	}
+
+	// KeyEncryptionKey wraps the data encryption keys with which the secrets of the stored keys are encrypted
+	KeyEncryptionKey interface {
+		Wrap(dek, additionalData []byte) ([]byte, error)
+		Unwrap(wrappedDek, additionalData []byte) ([]byte, error)
+	}
+
+	// KeyEncryptionKeyProvider creates KEKs and retrieves them by ID
+	KeyEncryptionKeyProvider interface {
+		Create() (string, error)
+		Retrieve(id string) (KeyEncryptionKey, error)
+	}
+
 	CertificateStore interface {
 		Create(certificate *kbs.Certificate) (*kbs.Certificate, error)
 		Retrieve(uuid.UUID) (*kbs.Certificate, error)
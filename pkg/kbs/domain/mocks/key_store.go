@@ -34,6 +34,36 @@ func (store *MockKeyStore) Retrieve(id uuid.UUID) (*models.KeyAttributes, error)
 	return nil, errors.New(commErr.RecordNotFound)
 }
 
+// Update replaces a Key in the store, the Key must still be at the revision it was read at
+func (store *MockKeyStore) Update(k *models.KeyAttributes) (*models.KeyAttributes, error) {
+	if stored, ok := store.KeyStore[k.ID]; ok {
+		if stored.Revision != k.Revision {
+			return nil, errors.New(commErr.RecordConflict)
+		}
+		updated := *k
+		updated.Revision++
+		updated.TransferCount = stored.TransferCount
+		updated.LastTransferredAt = stored.LastTransferredAt
+		store.KeyStore[k.ID] = &updated
+		return &updated, nil
+	}
+	return nil, errors.New(commErr.RecordNotFound)
+}
+
+// CountTransfer counts a transfer of the Key unless the limit is reached
+func (store *MockKeyStore) CountTransfer(id uuid.UUID, maxTransferCount int, transferredAt time.Time) (bool, error) {
+	k, ok := store.KeyStore[id]
+	if !ok {
+		return false, errors.New(commErr.RecordNotFound)
+	}
+	if maxTransferCount > 0 && k.TransferCount >= maxTransferCount {
+		return false, nil
+	}
+	k.TransferCount++
+	k.LastTransferredAt = &transferredAt
+	return true, nil
+}
+
 // Delete deletes Key from the store
 func (store *MockKeyStore) Delete(id uuid.UUID) error {
 	if _, ok := store.KeyStore[id]; ok {
@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package mocks
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
+)
+
+// MockKeyTransferRecordStore provides a mocked implementation of interface domain.KeyTransferRecordStore
+type MockKeyTransferRecordStore struct {
+	KeyTransferRecordStore []kbs.KeyTransferRecord
+}
+
+// Create inserts a KeyTransferRecord into the store
+func (store *MockKeyTransferRecordStore) Create(r *kbs.KeyTransferRecord) (*kbs.KeyTransferRecord, error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now().UTC()
+	}
+	store.KeyTransferRecordStore = append(store.KeyTransferRecordStore, *r)
+	return r, nil
+}
+
+// Search returns the KeyTransferRecords matching the provided KeyTransferRecordFilterCriteria, the latest first
+func (store *MockKeyTransferRecordStore) Search(criteria *models.KeyTransferRecordFilterCriteria) ([]kbs.KeyTransferRecord, error) {
+
+	records := []kbs.KeyTransferRecord{}
+	for i := len(store.KeyTransferRecordStore) - 1; i >= 0; i-- {
+		r := store.KeyTransferRecordStore[i]
+		if criteria != nil {
+			if criteria.KeyId != uuid.Nil && r.KeyID != criteria.KeyId ||
+				criteria.Requester != "" && r.Requester != criteria.Requester ||
+				criteria.AttestationType != "" && r.AttestationType != criteria.AttestationType ||
+				criteria.Decision != "" && r.Decision != criteria.Decision ||
+				!criteria.FromDate.IsZero() && r.CreatedAt.Before(criteria.FromDate) ||
+				!criteria.ToDate.IsZero() && r.CreatedAt.After(criteria.ToDate) {
+				continue
+			}
+			if criteria.Limit > 0 && len(records) == criteria.Limit {
+				break
+			}
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// NewFakeKeyTransferRecordStore provides an empty MockKeyTransferRecordStore
+func NewFakeKeyTransferRecordStore() *MockKeyTransferRecordStore {
+	return &MockKeyTransferRecordStore{}
+}
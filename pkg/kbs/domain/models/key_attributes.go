@@ -11,21 +11,119 @@ import (
 	"github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
 )
 
-// KeyAttributes - Contains all possible key attributes.
+// KeyAttributes - Contains all possible key attributes. The key material is the one of the primary version of the key,
+// the material of the versions it replaced is kept in PriorVersions. The usage policy limits the transfers of the key,
+// TransferCount counts them. Revision counts the updates of the stored key, an update of a key read at an older
+// revision is refused.
 type KeyAttributes struct {
-	ID               uuid.UUID `json:"id"`
-	Algorithm        string    `json:"algorithm"`
-	KeyLength        int       `json:"key_length,omitempty"`
-	KeyData          string    `json:"key,omitempty"`
-	CurveType        string    `json:"curve_type,omitempty"`
-	PublicKey        string    `json:"public_key,omitempty"`
-	PrivateKey       string    `json:"private_key,omitempty"`
-	KmipKeyID        string    `json:"kmip_key_id,omitempty"`
-	TransferPolicyId uuid.UUID `json:"transfer_policy_id,omitempty"`
-	TransferLink     string    `json:"transfer_link,omitempty"`
-	CreatedAt        time.Time `json:"created_at,omitempty"`
-	Label            string    `json:"label,omitempty"`
-	Usage            string    `json:"usage,omitempty"`
+	ID               uuid.UUID    `json:"id"`
+	Algorithm        string       `json:"algorithm"`
+	KeyLength        int          `json:"key_length,omitempty"`
+	KeyData          string       `json:"key,omitempty"`
+	CurveType        string       `json:"curve_type,omitempty"`
+	PublicKey        string       `json:"public_key,omitempty"`
+	PrivateKey       string       `json:"private_key,omitempty"`
+	KmipKeyID        string       `json:"kmip_key_id,omitempty"`
+	TransferPolicyId uuid.UUID    `json:"transfer_policy_id,omitempty"`
+	TransferLink     string       `json:"transfer_link,omitempty"`
+	CreatedAt        time.Time    `json:"created_at,omitempty"`
+	Label            string       `json:"label,omitempty"`
+	Usage            string       `json:"usage,omitempty"`
+	Version          int          `json:"version,omitempty"`
+	RotatedAt        *time.Time   `json:"rotated_at,omitempty"`
+	PriorVersions    []KeyVersion `json:"prior_versions,omitempty"`
+	kbs.KeyUsagePolicy
+	TransferCount     int        `json:"transfer_count,omitempty"`
+	LastTransferredAt *time.Time `json:"last_transferred_at,omitempty"`
+	Revision          int        `json:"revision,omitempty"`
+}
+
+// KeyVersion - The material of a version of a key replaced by a rotation, it stays transferable until TransferableUntil
+type KeyVersion struct {
+	Version           int       `json:"version"`
+	KeyLength         int       `json:"key_length,omitempty"`
+	KeyData           string    `json:"key,omitempty"`
+	CurveType         string    `json:"curve_type,omitempty"`
+	PublicKey         string    `json:"public_key,omitempty"`
+	PrivateKey        string    `json:"private_key,omitempty"`
+	KmipKeyID         string    `json:"kmip_key_id,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	RetiredAt         time.Time `json:"retired_at"`
+	TransferableUntil time.Time `json:"transferable_until"`
+}
+
+// PrimaryVersion returns the version number of the key material, the keys created before versioning are version 1
+func (ka *KeyAttributes) PrimaryVersion() int {
+	if ka.Version == 0 {
+		return 1
+	}
+	return ka.Version
+}
+
+// PrimaryCreatedAt returns the time the primary version of the key was created
+func (ka *KeyAttributes) PrimaryCreatedAt() time.Time {
+	if ka.RotatedAt != nil {
+		return *ka.RotatedAt
+	}
+	return ka.CreatedAt
+}
+
+// RetireVersion returns the primary version of the key, retired at the given time and transferable for the given duration
+func (ka *KeyAttributes) RetireVersion(retiredAt time.Time, validity time.Duration) KeyVersion {
+	return KeyVersion{
+		Version:           ka.PrimaryVersion(),
+		KeyLength:         ka.KeyLength,
+		KeyData:           ka.KeyData,
+		CurveType:         ka.CurveType,
+		PublicKey:         ka.PublicKey,
+		PrivateKey:        ka.PrivateKey,
+		KmipKeyID:         ka.KmipKeyID,
+		CreatedAt:         ka.PrimaryCreatedAt(),
+		RetiredAt:         retiredAt,
+		TransferableUntil: retiredAt.Add(validity),
+	}
+}
+
+// FindPriorVersion returns the prior version of the key with the given number, nil if the key has no such version
+func (ka *KeyAttributes) FindPriorVersion(version int) *KeyVersion {
+	for i := range ka.PriorVersions {
+		if ka.PriorVersions[i].Version == version {
+			return &ka.PriorVersions[i]
+		}
+	}
+	return nil
+}
+
+// WithVersion returns the attributes of the key with the material of the given prior version
+func (ka *KeyAttributes) WithVersion(version *KeyVersion) *KeyAttributes {
+	attributes := *ka
+	attributes.Version = version.Version
+	attributes.KeyLength = version.KeyLength
+	attributes.KeyData = version.KeyData
+	attributes.CurveType = version.CurveType
+	attributes.PublicKey = version.PublicKey
+	attributes.PrivateKey = version.PrivateKey
+	attributes.KmipKeyID = version.KmipKeyID
+	attributes.PriorVersions = nil
+	return &attributes
+}
+
+// IsTransferable returns true if the material of the prior version can still be transferred at the given time
+func (kv *KeyVersion) IsTransferable(at time.Time) bool {
+	return at.Before(kv.TransferableUntil) && kv.HasMaterial()
+}
+
+// HasMaterial returns false once the material of the prior version is deleted
+func (kv *KeyVersion) HasMaterial() bool {
+	return kv.KeyData != "" || kv.PrivateKey != "" || kv.KmipKeyID != ""
+}
+
+// ClearMaterial removes the material of the prior version, the version remains listed
+func (kv *KeyVersion) ClearMaterial() {
+	kv.KeyData = ""
+	kv.PublicKey = ""
+	kv.PrivateKey = ""
+	kv.KmipKeyID = ""
 }
 
 func (ka *KeyAttributes) ToKeyResponse() *kbs.KeyResponse {
@@ -39,13 +137,42 @@ func (ka *KeyAttributes) ToKeyResponse() *kbs.KeyResponse {
 	}
 
 	keyResponse := kbs.KeyResponse{
-		KeyInformation:   &keyInformation,
-		TransferPolicyID: ka.TransferPolicyId,
-		TransferLink:     ka.TransferLink,
-		CreatedAt:        ka.CreatedAt,
-		Label:            ka.Label,
-		Usage:            ka.Usage,
+		KeyInformation:    &keyInformation,
+		TransferPolicyID:  ka.TransferPolicyId,
+		TransferLink:      ka.TransferLink,
+		CreatedAt:         ka.CreatedAt,
+		Label:             ka.Label,
+		Usage:             ka.Usage,
+		Version:           ka.PrimaryVersion(),
+		KeyUsagePolicy:    ka.KeyUsagePolicy,
+		TransferCount:     ka.TransferCount,
+		LastTransferredAt: ka.LastTransferredAt,
 	}
 
 	return &keyResponse
 }
+
+// ToKeyVersionResponses returns the primary version of the key followed by its prior versions, newest first
+func (ka *KeyAttributes) ToKeyVersionResponses(now time.Time) []kbs.KeyVersionResponse {
+	versions := []kbs.KeyVersionResponse{{
+		Version:      ka.PrimaryVersion(),
+		Primary:      true,
+		Transferable: true,
+		CreatedAt:    ka.PrimaryCreatedAt(),
+	}}
+
+	for i := len(ka.PriorVersions) - 1; i >= 0; i-- {
+		version := ka.PriorVersions[i]
+		retiredAt := version.RetiredAt
+		transferableUntil := version.TransferableUntil
+		versions = append(versions, kbs.KeyVersionResponse{
+			Version:           version.Version,
+			Transferable:      version.IsTransferable(now),
+			CreatedAt:         version.CreatedAt,
+			RetiredAt:         &retiredAt,
+			TransferableUntil: &transferableUntil,
+		})
+	}
+
+	return versions
+}
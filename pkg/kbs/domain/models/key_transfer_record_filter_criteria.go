@@ -0,0 +1,22 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//KeyTransferRecordFilterCriteria stores the parameters for filtering the key transfer records
+type KeyTransferRecordFilterCriteria struct {
+	KeyId           uuid.UUID
+	Requester       string
+	AttestationType string
+	Decision        string
+	FromDate        time.Time
+	ToDate          time.Time
+	Limit           int
+}
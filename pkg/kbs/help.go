@@ -34,7 +34,10 @@ Available Tasks for setup:
 	server                              Setup http server on given port
 	download-ca-cert                    Download CMS root CA certificate
 	download-cert-tls                   Download CA certificate from CMS for tls
+	database                            Select the store and setup the database of the postgres store
 	create-default-key-transfer-policy  Create default key transfer policy for KBS
+	rewrap                              Create a key encryption key and rewrap the stored keys with it
+	migrate-to-postgres                 Copy the keys, key transfer policies and certificates of the directory store to postgres
 `
 
 func (app *App) printUsage() {
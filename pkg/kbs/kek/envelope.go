@@ -0,0 +1,230 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package kek
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/json"
+	"sync"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	"github.com/pkg/errors"
+)
+
+// Envelope holds data encrypted with AES-GCM by a data encryption key of its own, which is wrapped by a KEK. Both are
+// bound to the same additional data.
+type Envelope struct {
+	KekID      string `json:"kek_id"`
+	WrappedDek []byte `json:"wrapped_dek"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Sealer seals data in envelopes wrapped by the current KEK and opens the envelopes wrapped by any KEK of the provider
+type Sealer struct {
+	provider domain.KeyEncryptionKeyProvider
+	kekId    string
+	keks     map[string]domain.KeyEncryptionKey
+	lock     sync.Mutex
+}
+
+func NewSealer(provider domain.KeyEncryptionKeyProvider, kekId string) *Sealer {
+	return &Sealer{
+		provider: provider,
+		kekId:    kekId,
+		keks:     make(map[string]domain.KeyEncryptionKey),
+	}
+}
+
+// KekID returns the ID of the KEK wrapping the new envelopes
+func (s *Sealer) KekID() string {
+	return s.kekId
+}
+
+// Seal encrypts the plaintext with a new data encryption key wrapped by the current KEK
+func (s *Sealer) Seal(plaintext, additionalData []byte) (*Envelope, error) {
+	kek, err := s.kek(s.kekId)
+	if err != nil {
+		return nil, err
+	}
+
+	dek, err := crypt.GetRandomBytes(constants.DekLength / 8)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to generate data encryption key")
+	}
+	aead, err := newDekCipher(dek)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := crypt.GetRandomBytes(aead.NonceSize())
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to generate nonce")
+	}
+	wrappedDek, err := kek.Wrap(dek, additionalData)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to wrap data encryption key")
+	}
+
+	return &Envelope{
+		KekID:      s.kekId,
+		WrappedDek: wrappedDek,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, additionalData),
+	}, nil
+}
+
+// Open decrypts the plaintext of the envelope
+func (s *Sealer) Open(envelope *Envelope, additionalData []byte) ([]byte, error) {
+	dek, err := s.unwrap(envelope, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newDekCipher(dek)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, envelope.Nonce, envelope.Ciphertext, additionalData)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to decrypt envelope")
+	}
+	return plaintext, nil
+}
+
+// Rewrap returns the envelope with its data encryption key wrapped by the current KEK, the data is not decrypted
+func (s *Sealer) Rewrap(envelope *Envelope, additionalData []byte) (*Envelope, error) {
+	dek, err := s.unwrap(envelope, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	kek, err := s.kek(s.kekId)
+	if err != nil {
+		return nil, err
+	}
+	wrappedDek, err := kek.Wrap(dek, additionalData)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to wrap data encryption key")
+	}
+
+	rewrapped := *envelope
+	rewrapped.KekID = s.kekId
+	rewrapped.WrappedDek = wrappedDek
+	return &rewrapped, nil
+}
+
+func (s *Sealer) unwrap(envelope *Envelope, additionalData []byte) ([]byte, error) {
+	kek, err := s.kek(envelope.KekID)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := kek.Unwrap(envelope.WrappedDek, additionalData)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to unwrap data encryption key")
+	}
+	return dek, nil
+}
+
+// kek returns the KEK with the given ID, the KEKs are retrieved once from the provider. The envelopes may be wrapped by
+// a KEK created after the sealer, while they are rewrapped by another process.
+func (s *Sealer) kek(id string) (domain.KeyEncryptionKey, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if kek, ok := s.keks[id]; ok {
+		return kek, nil
+	}
+	if s.provider == nil || id == "" {
+		return nil, errors.New("No key encryption key configured")
+	}
+	kek, err := s.provider.Retrieve(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to retrieve key encryption key %s", id)
+	}
+	s.keks[id] = kek
+	return kek, nil
+}
+
+func newDekCipher(dek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dek)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create data encryption key cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create data encryption key cipher")
+	}
+	return aead, nil
+}
+
+// keySecrets are the attributes of a key encrypted in its envelope, the secrets of the prior versions of the key are
+// in the order of the versions
+type keySecrets struct {
+	KeyData       string       `json:"key,omitempty"`
+	PrivateKey    string       `json:"private_key,omitempty"`
+	PriorVersions []keySecrets `json:"prior_versions,omitempty"`
+}
+
+// SealKey returns the attributes of the key without its secrets and the envelope of the secrets, which is bound to the
+// ID of the key
+func (s *Sealer) SealKey(key *models.KeyAttributes) (*models.KeyAttributes, *Envelope, error) {
+	plaintext := keySecrets{KeyData: key.KeyData, PrivateKey: key.PrivateKey}
+	for _, version := range key.PriorVersions {
+		plaintext.PriorVersions = append(plaintext.PriorVersions, keySecrets{KeyData: version.KeyData, PrivateKey: version.PrivateKey})
+	}
+	secrets, err := json.Marshal(plaintext)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Failed to marshal key secrets")
+	}
+
+	envelope, err := s.Seal(secrets, []byte(key.ID.String()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sealedKey := *key
+	sealedKey.KeyData = ""
+	sealedKey.PrivateKey = ""
+	sealedKey.PriorVersions = nil
+	for _, version := range key.PriorVersions {
+		version.KeyData = ""
+		version.PrivateKey = ""
+		sealedKey.PriorVersions = append(sealedKey.PriorVersions, version)
+	}
+	return &sealedKey, envelope, nil
+}
+
+// OpenKey returns the attributes of the key with the secrets decrypted from the envelope, the keys stored before the
+// envelope encryption have no envelope
+func (s *Sealer) OpenKey(sealedKey *models.KeyAttributes, envelope *Envelope) (*models.KeyAttributes, error) {
+	key := *sealedKey
+	if envelope == nil {
+		return &key, nil
+	}
+
+	plaintext, err := s.Open(envelope, []byte(key.ID.String()))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to decrypt key secrets")
+	}
+	var secrets keySecrets
+	if err = json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal key secrets")
+	}
+	if len(secrets.PriorVersions) != len(sealedKey.PriorVersions) {
+		return nil, errors.New("Key secrets do not match the versions of the key")
+	}
+
+	key.KeyData = secrets.KeyData
+	key.PrivateKey = secrets.PrivateKey
+	key.PriorVersions = nil
+	for i, version := range sealedKey.PriorVersions {
+		version.KeyData = secrets.PriorVersions[i].KeyData
+		version.PrivateKey = secrets.PriorVersions[i].PrivateKey
+		key.PriorVersions = append(key.PriorVersions, version)
+	}
+	return &key, nil
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package kek
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	"github.com/pkg/errors"
+)
+
+// FileProvider keeps each KEK in a file of its directory named by the ID of the KEK
+type FileProvider struct {
+	dir string
+}
+
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir}
+}
+
+func (fp *FileProvider) Create() (string, error) {
+	defaultLog.Trace("kek/file_provider:Create() Entering")
+	defer defaultLog.Trace("kek/file_provider:Create() Leaving")
+
+	key, err := crypt.GetRandomBytes(constants.KekLength / 8)
+	if err != nil {
+		return "", errors.Wrap(err, "kek/file_provider:Create() Failed to generate KEK")
+	}
+	if err = os.MkdirAll(fp.dir, 0700); err != nil {
+		return "", errors.Wrap(err, "kek/file_provider:Create() Failed to create KEK directory")
+	}
+	id := uuid.New().String()
+	if err = ioutil.WriteFile(filepath.Join(fp.dir, id), key, 0600); err != nil {
+		return "", errors.Wrap(err, "kek/file_provider:Create() Failed to store KEK in file")
+	}
+	return id, nil
+}
+
+func (fp *FileProvider) Retrieve(id string) (domain.KeyEncryptionKey, error) {
+	defaultLog.Trace("kek/file_provider:Retrieve() Entering")
+	defer defaultLog.Trace("kek/file_provider:Retrieve() Leaving")
+
+	// the ID is read from the key files, it must not point outside of the KEK directory
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errors.Errorf("kek/file_provider:Retrieve() Invalid KEK ID %s", id)
+	}
+	key, err := ioutil.ReadFile(filepath.Join(fp.dir, id))
+	if err != nil {
+		return nil, errors.Wrapf(err, "kek/file_provider:Retrieve() Unable to read KEK file : %s", id)
+	}
+	return newAesGcmKey(key)
+}
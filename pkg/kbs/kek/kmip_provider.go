@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package kek
+
+import (
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/kmipclient"
+	"github.com/pkg/errors"
+)
+
+// KmipProvider keeps the KEKs in a kmip server, identified by their kmip ID. The KEKs are retrieved from the server
+// to wrap the data encryption keys.
+type KmipProvider struct {
+	client kmipclient.KmipClient
+}
+
+func NewKmipProvider(client kmipclient.KmipClient) *KmipProvider {
+	return &KmipProvider{client}
+}
+
+func (kp *KmipProvider) Create() (string, error) {
+	defaultLog.Trace("kek/kmip_provider:Create() Entering")
+	defer defaultLog.Trace("kek/kmip_provider:Create() Leaving")
+
+	id, err := kp.client.CreateSymmetricKey(constants.KMIP_CRYPTOALG_AES, constants.KekLength)
+	if err != nil {
+		return "", errors.Wrap(err, "kek/kmip_provider:Create() Failed to create KEK on kmip server")
+	}
+	return id, nil
+}
+
+func (kp *KmipProvider) Retrieve(id string) (domain.KeyEncryptionKey, error) {
+	defaultLog.Trace("kek/kmip_provider:Retrieve() Entering")
+	defer defaultLog.Trace("kek/kmip_provider:Retrieve() Leaving")
+
+	key, err := kp.client.GetSymmetricKey(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "kek/kmip_provider:Retrieve() Failed to retrieve KEK %s from kmip server", id)
+	}
+	return newAesGcmKey(key)
+}
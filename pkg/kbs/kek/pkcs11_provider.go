@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package kek
+
+import (
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/pkcs11"
+	"github.com/pkg/errors"
+)
+
+// Pkcs11Provider keeps the KEKs in a PKCS#11 token, labelled with their ID. The KEKs never leave the token.
+type Pkcs11Provider struct {
+	token *pkcs11.Token
+}
+
+func NewPkcs11Provider(token *pkcs11.Token) *Pkcs11Provider {
+	return &Pkcs11Provider{token}
+}
+
+func (pp *Pkcs11Provider) Create() (string, error) {
+	defaultLog.Trace("kek/pkcs11_provider:Create() Entering")
+	defer defaultLog.Trace("kek/pkcs11_provider:Create() Leaving")
+
+	id := uuid.New().String()
+	if _, err := pp.token.GenerateAesKey(id, constants.KekLength); err != nil {
+		return "", errors.Wrap(err, "kek/pkcs11_provider:Create() Failed to generate KEK")
+	}
+	return id, nil
+}
+
+func (pp *Pkcs11Provider) Retrieve(id string) (domain.KeyEncryptionKey, error) {
+	defaultLog.Trace("kek/pkcs11_provider:Retrieve() Entering")
+	defer defaultLog.Trace("kek/pkcs11_provider:Retrieve() Leaving")
+
+	key, err := pp.token.FindAesKey(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "kek/pkcs11_provider:Retrieve() Failed to find KEK %s", id)
+	}
+	return &pkcs11Key{key}, nil
+}
+
+// pkcs11Key wraps the data encryption keys with AES-GCM in the token, the wrapped keys are prefixed with their nonce
+type pkcs11Key struct {
+	key *pkcs11.AesKey
+}
+
+func (k *pkcs11Key) Wrap(dek, additionalData []byte) ([]byte, error) {
+	nonce, err := crypt.GetRandomBytes(gcmNonceSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to generate nonce")
+	}
+	wrappedDek, err := k.key.Seal(nonce, dek, additionalData)
+	if err != nil {
+		return nil, err
+	}
+	return append(nonce, wrappedDek...), nil
+}
+
+func (k *pkcs11Key) Unwrap(wrappedDek, additionalData []byte) ([]byte, error) {
+	if len(wrappedDek) < gcmNonceSize {
+		return nil, errors.New("Wrapped data encryption key is too short")
+	}
+	return k.key.Open(wrappedDek[:gcmNonceSize], wrappedDek[gcmNonceSize:], additionalData)
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package kek
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"strings"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/kmipclient"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/pkcs11"
+	"github.com/pkg/errors"
+)
+
+var defaultLog = log.GetDefaultLogger()
+
+// gcmNonceSize is the size of the nonces prepended to the data encryption keys wrapped with AES-GCM
+const gcmNonceSize = 12
+
+// NewProvider returns the provider of the KEKs configured in kekConfig
+func NewProvider(kekConfig *config.KekConfig, kmipConfig *config.KmipConfig) (domain.KeyEncryptionKeyProvider, error) {
+	defaultLog.Trace("kek/provider:NewProvider() Entering")
+	defer defaultLog.Trace("kek/provider:NewProvider() Leaving")
+
+	switch strings.ToLower(kekConfig.Provider) {
+	case constants.FileKekProvider, "":
+		return NewFileProvider(constants.KekDir), nil
+	case constants.Pkcs11KekProvider:
+		pin, err := pkcs11.ReadPin(constants.KekPkcs11PinEnv, kekConfig.Pkcs11.PinFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to read KEK token PIN")
+		}
+		token, err := pkcs11.OpenToken(kekConfig.Pkcs11.Module, kekConfig.Pkcs11.TokenLabel, pin)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to open KEK token")
+		}
+		return NewPkcs11Provider(token), nil
+	case constants.KmipKekProvider:
+		kmipClient := kmipclient.NewKmipClient()
+		err := kmipClient.InitializeClient(kmipConfig.ServerIP, kmipConfig.ServerPort, kmipConfig.ClientCert, kmipConfig.ClientKey, kmipConfig.RootCert)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to initialize KEK kmip client")
+		}
+		return NewKmipProvider(kmipClient), nil
+	default:
+		return nil, errors.Errorf("Unsupported KEK provider %s", kekConfig.Provider)
+	}
+}
+
+// aesGcmKey is a KEK held in memory that wraps the data encryption keys with AES-GCM, the wrapped keys are prefixed
+// with their nonce
+type aesGcmKey struct {
+	aead cipher.AEAD
+}
+
+func newAesGcmKey(key []byte) (*aesGcmKey, error) {
+	if len(key) != constants.KekLength/8 {
+		return nil, errors.Errorf("Invalid KEK length %d", len(key)*8)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create KEK cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create KEK cipher")
+	}
+	return &aesGcmKey{aead: aead}, nil
+}
+
+func (k *aesGcmKey) Wrap(dek, additionalData []byte) ([]byte, error) {
+	nonce, err := crypt.GetRandomBytes(gcmNonceSize)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to generate nonce")
+	}
+	return k.aead.Seal(nonce, nonce, dek, additionalData), nil
+}
+
+func (k *aesGcmKey) Unwrap(wrappedDek, additionalData []byte) ([]byte, error) {
+	if len(wrappedDek) < gcmNonceSize {
+		return nil, errors.New("Wrapped data encryption key is too short")
+	}
+	dek, err := k.aead.Open(nil, wrappedDek[:gcmNonceSize], wrappedDek[gcmNonceSize:], additionalData)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to unwrap data encryption key")
+	}
+	return dek, nil
+}
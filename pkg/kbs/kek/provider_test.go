@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package kek
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/kmipclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileProvider(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "kek")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+
+	provider := NewFileProvider(dir)
+	id, err := provider.Create()
+	a.NoError(err)
+	kek, err := provider.Retrieve(id)
+	a.NoError(err)
+
+	wrappedDek, err := kek.Wrap([]byte("data encryption key"), []byte("key id"))
+	a.NoError(err)
+	dek, err := kek.Unwrap(wrappedDek, []byte("key id"))
+	a.NoError(err)
+	a.Equal("data encryption key", string(dek))
+	_, err = kek.Unwrap(wrappedDek, []byte("other key id"))
+	a.Error(err)
+
+	_, err = provider.Retrieve("../" + id)
+	a.Error(err)
+}
+
+func TestKmipProvider(t *testing.T) {
+	a := assert.New(t)
+
+	key := make([]byte, constants.KekLength/8)
+	mockClient := kmipclient.NewMockKmipClient()
+	mockClient.On("CreateSymmetricKey", constants.KMIP_CRYPTOALG_AES, constants.KekLength).Return("1", nil)
+	mockClient.On("GetSymmetricKey", "1").Return(key, nil)
+
+	provider := NewKmipProvider(mockClient)
+	id, err := provider.Create()
+	a.NoError(err)
+	a.Equal("1", id)
+	kek, err := provider.Retrieve(id)
+	a.NoError(err)
+
+	wrappedDek, err := kek.Wrap([]byte("data encryption key"), nil)
+	a.NoError(err)
+	dek, err := kek.Unwrap(wrappedDek, nil)
+	a.NoError(err)
+	a.Equal("data encryption key", string(dek))
+}
@@ -162,6 +162,77 @@ func (dm *DirectoryManager) RegisterKey(request *kbs.KeyRequest) (*models.KeyAtt
 	return keyAttributes, nil
 }
 
+func (dm *DirectoryManager) RotateKey(attributes *models.KeyAttributes) (*models.KeyAttributes, error) {
+	defaultLog.Trace("keymanager/directory_key_manager:RotateKey() Entering")
+	defer defaultLog.Trace("keymanager/directory_key_manager:RotateKey() Leaving")
+
+	keyInformation, err := materialInformation(attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	material, err := dm.CreateKey(&kbs.KeyRequest{KeyInformation: keyInformation})
+	if err != nil {
+		return nil, err
+	}
+
+	return withMaterial(attributes, material), nil
+}
+
+// materialInformation returns the algorithm and the length or curve of the material of a key, they are read from the
+// material of the registered keys
+func materialInformation(attributes *models.KeyAttributes) (*kbs.KeyInformation, error) {
+	keyInformation := &kbs.KeyInformation{
+		Algorithm: attributes.Algorithm,
+		KeyLength: attributes.KeyLength,
+		CurveType: attributes.CurveType,
+	}
+
+	if attributes.Algorithm == constants.CRYPTOALG_AES {
+		if keyInformation.KeyLength == 0 {
+			key, err := base64.StdEncoding.DecodeString(attributes.KeyData)
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to decode key")
+			}
+			keyInformation.KeyLength = len(key) * 8
+		}
+		return keyInformation, nil
+	}
+
+	if keyInformation.KeyLength != 0 || keyInformation.CurveType != "" {
+		return keyInformation, nil
+	}
+
+	privateKeyBytes, err := base64.StdEncoding.DecodeString(attributes.PrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to decode private key")
+	}
+	private, err := x509.ParsePKCS8PrivateKey(privateKeyBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse private key")
+	}
+
+	switch key := private.(type) {
+	case *rsa.PrivateKey:
+		keyInformation.KeyLength = key.N.BitLen()
+	case *ecdsa.PrivateKey:
+		switch key.Curve {
+		case elliptic.P256():
+			keyInformation.CurveType = "secp256r1"
+		case elliptic.P384():
+			keyInformation.CurveType = "secp384r1"
+		case elliptic.P521():
+			keyInformation.CurveType = "secp521r1"
+		default:
+			return nil, errors.New("unsupported curve type")
+		}
+	default:
+		return nil, errors.Errorf("%s algorithm is not supported", attributes.Algorithm)
+	}
+
+	return keyInformation, nil
+}
+
 func (dm *DirectoryManager) TransferKey(attributes *models.KeyAttributes) ([]byte, error) {
 	defaultLog.Trace("keymanager/directory_key_manager:TransferKey() Entering")
 	defer defaultLog.Trace("keymanager/directory_key_manager:TransferKey() Leaving")
@@ -37,4 +37,19 @@ type KeyManager interface {
 	DeleteKey(*models.KeyAttributes) error
 	RegisterKey(*kbs.KeyRequest) (*models.KeyAttributes, error)
 	TransferKey(*models.KeyAttributes) ([]byte, error)
+	// RotateKey returns the attributes of the key with new material of the same algorithm, the prior material is not
+	// deleted
+	RotateKey(*models.KeyAttributes) (*models.KeyAttributes, error)
+}
+
+// withMaterial returns the attributes of the key with the material of the other attributes
+func withMaterial(attributes, material *models.KeyAttributes) *models.KeyAttributes {
+	rotated := *attributes
+	rotated.KeyLength = material.KeyLength
+	rotated.KeyData = material.KeyData
+	rotated.CurveType = material.CurveType
+	rotated.PublicKey = material.PublicKey
+	rotated.PrivateKey = material.PrivateKey
+	rotated.KmipKeyID = material.KmipKeyID
+	return &rotated
 }
@@ -88,6 +88,36 @@ func (km *KmipManager) RegisterKey(request *kbs.KeyRequest) (*models.KeyAttribut
 	return keyAttributes, nil
 }
 
+func (km *KmipManager) RotateKey(attributes *models.KeyAttributes) (*models.KeyAttributes, error) {
+	defaultLog.Trace("keymanager/kmip_key_manager:RotateKey() Entering")
+	defer defaultLog.Trace("keymanager/kmip_key_manager:RotateKey() Leaving")
+
+	if attributes.KmipKeyID == "" {
+		return nil, errors.New("key is not created with KMIP key manager")
+	}
+
+	if attributes.Algorithm != constants.CRYPTOALG_AES {
+		return nil, errors.Errorf("%s algorithm is not supported", attributes.Algorithm)
+	}
+
+	// the length of the registered keys is known from their material only
+	keyLength := attributes.KeyLength
+	if keyLength == 0 {
+		key, err := km.client.GetSymmetricKey(attributes.KmipKeyID)
+		if err != nil {
+			return nil, err
+		}
+		keyLength = len(key) * 8
+	}
+
+	kmipId, err := km.client.CreateSymmetricKey(constants.KMIP_CRYPTOALG_AES, keyLength)
+	if err != nil {
+		return nil, err
+	}
+
+	return withMaterial(attributes, &models.KeyAttributes{KeyLength: keyLength, KmipKeyID: kmipId}), nil
+}
+
 func (km *KmipManager) TransferKey(attributes *models.KeyAttributes) ([]byte, error) {
 	defaultLog.Trace("keymanager/kmip_key_manager:TransferKey() Entering")
 	defer defaultLog.Trace("keymanager/kmip_key_manager:TransferKey() Leaving")
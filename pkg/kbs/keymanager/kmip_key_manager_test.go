@@ -89,3 +89,26 @@ func TestKmipManager_TransferKey(t *testing.T) {
 	assert.NoError(err)
 	assert.Equal([]byte(""), key)
 }
+
+func TestKmipManager_RotateKey(t *testing.T) {
+	assert := assert.New(t)
+
+	keyAttributes := &models.KeyAttributes{
+		Algorithm: "AES",
+		KmipKeyID: "1",
+		Label:     "label",
+	}
+
+	mockClient := kmipclient.NewMockKmipClient()
+	mockClient.On("GetSymmetricKey", "1").Return(make([]byte, 32), nil)
+	mockClient.On("CreateSymmetricKey", mock.Anything, 256).Return("2", nil)
+
+	keyManager := &KmipManager{mockClient}
+
+	rotatedKey, err := keyManager.RotateKey(keyAttributes)
+	assert.NoError(err)
+	assert.Equal("2", rotatedKey.KmipKeyID)
+	assert.Equal(256, rotatedKey.KeyLength)
+	assert.Equal("label", rotatedKey.Label)
+	assert.Equal("1", keyAttributes.KmipKeyID)
+}
@@ -7,13 +7,36 @@ package keymanager
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
 	"github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
+	"github.com/pkg/errors"
 )
 
+var (
+	ErrKeyVersionNotFound        = errors.New("key version not found")
+	ErrKeyVersionNotTransferable = errors.New("key version is no longer transferable")
+
+	// the usage policy of the key does not allow the transfer
+	ErrKeyDisabled             = errors.New("key is disabled")
+	ErrKeyNotYetValid          = errors.New("key is not yet transferable")
+	ErrKeyExpired              = errors.New("key is no longer transferable")
+	ErrKeyTransferLimitReached = errors.New("key transfer limit is reached")
+)
+
+// KeyWrapError is returned by TransferKey when the key could not be wrapped for the requester, the transfer is not
+// counted then
+type KeyWrapError struct {
+	Err error
+}
+
+func (e *KeyWrapError) Error() string {
+	return "Failed to wrap key: " + e.Err.Error()
+}
+
 type RemoteManager struct {
 	store       domain.KeyStore
 	manager     KeyManager
@@ -37,6 +60,7 @@ func (rm *RemoteManager) CreateKey(request *kbs.KeyRequest) (*kbs.KeyResponse, e
 		return nil, err
 	}
 
+	keyAttributes.KeyUsagePolicy = request.KeyUsagePolicy
 	keyAttributes.TransferLink = rm.getTransferLink(keyAttributes.ID)
 	storedKey, err := rm.store.Create(keyAttributes)
 	if err != nil {
@@ -70,6 +94,11 @@ func (rm *RemoteManager) DeleteKey(keyId uuid.UUID) error {
 	if err := rm.manager.DeleteKey(keyAttributes); err != nil {
 		return err
 	}
+	for i := range keyAttributes.PriorVersions {
+		if err := rm.deleteVersionMaterial(keyAttributes, &keyAttributes.PriorVersions[i]); err != nil {
+			return err
+		}
+	}
 
 	return rm.store.Delete(keyId)
 }
@@ -100,6 +129,7 @@ func (rm *RemoteManager) RegisterKey(request *kbs.KeyRequest) (*kbs.KeyResponse,
 		return nil, err
 	}
 
+	keyAttributes.KeyUsagePolicy = request.KeyUsagePolicy
 	keyAttributes.TransferLink = rm.getTransferLink(keyAttributes.ID)
 	storedKey, err := rm.store.Create(keyAttributes)
 	if err != nil {
@@ -109,16 +139,165 @@ func (rm *RemoteManager) RegisterKey(request *kbs.KeyRequest) (*kbs.KeyResponse,
 	return storedKey.ToKeyResponse(), nil
 }
 
-func (rm *RemoteManager) TransferKey(keyId uuid.UUID) ([]byte, error) {
+// TransferKey returns the material of the given version of the key wrapped for the requester along with the version
+// number, the primary version is transferred when the version is 0. A prior version is transferable until the end of
+// its validity. The usage policy of the key is enforced and the transfer is counted by the store, which enforces the
+// transfer limit against concurrent transfers. The transfer is only counted once the material is wrapped, a failure to
+// wrap it is returned as a KeyWrapError.
+func (rm *RemoteManager) TransferKey(keyId uuid.UUID, version int, wrap func(secret []byte) ([]byte, error)) ([]byte, int, error) {
 	defaultLog.Trace("keymanager/remote_key_manager:TransferKey() Entering")
 	defer defaultLog.Trace("keymanager/remote_key_manager:TransferKey() Leaving")
 
+	keyAttributes, err := rm.store.Retrieve(keyId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now().UTC()
+	if err := checkUsagePolicy(keyAttributes, now); err != nil {
+		return nil, 0, err
+	}
+
+	transferredKey := keyAttributes
+	if version != 0 && version != keyAttributes.PrimaryVersion() {
+		priorVersion := keyAttributes.FindPriorVersion(version)
+		if priorVersion == nil {
+			return nil, 0, ErrKeyVersionNotFound
+		}
+		if !priorVersion.IsTransferable(now) {
+			return nil, 0, ErrKeyVersionNotTransferable
+		}
+		transferredKey = keyAttributes.WithVersion(priorVersion)
+	}
+
+	secret, err := rm.manager.TransferKey(transferredKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	wrappedSecret, err := wrap(secret)
+	if err != nil {
+		return nil, 0, &KeyWrapError{Err: err}
+	}
+
+	// the material is only released once the transfer is counted
+	counted, err := rm.store.CountTransfer(keyId, keyAttributes.MaxTransferCount, now)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !counted {
+		return nil, 0, ErrKeyTransferLimitReached
+	}
+	return wrappedSecret, transferredKey.PrimaryVersion(), nil
+}
+
+// UpdateKeyUsagePolicy replaces the usage policy of the key, the transfers already counted are kept. The update fails
+// with RecordConflict when the key is updated concurrently.
+func (rm *RemoteManager) UpdateKeyUsagePolicy(keyId uuid.UUID, policy kbs.KeyUsagePolicy) (*kbs.KeyResponse, error) {
+	defaultLog.Trace("keymanager/remote_key_manager:UpdateKeyUsagePolicy() Entering")
+	defer defaultLog.Trace("keymanager/remote_key_manager:UpdateKeyUsagePolicy() Leaving")
+
 	keyAttributes, err := rm.store.Retrieve(keyId)
 	if err != nil {
 		return nil, err
 	}
 
-	return rm.manager.TransferKey(keyAttributes)
+	keyAttributes.KeyUsagePolicy = policy
+	storedKey, err := rm.store.Update(keyAttributes)
+	if err != nil {
+		return nil, err
+	}
+
+	return storedKey.ToKeyResponse(), nil
+}
+
+// RotateKey replaces the material of the key with a new version, the replaced version stays transferable for the
+// given duration. The material of the prior versions that are no longer transferable is deleted. The rotation fails
+// with RecordConflict when the key is updated concurrently, the new material is deleted then.
+func (rm *RemoteManager) RotateKey(keyId uuid.UUID, priorVersionValidity time.Duration) (*kbs.KeyResponse, error) {
+	defaultLog.Trace("keymanager/remote_key_manager:RotateKey() Entering")
+	defer defaultLog.Trace("keymanager/remote_key_manager:RotateKey() Leaving")
+
+	keyAttributes, err := rm.store.Retrieve(keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	rotatedKey, err := rm.manager.RotateKey(keyAttributes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	rotatedKey.Version = keyAttributes.PrimaryVersion() + 1
+	rotatedKey.RotatedAt = &now
+	rotatedKey.PriorVersions = append([]models.KeyVersion{}, keyAttributes.PriorVersions...)
+	rotatedKey.PriorVersions = append(rotatedKey.PriorVersions, keyAttributes.RetireVersion(now, priorVersionValidity))
+
+	var expiredVersions []models.KeyVersion
+	for i, version := range rotatedKey.PriorVersions {
+		if version.HasMaterial() && !version.IsTransferable(now) {
+			expiredVersions = append(expiredVersions, version)
+			rotatedKey.PriorVersions[i].ClearMaterial()
+		}
+	}
+
+	storedKey, err := rm.store.Update(rotatedKey)
+	if err != nil {
+		// the new material is not referenced by any key
+		if deleteErr := rm.manager.DeleteKey(rotatedKey); deleteErr != nil {
+			defaultLog.WithError(deleteErr).Warnf("keymanager/remote_key_manager:RotateKey() Failed to delete material of key %s version %d", keyId, rotatedKey.Version)
+		}
+		return nil, err
+	}
+
+	// the material of the expired versions is deleted once it is no longer referenced
+	for i := range expiredVersions {
+		if err := rm.deleteVersionMaterial(keyAttributes, &expiredVersions[i]); err != nil {
+			defaultLog.WithError(err).Warnf("keymanager/remote_key_manager:RotateKey() Failed to delete material of key %s version %d", keyId, expiredVersions[i].Version)
+		}
+	}
+
+	return storedKey.ToKeyResponse(), nil
+}
+
+// ListKeyVersions returns the primary version of the key followed by its prior versions
+func (rm *RemoteManager) ListKeyVersions(keyId uuid.UUID) ([]kbs.KeyVersionResponse, error) {
+	defaultLog.Trace("keymanager/remote_key_manager:ListKeyVersions() Entering")
+	defer defaultLog.Trace("keymanager/remote_key_manager:ListKeyVersions() Leaving")
+
+	keyAttributes, err := rm.store.Retrieve(keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	return keyAttributes.ToKeyVersionResponses(time.Now()), nil
+}
+
+// checkUsagePolicy returns the reason why the usage policy of the key does not allow a transfer at the given time, nil
+// if it does
+func checkUsagePolicy(keyAttributes *models.KeyAttributes, at time.Time) error {
+	policy := keyAttributes.KeyUsagePolicy
+	if policy.Disabled {
+		return ErrKeyDisabled
+	}
+	if policy.NotBefore != nil && at.Before(*policy.NotBefore) {
+		return ErrKeyNotYetValid
+	}
+	if policy.NotAfter != nil && at.After(*policy.NotAfter) {
+		return ErrKeyExpired
+	}
+	if policy.MaxTransferCount > 0 && keyAttributes.TransferCount >= policy.MaxTransferCount {
+		return ErrKeyTransferLimitReached
+	}
+	return nil
+}
+
+// deleteVersionMaterial deletes the material of a prior version of the key from the key manager
+func (rm *RemoteManager) deleteVersionMaterial(keyAttributes *models.KeyAttributes, version *models.KeyVersion) error {
+	if !version.HasMaterial() {
+		return nil
+	}
+	return rm.manager.DeleteKey(keyAttributes.WithVersion(version))
 }
 
 func (rm *RemoteManager) getTransferLink(keyId uuid.UUID) string {
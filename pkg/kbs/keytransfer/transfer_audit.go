@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package keytransfer
+
+import (
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
+	"github.com/pkg/errors"
+)
+
+// TransferAudit is the record of an attempt to transfer a key. It is completed along the verifications of the transfer
+// and stored once, when the decision is taken.
+type TransferAudit struct {
+	store  domain.KeyTransferRecordStore
+	record kbs.KeyTransferRecord
+	stored bool
+}
+
+// NewTransferAudit starts the record of an attempt to transfer the key, the record is not stored when the store is nil
+func NewTransferAudit(store domain.KeyTransferRecordStore, keyId uuid.UUID, attestationType string) *TransferAudit {
+	return &TransferAudit{
+		store: store,
+		record: kbs.KeyTransferRecord{
+			KeyID:           keyId,
+			AttestationType: attestationType,
+		},
+	}
+}
+
+func (ta *TransferAudit) SetRequester(requester string) {
+	ta.record.Requester = requester
+}
+
+func (ta *TransferAudit) SetAttestationType(attestationType string) {
+	ta.record.AttestationType = attestationType
+}
+
+func (ta *TransferAudit) SetTransferPolicy(id uuid.UUID) {
+	ta.record.TransferPolicyID = id
+}
+
+// Allow stores the record of a transfer releasing the given version of the key. The key must not be released when the
+// record cannot be stored.
+func (ta *TransferAudit) Allow(version int) error {
+	ta.record.Version = version
+	return ta.save(constants.KeyTransferAllowed, "")
+}
+
+// Deny stores the record of a refused transfer with the reason of the refusal
+func (ta *TransferAudit) Deny(reason string) {
+	if err := ta.save(constants.KeyTransferDenied, reason); err != nil {
+		defaultLog.WithError(err).Errorf("keytransfer/transfer_audit:Deny() Failed to record the refused transfer of key %s", ta.record.KeyID)
+	}
+}
+
+// Challenge stores the record of a transfer answered with an attestation challenge
+func (ta *TransferAudit) Challenge(reason string) {
+	if err := ta.save(constants.KeyTransferChallenged, reason); err != nil {
+		defaultLog.WithError(err).Errorf("keytransfer/transfer_audit:Challenge() Failed to record the challenged transfer of key %s", ta.record.KeyID)
+	}
+}
+
+// save stores the record with the decision, only the first decision taken on the attempt is stored
+func (ta *TransferAudit) save(decision, reason string) error {
+	if ta.stored || ta.store == nil {
+		return nil
+	}
+	ta.stored = true
+
+	ta.record.Decision = decision
+	ta.record.Reason = reason
+	if _, err := ta.store.Create(&ta.record); err != nil {
+		return errors.Wrap(err, "keytransfer/transfer_audit:save() Failed to store key transfer record")
+	}
+	return nil
+}
@@ -8,12 +8,11 @@ import (
 	"crypto/x509"
 	"encoding/asn1"
 	"encoding/base64"
-	"io/ioutil"
-	"path/filepath"
 	"regexp"
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/keymanager"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
@@ -25,13 +24,23 @@ import (
 
 var defaultLog = log.GetDefaultLogger()
 
-//IsTrustedByHvs verifies if the client can be trusted for transfer
-func IsTrustedByHvs(saml string, samlReport *samlLib.Saml, keyId uuid.UUID, config domain.KeyControllerConfig, remoteManager *keymanager.RemoteManager) (bool, *x509.Certificate) {
+//IsTrustedByHvs verifies if the client can be trusted for transfer, the audit gets the host hardware UUID of a report
+//with a valid signature as requester and the reason of the refusal when the client is not trusted
+func IsTrustedByHvs(saml string, samlReport *samlLib.Saml, keyId uuid.UUID, config domain.KeyControllerConfig, remoteManager *keymanager.RemoteManager, audit *TransferAudit) (bool, *x509.Certificate) {
 	defaultLog.Trace("keytransfer/transfer_with_saml:IsTrustedByHvs() Entering")
 	defer defaultLog.Trace("keytransfer/transfer_with_saml:IsTrustedByHvs() Leaving")
 
+	notTrusted := func(reason string) (bool, *x509.Certificate) {
+		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() " + reason)
+		audit.Deny(reason)
+		return false, nil
+	}
+
 	usagePolicyTags := make(map[string]string, 0)
 	key, _ := remoteManager.RetrieveKey(keyId)
+	if key != nil {
+		audit.SetTransferPolicy(key.TransferPolicyID)
+	}
 	if key != nil && key.Usage != "" {
 		usagePolicies := strings.Split(key.Usage, ",")
 
@@ -45,10 +54,17 @@ func IsTrustedByHvs(saml string, samlReport *samlLib.Saml, keyId uuid.UUID, conf
 	//Remove Indentation from Request body
 	pattern := regexp.MustCompile(`( *)<`)
 	saml = pattern.ReplaceAllString(saml, "<")
-	verified := verifySamlSignature(saml, config.SamlCertsDir, config.TrustedCaCertsDir)
+	verified := verifySamlSignature(saml, config.SamlCertStore, config.TrustedCaCertsDir)
 	if !verified {
-		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Invalid signature on trust report")
-		return false, nil
+		return notTrusted("Invalid signature on trust report")
+	}
+
+	// the hardware UUID is only recorded as requester once the report is known to be issued by HVS
+	for _, as := range samlReport.Attribute {
+		if as.Name == constants.SamlHardwareUUIDAttribute {
+			audit.SetRequester(as.AttributeValue)
+			break
+		}
 	}
 
 	var err error
@@ -60,25 +76,21 @@ func IsTrustedByHvs(saml string, samlReport *samlLib.Saml, keyId uuid.UUID, conf
 		switch as.Name {
 		case "TRUST_OVERALL":
 			if as.AttributeValue != "true" {
-				defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Host is not trusted")
-				return false, nil
+				return notTrusted("Host is not trusted")
 			}
 		case "tpmVersion":
 			if as.AttributeValue != "2.0" {
-				defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() TPM version not supported")
-				return false, nil
+				return notTrusted("TPM version not supported")
 			}
 		case "Binding_Key_Certificate":
 			bindingKeyCertBytes, err = base64.StdEncoding.DecodeString(as.AttributeValue)
 			if err != nil {
-				defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Unable to decode Binding Key Certificate")
-				return false, nil
+				return notTrusted("Unable to decode Binding Key Certificate")
 			}
 		case "AIK_Certificate":
 			aikCertBytes, err = base64.StdEncoding.DecodeString(as.AttributeValue)
 			if err != nil {
-				defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Unable to decode AIK certificate")
-				return false, nil
+				return notTrusted("Unable to decode AIK certificate")
 			}
 		case "TRUST_ASSET_TAG":
 			// check if asset tag is deployed on the host
@@ -94,56 +106,47 @@ func IsTrustedByHvs(saml string, samlReport *samlLib.Saml, keyId uuid.UUID, conf
 	}
 
 	if len(aikCertBytes) == 0 {
-		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Assertion does not include AIK Certificate")
-		return false, nil
+		return notTrusted("Assertion does not include AIK Certificate")
 	}
 
 	aikCert, err := x509.ParseCertificate(aikCertBytes)
 	if err != nil {
-		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Unable to parse AIK certificate")
-		return false, nil
+		return notTrusted("Unable to parse AIK certificate")
 	}
 
-	verified = verifySignature(aikCert, config.TpmIdentityCertsDir)
+	verified = verifySignature(aikCert, config.TpmIdentityCertStore)
 	if !verified {
-		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() AIK certificate not verified by any trusted authority")
-		return false, nil
+		return notTrusted("AIK certificate not verified by any trusted authority")
 	}
 
 	if len(bindingKeyCertBytes) == 0 {
-		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() No binding key certificate in trust report")
-		return false, nil
+		return notTrusted("No binding key certificate in trust report")
 	}
 
 	bindingKeyCert, err := x509.ParseCertificate(bindingKeyCertBytes)
 	if err != nil {
-		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Unable to parse Binding Key certificate")
-		return false, nil
+		return notTrusted("Unable to parse Binding Key certificate")
 	}
 
-	verified = verifySignature(bindingKeyCert, config.TpmIdentityCertsDir)
+	verified = verifySignature(bindingKeyCert, config.TpmIdentityCertStore)
 	if !verified {
-		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Binding key certificate not verified by any trusted authority")
-		return false, nil
+		return notTrusted("Binding key certificate not verified by any trusted authority")
 	}
 
 	verified = verifyTpmBindingKeyCertificate(bindingKeyCert, aikCert)
 	if !verified {
-		defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Binding key certificate has invalid attributes or cannot be verified with the AIK")
-		return false, nil
+		return notTrusted("Binding key certificate has invalid attributes or cannot be verified with the AIK")
 	}
 
 	if len(usagePolicyTags) != 0 {
 		if !assetTagDeployed {
-			defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Asset tags are not deployed on the host, but a usage policy is defined for the requested key")
-			return false, nil
+			return notTrusted("Asset tags are not deployed on the host, but a usage policy is defined for the requested key")
 		}
 
 		// check if all the keys in tagsDeployedOnHost exist in usagePolicyTags and their values match
 		for key, value := range usagePolicyTags {
 			if v, ok := tagsDeployedOnHost[key]; !ok || strings.ToLower(v) != strings.ToLower(value) {
-				defaultLog.Error("keytransfer/transfer_with_saml:IsTrustedByHvs() Usage policy requirements of the key does not match with tags deployed on the host")
-				return false, nil
+				return notTrusted("Usage policy requirements of the key does not match with tags deployed on the host")
 			}
 		}
 	}
@@ -151,20 +154,20 @@ func IsTrustedByHvs(saml string, samlReport *samlLib.Saml, keyId uuid.UUID, conf
 	return true, bindingKeyCert
 }
 
-//verifySamlSignature verifies signature of the saml report
-func verifySamlSignature(saml, samlCertsDir, trustedCaCertsDir string) bool {
+//verifySamlSignature verifies signature of the saml report with the SAML certificates of the store
+func verifySamlSignature(saml string, samlCertStore domain.CertificateStore, trustedCaCertsDir string) bool {
 	defaultLog.Trace("keytransfer/transfer_with_saml:VerifySamlSignature() Entering")
 	defer defaultLog.Trace("keytransfer/transfer_with_saml:VerifySamlSignature() Leaving")
 
-	samlCertFiles, err := ioutil.ReadDir(samlCertsDir)
+	samlCerts, err := samlCertStore.Search(nil)
 	if err != nil {
-		defaultLog.WithError(err).Error("keytransfer/transfer_with_saml:VerifySamlSignature() Error while reading the directory: ")
+		defaultLog.WithError(err).Error("keytransfer/transfer_with_saml:VerifySamlSignature() Error while retrieving the SAML certificates")
 		return false
 	}
 
 	var verified bool
-	for _, samlCertFile := range samlCertFiles {
-		if isValidSaml := samlLib.VerifySamlSignature(saml, filepath.Join(samlCertsDir, samlCertFile.Name()), trustedCaCertsDir); isValidSaml {
+	for _, samlCert := range samlCerts {
+		if isValidSaml := samlLib.VerifySamlSignatureWithCertPem(saml, samlCert.Certificate, trustedCaCertsDir); isValidSaml {
 			verified = true
 		}
 	}
@@ -172,17 +175,27 @@ func verifySamlSignature(saml, samlCertsDir, trustedCaCertsDir string) bool {
 	return verified
 }
 
-//verifySignature verifies the signature of certificate
-func verifySignature(cert *x509.Certificate, signingCertsDir string) bool {
+//verifySignature verifies the signature of certificate with the signing certificates of the store
+func verifySignature(cert *x509.Certificate, signingCertStore domain.CertificateStore) bool {
 	defaultLog.Trace("keytransfer/transfer_with_saml:VerifySignature() Entering")
 	defer defaultLog.Trace("keytransfer/transfer_with_saml:VerifySignature() Leaving")
 
-	signingCerts, err := crypt.GetCertsFromDir(signingCertsDir)
+	storedCerts, err := signingCertStore.Search(nil)
 	if err != nil {
-		defaultLog.WithError(err).Errorf("keytransfer/transfer_with_saml:VerifySignature() Error retrieving signing certificates from %s", signingCertsDir)
+		defaultLog.WithError(err).Error("keytransfer/transfer_with_saml:VerifySignature() Error retrieving signing certificates")
 		return false
 	}
 
+	var signingCerts []x509.Certificate
+	for _, storedCert := range storedCerts {
+		certs, err := crypt.GetSubjectCertsMapFromPem(storedCert.Certificate)
+		if err != nil {
+			defaultLog.WithError(err).Warnf("keytransfer/transfer_with_saml:VerifySignature() Error reading signing certificate %s", storedCert.ID)
+			continue
+		}
+		signingCerts = append(signingCerts, certs...)
+	}
+
 	verifyRootCAOpts := x509.VerifyOptions{
 		Roots: crypt.GetCertPool(signingCerts),
 	}
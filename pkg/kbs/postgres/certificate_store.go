@@ -0,0 +1,166 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// CertificateStore stores the certificates of one type, the certificates of all types share the same table
+type CertificateStore struct {
+	Store    *DataStore
+	CertType string
+}
+
+func NewCertificateStore(store *DataStore, certType string) *CertificateStore {
+	return &CertificateStore{store, certType}
+}
+
+func (cs *CertificateStore) Create(cert *kbs.Certificate) (*kbs.Certificate, error) {
+	defaultLog.Trace("postgres/certificate_store:Create() Entering")
+	defer defaultLog.Trace("postgres/certificate_store:Create() Leaving")
+
+	newUuid, err := uuid.NewRandom()
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/certificate_store:Create() failed to create new UUID")
+	}
+	cert.ID = newUuid
+
+	return cs.Import(cert)
+}
+
+// Import stores the certificate with its ID, the certificates are imported when they are migrated from another store
+func (cs *CertificateStore) Import(cert *kbs.Certificate) (*kbs.Certificate, error) {
+	defaultLog.Trace("postgres/certificate_store:Import() Entering")
+	defer defaultLog.Trace("postgres/certificate_store:Import() Leaving")
+
+	x509Cert, err := crypt.GetCertFromPem(cert.Certificate)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/certificate_store:Import() Error in decoding the certificate")
+	}
+
+	fingerprint := sha512.Sum384(x509Cert.Raw)
+	dbCertificate := certificate{
+		ID:          cert.ID,
+		CertType:    cs.CertType,
+		Certificate: cert.Certificate,
+		Subject:     x509Cert.Subject.CommonName,
+		Issuer:      x509Cert.Issuer.CommonName,
+		NotBefore:   x509Cert.NotBefore,
+		NotAfter:    x509Cert.NotAfter,
+		Revoked:     cert.Revoked,
+		Digest:      hex.EncodeToString(fingerprint[:]),
+	}
+	if err := cs.Store.Db.Create(&dbCertificate).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/certificate_store:Import() Failed to create certificate")
+	}
+
+	return toCertificate(&dbCertificate), nil
+}
+
+func (cs *CertificateStore) Retrieve(id uuid.UUID) (*kbs.Certificate, error) {
+	defaultLog.Trace("postgres/certificate_store:Retrieve() Entering")
+	defer defaultLog.Trace("postgres/certificate_store:Retrieve() Leaving")
+
+	dbCertificate := certificate{}
+	if err := cs.Store.Db.Where("id = ? AND cert_type = ?", id, cs.CertType).First(&dbCertificate).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, errors.New(commErr.RecordNotFound)
+		}
+		return nil, errors.Wrap(err, "postgres/certificate_store:Retrieve() Failed to retrieve certificate")
+	}
+
+	return toCertificate(&dbCertificate), nil
+}
+
+func (cs *CertificateStore) Delete(id uuid.UUID) error {
+	defaultLog.Trace("postgres/certificate_store:Delete() Entering")
+	defer defaultLog.Trace("postgres/certificate_store:Delete() Leaving")
+
+	db := cs.Store.Db.Where("id = ? AND cert_type = ?", id, cs.CertType).Delete(&certificate{})
+	if db.Error != nil {
+		return errors.Wrap(db.Error, "postgres/certificate_store:Delete() Failed to delete certificate")
+	}
+	if db.RowsAffected != 1 {
+		return errors.New(commErr.RecordNotFound)
+	}
+	return nil
+}
+
+func (cs *CertificateStore) Search(criteria *models.CertificateFilterCriteria) ([]kbs.Certificate, error) {
+	defaultLog.Trace("postgres/certificate_store:Search() Entering")
+	defer defaultLog.Trace("postgres/certificate_store:Search() Leaving")
+
+	tx := buildCertificateSearchQuery(cs.Store.Db, cs.CertType, criteria)
+
+	var dbCertificates []certificate
+	if err := tx.Find(&dbCertificates).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/certificate_store:Search() Failed to retrieve records from db")
+	}
+
+	certificates := []kbs.Certificate{}
+	for i := range dbCertificates {
+		certificates = append(certificates, *toCertificate(&dbCertificates[i]))
+	}
+	return certificates, nil
+}
+
+func buildCertificateSearchQuery(tx *gorm.DB, certType string, criteria *models.CertificateFilterCriteria) *gorm.DB {
+	defaultLog.Trace("postgres/certificate_store:buildCertificateSearchQuery() Entering")
+	defer defaultLog.Trace("postgres/certificate_store:buildCertificateSearchQuery() Leaving")
+
+	tx = tx.Model(&certificate{}).Where("cert_type = ?", certType)
+	if criteria == nil {
+		return tx
+	}
+
+	if criteria.SubjectEqualTo != "" {
+		tx = tx.Where("subject = ?", criteria.SubjectEqualTo)
+	}
+	if criteria.SubjectContains != "" {
+		tx = tx.Where("subject like ?", "%"+criteria.SubjectContains+"%")
+	}
+	if criteria.IssuerEqualTo != "" {
+		tx = tx.Where("lower(issuer) = ?", strings.ToLower(criteria.IssuerEqualTo))
+	}
+	if criteria.IssuerContains != "" {
+		tx = tx.Where("lower(issuer) like ?", "%"+strings.ToLower(criteria.IssuerContains)+"%")
+	}
+	if !criteria.ValidBefore.IsZero() {
+		tx = tx.Where("not_after < ?", criteria.ValidBefore)
+	}
+	if !criteria.ValidAfter.IsZero() {
+		tx = tx.Where("not_before > ?", criteria.ValidAfter)
+	}
+	if !criteria.ValidOn.IsZero() {
+		tx = tx.Where("not_before < ? AND not_after > ?", criteria.ValidOn, criteria.ValidOn)
+	}
+	return tx
+}
+
+func toCertificate(dbCertificate *certificate) *kbs.Certificate {
+	notBefore := dbCertificate.NotBefore
+	notAfter := dbCertificate.NotAfter
+	return &kbs.Certificate{
+		ID:          dbCertificate.ID,
+		Certificate: dbCertificate.Certificate,
+		Subject:     dbCertificate.Subject,
+		Issuer:      dbCertificate.Issuer,
+		NotBefore:   &notBefore,
+		NotAfter:    &notAfter,
+		Revoked:     dbCertificate.Revoked,
+		Digest:      dbCertificate.Digest,
+	}
+}
@@ -0,0 +1,285 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/kek"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// KeyStore stores the keys with their secrets encrypted in envelopes wrapped by the current KEK
+type KeyStore struct {
+	Store  *DataStore
+	sealer *kek.Sealer
+}
+
+func NewKeyStore(store *DataStore, kekProvider domain.KeyEncryptionKeyProvider, kekId string) *KeyStore {
+	return &KeyStore{
+		Store:  store,
+		sealer: kek.NewSealer(kekProvider, kekId),
+	}
+}
+
+func (ks *KeyStore) Create(k *models.KeyAttributes) (*models.KeyAttributes, error) {
+	defaultLog.Trace("postgres/key_store:Create() Entering")
+	defer defaultLog.Trace("postgres/key_store:Create() Leaving")
+
+	dbKey, err := ks.seal(k)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/key_store:Create() Failed to encrypt key attributes")
+	}
+	if err := ks.Store.Db.Create(dbKey).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/key_store:Create() Failed to create key")
+	}
+	return k, nil
+}
+
+func (ks *KeyStore) Retrieve(id uuid.UUID) (*models.KeyAttributes, error) {
+	defaultLog.Trace("postgres/key_store:Retrieve() Entering")
+	defer defaultLog.Trace("postgres/key_store:Retrieve() Leaving")
+
+	dbKey := key{}
+	if err := ks.Store.Db.Where("id = ?", id).First(&dbKey).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, errors.New(commErr.RecordNotFound)
+		}
+		return nil, errors.Wrap(err, "postgres/key_store:Retrieve() Failed to retrieve key")
+	}
+
+	k, err := ks.open(&dbKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "postgres/key_store:Retrieve() Failed to decrypt key attributes : %s", id.String())
+	}
+	return k, nil
+}
+
+// Update replaces the attributes of an existing key, the secrets are encrypted with a new data encryption key. The key
+// is only replaced while it is still at the revision it was read at, the update of a key updated by another request or
+// KBS in the meantime fails with RecordConflict. The transfers counted by CountTransfer are kept.
+func (ks *KeyStore) Update(k *models.KeyAttributes) (*models.KeyAttributes, error) {
+	defaultLog.Trace("postgres/key_store:Update() Entering")
+	defer defaultLog.Trace("postgres/key_store:Update() Leaving")
+
+	dbKey, err := ks.seal(k)
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/key_store:Update() Failed to encrypt key attributes")
+	}
+
+	db := ks.Store.Db.Model(&key{}).Where("id = ? AND revision = ?", k.ID, k.Revision).Updates(map[string]interface{}{
+		"algorithm":          dbKey.Algorithm,
+		"key_length":         dbKey.KeyLength,
+		"curve_type":         dbKey.CurveType,
+		"transfer_policy_id": dbKey.TransferPolicyId,
+		"kek_id":             dbKey.KekID,
+		"created_at":         dbKey.CreatedAt,
+		"attributes":         dbKey.Attributes,
+		"envelope":           dbKey.Envelope,
+		"revision":           gorm.Expr("revision + 1"),
+	})
+	if db.Error != nil {
+		return nil, errors.Wrap(db.Error, "postgres/key_store:Update() Failed to update key")
+	}
+	if db.RowsAffected != 1 {
+		count := 0
+		if err := ks.Store.Db.Model(&key{}).Where("id = ?", k.ID).Count(&count).Error; err != nil {
+			return nil, errors.Wrap(err, "postgres/key_store:Update() Failed to retrieve key")
+		}
+		if count == 0 {
+			return nil, errors.New(commErr.RecordNotFound)
+		}
+		return nil, errors.New(commErr.RecordConflict)
+	}
+
+	updatedKey := *k
+	updatedKey.Revision++
+	return &updatedKey, nil
+}
+
+// CountTransfer counts a transfer of the key unless the key was already transferred maxTransferCount times. The count
+// is checked and incremented by a single statement, the limit holds for the transfers by concurrent requests and KBSs.
+func (ks *KeyStore) CountTransfer(id uuid.UUID, maxTransferCount int, transferredAt time.Time) (bool, error) {
+	defaultLog.Trace("postgres/key_store:CountTransfer() Entering")
+	defer defaultLog.Trace("postgres/key_store:CountTransfer() Leaving")
+
+	db := ks.Store.Db.Model(&key{}).Where("id = ? AND (? = 0 OR transfer_count < ?)", id, maxTransferCount, maxTransferCount).
+		Updates(map[string]interface{}{
+			"transfer_count":      gorm.Expr("transfer_count + 1"),
+			"last_transferred_at": transferredAt,
+		})
+	if db.Error != nil {
+		return false, errors.Wrap(db.Error, "postgres/key_store:CountTransfer() Failed to count key transfer")
+	}
+	if db.RowsAffected == 1 {
+		return true, nil
+	}
+
+	count := 0
+	if err := ks.Store.Db.Model(&key{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return false, errors.Wrap(err, "postgres/key_store:CountTransfer() Failed to retrieve key")
+	}
+	if count == 0 {
+		return false, errors.New(commErr.RecordNotFound)
+	}
+	return false, nil
+}
+
+func (ks *KeyStore) Delete(id uuid.UUID) error {
+	defaultLog.Trace("postgres/key_store:Delete() Entering")
+	defer defaultLog.Trace("postgres/key_store:Delete() Leaving")
+
+	db := ks.Store.Db.Where("id = ?", id).Delete(&key{})
+	if db.Error != nil {
+		return errors.Wrap(db.Error, "postgres/key_store:Delete() Failed to delete key")
+	}
+	if db.RowsAffected != 1 {
+		return errors.New(commErr.RecordNotFound)
+	}
+	return nil
+}
+
+func (ks *KeyStore) Search(criteria *models.KeyFilterCriteria) ([]models.KeyAttributes, error) {
+	defaultLog.Trace("postgres/key_store:Search() Entering")
+	defer defaultLog.Trace("postgres/key_store:Search() Leaving")
+
+	tx := buildKeySearchQuery(ks.Store.Db, criteria)
+
+	var dbKeys []key
+	if err := tx.Order("created_at").Find(&dbKeys).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/key_store:Search() Failed to retrieve records from db")
+	}
+
+	keys := []models.KeyAttributes{}
+	for i := range dbKeys {
+		k, err := ks.open(&dbKeys[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "postgres/key_store:Search() Failed to decrypt key attributes : %s", dbKeys[i].ID.String())
+		}
+		keys = append(keys, *k)
+	}
+	return keys, nil
+}
+
+// Rewrap rewraps the data encryption keys wrapped by another KEK with the current KEK. It returns the number of keys
+// updated. A key is only updated while it is still wrapped by the KEK it was read with, the keys updated or deleted by
+// another KBS in the meantime are skipped.
+func (ks *KeyStore) Rewrap() (int, error) {
+	defaultLog.Trace("postgres/key_store:Rewrap() Entering")
+	defer defaultLog.Trace("postgres/key_store:Rewrap() Leaving")
+
+	ids, err := ks.Stale()
+	if err != nil {
+		return 0, err
+	}
+
+	rewrapped := 0
+	for _, id := range ids {
+		dbKey := key{}
+		if err := ks.Store.Db.Where("id = ?", id).First(&dbKey).Error; err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				continue
+			}
+			return rewrapped, errors.Wrapf(err, "postgres/key_store:Rewrap() Failed to retrieve key : %s", id.String())
+		}
+
+		envelope := kek.Envelope(dbKey.Envelope)
+		rewrappedEnvelope, err := ks.sealer.Rewrap(&envelope, []byte(id.String()))
+		if err != nil {
+			return rewrapped, errors.Wrapf(err, "postgres/key_store:Rewrap() Failed to rewrap key : %s", id.String())
+		}
+
+		db := ks.Store.Db.Model(&key{}).Where("id = ? AND kek_id = ?", id, dbKey.KekID).Updates(map[string]interface{}{
+			"kek_id":   rewrappedEnvelope.KekID,
+			"envelope": PGEnvelope(*rewrappedEnvelope),
+		})
+		if db.Error != nil {
+			return rewrapped, errors.Wrapf(db.Error, "postgres/key_store:Rewrap() Failed to store key : %s", id.String())
+		}
+		rewrapped += int(db.RowsAffected)
+	}
+
+	return rewrapped, nil
+}
+
+// Stale returns the IDs of the keys wrapped by another KEK than the current one
+func (ks *KeyStore) Stale() ([]uuid.UUID, error) {
+	defaultLog.Trace("postgres/key_store:Stale() Entering")
+	defer defaultLog.Trace("postgres/key_store:Stale() Leaving")
+
+	var ids []uuid.UUID
+	if err := ks.Store.Db.Model(&key{}).Where("kek_id <> ?", ks.sealer.KekID()).Pluck("id", &ids).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/key_store:Stale() Failed to retrieve records from db")
+	}
+	return ids, nil
+}
+
+// seal returns the row of the key with its secrets encrypted with a new data encryption key wrapped by the current KEK
+func (ks *KeyStore) seal(k *models.KeyAttributes) (*key, error) {
+	sealedKey, envelope, err := ks.sealer.SealKey(k)
+	if err != nil {
+		return nil, err
+	}
+	// the transfers are only counted in their columns
+	sealedKey.TransferCount = 0
+	sealedKey.LastTransferredAt = nil
+	return &key{
+		ID:                k.ID,
+		Algorithm:         k.Algorithm,
+		KeyLength:         k.KeyLength,
+		CurveType:         k.CurveType,
+		TransferPolicyId:  k.TransferPolicyId,
+		KekID:             envelope.KekID,
+		CreatedAt:         k.CreatedAt,
+		Attributes:        PGKeyAttributes(*sealedKey),
+		Envelope:          PGEnvelope(*envelope),
+		Revision:          k.Revision,
+		TransferCount:     k.TransferCount,
+		LastTransferredAt: k.LastTransferredAt,
+	}, nil
+}
+
+// open returns the attributes of the key with the secrets decrypted from the envelope
+func (ks *KeyStore) open(dbKey *key) (*models.KeyAttributes, error) {
+	sealedKey := models.KeyAttributes(dbKey.Attributes)
+	envelope := kek.Envelope(dbKey.Envelope)
+	k, err := ks.sealer.OpenKey(&sealedKey, &envelope)
+	if err != nil {
+		return nil, err
+	}
+	k.Revision = dbKey.Revision
+	k.TransferCount = dbKey.TransferCount
+	k.LastTransferredAt = dbKey.LastTransferredAt
+	return k, nil
+}
+
+func buildKeySearchQuery(tx *gorm.DB, criteria *models.KeyFilterCriteria) *gorm.DB {
+	defaultLog.Trace("postgres/key_store:buildKeySearchQuery() Entering")
+	defer defaultLog.Trace("postgres/key_store:buildKeySearchQuery() Leaving")
+
+	tx = tx.Model(&key{})
+	if criteria == nil {
+		return tx
+	}
+
+	if criteria.Algorithm != "" {
+		tx = tx.Where("algorithm = ?", criteria.Algorithm)
+	}
+	if criteria.KeyLength != 0 {
+		tx = tx.Where("key_length = ?", criteria.KeyLength)
+	}
+	if criteria.CurveType != "" {
+		tx = tx.Where("curve_type = ?", criteria.CurveType)
+	}
+	if criteria.TransferPolicyId != uuid.Nil {
+		tx = tx.Where("transfer_policy_id = ?", criteria.TransferPolicyId)
+	}
+	return tx
+}
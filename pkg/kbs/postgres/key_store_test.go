@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"database/sql/driver"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/kek"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	updateKeyQuery     = regexp.QuoteMeta(`UPDATE "key" SET`) + `.*` + regexp.QuoteMeta(`"revision" = revision + 1`) + `.*` + regexp.QuoteMeta(`WHERE (id = $9 AND revision = $10)`)
+	countTransferQuery = regexp.QuoteMeta(`UPDATE "key" SET "last_transferred_at" = $1, "transfer_count" = transfer_count + 1 WHERE (id = $2 AND ($3 = 0 OR transfer_count < $4))`)
+	countKeyQuery      = regexp.QuoteMeta(`SELECT count(*) FROM "key" WHERE (id = $1)`)
+)
+
+func TestKeyStoreUpdateRevision(t *testing.T) {
+	a := assert.New(t)
+
+	keyStore, mock, cleanup := newTestKeyStore(t)
+	defer cleanup()
+	key := &models.KeyAttributes{ID: uuid.New(), Algorithm: "AES", KeyLength: 256, KeyData: "c2VjcmV0IGtleSBkYXRh", Revision: 3}
+	anyArgs := []driver.Value{sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+		sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()}
+
+	// the key is at the revision it was read at
+	mock.ExpectBegin()
+	mock.ExpectExec(updateKeyQuery).WithArgs(append(anyArgs, key.ID, 3)...).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	updated, err := keyStore.Update(key)
+	a.NoError(err)
+	a.Equal(4, updated.Revision)
+	a.Equal(3, key.Revision)
+
+	// the key was updated in the meantime
+	mock.ExpectBegin()
+	mock.ExpectExec(updateKeyQuery).WithArgs(append(anyArgs, key.ID, 3)...).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(countKeyQuery).WithArgs(key.ID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	_, err = keyStore.Update(key)
+	a.EqualError(err, commErr.RecordConflict)
+
+	// the key was deleted in the meantime
+	mock.ExpectBegin()
+	mock.ExpectExec(updateKeyQuery).WithArgs(append(anyArgs, key.ID, 3)...).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(countKeyQuery).WithArgs(key.ID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	_, err = keyStore.Update(key)
+	a.EqualError(err, commErr.RecordNotFound)
+
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestKeyStoreCountTransfer(t *testing.T) {
+	a := assert.New(t)
+
+	keyStore, mock, cleanup := newTestKeyStore(t)
+	defer cleanup()
+	id := uuid.New()
+	transferredAt := time.Now().UTC()
+
+	// the count is checked and incremented by the update
+	mock.ExpectBegin()
+	mock.ExpectExec(countTransferQuery).WithArgs(transferredAt, id, 2, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	counted, err := keyStore.CountTransfer(id, 2, transferredAt)
+	a.NoError(err)
+	a.True(counted)
+
+	// the key was already transferred the maximum number of times
+	mock.ExpectBegin()
+	mock.ExpectExec(countTransferQuery).WithArgs(transferredAt, id, 2, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(countKeyQuery).WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	counted, err = keyStore.CountTransfer(id, 2, transferredAt)
+	a.NoError(err)
+	a.False(counted)
+
+	// the key does not exist
+	mock.ExpectBegin()
+	mock.ExpectExec(countTransferQuery).WithArgs(transferredAt, id, 0, 0).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery(countKeyQuery).WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	_, err = keyStore.CountTransfer(id, 0, transferredAt)
+	a.EqualError(err, commErr.RecordNotFound)
+
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func newTestKeyStore(t *testing.T) (*KeyStore, sqlmock.Sqlmock, func()) {
+	dataStore, mock, err := NewSQLMockDataStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kekDir, err := ioutil.TempDir("", "kek")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kekProvider := kek.NewFileProvider(kekDir)
+	kekId, err := kekProvider.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewKeyStore(dataStore, kekProvider, kekId), mock, func() {
+		os.RemoveAll(kekDir)
+	}
+}
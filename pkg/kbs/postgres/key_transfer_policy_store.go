@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+type KeyTransferPolicyStore struct {
+	Store *DataStore
+}
+
+func NewKeyTransferPolicyStore(store *DataStore) *KeyTransferPolicyStore {
+	return &KeyTransferPolicyStore{store}
+}
+
+func (ktps *KeyTransferPolicyStore) Create(policy *kbs.KeyTransferPolicyAttributes) (*kbs.KeyTransferPolicyAttributes, error) {
+	defaultLog.Trace("postgres/key_transfer_policy_store:Create() Entering")
+	defer defaultLog.Trace("postgres/key_transfer_policy_store:Create() Leaving")
+
+	newUuid, err := uuid.NewRandom()
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/key_transfer_policy_store:Create() failed to create new UUID")
+	}
+	policy.ID = newUuid
+	policy.CreatedAt = time.Now().UTC()
+
+	return ktps.Import(policy)
+}
+
+// Import stores the key transfer policy with its ID and creation time, the policies are imported when they are
+// migrated from another store
+func (ktps *KeyTransferPolicyStore) Import(policy *kbs.KeyTransferPolicyAttributes) (*kbs.KeyTransferPolicyAttributes, error) {
+	defaultLog.Trace("postgres/key_transfer_policy_store:Import() Entering")
+	defer defaultLog.Trace("postgres/key_transfer_policy_store:Import() Leaving")
+
+	dbPolicy := keyTransferPolicy{
+		ID:         policy.ID,
+		CreatedAt:  policy.CreatedAt,
+		Attributes: PGKeyTransferPolicy(*policy),
+	}
+	if err := ktps.Store.Db.Create(&dbPolicy).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/key_transfer_policy_store:Import() Failed to create key transfer policy")
+	}
+	return policy, nil
+}
+
+func (ktps *KeyTransferPolicyStore) Retrieve(id uuid.UUID) (*kbs.KeyTransferPolicyAttributes, error) {
+	defaultLog.Trace("postgres/key_transfer_policy_store:Retrieve() Entering")
+	defer defaultLog.Trace("postgres/key_transfer_policy_store:Retrieve() Leaving")
+
+	dbPolicy := keyTransferPolicy{}
+	if err := ktps.Store.Db.Where("id = ?", id).First(&dbPolicy).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, errors.New(commErr.RecordNotFound)
+		}
+		return nil, errors.Wrap(err, "postgres/key_transfer_policy_store:Retrieve() Failed to retrieve key transfer policy")
+	}
+
+	policy := kbs.KeyTransferPolicyAttributes(dbPolicy.Attributes)
+	return &policy, nil
+}
+
+func (ktps *KeyTransferPolicyStore) Delete(id uuid.UUID) error {
+	defaultLog.Trace("postgres/key_transfer_policy_store:Delete() Entering")
+	defer defaultLog.Trace("postgres/key_transfer_policy_store:Delete() Leaving")
+
+	db := ktps.Store.Db.Where("id = ?", id).Delete(&keyTransferPolicy{})
+	if db.Error != nil {
+		return errors.Wrap(db.Error, "postgres/key_transfer_policy_store:Delete() Failed to delete key transfer policy")
+	}
+	if db.RowsAffected != 1 {
+		return errors.New(commErr.RecordNotFound)
+	}
+	return nil
+}
+
+func (ktps *KeyTransferPolicyStore) Search(criteria *models.KeyTransferPolicyFilterCriteria) ([]kbs.KeyTransferPolicyAttributes, error) {
+	defaultLog.Trace("postgres/key_transfer_policy_store:Search() Entering")
+	defer defaultLog.Trace("postgres/key_transfer_policy_store:Search() Leaving")
+
+	var dbPolicies []keyTransferPolicy
+	if err := ktps.Store.Db.Model(&keyTransferPolicy{}).Order("created_at").Find(&dbPolicies).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/key_transfer_policy_store:Search() Failed to retrieve records from db")
+	}
+
+	policies := []kbs.KeyTransferPolicyAttributes{}
+	for _, dbPolicy := range dbPolicies {
+		policies = append(policies, kbs.KeyTransferPolicyAttributes(dbPolicy.Attributes))
+	}
+	return policies, nil
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+type KeyTransferRecordStore struct {
+	Store *DataStore
+}
+
+func NewKeyTransferRecordStore(store *DataStore) *KeyTransferRecordStore {
+	return &KeyTransferRecordStore{store}
+}
+
+func (ktrs *KeyTransferRecordStore) Create(record *kbs.KeyTransferRecord) (*kbs.KeyTransferRecord, error) {
+	defaultLog.Trace("postgres/key_transfer_record_store:Create() Entering")
+	defer defaultLog.Trace("postgres/key_transfer_record_store:Create() Leaving")
+
+	newUuid, err := uuid.NewRandom()
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres/key_transfer_record_store:Create() failed to create new UUID")
+	}
+	record.ID = newUuid
+	record.CreatedAt = time.Now().UTC()
+
+	return ktrs.Import(record)
+}
+
+// Import stores the key transfer record with its ID and creation time, the records are imported when they are
+// migrated from another store
+func (ktrs *KeyTransferRecordStore) Import(record *kbs.KeyTransferRecord) (*kbs.KeyTransferRecord, error) {
+	defaultLog.Trace("postgres/key_transfer_record_store:Import() Entering")
+	defer defaultLog.Trace("postgres/key_transfer_record_store:Import() Leaving")
+
+	dbRecord := keyTransferRecord(*record)
+	if err := ktrs.Store.Db.Create(&dbRecord).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/key_transfer_record_store:Import() Failed to create key transfer record")
+	}
+	return record, nil
+}
+
+func (ktrs *KeyTransferRecordStore) Retrieve(id uuid.UUID) (*kbs.KeyTransferRecord, error) {
+	defaultLog.Trace("postgres/key_transfer_record_store:Retrieve() Entering")
+	defer defaultLog.Trace("postgres/key_transfer_record_store:Retrieve() Leaving")
+
+	dbRecord := keyTransferRecord{}
+	if err := ktrs.Store.Db.Where("id = ?", id).First(&dbRecord).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, errors.New(commErr.RecordNotFound)
+		}
+		return nil, errors.Wrap(err, "postgres/key_transfer_record_store:Retrieve() Failed to retrieve key transfer record")
+	}
+
+	record := kbs.KeyTransferRecord(dbRecord)
+	return &record, nil
+}
+
+// Search returns the key transfer records matching the criteria, the latest first
+func (ktrs *KeyTransferRecordStore) Search(criteria *models.KeyTransferRecordFilterCriteria) ([]kbs.KeyTransferRecord, error) {
+	defaultLog.Trace("postgres/key_transfer_record_store:Search() Entering")
+	defer defaultLog.Trace("postgres/key_transfer_record_store:Search() Leaving")
+
+	tx := ktrs.Store.Db.Model(&keyTransferRecord{})
+	if criteria != nil {
+		if criteria.KeyId != uuid.Nil {
+			tx = tx.Where("key_id = ?", criteria.KeyId)
+		}
+		if criteria.Requester != "" {
+			tx = tx.Where("requester = ?", criteria.Requester)
+		}
+		if criteria.AttestationType != "" {
+			tx = tx.Where("attestation_type = ?", criteria.AttestationType)
+		}
+		if criteria.Decision != "" {
+			tx = tx.Where("decision = ?", criteria.Decision)
+		}
+		if !criteria.FromDate.IsZero() {
+			tx = tx.Where("created_at >= ?", criteria.FromDate)
+		}
+		if !criteria.ToDate.IsZero() {
+			tx = tx.Where("created_at <= ?", criteria.ToDate)
+		}
+		if criteria.Limit > 0 {
+			tx = tx.Limit(criteria.Limit)
+		}
+	}
+
+	var dbRecords []keyTransferRecord
+	if err := tx.Order("created_at desc").Find(&dbRecords).Error; err != nil {
+		return nil, errors.Wrap(err, "postgres/key_transfer_record_store:Search() Failed to retrieve records from db")
+	}
+
+	records := []kbs.KeyTransferRecord{}
+	for _, dbRecord := range dbRecords {
+		records = append(records, kbs.KeyTransferRecord(dbRecord))
+	}
+	return records, nil
+}
@@ -0,0 +1,27 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jinzhu/gorm"
+)
+
+// NewSQLMockDataStore returns an instance of DataStore with a Mock Database connection injected into it
+func NewSQLMockDataStore() (*DataStore, sqlmock.Sqlmock, error) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		return nil, nil, err
+	}
+	gdb, err := gorm.Open("postgres", db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// enable single table setting
+	gdb.SingularTable(true)
+
+	return &DataStore{Db: gdb}, mock, nil
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package postgres
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/kek"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
+	"github.com/pkg/errors"
+)
+
+// Define all struct types here
+type (
+	PGKeyAttributes     models.KeyAttributes
+	PGEnvelope          kek.Envelope
+	PGKeyTransferPolicy kbs.KeyTransferPolicyAttributes
+
+	// key holds the attributes of a key without its secrets, which are encrypted in the envelope. The attributes the
+	// keys are searched by are also stored in columns. Revision is incremented by every update of the key, the transfers
+	// are counted in their own columns without updating the key.
+	key struct {
+		ID                uuid.UUID       `gorm:"primary_key;type:uuid"`
+		Algorithm         string          `gorm:"type:varchar(16);not null;index:idx_key_algorithm"`
+		KeyLength         int             `gorm:"column:key_length"`
+		CurveType         string          `gorm:"column:curve_type"`
+		TransferPolicyId  uuid.UUID       `gorm:"column:transfer_policy_id;type:uuid;index:idx_key_transfer_policy_id"`
+		KekID             string          `gorm:"column:kek_id;not null;index:idx_key_kek_id"`
+		CreatedAt         time.Time       `gorm:"column:created_at"`
+		Attributes        PGKeyAttributes `sql:"type:JSONB NOT NULL"`
+		Envelope          PGEnvelope      `sql:"type:JSONB NOT NULL"`
+		Revision          int             `gorm:"not null;default:0"`
+		TransferCount     int             `gorm:"column:transfer_count;not null;default:0"`
+		LastTransferredAt *time.Time      `gorm:"column:last_transferred_at"`
+	}
+
+	keyTransferPolicy struct {
+		ID         uuid.UUID           `gorm:"primary_key;type:uuid"`
+		CreatedAt  time.Time           `gorm:"column:created_at"`
+		Attributes PGKeyTransferPolicy `sql:"type:JSONB NOT NULL"`
+	}
+
+	// keyTransferRecord has the fields of kbs.KeyTransferRecord, the records are searched by all of them
+	keyTransferRecord struct {
+		ID               uuid.UUID `gorm:"primary_key;type:uuid"`
+		KeyID            uuid.UUID `gorm:"column:key_id;type:uuid;not null;index:idx_key_transfer_record_key_id"`
+		Requester        string    `gorm:"type:varchar(255);index:idx_key_transfer_record_requester"`
+		AttestationType  string    `gorm:"column:attestation_type;type:varchar(32)"`
+		TransferPolicyID uuid.UUID `gorm:"column:transfer_policy_id;type:uuid"`
+		Decision         string    `gorm:"type:varchar(16);not null"`
+		Reason           string
+		Version          int
+		CreatedAt        time.Time `gorm:"column:created_at;index:idx_key_transfer_record_created_at"`
+	}
+
+	// certificate holds the certificates of all types, CertType is saml or tpm-identity
+	certificate struct {
+		ID          uuid.UUID `gorm:"primary_key;type:uuid"`
+		CertType    string    `gorm:"column:cert_type;type:varchar(32);not null;index:idx_certificate_cert_type"`
+		Certificate []byte    `gorm:"not null"`
+		Subject     string    `gorm:"type:varchar(255);not null"`
+		Issuer      string    `gorm:"type:varchar(255);not null"`
+		NotBefore   time.Time `gorm:"column:not_before;not null"`
+		NotAfter    time.Time `gorm:"column:not_after;not null"`
+		Revoked     bool      `gorm:"not null;default:false"`
+		Digest      string    `gorm:"not null"`
+	}
+)
+
+func (ka PGKeyAttributes) Value() (driver.Value, error) {
+	return json.Marshal(ka)
+}
+
+func (ka *PGKeyAttributes) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("postgres/models:PGKeyAttributes_Scan() - type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, &ka)
+}
+
+func (e PGEnvelope) Value() (driver.Value, error) {
+	return json.Marshal(e)
+}
+
+func (e *PGEnvelope) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("postgres/models:PGEnvelope_Scan() - type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, &e)
+}
+
+func (ktp PGKeyTransferPolicy) Value() (driver.Value, error) {
+	return json.Marshal(ktp)
+}
+
+func (ktp *PGKeyTransferPolicy) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("postgres/models:PGKeyTransferPolicy_Scan() - type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, &ktp)
+}
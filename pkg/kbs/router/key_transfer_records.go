@@ -0,0 +1,25 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package router
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/controllers"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
+)
+
+//setKeyTransferRecordRoutes registers routes to search the records of the key transfers
+func setKeyTransferRecordRoutes(router *mux.Router, recordStore domain.KeyTransferRecordStore) *mux.Router {
+	defaultLog.Trace("router/key_transfer_records:setKeyTransferRecordRoutes() Entering")
+	defer defaultLog.Trace("router/key_transfer_records:setKeyTransferRecordRoutes() Leaving")
+
+	recordController := controllers.NewKeyTransferRecordController(recordStore)
+
+	router.Handle("/key-transfers", ErrorHandler(permissionsHandler(JsonResponseHandler(recordController.Search),
+		[]string{constants.KeyTransferRecordSearch}))).Methods("GET")
+
+	return router
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/config"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/controllers"
-	"github.com/intel-secl/intel-secl/v3/pkg/kbs/directory"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/keymanager"
 	consts "github.com/intel-secl/intel-secl/v3/pkg/lib/common/constants"
@@ -17,12 +16,10 @@ import (
 )
 
 //setKeyRoutes registers routes to perform Key CRUD operations
-func setKeyRoutes(router *mux.Router, endpointUrl string, config domain.KeyControllerConfig, keyManager keymanager.KeyManager) *mux.Router {
+func setKeyRoutes(router *mux.Router, endpointUrl string, config domain.KeyControllerConfig, keyManager keymanager.KeyManager, keyStore domain.KeyStore, policyStore domain.KeyTransferPolicyStore) *mux.Router {
 	defaultLog.Trace("router/keys:setKeyRoutes() Entering")
 	defer defaultLog.Trace("router/keys:setKeyRoutes() Leaving")
 
-	keyStore := directory.NewKeyStore(constants.KeysDir)
-	policyStore := directory.NewKeyTransferPolicyStore(constants.KeysTransferPolicyDir)
 	remoteManager := keymanager.NewRemoteManager(keyStore, keyManager, endpointUrl)
 	keyController := controllers.NewKeyController(remoteManager, policyStore, config)
 	keyIdExpr := "/keys/" + validation.IdReg
@@ -47,16 +44,26 @@ func setKeyRoutes(router *mux.Router, endpointUrl string, config domain.KeyContr
 		ErrorHandler(permissionsHandler(JsonResponseHandler(keyController.Transfer),
 			[]string{constants.KeyTransfer}))).Methods("POST")
 
+	router.Handle(keyIdExpr+"/rotate",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(keyController.Rotate),
+			[]string{constants.KeyRotate}))).Methods("POST")
+
+	router.Handle(keyIdExpr+"/usage-policy",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(keyController.UpdateUsagePolicy),
+			[]string{constants.KeyUpdate}))).Methods("PUT")
+
+	router.Handle(keyIdExpr+"/versions",
+		ErrorHandler(permissionsHandler(JsonResponseHandler(keyController.RetrieveVersions),
+			[]string{constants.KeyRetrieve}))).Methods("GET")
+
 	return router
 }
 
 //setKeyTransferRoutes registers routes to perform Key Transfer operations
-func setKeyTransferRoutes(router *mux.Router, endpointUrl string, config domain.KeyControllerConfig, keyManager keymanager.KeyManager) *mux.Router {
+func setKeyTransferRoutes(router *mux.Router, endpointUrl string, config domain.KeyControllerConfig, keyManager keymanager.KeyManager, keyStore domain.KeyStore, policyStore domain.KeyTransferPolicyStore) *mux.Router {
 	defaultLog.Trace("router/keys:setKeyTransferRoutes() Entering")
 	defer defaultLog.Trace("router/keys:setKeyTransferRoutes() Leaving")
 
-	keyStore := directory.NewKeyStore(constants.KeysDir)
-	policyStore := directory.NewKeyTransferPolicyStore(constants.KeysTransferPolicyDir)
 	remoteManager := keymanager.NewRemoteManager(keyStore, keyManager, endpointUrl)
 	keyController := controllers.NewKeyController(remoteManager, policyStore, config)
 	keyIdExpr := "/keys/" + validation.IdReg
@@ -68,14 +75,12 @@ func setKeyTransferRoutes(router *mux.Router, endpointUrl string, config domain.
 }
 
 //setSKCKeyTransferRoutes registers routes to perform SKC Transfer operations
-func setSKCKeyTransferRoutes(router *mux.Router, kbsConfig *config.Configuration, keyManager keymanager.KeyManager) *mux.Router {
+func setSKCKeyTransferRoutes(router *mux.Router, kbsConfig *config.Configuration, keyManager keymanager.KeyManager, keyStore domain.KeyStore, policyStore domain.KeyTransferPolicyStore, transferRecordStore domain.KeyTransferRecordStore) *mux.Router {
 	defaultLog.Trace("router/keys:setSKCKeyTransferRoutes() Entering")
 	defer defaultLog.Trace("router/keys:setSKCKeyTransferRoutes() Leaving")
 
-	keyStore := directory.NewKeyStore(constants.KeysDir)
-	policyStore := directory.NewKeyTransferPolicyStore(constants.KeysTransferPolicyDir)
 	remoteManager := keymanager.NewRemoteManager(keyStore, keyManager, kbsConfig.EndpointURL)
-	skcController := controllers.NewSKCController(remoteManager, policyStore, kbsConfig, constants.TrustedCaCertsDir)
+	skcController := controllers.NewSKCController(remoteManager, policyStore, transferRecordStore, kbsConfig, constants.TrustedCaCertsDir)
 	keyIdExpr := "/keys/" + validation.IdReg
 
 	router.Handle(keyIdExpr+"/dhsm2-transfer",
@@ -5,22 +5,21 @@
 package router
 
 import (
-	"crypto/tls"
-	"crypto/x509"
-	"io/ioutil"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/intel-secl/intel-secl/v3/pkg/clients"
+	"github.com/intel-secl/intel-secl/v3/pkg/clients/aas"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/config"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/keymanager"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
 	cmw "github.com/intel-secl/intel-secl/v3/pkg/lib/common/middleware"
-	cos "github.com/intel-secl/intel-secl/v3/pkg/lib/common/os"
+	aasModel "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
 	"github.com/pkg/errors"
 )
 
@@ -32,7 +31,7 @@ type Router struct {
 }
 
 // InitRoutes registers all routes for the application.
-func InitRoutes(cfg *config.Configuration, keyConfig domain.KeyControllerConfig, keyManager keymanager.KeyManager) *mux.Router {
+func InitRoutes(cfg *config.Configuration, keyConfig domain.KeyControllerConfig, keyManager keymanager.KeyManager, keyStore domain.KeyStore, policyStore domain.KeyTransferPolicyStore) *mux.Router {
 	defaultLog.Trace("router/router:InitRoutes() Entering")
 	defer defaultLog.Trace("router/router:InitRoutes() Leaving")
 
@@ -43,88 +42,88 @@ func InitRoutes(cfg *config.Configuration, keyConfig domain.KeyControllerConfig,
 	router.SkipClean(true)
 
 	// Define sub routes for path /kbs/v1
-	defineSubRoutes(router, "/"+strings.ToLower(constants.ServiceName)+constants.ApiVersion, cfg, keyConfig, keyManager)
+	defineSubRoutes(router, "/"+strings.ToLower(constants.ServiceName)+constants.ApiVersion, cfg, keyConfig, keyManager, keyStore, policyStore)
 
 	// Define sub routes for path /v1
-	defineSubRoutes(router, constants.ApiVersion, cfg, keyConfig, keyManager)
+	defineSubRoutes(router, constants.ApiVersion, cfg, keyConfig, keyManager, keyStore, policyStore)
 
 	return router
 }
 
-func defineSubRoutes(router *mux.Router, serviceApi string, cfg *config.Configuration, keyConfig domain.KeyControllerConfig, keyManager keymanager.KeyManager) {
+func defineSubRoutes(router *mux.Router, serviceApi string, cfg *config.Configuration, keyConfig domain.KeyControllerConfig, keyManager keymanager.KeyManager, keyStore domain.KeyStore, policyStore domain.KeyTransferPolicyStore) {
 	defaultLog.Trace("router/router:defineSubRoutes() Entering")
 	defer defaultLog.Trace("router/router:defineSubRoutes() Leaving")
 
 	subRouter := router.PathPrefix(serviceApi).Subrouter()
 	subRouter = setVersionRoutes(subRouter)
-	subRouter = setKeyTransferRoutes(subRouter, cfg.EndpointURL, keyConfig, keyManager)
-	subRouter = setSKCKeyTransferRoutes(subRouter, cfg, keyManager)
+	subRouter = setKeyTransferRoutes(subRouter, cfg.EndpointURL, keyConfig, keyManager, keyStore, policyStore)
+	subRouter = setSKCKeyTransferRoutes(subRouter, cfg, keyManager, keyStore, policyStore, keyConfig.KeyTransferRecordStore)
 	subRouter = setSessionRoutes(subRouter, cfg)
 	subRouter = router.PathPrefix(serviceApi).Subrouter()
 	cfgRouter := Router{cfg: cfg}
 	var cacheTime, _ = time.ParseDuration(constants.JWTCertsCacheTime)
-
-	subRouter.Use(cmw.NewTokenAuth(constants.TrustedJWTSigningCertsDir,
-		constants.TrustedCaCertsDir, cfgRouter.fnGetJwtCerts,
-		cacheTime))
-	subRouter = setKeyRoutes(subRouter, cfg.EndpointURL, keyConfig, keyManager)
-	subRouter = setKeyTransferPolicyRoutes(subRouter)
-	subRouter = setSamlCertRoutes(subRouter)
-	subRouter = setTpmIdentityCertRoutes(subRouter)
+	var revocationListCacheTime, _ = time.ParseDuration(constants.RevocationListCacheTime)
+	var revocationListMaxStaleness, _ = time.ParseDuration(constants.RevocationListMaxStaleness)
+
+	subRouter.Use(cmw.NewTokenAuthWithJwks(constants.TrustedJWTSigningCertsDir,
+		constants.TrustedCaCertsDir, cfgRouter.fnGetJwks,
+		cacheTime, cmw.NewRevocationListCache(cfgRouter.fnGetRevocationList, revocationListCacheTime, revocationListMaxStaleness)))
+	subRouter = setKeyRoutes(subRouter, cfg.EndpointURL, keyConfig, keyManager, keyStore, policyStore)
+	subRouter = setKeyTransferPolicyRoutes(subRouter, keyStore, policyStore)
+	subRouter = setSamlCertRoutes(subRouter, keyConfig.SamlCertStore)
+	subRouter = setTpmIdentityCertRoutes(subRouter, keyConfig.TpmIdentityCertStore)
+	subRouter = setKeyTransferRecordRoutes(subRouter, keyConfig.KeyTransferRecordStore)
 }
 
-// Fetch JWT certificate from AAS
-func (router *Router) fnGetJwtCerts() error {
-	defaultLog.Trace("router/router:fnGetJwtCerts() Entering")
-	defer defaultLog.Trace("router/router:fnGetJwtCerts() Leaving")
+// Fetch the token revocation list from AAS
+func (router *Router) fnGetRevocationList() (*aasModel.RevocationList, error) {
+	defaultLog.Trace("router/router:fnGetRevocationList() Entering")
+	defer defaultLog.Trace("router/router:fnGetRevocationList() Leaving")
 
-	cfg := router.cfg
-	if !strings.HasSuffix(cfg.AASApiUrl, "/") {
-		cfg.AASApiUrl = cfg.AASApiUrl + "/"
-	}
-	url := cfg.AASApiUrl + "noauth/jwt-certificates"
-	req, err := http.NewRequest("GET", url, nil)
+	caCerts, err := crypt.GetCertsFromDir(constants.TrustedCaCertsDir)
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Unable to create http request")
+		return nil, errors.Wrap(err, "router/router:fnGetRevocationList() Could not read root CA certificates")
 	}
-	req.Header.Add("accept", "application/x-pem-file")
-	rootCaCertPems, err := cos.GetDirFileContents(constants.TrustedCaCertsDir, "*.pem")
+	httpClient, err := clients.HTTPClientWithCA(caCerts)
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Unable to read root CA certificate")
+		return nil, errors.Wrap(err, "router/router:fnGetRevocationList() Could not create http client")
 	}
-
-	// Get the SystemCertPool to continue with an empty pool on error
-	rootCAs, err := x509.SystemCertPool()
-	if rootCAs == nil || err != nil {
-		rootCAs = x509.NewCertPool()
-	}
-	for _, rootCACert := range rootCaCertPems {
-		if ok := rootCAs.AppendCertsFromPEM(rootCACert); !ok {
-			return err
-		}
+	// the requests are checked against the previously retrieved list meanwhile, an unresponsive AAS must not
+	// keep it from being retrieved again
+	httpClient.Timeout, err = time.ParseDuration(constants.RevocationListTimeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "router/router:fnGetRevocationList() Could not parse token revocation list timeout")
 	}
-	httpClient := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				MinVersion:         tls.VersionTLS12,
-				InsecureSkipVerify: false,
-				RootCAs:            rootCAs,
-			},
-		},
+	aasClient := aas.Client{BaseURL: router.cfg.AASApiUrl, HTTPClient: httpClient}
+	revocationList, err := aasClient.GetRevokedTokens()
+	if err != nil {
+		return nil, errors.Wrap(err, "router/router:fnGetRevocationList() Could not retrieve token revocation list")
 	}
+	return revocationList, nil
+}
+
+// Fetch the JWK set of the token signing keys from AAS
+func (router *Router) fnGetJwks() (*jwtauth.JSONWebKeySet, error) {
+	defaultLog.Trace("router/router:fnGetJwks() Entering")
+	defer defaultLog.Trace("router/router:fnGetJwks() Leaving")
 
-	res, err := httpClient.Do(req)
+	caCerts, err := crypt.GetCertsFromDir(constants.TrustedCaCertsDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "router/router:fnGetJwks() Could not read root CA certificates")
+	}
+	httpClient, err := clients.HTTPClientWithCA(caCerts)
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Could not retrieve jwt certificate")
+		return nil, errors.Wrap(err, "router/router:fnGetJwks() Could not create http client")
 	}
-	defer res.Body.Close()
-	body, err := ioutil.ReadAll(res.Body)
+	// the requests signed by a key that is not in the cached JWK set wait for the retrieval
+	httpClient.Timeout, err = time.ParseDuration(constants.JwksTimeout)
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Read body filed failed")
+		return nil, errors.Wrap(err, "router/router:fnGetJwks() Could not parse JWK set timeout")
 	}
-	err = crypt.SavePemCertWithShortSha1FileName(body, constants.TrustedJWTSigningCertsDir)
+	aasClient := aas.Client{BaseURL: router.cfg.AASApiUrl, HTTPClient: httpClient}
+	jwks, err := aasClient.GetJwks()
 	if err != nil {
-		return errors.Wrap(err, "router/router:fnGetJwtCerts() Could not store Certificate")
+		return nil, errors.Wrap(err, "router/router:fnGetJwks() Could not retrieve JWK set")
 	}
-	return nil
+	return jwks, nil
 }
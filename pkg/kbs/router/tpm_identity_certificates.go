@@ -8,16 +8,15 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/controllers"
-	"github.com/intel-secl/intel-secl/v3/pkg/kbs/directory"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/validation"
 )
 
 //setTpmIdentityCertRoutes registers routes to perform TpmIdentityCertificate CRUD operations
-func setTpmIdentityCertRoutes(router *mux.Router) *mux.Router {
+func setTpmIdentityCertRoutes(router *mux.Router, certStore domain.CertificateStore) *mux.Router {
 	defaultLog.Trace("router/tpm_identity_certificates:setTpmIdentityCertRoutes() Entering")
 	defer defaultLog.Trace("router/tpm_identity_certificates:setTpmIdentityCertRoutes() Leaving")
 
-	certStore := directory.NewCertificateStore(constants.TpmIdentityCertsDir)
 	tpmIdentityCertController := controllers.NewCertificateController(certStore)
 	certIdExpr := "/tpm-identity-certificates/" + validation.IdReg
 
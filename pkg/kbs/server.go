@@ -7,6 +7,7 @@ package kbs
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509/pkix"
 	"fmt"
 	stdlog "log"
 	"net/http"
@@ -16,13 +17,19 @@ import (
 	"time"
 
 	"github.com/gorilla/handlers"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/config"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/directory"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/kek"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/keymanager"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/postgres"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/router"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/utils"
 	commLog "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
 	commLogMsg "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log/message"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/setup"
+	commTls "github.com/intel-secl/intel-secl/v3/pkg/lib/common/tls"
 	"github.com/pkg/errors"
 )
 
@@ -42,8 +49,17 @@ func (app *App) startServer() error {
 		return err
 	}
 
+	// Initialize the database of the postgres store, the directory store has none
+	dataStore, err := initDataStore(configuration)
+	if err != nil {
+		return err
+	}
+	if dataStore != nil {
+		defer dataStore.Close()
+	}
+
 	// Initialize KeyControllerConfig
-	kcc, err := initKeyControllerConfig()
+	kcc, err := initKeyControllerConfig(configuration, dataStore)
 	if err != nil {
 		return err
 	}
@@ -54,8 +70,34 @@ func (app *App) startServer() error {
 		return err
 	}
 
+	// Initialize KeyStore, the keys stored in plaintext or wrapped by a previous KEK are rewrapped
+	keyStore, err := initKeyStore(configuration, dataStore)
+	if err != nil {
+		return err
+	}
+
 	// Initialize routes
-	routes := router.InitRoutes(configuration, kcc, km)
+	routes := router.InitRoutes(configuration, kcc, km, keyStore, initKeyTransferPolicyStore(dataStore))
+
+	// Load the TLS certificate, it is reloaded when the files change and renewed with CMS before it expires
+	certReloader, err := commTls.NewCertReloader(configuration.TLS.CertFile, configuration.TLS.KeyFile,
+		configuration.TLS.RenewBefore, configuration.TLS.ReloadInterval,
+		setup.RenewCertWithUser(setup.DownloadCert{
+			KeyFile:       configuration.TLS.KeyFile,
+			CertFile:      configuration.TLS.CertFile,
+			KeyAlgorithm:  constants.DefaultKeyAlgorithm,
+			KeyLength:     constants.DefaultKeyLength,
+			Subject:       pkix.Name{CommonName: configuration.TLS.CommonName},
+			SanList:       configuration.TLS.SANList,
+			CertType:      "tls",
+			CaCertDirPath: constants.TrustedCaCertsDir,
+			CmsBaseURL:    configuration.CMSBaseURL,
+		}, configuration.AASApiUrl, configuration.TLS.RenewUsername, configuration.TLS.RenewPassword))
+	if err != nil {
+		return errors.Wrap(err, "kbs/server:startServer() Failed to load TLS certificate")
+	}
+	certReloader.Start()
+	defer certReloader.Stop()
 
 	defaultLog.Info("kbs/server:startServer() Starting server")
 	tlsConfig := &tls.Config{
@@ -64,7 +106,8 @@ func (app *App) startServer() error {
 			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
 			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
 			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
-		ClientAuth: tls.RequestClientCert,
+		ClientAuth:     tls.RequestClientCert,
+		GetCertificate: certReloader.GetCertificate,
 	}
 	// Setup signal handlers to gracefully handle termination
 	stop := make(chan os.Signal)
@@ -82,11 +125,9 @@ func (app *App) startServer() error {
 		MaxHeaderBytes:    configuration.Server.MaxHeaderBytes,
 	}
 
-	tlsCert := configuration.TLS.CertFile
-	tlsKey := configuration.TLS.KeyFile
 	// Dispatch web server go routine
 	go func() {
-		if err := httpServer.ListenAndServeTLS(tlsCert, tlsKey); err != nil {
+		if err := httpServer.ListenAndServeTLS("", ""); err != nil {
 			defaultLog.WithError(err).Error("kbs/server:startServer() Failed to start HTTPS server")
 			stop <- syscall.SIGTERM
 		}
@@ -106,7 +147,27 @@ func (app *App) startServer() error {
 	return nil
 }
 
-func initKeyControllerConfig() (domain.KeyControllerConfig, error) {
+// initDataStore connects to the database of the postgres store, it returns nil for the directory store
+func initDataStore(configuration *config.Configuration) (*postgres.DataStore, error) {
+	defaultLog.Trace("server:initDataStore() Entering")
+	defer defaultLog.Trace("server:initDataStore() Leaving")
+
+	switch configuration.Store {
+	case "", constants.DirectoryStore:
+		return nil, nil
+	case constants.PostgresStore:
+		dataStore, err := postgres.New(postgres.NewDatabaseConfig(constants.DBTypePostgres, &configuration.DB))
+		if err != nil {
+			return nil, errors.Wrap(err, "kbs/server:initDataStore() Failed to connect database")
+		}
+		dataStore.Migrate()
+		return dataStore, nil
+	default:
+		return nil, errors.Errorf("kbs/server:initDataStore() Unknown store %s", configuration.Store)
+	}
+}
+
+func initKeyControllerConfig(configuration *config.Configuration, dataStore *postgres.DataStore) (domain.KeyControllerConfig, error) {
 	defaultLog.Trace("server:initKeyControllerConfig() Entering")
 	defer defaultLog.Trace("server:initKeyControllerConfig() Leaving")
 
@@ -116,10 +177,78 @@ func initKeyControllerConfig() (domain.KeyControllerConfig, error) {
 	}
 
 	kcc := domain.KeyControllerConfig{
-		SamlCertsDir:            constants.SamlCertsDir,
+		SamlCertStore:           initCertificateStore(dataStore, constants.SamlCertType, constants.SamlCertsDir),
 		TrustedCaCertsDir:       constants.TrustedCaCertsDir,
-		TpmIdentityCertsDir:     constants.TpmIdentityCertsDir,
+		TpmIdentityCertStore:    initCertificateStore(dataStore, constants.TpmIdentityCertType, constants.TpmIdentityCertsDir),
 		DefaultTransferPolicyId: id,
+		PriorKeyVersionValidity: configuration.KeyRotation.PriorVersionValidity,
+		KeyTransferRecordStore:  initKeyTransferRecordStore(configuration, dataStore),
+	}
+	// the configurations saved before the key rotation do not set the validity
+	if kcc.PriorKeyVersionValidity == 0 {
+		kcc.PriorKeyVersionValidity = constants.DefaultPriorKeyVersionValidity
 	}
 	return kcc, nil
 }
+
+func initKeyStore(configuration *config.Configuration, dataStore *postgres.DataStore) (domain.KeyStore, error) {
+	defaultLog.Trace("server:initKeyStore() Entering")
+	defer defaultLog.Trace("server:initKeyStore() Leaving")
+
+	kekProvider, err := kek.NewProvider(&configuration.Kek, &configuration.Kmip)
+	if err != nil {
+		return nil, errors.Wrap(err, "kbs/server:initKeyStore() Failed to initialize key encryption key provider")
+	}
+
+	if configuration.Kek.ID == "" {
+		configuration.Kek.ID, err = kekProvider.Create()
+		if err != nil {
+			return nil, errors.Wrap(err, "kbs/server:initKeyStore() Failed to create key encryption key")
+		}
+		if err = configuration.Save(constants.DefaultConfigFilePath); err != nil {
+			return nil, errors.Wrap(err, "kbs/server:initKeyStore() Failed to save key encryption key ID")
+		}
+		secLog.Infof("kbs/server:initKeyStore() Created key encryption key %s", configuration.Kek.ID)
+	}
+
+	var keyStore domain.SealedKeyStore
+	if dataStore != nil {
+		keyStore = postgres.NewKeyStore(dataStore, kekProvider, configuration.Kek.ID)
+	} else {
+		keyStore = directory.NewKeyStore(constants.KeysDir, kekProvider, configuration.Kek.ID)
+	}
+	rewrapped, err := keyStore.Rewrap()
+	if err != nil {
+		return nil, errors.Wrap(err, "kbs/server:initKeyStore() Failed to wrap keys with key encryption key")
+	}
+	if rewrapped > 0 {
+		secLog.Infof("kbs/server:initKeyStore() Wrapped %d keys with key encryption key %s", rewrapped, configuration.Kek.ID)
+	}
+	return keyStore, nil
+}
+
+func initKeyTransferPolicyStore(dataStore *postgres.DataStore) domain.KeyTransferPolicyStore {
+	if dataStore != nil {
+		return postgres.NewKeyTransferPolicyStore(dataStore)
+	}
+	return directory.NewKeyTransferPolicyStore(constants.KeysTransferPolicyDir)
+}
+
+func initKeyTransferRecordStore(configuration *config.Configuration, dataStore *postgres.DataStore) domain.KeyTransferRecordStore {
+	if dataStore != nil {
+		return postgres.NewKeyTransferRecordStore(dataStore)
+	}
+	// the configurations saved before the retention of the records do not set the maximum
+	maxCount := configuration.KeyTransferRecords.MaxCount
+	if maxCount == 0 {
+		maxCount = constants.DefaultKeyTransferRecordsMaxCount
+	}
+	return directory.NewKeyTransferRecordStore(constants.KeyTransferRecordsDir, maxCount)
+}
+
+func initCertificateStore(dataStore *postgres.DataStore, certType, certsDir string) domain.CertificateStore {
+	if dataStore != nil {
+		return postgres.NewCertificateStore(dataStore, certType)
+	}
+	return directory.NewCertificateStore(certsDir)
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/config"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
 	"github.com/intel-secl/intel-secl/v3/pkg/kbs/tasks"
 	commConfig "github.com/intel-secl/intel-secl/v3/pkg/lib/common/config"
@@ -139,10 +140,59 @@ func (app *App) setupTaskRunner() (*setup.Runner, error) {
 		CmsBaseURL:    viper.GetString("cms-base-url"),
 		BearerToken:   viper.GetString("bearer-token"),
 	})
+	runner.AddTask("database", "", &tasks.DBSetup{
+		DBConfigPtr: &app.Config.DB,
+		DBConfig: commConfig.DBConfig{
+			Vendor:   viper.GetString("db-vendor"),
+			Host:     viper.GetString("db-host"),
+			Port:     viper.GetInt("db-port"),
+			DBName:   viper.GetString("db-name"),
+			Username: viper.GetString("db-username"),
+			Password: viper.GetString("db-password"),
+			SSLMode:  viper.GetString("db-ssl-mode"),
+			SSLCert:  viper.GetString("db-ssl-cert"),
+
+			ConnectionRetryAttempts: viper.GetInt("db-conn-retry-attempts"),
+			ConnectionRetryTime:     viper.GetInt("db-conn-retry-time"),
+		},
+		SSLCertSource: viper.GetString("db-ssl-cert-source"),
+		StorePtr:      &app.Config.Store,
+		Store:         viper.GetString("store"),
+		ConsoleWriter: app.consoleWriter(),
+	})
 	runner.AddTask("create-default-key-transfer-policy", "", &tasks.CreateDefaultTransferPolicy{
 		DefaultTransferPolicyFile: constants.DefaultTransferPolicyFile,
 		ConsoleWriter:             app.consoleWriter(),
 	})
+	runner.AddTask("rewrap", "", &tasks.Rewrap{
+		KekConfigPtr: &app.Config.Kek,
+		KekConfig: config.KekConfig{
+			Provider: viper.GetString("kek-provider"),
+			Pkcs11: config.Pkcs11Config{
+				Module:     viper.GetString("kek-pkcs11-module"),
+				TokenLabel: viper.GetString("kek-pkcs11-token-label"),
+				PinFile:    viper.GetString("kek-pkcs11-pin-file"),
+				Pin:        viper.GetString("kek-pkcs11-pin"),
+			},
+		},
+		KmipConfig:    &app.Config.Kmip,
+		KeysDir:       constants.KeysDir,
+		StorePtr:      &app.Config.Store,
+		DBConfigPtr:   &app.Config.DB,
+		ConsoleWriter: app.consoleWriter(),
+	})
+	runner.AddTask("migrate-to-postgres", "", &tasks.MigrateToPostgres{
+		StorePtr:              &app.Config.Store,
+		DBConfigPtr:           &app.Config.DB,
+		KekConfigPtr:          &app.Config.Kek,
+		KmipConfig:            &app.Config.Kmip,
+		ConsoleWriter:         app.consoleWriter(),
+		KeysDir:               constants.KeysDir,
+		KeyTransferPolicyDir:  constants.KeysTransferPolicyDir,
+		SamlCertsDir:          constants.SamlCertsDir,
+		TpmIdentityCertsDir:   constants.TpmIdentityCertsDir,
+		KeyTransferRecordsDir: constants.KeyTransferRecordsDir,
+	})
 
 	return runner, nil
 }
@@ -0,0 +1,300 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package tasks
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/directory"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/kek"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/postgres"
+	commConfig "github.com/intel-secl/intel-secl/v3/pkg/lib/common/config"
+	commErr "github.com/intel-secl/intel-secl/v3/pkg/lib/common/err"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
+	"github.com/pkg/errors"
+)
+
+// MigrateToPostgres copies the keys, key transfer policies, certificates and key transfer records of the directory store into the postgres
+// store, keeping their IDs. The records already in postgres are skipped, the task can be run again after a failure.
+// The directories are left untouched.
+type MigrateToPostgres struct {
+	StorePtr      *string
+	DBConfigPtr   *commConfig.DBConfig
+	KekConfigPtr  *config.KekConfig
+	KmipConfig    *config.KmipConfig
+	ConsoleWriter io.Writer
+	// KekProvider is created from the configuration when nil
+	KekProvider domain.KeyEncryptionKeyProvider
+	// DataStore is connected from the configuration, and its schema migrated, when nil
+	DataStore *postgres.DataStore
+
+	KeysDir               string
+	KeyTransferPolicyDir  string
+	SamlCertsDir          string
+	TpmIdentityCertsDir   string
+	KeyTransferRecordsDir string
+
+	commandName string
+}
+
+func (t *MigrateToPostgres) Run() error {
+	if *t.StorePtr != constants.PostgresStore {
+		fmt.Fprintln(t.ConsoleWriter, "Store is not postgres, skipping migration")
+		return nil
+	}
+	if t.KekConfigPtr.ID == "" {
+		return errors.New("tasks/migrate_to_postgres:Run() Key encryption key is not configured, run the rewrap task first")
+	}
+	kekProvider, err := t.kekProvider()
+	if err != nil {
+		return errors.Wrap(err, "tasks/migrate_to_postgres:Run() Failed to initialize key encryption key provider")
+	}
+	dataStore := t.DataStore
+	if dataStore == nil {
+		dataStore, err = postgres.New(pgConfig(t.DBConfigPtr))
+		if err != nil {
+			return errors.Wrap(err, "tasks/migrate_to_postgres:Run() Failed to connect database")
+		}
+		defer dataStore.Close()
+		dataStore.Migrate()
+	}
+
+	// the policies are migrated before the keys referring to them
+	policies, err := directory.NewKeyTransferPolicyStore(t.KeyTransferPolicyDir).Search(nil)
+	if err != nil {
+		return errors.Wrap(err, "tasks/migrate_to_postgres:Run() Failed to read key transfer policies")
+	}
+	policyStore := postgres.NewKeyTransferPolicyStore(dataStore)
+	migratedPolicies := 0
+	for i := range policies {
+		exists, err := recordExists(policyStore.Retrieve(policies[i].ID))
+		if err != nil {
+			return errors.Wrapf(err, "tasks/migrate_to_postgres:Run() Failed to check key transfer policy %s", policies[i].ID)
+		}
+		if exists {
+			continue
+		}
+		if _, err = policyStore.Import(&policies[i]); err != nil {
+			return errors.Wrapf(err, "tasks/migrate_to_postgres:Run() Failed to migrate key transfer policy %s", policies[i].ID)
+		}
+		migratedPolicies++
+	}
+
+	keys, err := directory.NewKeyStore(t.KeysDir, kekProvider, t.KekConfigPtr.ID).Search(nil)
+	if err != nil {
+		return errors.Wrap(err, "tasks/migrate_to_postgres:Run() Failed to read keys")
+	}
+	keyStore := postgres.NewKeyStore(dataStore, kekProvider, t.KekConfigPtr.ID)
+	migratedKeys := 0
+	for i := range keys {
+		exists, err := recordExists(keyStore.Retrieve(keys[i].ID))
+		if err != nil {
+			return errors.Wrapf(err, "tasks/migrate_to_postgres:Run() Failed to check key %s", keys[i].ID)
+		}
+		if exists {
+			continue
+		}
+		if _, err = keyStore.Create(&keys[i]); err != nil {
+			return errors.Wrapf(err, "tasks/migrate_to_postgres:Run() Failed to migrate key %s", keys[i].ID)
+		}
+		migratedKeys++
+	}
+
+	migratedSamlCerts, err := migrateCertificates(t.SamlCertsDir, postgres.NewCertificateStore(dataStore, constants.SamlCertType))
+	if err != nil {
+		return errors.Wrap(err, "tasks/migrate_to_postgres:Run() Failed to migrate SAML certificates")
+	}
+	migratedTpmIdentityCerts, err := migrateCertificates(t.TpmIdentityCertsDir, postgres.NewCertificateStore(dataStore, constants.TpmIdentityCertType))
+	if err != nil {
+		return errors.Wrap(err, "tasks/migrate_to_postgres:Run() Failed to migrate TPM identity certificates")
+	}
+
+	migratedRecords, err := migrateKeyTransferRecords(t.KeyTransferRecordsDir, postgres.NewKeyTransferRecordStore(dataStore))
+	if err != nil {
+		return errors.Wrap(err, "tasks/migrate_to_postgres:Run() Failed to migrate key transfer records")
+	}
+
+	fmt.Fprintf(t.ConsoleWriter, "Migrated %d keys, %d key transfer policies, %d SAML certificates, %d TPM identity certificates and %d key transfer records to postgres\n",
+		migratedKeys, migratedPolicies, migratedSamlCerts, migratedTpmIdentityCerts, migratedRecords)
+	return nil
+}
+
+// Validate checks that all the records of the directory store are in the postgres store
+func (t *MigrateToPostgres) Validate() error {
+	if *t.StorePtr != constants.PostgresStore {
+		return nil
+	}
+	kekProvider, err := t.kekProvider()
+	if err != nil {
+		return errors.Wrap(err, "tasks/migrate_to_postgres:Validate() Failed to initialize key encryption key provider")
+	}
+	dataStore := t.DataStore
+	if dataStore == nil {
+		dataStore, err = postgres.New(pgConfig(t.DBConfigPtr))
+		if err != nil {
+			return errors.Wrap(err, "tasks/migrate_to_postgres:Validate() Failed to connect database")
+		}
+		defer dataStore.Close()
+	}
+
+	missing := 0
+	policies, err := directory.NewKeyTransferPolicyStore(t.KeyTransferPolicyDir).Search(nil)
+	if err != nil {
+		return errors.Wrap(err, "tasks/migrate_to_postgres:Validate() Failed to read key transfer policies")
+	}
+	policyStore := postgres.NewKeyTransferPolicyStore(dataStore)
+	for _, policy := range policies {
+		exists, err := recordExists(policyStore.Retrieve(policy.ID))
+		if err != nil {
+			return errors.Wrapf(err, "tasks/migrate_to_postgres:Validate() Failed to check key transfer policy %s", policy.ID)
+		}
+		if !exists {
+			missing++
+		}
+	}
+
+	keys, err := directory.NewKeyStore(t.KeysDir, kekProvider, t.KekConfigPtr.ID).Search(nil)
+	if err != nil {
+		return errors.Wrap(err, "tasks/migrate_to_postgres:Validate() Failed to read keys")
+	}
+	keyStore := postgres.NewKeyStore(dataStore, kekProvider, t.KekConfigPtr.ID)
+	for _, key := range keys {
+		exists, err := recordExists(keyStore.Retrieve(key.ID))
+		if err != nil {
+			return errors.Wrapf(err, "tasks/migrate_to_postgres:Validate() Failed to check key %s", key.ID)
+		}
+		if !exists {
+			missing++
+		}
+	}
+
+	for certsDir, certType := range map[string]string{t.SamlCertsDir: constants.SamlCertType, t.TpmIdentityCertsDir: constants.TpmIdentityCertType} {
+		certs, err := directory.NewCertificateStore(certsDir).Search(nil)
+		if err != nil {
+			return errors.Wrapf(err, "tasks/migrate_to_postgres:Validate() Failed to read %s certificates", certType)
+		}
+		certStore := postgres.NewCertificateStore(dataStore, certType)
+		for _, cert := range certs {
+			exists, err := recordExists(certStore.Retrieve(cert.ID))
+			if err != nil {
+				return errors.Wrapf(err, "tasks/migrate_to_postgres:Validate() Failed to check %s certificate %s", certType, cert.ID)
+			}
+			if !exists {
+				missing++
+			}
+		}
+	}
+
+	records, err := readKeyTransferRecords(t.KeyTransferRecordsDir)
+	if err != nil {
+		return errors.Wrap(err, "tasks/migrate_to_postgres:Validate() Failed to read key transfer records")
+	}
+	recordStore := postgres.NewKeyTransferRecordStore(dataStore)
+	for _, record := range records {
+		exists, err := recordExists(recordStore.Retrieve(record.ID))
+		if err != nil {
+			return errors.Wrapf(err, "tasks/migrate_to_postgres:Validate() Failed to check key transfer record %s", record.ID)
+		}
+		if !exists {
+			missing++
+		}
+	}
+
+	if missing > 0 {
+		return errors.Errorf("tasks/migrate_to_postgres:Validate() %d records of the directory store are not migrated to postgres", missing)
+	}
+	return nil
+}
+
+func (t *MigrateToPostgres) PrintHelp(w io.Writer) {
+	fmt.Fprintln(w, "Copies the keys, key transfer policies, certificates and key transfer records of the directory store into the postgres store.")
+	fmt.Fprintln(w, "The task runs when the store is postgres, after the database task. The records already migrated are skipped.")
+	fmt.Fprintln(w, "Restart KBS afterwards, the directories can be removed once the migration is validated.")
+}
+
+func (t *MigrateToPostgres) SetName(n, e string) {
+	t.commandName = n
+}
+
+func (t *MigrateToPostgres) kekProvider() (domain.KeyEncryptionKeyProvider, error) {
+	if t.KekProvider != nil {
+		return t.KekProvider, nil
+	}
+	return kek.NewProvider(t.KekConfigPtr, t.KmipConfig)
+}
+
+// migrateCertificates imports the certificates of the directory into the store and returns the number imported
+func migrateCertificates(certsDir string, certStore *postgres.CertificateStore) (int, error) {
+	certs, err := directory.NewCertificateStore(certsDir).Search(nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "Failed to read certificates")
+	}
+
+	migrated := 0
+	for i := range certs {
+		exists, err := recordExists(certStore.Retrieve(certs[i].ID))
+		if err != nil {
+			return migrated, errors.Wrapf(err, "Failed to check certificate %s", certs[i].ID)
+		}
+		if exists {
+			continue
+		}
+		if _, err = certStore.Import(&certs[i]); err != nil {
+			return migrated, errors.Wrapf(err, "Failed to migrate certificate %s", certs[i].ID)
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// migrateKeyTransferRecords imports the key transfer records of the directory into the store and returns the number
+// imported
+func migrateKeyTransferRecords(recordsDir string, recordStore *postgres.KeyTransferRecordStore) (int, error) {
+	records, err := readKeyTransferRecords(recordsDir)
+	if err != nil {
+		return 0, errors.Wrap(err, "Failed to read key transfer records")
+	}
+
+	migrated := 0
+	for i := range records {
+		exists, err := recordExists(recordStore.Retrieve(records[i].ID))
+		if err != nil {
+			return migrated, errors.Wrapf(err, "Failed to check key transfer record %s", records[i].ID)
+		}
+		if exists {
+			continue
+		}
+		if _, err = recordStore.Import(&records[i]); err != nil {
+			return migrated, errors.Wrapf(err, "Failed to migrate key transfer record %s", records[i].ID)
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// readKeyTransferRecords returns the key transfer records of the directory, the directory does not exist on the
+// installations preceding the records
+func readKeyTransferRecords(recordsDir string) ([]kbs.KeyTransferRecord, error) {
+	if _, err := os.Stat(recordsDir); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return directory.NewKeyTransferRecordStore(recordsDir, 0).Search(nil)
+}
+
+// recordExists tells from the result of a Retrieve whether the record exists
+func recordExists(_ interface{}, err error) (bool, error) {
+	if err == nil {
+		return true, nil
+	}
+	if err.Error() == commErr.RecordNotFound {
+		return false, nil
+	}
+	return false, err
+}
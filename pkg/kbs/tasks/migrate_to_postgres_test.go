@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package tasks
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/directory"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain/models"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/kek"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/postgres"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/kbs"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	retrievePolicyQuery = regexp.QuoteMeta(`SELECT * FROM "key_transfer_policy" WHERE (id = $1)`)
+	insertPolicyQuery   = regexp.QuoteMeta(`INSERT INTO "key_transfer_policy"`)
+	retrieveKeyQuery    = regexp.QuoteMeta(`SELECT * FROM "key" WHERE (id = $1)`)
+	insertKeyQuery      = regexp.QuoteMeta(`INSERT INTO "key"`)
+)
+
+func TestMigrateToPostgresRunAgain(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "migrate")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+	kekProvider := kek.NewFileProvider(filepath.Join(dir, "kek"))
+	kekId, err := kekProvider.Create()
+	a.NoError(err)
+
+	task := MigrateToPostgres{
+		StorePtr:     &[]string{constants.PostgresStore}[0],
+		KekConfigPtr: &config.KekConfig{ID: kekId},
+		KekProvider:  kekProvider,
+
+		KeysDir:               filepath.Join(dir, "keys"),
+		KeyTransferPolicyDir:  filepath.Join(dir, "policies"),
+		SamlCertsDir:          filepath.Join(dir, "saml"),
+		TpmIdentityCertsDir:   filepath.Join(dir, "tpm-identity"),
+		KeyTransferRecordsDir: filepath.Join(dir, "records"),
+	}
+	for _, d := range []string{task.KeysDir, task.KeyTransferPolicyDir, task.SamlCertsDir, task.TpmIdentityCertsDir} {
+		a.NoError(os.MkdirAll(d, 0700))
+	}
+	policy, err := directory.NewKeyTransferPolicyStore(task.KeyTransferPolicyDir).Create(&kbs.KeyTransferPolicyAttributes{
+		SGXEnclaveIssuerAnyof: []string{"cd171c56941c6ce49690b455f691d9c8a04c2e43e0a4d30f752fa5285c7ee57f"},
+	})
+	a.NoError(err)
+	key := &models.KeyAttributes{ID: uuid.New(), Algorithm: "AES", KeyLength: 256, KeyData: "c2VjcmV0IGtleSBkYXRh",
+		TransferPolicyId: policy.ID}
+	_, err = directory.NewKeyStore(task.KeysDir, kekProvider, kekId).Create(key)
+	a.NoError(err)
+
+	// the first run imports the policy and the key
+	dataStore, mock, err := postgres.NewSQLMockDataStore()
+	a.NoError(err)
+	mock.ExpectQuery(retrievePolicyQuery).WithArgs(policy.ID).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectBegin()
+	mock.ExpectQuery(insertPolicyQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(policy.ID))
+	mock.ExpectCommit()
+	mock.ExpectQuery(retrieveKeyQuery).WithArgs(key.ID).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectBegin()
+	mock.ExpectQuery(insertKeyQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(key.ID))
+	mock.ExpectCommit()
+	console := &bytes.Buffer{}
+	task.DataStore = dataStore
+	task.ConsoleWriter = console
+	a.NoError(task.Run())
+	a.NoError(mock.ExpectationsWereMet())
+	a.Contains(console.String(), "Migrated 1 keys, 1 key transfer policies, 0 SAML certificates, 0 TPM identity certificates and 0 key transfer records")
+
+	// the run after a failure or a completed migration skips the records already in postgres
+	policyAttributes, err := json.Marshal(policy)
+	a.NoError(err)
+	sealedKey, envelope, err := kek.NewSealer(kekProvider, kekId).SealKey(key)
+	a.NoError(err)
+	keyAttributes, err := json.Marshal(sealedKey)
+	a.NoError(err)
+	keyEnvelope, err := json.Marshal(envelope)
+	a.NoError(err)
+
+	dataStore, mock, err = postgres.NewSQLMockDataStore()
+	a.NoError(err)
+	mock.ExpectQuery(retrievePolicyQuery).WithArgs(policy.ID).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "created_at", "attributes"}).AddRow(policy.ID, policy.CreatedAt, policyAttributes))
+	mock.ExpectQuery(retrieveKeyQuery).WithArgs(key.ID).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "algorithm", "kek_id", "attributes", "envelope", "revision"}).
+			AddRow(key.ID, key.Algorithm, kekId, keyAttributes, keyEnvelope, 0))
+	console.Reset()
+	task.DataStore = dataStore
+	a.NoError(task.Run())
+	a.NoError(mock.ExpectationsWereMet())
+	a.Contains(console.String(), "Migrated 0 keys, 0 key transfer policies, 0 SAML certificates, 0 TPM identity certificates and 0 key transfer records")
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package tasks
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/constants"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/directory"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/domain"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/kek"
+	"github.com/intel-secl/intel-secl/v3/pkg/kbs/postgres"
+	commConfig "github.com/intel-secl/intel-secl/v3/pkg/lib/common/config"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/pkcs11"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/setup"
+	"github.com/pkg/errors"
+)
+
+// Rewrap creates a new key encryption key and rewraps the data encryption keys of the stored keys with it. The keys
+// wrapped by the previous KEK stay readable while the task runs, the previous KEK must be kept until KBS is restarted
+// with the new one.
+type Rewrap struct {
+	KekConfigPtr *config.KekConfig
+	KekConfig    config.KekConfig
+	KmipConfig   *config.KmipConfig
+	KeysDir      string
+	// StorePtr and DBConfigPtr select the store of the keys, the keys are in KeysDir unless the store is postgres
+	StorePtr      *string
+	DBConfigPtr   *commConfig.DBConfig
+	ConsoleWriter io.Writer
+	// KekProvider is created from the configuration when nil
+	KekProvider domain.KeyEncryptionKeyProvider
+	commandName string
+}
+
+const rewrapEnvHelpPrompt = "Following environment variables are optionally used in "
+
+var rewrapEnvHelp = map[string]string{
+	"KEK_PROVIDER":           "Provider of the key encryption keys: file, pkcs11 or kmip, can not be changed once a KEK exists",
+	"KEK_PKCS11_MODULE":      "Path of the PKCS#11 library of the pkcs11 provider",
+	"KEK_PKCS11_TOKEN_LABEL": "Label of the PKCS#11 token of the pkcs11 provider",
+	"KEK_PKCS11_PIN":         "User PIN of the PKCS#11 token of the pkcs11 provider, it is saved in the PIN file",
+	"KEK_PKCS11_PIN_FILE":    "File keeping the user PIN of the PKCS#11 token, readable only by the owner",
+}
+
+func (t *Rewrap) Run() error {
+	if t.KekConfigPtr.ID == "" {
+		if t.KekConfig.Provider != "" {
+			t.KekConfigPtr.Provider = t.KekConfig.Provider
+		}
+		if t.KekConfig.Pkcs11.Module != "" {
+			t.KekConfigPtr.Pkcs11 = t.KekConfig.Pkcs11
+			if t.KekConfig.Pkcs11.Pin != "" {
+				err := pkcs11.SavePin(t.KekConfig.Pkcs11.PinFile, t.KekConfig.Pkcs11.Pin)
+				if err != nil {
+					return errors.Wrap(err, "tasks/rewrap:Run() Failed to save PKCS#11 token PIN")
+				}
+			}
+		}
+	} else if t.KekConfig.Provider != "" && !strings.EqualFold(t.KekConfig.Provider, t.KekConfigPtr.Provider) {
+		return errors.Errorf("tasks/rewrap:Run() KEK provider can not be changed from %s to %s", t.KekConfigPtr.Provider, t.KekConfig.Provider)
+	}
+
+	kekProvider, err := t.kekProvider()
+	if err != nil {
+		return errors.Wrap(err, "tasks/rewrap:Run() Failed to initialize key encryption key provider")
+	}
+
+	fmt.Fprintln(t.ConsoleWriter, "Creating key encryption key")
+	kekId, err := kekProvider.Create()
+	if err != nil {
+		return errors.Wrap(err, "tasks/rewrap:Run() Failed to create key encryption key")
+	}
+
+	keyStore, err := t.keyStore(kekProvider, kekId)
+	if err != nil {
+		return errors.Wrap(err, "tasks/rewrap:Run() Failed to initialize key store")
+	}
+	rewrapped, err := keyStore.Rewrap()
+	if err != nil {
+		return errors.Wrap(err, "tasks/rewrap:Run() Failed to rewrap keys")
+	}
+	previousKekId := t.KekConfigPtr.ID
+	t.KekConfigPtr.ID = kekId
+
+	fmt.Fprintf(t.ConsoleWriter, "Rewrapped %d keys with key encryption key %s\n", rewrapped, kekId)
+	if previousKekId != "" {
+		fmt.Fprintf(t.ConsoleWriter, "Restart KBS to wrap the new keys with it, the previous key encryption key %s can be removed afterwards\n", previousKekId)
+	}
+	return nil
+}
+
+// Validate checks that a KEK is configured and that the stored keys are wrapped by it
+func (t *Rewrap) Validate() error {
+	if t.KekConfigPtr.ID == "" {
+		return errors.New("tasks/rewrap:Validate() Key encryption key is not configured")
+	}
+	kekProvider, err := t.kekProvider()
+	if err != nil {
+		return errors.Wrap(err, "tasks/rewrap:Validate() Failed to initialize key encryption key provider")
+	}
+	if _, err = kekProvider.Retrieve(t.KekConfigPtr.ID); err != nil {
+		return errors.Wrap(err, "tasks/rewrap:Validate() Failed to retrieve key encryption key")
+	}
+	keyStore, err := t.keyStore(kekProvider, t.KekConfigPtr.ID)
+	if err != nil {
+		return errors.Wrap(err, "tasks/rewrap:Validate() Failed to initialize key store")
+	}
+	stale, err := keyStore.Stale()
+	if err != nil {
+		return errors.Wrap(err, "tasks/rewrap:Validate() Failed to check stored keys")
+	}
+	if len(stale) > 0 {
+		return errors.Errorf("tasks/rewrap:Validate() %d keys are not wrapped by the key encryption key", len(stale))
+	}
+	return nil
+}
+
+func (t *Rewrap) PrintHelp(w io.Writer) {
+	fmt.Fprintln(w, "Creates a new key encryption key and rewraps the data encryption keys of the stored keys with it.")
+	fmt.Fprintln(w, "Run with --force to rotate the key encryption key, KBS keeps serving the keys meanwhile.")
+	setup.PrintEnvHelp(w, rewrapEnvHelpPrompt+t.commandName, "", rewrapEnvHelp)
+}
+
+func (t *Rewrap) SetName(n, e string) {
+	t.commandName = n
+}
+
+func (t *Rewrap) kekProvider() (domain.KeyEncryptionKeyProvider, error) {
+	if t.KekProvider != nil {
+		return t.KekProvider, nil
+	}
+	return kek.NewProvider(t.KekConfigPtr, t.KmipConfig)
+}
+
+func (t *Rewrap) keyStore(kekProvider domain.KeyEncryptionKeyProvider, kekId string) (domain.SealedKeyStore, error) {
+	if t.StorePtr == nil || *t.StorePtr != constants.PostgresStore {
+		return directory.NewKeyStore(t.KeysDir, kekProvider, kekId), nil
+	}
+	dataStore, err := postgres.New(pgConfig(t.DBConfigPtr))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to connect database")
+	}
+	return postgres.NewKeyStore(dataStore, kekProvider, kekId), nil
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package auth
+
+import (
+	"strings"
+
+	types "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/pkg/errors"
+)
+
+// Attributes are the attributes of a resource that the constraints of the permissions are evaluated against. An
+// attribute can have several values, e.g. the flavorgroups of a host.
+type Attributes map[string][]string
+
+// Constraints restrict a permission to the resources whose attributes match. They are set in the context of the
+// role holding the permission as <attribute>=<value>[,<value>...][;<attribute>=<value>...], e.g.
+// flavorgroup=automatic,prod-*;host=edge-*. A value ending with * matches the values starting with the rest of it.
+type Constraints map[string][]string
+
+// ParseConstraints parses the context of a permission, an empty context has no constraints
+func ParseConstraints(context string) (Constraints, error) {
+	constraints := Constraints{}
+	for _, constraint := range strings.Split(context, ";") {
+		if strings.TrimSpace(constraint) == "" {
+			continue
+		}
+		split := strings.SplitN(constraint, "=", 2)
+		attribute := strings.TrimSpace(split[0])
+		if len(split) != 2 || attribute == "" {
+			return nil, errors.Errorf("Invalid constraint %q, <attribute>=<value>[,<value>...] expected", constraint)
+		}
+		for _, value := range strings.Split(split[1], ",") {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return nil, errors.Errorf("Invalid constraint %q, empty value", constraint)
+			}
+			constraints[attribute] = append(constraints[attribute], value)
+		}
+	}
+	return constraints, nil
+}
+
+// Match tells whether each constrained attribute of the resource has one of the allowed values. A resource that does
+// not have a constrained attribute does not match.
+func (c Constraints) Match(attributes Attributes) bool {
+	for attribute, allowed := range c {
+		if !matchAny(allowed, attributes[attribute]) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAny(allowed, values []string) bool {
+	for _, value := range values {
+		for _, a := range allowed {
+			if a == "*" || a == value ||
+				(strings.HasSuffix(a, "*") && strings.HasPrefix(value, strings.TrimSuffix(a, "*"))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Policy holds the constraints of the permissions that grant a rule to a user. The rule is granted on every resource
+// when one of the permissions has no context, otherwise on the resources that match the constraints of one of them.
+type Policy struct {
+	unrestricted bool
+	constraints  []Constraints
+}
+
+// GetPolicy returns the policy of the privileges that grant one of the requested rules, false is returned when none
+// of the privileges grants them. attributeNames are the attributes of the resources of the rules. A context is only
+// taken as constraints when it names at least one of them, other contexts are used by the services for other purposes,
+// e.g. the permissions=<...> context of the KBS SKC key transfer, and grant the rule on every resource as they did
+// before constraints were introduced.
+func GetPolicy(privileges []types.PermissionInfo, reqPermissions types.PermissionInfo, attributeNames []string) (*Policy, bool) {
+	policy := &Policy{}
+	found := false
+	for _, permission := range privileges {
+		if reqPermissions.Service != permission.Service || !grantsAny(permission.Rules, reqPermissions.Rules) {
+			continue
+		}
+		found = true
+		constraints, err := ParseConstraints(permission.Context)
+		if err != nil || !constraints.constrainsAny(attributeNames) {
+			return &Policy{unrestricted: true}, true
+		}
+		policy.constraints = append(policy.constraints, constraints)
+	}
+	return policy, found
+}
+
+// constrainsAny tells whether one of the attributes is constrained
+func (c Constraints) constrainsAny(attributeNames []string) bool {
+	for _, attributeName := range attributeNames {
+		if _, ok := c[attributeName]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func grantsAny(rules, reqRules []string) bool {
+	for _, rule := range rules {
+		for _, reqRule := range reqRules {
+			if isAuthorized(rule, reqRule) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Unrestricted tells whether the rule is granted on every resource
+func (p *Policy) Unrestricted() bool {
+	return p.unrestricted
+}
+
+// Allows tells whether the rule is granted on a resource with the given attributes
+func (p *Policy) Allows(attributes Attributes) bool {
+	if p.unrestricted {
+		return true
+	}
+	for _, constraints := range p.constraints {
+		if constraints.Match(attributes) {
+			return true
+		}
+	}
+	return false
+}
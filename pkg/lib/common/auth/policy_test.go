@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package auth
+
+import (
+	"testing"
+
+	types "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConstraints(t *testing.T) {
+	a := assert.New(t)
+
+	constraints, err := ParseConstraints("flavorgroup=automatic, prod-*;host=edge-1")
+	a.NoError(err)
+	a.Equal(Constraints{"flavorgroup": {"automatic", "prod-*"}, "host": {"edge-1"}}, constraints)
+
+	constraints, err = ParseConstraints(" ")
+	a.NoError(err)
+	a.Empty(constraints)
+
+	for _, context := range []string{"automatic", "=automatic", "flavorgroup=", "flavorgroup=a,,b"} {
+		_, err = ParseConstraints(context)
+		a.Error(err, context)
+	}
+}
+
+var reportAttributes = []string{"host", "flavorgroup"}
+
+func TestPolicyAllows(t *testing.T) {
+	a := assert.New(t)
+
+	privileges := []types.PermissionInfo{
+		{Service: "HVS", Context: "flavorgroup=automatic", Rules: []string{"reports:retrieve:*"}},
+		{Service: "HVS", Context: "flavorgroup=prod-*;host=edge-*", Rules: []string{"reports:*:*"}},
+		{Service: "KBS", Rules: []string{"reports:retrieve:*"}},
+	}
+	policy, found := GetPolicy(privileges, types.PermissionInfo{Service: "HVS", Rules: []string{"reports:retrieve"}}, reportAttributes)
+	a.True(found)
+	a.False(policy.Unrestricted())
+	a.True(policy.Allows(Attributes{"flavorgroup": {"automatic"}}))
+	a.True(policy.Allows(Attributes{"flavorgroup": {"workload", "prod-eu"}, "host": {"edge-7"}}))
+	a.False(policy.Allows(Attributes{"flavorgroup": {"prod-eu"}, "host": {"core-1"}}))
+	a.False(policy.Allows(Attributes{"flavorgroup": {"prod-eu"}}))
+	a.False(policy.Allows(Attributes{"host": {"edge-7"}}))
+
+	policy, found = GetPolicy(privileges, types.PermissionInfo{Service: "KBS", Rules: []string{"reports:retrieve"}}, reportAttributes)
+	a.True(found)
+	a.True(policy.Unrestricted())
+	a.True(policy.Allows(nil))
+
+	_, found = GetPolicy(privileges, types.PermissionInfo{Service: "HVS", Rules: []string{"hosts:retrieve"}}, reportAttributes)
+	a.False(found)
+
+}
+
+func TestPolicyContextsWithoutConstraints(t *testing.T) {
+	a := assert.New(t)
+	keyAttributes := []string{"label", "algorithm"}
+
+	// the contexts that do not constrain an attribute of the resource grant the rule on every resource, as they did
+	// before the constraints were introduced
+	for _, context := range []string{"label", "permissions=nginx,USA"} {
+		policy, found := GetPolicy([]types.PermissionInfo{{Service: "KBS", Context: context, Rules: []string{"keys:transfer:*"}}},
+			types.PermissionInfo{Service: "KBS", Rules: []string{"keys:transfer"}}, keyAttributes)
+		a.True(found)
+		a.True(policy.Unrestricted(), context)
+		a.True(policy.Allows(Attributes{"label": {"label"}}), context)
+	}
+
+	// a context constraining an attribute of the resource also requires the other attributes it names
+	policy, found := GetPolicy([]types.PermissionInfo{{Service: "KBS", Context: "label=db-*;permissions=nginx", Rules: []string{"keys:transfer:*"}}},
+		types.PermissionInfo{Service: "KBS", Rules: []string{"keys:transfer"}}, keyAttributes)
+	a.True(found)
+	a.False(policy.Unrestricted())
+	a.False(policy.Allows(Attributes{"label": {"db-1"}}))
+}
@@ -4,6 +4,8 @@
  */
 package config
 
+import "time"
+
 type SigningCertConfig struct {
 	CertFile   string `yaml:"cert-file" mapstructure:"cert-file"`
 	KeyFile    string `yaml:"key-file" mapstructure:"key-file"`
@@ -15,6 +17,14 @@ type TLSCertConfig struct {
 	KeyFile    string `yaml:"key-file" mapstructure:"key-file"`
 	CommonName string `yaml:"common-name" mapstructure:"common-name"`
 	SANList    string `yaml:"san-list" mapstructure:"san-list"`
+	// RenewBefore is how long before expiry a running server renews its TLS certificate, zero disables renewal
+	RenewBefore time.Duration `yaml:"renew-before" mapstructure:"renew-before"`
+	// RenewUsername and RenewPassword are the AAS credentials used to renew the certificate, the user needs the CMS
+	// CertApprover role for the common name and SAN list of the certificate
+	RenewUsername string `yaml:"renew-username" mapstructure:"renew-username"`
+	RenewPassword string `yaml:"renew-password" mapstructure:"renew-password"`
+	// ReloadInterval is how often a running server checks its TLS certificate and key files for changes
+	ReloadInterval time.Duration `yaml:"reload-interval" mapstructure:"reload-interval"`
 }
 
 type SelfSignedCertConfig struct {
@@ -16,6 +16,7 @@ type httpContextKey string
 
 var userRoleKey = httpContextKey("userroles")
 var userPermissionKey = httpContextKey("userpermissions")
+var tokenSubjectKey = httpContextKey("tokensubject")
 
 func SetUserRoles(r *http.Request, val []types.RoleInfo) *http.Request {
 
@@ -29,6 +30,12 @@ func SetUserPermissions(r *http.Request, val []types.PermissionInfo) *http.Reque
 	return r.WithContext(ctx)
 }
 
+func SetTokenSubject(r *http.Request, val string) *http.Request {
+
+	ctx := context.WithValue(r.Context(), tokenSubjectKey, val)
+	return r.WithContext(ctx)
+}
+
 func GetUserRoles(r *http.Request) ([]types.RoleInfo, error) {
 	if rv := r.Context().Value("userroles"); rv != nil {
 		if ur, ok := rv.([]types.RoleInfo); ok {
@@ -46,3 +53,12 @@ func GetUserPermissions(r *http.Request) ([]types.PermissionInfo, error) {
 	}
 	return nil, fmt.Errorf("could not retrieve user permissions from context")
 }
+
+func GetTokenSubject(r *http.Request) (string, error) {
+	if rv := r.Context().Value(tokenSubjectKey); rv != nil {
+		if sub, ok := rv.(string); ok {
+			return sub, nil
+		}
+	}
+	return "", fmt.Errorf("could not retrieve token subject from context")
+}
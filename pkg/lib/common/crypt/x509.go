@@ -276,22 +276,27 @@ func GetCertFromPemFile(path string) (*x509.Certificate, error) {
 
 func GetSubjectCertsMapFromPemFile(path string) ([]x509.Certificate, error) {
 	log.Debugf("crypt/x509:GetSubjectCertsMapFromPemFile() Loading certificates from  %s", path)
-	var certificates []x509.Certificate
 	certsBytes, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	return GetSubjectCertsMapFromPem(certsBytes)
+}
 
+// GetSubjectCertsMapFromPem returns the certificates of the PEM bytes, the certificates which can not be parsed are
+// skipped except the first one
+func GetSubjectCertsMapFromPem(certsBytes []byte) ([]x509.Certificate, error) {
+	var certificates []x509.Certificate
 	block, rest := pem.Decode(certsBytes)
 	if block == nil {
 		return nil, fmt.Errorf("Unable to decode pem bytes")
 	}
 	certAuth, err := x509.ParseCertificate(block.Bytes)
 	if err != nil {
-		log.WithError(err).Warn("crypt/x509:GetSubjectCertsMapFromPemFile() Failed to parse certificate")
+		log.WithError(err).Warn("crypt/x509:GetSubjectCertsMapFromPem() Failed to parse certificate")
 	} else {
 		certificates = append(certificates, *certAuth)
-		log.Debugf("crypt/x509:GetSubjectCertsMapFromPemFile() CommonName %s", certAuth.Subject.CommonName)
+		log.Debugf("crypt/x509:GetSubjectCertsMapFromPem() CommonName %s", certAuth.Subject.CommonName)
 	}
 
 	// Return if no more certificates present in path file
@@ -306,11 +311,11 @@ func GetSubjectCertsMapFromPemFile(path string) ([]x509.Certificate, error) {
 		}
 		certAuth, err = x509.ParseCertificate(block.Bytes)
 		if err != nil {
-			log.WithError(err).Warn("crypt/x509:GetSubjectCertsMapFromPemFile() Failed to parse certificate")
+			log.WithError(err).Warn("crypt/x509:GetSubjectCertsMapFromPem() Failed to parse certificate")
 			continue
 		}
 		certificates = append(certificates, *certAuth)
-		log.Debugf("crypt/x509:GetSubjectCertsMapFromPemFile() CommonName %s", certAuth.Subject.CommonName)
+		log.Debugf("crypt/x509:GetSubjectCertsMapFromPem() CommonName %s", certAuth.Subject.CommonName)
 	}
 	return certificates, nil
 }
@@ -9,6 +9,7 @@ import "fmt"
 const (
 	RecordNotFound = "record not found"
 	RowsNotFound   = "no rows in result set"
+	RecordConflict = "record was updated concurrently"
 )
 
 type HandledError struct {
@@ -0,0 +1,191 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+)
+
+// JSONWebKey is a public token signing key as defined in RFC 7517. The key id is the hex encoded SHA1 of the
+// signing certificate as in the kid header of the tokens, x5c carries the signing certificate and its chain.
+type JSONWebKey struct {
+	Kty string   `json:"kty"`
+	Use string   `json:"use,omitempty"`
+	Kid string   `json:"kid,omitempty"`
+	Alg string   `json:"alg,omitempty"`
+	N   string   `json:"n,omitempty"`
+	E   string   `json:"e,omitempty"`
+	Crv string   `json:"crv,omitempty"`
+	X   string   `json:"x,omitempty"`
+	Y   string   `json:"y,omitempty"`
+	X5c []string `json:"x5c,omitempty"`
+}
+
+// JSONWebKeySet is the JWK set of RFC 7517 section 5
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// NewJSONWebKey returns the JWK of the public key of a token signing certificate
+func NewJSONWebKey(cert *x509.Certificate, chain ...*x509.Certificate) (*JSONWebKey, error) {
+	kid, err := crypt.GetCertHashInHex(cert, crypto.SHA1)
+	if err != nil {
+		return nil, err
+	}
+	alg, err := SigningAlgorithm(cert.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	jwk := &JSONWebKey{Use: "sig", Kid: kid, Alg: alg}
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	case *ecdsa.PublicKey:
+		jwk.Kty = "EC"
+		jwk.Crv = key.Curve.Params().Name
+		// the coordinates are padded to the size of the curve
+		size := (key.Curve.Params().BitSize + 7) / 8
+		jwk.X = base64.RawURLEncoding.EncodeToString(leftPad(key.X.Bytes(), size))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(leftPad(key.Y.Bytes(), size))
+	}
+	for _, c := range append([]*x509.Certificate{cert}, chain...) {
+		jwk.X5c = append(jwk.X5c, base64.StdEncoding.EncodeToString(c.Raw))
+	}
+	return jwk, nil
+}
+
+// NewJSONWebKeySet returns the JWK set of token signing certificates. Each PEM holds a signing certificate followed by
+// its chain, blocks that are not certificates are skipped.
+func NewJSONWebKeySet(certPems ...[]byte) (*JSONWebKeySet, error) {
+	jwks := &JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, certPem := range certPems {
+		var certs []*x509.Certificate
+		for block, rest := pem.Decode(certPem); block != nil; block, rest = pem.Decode(rest) {
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse token signing certificate: %v", err)
+			}
+			certs = append(certs, cert)
+		}
+		if len(certs) == 0 {
+			continue
+		}
+		jwk, err := NewJSONWebKey(certs[0], certs[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		jwks.Keys = append(jwks.Keys, *jwk)
+	}
+	return jwks, nil
+}
+
+func leftPad(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	return append(make([]byte, size-len(b)), b...)
+}
+
+// SigningAlgorithm returns the JWS algorithm of the tokens signed with the private key of the public key
+func SigningAlgorithm(publicKey crypto.PublicKey) (string, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return "RS384", nil
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return "ES256", nil
+		case elliptic.P384():
+			return "ES384", nil
+		}
+		return "", fmt.Errorf("unsupported elliptic curve %s for JWT signing", key.Curve.Params().Name)
+	default:
+		return "", fmt.Errorf("unsupported key type for JWT signing. only RSA and ECDSA supported")
+	}
+}
+
+// Certificates returns the certificates of the x5c parameter, the first one is the certificate of the key
+func (k *JSONWebKey) Certificates() ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for _, encodedCert := range k.X5c {
+		der, err := base64.StdEncoding.DecodeString(encodedCert)
+		if err != nil {
+			return nil, fmt.Errorf("x5c of JWK %s is not base64 encoded: %v", k.Kid, err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("x5c of JWK %s is not a certificate: %v", k.Kid, err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// PublicKey returns the public key of the key parameters of the JWK
+func (k *JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	decode := func(param, value string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(b) == 0 {
+			return nil, fmt.Errorf("invalid %s parameter of JWK %s", param, k.Kid)
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode("n", k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode("e", k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid e parameter of JWK %s", k.Kid)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s of JWK %s", k.Crv, k.Kid)
+		}
+		x, err := decode("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode("y", k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("the point of JWK %s is not on curve %s", k.Kid, k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s of JWK %s", k.Kty, k.Kid)
+	}
+}
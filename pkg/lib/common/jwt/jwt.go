@@ -14,6 +14,7 @@ import (
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
 	"strings"
 	"time"
@@ -97,6 +98,30 @@ func (t *Token) GetStandardClaims() interface{} {
 	return t.standardClaims
 }
 
+// GetSubject returns the subject (sub) claim of the token
+func (t *Token) GetSubject() string {
+	if t == nil || t.standardClaims == nil {
+		return ""
+	}
+	return t.standardClaims.Subject
+}
+
+// GetId returns the unique identifier (jti) claim of the token
+func (t *Token) GetId() string {
+	if t == nil || t.standardClaims == nil {
+		return ""
+	}
+	return t.standardClaims.Id
+}
+
+// GetIssuedAt returns the time the token was issued at (iat)
+func (t *Token) GetIssuedAt() time.Time {
+	if t == nil || t.standardClaims == nil {
+		return time.Time{}
+	}
+	return time.Unix(t.standardClaims.IssuedAt, 0)
+}
+
 func (t *Token) GetHeader() *map[string]interface{} {
 	if t.jwtToken == nil {
 		return nil
@@ -217,6 +242,8 @@ func (f *JwtFactory) Create(clms interface{}, subject string, validity time.Dura
 	jwtclaim.StandardClaims.ExpiresAt = now.Add(validity).Unix()
 	jwtclaim.StandardClaims.Issuer = f.issuer
 	jwtclaim.StandardClaims.Subject = subject
+	// the token id allows the token to be revoked before it expires
+	jwtclaim.StandardClaims.Id = uuid.New().String()
 
 	jwtclaim.customClaims = clms
 	token := jwt.NewWithClaims(f.signingMethod, jwtclaim)
@@ -227,6 +254,19 @@ func (f *JwtFactory) Create(clms interface{}, subject string, validity time.Dura
 
 }
 
+// ParseToken parses the claims of a token created by the factory. An error is returned when the token is not signed
+// with the key of the factory or its claims are not valid, e.g. because it has expired.
+func (f *JwtFactory) ParseToken(tokenString string, clms jwt.Claims) error {
+	_, err := jwt.ParseWithClaims(tokenString, clms, func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != f.signingMethod.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s in token", token.Method.Alg())
+		}
+		// the key is either an RSA or an ECDSA key, see getJwtSigningMethod
+		return f.privKey.(crypto.Signer).Public(), nil
+	})
+	return err
+}
+
 //TODO: move to common crypto
 
 //TODO - implement this to parse the claims
@@ -305,6 +345,7 @@ func NewVerifier(signingCertPems interface{}, rootCAPems [][]byte, cacheTime tim
 	v.pubKeyMap = make(map[string]verifierKey)
 
 	var certPemSlice [][]byte
+	var jwks *JSONWebKeySet
 
 	switch signingCertPems.(type) {
 	case nil:
@@ -313,8 +354,13 @@ func NewVerifier(signingCertPems interface{}, rootCAPems [][]byte, cacheTime tim
 		certPemSlice = signingCertPems.([][]byte)
 	case []byte:
 		certPemSlice = [][]byte{signingCertPems.([]byte)}
+	case JSONWebKeySet:
+		keySet := signingCertPems.(JSONWebKeySet)
+		jwks = &keySet
+	case *JSONWebKeySet:
+		jwks = signingCertPems.(*JSONWebKeySet)
 	default:
-		return nil, fmt.Errorf("signingCertPems has to be of type []byte or [][]byte")
+		return nil, fmt.Errorf("signingCertPems has to be of type []byte, [][]byte or JSONWebKeySet")
 
 	}
 	// build the trust root CAs first
@@ -363,7 +409,76 @@ func NewVerifier(signingCertPems interface{}, rootCAPems [][]byte, cacheTime tim
 			v.expiration = cert.NotAfter
 		}
 	}
+	if jwks != nil {
+		v.addJSONWebKeys(jwks, roots)
+	}
 	// we will return a valid object at this point.. it still might not contain any valid certificates
 	return &v, nil
 
 }
+
+// NewVerifierWithJwks returns a verifier that accepts the tokens signed by the certificates in signingCertPems and by
+// the keys of jwks. The signing certificates are installed locally, the keys of the JWK set are only accepted with
+// a certificate chain in x5c that verifies against rootCAPems.
+func NewVerifierWithJwks(signingCertPems [][]byte, jwks *JSONWebKeySet, rootCAPems [][]byte, cacheTime time.Duration) (Verifier, error) {
+	verifier, err := NewVerifier(signingCertPems, rootCAPems, cacheTime)
+	if err != nil || jwks == nil {
+		return verifier, err
+	}
+	roots := x509.NewCertPool()
+	for _, rootPEM := range rootCAPems {
+		roots.AppendCertsFromPEM(rootPEM)
+	}
+	verifier.(*verifierPrivate).addJSONWebKeys(jwks, roots)
+	return verifier, nil
+}
+
+// addJSONWebKeys adds the signing keys of a JWK set. Only keys with a certificate chain in x5c that verifies against
+// the trusted root CAs are added, the key set is retrieved over the network and a key without a verified chain
+// could have been put in by anyone between the issuer and the verifier. The keys are identified by the kid of the
+// JWK, so that the issuer can rotate its signing key by publishing the new key before it signs tokens with it.
+func (v *verifierPrivate) addJSONWebKeys(jwks *JSONWebKeySet, roots *x509.CertPool) {
+	for i := range jwks.Keys {
+		jwk := &jwks.Keys[i]
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		certs, err := jwk.Certificates()
+		if err != nil || len(certs) == 0 {
+			continue
+		}
+
+		cert := certs[0]
+		if time.Now().After(cert.NotAfter) {
+			continue
+		}
+		intermediates := x509.NewCertPool()
+		for _, c := range certs[1:] {
+			intermediates.AddCert(c)
+		}
+		if _, err := cert.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates}); err != nil {
+			continue
+		}
+		// the key parameters are optional when x5c is present, they must match the certificate otherwise
+		if jwk.Kty != "" {
+			pubKey, err := jwk.PublicKey()
+			if err != nil {
+				continue
+			}
+			if certKey, ok := cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool }); !ok || !certKey.Equal(pubKey) {
+				continue
+			}
+		}
+
+		kid := jwk.Kid
+		if kid == "" {
+			if kid, err = crypt.GetCertHashInHex(cert, crypto.SHA1); err != nil {
+				continue
+			}
+		}
+		v.pubKeyMap[kid] = verifierKey{pubKey: cert.PublicKey, expTime: cert.NotAfter}
+		if v.expiration.After(cert.NotAfter) {
+			v.expiration = cert.NotAfter
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
+	cos "github.com/intel-secl/intel-secl/v3/pkg/lib/common/os"
+	ct "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/pkg/errors"
+)
+
+// RetrieveJwksFn returns the JWK set of the token signing keys of AAS
+type RetrieveJwksFn func() (*jwtauth.JSONWebKeySet, error)
+
+// jwksMinRetrievalInterval keeps the tokens with an unknown kid from retrieving the JWK set on every request
+const jwksMinRetrievalInterval = 10 * time.Second
+
+type jwksVerifierCache struct {
+	sync.RWMutex
+	// retrieval serializes the retrievals of the JWK set, the requests that can be validated with the cached
+	// verifier are not blocked meanwhile
+	retrieval       sync.Mutex
+	fnGetJwks       RetrieveJwksFn
+	signingCertsDir string
+	trustedCAsDir   string
+	cacheTime       time.Duration
+	verifier        jwtauth.Verifier
+	retrievedAt     time.Time
+}
+
+// NewTokenAuthWithJwks returns the token authentication middleware that validates the tokens with the signing
+// certificates in signingCertsDir and the keys of the JWK set returned by fnGetJwks. Only the keys with a certificate
+// chain in x5c that verifies against the certificates in trustedCAsDir are used. The JWK set is retrieved again once
+// the verifier is older than cacheTime or a token is signed by an unknown key. The tokens for which fnIsRevoked
+// returns true are rejected.
+func NewTokenAuthWithJwks(signingCertsDir, trustedCAsDir string, fnGetJwks RetrieveJwksFn, cacheTime time.Duration,
+	fnIsRevoked TokenRevokedFn) mux.MiddlewareFunc {
+	cache := &jwksVerifierCache{
+		fnGetJwks:       fnGetJwks,
+		signingCertsDir: signingCertsDir,
+		trustedCAsDir:   trustedCAsDir,
+		cacheTime:       cacheTime,
+	}
+	return newTokenAuth(cache.validate, fnIsRevoked)
+}
+
+func (c *jwksVerifierCache) current() (jwtauth.Verifier, time.Time) {
+	c.RLock()
+	defer c.RUnlock()
+	return c.verifier, c.retrievedAt
+}
+
+// refresh builds the verifier from the JWK set unless it was built again after since. Unless force is set, the
+// verifier is not built again within jwksMinRetrievalInterval or the cache time when that is shorter.
+func (c *jwksVerifierCache) refresh(since time.Time, force bool) (jwtauth.Verifier, error) {
+	c.retrieval.Lock()
+	defer c.retrieval.Unlock()
+
+	minInterval := jwksMinRetrievalInterval
+	if c.cacheTime < minInterval {
+		minInterval = c.cacheTime
+	}
+	verifier, retrievedAt := c.current()
+	if verifier != nil && (retrievedAt.After(since) || (!force && time.Since(retrievedAt) < minInterval)) {
+		return verifier, nil
+	}
+
+	rootPems, err := cos.GetDirFileContents(c.trustedCAsDir, "*.pem")
+	if err != nil {
+		return nil, errors.Wrap(err, "middleware/jwks:refresh() Could not read trusted CA certificates")
+	}
+	certPems, err := cos.GetDirFileContents(c.signingCertsDir, "*.pem")
+	if err != nil {
+		log.WithError(err).Warn("middleware/jwks:refresh() Could not read the installed token signing certificates")
+	}
+	jwks, err := c.fnGetJwks()
+	if err != nil {
+		log.WithError(err).Warn("middleware/jwks:refresh() Failed to retrieve the JWK set, " +
+			"only the installed token signing certificates are used")
+	}
+	verifier, err = jwtauth.NewVerifierWithJwks(certPems, jwks, rootPems, c.cacheTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "middleware/jwks:refresh() Could not create token verifier")
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	c.verifier = verifier
+	c.retrievedAt = time.Now()
+	return verifier, nil
+}
+
+func (c *jwksVerifierCache) validate(tokenString string, claims *ct.AuthClaims) (*jwtauth.Token, int, error) {
+	verifier, retrievedAt := c.current()
+	var err error
+	if verifier == nil {
+		if verifier, err = c.refresh(retrievedAt, true); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+
+	token, err := verifier.ValidateTokenAndGetClaims(tokenString, claims)
+	if err == nil {
+		return token, http.StatusOK, nil
+	}
+	// the verifier is built again when it expired or the token is signed by a key that was not published yet when
+	// the JWK set was retrieved, the token is validated once more then
+	switch err.(type) {
+	case *jwtauth.VerifierExpiredError:
+		verifier, err = c.refresh(retrievedAt, true)
+	case *jwtauth.MatchingCertNotFoundError, *jwtauth.MatchingCertJustExpired:
+		verifier, err = c.refresh(retrievedAt, false)
+	default:
+		return nil, http.StatusUnauthorized, err
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	token, err = verifier.ValidateTokenAndGetClaims(tokenString, claims)
+	if err != nil {
+		return nil, http.StatusUnauthorized, err
+	}
+	return token, http.StatusOK, nil
+}
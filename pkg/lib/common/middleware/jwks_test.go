@@ -0,0 +1,195 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSigner struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestSigner(t *testing.T, commonName string, issuer *testSigner) *testSigner {
+	assertions := assert.New(t)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assertions.NoError(err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	parent, parentKey := template, key
+	if issuer == nil {
+		template.KeyUsage |= x509.KeyUsageCertSign
+		template.BasicConstraintsValid = true
+		template.IsCA = true
+	} else {
+		parent, parentKey = issuer.cert, issuer.key
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assertions.NoError(err)
+	cert, err := x509.ParseCertificate(certDer)
+	assertions.NoError(err)
+	return &testSigner{cert: cert, key: key}
+}
+
+func (s *testSigner) pem() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.cert.Raw})
+}
+
+func (s *testSigner) token(t *testing.T) string {
+	assertions := assert.New(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(s.key)
+	assertions.NoError(err)
+	factory, err := jwtauth.NewTokenFactory(pkcs8, true, s.pem(), "AAS JWT Issuer", time.Hour)
+	assertions.NoError(err)
+	token, err := factory.Create(&testClaims{Roles: []string{"Administrator"}}, "admin", time.Hour)
+	assertions.NoError(err)
+	return token
+}
+
+func newTestJwks(t *testing.T, signer *testSigner, chain ...*testSigner) *jwtauth.JSONWebKeySet {
+	certPem := signer.pem()
+	for _, c := range chain {
+		certPem = append(certPem, c.pem()...)
+	}
+	jwks, err := jwtauth.NewJSONWebKeySet(certPem)
+	assert.NoError(t, err)
+	return jwks
+}
+
+// newJwksTokenAuth returns a handler behind the JWK set token authentication with ca as the trusted CA and
+// installedCerts as the installed token signing certificates
+func newJwksTokenAuth(t *testing.T, ca *testSigner, fnGetJwks RetrieveJwksFn, cacheTime time.Duration,
+	installedCerts ...*testSigner) (http.Handler, func()) {
+	assertions := assert.New(t)
+	dir, err := ioutil.TempDir("", "jwks")
+	assertions.NoError(err)
+	trustedCAsDir := filepath.Join(dir, "trusted-ca")
+	signingCertsDir := filepath.Join(dir, "trusted-jwt")
+	assertions.NoError(os.Mkdir(trustedCAsDir, 0755))
+	assertions.NoError(os.Mkdir(signingCertsDir, 0755))
+	assertions.NoError(ioutil.WriteFile(filepath.Join(trustedCAsDir, "ca.pem"), ca.pem(), 0644))
+	for i, cert := range installedCerts {
+		assertions.NoError(ioutil.WriteFile(filepath.Join(signingCertsDir, string(rune('a'+i))+".pem"), cert.pem(), 0644))
+	}
+
+	handler := NewTokenAuthWithJwks(signingCertsDir, trustedCAsDir, fnGetJwks, cacheTime, nil)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	return handler, func() { os.RemoveAll(dir) }
+}
+
+func authenticate(handler http.Handler, token string) int {
+	request := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder.Code
+}
+
+func TestTokenAuthWithJwks(t *testing.T) {
+	assertions := assert.New(t)
+	ca := newTestSigner(t, "CMS Signing CA", nil)
+	signer := newTestSigner(t, "AAS JWT Signing Certificate", ca)
+
+	handler, cleanup := newJwksTokenAuth(t, ca, func() (*jwtauth.JSONWebKeySet, error) {
+		return newTestJwks(t, signer, ca), nil
+	}, time.Hour)
+	defer cleanup()
+	assertions.Equal(http.StatusOK, authenticate(handler, signer.token(t)))
+
+	// keys that are not in the JWK set are rejected
+	other := newTestSigner(t, "AAS JWT Signing Certificate", ca)
+	assertions.Equal(http.StatusUnauthorized, authenticate(handler, other.token(t)))
+}
+
+func TestTokenAuthWithJwksRequiresX5cChain(t *testing.T) {
+	assertions := assert.New(t)
+	ca := newTestSigner(t, "CMS Signing CA", nil)
+	signer := newTestSigner(t, "AAS JWT Signing Certificate", ca)
+	selfSigned := newTestSigner(t, "AAS JWT Signing Certificate", nil)
+
+	handler, cleanup := newJwksTokenAuth(t, ca, func() (*jwtauth.JSONWebKeySet, error) {
+		jwks := newTestJwks(t, signer, ca)
+		// the key parameters are published without the certificate chain
+		jwks.Keys[0].X5c = nil
+		// a self signed certificate does not chain to the trusted CA
+		jwks.Keys = append(jwks.Keys, newTestJwks(t, selfSigned).Keys...)
+		return jwks, nil
+	}, time.Hour)
+	defer cleanup()
+	assertions.Equal(http.StatusUnauthorized, authenticate(handler, signer.token(t)))
+	assertions.Equal(http.StatusUnauthorized, authenticate(handler, selfSigned.token(t)))
+}
+
+func TestTokenAuthWithJwksRetrieval(t *testing.T) {
+	assertions := assert.New(t)
+	ca := newTestSigner(t, "CMS Signing CA", nil)
+	current := newTestSigner(t, "AAS JWT Signing Certificate", ca)
+	next := newTestSigner(t, "AAS JWT Signing Certificate", ca)
+	installed := newTestSigner(t, "CMS JWT Signing Certificate", nil)
+
+	var lock sync.Mutex
+	var jwks *jwtauth.JSONWebKeySet
+	var jwksErr error
+	retrievals := 0
+	handler, cleanup := newJwksTokenAuth(t, ca, func() (*jwtauth.JSONWebKeySet, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		retrievals++
+		return jwks, jwksErr
+	}, 100*time.Millisecond, installed)
+	defer cleanup()
+
+	// the installed signing certificates are used when the JWK set can not be retrieved
+	lock.Lock()
+	jwksErr = http.ErrHandlerTimeout
+	lock.Unlock()
+	assertions.Equal(http.StatusOK, authenticate(handler, installed.token(t)))
+	assertions.Equal(http.StatusUnauthorized, authenticate(handler, current.token(t)))
+
+	// the JWK set is retrieved again once the verifier expired, the rotated key is accepted then
+	lock.Lock()
+	jwks, jwksErr = newTestJwks(t, current, ca), nil
+	jwks.Keys = append(jwks.Keys, newTestJwks(t, next, ca).Keys...)
+	lock.Unlock()
+	time.Sleep(150 * time.Millisecond)
+	assertions.Equal(http.StatusOK, authenticate(handler, next.token(t)))
+	assertions.Equal(http.StatusOK, authenticate(handler, current.token(t)))
+	assertions.Equal(http.StatusOK, authenticate(handler, installed.token(t)))
+
+	// tokens with an unknown key do not retrieve the JWK set on every request
+	unknownToken := newTestSigner(t, "AAS JWT Signing Certificate", ca).token(t)
+	lock.Lock()
+	retrievalsBefore := retrievals
+	lock.Unlock()
+	for i := 0; i < 5; i++ {
+		assertions.Equal(http.StatusUnauthorized, authenticate(handler, unknownToken))
+	}
+	lock.Lock()
+	assertions.Equal(retrievalsBefore, retrievals)
+	lock.Unlock()
+}
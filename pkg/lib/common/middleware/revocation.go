@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
+	ct "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+)
+
+// RetrieveRevocationListFn returns the current token revocation list of AAS
+type RetrieveRevocationListFn func() (*ct.RevocationList, error)
+
+type revocationListCache struct {
+	sync.RWMutex
+	fnGetRevocationList RetrieveRevocationListFn
+	cacheTime           time.Duration
+	maxStaleness        time.Duration
+	// refreshing is set while the list is retrieved, the lock is not held meanwhile so that a slow AAS does not
+	// block the requests that can be checked against the cached list
+	refreshing  bool
+	attemptedAt time.Time
+	retrievedAt time.Time
+	tokenIDs    map[string]struct{}
+	subjects    map[string]time.Time
+	// firstAttempt is closed once the list was retrieved or failed to be retrieved for the first time
+	firstAttempt     chan struct{}
+	firstAttemptOnce sync.Once
+}
+
+// NewRevocationListCache returns a TokenRevokedFn that checks tokens against the revocation list returned by
+// fnGetRevocationList. The list is retrieved again once it is older than cacheTime, the last retrieved list keeps
+// being used when it can not be retrieved. Every token is rejected once the last retrieved list is older than
+// maxStaleness, as revoked tokens can no longer be told apart.
+func NewRevocationListCache(fnGetRevocationList RetrieveRevocationListFn, cacheTime, maxStaleness time.Duration) TokenRevokedFn {
+	cache := &revocationListCache{
+		fnGetRevocationList: fnGetRevocationList,
+		cacheTime:           cacheTime,
+		maxStaleness:        maxStaleness,
+		tokenIDs:            map[string]struct{}{},
+		subjects:            map[string]time.Time{},
+		firstAttempt:        make(chan struct{}),
+	}
+	// the list is retrieved ahead of the first request
+	go cache.refresh()
+	return cache.isRevoked
+}
+
+// refresh retrieves the revocation list when the cache time elapsed since the last attempt, unless another request
+// is retrieving it already. A failed attempt is not retried before the cache time elapsed so that an unavailable AAS
+// does not slow down every request.
+func (c *revocationListCache) refresh() {
+	c.Lock()
+	now := time.Now()
+	if c.refreshing || (!c.attemptedAt.IsZero() && now.Sub(c.attemptedAt) <= c.cacheTime) {
+		c.Unlock()
+		return
+	}
+	c.refreshing = true
+	c.attemptedAt = now
+	c.Unlock()
+
+	revocationList, err := c.fnGetRevocationList()
+
+	c.Lock()
+	defer c.Unlock()
+	defer c.firstAttemptOnce.Do(func() { close(c.firstAttempt) })
+	c.refreshing = false
+	if err != nil {
+		log.WithError(err).Error("middleware/revocation:refresh() Failed to retrieve the token revocation list, " +
+			"using the previously retrieved list")
+		return
+	}
+	c.tokenIDs = map[string]struct{}{}
+	c.subjects = map[string]time.Time{}
+	for _, revoked := range revocationList.RevokedTokens {
+		if revoked.TokenID != "" {
+			c.tokenIDs[revoked.TokenID] = struct{}{}
+		} else if revokedAt, ok := c.subjects[revoked.SubjectHash]; !ok || revoked.RevokedAt.After(revokedAt) {
+			c.subjects[revoked.SubjectHash] = revoked.RevokedAt
+		}
+	}
+	c.retrievedAt = now
+}
+
+func (c *revocationListCache) isRevoked(token *jwtauth.Token) bool {
+	c.refresh()
+	<-c.firstAttempt
+
+	c.RLock()
+	defer c.RUnlock()
+
+	if time.Since(c.retrievedAt) > c.maxStaleness {
+		log.Errorf("middleware/revocation:isRevoked() The token revocation list has not been retrieved since %s, "+
+			"rejecting the token", c.retrievedAt.Format(time.RFC3339))
+		return true
+	}
+	if tokenID := token.GetId(); tokenID != "" {
+		if _, revoked := c.tokenIDs[tokenID]; revoked {
+			return true
+		}
+	}
+	// revoked_at is a whole second, the tokens issued in the second before it were issued before the revocation
+	if revokedAt, ok := c.subjects[ct.SubjectHash(token.GetSubject())]; ok && token.GetIssuedAt().Before(revokedAt) {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	jwtauth "github.com/intel-secl/intel-secl/v3/pkg/lib/common/jwt"
+	ct "github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type testClaims struct {
+	Roles []string `json:"roles"`
+}
+
+// newTestToken returns a validated token issued to subject
+func newTestToken(t *testing.T, subject string) *jwtauth.Token {
+	assertions := assert.New(t)
+	signingKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assertions.NoError(err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "AAS JWT Signing Certificate"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, template, template, &signingKey.PublicKey, signingKey)
+	assertions.NoError(err)
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer})
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(signingKey)
+	assertions.NoError(err)
+
+	factory, err := jwtauth.NewTokenFactory(pkcs8, true, certPem, "AAS JWT Issuer", time.Hour)
+	assertions.NoError(err)
+	tokenString, err := factory.Create(&testClaims{Roles: []string{"Administrator"}}, subject, time.Hour)
+	assertions.NoError(err)
+	verifier, err := jwtauth.NewVerifier(certPem, nil, time.Hour)
+	assertions.NoError(err)
+	token, err := verifier.ValidateTokenAndGetClaims(tokenString, &testClaims{})
+	assertions.NoError(err)
+	return token
+}
+
+func TestRevocationListCache(t *testing.T) {
+	assertions := assert.New(t)
+	token := newTestToken(t, "admin")
+	otherToken := newTestToken(t, "operator")
+
+	revocationList := &ct.RevocationList{RevokedTokens: []ct.RevokedToken{
+		{TokenID: otherToken.GetId(), RevokedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	isRevoked := NewRevocationListCache(func() (*ct.RevocationList, error) {
+		return revocationList, nil
+	}, 0, time.Minute)
+	assertions.False(isRevoked(token))
+	assertions.True(isRevoked(otherToken))
+
+	// the tokens issued in the second before revoked_at are revoked, the tokens issued in that second are not
+	revocationList.RevokedTokens[0] = ct.RevokedToken{SubjectHash: ct.SubjectHash("admin"),
+		RevokedAt: token.GetIssuedAt(), ExpiresAt: time.Now().Add(time.Hour)}
+	assertions.False(isRevoked(token))
+	revocationList.RevokedTokens[0].RevokedAt = token.GetIssuedAt().Add(time.Second)
+	assertions.True(isRevoked(token))
+	assertions.False(isRevoked(otherToken))
+}
+
+func TestRevocationListCacheMaxStaleness(t *testing.T) {
+	assertions := assert.New(t)
+	token := newTestToken(t, "admin")
+
+	var lock sync.Mutex
+	fail := false
+	isRevoked := NewRevocationListCache(func() (*ct.RevocationList, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if fail {
+			return nil, errors.New("AAS is not available")
+		}
+		return &ct.RevocationList{}, nil
+	}, 0, 100*time.Millisecond)
+	assertions.False(isRevoked(token))
+
+	// the previously retrieved list is used until it is older than the max staleness
+	lock.Lock()
+	fail = true
+	lock.Unlock()
+	assertions.False(isRevoked(token))
+	time.Sleep(150 * time.Millisecond)
+	assertions.True(isRevoked(token), "tokens must be rejected once the list is stale")
+}
+
+func TestRevocationListCacheRetrievalDoesNotBlock(t *testing.T) {
+	assertions := assert.New(t)
+	token := newTestToken(t, "admin")
+
+	retrieving := make(chan struct{}, 1)
+	release := make(chan struct{})
+	first := true
+	isRevoked := NewRevocationListCache(func() (*ct.RevocationList, error) {
+		if !first {
+			retrieving <- struct{}{}
+			<-release
+		}
+		first = false
+		return &ct.RevocationList{}, nil
+	}, time.Millisecond, time.Minute)
+	assertions.False(isRevoked(token))
+	time.Sleep(10 * time.Millisecond)
+
+	// a request retrieves the list again, the other requests are checked against the cached list meanwhile
+	done := make(chan bool)
+	go func() { done <- isRevoked(token) }()
+	<-retrieving
+	assertions.False(isRevoked(token))
+	close(release)
+	assertions.False(<-done)
+}
@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 )
 
 var jwtVerifier jwtauth.Verifier
@@ -50,7 +51,62 @@ func retrieveAndSaveTrustedJwtSigningCerts() error {
 
 type RetriveJwtCertFn func() error
 
+// TokenRevokedFn returns true when the validated token has been revoked
+type TokenRevokedFn func(token *jwtauth.Token) bool
+
 func NewTokenAuth(signingCertsDir, trustedCAsDir string, fnGetJwtCerts RetriveJwtCertFn, cacheTime time.Duration) mux.MiddlewareFunc {
+	return NewTokenAuthWithRevocationCheck(signingCertsDir, trustedCAsDir, fnGetJwtCerts, cacheTime, nil)
+}
+
+// NewTokenAuthWithRevocationCheck returns the token authentication middleware that additionally rejects the tokens
+// for which fnIsRevoked returns true
+func NewTokenAuthWithRevocationCheck(signingCertsDir, trustedCAsDir string, fnGetJwtCerts RetriveJwtCertFn, cacheTime time.Duration,
+	fnIsRevoked TokenRevokedFn) mux.MiddlewareFunc {
+	return newTokenAuth(func(tokenString string, claims *ct.AuthClaims) (*jwtauth.Token, int, error) {
+		var token *jwtauth.Token
+		var err error
+
+		// There are two scenarios when we retry the ValidateTokenAndClaims.
+		//     1. The cached verifier has expired - could be because the certificate we are using has just expired
+		//        or the time has reached when we want to look at the CRL list to make sure the certificate is still
+		//        valid.
+		//        Error : VerifierExpiredError
+		//     2. There are no valid certificates (maybe all are expired) and we need to call the function that retrives
+		//        a new certificate. initJwtVerifier takes care of this scenario.
+
+		for needInit, retryNeeded, looped := jwtVerifier == nil, false, false; retryNeeded || !looped; looped = true {
+
+			if needInit || retryNeeded {
+				if initErr := initJwtVerifier(signingCertsDir, trustedCAsDir, cacheTime); initErr != nil {
+					return nil, http.StatusInternalServerError, errors.Wrap(initErr, "attempt to initialize jwt verifier failed")
+				}
+				needInit = false
+			}
+			retryNeeded = false
+			token, err = jwtVerifier.ValidateTokenAndGetClaims(tokenString, claims)
+			if err != nil && !looped {
+				switch err.(type) {
+				case *jwtauth.MatchingCertNotFoundError, *jwtauth.MatchingCertJustExpired:
+					err = fnGetJwtCerts()
+					if err != nil {
+						log.WithError(err).Error("failed to get jwt certificate")
+					}
+					retryNeeded = true
+				case *jwtauth.VerifierExpiredError:
+					retryNeeded = true
+				}
+
+			}
+
+		}
+		return token, http.StatusUnauthorized, err
+	}, fnIsRevoked)
+}
+
+// tokenValidatorFn validates the bearer token and fills in its claims, the status code is returned with the error
+type tokenValidatorFn func(tokenString string, claims *ct.AuthClaims) (*jwtauth.Token, int, error)
+
+func newTokenAuth(validate tokenValidatorFn, fnIsRevoked TokenRevokedFn) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -66,45 +122,13 @@ func NewTokenAuth(signingCertsDir, trustedCAsDir string, fnGetJwtCerts RetriveJw
 
 			// the second item in the slice should be the jwtToken. let try to validate
 			claims := ct.AuthClaims{}
-			var err error
-
-			// There are two scenarios when we retry the ValidateTokenAndClaims.
-			//     1. The cached verifier has expired - could be because the certificate we are using has just expired
-			//        or the time has reached when we want to look at the CRL list to make sure the certificate is still
-			//        valid.
-			//        Error : VerifierExpiredError
-			//     2. There are no valid certificates (maybe all are expired) and we need to call the function that retrives
-			//        a new certificate. initJwtVerifier takes care of this scenario.
-
-			for needInit, retryNeeded, looped := jwtVerifier == nil, false, false; retryNeeded || !looped; looped = true {
-
-				if needInit || retryNeeded {
-					if initErr := initJwtVerifier(signingCertsDir, trustedCAsDir, cacheTime); initErr != nil {
-						log.WithError(initErr).Error("attempt to initialize jwt verifier failed")
-						w.WriteHeader(http.StatusInternalServerError)
-						return
-					}
-					needInit = false
-				}
-				retryNeeded = false
-				_, err = jwtVerifier.ValidateTokenAndGetClaims(strings.TrimSpace(splitAuthHeader[1]), &claims)
-				if err != nil && !looped {
-					switch err.(type) {
-					case *jwtauth.MatchingCertNotFoundError, *jwtauth.MatchingCertJustExpired:
-						err = fnGetJwtCerts()
-						if err != nil {
-							log.WithError(err).Error("failed to get jwt certificate")
-						}
-						retryNeeded = true
-					case *jwtauth.VerifierExpiredError:
-						retryNeeded = true
-					}
-
-				}
-
-			}
-
+			token, status, err := validate(strings.TrimSpace(splitAuthHeader[1]), &claims)
 			if err != nil {
+				if status == http.StatusInternalServerError {
+					log.WithError(err).Error("token validation could not be performed")
+					w.WriteHeader(status)
+					return
+				}
 				// this is a validation failure. Let us log the message and return unauthorized
 				log.WithError(err).Error("token validation Failure")
 				w.WriteHeader(http.StatusUnauthorized)
@@ -112,8 +136,16 @@ func NewTokenAuth(signingCertsDir, trustedCAsDir string, fnGetJwtCerts RetriveJw
 				return
 			}
 
+			if fnIsRevoked != nil && fnIsRevoked(token) {
+				log.Error("token has been revoked")
+				w.WriteHeader(http.StatusUnauthorized)
+				slog.Warningf("%s: Revoked token, requested from %s: ", commLogMsg.AuthenticationFailed, r.RemoteAddr)
+				return
+			}
+
 			r = context.SetUserRoles(r, claims.Roles)
 			r = context.SetUserPermissions(r, claims.Permissions)
+			r = context.SetTokenSubject(r, token.GetSubject())
 			next.ServeHTTP(w, r)
 		})
 	}
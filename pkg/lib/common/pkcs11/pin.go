@@ -0,0 +1,46 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package pkcs11
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ReadPin returns the user PIN of a token from the environment variable pinEnv or, when it is not set, from pinFile.
+// The PIN file must only be accessible by its owner.
+func ReadPin(pinEnv, pinFile string) (string, error) {
+	if pin := os.Getenv(pinEnv); pin != "" {
+		return pin, nil
+	}
+	if pinFile == "" {
+		return "", errors.Errorf("pkcs11/pin:ReadPin() Neither %s nor a PIN file is set", pinEnv)
+	}
+	fileInfo, err := os.Stat(pinFile)
+	if err != nil {
+		return "", errors.Wrap(err, "pkcs11/pin:ReadPin() Could not read PIN file")
+	}
+	if fileInfo.Mode().Perm()&0077 != 0 {
+		return "", errors.Errorf("pkcs11/pin:ReadPin() PIN file %s must not be accessible by group or others", pinFile)
+	}
+	pin, err := ioutil.ReadFile(pinFile)
+	if err != nil {
+		return "", errors.Wrap(err, "pkcs11/pin:ReadPin() Could not read PIN file")
+	}
+	return strings.TrimRight(string(pin), "\r\n"), nil
+}
+
+// SavePin writes the user PIN of a token to pinFile, which is only accessible by its owner
+func SavePin(pinFile, pin string) error {
+	err := ioutil.WriteFile(pinFile, []byte(pin), 0600)
+	if err != nil {
+		return errors.Wrap(err, "pkcs11/pin:SavePin() Could not write PIN file")
+	}
+	// the mode is not changed by WriteFile when the file exists
+	return errors.Wrap(os.Chmod(pinFile, 0600), "pkcs11/pin:SavePin() Could not change PIN file permission")
+}
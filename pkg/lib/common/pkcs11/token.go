@@ -0,0 +1,426 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"io"
+	"math/big"
+	"sync"
+
+	p11 "github.com/miekg/pkcs11"
+	"github.com/pkg/errors"
+)
+
+// Token is a logged in session with a PKCS#11 token, in which keys are generated and used for signing without the
+// private key ever leaving the token
+type Token struct {
+	ctx     *p11.Ctx
+	session p11.SessionHandle
+	// lock serializes the use of the session, PKCS#11 sessions must not be used concurrently
+	lock sync.Mutex
+}
+
+const (
+	// newKeyLabelSuffix and oldKeyLabelSuffix mark the key pairs swapped by GenerateRsaKey
+	newKeyLabelSuffix = ".new"
+	oldKeyLabelSuffix = ".old"
+)
+
+var (
+	// modules and tokens are kept for the lifetime of the process, a PKCS#11 module can only be initialized once
+	modules    = map[string]*p11.Ctx{}
+	tokens     = map[string]*Token{}
+	tokensLock sync.Mutex
+)
+
+// OpenToken opens a session with the token with the given label in a PKCS#11 module and logs in as user. The token
+// is shared by all callers that open it.
+func OpenToken(module, tokenLabel, pin string) (*Token, error) {
+	tokensLock.Lock()
+	defer tokensLock.Unlock()
+
+	tokenId := module + ":" + tokenLabel
+	if token, ok := tokens[tokenId]; ok {
+		return token, nil
+	}
+
+	ctx, ok := modules[module]
+	if !ok {
+		ctx = p11.New(module)
+		if ctx == nil {
+			return nil, errors.Errorf("pkcs11/token:OpenToken() Could not load PKCS#11 module %s", module)
+		}
+		if err := ctx.Initialize(); err != nil {
+			ctx.Destroy()
+			return nil, errors.Wrapf(err, "pkcs11/token:OpenToken() Could not initialize PKCS#11 module %s", module)
+		}
+		modules[module] = ctx
+	}
+
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return nil, errors.Wrap(err, "pkcs11/token:OpenToken() Could not list PKCS#11 slots")
+	}
+	for _, slot := range slots {
+		tokenInfo, err := ctx.GetTokenInfo(slot)
+		if err != nil || tokenInfo.Label != tokenLabel {
+			continue
+		}
+		session, err := ctx.OpenSession(slot, p11.CKF_SERIAL_SESSION|p11.CKF_RW_SESSION)
+		if err != nil {
+			return nil, errors.Wrapf(err, "pkcs11/token:OpenToken() Could not open session with token %s", tokenLabel)
+		}
+		err = ctx.Login(session, p11.CKU_USER, pin)
+		if err != nil && err != p11.Error(p11.CKR_USER_ALREADY_LOGGED_IN) {
+			_ = ctx.CloseSession(session)
+			return nil, errors.Wrapf(err, "pkcs11/token:OpenToken() Could not log in to token %s", tokenLabel)
+		}
+		token := &Token{ctx: ctx, session: session}
+		tokens[tokenId] = token
+		return token, nil
+	}
+	return nil, errors.Errorf("pkcs11/token:OpenToken() Could not find token %s", tokenLabel)
+}
+
+// GenerateRsaKey generates a RSA key pair in the token. The private key is not extractable. Existing keys with the
+// same label are replaced once the new key pair is generated, they are kept when the generation fails.
+func (t *Token) GenerateRsaKey(label string, bits int) (crypto.Signer, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	newLabel := label + newKeyLabelSuffix
+	oldLabel := label + oldKeyLabelSuffix
+	// left overs of an interrupted generation
+	for _, staleLabel := range []string{newLabel, oldLabel} {
+		if err := t.deleteObjects(staleLabel); err != nil {
+			return nil, err
+		}
+	}
+
+	publicTemplate := []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_CLASS, p11.CKO_PUBLIC_KEY),
+		p11.NewAttribute(p11.CKA_KEY_TYPE, p11.CKK_RSA),
+		p11.NewAttribute(p11.CKA_TOKEN, true),
+		p11.NewAttribute(p11.CKA_VERIFY, true),
+		p11.NewAttribute(p11.CKA_MODULUS_BITS, bits),
+		p11.NewAttribute(p11.CKA_PUBLIC_EXPONENT, []byte{1, 0, 1}),
+		p11.NewAttribute(p11.CKA_LABEL, newLabel),
+	}
+	privateTemplate := []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_CLASS, p11.CKO_PRIVATE_KEY),
+		p11.NewAttribute(p11.CKA_KEY_TYPE, p11.CKK_RSA),
+		p11.NewAttribute(p11.CKA_TOKEN, true),
+		p11.NewAttribute(p11.CKA_PRIVATE, true),
+		p11.NewAttribute(p11.CKA_SENSITIVE, true),
+		p11.NewAttribute(p11.CKA_EXTRACTABLE, false),
+		p11.NewAttribute(p11.CKA_SIGN, true),
+		p11.NewAttribute(p11.CKA_LABEL, newLabel),
+	}
+	publicKey, privateKey, err := t.ctx.GenerateKeyPair(t.session,
+		[]*p11.Mechanism{p11.NewMechanism(p11.CKM_RSA_PKCS_KEY_PAIR_GEN, nil)}, publicTemplate, privateTemplate)
+	if err != nil {
+		return nil, errors.Wrapf(err, "pkcs11/token:GenerateRsaKey() Could not generate key %s", label)
+	}
+	signer, err := t.newSigner(privateKey, publicKey)
+	if err != nil {
+		_ = t.deleteObjects(newLabel)
+		return nil, err
+	}
+
+	// the existing key is moved aside until the new key has its label
+	if err = t.relabelObjects(label, oldLabel); err != nil {
+		_ = t.deleteObjects(newLabel)
+		return nil, err
+	}
+	if err = t.relabelObjects(newLabel, label); err != nil {
+		_ = t.relabelObjects(oldLabel, label)
+		return nil, err
+	}
+	if err = t.deleteObjects(oldLabel); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// FindKey returns a signer for the private key with the given label in the token
+func (t *Token) FindKey(label string) (crypto.Signer, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	privateKeys, err := t.findObjects(p11.CKO_PRIVATE_KEY, label)
+	if err != nil {
+		return nil, err
+	}
+	publicKeys, err := t.findObjects(p11.CKO_PUBLIC_KEY, label)
+	if err != nil {
+		return nil, err
+	}
+	if len(privateKeys) != 1 || len(publicKeys) != 1 {
+		return nil, errors.Errorf("pkcs11/token:FindKey() Expected one key pair %s in token, found %d private and %d public keys",
+			label, len(privateKeys), len(publicKeys))
+	}
+	return t.newSigner(privateKeys[0], publicKeys[0])
+}
+
+// DeleteKey removes the key pair or the secret key with the given label from the token
+func (t *Token) DeleteKey(label string) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.deleteObjects(label)
+}
+
+// GenerateAesKey generates an AES key in the token used for encryption with AES-GCM. The key is not extractable.
+func (t *Token) GenerateAesKey(label string, bits int) (*AesKey, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	existing, err := t.findObjects(p11.CKO_SECRET_KEY, label)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, errors.Errorf("pkcs11/token:GenerateAesKey() Key %s already exists in token", label)
+	}
+	template := []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_CLASS, p11.CKO_SECRET_KEY),
+		p11.NewAttribute(p11.CKA_KEY_TYPE, p11.CKK_AES),
+		p11.NewAttribute(p11.CKA_TOKEN, true),
+		p11.NewAttribute(p11.CKA_PRIVATE, true),
+		p11.NewAttribute(p11.CKA_SENSITIVE, true),
+		p11.NewAttribute(p11.CKA_EXTRACTABLE, false),
+		p11.NewAttribute(p11.CKA_ENCRYPT, true),
+		p11.NewAttribute(p11.CKA_DECRYPT, true),
+		p11.NewAttribute(p11.CKA_VALUE_LEN, bits/8),
+		p11.NewAttribute(p11.CKA_LABEL, label),
+	}
+	key, err := t.ctx.GenerateKey(t.session, []*p11.Mechanism{p11.NewMechanism(p11.CKM_AES_KEY_GEN, nil)}, template)
+	if err != nil {
+		return nil, errors.Wrapf(err, "pkcs11/token:GenerateAesKey() Could not generate key %s", label)
+	}
+	return &AesKey{token: t, key: key}, nil
+}
+
+// FindAesKey returns the AES key with the given label in the token
+func (t *Token) FindAesKey(label string) (*AesKey, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	keys, err := t.findObjects(p11.CKO_SECRET_KEY, label)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) != 1 {
+		return nil, errors.Errorf("pkcs11/token:FindAesKey() Expected one key %s in token, found %d", label, len(keys))
+	}
+	return &AesKey{token: t, key: keys[0]}, nil
+}
+
+// AesKey encrypts and decrypts with AES-GCM using an AES key in a PKCS#11 token
+type AesKey struct {
+	token *Token
+	key   p11.ObjectHandle
+}
+
+// gcmTagBits is the size of the authentication tag appended to the AES-GCM ciphertexts
+const gcmTagBits = 128
+
+// Seal encrypts and authenticates the plaintext and authenticates the additional data, the returned ciphertext
+// includes the authentication tag
+func (k *AesKey) Seal(nonce, plaintext, additionalData []byte) ([]byte, error) {
+	k.token.lock.Lock()
+	defer k.token.lock.Unlock()
+
+	params := p11.NewGCMParams(nonce, additionalData, gcmTagBits)
+	defer params.Free()
+	if err := k.token.ctx.EncryptInit(k.token.session, []*p11.Mechanism{p11.NewMechanism(p11.CKM_AES_GCM, params)}, k.key); err != nil {
+		return nil, errors.Wrap(err, "pkcs11/token:Seal() Could not initialize encryption")
+	}
+	ciphertext, err := k.token.ctx.Encrypt(k.token.session, plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "pkcs11/token:Seal() Could not encrypt")
+	}
+	return ciphertext, nil
+}
+
+// Open decrypts and authenticates the ciphertext and authenticates the additional data
+func (k *AesKey) Open(nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	k.token.lock.Lock()
+	defer k.token.lock.Unlock()
+
+	params := p11.NewGCMParams(nonce, additionalData, gcmTagBits)
+	defer params.Free()
+	if err := k.token.ctx.DecryptInit(k.token.session, []*p11.Mechanism{p11.NewMechanism(p11.CKM_AES_GCM, params)}, k.key); err != nil {
+		return nil, errors.Wrap(err, "pkcs11/token:Open() Could not initialize decryption")
+	}
+	plaintext, err := k.token.ctx.Decrypt(k.token.session, ciphertext)
+	if err != nil {
+		return nil, errors.Wrap(err, "pkcs11/token:Open() Could not decrypt")
+	}
+	return plaintext, nil
+}
+
+// deleteObjects must be called with the token lock held
+func (t *Token) deleteObjects(label string) error {
+	for _, class := range []uint{p11.CKO_PRIVATE_KEY, p11.CKO_PUBLIC_KEY, p11.CKO_SECRET_KEY} {
+		objects, err := t.findObjects(class, label)
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			if err = t.ctx.DestroyObject(t.session, object); err != nil {
+				return errors.Wrapf(err, "pkcs11/token:deleteObjects() Could not delete key %s", label)
+			}
+		}
+	}
+	return nil
+}
+
+// relabelObjects must be called with the token lock held
+func (t *Token) relabelObjects(label, newLabel string) error {
+	for _, class := range []uint{p11.CKO_PRIVATE_KEY, p11.CKO_PUBLIC_KEY} {
+		objects, err := t.findObjects(class, label)
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			err = t.ctx.SetAttributeValue(t.session, object, []*p11.Attribute{p11.NewAttribute(p11.CKA_LABEL, newLabel)})
+			if err != nil {
+				return errors.Wrapf(err, "pkcs11/token:relabelObjects() Could not change label of key %s", label)
+			}
+		}
+	}
+	return nil
+}
+
+// findObjects must be called with the token lock held
+func (t *Token) findObjects(class uint, label string) ([]p11.ObjectHandle, error) {
+	err := t.ctx.FindObjectsInit(t.session, []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_CLASS, class),
+		p11.NewAttribute(p11.CKA_LABEL, label),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "pkcs11/token:findObjects() Could not search token")
+	}
+	var objects []p11.ObjectHandle
+	for {
+		found, _, err := t.ctx.FindObjects(t.session, 16)
+		if err != nil {
+			_ = t.ctx.FindObjectsFinal(t.session)
+			return nil, errors.Wrap(err, "pkcs11/token:findObjects() Could not search token")
+		}
+		if len(found) == 0 {
+			break
+		}
+		objects = append(objects, found...)
+	}
+	if err = t.ctx.FindObjectsFinal(t.session); err != nil {
+		return nil, errors.Wrap(err, "pkcs11/token:findObjects() Could not search token")
+	}
+	return objects, nil
+}
+
+// newSigner must be called with the token lock held
+func (t *Token) newSigner(privateKey, publicKey p11.ObjectHandle) (crypto.Signer, error) {
+	attributes, err := t.ctx.GetAttributeValue(t.session, publicKey, []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_KEY_TYPE, nil),
+		p11.NewAttribute(p11.CKA_MODULUS, nil),
+		p11.NewAttribute(p11.CKA_PUBLIC_EXPONENT, nil),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "pkcs11/token:newSigner() Could not read public key")
+	}
+
+	publicRsaKey := rsa.PublicKey{}
+	for _, attribute := range attributes {
+		switch attribute.Type {
+		case p11.CKA_KEY_TYPE:
+			// the key type is a CK_ULONG in native byte order, CKK_RSA is zero
+			for _, b := range attribute.Value {
+				if b != p11.CKK_RSA {
+					return nil, errors.New("pkcs11/token:newSigner() Only RSA keys are supported")
+				}
+			}
+		case p11.CKA_MODULUS:
+			publicRsaKey.N = new(big.Int).SetBytes(attribute.Value)
+		case p11.CKA_PUBLIC_EXPONENT:
+			publicRsaKey.E = int(new(big.Int).SetBytes(attribute.Value).Int64())
+		}
+	}
+	if publicRsaKey.N == nil || publicRsaKey.E == 0 {
+		return nil, errors.New("pkcs11/token:newSigner() Incomplete RSA public key")
+	}
+	return &rsaSigner{token: t, privateKey: privateKey, publicKey: &publicRsaKey}, nil
+}
+
+// rsaSigner signs with a RSA private key in a PKCS#11 token
+type rsaSigner struct {
+	token      *Token
+	privateKey p11.ObjectHandle
+	publicKey  *rsa.PublicKey
+}
+
+func (s *rsaSigner) Public() crypto.PublicKey {
+	return s.publicKey
+}
+
+// Sign creates a PKCS #1 v1.5 or, if opts is a *rsa.PSSOptions, a PSS signature of the digest
+func (s *rsaSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	hashAlg := opts.HashFunc()
+	if len(digest) != hashAlg.Size() {
+		return nil, errors.New("pkcs11/token:Sign() Digest length does not match the hash function")
+	}
+
+	var mechanism *p11.Mechanism
+	var message []byte
+	if pssOpts, ok := opts.(*rsa.PSSOptions); ok {
+		hashMech, ok := pssHashMechanisms[hashAlg]
+		if !ok {
+			return nil, errors.Errorf("pkcs11/token:Sign() Unsupported hash function %v", hashAlg)
+		}
+		saltLength := pssOpts.SaltLength
+		if saltLength == rsa.PSSSaltLengthEqualsHash {
+			saltLength = hashAlg.Size()
+		} else if saltLength == rsa.PSSSaltLengthAuto {
+			saltLength = (s.publicKey.N.BitLen()-1+7)/8 - 2 - hashAlg.Size()
+		}
+		mechanism = p11.NewMechanism(p11.CKM_RSA_PKCS_PSS, p11.NewPSSParams(hashMech[0], hashMech[1], uint(saltLength)))
+		message = digest
+	} else {
+		prefix, ok := digestInfoPrefixes[hashAlg]
+		if !ok {
+			return nil, errors.Errorf("pkcs11/token:Sign() Unsupported hash function %v", hashAlg)
+		}
+		mechanism = p11.NewMechanism(p11.CKM_RSA_PKCS, nil)
+		message = append(append([]byte{}, prefix...), digest...)
+	}
+
+	s.token.lock.Lock()
+	defer s.token.lock.Unlock()
+	if err := s.token.ctx.SignInit(s.token.session, []*p11.Mechanism{mechanism}, s.privateKey); err != nil {
+		return nil, errors.Wrap(err, "pkcs11/token:Sign() Could not initialize signing")
+	}
+	signature, err := s.token.ctx.Sign(s.token.session, message)
+	if err != nil {
+		return nil, errors.Wrap(err, "pkcs11/token:Sign() Could not sign")
+	}
+	return signature, nil
+}
+
+// digestInfoPrefixes are the DER encoded DigestInfo headers that precede the digest in a PKCS #1 v1.5 signature
+var digestInfoPrefixes = map[crypto.Hash][]byte{
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+// pssHashMechanisms maps a hash function to the PKCS#11 hash and MGF1 mechanisms used in PSS signatures
+var pssHashMechanisms = map[crypto.Hash][2]uint{
+	crypto.SHA256: {p11.CKM_SHA256, p11.CKG_MGF1_SHA256},
+	crypto.SHA384: {p11.CKM_SHA384, p11.CKG_MGF1_SHA384},
+	crypto.SHA512: {p11.CKM_SHA512, p11.CKG_MGF1_SHA512},
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package pkcs11
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadPin(t *testing.T) {
+	assertions := assert.New(t)
+	dir, err := ioutil.TempDir("", "pkcs11-pin")
+	assertions.NoError(err)
+	defer os.RemoveAll(dir)
+
+	pinFile := filepath.Join(dir, "pin")
+	assertions.NoError(SavePin(pinFile, "1234"))
+	pin, err := ReadPin("PKCS11_TEST_READ_PIN", pinFile)
+	assertions.NoError(err)
+	assertions.Equal("1234", pin)
+
+	os.Setenv("PKCS11_TEST_READ_PIN", "5678")
+	defer os.Unsetenv("PKCS11_TEST_READ_PIN")
+	pin, err = ReadPin("PKCS11_TEST_READ_PIN", pinFile)
+	assertions.NoError(err)
+	assertions.Equal("5678", pin, "the PIN in the environment should be preferred")
+	os.Unsetenv("PKCS11_TEST_READ_PIN")
+
+	assertions.NoError(os.Chmod(pinFile, 0644))
+	_, err = ReadPin("PKCS11_TEST_READ_PIN", pinFile)
+	assertions.Error(err, "a PIN file readable by others should be refused")
+	_, err = ReadPin("PKCS11_TEST_READ_PIN", "")
+	assertions.Error(err)
+}
+
+func TestDigestInfoPrefixes(t *testing.T) {
+	oids := map[crypto.Hash]asn1.ObjectIdentifier{
+		crypto.SHA256: {2, 16, 840, 1, 101, 3, 4, 2, 1},
+		crypto.SHA384: {2, 16, 840, 1, 101, 3, 4, 2, 2},
+		crypto.SHA512: {2, 16, 840, 1, 101, 3, 4, 2, 3},
+	}
+	for hashAlg, oid := range oids {
+		digestInfo, err := asn1.Marshal(struct {
+			Algorithm pkix.AlgorithmIdentifier
+			Digest    []byte
+		}{pkix.AlgorithmIdentifier{Algorithm: oid, Parameters: asn1.NullRawValue}, make([]byte, hashAlg.Size())})
+		assert.NoError(t, err)
+		assert.Equal(t, digestInfo[:len(digestInfo)-hashAlg.Size()], digestInfoPrefixes[hashAlg], hashAlg.String())
+	}
+}
+
+// softHsmModules are the locations of the SoftHSM library in the distributions
+var softHsmModules = []string{
+	"/usr/lib/softhsm/libsofthsm2.so",
+	"/usr/lib64/softhsm/libsofthsm2.so",
+	"/usr/lib/x86_64-linux-gnu/softhsm/libsofthsm2.so",
+	"/usr/local/lib/softhsm/libsofthsm2.so",
+}
+
+// openTestToken opens the token set in PKCS11_TEST_MODULE, PKCS11_TEST_TOKEN_LABEL and PKCS11_TEST_PIN, or creates a
+// SoftHSM token in a temporary directory when SoftHSM is installed
+func openTestToken(t *testing.T) (*Token, func()) {
+	if module := os.Getenv("PKCS11_TEST_MODULE"); module != "" {
+		token, err := OpenToken(module, os.Getenv("PKCS11_TEST_TOKEN_LABEL"), os.Getenv("PKCS11_TEST_PIN"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token, func() {}
+	}
+
+	softHsmUtil, err := exec.LookPath("softhsm2-util")
+	if err != nil {
+		t.Skip("Neither PKCS11_TEST_MODULE is set nor SoftHSM is installed")
+	}
+	var module string
+	for _, path := range softHsmModules {
+		if _, err = os.Stat(path); err == nil {
+			module = path
+			break
+		}
+	}
+	if module == "" {
+		t.Skip("SoftHSM library is not found")
+	}
+
+	dir, err := ioutil.TempDir("", "softhsm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := filepath.Join(dir, "softhsm2.conf")
+	err = ioutil.WriteFile(conf, []byte("directories.tokendir = "+dir+"\nobjectstore.backend = file\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the module reads its configuration when it is initialized by OpenToken
+	os.Setenv("SOFTHSM2_CONF", conf)
+	output, err := exec.Command(softHsmUtil, "--init-token", "--free", "--label", "pkcs11-test",
+		"--pin", "1234", "--so-pin", "1234").CombinedOutput()
+	if err != nil {
+		t.Fatal("Failed to create SoftHSM token", string(output))
+	}
+	token, err := OpenToken(module, "pkcs11-test", "1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token, func() { os.RemoveAll(dir) }
+}
+
+func TestTokenSoftHsm(t *testing.T) {
+	token, cleanup := openTestToken(t)
+	defer cleanup()
+	assertions := assert.New(t)
+
+	signer, err := token.GenerateRsaKey("pkcs11-test-key", 2048)
+	assertions.NoError(err)
+	defer token.DeleteKey("pkcs11-test-key")
+
+	found, err := token.FindKey("pkcs11-test-key")
+	assertions.NoError(err)
+	assertions.Equal(signer.Public(), found.Public())
+
+	digest := sha256.Sum256([]byte("pkcs11 test"))
+	signature, err := found.Sign(rand.Reader, digest[:], crypto.SHA256)
+	assertions.NoError(err)
+	assertions.NoError(rsa.VerifyPKCS1v15(signer.Public().(*rsa.PublicKey), crypto.SHA256, digest[:], signature))
+
+	pssOpts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+	signature, err = found.Sign(rand.Reader, digest[:], pssOpts)
+	assertions.NoError(err)
+	assertions.NoError(rsa.VerifyPSS(signer.Public().(*rsa.PublicKey), crypto.SHA256, digest[:], signature, pssOpts))
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "pkcs11 test CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, &template, &template, signer.Public(), signer)
+	assertions.NoError(err)
+	cert, err := x509.ParseCertificate(certDer)
+	assertions.NoError(err)
+	assertions.NoError(cert.CheckSignatureFrom(cert))
+
+	// generating a key with the label of an existing key replaces it
+	replacement, err := token.GenerateRsaKey("pkcs11-test-key", 2048)
+	assertions.NoError(err)
+	assertions.NotEqual(signer.Public(), replacement.Public())
+	found, err = token.FindKey("pkcs11-test-key")
+	assertions.NoError(err)
+	assertions.Equal(replacement.Public(), found.Public())
+	for _, label := range []string{"pkcs11-test-key" + newKeyLabelSuffix, "pkcs11-test-key" + oldKeyLabelSuffix} {
+		_, err = token.FindKey(label)
+		assertions.Error(err, "the key pairs swapped during generation should be removed")
+	}
+
+	assertions.NoError(token.DeleteKey("pkcs11-test-key"))
+	_, err = token.FindKey("pkcs11-test-key")
+	assertions.Error(err)
+
+	aesKey, err := token.GenerateAesKey("pkcs11-test-aes-key", 256)
+	assertions.NoError(err)
+	defer token.DeleteKey("pkcs11-test-aes-key")
+	nonce := make([]byte, 12)
+	ciphertext, err := aesKey.Seal(nonce, []byte("pkcs11 test"), []byte("aad"))
+	assertions.NoError(err)
+	foundAesKey, err := token.FindAesKey("pkcs11-test-aes-key")
+	assertions.NoError(err)
+	plaintext, err := foundAesKey.Open(nonce, ciphertext, []byte("aad"))
+	assertions.NoError(err)
+	assertions.Equal("pkcs11 test", string(plaintext))
+	_, err = foundAesKey.Open(nonce, ciphertext, []byte("other aad"))
+	assertions.Error(err)
+}
@@ -87,24 +87,43 @@ func (dc *DownloadCert) Run() error {
 		printToWriter(dc.ConsoleWriter, dc.commandName, "Failed to download certificate")
 		return err
 	}
-	err = crypt.SavePrivateKeyAsPKCS8(key, dc.KeyFile)
+	// the key and certificate are written to temporary files and renamed, so that a running server reloading them
+	// never reads a partially written file
+	tmpKeyFile := dc.KeyFile + ".tmp"
+	err = crypt.SavePrivateKeyAsPKCS8(key, tmpKeyFile)
 	if err != nil {
+		os.Remove(tmpKeyFile)
 		return errors.Wrap(err, "crypt.SavePrivateKeyAsPKCS8 failed")
 	}
 
 	fi, err := os.Stat(dc.CertFile)
 	if err != nil || fi.Mode().IsRegular() {
-		err = ioutil.WriteFile(dc.CertFile, cert, 0644)
+		tmpCertFile := dc.CertFile + ".tmp"
+		err = writeCertFile(tmpCertFile, cert)
 		if err != nil {
+			os.Remove(tmpKeyFile)
+			os.Remove(tmpCertFile)
 			printToWriter(dc.ConsoleWriter, dc.commandName, "Failed to save certificate")
-			return errors.Wrap(err, "Could not store Certificate")
+			return err
 		}
-		err = os.Chmod(dc.CertFile, 0644)
+		err = os.Rename(tmpKeyFile, dc.KeyFile)
 		if err != nil {
-			printToWriter(dc.ConsoleWriter, dc.commandName, "Failed to change file permission")
-			return errors.Wrap(err, "Could not change file permission")
+			os.Remove(tmpKeyFile)
+			os.Remove(tmpCertFile)
+			return errors.Wrap(err, "Could not store private key")
+		}
+		err = os.Rename(tmpCertFile, dc.CertFile)
+		if err != nil {
+			os.Remove(tmpCertFile)
+			printToWriter(dc.ConsoleWriter, dc.commandName, "Failed to save certificate")
+			return errors.Wrap(err, "Could not store Certificate")
 		}
 	} else if fi.Mode().IsDir() {
+		err = os.Rename(tmpKeyFile, dc.KeyFile)
+		if err != nil {
+			os.Remove(tmpKeyFile)
+			return errors.Wrap(err, "Could not store private key")
+		}
 		err = crypt.SavePemCertWithShortSha1FileName(cert, dc.CertFile)
 		if err != nil {
 			printToWriter(dc.ConsoleWriter, dc.commandName, "Failed to save certificate")
@@ -115,6 +134,18 @@ func (dc *DownloadCert) Run() error {
 	return nil
 }
 
+func writeCertFile(certFile string, cert []byte) error {
+	err := ioutil.WriteFile(certFile, cert, 0644)
+	if err != nil {
+		return errors.Wrap(err, "Could not store Certificate")
+	}
+	err = os.Chmod(certFile, 0644)
+	if err != nil {
+		return errors.Wrap(err, "Could not change file permission")
+	}
+	return nil
+}
+
 func (dc *DownloadCert) Validate() error {
 	_, err := os.Stat(dc.KeyFile)
 	if os.IsNotExist(err) {
@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package setup
+
+import (
+	"github.com/intel-secl/intel-secl/v3/pkg/clients"
+	aasClient "github.com/intel-secl/intel-secl/v3/pkg/clients/aas"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/common/crypt"
+	"github.com/pkg/errors"
+)
+
+// RenewCertWithUser returns a function that downloads a new certificate from CMS for a running service, as done by
+// the download-cert setup task. The bearer token for CMS is fetched from AAS with the given credentials, the user
+// needs the CMS CertApprover role for the common name and SAN list of the certificate. The service users do not
+// have this role.
+func RenewCertWithUser(dc DownloadCert, aasApiUrl, username, password string) func() error {
+	return func() error {
+		if aasApiUrl == "" || username == "" || password == "" {
+			return errors.New("AAS url or certificate renewal credentials are not configured")
+		}
+		caCerts, err := crypt.GetCertsFromDir(dc.CaCertDirPath)
+		if err != nil {
+			return errors.Wrap(err, "Could not read trusted CA certificates")
+		}
+		client, err := clients.HTTPClientWithCA(caCerts)
+		if err != nil {
+			return errors.Wrap(err, "Could not create http client")
+		}
+
+		jwtcl := aasClient.NewJWTClient(aasApiUrl)
+		jwtcl.HTTPClient = client
+		jwtcl.AddUser(username, password)
+		token, err := jwtcl.FetchTokenForUser(username)
+		if err != nil {
+			return errors.Wrap(err, "Could not fetch token for user "+username)
+		}
+
+		dc.BearerToken = string(token)
+		dc.ConsoleWriter = nil
+		return dc.Run()
+	}
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package setup
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/model/aas"
+)
+
+// newFakeAasAndCms starts a server issuing a token for the user renewer and signing the CSRs posted with that token.
+// The CA certificate of the server is written to caCertDir.
+func newFakeAasAndCms(t *testing.T, caCertDir string) *httptest.Server {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "CMS Signing CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, _ := x509.ParseCertificate(caDer)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/aas/token", func(w http.ResponseWriter, r *http.Request) {
+		var userCred aas.UserCred
+		if json.NewDecoder(r.Body).Decode(&userCred) != nil || userCred.UserName != "renewer" || userCred.Password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("cert-approver-token"))
+	})
+	mux.HandleFunc("/cms/certificates", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer cert-approver-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		block, _ := pem.Decode(body)
+		if block == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(time.Now().UnixNano()),
+			Subject:      csr.Subject,
+			DNSNames:     csr.DNSNames,
+			IPAddresses:  csr.IPAddresses,
+			NotBefore:    time.Now().Add(-time.Minute),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		certDer, err := x509.CreateCertificate(rand.Reader, template, caCert, csr.PublicKey, caKey)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}))
+	})
+
+	server := httptest.NewTLSServer(mux)
+	serverCa := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err = ioutil.WriteFile(filepath.Join(caCertDir, "server.pem"), serverCa, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+func TestRenewCertWithUser(t *testing.T) {
+	dir, err := ioutil.TempDir("", "renew-cert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	caCertDir := filepath.Join(dir, "trusted-ca")
+	if err = os.Mkdir(caCertDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	server := newFakeAasAndCms(t, caCertDir)
+	defer server.Close()
+
+	dc := DownloadCert{
+		KeyFile:       filepath.Join(dir, "tls.key"),
+		CertFile:      filepath.Join(dir, "tls-cert.pem"),
+		KeyAlgorithm:  "ecdsa",
+		KeyLength:     384,
+		Subject:       pkix.Name{CommonName: "Test TLS Certificate"},
+		SanList:       "127.0.0.1,localhost",
+		CertType:      "tls",
+		CaCertDirPath: caCertDir,
+		CmsBaseURL:    server.URL + "/cms/",
+	}
+	// the files of the previous certificate are replaced
+	if err = ioutil.WriteFile(dc.KeyFile, []byte("old key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(dc.CertFile, []byte("old certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = RenewCertWithUser(dc, server.URL+"/aas/", "renewer", "wrong-password")(); err == nil {
+		t.Error("Renewal should fail when the token can not be fetched")
+	}
+	if certPem, _ := ioutil.ReadFile(dc.CertFile); string(certPem) != "old certificate" {
+		t.Error("Failed renewal should keep the previous certificate")
+	}
+
+	if err = RenewCertWithUser(dc, server.URL+"/aas/", "renewer", "password")(); err != nil {
+		t.Fatal("Failed to renew certificate", err)
+	}
+	keyPair, err := tls.LoadX509KeyPair(dc.CertFile, dc.KeyFile)
+	if err != nil {
+		t.Fatal("Renewed certificate and key do not match", err)
+	}
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Subject.CommonName != "Test TLS Certificate" || len(cert.DNSNames) != 1 || len(cert.IPAddresses) != 1 {
+		t.Error("Renewed certificate does not have the configured subject and SAN list")
+	}
+	if tmpFiles, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(tmpFiles) != 0 {
+		t.Error("Temporary files should not be left behind", tmpFiles)
+	}
+
+	if err = RenewCertWithUser(dc, server.URL+"/aas/", "", "")(); err == nil {
+		t.Error("Renewal should fail when the credentials are not configured")
+	}
+}
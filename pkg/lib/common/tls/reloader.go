@@ -0,0 +1,211 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package tls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	commLog "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
+	"github.com/pkg/errors"
+)
+
+var defaultLog = commLog.GetDefaultLogger()
+var secLog = commLog.GetSecurityLogger()
+
+const (
+	// DefaultRenewBefore is how long before expiry the certificate of a server is renewed
+	DefaultRenewBefore = 7 * 24 * time.Hour
+	// DefaultReloadInterval is how often the certificate and key files of a server are checked for changes
+	DefaultReloadInterval = time.Minute
+	// renewRetryInterval is the time to wait before a failed renewal is attempted again
+	renewRetryInterval = time.Hour
+)
+
+// RenewFunc replaces the certificate and key files of a server with a newly issued certificate
+type RenewFunc func() error
+
+// CertReloader serves the certificate of a TLS server through tls.Config.GetCertificate. The certificate and key files
+// are checked periodically, so that a rotated certificate is used for new connections without restarting the server.
+// If a RenewFunc is set, it is called when the certificate is about to expire.
+type CertReloader struct {
+	certFile       string
+	keyFile        string
+	renewBefore    time.Duration
+	reloadInterval time.Duration
+	renew          RenewFunc
+
+	lock        sync.RWMutex
+	cert        *tls.Certificate
+	notAfter    time.Time
+	certModTime time.Time
+	keyModTime  time.Time
+
+	nextRenewal   time.Time
+	expiryLogged  bool
+	stop          chan struct{}
+	stopped       sync.WaitGroup
+	startStopLock sync.Mutex
+}
+
+// NewCertReloader loads the certificate and key from the given files. A renewBefore of zero disables renewal and
+// expiry warnings, renew can be nil if the certificate files are replaced externally.
+func NewCertReloader(certFile, keyFile string, renewBefore, reloadInterval time.Duration, renew RenewFunc) (*CertReloader, error) {
+	if reloadInterval <= 0 {
+		reloadInterval = DefaultReloadInterval
+	}
+	cr := &CertReloader{
+		certFile:       certFile,
+		keyFile:        keyFile,
+		renewBefore:    renewBefore,
+		reloadInterval: reloadInterval,
+		renew:          renew,
+	}
+	if _, err := cr.Reload(); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// GetCertificate returns the currently loaded certificate. It is meant to be set as tls.Config.GetCertificate
+func (cr *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.lock.RLock()
+	defer cr.lock.RUnlock()
+	return cr.cert, nil
+}
+
+// NotAfter returns the expiry time of the currently loaded certificate
+func (cr *CertReloader) NotAfter() time.Time {
+	cr.lock.RLock()
+	defer cr.lock.RUnlock()
+	return cr.notAfter
+}
+
+// Reload loads the certificate and key files if they were modified since they were last loaded. It reports whether a
+// new certificate was loaded. The current certificate is kept if the files cannot be loaded, for example while they
+// are being written.
+func (cr *CertReloader) Reload() (bool, error) {
+	certInfo, err := os.Stat(cr.certFile)
+	if err != nil {
+		return false, errors.Wrap(err, "Could not read TLS certificate file")
+	}
+	keyInfo, err := os.Stat(cr.keyFile)
+	if err != nil {
+		return false, errors.Wrap(err, "Could not read TLS key file")
+	}
+
+	cr.lock.RLock()
+	unchanged := cr.cert != nil && certInfo.ModTime().Equal(cr.certModTime) && keyInfo.ModTime().Equal(cr.keyModTime)
+	cr.lock.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return false, errors.Wrap(err, "Could not load TLS certificate and key")
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return false, errors.Wrap(err, "Could not parse TLS certificate")
+	}
+	cert.Leaf = leaf
+
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+	if cr.cert != nil {
+		secLog.Infof("tls/reloader:Reload() TLS certificate reloaded from %s, serial number %s, valid until %s",
+			cr.certFile, leaf.SerialNumber.Text(16), leaf.NotAfter.Format(time.RFC3339))
+	}
+	cr.cert = &cert
+	cr.notAfter = leaf.NotAfter
+	cr.certModTime = certInfo.ModTime()
+	cr.keyModTime = keyInfo.ModTime()
+	cr.nextRenewal = time.Time{}
+	cr.expiryLogged = false
+	return true, nil
+}
+
+// Start checks the certificate files for changes and renews the certificate in the background until Stop is called
+func (cr *CertReloader) Start() {
+	cr.startStopLock.Lock()
+	defer cr.startStopLock.Unlock()
+	if cr.stop != nil {
+		return
+	}
+	cr.stop = make(chan struct{})
+	cr.stopped.Add(1)
+	go func(stop chan struct{}) {
+		defer cr.stopped.Done()
+		ticker := time.NewTicker(cr.reloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				cr.check(time.Now())
+			}
+		}
+	}(cr.stop)
+}
+
+// Stop ends the background checks started by Start
+func (cr *CertReloader) Stop() {
+	cr.startStopLock.Lock()
+	defer cr.startStopLock.Unlock()
+	if cr.stop == nil {
+		return
+	}
+	close(cr.stop)
+	cr.stopped.Wait()
+	cr.stop = nil
+}
+
+// check reloads changed certificate files and renews the certificate once it is within renewBefore of its expiry
+func (cr *CertReloader) check(now time.Time) {
+	if _, err := cr.Reload(); err != nil {
+		defaultLog.WithError(err).Warn("tls/reloader:check() Failed to reload TLS certificate, the current certificate is still used")
+	}
+	if cr.renewBefore <= 0 {
+		return
+	}
+
+	cr.lock.Lock()
+	notAfter := cr.notAfter
+	if notAfter.Sub(now) > cr.renewBefore || now.Before(cr.nextRenewal) {
+		cr.lock.Unlock()
+		return
+	}
+	cr.nextRenewal = now.Add(renewRetryInterval)
+	logExpiry := !cr.expiryLogged
+	cr.expiryLogged = true
+	cr.lock.Unlock()
+
+	if cr.renew == nil {
+		if logExpiry {
+			secLog.Warnf("tls/reloader:check() TLS certificate %s expires at %s and must be replaced",
+				cr.certFile, notAfter.Format(time.RFC3339))
+		}
+		return
+	}
+
+	secLog.Infof("tls/reloader:check() TLS certificate %s expires at %s, renewing certificate", cr.certFile,
+		notAfter.Format(time.RFC3339))
+	if err := cr.renew(); err != nil {
+		secLog.WithError(err).Errorf("tls/reloader:check() Failed to renew TLS certificate %s, retrying in %s",
+			cr.certFile, renewRetryInterval)
+		return
+	}
+	if _, err := cr.Reload(); err != nil {
+		secLog.WithError(err).Errorf("tls/reloader:check() Failed to load renewed TLS certificate %s", cr.certFile)
+		return
+	}
+	secLog.Infof("tls/reloader:check() TLS certificate %s renewed, valid until %s", cr.certFile,
+		cr.NotAfter().Format(time.RFC3339))
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeCertAndKey(t *testing.T, certFile, keyFile string, serialNumber int64, validity time.Duration) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(serialNumber),
+		Subject:      pkix.Name{CommonName: "reloader test"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(validity),
+		DNSNames:     []string{"localhost"},
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}), 0600))
+	assert.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}), 0644))
+	// make sure the modification time changes even on file systems with a coarse timestamp resolution
+	modTime := time.Now().Add(time.Duration(serialNumber) * time.Second)
+	assert.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+	assert.NoError(t, os.Chtimes(certFile, modTime, modTime))
+}
+
+func servedSerialNumber(t *testing.T, cr *CertReloader) int64 {
+	cert, err := cr.GetCertificate(nil)
+	assert.NoError(t, err)
+	return cert.Leaf.SerialNumber.Int64()
+}
+
+func TestCertReloaderReload(t *testing.T) {
+	assertions := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "tls-reloader")
+	assertions.NoError(err)
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "tls-cert.pem")
+	keyFile := filepath.Join(dir, "tls.key")
+
+	_, err = NewCertReloader(certFile, keyFile, 0, 0, nil)
+	assertions.Error(err, "reloader should fail without certificate files")
+
+	writeCertAndKey(t, certFile, keyFile, 1, time.Hour)
+	cr, err := NewCertReloader(certFile, keyFile, 0, 0, nil)
+	assertions.NoError(err)
+	assertions.Equal(int64(1), servedSerialNumber(t, cr))
+
+	reloaded, err := cr.Reload()
+	assertions.NoError(err)
+	assertions.False(reloaded, "unchanged files should not be reloaded")
+
+	writeCertAndKey(t, certFile, keyFile, 2, time.Hour)
+	reloaded, err = cr.Reload()
+	assertions.NoError(err)
+	assertions.True(reloaded)
+	assertions.Equal(int64(2), servedSerialNumber(t, cr))
+
+	// a certificate file that cannot be loaded must not replace the served certificate
+	modTime := time.Now().Add(time.Hour)
+	assertions.NoError(ioutil.WriteFile(certFile, []byte("not a certificate"), 0644))
+	assertions.NoError(os.Chtimes(certFile, modTime, modTime))
+	_, err = cr.Reload()
+	assertions.Error(err)
+	assertions.Equal(int64(2), servedSerialNumber(t, cr))
+}
+
+func TestCertReloaderRenew(t *testing.T) {
+	assertions := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "tls-reloader")
+	assertions.NoError(err)
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "tls-cert.pem")
+	keyFile := filepath.Join(dir, "tls.key")
+
+	writeCertAndKey(t, certFile, keyFile, 1, time.Hour)
+	renewals := 0
+	cr, err := NewCertReloader(certFile, keyFile, 24*time.Hour, 0, func() error {
+		renewals++
+		writeCertAndKey(t, certFile, keyFile, 2, 48*time.Hour)
+		return nil
+	})
+	assertions.NoError(err)
+
+	cr.check(time.Now())
+	assertions.Equal(1, renewals)
+	assertions.Equal(int64(2), servedSerialNumber(t, cr))
+
+	// the renewed certificate is not within the renewal window yet
+	cr.check(time.Now())
+	assertions.Equal(1, renewals)
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	"github.com/antchfx/jsonquery"
+	"github.com/google/cel-go/cel"
+	hcTypes "github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector/types"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// ConditionExpressionVariable is the name under which the host manifest is made available to the
+// condition_expression of a flavor template
+const ConditionExpressionVariable = "host_manifest"
+
+const (
+	// conditionExpressionCostLimit bounds the evaluation of a condition expression, an expression iterating over the
+	// event logs of a host manifest stays far below it
+	conditionExpressionCostLimit = 1000000
+	// maxConditionPrograms bounds the number of cached programs, the cache is emptied when it is full
+	maxConditionPrograms = 1000
+)
+
+var (
+	conditionExpressionEnv     *cel.Env
+	conditionExpressionEnvErr  error
+	conditionExpressionEnvOnce sync.Once
+
+	// conditionPrograms caches the programs of the condition expressions compiled so far, a program is safe for
+	// concurrent evaluation
+	conditionPrograms     = make(map[string]cel.Program)
+	conditionProgramsLock sync.RWMutex
+)
+
+// CompileConditionExpression compiles the Common Expression Language condition_expression of a flavor template.
+// The returned error lists every syntax and type error of the expression along with its position. Each expression
+// is only compiled once, later calls return the cached program.
+func CompileConditionExpression(expression string) (cel.Program, error) {
+	conditionProgramsLock.RLock()
+	program, found := conditionPrograms[expression]
+	conditionProgramsLock.RUnlock()
+	if found {
+		return program, nil
+	}
+
+	conditionExpressionEnvOnce.Do(func() {
+		conditionExpressionEnv, conditionExpressionEnvErr = cel.NewEnv(
+			cel.Variable(ConditionExpressionVariable, cel.MapType(cel.StringType, cel.DynType)))
+	})
+	if conditionExpressionEnvErr != nil {
+		return nil, errors.Wrap(conditionExpressionEnvErr, "Failed to create the condition expression environment")
+	}
+	env := conditionExpressionEnv
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	// a bool can only be assigned to a bool or dyn output type
+	if !ast.OutputType().IsAssignableType(cel.BoolType) {
+		return nil, errors.Errorf("The condition expression must evaluate to a bool, not a %s", ast.OutputType())
+	}
+
+	program, err := env.Program(ast, cel.CostLimit(conditionExpressionCostLimit))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create the condition expression program")
+	}
+
+	conditionProgramsLock.Lock()
+	if len(conditionPrograms) >= maxConditionPrograms {
+		conditionPrograms = make(map[string]cel.Program)
+	}
+	conditionPrograms[expression] = program
+	conditionProgramsLock.Unlock()
+	return program, nil
+}
+
+// FlavorTemplateConditionEvaluator determines whether flavor templates apply to a host manifest
+type FlavorTemplateConditionEvaluator struct {
+	hostManifestJSON *jsonquery.Node
+	hostManifestMap  map[string]interface{}
+}
+
+// NewFlavorTemplateConditionEvaluator returns an evaluator for the conditions of flavor templates against the host manifest
+func NewFlavorTemplateConditionEvaluator(hostManifest *hcTypes.HostManifest) (*FlavorTemplateConditionEvaluator, error) {
+	hostManifestBytes, err := json.Marshal(hostManifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling the host manifest")
+	}
+
+	hostManifestJSON, err := jsonquery.Parse(bytes.NewReader(hostManifestBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "Error parsing the host manifest")
+	}
+
+	var hostManifestMap map[string]interface{}
+	if err = json.Unmarshal(hostManifestBytes, &hostManifestMap); err != nil {
+		return nil, errors.Wrap(err, "Error unmarshalling the host manifest")
+	}
+
+	return &FlavorTemplateConditionEvaluator{
+		hostManifestJSON: hostManifestJSON,
+		hostManifestMap:  hostManifestMap,
+	}, nil
+}
+
+// Matches returns true if every jsonquery statement of the template's condition selects data from the host manifest
+// and its condition_expression, when present, evaluates to true. An expression that cannot be evaluated against the
+// host manifest, e.g. because it refers to a field the manifest does not have, does not match and is logged as a
+// warning, since the host manifest is not typed and such errors are only found at evaluation. An error is returned
+// when the condition or the condition_expression of the template is invalid.
+func (evaluator *FlavorTemplateConditionEvaluator) Matches(flavorTemplate *hvs.FlavorTemplate) (bool, error) {
+	for _, condition := range flavorTemplate.Condition {
+		expectedData, err := jsonquery.Query(evaluator.hostManifestJSON, condition)
+		if err != nil {
+			return false, errors.Wrapf(err, "Invalid syntax in condition : %s", condition)
+		}
+		if expectedData == nil {
+			return false, nil
+		}
+	}
+
+	if flavorTemplate.ConditionExpression == "" {
+		return true, nil
+	}
+
+	program, err := CompileConditionExpression(flavorTemplate.ConditionExpression)
+	if err != nil {
+		return false, errors.Wrapf(err, "Invalid condition expression of flavor template %s", flavorTemplate.ID)
+	}
+
+	result, _, err := program.Eval(map[string]interface{}{ConditionExpressionVariable: evaluator.hostManifestMap})
+	if err != nil {
+		log.WithError(err).Warnf("flavor/util/flavor_template_condition:Matches() Condition expression of flavor template %s "+
+			"could not be evaluated against the host manifest", flavorTemplate.ID)
+		return false, nil
+	}
+
+	matches, ok := result.Value().(bool)
+	if !ok {
+		log.Warnf("flavor/util/flavor_template_condition:Matches() Condition expression of flavor template %s "+
+			"evaluated to a %s instead of a bool", flavorTemplate.ID, result.Type().TypeName())
+		return false, nil
+	}
+	return matches, nil
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package util
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	hcTypes "github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector/types"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	RHELHostManifestJson string = "../test/resources/RHELHostManifest.json"
+)
+
+func TestFlavorTemplateConditionEvaluator_Matches(t *testing.T) {
+	var hostManifest hcTypes.HostManifest
+	hostManifestBytes, err := ioutil.ReadFile(RHELHostManifestJson)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(hostManifestBytes, &hostManifest))
+
+	evaluator, err := NewFlavorTemplateConditionEvaluator(&hostManifest)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		template hvs.FlavorTemplate
+		matches  bool
+		wantErr  bool
+	}{
+		{
+			name:     "matching jsonquery condition",
+			template: hvs.FlavorTemplate{Condition: []string{"//host_info/os_name[text()='RedHatEnterprise']"}},
+			matches:  true,
+		},
+		{
+			name:     "jsonquery condition that does not match",
+			template: hvs.FlavorTemplate{Condition: []string{"//host_info/os_name[text()='VMware ESXi']"}},
+			matches:  false,
+		},
+		{
+			name: "matching condition expression",
+			template: hvs.FlavorTemplate{ConditionExpression: "host_manifest.host_info.os_name == 'RedHatEnterprise' && " +
+				"host_manifest.host_info.hardware_features.TPM.meta.tpm_version == '2.0'"},
+			matches: true,
+		},
+		{
+			name:     "condition expression that does not match",
+			template: hvs.FlavorTemplate{ConditionExpression: "host_manifest.host_info.os_name.startsWith('VMware')"},
+			matches:  false,
+		},
+		{
+			name:     "condition expression on a field the host manifest does not have",
+			template: hvs.FlavorTemplate{ConditionExpression: "host_manifest.host_info.hardware_features.SGX.enabled == 'true'"},
+			matches:  false,
+		},
+		{
+			name: "condition expression testing for an optional field",
+			template: hvs.FlavorTemplate{ConditionExpression: "!has(host_manifest.host_info.hardware_features.SGX) && " +
+				"host_manifest.host_info.hardware_features.TPM.enabled == 'true'"},
+			matches: true,
+		},
+		{
+			name: "both condition and condition expression must match",
+			template: hvs.FlavorTemplate{
+				Condition:           []string{"//host_info/os_name[text()='RedHatEnterprise']"},
+				ConditionExpression: "host_manifest.host_info.hardware_features.TPM.meta.tpm_version == '1.2'",
+			},
+			matches: false,
+		},
+		{
+			name:     "condition expression with a syntax error",
+			template: hvs.FlavorTemplate{ConditionExpression: "host_manifest.host_info.os_name = 'RedHatEnterprise'"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := evaluator.Matches(&tt.template)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.matches, matches)
+		})
+	}
+}
+
+func TestCompileConditionExpression(t *testing.T) {
+	program, err := CompileConditionExpression("has(host_manifest.host_info.hardware_features.TXT)")
+	assert.NoError(t, err)
+	cachedProgram, err := CompileConditionExpression("has(host_manifest.host_info.hardware_features.TXT)")
+	assert.NoError(t, err)
+	assert.True(t, program == cachedProgram, "the expression should only be compiled once")
+
+	_, err = CompileConditionExpression("(host_manifest.host_info.os_name == 'RedHatEnterprise'")
+	assert.Error(t, err)
+
+	_, err = CompileConditionExpression("size(host_manifest) + 1")
+	assert.Error(t, err, "non bool expression must fail")
+
+	_, err = CompileConditionExpression("host_info.os_name == 'RedHatEnterprise'")
+	assert.Error(t, err, "undeclared reference must fail")
+}
+
+func TestConditionExpressionCostLimit(t *testing.T) {
+	elements := make([]string, 1500)
+	for i := range elements {
+		elements[i] = strconv.Itoa(i)
+	}
+	list := "[" + strings.Join(elements, ", ") + "]"
+
+	program, err := CompileConditionExpression(list + ".all(x, " + list + ".all(y, x >= 0 || y >= 0))")
+	assert.NoError(t, err)
+	_, _, err = program.Eval(map[string]interface{}{ConditionExpressionVariable: map[string]interface{}{}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cost limit exceeded")
+}
+
+func TestConditionProgramsCacheIsBounded(t *testing.T) {
+	for i := 0; i <= maxConditionPrograms; i++ {
+		_, err := CompileConditionExpression("size(host_manifest) > " + strconv.Itoa(i))
+		assert.NoError(t, err)
+	}
+	conditionProgramsLock.RLock()
+	defer conditionProgramsLock.RUnlock()
+	assert.True(t, len(conditionPrograms) <= maxConditionPrograms)
+}
@@ -310,6 +310,7 @@ func (pfutil PlatformFlavorUtil) GetPcrDetails(pcrManifest hcTypes.PcrManifest,
 			currPcrEx.Pcr.Bank = pcr.Bank
 			currPcrEx.Measurement = pcrInfo.Value
 			currPcrEx.PCRMatches = true
+			currPcrEx.EventlogReplayAllBanks = rules.PcrReplayAllBanks
 
 			// Populate Event log value
 			var eventLogEqualEvents []hcTypes.EventLog
@@ -522,6 +523,9 @@ func getPcrRulesForFlavorPart(flavorPart *hvs.FlavorPart, pcrList map[hvs.PCR]hv
 		if pcrRule.PcrMatches != nil && *pcrRule.PcrMatches {
 			rulesList.PcrMatches = true
 		}
+		if pcrRule.EventlogReplayAllBanks != nil && *pcrRule.EventlogReplayAllBanks {
+			rulesList.PcrReplayAllBanks = true
+		}
 		if rulesList.PcrIncludes != nil && pcrRule.EventlogEquals != nil {
 			return nil, errors.New("flavor/util/platform_flavor_util:getPcrRulesForFlavorPart() Error getting pcrList : Both event log equals and includes rule present for single pcr index/bank")
 		}
@@ -17,10 +17,11 @@ const (
 	VendorIntel
 	VendorVMware
 	VendorMicrosoft
+	VendorGrpc
 )
 
 func (vendor Vendor) String() string {
-	return [...]string{"UNKNOWN", "INTEL", "VMWARE", "MICROSOFT"}[vendor]
+	return [...]string{"UNKNOWN", "INTEL", "VMWARE", "MICROSOFT", "GRPC"}[vendor]
 }
 
 func (vendor *Vendor) GetVendorFromOSName(osName string) error {
@@ -55,6 +56,8 @@ func (vendor *Vendor) UnmarshalJSON(b []byte) error {
 		*vendor = VendorVMware
 	case "INTEL":
 		*vendor = VendorIntel
+	case "GRPC":
+		*vendor = VendorGrpc
 	default:
 		*vendor = VendorUnknown
 		err = errors.Errorf("Provided vendor is not supported. Vendor : '%s'", jsonValue)
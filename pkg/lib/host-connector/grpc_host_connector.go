@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package host_connector
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"strings"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/clients/grpcagent"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector/types"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector/util"
+	taModel "github.com/intel-secl/intel-secl/v3/pkg/model/ta"
+	"github.com/pkg/errors"
+	"github.com/vmware/govmomi/vim25/mo"
+)
+
+// GrpcConnector collects host manifests from hosts running a generic TPM2 quote agent that speaks the gRPC
+// attestation protocol in pkg/clients/grpcagent. The agent only reports host information and quotes, so asset tags
+// and software manifests cannot be deployed through it.
+type GrpcConnector struct {
+	client grpcagent.AgentClient
+}
+
+func (gc *GrpcConnector) GetHostDetails() (taModel.HostInfo, error) {
+
+	log.Trace("grpc_host_connector:GetHostDetails() Entering")
+	defer log.Trace("grpc_host_connector:GetHostDetails() Leaving")
+	hostInfo, err := gc.client.GetHostInfo()
+	if err != nil {
+		return taModel.HostInfo{}, err
+	}
+	return toTaHostInfo(hostInfo), nil
+}
+
+func (gc *GrpcConnector) GetHostManifest() (types.HostManifest, error) {
+	log.Trace("grpc_host_connector:GetHostManifest() Entering")
+	defer log.Trace("grpc_host_connector:GetHostManifest() Leaving")
+
+	nonce, err := util.GenerateNonce(20)
+	if err != nil {
+		return types.HostManifest{}, errors.Wrap(err, "grpc_host_connector:GetHostManifest() Error generating "+
+			"nonce for TPM quote request")
+	}
+	hostManifest, err := gc.GetHostManifestAcceptNonce(nonce)
+	if err != nil {
+		return types.HostManifest{}, errors.Wrap(err, "grpc_host_connector:GetHostManifest() Error creating "+
+			"host manifest")
+	}
+	return hostManifest, nil
+}
+
+//GetHostManifestAcceptNonce accepts the nonce to support unit tests, like the Intel connector
+func (gc *GrpcConnector) GetHostManifestAcceptNonce(nonce string) (types.HostManifest, error) {
+
+	log.Trace("grpc_host_connector:GetHostManifestAcceptNonce() Entering")
+	defer log.Trace("grpc_host_connector:GetHostManifestAcceptNonce() Leaving")
+
+	pcrList := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23}
+	hostInfo, err := gc.client.GetHostInfo()
+	if err != nil {
+		return types.HostManifest{}, errors.Wrap(err, "grpc_host_connector:GetHostManifestAcceptNonce() Error getting "+
+			"host details from agent")
+	}
+	pcrBankList := hostInfo.PcrBanks
+	if len(pcrBankList) == 0 {
+		//support both pcr banks by default
+		pcrBankList = []string{"SHA1", "SHA256"}
+	}
+
+	nonceInBytes, err := base64.StdEncoding.DecodeString(nonce)
+	if err != nil {
+		return types.HostManifest{}, errors.Wrap(err, "grpc_host_connector:GetHostManifestAcceptNonce() Base64 decode of TPM "+
+			"nonce failed")
+	}
+	quoteResponse, err := gc.client.GetQuote(nonceInBytes, pcrList, pcrBankList)
+	if err != nil {
+		return types.HostManifest{}, errors.Wrap(err, "grpc_host_connector:GetHostManifestAcceptNonce() Error getting TPM "+
+			"quote response")
+	}
+	if quoteResponse.HostInfo != nil {
+		hostInfo = quoteResponse.HostInfo
+	}
+
+	aikCertificate, err := x509.ParseCertificate(quoteResponse.AikCertificate)
+	if err != nil {
+		return types.HostManifest{}, errors.Wrap(err, "grpc_host_connector:GetHostManifestAcceptNonce() Error parsing "+
+			"AIK certificate")
+	}
+
+	eventLog := string(quoteResponse.EventLog)
+	if eventLog == "" {
+		eventLog = "[]"
+	}
+	log.Info("grpc_host_connector:GetHostManifestAcceptNonce() Verifying quote and retrieving PCR manifest from TPM quote " +
+		"response ...")
+	pcrManifest, err := util.VerifyQuoteAndGetPCRManifest(eventLog, nonceInBytes,
+		util.EncodeTpmQuote(quoteResponse.Quote, quoteResponse.Signature, quoteResponse.PcrValues), aikCertificate)
+	if err != nil {
+		return types.HostManifest{}, errors.Wrap(err, "grpc_host_connector:GetHostManifestAcceptNonce() Error verifying "+
+			"TPM Quote")
+	}
+	log.Info("grpc_host_connector:GetHostManifestAcceptNonce() Successfully retrieved PCR manifest from quote")
+
+	hostManifest := types.HostManifest{
+		HostInfo:       toTaHostInfo(hostInfo),
+		PcrManifest:    pcrManifest,
+		AIKCertificate: base64.StdEncoding.EncodeToString(aikCertificate.Raw),
+	}
+	log.Info("grpc_host_connector:GetHostManifestAcceptNonce() Host manifest created successfully")
+	return hostManifest, nil
+}
+
+func (gc *GrpcConnector) DeployAssetTag(hardwareUUID, tag string) error {
+	return errors.New("grpc_host_connector:DeployAssetTag() Operation not supported")
+}
+
+func (gc *GrpcConnector) DeploySoftwareManifest(manifest taModel.Manifest) error {
+	return errors.New("grpc_host_connector:DeploySoftwareManifest() Operation not supported")
+}
+
+func (gc *GrpcConnector) GetMeasurementFromManifest(manifest taModel.Manifest) (taModel.Measurement, error) {
+	return taModel.Measurement{}, errors.New("grpc_host_connector:GetMeasurementFromManifest() Operation not supported")
+}
+
+func (gc *GrpcConnector) GetClusterReference(clusterName string) ([]mo.HostSystem, error) {
+	return nil, errors.New("grpc_host_connector:GetClusterReference() Operation not supported")
+}
+
+func toTaHostInfo(hostInfo *grpcagent.HostInfo) taModel.HostInfo {
+	taHostInfo := taModel.HostInfo{
+		OSName:              hostInfo.GetOsName(),
+		OSVersion:           hostInfo.GetOsVersion(),
+		BiosVersion:         hostInfo.GetBiosVersion(),
+		VMMName:             hostInfo.GetVmmName(),
+		VMMVersion:          hostInfo.GetVmmVersion(),
+		ProcessorInfo:       hostInfo.GetProcessorInfo(),
+		HostName:            hostInfo.GetHostName(),
+		BiosName:            hostInfo.GetBiosName(),
+		HardwareUUID:        hostInfo.GetHardwareUuid(),
+		ProcessorFlags:      hostInfo.GetProcessorFlags(),
+		NumberOfSockets:     int(hostInfo.GetNumberOfSockets()),
+		InstalledComponents: hostInfo.GetInstalledComponents(),
+	}
+	taHostInfo.HardwareFeatures.TPM.Supported = hostInfo.GetTpmEnabled()
+	taHostInfo.HardwareFeatures.TPM.Enabled = hostInfo.GetTpmEnabled()
+	taHostInfo.HardwareFeatures.TPM.Meta.TPMVersion = hostInfo.GetTpmVersion()
+	taHostInfo.HardwareFeatures.TPM.Meta.PCRBanks = strings.Join(hostInfo.GetPcrBanks(), "_")
+	taHostInfo.HardwareFeatures.TXT.Supported = hostInfo.GetTxtEnabled()
+	taHostInfo.HardwareFeatures.TXT.Enabled = hostInfo.GetTxtEnabled()
+	taHostInfo.HardwareFeatures.UEFI.Meta.SecureBootEnabled = hostInfo.GetSecureBootEnabled()
+	return taHostInfo
+}
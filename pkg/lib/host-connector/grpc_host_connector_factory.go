@@ -0,0 +1,39 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package host_connector
+
+import (
+	"crypto/x509"
+	"github.com/intel-secl/intel-secl/v3/pkg/clients/grpcagent"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector/types"
+	"github.com/pkg/errors"
+	"net/url"
+)
+
+type GrpcConnectorFactory struct {
+}
+
+func (gcf *GrpcConnectorFactory) GetHostConnector(vendorConnector types.VendorConnector, aasApiUrl string,
+	trustedCaCerts []x509.Certificate) (HostConnector, error) {
+
+	log.Trace("grpc_host_connector_factory:GetHostConnector() Entering")
+	defer log.Trace("grpc_host_connector_factory:GetHostConnector() Leaving")
+	agentApiURL, err := url.Parse(vendorConnector.Url)
+	if err != nil {
+		return nil, errors.New("grpc_host_connector_factory:GetHostConnector() error retrieving agent API URL")
+	}
+
+	agentClient, err := grpcagent.NewAgentClient(aasApiUrl,
+		agentApiURL,
+		vendorConnector.Configuration.Username,
+		vendorConnector.Configuration.Password,
+		trustedCaCerts)
+	if err != nil {
+		return nil, errors.Wrap(err, "grpc_host_connector_factory:GetHostConnector() Could not create gRPC agent client")
+	}
+
+	log.Debug("grpc_host_connector_factory:GetHostConnector() gRPC agent client created")
+	return &GrpcConnector{agentClient}, nil
+}
@@ -0,0 +1,165 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package host_connector
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/intel-secl/intel-secl/v3/pkg/clients/grpcagent"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockAas issues the current token to every user
+type mockAas struct {
+	lock  sync.Mutex
+	token string
+}
+
+func (aas *mockAas) setToken(token string) {
+	aas.lock.Lock()
+	defer aas.lock.Unlock()
+	aas.token = token
+}
+
+func (aas *mockAas) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/aas/token" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	aas.lock.Lock()
+	defer aas.lock.Unlock()
+	_, _ = w.Write([]byte(aas.token))
+}
+
+func TestGrpcConnectorGetHostManifest(t *testing.T) {
+	assertions := assert.New(t)
+
+	eventLog, err := ioutil.ReadFile("./test/sample_measure_log.json")
+	assertions.NoError(err)
+	hostInfo := &grpcagent.HostInfo{
+		HostName:     "grpc-host",
+		HardwareUuid: "8032632b-8fa4-e811-906e-00163566263e",
+		OsName:       "RedHatEnterprise",
+		OsVersion:    "8.1",
+		TpmVersion:   "2.0",
+		PcrBanks:     []string{"SHA1", "SHA256"},
+		TpmEnabled:   true,
+	}
+	mockAgent, err := grpcagent.NewMockAgentServer(hostInfo, eventLog)
+	assertions.NoError(err)
+	defer mockAgent.Close()
+	pcr0 := sha256.Sum256([]byte("pcr0"))
+	mockAgent.PcrValues["SHA256"][0] = pcr0[:]
+	mockAgent.BearerToken = "token-1"
+
+	aas := &mockAas{token: "token-1"}
+	aasServer := httptest.NewTLSServer(aas)
+	defer aasServer.Close()
+	trustedCaCerts := []x509.Certificate{*mockAgent.Server.Certificate(), *aasServer.Certificate()}
+
+	htcFactory := NewHostConnectorFactory(aasServer.URL+"/aas/", trustedCaCerts)
+	hostConnector, err := htcFactory.NewHostConnector(mockAgent.ConnectionString("agent-user", "agent-password"))
+	assertions.NoError(err)
+	grpcConnector, ok := hostConnector.(*GrpcConnector)
+	assertions.True(ok, "grpc connection string should create a GrpcConnector")
+
+	hostDetails, err := grpcConnector.GetHostDetails()
+	assertions.NoError(err)
+	assertions.Equal("grpc-host", hostDetails.HostName)
+	assertions.Equal("SHA1_SHA256", hostDetails.HardwareFeatures.TPM.Meta.PCRBanks)
+
+	hostManifest, err := grpcConnector.GetHostManifestAcceptNonce("tHgfRQED1+pYgEZpq3dZC9ONmBCZKdx10LErTZs1k/k=")
+	assertions.NoError(err)
+	assertions.Equal("8032632b-8fa4-e811-906e-00163566263e", hostManifest.HostInfo.HardwareUUID)
+	assertions.Len(hostManifest.PcrManifest.Sha1Pcrs, 24)
+	assertions.Len(hostManifest.PcrManifest.Sha256Pcrs, 24)
+	pcr, err := hostManifest.PcrManifest.GetPcrValue(types.SHA256, types.PCR0)
+	assertions.NoError(err)
+	assertions.Equal(hex.EncodeToString(pcr0[:]), pcr.Value)
+	assertions.NotEmpty(hostManifest.PcrManifest.PcrEventLogMap.Sha256EventLogs)
+	assertions.NotEmpty(hostManifest.AIKCertificate)
+
+	// the agent rejects the cached token after it rotated in AAS, the connector should fetch the new one
+	aas.setToken("token-2")
+	mockAgent.BearerToken = "token-2"
+	_, err = grpcConnector.GetHostManifest()
+	assertions.NoError(err)
+
+	mockAgent.BearerToken = "token-3"
+	_, err = grpcConnector.GetHostManifest()
+	assertions.Error(err)
+
+	assertions.Error(grpcConnector.DeployAssetTag(hostInfo.HardwareUuid, "tag"))
+}
+
+func TestGrpcConnectorRejectsTamperedQuote(t *testing.T) {
+	assertions := assert.New(t)
+
+	mockAgent, err := grpcagent.NewMockAgentServer(&grpcagent.HostInfo{PcrBanks: []string{"SHA256"}}, nil)
+	assertions.NoError(err)
+	defer mockAgent.Close()
+
+	htcFactory := NewHostConnectorFactory("", []x509.Certificate{*mockAgent.Server.Certificate()})
+	hostConnector, err := htcFactory.NewHostConnector("grpc:" + mockAgent.Server.URL)
+	assertions.NoError(err)
+	grpcConnector := hostConnector.(*GrpcConnector)
+
+	_, err = grpcConnector.GetHostManifest()
+	assertions.NoError(err)
+
+	// a quote signed by a different AIK must not verify
+	otherAgent, err := grpcagent.NewMockAgentServer(&grpcagent.HostInfo{}, nil)
+	assertions.NoError(err)
+	otherAgent.Close()
+	mockAgent.AikCertificate = otherAgent.AikCertificate
+	_, err = grpcConnector.GetHostManifest()
+	assertions.Error(err)
+}
+
+func TestGrpcConnectorTcgEventLog(t *testing.T) {
+	assertions := assert.New(t)
+
+	eventLog, err := ioutil.ReadFile("./test/sample_tcg_event_log.bin")
+	assertions.NoError(err)
+	mockAgent, err := grpcagent.NewMockAgentServer(&grpcagent.HostInfo{PcrBanks: []string{"SHA1", "SHA256"}}, eventLog)
+	assertions.NoError(err)
+	defer mockAgent.Close()
+
+	// the simulated PCRs hold the values the firmware extended into them
+	pcrEventLogMap, err := types.ParseTcgEventLog(eventLog)
+	assertions.NoError(err)
+	for _, pcrEventLog := range append(pcrEventLogMap.Sha1EventLogs, pcrEventLogMap.Sha256EventLogs...) {
+		pcrValue, err := pcrEventLog.Replay()
+		assertions.NoError(err)
+		mockAgent.PcrValues[pcrEventLog.Pcr.Bank][pcrEventLog.Pcr.Index], err = hex.DecodeString(pcrValue)
+		assertions.NoError(err)
+	}
+
+	htcFactory := NewHostConnectorFactory("", []x509.Certificate{*mockAgent.Server.Certificate()})
+	hostConnector, err := htcFactory.NewHostConnector("grpc:" + mockAgent.Server.URL)
+	assertions.NoError(err)
+
+	hostManifest, err := hostConnector.GetHostManifest()
+	assertions.NoError(err)
+	pcr7Events, err := hostManifest.PcrManifest.GetEventLogCriteria(types.SHA256, types.PCR7)
+	assertions.NoError(err)
+	assertions.Equal([]string{"SecureBoot"}, pcr7Events[0].Tags)
+
+	// an event log that does not replay to the quoted PCRs is left to the flavor rules to report
+	pcr7 := sha256.Sum256([]byte("pcr7"))
+	mockAgent.PcrValues["SHA256"][7] = pcr7[:]
+	hostManifest, err = hostConnector.GetHostManifest()
+	assertions.NoError(err)
+	assertions.Error(hostManifest.PcrManifest.VerifyEventLogReplay())
+}
@@ -46,6 +46,9 @@ func (htcFactory *HostConnectorFactory) NewHostConnector(connectionString string
 	case constants.VendorVMware:
 		log.Debug("host_connector/host_connector_factory:NewHostConnector() Connector type for provided connection string is VMWARE")
 		connectorFactory = &VmwareConnectorFactory{}
+	case constants.VendorGrpc:
+		log.Debug("host_connector/host_connector_factory:NewHostConnector() Connector type for provided connection string is GRPC")
+		connectorFactory = &GrpcConnectorFactory{}
 	default:
 		return nil, errors.New("host_connector_factory:NewHostConnector() Vendor not supported yet: " + vendorConnector.Vendor.String())
 	}
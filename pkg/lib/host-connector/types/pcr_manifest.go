@@ -61,11 +61,12 @@ type Pcr struct {
 	Bank string `json:"bank"`
 }
 type FlavorPcrs struct {
-	Pcr              Pcr            `json:"pcr"`         //required
-	Measurement      string         `json:"measurement"` //required
-	PCRMatches       bool           `json:"pcr_matches,omitempty"`
-	EventlogEqual    *EventLogEqual `json:"eventlog_equals,omitempty"`
-	EventlogIncludes []EventLog     `json:"eventlog_includes,omitempty"`
+	Pcr                    Pcr            `json:"pcr"`         //required
+	Measurement            string         `json:"measurement"` //required
+	PCRMatches             bool           `json:"pcr_matches,omitempty"`
+	EventlogEqual          *EventLogEqual `json:"eventlog_equals,omitempty"`
+	EventlogIncludes       []EventLog     `json:"eventlog_includes,omitempty"`
+	EventlogReplayAllBanks bool           `json:"eventlog_replay_all_banks,omitempty"`
 }
 
 type EventLogEqual struct {
@@ -76,10 +77,16 @@ type EventLogEqual struct {
 type PcrEventLogMap struct {
 	Sha1EventLogs   []TpmEventLog `json:"SHA1"`
 	Sha256EventLogs []TpmEventLog `json:"SHA256"`
+	Sha384EventLogs []TpmEventLog `json:"SHA384,omitempty"`
+	Sha512EventLogs []TpmEventLog `json:"SHA512,omitempty"`
 }
 type PcrManifest struct {
-	Sha1Pcrs       []HostManifestPcrs `json:"sha1pcrs"`
-	Sha256Pcrs     []HostManifestPcrs `json:"sha2pcrs"`
+	Sha1Pcrs   []HostManifestPcrs `json:"sha1pcrs"`
+	Sha256Pcrs []HostManifestPcrs `json:"sha2pcrs"`
+	// Sha384Pcrs and Sha512Pcrs are only used to verify the event log replay, flavors are created from the
+	// SHA1 and SHA256 banks
+	Sha384Pcrs     []HostManifestPcrs `json:"sha384pcrs,omitempty"`
+	Sha512Pcrs     []HostManifestPcrs `json:"sha512pcrs,omitempty"`
 	PcrEventLogMap PcrEventLogMap     `json:"pcr_event_log_map"`
 }
 
@@ -217,7 +224,7 @@ func GetPcrIndexFromString(stringValue string) (PcrIndex, error) {
 
 // Finds the Pcr in a PcrManifest provided the pcrBank and index.  Returns
 // null if not found.  Returns an error if the pcrBank is not supported
-// by intel-secl (currently supports SHA1 and SHA256).
+// by intel-secl (currently supports SHA1, SHA256, SHA384 and SHA512).
 func (pcrManifest *PcrManifest) GetPcrValue(pcrBank SHAAlgorithm, pcrIndex PcrIndex) (*HostManifestPcrs, error) {
 	// TODO: Is this the right data model for the PcrManifest?  Two things...
 	// - Flavor API returns a map[bank]map[pcrindex]
@@ -239,6 +246,20 @@ func (pcrManifest *PcrManifest) GetPcrValue(pcrBank SHAAlgorithm, pcrIndex PcrIn
 				break
 			}
 		}
+	case SHA384:
+		for _, pcr := range pcrManifest.Sha384Pcrs {
+			if pcr.Index == pcrIndex {
+				pcrValue = &pcr
+				break
+			}
+		}
+	case SHA512:
+		for _, pcr := range pcrManifest.Sha512Pcrs {
+			if pcr.Index == pcrIndex {
+				pcrValue = &pcr
+				break
+			}
+		}
 	default:
 		return nil, errors.Errorf("Unsupported sha algorithm %s", pcrBank)
 	}
@@ -261,15 +282,15 @@ func (pcrManifest *PcrManifest) GetRequiredPcrValue(bank SHAAlgorithm, pcrIndex
 	return pcrValue, nil
 }
 
-// IsEmpty returns true if both the Sha1Pcrs and Sha256Pcrs
-// are empty.
+// IsEmpty returns true if the PCRs of all banks are empty.
 func (pcrManifest *PcrManifest) IsEmpty() bool {
-	return len(pcrManifest.Sha1Pcrs) == 0 && len(pcrManifest.Sha256Pcrs) == 0
+	return len(pcrManifest.Sha1Pcrs) == 0 && len(pcrManifest.Sha256Pcrs) == 0 &&
+		len(pcrManifest.Sha384Pcrs) == 0 && len(pcrManifest.Sha512Pcrs) == 0
 }
 
 // Finds the EventLogEntry in a PcrEventLogMap provided the pcrBank and index.  Returns
 // null if not found.  Returns an error if the pcrBank is not supported
-// by intel-secl (currently supports SHA1 and SHA256).
+// by intel-secl (currently supports SHA1, SHA256, SHA384 and SHA512).
 func (pcrEventLogMap *PcrEventLogMap) GetEventLogNew(pcrBank string, pcrIndex int) ([]EventLog, int, string, error) {
 	var eventLog []EventLog
 	var pIndex int
@@ -294,6 +315,24 @@ func (pcrEventLogMap *PcrEventLogMap) GetEventLogNew(pcrBank string, pcrIndex in
 				break
 			}
 		}
+	case SHA384:
+		for _, entry := range pcrEventLogMap.Sha384EventLogs {
+			if entry.Pcr.Index == pcrIndex {
+				eventLog = entry.TpmEvent
+				pIndex = entry.Pcr.Index
+				bank = entry.Pcr.Bank
+				break
+			}
+		}
+	case SHA512:
+		for _, entry := range pcrEventLogMap.Sha512EventLogs {
+			if entry.Pcr.Index == pcrIndex {
+				eventLog = entry.TpmEvent
+				pIndex = entry.Pcr.Index
+				bank = entry.Pcr.Bank
+				break
+			}
+		}
 	default:
 		return nil, 0, "", errors.Errorf("Unsupported sha algorithm %s", pcrBank)
 	}
@@ -301,6 +340,15 @@ func (pcrEventLogMap *PcrEventLogMap) GetEventLogNew(pcrBank string, pcrIndex in
 	return eventLog, pIndex, bank, nil
 }
 
+// GetAllEventLogs returns the event logs of all banks
+func (pcrEventLogMap *PcrEventLogMap) GetAllEventLogs() []TpmEventLog {
+	var eventLogs []TpmEventLog
+	eventLogs = append(eventLogs, pcrEventLogMap.Sha1EventLogs...)
+	eventLogs = append(eventLogs, pcrEventLogMap.Sha256EventLogs...)
+	eventLogs = append(eventLogs, pcrEventLogMap.Sha384EventLogs...)
+	return append(eventLogs, pcrEventLogMap.Sha512EventLogs...)
+}
+
 // Provided an EventLogEntry that contains an array of EventLogs, this function
 // will return a new EventLogEntry that contains the events that existed in
 // the original ('eventLogEntry') but not in 'eventsToSubtract'.  Returns an error
@@ -434,6 +482,18 @@ func (pcrManifest *PcrManifest) GetEventLogCriteria(pcrBank SHAAlgorithm, pcrInd
 				return eventLogEntry.TpmEvent, nil
 			}
 		}
+	case "SHA384":
+		for _, eventLogEntry := range pcrManifest.PcrEventLogMap.Sha384EventLogs {
+			if eventLogEntry.Pcr.Index == pI {
+				return eventLogEntry.TpmEvent, nil
+			}
+		}
+	case "SHA512":
+		for _, eventLogEntry := range pcrManifest.PcrEventLogMap.Sha512EventLogs {
+			if eventLogEntry.Pcr.Index == pI {
+				return eventLogEntry.TpmEvent, nil
+			}
+		}
 	default:
 		return nil, fmt.Errorf("Unsupported sha algorithm %s", pcrBank)
 	}
@@ -0,0 +1,366 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+// The TCG PC Client Platform Firmware Profile event log, as exposed by the Linux kernel in
+// /sys/kernel/security/tpm0/binary_bios_measurements. The log starts with a TCG_PCR_EVENT in the legacy SHA1
+// format that carries the TCG_EfiSpecIDEvent, which lists the digest algorithms of the TCG_PCR_EVENT2 records
+// that follow it (crypto-agile format).
+
+const (
+	tcgEventNoAction             = 0x3
+	tcgEventEfiVariableDriverCfg = 0x80000001
+	tcgEventEfiVariableBoot      = 0x80000002
+	tcgEventEfiVariableAuthority = 0x800000e0
+
+	tcgAlgSha1   = 0x0004
+	tcgAlgSha256 = 0x000b
+	tcgAlgSha384 = 0x000c
+	tcgAlgSha512 = 0x000d
+
+	// pcrIndex, eventType, SHA1 digest and eventSize of the legacy TCG_PCR_EVENT
+	tcgPcrEventHeaderSize = 4 + 4 + 20 + 4
+	// pcrIndex, eventType and digest count of the TCG_PCR_EVENT2
+	tcgPcrEvent2HeaderSize = 4 + 4 + 4
+
+	tcgSpecIdEventSignature     = "Spec ID Event03\x00"
+	tcgStartupLocalitySignature = "StartupLocality\x00"
+	tcgStartupLocalityTag       = "StartupLocality"
+)
+
+var tcgEventTypeNames = map[uint32]string{
+	0x0:        "EV_PREBOOT_CERT",
+	0x1:        "EV_POST_CODE",
+	0x2:        "EV_UNUSED",
+	0x3:        "EV_NO_ACTION",
+	0x4:        "EV_SEPARATOR",
+	0x5:        "EV_ACTION",
+	0x6:        "EV_EVENT_TAG",
+	0x7:        "EV_S_CRTM_CONTENTS",
+	0x8:        "EV_S_CRTM_VERSION",
+	0x9:        "EV_CPU_MICROCODE",
+	0xa:        "EV_PLATFORM_CONFIG_FLAGS",
+	0xb:        "EV_TABLE_OF_DEVICES",
+	0xc:        "EV_COMPACT_HASH",
+	0xd:        "EV_IPL",
+	0xe:        "EV_IPL_PARTITION_DATA",
+	0xf:        "EV_NONHOST_CODE",
+	0x10:       "EV_NONHOST_CONFIG",
+	0x11:       "EV_NONHOST_INFO",
+	0x12:       "EV_OMIT_BOOT_DEVICE_EVENTS",
+	0x80000001: "EV_EFI_VARIABLE_DRIVER_CONFIG",
+	0x80000002: "EV_EFI_VARIABLE_BOOT",
+	0x80000003: "EV_EFI_BOOT_SERVICES_APPLICATION",
+	0x80000004: "EV_EFI_BOOT_SERVICES_DRIVER",
+	0x80000005: "EV_EFI_RUNTIME_SERVICES_DRIVER",
+	0x80000006: "EV_EFI_GPT_EVENT",
+	0x80000007: "EV_EFI_ACTION",
+	0x80000008: "EV_EFI_PLATFORM_FIRMWARE_BLOB",
+	0x80000009: "EV_EFI_HANDOFF_TABLES",
+	0x8000000a: "EV_EFI_PLATFORM_FIRMWARE_BLOB2",
+	0x8000000b: "EV_EFI_HANDOFF_TABLES2",
+	0x800000e0: "EV_EFI_VARIABLE_AUTHORITY",
+	0x800000e1: "EV_EFI_SPDM_FIRMWARE_BLOB",
+	0x800000e2: "EV_EFI_SPDM_FIRMWARE_CONFIG",
+}
+
+// the event data of these event types is a description of the measurement, it is used as the tag of the event
+var tcgDescriptiveEventTypes = map[uint32]bool{
+	0x1:        true,
+	0x5:        true,
+	0x7:        true,
+	0x8:        true,
+	0xd:        true,
+	0x80000007: true,
+}
+
+// IsTcgEventLog returns true if the event log is a TCG PC Client binary event log in the crypto-agile format
+func IsTcgEventLog(eventLog []byte) bool {
+	if len(eventLog) < tcgPcrEventHeaderSize+len(tcgSpecIdEventSignature) {
+		return false
+	}
+	return binary.LittleEndian.Uint32(eventLog[0:4]) == 0 &&
+		binary.LittleEndian.Uint32(eventLog[4:8]) == tcgEventNoAction &&
+		string(eventLog[tcgPcrEventHeaderSize:tcgPcrEventHeaderSize+len(tcgSpecIdEventSignature)]) == tcgSpecIdEventSignature
+}
+
+// ParseTcgEventLog parses a TCG PC Client binary event log in the crypto-agile format into a PcrEventLogMap. The
+// digests of banks other than SHA1, SHA256, SHA384 and SHA512 are skipped. EV_NO_ACTION events are not extended
+// into the PCRs and are dropped, except for the StartupLocality event that sets the initial value of PCR 0.
+func ParseTcgEventLog(eventLog []byte) (PcrEventLogMap, error) {
+	var pcrEventLogMap PcrEventLogMap
+	if !IsTcgEventLog(eventLog) {
+		return pcrEventLogMap, errors.New("Event log is not a crypto-agile TCG event log")
+	}
+
+	reader := tcgReader{buf: eventLog}
+	reader.skip(tcgPcrEventHeaderSize - 4)
+	specIdEvent, err := reader.next(int(reader.uint32()))
+	if err != nil {
+		return pcrEventLogMap, errors.Wrap(err, "Could not read the TCG_EfiSpecIDEvent")
+	}
+	digestSizes, err := parseTcgSpecIdEvent(specIdEvent)
+	if err != nil {
+		return pcrEventLogMap, err
+	}
+
+	for eventIndex := 1; reader.remaining() > 0; eventIndex++ {
+		// firmware can leave the unused part of the log area filled with 0x00 or 0xff
+		if isTcgEventLogPadding(reader.buf[reader.offset:]) {
+			break
+		}
+
+		pcrIndex, eventType, digests, eventData, err := reader.pcrEvent2(digestSizes)
+		if err != nil {
+			return pcrEventLogMap, errors.Wrapf(err, "Could not read TCG event %d", eventIndex)
+		}
+		if pcrIndex > uint32(PCR23) {
+			return pcrEventLogMap, errors.Errorf("TCG event %d has invalid PCR index %d", eventIndex, pcrIndex)
+		}
+
+		var tags []string
+		if eventType == tcgEventNoAction {
+			if !bytes.HasPrefix(eventData, []byte(tcgStartupLocalitySignature)) ||
+				len(eventData) <= len(tcgStartupLocalitySignature) {
+				continue
+			}
+			tags = []string{tcgStartupLocalityTag + strconv.Itoa(int(eventData[len(tcgStartupLocalitySignature)]))}
+		} else if tag := getTcgEventTag(eventType, eventData); tag != "" {
+			tags = []string{tag}
+		}
+
+		for _, bank := range []SHAAlgorithm{SHA1, SHA256, SHA384, SHA512} {
+			digest, ok := digests[bank]
+			if !ok {
+				continue
+			}
+			pcrEventLogMap.addEvent(bank, int(pcrIndex), EventLog{
+				TypeID:      fmt.Sprintf("0x%x", eventType),
+				TypeName:    tcgEventTypeNames[eventType],
+				Tags:        tags,
+				Measurement: hex.EncodeToString(digest),
+			})
+		}
+	}
+
+	return pcrEventLogMap, nil
+}
+
+// VerifyEventLogReplay replays the event log of each PCR and checks that it results in the PCR value of the
+// manifest. PCRs that have no value in the manifest are not checked.
+func (pcrManifest *PcrManifest) VerifyEventLogReplay() error {
+	eventLogs := pcrManifest.PcrEventLogMap.GetAllEventLogs()
+
+	for i := range eventLogs {
+		pcrValue, err := pcrManifest.GetPcrValue(SHAAlgorithm(eventLogs[i].Pcr.Bank), PcrIndex(eventLogs[i].Pcr.Index))
+		if err != nil {
+			return err
+		}
+		if pcrValue == nil || len(eventLogs[i].TpmEvent) == 0 {
+			continue
+		}
+
+		replayedValue, err := eventLogs[i].Replay()
+		if err != nil {
+			return errors.Wrapf(err, "Could not replay the event log of PCR %d in bank %s", eventLogs[i].Pcr.Index,
+				eventLogs[i].Pcr.Bank)
+		}
+		if !strings.EqualFold(replayedValue, pcrValue.Value) {
+			return errors.Errorf("Replay of the event log of PCR %d in bank %s does not match the PCR value",
+				eventLogs[i].Pcr.Index, eventLogs[i].Pcr.Bank)
+		}
+	}
+	return nil
+}
+
+// addEvent appends the event to the event log of the PCR, in the order of the log
+func (pcrEventLogMap *PcrEventLogMap) addEvent(bank SHAAlgorithm, pcrIndex int, event EventLog) {
+	var eventLogs *[]TpmEventLog
+	switch bank {
+	case SHA1:
+		eventLogs = &pcrEventLogMap.Sha1EventLogs
+	case SHA256:
+		eventLogs = &pcrEventLogMap.Sha256EventLogs
+	case SHA384:
+		eventLogs = &pcrEventLogMap.Sha384EventLogs
+	case SHA512:
+		eventLogs = &pcrEventLogMap.Sha512EventLogs
+	default:
+		return
+	}
+
+	for i := range *eventLogs {
+		if (*eventLogs)[i].Pcr.Index == pcrIndex {
+			(*eventLogs)[i].TpmEvent = append((*eventLogs)[i].TpmEvent, event)
+			return
+		}
+	}
+	*eventLogs = append(*eventLogs, TpmEventLog{
+		Pcr:      Pcr{Index: pcrIndex, Bank: string(bank)},
+		TpmEvent: []EventLog{event},
+	})
+}
+
+// parseTcgSpecIdEvent returns the digest size of each algorithm of the log, which is needed to walk the digests of
+// the TCG_PCR_EVENT2 records
+func parseTcgSpecIdEvent(specIdEvent []byte) (map[uint16]int, error) {
+	reader := tcgReader{buf: specIdEvent}
+	// signature, platformClass, specVersionMinor, specVersionMajor, specErrata and uintnSize
+	reader.skip(len(tcgSpecIdEventSignature) + 4 + 4)
+	numberOfAlgorithms := reader.uint32()
+	if reader.err != nil || numberOfAlgorithms == 0 || int(numberOfAlgorithms) > reader.remaining()/4 {
+		return nil, errors.New("TCG_EfiSpecIDEvent has an invalid number of algorithms")
+	}
+
+	digestSizes := make(map[uint16]int, numberOfAlgorithms)
+	for i := 0; i < int(numberOfAlgorithms); i++ {
+		algorithmId := reader.uint16()
+		digestSizes[algorithmId] = int(reader.uint16())
+	}
+	if reader.err != nil {
+		return nil, errors.Wrap(reader.err, "Could not read the algorithms of the TCG_EfiSpecIDEvent")
+	}
+	return digestSizes, nil
+}
+
+// getTcgEventTag returns the name of the UEFI variable of variable events and the description carried by the
+// descriptive events, the same tags the Trust Agent reports in its event logs
+func getTcgEventTag(eventType uint32, eventData []byte) string {
+	switch eventType {
+	case tcgEventEfiVariableDriverCfg, tcgEventEfiVariableBoot, tcgEventEfiVariableAuthority:
+		// UEFI_VARIABLE_DATA: VariableName GUID, UnicodeNameLength, VariableDataLength and UnicodeName
+		reader := tcgReader{buf: eventData}
+		reader.skip(16)
+		nameLength := reader.uint64()
+		reader.skip(8)
+		if reader.err != nil || nameLength > uint64(reader.remaining()/2) {
+			return ""
+		}
+		name := make([]uint16, nameLength)
+		for i := range name {
+			name[i] = reader.uint16()
+		}
+		return strings.TrimRight(string(utf16.Decode(name)), "\x00")
+	}
+
+	if !tcgDescriptiveEventTypes[eventType] {
+		return ""
+	}
+	description := strings.TrimRight(string(eventData), "\x00")
+	for _, c := range description {
+		if c < 0x20 || c > 0x7e {
+			return ""
+		}
+	}
+	return description
+}
+
+func isTcgEventLogPadding(b []byte) bool {
+	return len(bytes.Trim(b, "\x00")) == 0 || len(bytes.Trim(b, "\xff")) == 0
+}
+
+// tcgReader reads the little endian fields of the event log, the first read past the end of the buffer sets err
+type tcgReader struct {
+	buf    []byte
+	offset int
+	err    error
+}
+
+func (r *tcgReader) remaining() int {
+	return len(r.buf) - r.offset
+}
+
+func (r *tcgReader) next(n int) ([]byte, error) {
+	if r.err == nil && (n < 0 || n > r.remaining()) {
+		r.err = errors.Errorf("Unexpected end of event log at offset %d", r.offset)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	b := r.buf[r.offset : r.offset+n]
+	r.offset += n
+	return b, nil
+}
+
+func (r *tcgReader) skip(n int) {
+	_, _ = r.next(n)
+}
+
+func (r *tcgReader) uint16() uint16 {
+	if b, err := r.next(2); err == nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *tcgReader) uint32() uint32 {
+	if b, err := r.next(4); err == nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *tcgReader) uint64() uint64 {
+	if b, err := r.next(8); err == nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+// pcrEvent2 reads a TCG_PCR_EVENT2 record and returns its digests of the supported banks
+func (r *tcgReader) pcrEvent2(digestSizes map[uint16]int) (uint32, uint32, map[SHAAlgorithm][]byte, []byte, error) {
+	if r.remaining() < tcgPcrEvent2HeaderSize {
+		return 0, 0, nil, nil, errors.Errorf("Unexpected end of event log at offset %d", r.offset)
+	}
+	pcrIndex := r.uint32()
+	eventType := r.uint32()
+	digestCount := r.uint32()
+	if int(digestCount) > len(digestSizes) {
+		return 0, 0, nil, nil, errors.Errorf("Invalid digest count %d", digestCount)
+	}
+
+	digests := make(map[SHAAlgorithm][]byte, digestCount)
+	for i := 0; i < int(digestCount); i++ {
+		algorithmId := r.uint16()
+		digestSize, ok := digestSizes[algorithmId]
+		if !ok {
+			return 0, 0, nil, nil, errors.Errorf("Digest algorithm 0x%x is not listed in the TCG_EfiSpecIDEvent",
+				algorithmId)
+		}
+		digest, err := r.next(digestSize)
+		if err != nil {
+			return 0, 0, nil, nil, err
+		}
+		switch algorithmId {
+		case tcgAlgSha1:
+			digests[SHA1] = digest
+		case tcgAlgSha256:
+			digests[SHA256] = digest
+		case tcgAlgSha384:
+			digests[SHA384] = digest
+		case tcgAlgSha512:
+			digests[SHA512] = digest
+		}
+	}
+
+	eventData, err := r.next(int(r.uint32()))
+	if err != nil {
+		return 0, 0, nil, nil, err
+	}
+	return pcrIndex, eventType, digests, eventData, nil
+}
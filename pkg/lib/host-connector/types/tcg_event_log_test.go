@@ -0,0 +1,93 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTcgEventLog(t *testing.T) {
+	assertions := assert.New(t)
+
+	eventLog, err := ioutil.ReadFile("../test/sample_tcg_event_log.bin")
+	assertions.NoError(err)
+	assertions.True(IsTcgEventLog(eventLog))
+	assertions.False(IsTcgEventLog([]byte("[]")))
+
+	pcrEventLogMap, err := ParseTcgEventLog(eventLog)
+	assertions.NoError(err)
+	assertions.Len(pcrEventLogMap.Sha1EventLogs, 9)
+	assertions.Len(pcrEventLogMap.Sha256EventLogs, 9)
+	assertions.Len(pcrEventLogMap.Sha384EventLogs, 9)
+	assertions.Empty(pcrEventLogMap.Sha512EventLogs)
+
+	pcr0Events, _, _, err := pcrEventLogMap.GetEventLogNew(string(SHA256), 0)
+	assertions.NoError(err)
+	// the SP800-155 EV_NO_ACTION event is dropped
+	assertions.Len(pcr0Events, 4)
+	assertions.Equal(StartupLocalityEvent, pcr0Events[0].TypeName)
+	assertions.Equal([]string{StartupLocalityTag}, pcr0Events[0].Tags)
+	assertions.Equal(EventLog{
+		TypeID:      "0x8",
+		TypeName:    "EV_S_CRTM_VERSION",
+		Tags:        []string{"1.0.0"},
+		Measurement: sha256Hex([]byte("1.0.0\x00")),
+	}, pcr0Events[1])
+
+	pcr7Events, _, _, err := pcrEventLogMap.GetEventLogNew(string(SHA256), 7)
+	assertions.NoError(err)
+	assertions.Equal("EV_EFI_VARIABLE_DRIVER_CONFIG", pcr7Events[0].TypeName)
+	assertions.Equal([]string{"SecureBoot"}, pcr7Events[0].Tags)
+	assertions.Equal("EV_SEPARATOR", pcr7Events[1].TypeName)
+	assertions.Equal(sha256Hex(make([]byte, 4)), pcr7Events[1].Measurement)
+
+	pcr8Events, _, _, err := pcrEventLogMap.GetEventLogNew(string(SHA1), 8)
+	assertions.NoError(err)
+	assertions.Equal([]string{"grub_cmd: linux /vmlinuz"}, pcr8Events[0].Tags)
+	assertions.Len(pcr8Events[0].Measurement, 40)
+
+	_, err = ParseTcgEventLog(eventLog[:len(eventLog)-100])
+	assertions.Error(err, "truncated event log must fail")
+}
+
+func TestVerifyEventLogReplay(t *testing.T) {
+	assertions := assert.New(t)
+
+	eventLog, err := ioutil.ReadFile("../test/sample_tcg_event_log.bin")
+	assertions.NoError(err)
+	pcrEventLogMap, err := ParseTcgEventLog(eventLog)
+	assertions.NoError(err)
+
+	// PCR 0 starts at locality 3 and is extended with the S-CRTM version, the POST code and the separator
+	pcr0 := make([]byte, sha256.Size)
+	pcr0[sha256.Size-1] = 3
+	for _, event := range [][]byte{[]byte("1.0.0\x00"), []byte("ACPI DATA"), make([]byte, 4)} {
+		digest := sha256.Sum256(event)
+		extended := sha256.Sum256(append(pcr0, digest[:]...))
+		pcr0 = extended[:]
+	}
+
+	pcrManifest := PcrManifest{
+		Sha256Pcrs: []HostManifestPcrs{
+			{Index: PCR0, Value: hex.EncodeToString(pcr0), PcrBank: SHA256},
+		},
+		PcrEventLogMap: pcrEventLogMap,
+	}
+	assertions.NoError(pcrManifest.VerifyEventLogReplay())
+
+	pcrManifest.Sha256Pcrs[0].Value = sha256Hex(pcr0)
+	assertions.Error(pcrManifest.VerifyEventLogReplay())
+}
+
+func sha256Hex(b []byte) string {
+	digest := sha256.Sum256(b)
+	return hex.EncodeToString(digest[:])
+}
@@ -58,6 +58,16 @@ type pcrSelection struct {
 	pcrSelected []byte
 }
 
+// EncodeTpmQuote lays out a TPM2_Quote the way it is returned by the Trust Agent, which is the format verified by
+// VerifyQuoteAndGetPCRManifest: the TPM2B_ATTEST of the quote, its TPMT_SIGNATURE and the quoted PCR values
+func EncodeTpmQuote(attest, signature, pcrValues []byte) []byte {
+	quote := make([]byte, 2, 2+len(attest)+len(signature)+len(pcrValues))
+	binary.BigEndian.PutUint16(quote, uint16(len(attest)))
+	quote = append(quote, attest...)
+	quote = append(quote, signature...)
+	return append(quote, pcrValues...)
+}
+
 func VerifyQuoteAndGetPCRManifest(decodedEventLog string, verificationNonce []byte, tpmQuoteInBytes []byte,
 	aikCertificate *x509.Certificate) (types.PcrManifest, error) {
 
@@ -176,7 +186,7 @@ func VerifyQuoteAndGetPCRManifest(decodedEventLog string, verificationNonce []by
 			"AIK Quote verification failed, No PCR values included in quote")
 	}
 	pcrs := tpmtSig[pos : pos+pcrLen]
-	pcrConcatLen := SHA256_SIZE * 24 * 3
+	pcrConcatLen := SHA512_SIZE * 24 * MAX_PCR_BANKS
 	pcrPos := 0
 	count := 0
 	var pcrConcat []byte
@@ -208,9 +218,14 @@ func VerifyQuoteAndGetPCRManifest(decodedEventLog string, verificationNonce []by
 					buffer.WriteString(fmt.Sprintf("%2d ", pcr))
 				} else if hashAlg == TPM_API_ALG_ID_SHA256 {
 					buffer.WriteString(fmt.Sprintf("%2d_SHA256 ", pcr))
+				} else if hashAlg == TPM_API_ALG_ID_SHA384 {
+					buffer.WriteString(fmt.Sprintf("%2d_SHA384 ", pcr))
+				} else if hashAlg == TPM_API_ALG_ID_SHA512 {
+					buffer.WriteString(fmt.Sprintf("%2d_SHA512 ", pcr))
 				}
-				//Ignore the pcr banks other than SHA1 and SHA256
-				if hashAlg == TPM_API_ALG_ID_SHA1 || hashAlg == TPM_API_ALG_ID_SHA256 {
+				//Ignore the pcr banks other than SHA1, SHA256, SHA384 and SHA512
+				if hashAlg == TPM_API_ALG_ID_SHA1 || hashAlg == TPM_API_ALG_ID_SHA256 ||
+					hashAlg == TPM_API_ALG_ID_SHA384 || hashAlg == TPM_API_ALG_ID_SHA512 {
 					for i := 0; i < pcrSize; i++ {
 						buffer.WriteString(fmt.Sprintf("%02x", pcrs[pcrPos+i]))
 					}
@@ -334,12 +349,40 @@ func createPCRManifest(pcrList []string, eventLog string) (types.PcrManifest, er
 						Value:   pcrValue,
 						PcrBank: shaAlgorithm,
 					})
+				} else if strings.EqualFold(pcrBank, "SHA384") {
+					pcrManifest.Sha384Pcrs = append(pcrManifest.Sha384Pcrs, types.HostManifestPcrs{
+						Index:   pcrIndex,
+						Value:   pcrValue,
+						PcrBank: shaAlgorithm,
+					})
+				} else if strings.EqualFold(pcrBank, "SHA512") {
+					pcrManifest.Sha512Pcrs = append(pcrManifest.Sha512Pcrs, types.HostManifestPcrs{
+						Index:   pcrIndex,
+						Value:   pcrValue,
+						PcrBank: shaAlgorithm,
+					})
 				}
 			} else {
 				log.Warn("util/aik_quote_verifier:createPCRManifest() Result PCR invalid")
 			}
 		}
 	}
+	if tcgEventLog, ok := getTcgEventLog(eventLog); ok {
+		pcrManifest.PcrEventLogMap, err = types.ParseTcgEventLog(tcgEventLog)
+		if err != nil {
+			return pcrManifest, errors.Wrap(err, "util/aik_quote_verifier:createPCRManifest() Error parsing TCG "+
+				"event log")
+		}
+		// the TCG event log holds every measurement extended into the PCRs. A replay that does not match the quote
+		// is reported by the event log rules of the flavors, per PCR and bank, so the manifest is still returned.
+		err = pcrManifest.VerifyEventLogReplay()
+		if err != nil {
+			log.WithError(err).Warn("util/aik_quote_verifier:createPCRManifest() TCG event log does not match " +
+				"the quoted PCRs")
+		}
+		return pcrManifest, nil
+	}
+
 	pcrManifest.PcrEventLogMap, err = getPcrEventLog(eventLog)
 	if err != nil {
 		log.Errorf("util/aik_quote_verifier:createPCRManifest() Error getting PCR event log : %s", err.Error())
@@ -349,6 +392,19 @@ func createPCRManifest(pcrList []string, eventLog string) (types.PcrManifest, er
 	return pcrManifest, nil
 }
 
+// getTcgEventLog returns the TCG binary event log forwarded by the agent, either as is or base64 encoded when it is
+// carried in a text document
+func getTcgEventLog(eventLog string) ([]byte, bool) {
+	if types.IsTcgEventLog([]byte(eventLog)) {
+		return []byte(eventLog), true
+	}
+	if decodedEventLog, err := base64.StdEncoding.DecodeString(strings.TrimSpace(eventLog)); err == nil &&
+		types.IsTcgEventLog(decodedEventLog) {
+		return decodedEventLog, true
+	}
+	return nil, false
+}
+
 func getPcrEventLog(eventLog string) (types.PcrEventLogMap, error) {
 
 	log.Trace("util/aik_quote_verifier:getPcrEventLog() Entering")
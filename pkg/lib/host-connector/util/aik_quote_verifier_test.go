@@ -11,6 +11,7 @@ import (
 	"encoding/pem"
 	"encoding/xml"
 	"io/ioutil"
+	"strings"
 	"testing"
 
 	taModel "github.com/intel-secl/intel-secl/v3/pkg/model/ta"
@@ -92,3 +93,16 @@ func TestGetVerificationNonceAssetTagProvisioned(t *testing.T) {
 	_, err = GetVerificationNonce(nonceInBytes, tpmQuoteResponse)
 	assert.NoError(t, err)
 }
+
+func TestCreatePCRManifestEventLogReplayMismatch(t *testing.T) {
+	tcgEventLog, err := ioutil.ReadFile("../test/sample_tcg_event_log.bin")
+	assert.NoError(t, err)
+
+	// the quoted value of PCR 7 does not match the replay of its event log, the mismatch is left to the flavor rules
+	pcrList := []string{"7_SHA256 " + strings.Repeat("00", 32)}
+	pcrManifest, err := createPCRManifest(pcrList, base64.StdEncoding.EncodeToString(tcgEventLog))
+	assert.NoError(t, err)
+	assert.Error(t, pcrManifest.VerifyEventLogReplay())
+	assert.NotEmpty(t, pcrManifest.PcrEventLogMap.Sha256EventLogs)
+	assert.Equal(t, 1, len(pcrManifest.Sha256Pcrs))
+}
@@ -17,6 +17,7 @@ func TestGetConnectorDetails(t *testing.T) {
 	sampleUrl3 := "vmware:https://vsphere.com:443/sdk;h=hostName;u=admin.local;p=password"
 	sampleUrl4 := "https://vsphere.com:443/sdk;h=hostName;u=admin.local;p=password"
 	sampleUrl5 := "microsoft:https://microsoft.com:1443;u=admin.local;p=password"
+	sampleUrl6 := "grpc:https://agent.ip.com:1443;u=admin;p=password"
 
 	invalidUrl := "https:// abcde"
 
@@ -40,6 +41,12 @@ func TestGetConnectorDetails(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, constants.VendorMicrosoft, connectorDetails.Vendor)
 
+	connectorDetails, err = GetConnectorDetails(sampleUrl6)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.VendorGrpc, connectorDetails.Vendor)
+	assert.Equal(t, "https://agent.ip.com:1443", connectorDetails.Url)
+	assert.Equal(t, "admin", connectorDetails.Configuration.Username)
+
 	connectorDetails, err = GetConnectorDetails(invalidUrl)
 	assert.Error(t, err)
 }
@@ -12,6 +12,7 @@ import (
 	commLog "github.com/intel-secl/intel-secl/v3/pkg/lib/common/log"
 	rtvalidator "github.com/mattermost/xml-roundtrip-validator"
 	dsig "github.com/russellhaering/goxmldsig"
+	"io/ioutil"
 	"strings"
 )
 
@@ -23,15 +24,30 @@ func VerifySamlSignature(samlReport, SamlCertPath, CACertDirPath string) bool {
 	log.Trace("saml/saml-verifier:VerifySamlSignature() Entering")
 	defer log.Trace("saml/saml-verifier:VerifySamlSignature() Leaving")
 
-	caCerts, err := crypt.GetCertsFromDir(CACertDirPath)
+	samlCertPem, err := ioutil.ReadFile(SamlCertPath)
 	if err != nil {
-		log.WithError(err).Errorf("saml/saml-verifier:VerifySamlSignature() Error retrieving CA certificates from %s", CACertDirPath)
+		log.WithError(err).Error("saml/saml-verifier:VerifySamlSignature() Error while retrieving SAML certificate")
 		return false
 	}
 
-	certPemSlice, err := crypt.GetSubjectCertsMapFromPemFile(SamlCertPath)
+	return VerifySamlSignatureWithCertPem(samlReport, samlCertPem, CACertDirPath)
+}
+
+//VerifySamlSignatureWithCertPem verifies the signature of the SAML report with the SAML certificate chain in PEM
+func VerifySamlSignatureWithCertPem(samlReport string, samlCertPem []byte, CACertDirPath string) bool {
+
+	log.Trace("saml/saml-verifier:VerifySamlSignatureWithCertPem() Entering")
+	defer log.Trace("saml/saml-verifier:VerifySamlSignatureWithCertPem() Leaving")
+
+	caCerts, err := crypt.GetCertsFromDir(CACertDirPath)
 	if err != nil {
-		log.WithError(err).Error("saml/saml-verifier:VerifySamlSignature() Error while retrieving SAML certificate")
+		log.WithError(err).Errorf("saml/saml-verifier:VerifySamlSignatureWithCertPem() Error retrieving CA certificates from %s", CACertDirPath)
+		return false
+	}
+
+	certPemSlice, err := crypt.GetSubjectCertsMapFromPem(samlCertPem)
+	if err != nil || len(certPemSlice) == 0 {
+		log.WithError(err).Error("saml/saml-verifier:VerifySamlSignatureWithCertPem() Error while retrieving SAML certificate")
 		return false
 	}
 
@@ -47,7 +63,7 @@ func VerifySamlSignature(samlReport, SamlCertPath, CACertDirPath string) bool {
 			if _, err := cert.Verify(verifyRootCAOpts); err != nil {
 				continue
 			} else {
-				log.Info("saml/saml-verifier:VerifySamlSignature() SAML certificate chain verification successful")
+				log.Info("saml/saml-verifier:VerifySamlSignatureWithCertPem() SAML certificate chain verification successful")
 				trustedCertChainFound = true
 				break
 			}
@@ -55,21 +71,21 @@ func VerifySamlSignature(samlReport, SamlCertPath, CACertDirPath string) bool {
 	}
 
 	if !trustedCertChainFound {
-		log.Error("saml/saml-verifier:VerifySamlSignature() Error verifying certificate chain for SAML certificate. No " +
+		log.Error("saml/saml-verifier:VerifySamlSignatureWithCertPem() Error verifying certificate chain for SAML certificate. No " +
 			"valid certificate chain could be found")
 		return false
 	}
 
 	pemBlock, _ := pem.Decode(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certPemSlice[0].Raw}))
 
-	log.Debug("saml/saml-verifier:VerifySamlSignature() Validating saml signature from HVS")
+	log.Debug("saml/saml-verifier:VerifySamlSignatureWithCertPem() Validating saml signature from HVS")
 	isValidated := validateSamlSignature(samlReport, pemBlock.Bytes)
 	if !isValidated {
-		log.Error("saml/saml-verifier:VerifySamlSignature() SAML signature verification failed")
+		log.Error("saml/saml-verifier:VerifySamlSignatureWithCertPem() SAML signature verification failed")
 		return false
 	}
 
-	log.Info("saml/saml-verifier:VerifySamlSignature() Successfully validated SAML signature")
+	log.Info("saml/saml-verifier:VerifySamlSignatureWithCertPem() Successfully validated SAML signature")
 	return true
 }
 
@@ -91,6 +91,27 @@ func getPcrEventLogIntegrityRules(pcrLogData *types.FlavorPcrs, marker common.Fl
 	return pcrRules, nil
 }
 
+//getPcrEventLogReplayAllBanksRules method will create PcrEventLogReplayAllBanksRule for the pcr index and return the rule
+//return nil if error occurs
+func getPcrEventLogReplayAllBanksRules(pcrIndex int, flavorPcrs []types.FlavorPcrs, marker common.FlavorPart) ([]rules.Rule, error) {
+	var pcrRules []rules.Rule
+
+	var expectedPcrs []types.FlavorPcrs
+	for _, flavorPcr := range flavorPcrs {
+		if flavorPcr.Pcr.Index == pcrIndex {
+			expectedPcrs = append(expectedPcrs, flavorPcr)
+		}
+	}
+
+	rule, err := rules.NewPcrEventLogReplayAllBanks(expectedPcrs, marker)
+	if err != nil {
+		return nil, errors.Wrapf(err, "An error occurred creating a PcrEventLogReplayAllBanks rule for index '%d'", pcrIndex)
+	}
+	pcrRules = append(pcrRules, rule)
+
+	return pcrRules, nil
+}
+
 //getAssetTagMatchesRule method will create AssetTagMatchesRule and return the rule
 //return nil if error occurs
 func getAssetTagMatchesRule(flavor *hvs.Flavor) (rules.Rule, error) {
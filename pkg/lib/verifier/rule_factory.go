@@ -83,6 +83,8 @@ func (factory *ruleFactory) GetVerificationRules() ([]rules.Rule, string, error)
 	log.Info("requiredRules:", requiredRules)
 
 	flavorPcrs := factory.signedFlavor.Flavor.Pcrs
+	// the event logs of all the banks of a pcr index are replayed by a single rule
+	replayedPcrIndexes := make(map[int]bool)
 
 	// Iterate the pcrs section to get rules
 	for _, rule := range flavorPcrs {
@@ -109,6 +111,12 @@ func (factory *ruleFactory) GetVerificationRules() ([]rules.Rule, string, error)
 				//call method to create pcr matches constant rule
 				pcrRules, err = getPcrMatchesConstantRules(&rule, flavorPart)
 				requiredRules = append(requiredRules, pcrRules...)
+			} else if value.Type().Field(i).Name == hvsconstants.EventlogReplayAllBanksRule && rule.EventlogReplayAllBanks &&
+				!replayedPcrIndexes[rule.Pcr.Index] {
+				replayedPcrIndexes[rule.Pcr.Index] = true
+				//call method to create pcr event log replay all banks rule
+				pcrRules, err = getPcrEventLogReplayAllBanksRules(rule.Pcr.Index, flavorPcrs, flavorPart)
+				requiredRules = append(requiredRules, pcrRules...)
 			}
 
 			if eventsPresent == true && integrityRuleAdded == false {
@@ -0,0 +1,195 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package rules
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	constants "github.com/intel-secl/intel-secl/v3/pkg/hvs/constants/verifier-rules-and-faults"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/flavor/common"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector/types"
+	"github.com/intel-secl/intel-secl/v3/pkg/model/hvs"
+	"github.com/pkg/errors"
+)
+
+// the banks that are replayed, in the order their faults are reported
+var replayedPcrBanks = []types.SHAAlgorithm{types.SHA1, types.SHA256, types.SHA384, types.SHA512}
+
+// NewPcrEventLogReplayAllBanks creates a rule that replays the event log of a PCR index in every bank of the
+// host-manifest and checks that each replay matches the PCR value of its bank. The flavor PCRs of that index are
+// used to find the first divergent event of a bank that does not replay.
+func NewPcrEventLogReplayAllBanks(expectedPcrs []types.FlavorPcrs, marker common.FlavorPart) (Rule, error) {
+	if len(expectedPcrs) == 0 {
+		return nil, errors.New("The expected pcrs cannot be empty")
+	}
+
+	for _, expectedPcr := range expectedPcrs {
+		if expectedPcr.Pcr.Index != expectedPcrs[0].Pcr.Index {
+			return nil, errors.New("The expected pcrs must have the same index")
+		}
+	}
+
+	rule := pcrEventLogReplayAllBanks{
+		expectedPcrs: expectedPcrs,
+		marker:       marker,
+	}
+
+	return &rule, nil
+}
+
+type pcrEventLogReplayAllBanks struct {
+	expectedPcrs []types.FlavorPcrs
+	marker       common.FlavorPart
+}
+
+type bankReplay struct {
+	bank            types.SHAAlgorithm
+	events          []types.EventLog
+	calculatedValue string
+	actualValue     string
+}
+
+func (replay *bankReplay) matches() bool {
+	return strings.EqualFold(replay.calculatedValue, replay.actualValue)
+}
+
+// - If the hostmanifest's PcrManifest is not present, create PcrManifestMissing fault.
+// - If the hostmanifest does not contain the pcr index in any bank, create a PcrValueMissing fault.
+// - If the hostmanifest does not have an event log for a bank that contains the pcr index, create a
+//   PcrEventLogMissing fault.
+// - Otherwise, replay the event log of each bank and verify the calculated hash matches the pcr value
+//   of that bank.  If not, create a PcrEventLogInvalid fault with the first divergent event of the bank.
+func (rule *pcrEventLogReplayAllBanks) Apply(hostManifest *types.HostManifest) (*hvs.RuleResult, error) {
+	result := hvs.RuleResult{}
+	result.Trusted = true
+	result.Rule.Name = constants.RulePcrEventLogReplayAllBanks
+
+	result.Rule.ExpectedPcr = &rule.expectedPcrs[0]
+	result.Rule.Markers = append(result.Rule.Markers, rule.marker)
+
+	if hostManifest.PcrManifest.IsEmpty() {
+		result.Faults = append(result.Faults, newPcrManifestMissingFault())
+		return &result, nil
+	}
+
+	pcrIndex := types.PcrIndex(rule.expectedPcrs[0].Pcr.Index)
+	pcrFound := false
+	var replays []bankReplay
+	for _, bank := range replayedPcrBanks {
+		actualPcr, err := hostManifest.PcrManifest.GetPcrValue(bank, pcrIndex)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error in getting actual Pcr in Pcr Eventlog Replay All Banks rule")
+		}
+		if actualPcr == nil {
+			continue
+		}
+		pcrFound = true
+
+		actualEventLogCriteria, _, _, err := hostManifest.PcrManifest.PcrEventLogMap.GetEventLogNew(string(bank), int(pcrIndex))
+		if err != nil {
+			return nil, errors.Wrap(err, "Error in getting actual eventlogs in Pcr Eventlog Replay All Banks rule")
+		}
+		if actualEventLogCriteria == nil {
+			result.Faults = append(result.Faults, newPcrEventLogMissingFault(pcrIndex, bank))
+			continue
+		}
+
+		actualEventLog := types.TpmEventLog{
+			Pcr: types.Pcr{
+				Index: int(pcrIndex),
+				Bank:  string(bank),
+			},
+			TpmEvent: actualEventLogCriteria,
+		}
+		calculatedValue, err := actualEventLog.Replay()
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error in calculating replay of bank %s in Pcr Eventlog Replay All Banks rule", bank)
+		}
+		replays = append(replays, bankReplay{
+			bank:            bank,
+			events:          actualEventLogCriteria,
+			calculatedValue: calculatedValue,
+			actualValue:     actualPcr.Value,
+		})
+	}
+
+	if !pcrFound {
+		result.Faults = append(result.Faults, newPcrValueMissingFault(types.SHAAlgorithm(rule.expectedPcrs[0].Pcr.Bank), pcrIndex))
+	}
+
+	for i := range replays {
+		if replays[i].matches() {
+			continue
+		}
+
+		description := fmt.Sprintf("PCR %d Event Log of bank %s is invalid, mismatches between calculated event log values %s and actual pcr values %s",
+			pcrIndex, replays[i].bank, replays[i].calculatedValue, replays[i].actualValue)
+		divergentEntry, divergentIndex := rule.getDivergentEntry(&replays[i], replays)
+		if divergentEntry != nil {
+			description += fmt.Sprintf(", first divergent event is entry %d of type %s", divergentIndex, divergentEntry.TypeID)
+		}
+
+		PI := pcrIndex
+		bank := replays[i].bank
+		result.Faults = append(result.Faults, hvs.Fault{
+			Name:            constants.FaultPcrEventLogInvalid,
+			Description:     description,
+			PcrIndex:        &PI,
+			PcrBank:         &bank,
+			CalculatedValue: &replays[i].calculatedValue,
+			ActualPcrValue:  &replays[i].actualValue,
+			DivergentEntry:  divergentEntry,
+		})
+	}
+
+	return &result, nil
+}
+
+// getDivergentEntry returns the first event of a bank that does not replay, along with its position in the log.
+// The events are compared with the events the flavor expects in that bank when the flavor holds the complete log
+// of the PCR. Otherwise they are compared with the log of a bank that replays: every event is extended into all the
+// banks, so the logs of the banks are expected to list the same events in the same order. Returns nil if the
+// divergent event cannot be identified, e.g. when the logs only differ by the PCR value.
+func (rule *pcrEventLogReplayAllBanks) getDivergentEntry(replay *bankReplay, replays []bankReplay) (*types.EventLog, int) {
+	for _, expectedPcr := range rule.expectedPcrs {
+		if types.SHAAlgorithm(expectedPcr.Pcr.Bank) != replay.bank || expectedPcr.EventlogEqual == nil ||
+			len(expectedPcr.EventlogEqual.ExcludeTags) > 0 {
+			continue
+		}
+		return getFirstDivergentEntry(replay.events, expectedPcr.EventlogEqual.Events, func(actual, expected *types.EventLog) bool {
+			return actual.TypeID == expected.TypeID && strings.EqualFold(actual.Measurement, expected.Measurement)
+		})
+	}
+
+	for i := range replays {
+		if !replays[i].matches() {
+			continue
+		}
+		return getFirstDivergentEntry(replay.events, replays[i].events, func(actual, reference *types.EventLog) bool {
+			return actual.TypeID == reference.TypeID && actual.TypeName == reference.TypeName &&
+				(len(actual.Tags) == 0 && len(reference.Tags) == 0 || reflect.DeepEqual(actual.Tags, reference.Tags))
+		})
+	}
+
+	return nil, -1
+}
+
+// getFirstDivergentEntry walks both logs in order and returns the first actual event that does not match its
+// reference event. If the actual log is shorter, the first reference event that is missing from it is returned.
+func getFirstDivergentEntry(actualEvents, referenceEvents []types.EventLog, match func(actual, reference *types.EventLog) bool) (*types.EventLog, int) {
+	for i := range actualEvents {
+		if i >= len(referenceEvents) || !match(&actualEvents[i], &referenceEvents[i]) {
+			divergentEntry := actualEvents[i]
+			return &divergentEntry, i
+		}
+	}
+	if len(referenceEvents) > len(actualEvents) {
+		divergentEntry := referenceEvents[len(actualEvents)]
+		return &divergentEntry, len(actualEvents)
+	}
+	return nil, -1
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package rules
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	constants "github.com/intel-secl/intel-secl/v3/pkg/hvs/constants/verifier-rules-and-faults"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/flavor/common"
+	"github.com/intel-secl/intel-secl/v3/pkg/lib/host-connector/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// newReplayAllBanksHostManifest returns a host manifest with the SHA1, SHA256 and SHA384 event logs of the sample
+// TCG event log, and the PCR values they replay to
+func newReplayAllBanksHostManifest(t *testing.T) types.HostManifest {
+	eventLog, err := ioutil.ReadFile("../../host-connector/test/sample_tcg_event_log.bin")
+	assert.NoError(t, err)
+	pcrEventLogMap, err := types.ParseTcgEventLog(eventLog)
+	assert.NoError(t, err)
+
+	hostManifest := types.HostManifest{}
+	hostManifest.PcrManifest.PcrEventLogMap = pcrEventLogMap
+	for _, eventLog := range pcrEventLogMap.GetAllEventLogs() {
+		pcrValue, err := eventLog.Replay()
+		assert.NoError(t, err)
+		pcr := types.HostManifestPcrs{
+			Index:   types.PcrIndex(eventLog.Pcr.Index),
+			Value:   pcrValue,
+			PcrBank: types.SHAAlgorithm(eventLog.Pcr.Bank),
+		}
+		switch pcr.PcrBank {
+		case types.SHA1:
+			hostManifest.PcrManifest.Sha1Pcrs = append(hostManifest.PcrManifest.Sha1Pcrs, pcr)
+		case types.SHA256:
+			hostManifest.PcrManifest.Sha256Pcrs = append(hostManifest.PcrManifest.Sha256Pcrs, pcr)
+		case types.SHA384:
+			hostManifest.PcrManifest.Sha384Pcrs = append(hostManifest.PcrManifest.Sha384Pcrs, pcr)
+		}
+	}
+	return hostManifest
+}
+
+func TestPcrEventLogReplayAllBanksNoFault(t *testing.T) {
+	hostManifest := newReplayAllBanksHostManifest(t)
+
+	rule, err := NewPcrEventLogReplayAllBanks([]types.FlavorPcrs{{Pcr: types.Pcr{Index: 7, Bank: "SHA256"}}}, common.FlavorPartOs)
+	assert.NoError(t, err)
+	result, err := rule.Apply(&hostManifest)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.RulePcrEventLogReplayAllBanks, result.Rule.Name)
+	assert.Equal(t, 0, len(result.Faults))
+	assert.True(t, result.Trusted)
+}
+
+func TestPcrEventLogReplayAllBanksUnexpectedEventFault(t *testing.T) {
+	hostManifest := newReplayAllBanksHostManifest(t)
+
+	// an event that was not extended into the SHA384 bank is inserted in its log
+	unexpectedEvent := types.EventLog{
+		TypeID:      "0x80000003",
+		TypeName:    "EV_EFI_BOOT_SERVICES_APPLICATION",
+		Measurement: strings.Repeat("ab", 48),
+	}
+	for i, eventLog := range hostManifest.PcrManifest.PcrEventLogMap.Sha384EventLogs {
+		if eventLog.Pcr.Index == 7 {
+			hostManifest.PcrManifest.PcrEventLogMap.Sha384EventLogs[i].TpmEvent = append([]types.EventLog{
+				eventLog.TpmEvent[0], unexpectedEvent}, eventLog.TpmEvent[1:]...)
+		}
+	}
+
+	rule, err := NewPcrEventLogReplayAllBanks([]types.FlavorPcrs{{Pcr: types.Pcr{Index: 7, Bank: "SHA256"}}}, common.FlavorPartOs)
+	assert.NoError(t, err)
+	result, err := rule.Apply(&hostManifest)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result.Faults))
+	assert.Equal(t, constants.FaultPcrEventLogInvalid, result.Faults[0].Name)
+	assert.Equal(t, types.SHA384, *result.Faults[0].PcrBank)
+	assert.Equal(t, types.PCR7, *result.Faults[0].PcrIndex)
+	assert.Equal(t, &unexpectedEvent, result.Faults[0].DivergentEntry)
+}
+
+func TestPcrEventLogReplayAllBanksInvalidMeasurementFault(t *testing.T) {
+	hostManifest := newReplayAllBanksHostManifest(t)
+
+	expectedEvents, err := hostManifest.PcrManifest.GetEventLogCriteria(types.SHA256, types.PCR0)
+	assert.NoError(t, err)
+	expectedPcr := types.FlavorPcrs{
+		Pcr: types.Pcr{Index: 0, Bank: "SHA256"},
+		EventlogEqual: &types.EventLogEqual{
+			Events: append([]types.EventLog{}, expectedEvents...),
+		},
+	}
+
+	// the measurement of the S-CRTM version does not match the flavor
+	for i, eventLog := range hostManifest.PcrManifest.PcrEventLogMap.Sha256EventLogs {
+		if eventLog.Pcr.Index == 0 {
+			hostManifest.PcrManifest.PcrEventLogMap.Sha256EventLogs[i].TpmEvent[1].Measurement = strings.Repeat("cd", 32)
+		}
+	}
+
+	rule, err := NewPcrEventLogReplayAllBanks([]types.FlavorPcrs{expectedPcr}, common.FlavorPartPlatform)
+	assert.NoError(t, err)
+	result, err := rule.Apply(&hostManifest)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result.Faults))
+	assert.Equal(t, types.SHA256, *result.Faults[0].PcrBank)
+	assert.NotNil(t, result.Faults[0].DivergentEntry)
+	assert.Equal(t, "EV_S_CRTM_VERSION", result.Faults[0].DivergentEntry.TypeName)
+	assert.Equal(t, strings.Repeat("cd", 32), result.Faults[0].DivergentEntry.Measurement)
+}
+
+func TestPcrEventLogReplayAllBanksMissingFaults(t *testing.T) {
+	hostManifest := newReplayAllBanksHostManifest(t)
+	hostManifest.PcrManifest.PcrEventLogMap.Sha1EventLogs = nil
+
+	rule, err := NewPcrEventLogReplayAllBanks([]types.FlavorPcrs{{Pcr: types.Pcr{Index: 7, Bank: "SHA256"}}}, common.FlavorPartOs)
+	assert.NoError(t, err)
+	result, err := rule.Apply(&hostManifest)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result.Faults))
+	assert.Equal(t, constants.FaultPcrEventLogMissing, result.Faults[0].Name)
+	assert.Equal(t, types.SHA1, *result.Faults[0].PcrBank)
+
+	// pcr 15 is not quoted in any bank
+	rule, err = NewPcrEventLogReplayAllBanks([]types.FlavorPcrs{{Pcr: types.Pcr{Index: 15, Bank: "SHA256"}}}, common.FlavorPartOs)
+	assert.NoError(t, err)
+	result, err = rule.Apply(&hostManifest)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result.Faults))
+	assert.Equal(t, constants.FaultPcrValueMissing, result.Faults[0].Name)
+
+	_, err = NewPcrEventLogReplayAllBanks(nil, common.FlavorPartOs)
+	assert.Error(t, err)
+}
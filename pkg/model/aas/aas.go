@@ -4,6 +4,12 @@
  */
 package aas
 
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"time"
+)
+
 type RoleInfo struct {
 	Service string `json:"service"`
 	// Name: UpdateHost
@@ -55,6 +61,27 @@ type UserRoleCreate struct {
 type UserCred struct {
 	UserName string `json:"username"`
 	Password string `json:"password"`
+	// TotpCode is the TOTP code or a recovery code of the users that enrolled an authenticator
+	TotpCode string `json:"totp_code,omitempty"`
+}
+
+// TotpEnrollmentRequest starts or confirms the enrollment of a TOTP authenticator. The code of the current
+// authenticator is required to replace it, the code of the new authenticator confirms the enrollment.
+type TotpEnrollmentRequest struct {
+	UserName string `json:"username"`
+	Password string `json:"password"`
+	TotpCode string `json:"totp_code,omitempty"`
+}
+
+// TotpEnrollment is the secret of a new TOTP authenticator, key_uri is the otpauth URI of the QR code
+type TotpEnrollment struct {
+	Secret string `json:"secret"`
+	KeyURI string `json:"key_uri"`
+}
+
+// MfaRecoveryCodes are the single use codes that replace the TOTP code when the authenticator is lost
+type MfaRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 type PasswordChange struct {
@@ -64,7 +91,82 @@ type PasswordChange struct {
 	PasswordConfirm string `json:"password_confirm"`
 }
 
+// AccountLockout is a user or a client that is locked out after too many failed authentications
+type AccountLockout struct {
+	UserName       string    `json:"username,omitempty"`
+	ClientID       string    `json:"client_id,omitempty"`
+	FailedAttempts int       `json:"failed_attempts,omitempty"`
+	LockedUntil    time.Time `json:"locked_until"`
+}
+
 type AuthClaims struct {
 	Roles       []RoleInfo       `json:"roles"`
 	Permissions []PermissionInfo `json:"permissions,omitempty"`
 }
+
+type ClientCreate struct {
+	Name string `json:"client_name"`
+	// One of client_secret_basic, client_secret_post, private_key_jwt or tls_client_auth
+	TokenEndpointAuthMethod string `json:"token_endpoint_auth_method"`
+	// Common name of the CMS issued certificate the client authenticates with, required for private_key_jwt and tls_client_auth
+	CertificateCommonName string `json:"certificate_common_name,omitempty"`
+}
+
+type ClientCreateResponse struct {
+	ID                      string `json:"client_id"`
+	Name                    string `json:"client_name"`
+	TokenEndpointAuthMethod string `json:"token_endpoint_auth_method"`
+	CertificateCommonName   string `json:"certificate_common_name,omitempty"`
+	// The secret is only returned when the client is created
+	Secret string `json:"client_secret,omitempty"`
+}
+
+type OAuth2TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+type OAuth2Error struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// OpenIDConfiguration is the OpenID Connect discovery document of AAS, it lets OAuth2 clients and API gateways find
+// the token endpoint and the JWK set of the token signing keys
+type OpenIDConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	JwksURI                           string   `json:"jwks_uri"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// RevokedToken is an entry of the token revocation list. A token is revoked when its id (jti) is the token_id of an
+// entry, or when the entry has no token_id, the subject_hash is the SubjectHash of the token subject and the token was
+// issued (iat) before revoked_at. As iat has a precision of one second, revoked_at is always a whole second.
+type RevokedToken struct {
+	TokenID string `json:"token_id,omitempty"`
+	// SubjectHash is published instead of the subject, so that the list does not disclose user names
+	SubjectHash string    `json:"subject_hash,omitempty"`
+	RevokedAt   time.Time `json:"revoked_at"`
+	// the entry can be dropped from the list once the revoked tokens expired
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SubjectHash returns the hex encoded SHA-384 digest of a token subject as it is published in the revocation list
+func SubjectHash(subject string) string {
+	digest := sha512.Sum384([]byte(subject))
+	return hex.EncodeToString(digest[:])
+}
+
+type RevocationList struct {
+	RevokedTokens []RevokedToken `json:"revoked_tokens"`
+}
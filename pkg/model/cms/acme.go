@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package cms
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// The ACME objects below follow the field names defined in RFC 8555 so that standard ACME clients can talk to CMS
+
+const (
+	AcmeStatusPending     = "pending"
+	AcmeStatusReady       = "ready"
+	AcmeStatusProcessing  = "processing"
+	AcmeStatusValid       = "valid"
+	AcmeStatusInvalid     = "invalid"
+	AcmeStatusDeactivated = "deactivated"
+
+	AcmeIdentifierDns = "dns"
+	AcmeIdentifierIp  = "ip"
+
+	// AcmeChallengeAasRole is the challenge type used by CMS. The identifiers are validated against the SAN lists of
+	// the CertApprover roles in the AAS token that was used to create the external account binding key of the account
+	AcmeChallengeAasRole = "aas-role-01"
+)
+
+// ACME error types (RFC 8555 section 6.7)
+const (
+	AcmeErrorAccountDoesNotExist     = "urn:ietf:params:acme:error:accountDoesNotExist"
+	AcmeErrorBadCsr                  = "urn:ietf:params:acme:error:badCSR"
+	AcmeErrorBadNonce                = "urn:ietf:params:acme:error:badNonce"
+	AcmeErrorBadPublicKey            = "urn:ietf:params:acme:error:badPublicKey"
+	AcmeErrorBadSignatureAlgorithm   = "urn:ietf:params:acme:error:badSignatureAlgorithm"
+	AcmeErrorExternalAccountRequired = "urn:ietf:params:acme:error:externalAccountRequired"
+	AcmeErrorMalformed               = "urn:ietf:params:acme:error:malformed"
+	AcmeErrorOrderNotReady           = "urn:ietf:params:acme:error:orderNotReady"
+	AcmeErrorRejectedIdentifier      = "urn:ietf:params:acme:error:rejectedIdentifier"
+	AcmeErrorServerInternal          = "urn:ietf:params:acme:error:serverInternal"
+	AcmeErrorUnauthorized            = "urn:ietf:params:acme:error:unauthorized"
+	AcmeErrorUnsupportedIdentifier   = "urn:ietf:params:acme:error:unsupportedIdentifier"
+)
+
+// AcmeProblem is a problem document (RFC 7807) returned by the ACME endpoints on errors
+type AcmeProblem struct {
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+	Status int    `json:"status"`
+}
+
+func (p AcmeProblem) Error() string {
+	return p.Type + ": " + p.Detail
+}
+
+type AcmeDirectory struct {
+	NewNonce   string            `json:"newNonce"`
+	NewAccount string            `json:"newAccount"`
+	NewOrder   string            `json:"newOrder"`
+	Meta       AcmeDirectoryMeta `json:"meta"`
+}
+
+type AcmeDirectoryMeta struct {
+	ExternalAccountRequired bool `json:"externalAccountRequired"`
+}
+
+type AcmeIdentifier struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type AcmeAccount struct {
+	Status  string   `json:"status"`
+	Contact []string `json:"contact,omitempty"`
+	Orders  string   `json:"orders"`
+}
+
+type AcmeNewAccountRequest struct {
+	Contact                []string        `json:"contact,omitempty"`
+	TermsOfServiceAgreed   bool            `json:"termsOfServiceAgreed,omitempty"`
+	OnlyReturnExisting     bool            `json:"onlyReturnExisting,omitempty"`
+	ExternalAccountBinding json.RawMessage `json:"externalAccountBinding,omitempty"`
+}
+
+type AcmeAccountUpdateRequest struct {
+	Status  string   `json:"status,omitempty"`
+	Contact []string `json:"contact,omitempty"`
+}
+
+type AcmeOrderList struct {
+	Orders []string `json:"orders"`
+}
+
+type AcmeNewOrderRequest struct {
+	Identifiers []AcmeIdentifier `json:"identifiers"`
+	NotBefore   string           `json:"notBefore,omitempty"`
+	NotAfter    string           `json:"notAfter,omitempty"`
+}
+
+type AcmeOrder struct {
+	Status         string           `json:"status"`
+	Expires        time.Time        `json:"expires"`
+	Identifiers    []AcmeIdentifier `json:"identifiers"`
+	Authorizations []string         `json:"authorizations"`
+	Finalize       string           `json:"finalize"`
+	Certificate    string           `json:"certificate,omitempty"`
+	Error          *AcmeProblem     `json:"error,omitempty"`
+}
+
+type AcmeAuthorization struct {
+	Identifier AcmeIdentifier  `json:"identifier"`
+	Status     string          `json:"status"`
+	Expires    time.Time       `json:"expires"`
+	Challenges []AcmeChallenge `json:"challenges"`
+}
+
+type AcmeChallenge struct {
+	Type      string     `json:"type"`
+	Url       string     `json:"url"`
+	Status    string     `json:"status"`
+	Token     string     `json:"token"`
+	Validated *time.Time `json:"validated,omitempty"`
+}
+
+type AcmeFinalizeRequest struct {
+	// Csr is the base64url encoded DER CSR
+	Csr string `json:"csr"`
+}
+
+// EabKey is the external account binding key handed out to a CMS client for registering an ACME account
+type EabKey struct {
+	KeyId string `json:"key_id"`
+	// HmacKey is the base64url encoded MAC key used to sign the external account binding
+	HmacKey string    `json:"hmac_key"`
+	Expires time.Time `json:"expires"`
+}
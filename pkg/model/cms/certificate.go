@@ -0,0 +1,23 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package cms
+
+import "time"
+
+// IssuedCertificate stores the details of a certificate issued by CMS
+type IssuedCertificate struct {
+	// SerialNumber is the hex encoded serial number of the issued certificate
+	SerialNumber string   `json:"serial_number"`
+	Subject      string   `json:"subject"`
+	SanList      []string `json:"san_list,omitempty"`
+	CertType     string   `json:"cert_type"`
+	IssuingCa    string   `json:"issuing_ca"`
+	// RequestedBy is the subject of the token used to request the certificate
+	RequestedBy string    `json:"requested_by,omitempty"`
+	NotBefore   time.Time `json:"not_before"`
+	NotAfter    time.Time `json:"not_after"`
+	// Certificate is the PEM encoded issued certificate
+	Certificate string `json:"certificate"`
+}
@@ -0,0 +1,24 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package cms
+
+import "time"
+
+// RevocationRequest is the payload used to revoke a certificate issued by CMS
+type RevocationRequest struct {
+	// SerialNumber is the hex encoded serial number of the certificate to be revoked
+	SerialNumber string `json:"serial_number"`
+	// IssuingCa is only required for the certificates that are not in the issued certificate inventory
+	IssuingCa string `json:"issuing_ca,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// RevokedCertificate stores the details of a certificate revoked by CMS
+type RevokedCertificate struct {
+	SerialNumber string    `json:"serial_number"`
+	IssuingCa    string    `json:"issuing_ca"`
+	Reason       string    `json:"reason"`
+	RevokedAt    time.Time `json:"revoked_at"`
+}
@@ -30,6 +30,8 @@ type PcrRules struct {
 	EventlogEquals   *EventLogEquals `json:"eventlog_equals,omitempty"`
 	// To include events, list of event tags need to be provided as string array. Sample value: "eventlog_includes": ["shim","db","kek","vmlinuz"]
 	EventlogIncludes []string        `json:"eventlog_includes,omitempty"`
+	// Boolean value to denote whether the event logs of the PCR index need to be replayed in every bank of the quote.
+	EventlogReplayAllBanks *bool `json:"eventlog_replay_all_banks,omitempty"`
 }
 
 type FlavorPart struct {
@@ -51,13 +53,16 @@ type FlavorTemplate struct {
 	Label string    `json:"label"`
 	// An array of 'jsonquery' statements that are used to determine if the template should be executed. Sample value: ["//host_info/os_name//*[text()='RedHatEnterprise']","//host_info/hardware_features/TPM/meta/tpm_version//*[text()='2.0']"].
 	Condition   []string     `json:"condition" sql:"type:text[]"`
-	FlavorParts *FlavorParts `json:"flavor_parts,omitempty" sql:"type:JSONB"`
+	// A Common Expression Language expression over the host manifest, available as 'host_manifest', that must evaluate to true for the template to be executed. Sample value: "host_manifest.host_info.os_name == 'RedHatEnterprise' && host_manifest.host_info.hardware_features.TPM.meta.tpm_version == '2.0'".
+	ConditionExpression string       `json:"condition_expression,omitempty"`
+	FlavorParts         *FlavorParts `json:"flavor_parts,omitempty" sql:"type:JSONB"`
 }
 
 type PcrListRules struct {
-	PcrMatches  bool
-	PcrEquals   PcrEquals
-	PcrIncludes map[string]bool
+	PcrMatches        bool
+	PcrEquals         PcrEquals
+	PcrIncludes       map[string]bool
+	PcrReplayAllBanks bool
 }
 
 type PcrEquals struct {
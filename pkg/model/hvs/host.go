@@ -19,6 +19,8 @@ type Host struct {
 	// swagger:strfmt uuid
 	HardwareUuid     *uuid.UUID `json:"hardware_uuid,omitempty"`
 	FlavorgroupNames []string   `json:"flavorgroup_names,omitempty"`
+	// PushMode hosts submit their quotes to HVS and are never connected to by HVS
+	PushMode bool `json:"push_mode,omitempty"`
 }
 
 type HostCreateRequest struct {
@@ -26,6 +28,7 @@ type HostCreateRequest struct {
 	Description      string   `json:"description,omitempty"`
 	ConnectionString string   `json:"connection_string"`
 	FlavorgroupNames []string `json:"flavorgroup_names,omitempty"`
+	PushMode         bool     `json:"push_mode,omitempty"`
 }
 
 type HostFlavorgroupCollection struct {
@@ -0,0 +1,35 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package hvs
+
+import (
+	"time"
+
+	taModel "github.com/intel-secl/intel-secl/v3/pkg/model/ta"
+)
+
+// HostQuoteNonce is the nonce that a push-mode host has to quote before it expires
+type HostQuoteNonce struct {
+	// Nonce is base64 encoded
+	Nonce      string    `json:"nonce"`
+	Expiration time.Time `json:"expiration"`
+}
+
+// HostQuote is the TPM quote submitted by a push-mode host
+type HostQuote struct {
+	// Nonce is the base64 encoded nonce issued by HVS for this quote
+	Nonce string `json:"nonce"`
+	// Quote is the TPMS_ATTEST structure returned by TPM2_Quote
+	Quote []byte `json:"quote"`
+	// Signature is the TPMT_SIGNATURE of the quote
+	Signature []byte `json:"signature"`
+	// PcrValues are the values of the quoted PCRs in the order of the PCR selection of the quote
+	PcrValues []byte `json:"pcr_values"`
+	// AikCertificate is the DER encoded AIK certificate issued by the HVS Privacy CA
+	AikCertificate []byte `json:"aik_certificate"`
+	// EventLog is the measure log of the host in the Trust Agent format or the base64 encoded TCG binary event log
+	EventLog string           `json:"event_log,omitempty"`
+	HostInfo taModel.HostInfo `json:"host_info"`
+}
@@ -0,0 +1,83 @@
+/*
+ *  Copyright (C) 2020 Intel Corporation
+ *  SPDX-License-Identifier: BSD-3-Clause
+ */
+
+package hvs
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HostTrustChangedEvent is the event notified when the trust of a host, or of one of its flavor parts, changes
+const HostTrustChangedEvent = "host_trust_changed"
+
+type NotificationSubscriptionCollection struct {
+	NotificationSubscriptions []NotificationSubscription `json:"notification_subscriptions"`
+}
+
+// NotificationSubscription is a webhook notified of the trust changes of the hosts that match its filters. An empty
+// filter matches every host.
+type NotificationSubscription struct {
+	// swagger:strfmt uuid
+	Id  uuid.UUID `json:"id"`
+	URL string    `json:"url"`
+	// Secret is the HMAC key the payloads are signed with, it is only returned when the subscription is created
+	Secret         string      `json:"secret,omitempty"`
+	HostIds        []uuid.UUID `json:"host_ids,omitempty"`
+	FlavorgroupIds []uuid.UUID `json:"flavorgroup_ids,omitempty"`
+	FlavorParts    []string    `json:"flavor_parts,omitempty"`
+	CreatedAt      time.Time   `json:"created"`
+}
+
+type NotificationSubscriptionCreateRequest struct {
+	URL            string      `json:"url"`
+	HostIds        []uuid.UUID `json:"host_ids,omitempty"`
+	FlavorgroupIds []uuid.UUID `json:"flavorgroup_ids,omitempty"`
+	FlavorParts    []string    `json:"flavor_parts,omitempty"`
+}
+
+type NotificationDeliveryCollection struct {
+	NotificationDeliveries []NotificationDelivery `json:"notification_deliveries"`
+}
+
+// NotificationDelivery records the delivery of a notification to a subscription
+type NotificationDelivery struct {
+	// swagger:strfmt uuid
+	Id uuid.UUID `json:"id"`
+	// swagger:strfmt uuid
+	SubscriptionId uuid.UUID `json:"subscription_id"`
+	// swagger:strfmt uuid
+	HostId     uuid.UUID `json:"host_id"`
+	Event      string    `json:"event"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Delivered  bool      `json:"delivered"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created"`
+	UpdatedAt  time.Time `json:"updated"`
+}
+
+// HostTrustChange is the payload posted to the subscriptions when the trust of a host changes
+type HostTrustChange struct {
+	Event string `json:"event"`
+	// swagger:strfmt uuid
+	HostId         uuid.UUID               `json:"host_id"`
+	HostName       string                  `json:"host_name,omitempty"`
+	FlavorgroupIds []uuid.UUID             `json:"flavorgroup_ids,omitempty"`
+	Trusted        bool                    `json:"trusted"`
+	PreviousTrust  bool                    `json:"previously_trusted"`
+	FlavorParts    []FlavorPartTrustChange `json:"flavor_parts,omitempty"`
+	// swagger:strfmt uuid
+	ReportId uuid.UUID `json:"report_id"`
+	Time     time.Time `json:"time"`
+}
+
+// FlavorPartTrustChange is a flavor part whose trust changed
+type FlavorPartTrustChange struct {
+	FlavorPart    string `json:"flavor_part"`
+	Trusted       bool   `json:"trusted"`
+	PreviousTrust bool   `json:"previously_trusted"`
+}
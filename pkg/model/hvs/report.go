@@ -46,3 +46,25 @@ type ReportCreateRequest struct {
 	HardwareUUID uuid.UUID `json:"hardware_uuid"`
 	HostName     string    `json:"host_name"`
 }
+
+// ReportSummary is the summary of a trust report sent by the report stream
+type ReportSummary struct {
+	// swagger:strfmt uuid
+	ID uuid.UUID `json:"id"`
+	// swagger:strfmt uuid
+	HostID      uuid.UUID                  `json:"host_id"`
+	HostName    string                     `json:"host_name"`
+	Overall     bool                       `json:"OVERALL"`
+	FlavorTrust map[common.FlavorPart]bool `json:"flavors_trust"`
+	Faults      []ReportFault              `json:"faults,omitempty"`
+	CreatedAt   time.Time                  `json:"created"`
+	Expiration  time.Time                  `json:"expiration"`
+}
+
+// ReportFault is a fault of a rule of a trust report
+type ReportFault struct {
+	RuleName    string              `json:"rule_name"`
+	Markers     []common.FlavorPart `json:"markers,omitempty"`
+	FaultName   string              `json:"fault_name"`
+	Description string              `json:"description"`
+}
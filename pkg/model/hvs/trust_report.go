@@ -77,6 +77,8 @@ type Fault struct {
 	MeasurementId          *string                `json:"measurement_id,omitempty"`
 	FlavorDigestAlg        *string                `json:"flavor_digest_alg,omitempty"`
 	MeasurementDigestAlg   *string                `json:"measurement_digest_alg,omitempty"`
+	// DivergentEntry is the first event of the event log that does not match its expected value
+	DivergentEntry *types.EventLog `json:"divergent_entry,omitempty"`
 }
 
 func NewTrustReport(report TrustReport) *TrustReport {
@@ -129,7 +131,8 @@ func (t *TrustReport) CheckResultExists(targetRuleResult RuleResult) bool {
 				constants.RulePcrEventLogIncludes,
 				constants.RulePcrEventLogIntegrity,
 				constants.RulePcrEventLogEquals,
-				constants.RulePcrMatchesConstant:
+				constants.RulePcrMatchesConstant,
+				constants.RulePcrEventLogReplayAllBanks:
 				// Compare pcrs for only these rules, all other rules will have pcr expected entry = nil
 				// We need to take care same rule does not get repeated in report, hence exclude value for PCR match
 				// as in case of rules like RulePcrEventLogIncludes, RulePcrEventLogEqualsExcluding etc actual PCR value can be different
@@ -28,6 +28,17 @@ type KeyRequest struct {
 	TransferPolicyID uuid.UUID `json:"transfer_policy_id,omitempty"`
 	Label            string    `json:"label,omitempty"`
 	Usage            string    `json:"usage,omitempty"`
+	KeyUsagePolicy
+}
+
+// KeyUsagePolicy - Limits when and how often a key can be transferred.
+type KeyUsagePolicy struct {
+	// NotBefore and NotAfter bound the period in which the key is transferable
+	NotBefore *time.Time `json:"not_before,omitempty"`
+	NotAfter  *time.Time `json:"not_after,omitempty"`
+	// MaxTransferCount is the number of transfers allowed, the transfers are not limited when it is 0
+	MaxTransferCount int  `json:"max_transfer_count,omitempty"`
+	Disabled         bool `json:"disabled,omitempty"`
 }
 
 // KeyResponse - key attributes from key create or register response.
@@ -39,6 +50,29 @@ type KeyResponse struct {
 	CreatedAt        time.Time `json:"created_at"`
 	Label            string    `json:"label,omitempty"`
 	Usage            string    `json:"usage,omitempty"`
+	// Version of the key material, incremented by each rotation
+	Version int `json:"version,omitempty"`
+	KeyUsagePolicy
+	// TransferCount is the number of transfers of the key
+	TransferCount     int        `json:"transfer_count"`
+	LastTransferredAt *time.Time `json:"last_transferred_at,omitempty"`
+}
+
+// KeyRotateRequest - Optional attributes of a key rotate request.
+type KeyRotateRequest struct {
+	// PriorVersionValidityMinutes is how long the replaced version stays transferable, the configured default applies
+	// when it is not set
+	PriorVersionValidityMinutes *int `json:"prior_version_validity_minutes,omitempty"`
+}
+
+// KeyVersionResponse - A version of a key, the material of a prior version can be transferred until transferable_until.
+type KeyVersionResponse struct {
+	Version           int        `json:"version"`
+	Primary           bool       `json:"primary"`
+	Transferable      bool       `json:"transferable"`
+	CreatedAt         time.Time  `json:"created_at"`
+	RetiredAt         *time.Time `json:"retired_at,omitempty"`
+	TransferableUntil *time.Time `json:"transferable_until,omitempty"`
 }
 
 // KeyTransferAttributes - Contains all possible key transfer attributes.
@@ -50,6 +84,7 @@ type KeyTransferAttributes struct {
 	KeyAlgorithm string     `json:"algorithm,omitempty"`
 	KeyLength    int        `json:"key_length,omitempty"`
 	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	Version      int        `json:"version,omitempty"`
 	Policy       struct {
 		Link struct {
 			KeyTransfer struct {
@@ -0,0 +1,30 @@
+/*
+ * Copyright (C) 2020 Intel Corporation
+ * SPDX-License-Identifier: BSD-3-Clause
+ */
+package kbs
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KeyTransferRecord - The record of an attempt to transfer a key, with the decision taken and its reason.
+type KeyTransferRecord struct {
+	// swagger:strfmt uuid
+	ID uuid.UUID `json:"id"`
+	// swagger:strfmt uuid
+	KeyID uuid.UUID `json:"key_id"`
+	// Requester is the JWT subject, the common name of the client certificate or the hardware UUID of the host in the
+	// SAML report
+	Requester       string `json:"requester,omitempty"`
+	AttestationType string `json:"attestation_type"`
+	// swagger:strfmt uuid
+	TransferPolicyID uuid.UUID `json:"transfer_policy_id,omitempty"`
+	// Decision is allowed, denied or challenged
+	Decision  string    `json:"decision"`
+	Reason    string    `json:"reason,omitempty"`
+	Version   int       `json:"version,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
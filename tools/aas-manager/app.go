@@ -250,6 +250,8 @@ func (a *App) GetGlobalAdminUser() *UserAndRolesCreate {
 			urc.Roles = append(urc.Roles, NewRole("AAS", "Administrator", "", []string{"*:*:*"}))
 		}
 	}
+	// CMS is part of every deployment, so the global admin is always allowed to search and revoke the certificates issued by CMS
+	urc.Roles = append(urc.Roles, NewRole("CMS", "Administrator", "", []string{"*:*:*"}))
 	return &urc
 }
 